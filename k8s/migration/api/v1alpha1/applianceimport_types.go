@@ -0,0 +1,80 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplianceFormat is the packaging of an appliance that is imported without a source hypervisor
+// +kubebuilder:validation:Enum=ova;hyperv
+type ApplianceFormat string
+
+const (
+	// ApplianceFormatOVA is an .ova archive or an .ovf descriptor with its disk files beside it
+	ApplianceFormatOVA ApplianceFormat = "ova"
+	// ApplianceFormatHyperV is a Hyper-V export: the configuration XML and the
+	// VHD/VHDX files, laid out as Export-VM writes them
+	ApplianceFormatHyperV ApplianceFormat = "hyperv"
+)
+
+// ApplianceImportSpec defines the desired state of ApplianceImport
+type ApplianceImportSpec struct {
+	// Format is the packaging of the source
+	Format ApplianceFormat `json:"format"`
+	// Source is a path relative to the import directory (/home/ubuntu/imports)
+	// on the vjailbreak node, an nfs://host/export/path or smb://host/share/path
+	// on a file share, or for an OVA an http(s) URL to download. For an .ovf the
+	// disk files must sit next to the descriptor. For Hyper-V it is the export
+	// directory of the VM or its configuration XML.
+	Source string `json:"source"`
+	// SourceSecretRef is a secret with username and password keys for an smb:// source
+	// +optional
+	SourceSecretRef *corev1.LocalObjectReference `json:"sourceSecretRef,omitempty"`
+
+	ImportTargetSpec `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.spec.format`,name=Format,type=string
+// +kubebuilder:printcolumn:JSONPath=`.spec.source`,name=Source,type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.phase`,name=Phase,type=string
+
+// ApplianceImport is the Schema for the applianceimports API. It imports an
+// appliance that has no source hypervisor, such as a vendor OVA, into OpenStack.
+type ApplianceImport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApplianceImportSpec `json:"spec,omitempty"`
+	Status ImportStatus        `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApplianceImportList contains a list of ApplianceImport
+type ApplianceImportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ApplianceImport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ApplianceImport{}, &ApplianceImportList{})
+}
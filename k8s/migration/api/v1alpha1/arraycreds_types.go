@@ -43,7 +43,7 @@ type ArrayCredsInfo struct {
 
 // ArrayCredsSpec defines the desired state of ArrayCreds
 type ArrayCredsSpec struct {
-	// VendorType is the storage array vendor type (e.g., pure, ontap, hpalletra)
+	// VendorType is the storage array vendor type (e.g., pure, netapp, powerstore, hpalletra, simulator)
 	VendorType string `json:"vendorType"`
 
 	// SecretRef is the reference to the Kubernetes secret holding storage array credentials
@@ -62,6 +62,31 @@ type ArrayCredsSpec struct {
 	// on status.backendTargets for interactive selection.
 	// +optional
 	NetAppConfig *NetAppConfig `json:"netAppConfig,omitempty"`
+
+	// PowerStoreConfig holds Dell PowerStore-specific configuration. Optional;
+	// the appliances of the cluster are surfaced on status.backendTargets.
+	// +optional
+	PowerStoreConfig *PowerStoreConfig `json:"powerStoreConfig,omitempty"`
+
+	// HPEAlletraConfig holds HPE Alletra, Primera and 3PAR-specific
+	// configuration. Required when the array has more than one CPG; the CPGs
+	// are surfaced on status.backendTargets for selection.
+	// +optional
+	HPEAlletraConfig *HPEAlletraConfig `json:"hpeAlletraConfig,omitempty"`
+
+	// SimulatorConfig holds the configuration of the simulated array, for
+	// development and CI clusters. Optional.
+	// +optional
+	SimulatorConfig *SimulatorConfig `json:"simulatorConfig,omitempty"`
+
+	// ArrayNativeCopy copies the disks of VMs on single-LUN VMFS datastores
+	// by snapshotting and cloning the datastore LUN on the array, then
+	// extracting the VMDKs from the clone in the v2v-helper pod, instead
+	// of cloning each VMDK with vmkfstools on the ESXi host. Requires a
+	// vendor with snapshot clones and a VMFS FUSE driver in the v2v-helper
+	// image, which it does not ship yet: validation rejects the setting.
+	// +optional
+	ArrayNativeCopy bool `json:"arrayNativeCopy,omitempty"`
 }
 
 // NetAppConfig holds NetApp ONTAP-specific targeting information. Both fields
@@ -75,6 +100,39 @@ type NetAppConfig struct {
 	FlexVol string `json:"flexVol,omitempty"`
 }
 
+// PowerStoreConfig holds Dell PowerStore-specific targeting information.
+type PowerStoreConfig struct {
+	// Appliance is the name of the appliance of the cluster new volumes are
+	// created on. PowerStore places them itself when it is empty.
+	// +optional
+	Appliance string `json:"appliance,omitempty"`
+}
+
+// HPEAlletraConfig holds HPE Alletra, Primera and 3PAR-specific targeting information.
+type HPEAlletraConfig struct {
+	// CPG is the Common Provisioning Group new volumes are created in. May be
+	// left empty when the array has a single CPG.
+	// +optional
+	CPG string `json:"cpg,omitempty"`
+}
+
+// SimulatorConfig holds the state file and the faults of the simulated array.
+type SimulatorConfig struct {
+	// StateFile is the JSON file the array state is kept in, to share it
+	// between pods. The state is kept in memory when it is empty.
+	// +optional
+	StateFile string `json:"stateFile,omitempty"`
+	// FailMapping makes mapping volumes to initiator groups fail.
+	// +optional
+	FailMapping bool `json:"failMapping,omitempty"`
+	// CreateDelay is how long creating a volume takes, as a Go duration (e.g. 30s).
+	// +optional
+	CreateDelay string `json:"createDelay,omitempty"`
+	// NAACollision gives new volumes the NAA of an existing volume.
+	// +optional
+	NAACollision bool `json:"naaCollision,omitempty"`
+}
+
 // OpenstackMapping holds the OpenStack Cinder configuration mapping
 type OpenstackMapping struct {
 	// VolumeType is the Cinder volume type associated with this mapping
@@ -36,6 +36,10 @@ type BootSource struct {
 const (
 	// MAASProvider represents the Metal As A Service provider for bare metal provisioning
 	MAASProvider BMCProviderName = "MAAS"
+	// IronicProvider represents the OpenStack Ironic bare metal service
+	IronicProvider BMCProviderName = "IRONIC"
+	// RedfishProvider represents direct control of the hosts' BMCs over the Redfish API
+	RedfishProvider BMCProviderName = "REDFISH"
 )
 
 // BMConfigSpec defines the desired state of BMConfig
@@ -44,9 +48,11 @@ type BMConfigSpec struct {
 	UserName string `json:"userName,omitempty"`
 	// Password is the password for the BM server
 	Password string `json:"password,omitempty"`
-	// APIKey is the API key for the BM server
+	// APIKey is the API key for the BM server. For Ironic this is a keystone token,
+	// leave it empty to use UserName/Password (http_basic) or noauth
 	APIKey string `json:"apiKey"`
-	// APIUrl is the API URL for the BM server
+	// APIUrl is the API URL for the BM server. For Redfish this is a comma separated
+	// list of BMC URLs sharing UserName/Password
 	APIUrl string `json:"apiUrl"`
 	// Insecure is a boolean indicating whether to use insecure connection
 	//+kubebuilder:default=false
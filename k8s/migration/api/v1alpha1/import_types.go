@@ -0,0 +1,82 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ImportPhase is the phase of an import that runs as a single v2v-helper job,
+// such as an ApplianceImport, a LibvirtMigration or a ProxmoxMigration
+type ImportPhase string
+
+const (
+	// ImportPhasePending means the import job has not been created yet
+	ImportPhasePending ImportPhase = "Pending"
+	// ImportPhaseRunning means the import job is running
+	ImportPhaseRunning ImportPhase = "Running"
+	// ImportPhaseSucceeded means the instance was created
+	ImportPhaseSucceeded ImportPhase = "Succeeded"
+	// ImportPhaseFailed means the import failed
+	ImportPhaseFailed ImportPhase = "Failed"
+)
+
+// ImportTargetSpec is the OpenStack instance an import creates
+type ImportTargetSpec struct {
+	// OpenstackCredsRef is the OpenStack project the instance is created in
+	OpenstackCredsRef corev1.LocalObjectReference `json:"openstackCredsRef"`
+	// TargetName is the name of the instance, defaults to the name of the source VM
+	// +optional
+	TargetName string `json:"targetName,omitempty"`
+	// NetworkNames are the Neutron networks for the NICs, in NIC order
+	NetworkNames []string `json:"networkNames"`
+	// VolumeTypes are the Cinder volume types for the disks, in disk order. The
+	// last entry applies to any remaining disks.
+	// +optional
+	VolumeTypes []string `json:"volumeTypes,omitempty"`
+	// TargetFlavorID is the flavor of the instance, the closest flavor is used when empty
+	// +optional
+	TargetFlavorID string `json:"targetFlavorId,omitempty"`
+	// TargetAvailabilityZone is the availability zone of the instance
+	// +optional
+	TargetAvailabilityZone string `json:"targetAvailabilityZone,omitempty"`
+	// SecurityGroups are the security groups of the instance
+	// +optional
+	SecurityGroups []string `json:"securityGroups,omitempty"`
+	// OSFamily overrides the guest OS family read from the source. Required when
+	// the source does not record it, as with a Hyper-V export.
+	// +kubebuilder:validation:Enum=windowsGuest;linuxGuest
+	// +optional
+	OSFamily string `json:"osFamily,omitempty"`
+	// VirtioWinDriver is the virtio driver ISO used for Windows guests
+	// +optional
+	VirtioWinDriver string `json:"virtioWinDriver,omitempty"`
+	// FirstBootScript runs in the guest on first boot
+	// +optional
+	FirstBootScript string `json:"firstBootScript,omitempty"`
+}
+
+// ImportStatus is the observed state of an import
+type ImportStatus struct {
+	// +optional
+	Phase ImportPhase `json:"phase,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+	// JobName is the v2v-helper job performing the import
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LibvirtCredsSpec defines the desired state of LibvirtCreds
+type LibvirtCredsSpec struct {
+	// URI is the libvirt connection URI of the KVM host, e.g.
+	// qemu+ssh://root@kvm01.example.com/system. Only the qemu+ssh transport on
+	// port 22 is supported, the disks are served from the host with qemu-nbd.
+	// +kubebuilder:validation:Pattern=`^qemu\+ssh://`
+	URI string `json:"uri"`
+
+	// SecretRef is the reference to the Kubernetes secret holding the SSH private key
+	// The secret should contain a key named "privateKey" with the SSH private key in PEM format,
+	// and live in the namespace of the LibvirtCreds so migration pods can read it
+	SecretRef corev1.ObjectReference `json:"secretRef"`
+}
+
+// LibvirtCredsStatus defines the observed state of LibvirtCreds
+type LibvirtCredsStatus struct {
+	// ValidationStatus is the status of the connection check
+	// Possible values: Validating, Succeeded, Failed
+	ValidationStatus string `json:"validationStatus,omitempty"`
+
+	// ValidationMessage is the message associated with the validation
+	ValidationMessage string `json:"validationMessage,omitempty"`
+
+	// Domains are the libvirt domains defined on the host
+	// +optional
+	Domains []string `json:"domains,omitempty"`
+
+	// LastValidationTime is the timestamp of the last validation run
+	LastValidationTime metav1.Time `json:"lastValidationTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.spec.uri`,name=URI,type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.validationStatus`,name=Status,type=string
+
+// LibvirtCreds is the Schema for the libvirtcreds API that defines how to reach a
+// standalone KVM host managed by libvirt. The controller connects over SSH, checks
+// that virsh and qemu-nbd are available and lists the domains defined on the host.
+type LibvirtCreds struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LibvirtCredsSpec   `json:"spec,omitempty"`
+	Status LibvirtCredsStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LibvirtCredsList contains a list of LibvirtCreds
+type LibvirtCredsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LibvirtCreds `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LibvirtCreds{}, &LibvirtCredsList{})
+}
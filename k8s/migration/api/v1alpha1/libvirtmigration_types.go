@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LibvirtMigrationSpec defines the desired state of LibvirtMigration
+type LibvirtMigrationSpec struct {
+	// LibvirtCredsRef is the KVM host the domain is defined on
+	LibvirtCredsRef corev1.LocalObjectReference `json:"libvirtCredsRef"`
+	// DomainName is the libvirt domain to migrate. It must be shut off, its disks
+	// are copied as they are.
+	DomainName string `json:"domainName"`
+
+	ImportTargetSpec `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.spec.libvirtCredsRef.name`,name=Host,type=string
+// +kubebuilder:printcolumn:JSONPath=`.spec.domainName`,name=Domain,type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.phase`,name=Phase,type=string
+
+// LibvirtMigration is the Schema for the libvirtmigrations API. It migrates a
+// domain from a standalone KVM host into OpenStack. The qcow2 or raw disks are
+// copied over NBD, and guests that already boot from virtio disks skip the
+// virt-v2v conversion.
+type LibvirtMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LibvirtMigrationSpec `json:"spec,omitempty"`
+	Status ImportStatus         `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// LibvirtMigrationList contains a list of LibvirtMigration
+type LibvirtMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LibvirtMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LibvirtMigration{}, &LibvirtMigrationList{})
+}
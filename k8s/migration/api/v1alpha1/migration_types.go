@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -25,7 +27,7 @@ import (
 // tracking the detailed progression through various stages including validation, data copying,
 // disk conversion, and cutover. Each phase provides visibility into the migration's progress,
 // enabling precise monitoring and troubleshooting of the migration workflow.
-// +kubebuilder:validation:Enum=Pending;Validating;ValidationFailed;AwaitingDataCopyStart;CopyingBlocks;CopyingChangedBlocks;ConvertingDisk;AwaitingCutOverStartTime;AwaitingAdminCutOver;WaitingForLDMBootSuccess;PromotingToVirtio;Succeeded;Failed;Unknown;ConnectingToESXi;CreatingInitiatorGroup;CreatingVolume;ImportingToCinder;MappingVolume;RescanningStorage;XCOPYInProgress;SnapshottingSourceVM;AttachingDisksToProxy;IdentifyingBlockDevices;HotAddTransferInProgress;HotAddCleanup;DataCopied
+// +kubebuilder:validation:Enum=Pending;Queued;Validating;ValidationFailed;AwaitingDataCopyStart;CopyingBlocks;CopyingChangedBlocks;ConvertingDisk;AwaitingCutOverStartTime;AwaitingAdminCutOver;WaitingForLDMBootSuccess;PromotingToVirtio;Succeeded;Failed;Unknown;ConnectingToESXi;CreatingInitiatorGroup;CreatingVolume;ImportingToCinder;MappingVolume;RescanningStorage;XCOPYInProgress;SnapshottingSourceVM;AttachingDisksToProxy;IdentifyingBlockDevices;HotAddTransferInProgress;HotAddCleanup;DataCopied
 type VMMigrationPhase string
 
 // MigrationConditionType represents the type of condition for a migration, used to track
@@ -36,6 +38,10 @@ type MigrationConditionType string
 const (
 	// VMMigrationPhasePending indicates the migration is waiting to start
 	VMMigrationPhasePending VMMigrationPhase = "Pending"
+	// VMMigrationPhaseQueued indicates the migration is held back because its ESXi
+	// host, one of its datastores or the vjailbreak agents already run as many
+	// migrations as allowed. QueuedReason says which.
+	VMMigrationPhaseQueued VMMigrationPhase = "Queued"
 	// VMMigrationPhaseValidating indicates the migration prerequisites are being validated
 	VMMigrationPhaseValidating VMMigrationPhase = "Validating"
 	// VMMigrationPhaseValidationFailed indicates the migration prerequisites validation failed
@@ -148,6 +154,17 @@ type MigrationSpec struct {
 	// DataOnly indicates no OpenStack VM should be created after disk conversion.
 	// +optional
 	DataOnly bool `json:"dataOnly,omitempty"`
+
+	// Export exports the converted disks as qcow2 files or Glance images. Set with DataOnly.
+	// +optional
+	Export *ExportOptions `json:"export,omitempty"`
+
+	// Promote creates the OpenStack instance of a data-only migration from its staged
+	// volumes once it reached DataCopied, without reading the source VM again. Ports
+	// are reserved and the flavor is picked as for any other migration. A failed
+	// promotion keeps the staged volumes and runs again after the Migration is edited.
+	// +optional
+	Promote bool `json:"promote,omitempty"`
 }
 
 // MigrationStatus defines the observed state of Migration
@@ -180,9 +197,201 @@ type MigrationStatus struct {
 	// +optional
 	SyncWarningMessage string `json:"syncWarningMessage,omitempty"`
 
+	// QueuedReason is why the migration is in the Queued phase
+	// +optional
+	QueuedReason string `json:"queuedReason,omitempty"`
+
 	// StagedVolumeIDs lists the Cinder volume IDs created during a data-only migration.
 	// +optional
 	StagedVolumeIDs []string `json:"stagedVolumeIDs,omitempty"`
+
+	// ExportedImages lists the qcow2 files, relative to the export PVC, or the Glance
+	// image IDs written by an export, in disk order.
+	// +optional
+	ExportedImages []string `json:"exportedImages,omitempty"`
+
+	// ExportManifest is the name of the ConfigMap holding the export manifest
+	// +optional
+	ExportManifest string `json:"exportManifest,omitempty"`
+
+	// DiskVerification holds the result of the integrity verification of each
+	// disk, in disk order
+	// +optional
+	DiskVerification []DiskVerification `json:"diskVerification,omitempty"`
+
+	// DiskProgress is the copy progress of each disk that reported any, as disks
+	// of a VM can be copied in parallel. Extracted from migration pod events.
+	// +optional
+	DiskProgress []DiskCopyProgress `json:"diskProgress,omitempty"`
+
+	// DiskTransfers is the data-plane throughput of the copy of each disk, reported
+	// by the migration pod and exported as Prometheus metrics by the controller
+	// +optional
+	DiskTransfers []DiskTransfer `json:"diskTransfers,omitempty"`
+
+	// CutoverReadiness is how long the final sync of a warm migration is
+	// predicted to take, from the changed-block syncs so far
+	// +optional
+	CutoverReadiness *CutoverReadiness `json:"cutoverReadiness,omitempty"`
+
+	// GuestStorage is what the inspection of the copied disks of a Windows guest
+	// found that conversion cannot take as is, checked before conversion starts
+	// +optional
+	GuestStorage []GuestStorageFinding `json:"guestStorage,omitempty"`
+}
+
+// GuestStorageKind is a Windows storage layout conversion has to look out for
+// +kubebuilder:validation:Enum=BitLocker;DynamicDisk;StorageSpaces;UnsupportedFilesystem
+type GuestStorageKind string
+
+const (
+	// GuestStorageKindBitLocker is a BitLocker encrypted volume
+	GuestStorageKindBitLocker GuestStorageKind = "BitLocker"
+	// GuestStorageKindDynamicDisk is a volume on a Windows Dynamic Disk (LDM)
+	GuestStorageKindDynamicDisk GuestStorageKind = "DynamicDisk"
+	// GuestStorageKindStorageSpaces is a disk that is a member of a Storage Spaces pool
+	GuestStorageKindStorageSpaces GuestStorageKind = "StorageSpaces"
+	// GuestStorageKindUnsupportedFilesystem is a file system libguestfs cannot open, such as ReFS
+	GuestStorageKindUnsupportedFilesystem GuestStorageKind = "UnsupportedFilesystem"
+)
+
+// GuestStorageSeverity is what a guest storage finding means for the migration
+// +kubebuilder:validation:Enum=Blocking;Warning;Remediated
+type GuestStorageSeverity string
+
+const (
+	// GuestStorageSeverityBlocking stops the migration before conversion
+	GuestStorageSeverityBlocking GuestStorageSeverity = "Blocking"
+	// GuestStorageSeverityWarning is copied as is but needs attention after cutover
+	GuestStorageSeverityWarning GuestStorageSeverity = "Warning"
+	// GuestStorageSeverityRemediated is handled by the migration
+	GuestStorageSeverityRemediated GuestStorageSeverity = "Remediated"
+)
+
+// GuestStorageFinding is one volume or disk of the guest found by the storage preflight
+type GuestStorageFinding struct {
+	// Device is the volume or partition as libguestfs names it, such as /dev/sda2
+	Device string `json:"device"`
+	// Kind is what was found on it
+	Kind GuestStorageKind `json:"kind"`
+	// Severity is what it means for the migration
+	Severity GuestStorageSeverity `json:"severity"`
+	// Message describes the finding
+	Message string `json:"message"`
+	// Remediation is what to do about it, or what the migration did
+	// +optional
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// CutoverReadiness predicts the downtime of cutting over a warm migration now.
+// The source VM is down while the blocks changed since the last sync are copied.
+type CutoverReadiness struct {
+	// Ready is true when the predicted downtime fits the downtime target
+	Ready bool `json:"ready"`
+	// PredictedDowntime is how long the final sync is expected to take
+	PredictedDowntime metav1.Duration `json:"predictedDowntime"`
+	// DowntimeTarget is the CUTOVER_DOWNTIME_TARGET vjailbreak setting
+	DowntimeTarget metav1.Duration `json:"downtimeTarget"`
+	// DeltaBytes is the size of the changed blocks of the last sync
+	DeltaBytes int64 `json:"deltaBytes"`
+	// DirtyBytesPerSecond is how fast the source VM changes blocks
+	DirtyBytesPerSecond int64 `json:"dirtyBytesPerSecond"`
+	// CopyBytesPerSecond is how fast changed blocks are copied
+	CopyBytesPerSecond int64 `json:"copyBytesPerSecond"`
+	// Syncs is the number of changed-block syncs so far
+	Syncs int `json:"syncs"`
+	// NextSyncInterval is the wait before the next periodic sync
+	// +optional
+	NextSyncInterval *metav1.Duration `json:"nextSyncInterval,omitempty"`
+	// UpdatedAt is when the prediction was made
+	UpdatedAt metav1.Time `json:"updatedAt"`
+}
+
+// DiskTransferMethod is how the data of a disk is copied
+// +kubebuilder:validation:Enum=nbd;hot-add;storage-accelerated
+type DiskTransferMethod string
+
+const (
+	// DiskTransferMethodNBD reads the disk over NBD from the VDDK
+	DiskTransferMethodNBD DiskTransferMethod = "nbd"
+	// DiskTransferMethodHotAdd reads the disk through a proxy VM it is attached to
+	DiskTransferMethodHotAdd DiskTransferMethod = "hot-add"
+	// DiskTransferMethodStorageAccelerated clones the disk on the storage array
+	DiskTransferMethodStorageAccelerated DiskTransferMethod = "storage-accelerated"
+)
+
+// DiskTransfer is how fast the copy of one disk goes
+type DiskTransfer struct {
+	// Disk is the name of the source disk
+	Disk string `json:"disk"`
+	// Method is how the disk is copied
+	Method DiskTransferMethod `json:"method"`
+	// BytesCopied is how much of the current copy pass is done. A pass is the
+	// whole disk, or the changed blocks of one CBT sync.
+	BytesCopied int64 `json:"bytesCopied"`
+	// BytesTotal is the size of the current copy pass
+	BytesTotal int64 `json:"bytesTotal"`
+	// BytesPerSecond is the recent copy rate
+	BytesPerSecond int64 `json:"bytesPerSecond"`
+	// CBTDeltaBytes is the size of the changed blocks of the last CBT sync
+	// +optional
+	CBTDeltaBytes int64 `json:"cbtDeltaBytes,omitempty"`
+	// CBTSyncs is the number of CBT syncs of the disk so far
+	// +optional
+	CBTSyncs int `json:"cbtSyncs,omitempty"`
+	// Transport is the VDDK transport mode the disk is read with, for the nbd method
+	// +optional
+	Transport VDDKTransportMode `json:"transport,omitempty"`
+	// UpdatedAt is when the copy last made progress
+	UpdatedAt metav1.Time `json:"updatedAt"`
+}
+
+// BytesRemaining is how much of the current copy pass is left
+func (t *DiskTransfer) BytesRemaining() int64 {
+	return max(t.BytesTotal-t.BytesCopied, 0)
+}
+
+// ETA is how long the rest of the current copy pass takes at the current rate,
+// and false while there is no rate to estimate it from
+func (t *DiskTransfer) ETA() (time.Duration, bool) {
+	remaining := t.BytesRemaining()
+	if remaining == 0 {
+		return 0, true
+	}
+	if t.BytesPerSecond <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(remaining) / float64(t.BytesPerSecond) * float64(time.Second)), true
+}
+
+// DiskCopyProgress is how far the copy of one disk has got
+type DiskCopyProgress struct {
+	// Disk is the index of the disk, as in CurrentDisk
+	Disk string `json:"disk"`
+	// Percent is the share of the disk copied in the last copy pass
+	Percent int `json:"percent"`
+}
+
+// DiskVerification is the result of comparing a copied disk with the source
+type DiskVerification struct {
+	// Disk is the name of the source disk
+	Disk string `json:"disk"`
+	// Mode is the verification mode the disk was compared in
+	Mode VerificationMode `json:"mode"`
+	// BytesVerified is how much of the disk was compared
+	BytesVerified int64 `json:"bytesVerified"`
+	// Mismatches is the number of ranges that differed from the source on the first comparison
+	// +optional
+	Mismatches int `json:"mismatches,omitempty"`
+	// MismatchedRanges lists the first of those ranges as offset+length in bytes
+	// +optional
+	MismatchedRanges []string `json:"mismatchedRanges,omitempty"`
+	// Repaired is the number of ranges copied again and found matching afterwards
+	// +optional
+	Repaired int `json:"repaired,omitempty"`
+	// Verified is true once every compared range matches the source. The VM is
+	// not created while a disk is not verified.
+	Verified bool `json:"verified"`
 }
 
 // +kubebuilder:object:root=true
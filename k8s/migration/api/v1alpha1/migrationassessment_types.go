@@ -0,0 +1,181 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AssessmentPhase is the state of a MigrationAssessment
+// +kubebuilder:validation:Enum=Completed;Failed
+type AssessmentPhase string
+
+const (
+	// AssessmentPhaseCompleted is set once the report is written. The VMs are
+	// assessed again periodically.
+	AssessmentPhaseCompleted AssessmentPhase = "Completed"
+	// AssessmentPhaseFailed is set when the assessment cannot run, see the message
+	AssessmentPhaseFailed AssessmentPhase = "Failed"
+)
+
+// AssessmentReadiness is how ready a VM is to be migrated
+// +kubebuilder:validation:Enum=Ready;NeedsAttention;Blocked
+type AssessmentReadiness string
+
+const (
+	// AssessmentReadinessReady VMs have nothing in the way of their migration
+	AssessmentReadinessReady AssessmentReadiness = "Ready"
+	// AssessmentReadinessNeedsAttention VMs migrate, but something should be
+	// looked at before or after
+	AssessmentReadinessNeedsAttention AssessmentReadiness = "NeedsAttention"
+	// AssessmentReadinessBlocked VMs fail to migrate until a blocking finding is resolved
+	AssessmentReadinessBlocked AssessmentReadiness = "Blocked"
+)
+
+// AssessmentSeverity is what a finding means for the migration of the VM
+// +kubebuilder:validation:Enum=Blocking;Warning;Info
+type AssessmentSeverity string
+
+const (
+	// AssessmentSeverityBlocking findings fail the migration
+	AssessmentSeverityBlocking AssessmentSeverity = "Blocking"
+	// AssessmentSeverityWarning findings lose something in the migration or
+	// need a choice to be made for it
+	AssessmentSeverityWarning AssessmentSeverity = "Warning"
+	// AssessmentSeverityInfo findings only change how the VM is migrated
+	AssessmentSeverityInfo AssessmentSeverity = "Info"
+)
+
+// AssessmentCheck is one of the checks a VM is assessed with
+// +kubebuilder:validation:Enum=OperatingSystem;Firmware;RDMDisks;USBDevices;SerialPorts;IndependentDisks;Snapshots;ChangedBlockTracking;HardwareVersion;NetworkMapping;StorageMapping;Flavor;Disks
+type AssessmentCheck string
+
+// The checks, named after what they look at
+const (
+	AssessmentCheckOperatingSystem      AssessmentCheck = "OperatingSystem"
+	AssessmentCheckFirmware             AssessmentCheck = "Firmware"
+	AssessmentCheckRDMDisks             AssessmentCheck = "RDMDisks"
+	AssessmentCheckUSBDevices           AssessmentCheck = "USBDevices"
+	AssessmentCheckSerialPorts          AssessmentCheck = "SerialPorts"
+	AssessmentCheckIndependentDisks     AssessmentCheck = "IndependentDisks"
+	AssessmentCheckSnapshots            AssessmentCheck = "Snapshots"
+	AssessmentCheckChangedBlockTracking AssessmentCheck = "ChangedBlockTracking"
+	AssessmentCheckHardwareVersion      AssessmentCheck = "HardwareVersion"
+	AssessmentCheckNetworkMapping       AssessmentCheck = "NetworkMapping"
+	AssessmentCheckStorageMapping       AssessmentCheck = "StorageMapping"
+	AssessmentCheckFlavor               AssessmentCheck = "Flavor"
+	AssessmentCheckDisks                AssessmentCheck = "Disks"
+)
+
+// MigrationAssessmentSpec defines the desired state of MigrationAssessment
+type MigrationAssessmentSpec struct {
+	// VMwareCredsRef is the VMwareCreds whose VMs are assessed
+	VMwareCredsRef corev1.LocalObjectReference `json:"vmwareCredsRef"`
+	// OpenstackCredsRef is the OpenstackCreds whose flavors the VMs are fitted
+	// to. The flavor fit is not checked without it.
+	// +optional
+	OpenstackCredsRef *corev1.LocalObjectReference `json:"openstackCredsRef,omitempty"`
+	// NetworkMapping is the NetworkMapping the VMs would be migrated with. The
+	// coverage of their networks is not checked without it.
+	// +optional
+	NetworkMapping string `json:"networkMapping,omitempty"`
+	// StorageMapping is the StorageMapping the VMs would be migrated with. The
+	// coverage of their datastores is not checked without it.
+	// +optional
+	StorageMapping string `json:"storageMapping,omitempty"`
+	// VMs limits the assessment to these VMs, by name. Every VM of the
+	// credentials is assessed when empty.
+	// +optional
+	VMs []string `json:"vms,omitempty"`
+}
+
+// AssessmentSummary counts the assessed VMs by readiness
+type AssessmentSummary struct {
+	// TotalVMs is the number of VMs assessed
+	TotalVMs int `json:"totalVMs"`
+	// Ready is the number of VMs with nothing in the way of their migration
+	Ready int `json:"ready"`
+	// NeedsAttention is the number of VMs with warnings only
+	NeedsAttention int `json:"needsAttention"`
+	// Blocked is the number of VMs with a blocking finding
+	Blocked int `json:"blocked"`
+	// AverageScore is the mean readiness score of the VMs, from 0 to 100
+	AverageScore int `json:"averageScore"`
+}
+
+// AssessmentIssueCount is the number of VMs a check found a problem with
+type AssessmentIssueCount struct {
+	// Check is the check that found the problem
+	Check AssessmentCheck `json:"check"`
+	// Severity is the severity of the findings
+	Severity AssessmentSeverity `json:"severity"`
+	// VMs is the number of VMs with such a finding
+	VMs int `json:"vms"`
+}
+
+// MigrationAssessmentStatus defines the observed state of MigrationAssessment
+type MigrationAssessmentStatus struct {
+	// Phase is the state of the assessment
+	Phase AssessmentPhase `json:"phase,omitempty"`
+	// Message explains the phase
+	Message string `json:"message,omitempty"`
+	// ObservedGeneration is the generation of the spec last assessed
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// AssessedAt is when the VMs were last assessed
+	AssessedAt *metav1.Time `json:"assessedAt,omitempty"`
+	// Summary counts the assessed VMs by readiness
+	Summary AssessmentSummary `json:"summary,omitempty"`
+	// Issues counts the VMs each check found a problem with, blocking ones first
+	Issues []AssessmentIssueCount `json:"issues,omitempty"`
+	// ReportConfigMap is the ConfigMap holding the per-VM report, as JSON under
+	// report.json and as CSV under report.csv
+	ReportConfigMap string `json:"reportConfigMap,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.spec.vmwareCredsRef.name`,name=VMwareCreds,type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.summary.totalVMs`,name=VMs,type=integer
+// +kubebuilder:printcolumn:JSONPath=`.status.summary.ready`,name=Ready,type=integer
+// +kubebuilder:printcolumn:JSONPath=`.status.summary.blocked`,name=Blocked,type=integer
+// +kubebuilder:printcolumn:JSONPath=`.status.summary.averageScore`,name=Score,type=integer
+// +kubebuilder:printcolumn:JSONPath=`.status.phase`,name=Phase,type=string
+
+// MigrationAssessment is the Schema for the migrationassessments API. It
+// assesses every VM of a VMwareCreds for what is in the way of its migration
+// and scores how ready it is, before any MigrationPlan is written.
+type MigrationAssessment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MigrationAssessmentSpec   `json:"spec,omitempty"`
+	Status MigrationAssessmentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MigrationAssessmentList contains a list of MigrationAssessment
+type MigrationAssessmentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MigrationAssessment `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MigrationAssessment{}, &MigrationAssessmentList{})
+}
@@ -49,6 +49,54 @@ type MigrationPlanStrategy struct {
 	// Compatible with all strategy types (hot, cold, mock).
 	// +kubebuilder:default:=false
 	DataOnly bool `json:"dataOnly,omitempty"`
+	// Export turns the converted disks into portable images instead of an OpenStack VM.
+	// It implies DataOnly.
+	// +optional
+	Export *ExportOptions `json:"export,omitempty"`
+}
+
+// ExportTarget is where an export writes the converted disks
+// +kubebuilder:validation:Enum=pvc;glance
+type ExportTarget string
+
+const (
+	// ExportTargetPVC writes qcow2 files to a PersistentVolumeClaim
+	ExportTargetPVC ExportTarget = "pvc"
+	// ExportTargetGlance uploads the converted volumes as Glance images
+	ExportTargetGlance ExportTarget = "glance"
+)
+
+// ExportOptions configures the export of the converted boot and data disks, for
+// golden-image capture or for archiving decommissioned VMs. Each export also writes
+// a manifest describing the disks, firmware and NIC layout of the VM.
+type ExportOptions struct {
+	// Target is pvc to write qcow2 files to PVCName or glance to upload Glance images
+	Target ExportTarget `json:"target"`
+	// PVCName is the PersistentVolumeClaim in the migration namespace the qcow2 files
+	// and manifest are written to, in a directory named after the Migration.
+	// Required when Target is pvc.
+	// +optional
+	PVCName string `json:"pvcName,omitempty"`
+	// Compress writes compressed qcow2 files. Glance images are uploaded by Cinder
+	// and are not compressed.
+	// +kubebuilder:default:=true
+	// +optional
+	Compress *bool `json:"compress,omitempty"`
+	// ImageVisibility is the visibility of the Glance images
+	// +kubebuilder:validation:Enum=private;shared;community;public
+	// +kubebuilder:default:=private
+	// +optional
+	ImageVisibility string `json:"imageVisibility,omitempty"`
+	// KeepVolumes keeps the converted Cinder volumes staged after the export.
+	// They are deleted otherwise.
+	// +kubebuilder:default:=false
+	// +optional
+	KeepVolumes bool `json:"keepVolumes,omitempty"`
+}
+
+// CompressEnabled returns whether qcow2 exports are compressed, which is the default
+func (e *ExportOptions) CompressEnabled() bool {
+	return e.Compress == nil || *e.Compress
 }
 
 // AdvancedOptions defines advanced configuration options for the migration process
@@ -75,6 +123,77 @@ type AdvancedOptions struct {
 	// ImageProfiles is the ordered list of VolumeImageProfile names to apply to the migrated VM's boot volume.
 	// +optional
 	ImageProfiles []string `json:"imageProfiles,omitempty"`
+	// BandwidthLimit caps the disk copy rate of each VM in the plan. The global cap
+	// in the vjailbreak settings still applies on top of it.
+	// +optional
+	BandwidthLimit *BandwidthLimit `json:"bandwidthLimit,omitempty"`
+	// Verification compares the copied disks with the source before the VM is
+	// created. Ranges that differ are copied again, and a migration whose disks
+	// still differ fails instead of cutting over. Not available with
+	// StorageAcceleratedCopy or a standalone ESXi source.
+	// +optional
+	Verification *IntegrityVerification `json:"verification,omitempty"`
+	// ParallelDiskCopies is how many disks of a VM are copied at once. Fewer are
+	// copied when the VM's share of the NFC connections of its ESXi host, set in
+	// the vjailbreak settings, is smaller. Defaults to one disk at a time, and to
+	// all disks at once with hot-add.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ParallelDiskCopies int `json:"parallelDiskCopies,omitempty"`
+	// VDDKTransport overrides the VDDK transport modes and compression of the
+	// MigrationTemplate
+	// +optional
+	VDDKTransport *VDDKTransport `json:"vddkTransport,omitempty"`
+}
+
+// VerificationMode selects how much of each disk integrity verification reads back
+// +kubebuilder:validation:Enum=Sampled;Full
+type VerificationMode string
+
+const (
+	// VerificationModeSampled compares SamplePercent of each disk, spread evenly across it
+	VerificationModeSampled VerificationMode = "Sampled"
+	// VerificationModeFull compares every block of each disk
+	VerificationModeFull VerificationMode = "Full"
+)
+
+// IntegrityVerification configures the comparison of the copied disks with the
+// source snapshot, read back over NBD and from the attached Cinder volume
+type IntegrityVerification struct {
+	// Mode is Sampled or Full
+	Mode VerificationMode `json:"mode"`
+	// SamplePercent is the percentage of each disk compared in Sampled mode
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=1
+	// +optional
+	SamplePercent int `json:"samplePercent,omitempty"`
+}
+
+// BandwidthLimit is a disk copy rate cap that can vary with the time of day
+type BandwidthLimit struct {
+	// RateMbps is the cap in megabits per second outside every schedule window, 0 means unlimited
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RateMbps int `json:"rateMbps,omitempty"`
+	// Schedule lists daily windows that override RateMbps, e.g. full speed at night
+	// and 200 Mbps during working hours. Times are in the appliance time zone and the
+	// first matching window wins.
+	// +optional
+	Schedule []BandwidthWindow `json:"schedule,omitempty"`
+}
+
+// BandwidthWindow is a daily time window with its own bandwidth cap
+type BandwidthWindow struct {
+	// Start is the start of the window, HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// End is the end of the window, HH:MM. A window ending before it starts wraps past midnight.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+	// RateMbps is the cap in megabits per second inside the window, 0 means unlimited
+	// +kubebuilder:validation:Minimum=0
+	RateMbps int `json:"rateMbps"`
 }
 
 // PostMigrationAction defines the post migration action for the virtual machine
@@ -27,10 +27,100 @@ type MigrationTemplateSource struct {
 	VMwareRef string `json:"vmwareRef"`
 }
 
+// DestinationType is the platform a MigrationTemplate migrates virtual machines to
+// +kubebuilder:validation:Enum=openstack;kubevirt
+type DestinationType string
+
+const (
+	// DestinationTypeOpenstack is an OpenStack cloud, the default
+	DestinationTypeOpenstack DestinationType = "openstack"
+	// DestinationTypeKubevirt is KubeVirt in the cluster vJailbreak runs in
+	DestinationTypeKubevirt DestinationType = "kubevirt"
+)
+
 // MigrationTemplateDestination defines the destination environment details for the migration template
 type MigrationTemplateDestination struct {
-	// OpenstackRef is the reference to the OpenStack credentials to be used as the destination environment
-	OpenstackRef string `json:"openstackRef"`
+	// Type is the platform the virtual machines are migrated to
+	// +kubebuilder:default:=openstack
+	// +optional
+	Type DestinationType `json:"type,omitempty"`
+	// OpenstackRef is the reference to the OpenStack credentials to be used as the destination environment.
+	// Required when Type is openstack.
+	// +optional
+	OpenstackRef string `json:"openstackRef,omitempty"`
+	// Kubevirt is the KubeVirt destination. Required when Type is kubevirt.
+	// +optional
+	Kubevirt *KubevirtDestination `json:"kubevirt,omitempty"`
+}
+
+// KubevirtDestination places the migrated virtual machines on KubeVirt in the cluster vJailbreak runs in.
+// Each disk becomes a CDI DataVolume uploaded through the CDI upload proxy, and the VM a VirtualMachine.
+// The StorageMapping targets are StorageClass names, empty for the default StorageClass, and the
+// NetworkMapping targets are Multus NetworkAttachmentDefinitions, as name or namespace/name, or "pod"
+// for the pod network.
+type KubevirtDestination struct {
+	// Namespace is where the DataVolumes and VirtualMachines are created
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// UploadProxyURL is the URL of the CDI upload proxy, https://cdi-uploadproxy.cdi.svc when empty
+	// +optional
+	UploadProxyURL string `json:"uploadProxyURL,omitempty"`
+}
+
+// IsKubevirt reports whether the virtual machines are migrated to KubeVirt rather than OpenStack
+func (d MigrationTemplateDestination) IsKubevirt() bool {
+	return d.Type == DestinationTypeKubevirt
+}
+
+// VDDKTransportMode is a way VDDK reads a source disk when it is copied over NBD
+// +kubebuilder:validation:Enum=file;san;hotadd;nbdssl;nbd
+type VDDKTransportMode string
+
+const (
+	// VDDKTransportModeFile reads a VMDK file local to the agent
+	VDDKTransportModeFile VDDKTransportMode = "file"
+	// VDDKTransportModeSAN reads the datastore LUN directly, over fibre channel or
+	// iSCSI from the agent
+	VDDKTransportModeSAN VDDKTransportMode = "san"
+	// VDDKTransportModeHotAdd attaches the disk to the agent, which has to be a VM
+	// in the vCenter of the source VM
+	VDDKTransportModeHotAdd VDDKTransportMode = "hotadd"
+	// VDDKTransportModeNBDSSL reads the disk from the ESXi host over encrypted NFC
+	VDDKTransportModeNBDSSL VDDKTransportMode = "nbdssl"
+	// VDDKTransportModeNBD reads the disk from the ESXi host over NFC
+	VDDKTransportModeNBD VDDKTransportMode = "nbd"
+)
+
+// VDDKCompression is how the ESXi host compresses the data of the nbd and nbdssl
+// transport modes
+// +kubebuilder:validation:Enum=none;zlib;fastlz;skipz
+type VDDKCompression string
+
+const (
+	// VDDKCompressionNone sends the data uncompressed
+	VDDKCompressionNone VDDKCompression = "none"
+	// VDDKCompressionZlib compresses best at the highest cost on the ESXi host
+	VDDKCompressionZlib VDDKCompression = "zlib"
+	// VDDKCompressionFastLZ compresses fast, the default
+	VDDKCompressionFastLZ VDDKCompression = "fastlz"
+	// VDDKCompressionSkipZ only skips zeroed blocks
+	VDDKCompressionSkipZ VDDKCompression = "skipz"
+)
+
+// VDDKTransport selects how the source disks are read through VDDK when they
+// are copied over NBD, including the changed blocks of a warm
+// StorageAcceleratedCopy. It does not apply to HotAdd.
+type VDDKTransport struct {
+	// Modes are the transport modes VDDK tries, in order, falling back to the
+	// next one when a mode is not available for a disk. Defaults to file,
+	// nbdssl, nbd. A mode the agent can never use fails the migration when it
+	// is validated.
+	// +kubebuilder:validation:MinItems=1
+	// +optional
+	Modes []VDDKTransportMode `json:"modes,omitempty"`
+	// Compression is the compression of the nbd and nbdssl modes, fastlz when empty
+	// +optional
+	Compression VDDKCompression `json:"compression,omitempty"`
 }
 
 // MigrationTemplateSpec defines the desired state of MigrationTemplate including source/destination environments and mappings
@@ -71,6 +161,10 @@ type MigrationTemplateSpec struct {
 	// UseGPUFlavor indicates if the migration should filter and use GPU-enabled flavors.
 	// +optional
 	UseGPUFlavor bool `json:"useGPUFlavor,omitempty"`
+	// VDDKTransport selects the VDDK transport modes and compression of the NBD
+	// copy. A MigrationPlan can override it in its advanced options.
+	// +optional
+	VDDKTransport *VDDKTransport `json:"vddkTransport,omitempty"`
 }
 
 // +kubebuilder:object:root=true
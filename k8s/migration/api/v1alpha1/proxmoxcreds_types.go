@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProxmoxCredsSpec defines the desired state of ProxmoxCreds
+type ProxmoxCredsSpec struct {
+	// APIURL is the URL of the Proxmox VE API of any node of the cluster,
+	// e.g. https://pve01.example.com:8006
+	// +kubebuilder:validation:Pattern=`^https?://`
+	APIURL string `json:"apiUrl"`
+
+	// SecretRef is the reference to the Kubernetes secret holding the credentials.
+	// The secret should contain "tokenId" (e.g. root@pam!vjailbreak) and
+	// "tokenSecret" for the API, and "privateKey" for SSH as root to the nodes,
+	// where qemu-nbd serves the disks. It must live in the namespace of the
+	// ProxmoxCreds so migration pods can read it.
+	SecretRef corev1.ObjectReference `json:"secretRef"`
+
+	// Insecure skips verification of the API certificate, which is self-signed
+	// on a default installation
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// ProxmoxCredsStatus defines the observed state of ProxmoxCreds
+type ProxmoxCredsStatus struct {
+	// ValidationStatus is the status of the API check
+	// Possible values: Validating, Succeeded, Failed
+	ValidationStatus string `json:"validationStatus,omitempty"`
+
+	// ValidationMessage is the message associated with the validation
+	ValidationMessage string `json:"validationMessage,omitempty"`
+
+	// Version is the Proxmox VE version reported by the API
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// LastValidationTime is the timestamp of the last validation run
+	LastValidationTime metav1.Time `json:"lastValidationTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.spec.apiUrl`,name=API URL,type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.version`,name=Version,type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.validationStatus`,name=Status,type=string
+
+// ProxmoxCreds is the Schema for the proxmoxcreds API that defines how to reach a
+// Proxmox VE cluster. The controller validates the API token and records the
+// QEMU VMs of the cluster as ProxmoxMachines.
+type ProxmoxCreds struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProxmoxCredsSpec   `json:"spec,omitempty"`
+	Status ProxmoxCredsStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProxmoxCredsList contains a list of ProxmoxCreds
+type ProxmoxCredsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProxmoxCreds `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProxmoxCreds{}, &ProxmoxCredsList{})
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProxmoxDisk is a disk of a Proxmox VM
+type ProxmoxDisk struct {
+	// Key is the configuration key of the disk, e.g. scsi0
+	Key string `json:"key"`
+	// Volume is the volume id, e.g. local-lvm:vm-100-disk-0
+	Volume string `json:"volume"`
+	// SizeBytes is the size of the disk in bytes
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+	// Bus is ide, sata, scsi or virtio
+	Bus string `json:"bus,omitempty"`
+}
+
+// ProxmoxNIC is a network device of a Proxmox VM
+type ProxmoxNIC struct {
+	// Key is the configuration key of the device, e.g. net0
+	Key string `json:"key"`
+	// Model is the emulated device, e.g. virtio or e1000
+	Model string `json:"model,omitempty"`
+	// MAC is the MAC address of the device
+	MAC string `json:"mac,omitempty"`
+	// Bridge is the Linux bridge the device is attached to
+	Bridge string `json:"bridge,omitempty"`
+}
+
+// ProxmoxVMInfo is what a migration needs to know about a Proxmox VM
+type ProxmoxVMInfo struct {
+	// VMID is the id of the VM in the cluster
+	VMID int `json:"vmid"`
+	// Name is the name of the VM
+	Name string `json:"name"`
+	// Node is the cluster node the VM is on
+	Node string `json:"node"`
+	// VMState is the status of the VM, e.g. running or stopped
+	VMState string `json:"vmState,omitempty"`
+	// CPU is the number of vCPUs, sockets times cores
+	CPU int `json:"cpu,omitempty"`
+	// MemoryMB is the memory of the VM in MiB
+	MemoryMB int `json:"memoryMB,omitempty"`
+	// UEFI is true when the VM boots with OVMF
+	UEFI bool `json:"uefi,omitempty"`
+	// OSType is the Proxmox ostype, e.g. l26 or win11
+	OSType string `json:"osType,omitempty"`
+	// OSFamily is windowsGuest or linuxGuest, empty when OSType does not say
+	OSFamily string `json:"osFamily,omitempty"`
+	// Disks are the disks of the VM, the boot disk first
+	Disks []ProxmoxDisk `json:"disks,omitempty"`
+	// NICs are the network devices of the VM
+	NICs []ProxmoxNIC `json:"nics,omitempty"`
+}
+
+// ProxmoxMachineSpec defines the desired state of ProxmoxMachine
+type ProxmoxMachineSpec struct {
+	// VMInfo is the VM as last read from the Proxmox VE API
+	VMInfo ProxmoxVMInfo `json:"vmInfo"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:JSONPath=`.spec.vmInfo.vmid`,name=VMID,type=integer
+// +kubebuilder:printcolumn:JSONPath=`.spec.vmInfo.name`,name=VM Name,type=string
+// +kubebuilder:printcolumn:JSONPath=`.spec.vmInfo.node`,name=Node,type=string
+// +kubebuilder:printcolumn:JSONPath=`.spec.vmInfo.vmState`,name=State,type=string
+
+// ProxmoxMachine is the Schema for the proxmoxmachines API. It is the Proxmox
+// counterpart of a VMwareMachine, created and kept up to date by the controller
+// of its ProxmoxCreds.
+type ProxmoxMachine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ProxmoxMachineSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProxmoxMachineList contains a list of ProxmoxMachine
+type ProxmoxMachineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProxmoxMachine `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProxmoxMachine{}, &ProxmoxMachineList{})
+}
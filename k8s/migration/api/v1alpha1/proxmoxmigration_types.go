@@ -0,0 +1,61 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProxmoxMigrationSpec defines the desired state of ProxmoxMigration
+type ProxmoxMigrationSpec struct {
+	// ProxmoxMachineRef is the VM to migrate. It must be stopped, its disks are
+	// copied as they are.
+	ProxmoxMachineRef corev1.LocalObjectReference `json:"proxmoxMachineRef"`
+
+	ImportTargetSpec `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:JSONPath=`.spec.proxmoxMachineRef.name`,name=Machine,type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.phase`,name=Phase,type=string
+
+// ProxmoxMigration is the Schema for the proxmoxmigrations API. It migrates a
+// VM from a Proxmox VE cluster into OpenStack. The disks are served from their
+// node with qemu-nbd and copied over NBD, and guests that already boot from
+// virtio disks skip the virt-v2v conversion.
+type ProxmoxMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProxmoxMigrationSpec `json:"spec,omitempty"`
+	Status ImportStatus         `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProxmoxMigrationList contains a list of ProxmoxMigration
+type ProxmoxMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProxmoxMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProxmoxMigration{}, &ProxmoxMigrationList{})
+}
@@ -21,6 +21,19 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// VMwareHostType is the kind of VMware endpoint a VMwareCreds points at
+// +kubebuilder:validation:Enum=vcenter;esxi
+type VMwareHostType string
+
+const (
+	// VMwareHostTypeVCenter is a vCenter Server, the default
+	VMwareHostTypeVCenter VMwareHostType = "vcenter"
+	// VMwareHostTypeESXi is a standalone ESXi host not managed by a vCenter.
+	// VMs on it are migrated cold: they are powered off and their disks are
+	// copied over SSH with the key from the esxi-ssh-key secret.
+	VMwareHostTypeESXi VMwareHostType = "esxi"
+)
+
 // VMwareCredsInfo holds the actual VMware credentials after decoding from secret
 type VMwareCredsInfo struct {
 	// Host is the vCenter host
@@ -43,6 +56,10 @@ type VMwareCredsSpec struct {
 	SecretRef corev1.ObjectReference `json:"secretRef,omitempty"`
 	// VcenterHost is the vCenter host
 	VcenterHost string `json:"vcenterHost,omitempty"`
+	// HostType is vcenter (default) or esxi for a standalone ESXi host. For esxi
+	// the secret's VCENTER_HOST holds the ESXi host
+	// +optional
+	HostType VMwareHostType `json:"hostType,omitempty"`
 }
 
 // VMwareCredsStatus defines the observed state of VMwareCreds
@@ -71,6 +88,11 @@ type VMwareCreds struct {
 	Status VMwareCredsStatus `json:"status,omitempty"`
 }
 
+// IsStandaloneESXi reports whether the credentials point at a standalone ESXi host
+func (v *VMwareCreds) IsStandaloneESXi() bool {
+	return v.Spec.HostType == VMwareHostTypeESXi
+}
+
 // +kubebuilder:object:root=true
 
 // VMwareCredsList contains a list of VMwareCreds
@@ -58,6 +58,8 @@ type VMInfo struct {
 	VMState string `json:"vmState,omitempty"`
 	// OSFamily is the OS family of the virtual machine
 	OSFamily string `json:"osFamily,omitempty"`
+	// GuestOS is the guest operating system as configured in vCenter, e.g. "Ubuntu Linux (64-bit)"
+	GuestOS string `json:"guestOS,omitempty"`
 	// CPU is the number of CPUs in the virtual machine
 	CPU int `json:"cpu,omitempty"`
 	// Memory is the amount of memory in the virtual machine
@@ -76,6 +78,8 @@ type VMInfo struct {
 	GuestNetworks []GuestNetwork `json:"guestNetworks,omitempty"`
 	// GPU contains information about GPU devices attached to the VM
 	GPU GPUInfo `json:"gpu,omitempty"`
+	// Hardware is the virtual hardware of the VM that decides how it can be migrated
+	Hardware VMHardware `json:"hardware,omitempty"`
 	// Tags maps vSphere tag category names to a comma-separated list of the
 	// tag names attached to the VM in that category (e.g. "env" -> "production")
 	Tags map[string]string `json:"tags,omitempty"`
@@ -83,6 +87,26 @@ type VMInfo struct {
 	CustomAttributes map[string]string `json:"customAttributes,omitempty"`
 }
 
+// VMHardware is the virtual hardware of a VM that decides how it can be migrated
+type VMHardware struct {
+	// Firmware is the firmware of the VM, bios or efi
+	Firmware string `json:"firmware,omitempty"`
+	// SecureBoot is set when UEFI Secure Boot is enabled
+	SecureBoot bool `json:"secureBoot,omitempty"`
+	// Version is the virtual hardware version, 19 for vmx-19
+	Version int `json:"version,omitempty"`
+	// CBTEnabled is set when Changed Block Tracking is enabled on the VM
+	CBTEnabled bool `json:"cbtEnabled,omitempty"`
+	// SnapshotDepth is the length of the snapshot chain the VM runs on
+	SnapshotDepth int `json:"snapshotDepth,omitempty"`
+	// USBDevices is the number of USB controllers and devices attached to the VM
+	USBDevices int `json:"usbDevices,omitempty"`
+	// SerialPorts is the number of serial ports of the VM
+	SerialPorts int `json:"serialPorts,omitempty"`
+	// IndependentDisks are the disks in independent mode, which VDDK cannot read
+	IndependentDisks []string `json:"independentDisks,omitempty"`
+}
+
 // Disk represents a virtual disk attached to a virtual machine
 type Disk struct {
 	Name        string `json:"name,omitempty"`
@@ -54,6 +54,21 @@ func (in *AdvancedOptions) DeepCopyInto(out *AdvancedOptions) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.BandwidthLimit != nil {
+		in, out := &in.BandwidthLimit, &out.BandwidthLimit
+		*out = new(BandwidthLimit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(IntegrityVerification)
+		**out = **in
+	}
+	if in.VDDKTransport != nil {
+		in, out := &in.VDDKTransport, &out.VDDKTransport
+		*out = new(VDDKTransport)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvancedOptions.
@@ -66,6 +81,86 @@ func (in *AdvancedOptions) DeepCopy() *AdvancedOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplianceImport) DeepCopyInto(out *ApplianceImport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplianceImport.
+func (in *ApplianceImport) DeepCopy() *ApplianceImport {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplianceImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplianceImport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplianceImportList) DeepCopyInto(out *ApplianceImportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApplianceImport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplianceImportList.
+func (in *ApplianceImportList) DeepCopy() *ApplianceImportList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplianceImportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApplianceImportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplianceImportSpec) DeepCopyInto(out *ApplianceImportSpec) {
+	*out = *in
+	if in.SourceSecretRef != nil {
+		in, out := &in.SourceSecretRef, &out.SourceSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	in.ImportTargetSpec.DeepCopyInto(&out.ImportTargetSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplianceImportSpec.
+func (in *ApplianceImportSpec) DeepCopy() *ApplianceImportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplianceImportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArrayCreds) DeepCopyInto(out *ArrayCreds) {
 	*out = *in
@@ -244,6 +339,21 @@ func (in *ArrayCredsSpec) DeepCopyInto(out *ArrayCredsSpec) {
 		*out = new(NetAppConfig)
 		**out = **in
 	}
+	if in.PowerStoreConfig != nil {
+		in, out := &in.PowerStoreConfig, &out.PowerStoreConfig
+		*out = new(PowerStoreConfig)
+		**out = **in
+	}
+	if in.HPEAlletraConfig != nil {
+		in, out := &in.HPEAlletraConfig, &out.HPEAlletraConfig
+		*out = new(HPEAlletraConfig)
+		**out = **in
+	}
+	if in.SimulatorConfig != nil {
+		in, out := &in.SimulatorConfig, &out.SimulatorConfig
+		*out = new(SimulatorConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArrayCredsSpec.
@@ -283,6 +393,36 @@ func (in *ArrayCredsStatus) DeepCopy() *ArrayCredsStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssessmentIssueCount) DeepCopyInto(out *AssessmentIssueCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssessmentIssueCount.
+func (in *AssessmentIssueCount) DeepCopy() *AssessmentIssueCount {
+	if in == nil {
+		return nil
+	}
+	out := new(AssessmentIssueCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssessmentSummary) DeepCopyInto(out *AssessmentSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssessmentSummary.
+func (in *AssessmentSummary) DeepCopy() *AssessmentSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(AssessmentSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BMConfig) DeepCopyInto(out *BMConfig) {
 	*out = *in
@@ -409,6 +549,41 @@ func (in *BackendTargetGroup) DeepCopy() *BackendTargetGroup {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthLimit) DeepCopyInto(out *BandwidthLimit) {
+	*out = *in
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = make([]BandwidthWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthLimit.
+func (in *BandwidthLimit) DeepCopy() *BandwidthLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthWindow) DeepCopyInto(out *BandwidthWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthWindow.
+func (in *BandwidthWindow) DeepCopy() *BandwidthWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootSource) DeepCopyInto(out *BootSource) {
 	*out = *in
@@ -556,6 +731,29 @@ func (in *ClusterMigrationStatus) DeepCopy() *ClusterMigrationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CutoverReadiness) DeepCopyInto(out *CutoverReadiness) {
+	*out = *in
+	out.PredictedDowntime = in.PredictedDowntime
+	out.DowntimeTarget = in.DowntimeTarget
+	if in.NextSyncInterval != nil {
+		in, out := &in.NextSyncInterval, &out.NextSyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	in.UpdatedAt.DeepCopyInto(&out.UpdatedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CutoverReadiness.
+func (in *CutoverReadiness) DeepCopy() *CutoverReadiness {
+	if in == nil {
+		return nil
+	}
+	out := new(CutoverReadiness)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatastoreArrayCredsMapping) DeepCopyInto(out *DatastoreArrayCredsMapping) {
 	*out = *in
@@ -601,6 +799,57 @@ func (in *Disk) DeepCopy() *Disk {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskCopyProgress) DeepCopyInto(out *DiskCopyProgress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskCopyProgress.
+func (in *DiskCopyProgress) DeepCopy() *DiskCopyProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskCopyProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskTransfer) DeepCopyInto(out *DiskTransfer) {
+	*out = *in
+	in.UpdatedAt.DeepCopyInto(&out.UpdatedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskTransfer.
+func (in *DiskTransfer) DeepCopy() *DiskTransfer {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskTransfer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskVerification) DeepCopyInto(out *DiskVerification) {
+	*out = *in
+	if in.MismatchedRanges != nil {
+		in, out := &in.MismatchedRanges, &out.MismatchedRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskVerification.
+func (in *DiskVerification) DeepCopy() *DiskVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ESXIMigration) DeepCopyInto(out *ESXIMigration) {
 	*out = *in
@@ -809,6 +1058,26 @@ func (in *ESXiSSHCredsStatus) DeepCopy() *ESXiSSHCredsStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportOptions) DeepCopyInto(out *ExportOptions) {
+	*out = *in
+	if in.Compress != nil {
+		in, out := &in.Compress, &out.Compress
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportOptions.
+func (in *ExportOptions) DeepCopy() *ExportOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GPUInfo) DeepCopyInto(out *GPUInfo) {
 	*out = *in
@@ -844,6 +1113,36 @@ func (in *GuestNetwork) DeepCopy() *GuestNetwork {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestStorageFinding) DeepCopyInto(out *GuestStorageFinding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestStorageFinding.
+func (in *GuestStorageFinding) DeepCopy() *GuestStorageFinding {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestStorageFinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HPEAlletraConfig) DeepCopyInto(out *HPEAlletraConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HPEAlletraConfig.
+func (in *HPEAlletraConfig) DeepCopy() *HPEAlletraConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HPEAlletraConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostConfig) DeepCopyInto(out *HostConfig) {
 	*out = *in
@@ -867,84 +1166,134 @@ func (in *HostConfig) DeepCopy() *HostConfig {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Migration) DeepCopyInto(out *Migration) {
+func (in *ImportStatus) DeepCopyInto(out *ImportStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Migration.
-func (in *Migration) DeepCopy() *Migration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportStatus.
+func (in *ImportStatus) DeepCopy() *ImportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Migration)
+	out := new(ImportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Migration) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationBlueprint) DeepCopyInto(out *MigrationBlueprint) {
+func (in *ImportTargetSpec) DeepCopyInto(out *ImportTargetSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.OpenstackCredsRef = in.OpenstackCredsRef
+	if in.NetworkNames != nil {
+		in, out := &in.NetworkNames, &out.NetworkNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VolumeTypes != nil {
+		in, out := &in.VolumeTypes, &out.VolumeTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationBlueprint.
-func (in *MigrationBlueprint) DeepCopy() *MigrationBlueprint {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImportTargetSpec.
+func (in *ImportTargetSpec) DeepCopy() *ImportTargetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationBlueprint)
+	out := new(ImportTargetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigrationBlueprint) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtDestination) DeepCopyInto(out *KubevirtDestination) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtDestination.
+func (in *KubevirtDestination) DeepCopy() *KubevirtDestination {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(KubevirtDestination)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationBlueprintList) DeepCopyInto(out *MigrationBlueprintList) {
+func (in *IntegrityVerification) DeepCopyInto(out *IntegrityVerification) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrityVerification.
+func (in *IntegrityVerification) DeepCopy() *IntegrityVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(IntegrityVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LibvirtCreds) DeepCopyInto(out *LibvirtCreds) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LibvirtCreds.
+func (in *LibvirtCreds) DeepCopy() *LibvirtCreds {
+	if in == nil {
+		return nil
+	}
+	out := new(LibvirtCreds)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LibvirtCreds) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LibvirtCredsList) DeepCopyInto(out *LibvirtCredsList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]MigrationBlueprint, len(*in))
+		*out = make([]LibvirtCreds, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationBlueprintList.
-func (in *MigrationBlueprintList) DeepCopy() *MigrationBlueprintList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LibvirtCredsList.
+func (in *LibvirtCredsList) DeepCopy() *LibvirtCredsList {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationBlueprintList)
+	out := new(LibvirtCredsList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigrationBlueprintList) DeepCopyObject() runtime.Object {
+func (in *LibvirtCredsList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -952,89 +1301,95 @@ func (in *MigrationBlueprintList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationBlueprintSpec) DeepCopyInto(out *MigrationBlueprintSpec) {
+func (in *LibvirtCredsSpec) DeepCopyInto(out *LibvirtCredsSpec) {
 	*out = *in
-	if in.NetworkMappings != nil {
-		in, out := &in.NetworkMappings, &out.NetworkMappings
-		*out = make([]Network, len(*in))
-		copy(*out, *in)
-	}
-	if in.StorageMappings != nil {
-		in, out := &in.StorageMappings, &out.StorageMappings
-		*out = make([]Storage, len(*in))
-		copy(*out, *in)
-	}
-	if in.ArrayCredsMappings != nil {
-		in, out := &in.ArrayCredsMappings, &out.ArrayCredsMappings
-		*out = make([]DatastoreArrayCredsMapping, len(*in))
-		copy(*out, *in)
-	}
-	if in.ProxyVMRef != nil {
-		in, out := &in.ProxyVMRef, &out.ProxyVMRef
-		*out = new(v1.LocalObjectReference)
-		**out = **in
-	}
-	if in.MigrationStrategy != nil {
-		in, out := &in.MigrationStrategy, &out.MigrationStrategy
-		*out = new(MigrationPlanStrategy)
-		(*in).DeepCopyInto(*out)
-	}
-	in.AdvancedOptions.DeepCopyInto(&out.AdvancedOptions)
-	if in.PostMigrationAction != nil {
-		in, out := &in.PostMigrationAction, &out.PostMigrationAction
-		*out = new(PostMigrationAction)
-		(*in).DeepCopyInto(*out)
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LibvirtCredsSpec.
+func (in *LibvirtCredsSpec) DeepCopy() *LibvirtCredsSpec {
+	if in == nil {
+		return nil
 	}
-	if in.SecurityGroups != nil {
-		in, out := &in.SecurityGroups, &out.SecurityGroups
+	out := new(LibvirtCredsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LibvirtCredsStatus) DeepCopyInto(out *LibvirtCredsStatus) {
+	*out = *in
+	if in.Domains != nil {
+		in, out := &in.Domains, &out.Domains
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.CustomMetadata != nil {
-		in, out := &in.CustomMetadata, &out.CustomMetadata
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	in.LastValidationTime.DeepCopyInto(&out.LastValidationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LibvirtCredsStatus.
+func (in *LibvirtCredsStatus) DeepCopy() *LibvirtCredsStatus {
+	if in == nil {
+		return nil
 	}
+	out := new(LibvirtCredsStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationBlueprintSpec.
-func (in *MigrationBlueprintSpec) DeepCopy() *MigrationBlueprintSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LibvirtMigration) DeepCopyInto(out *LibvirtMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LibvirtMigration.
+func (in *LibvirtMigration) DeepCopy() *LibvirtMigration {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationBlueprintSpec)
+	out := new(LibvirtMigration)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LibvirtMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationList) DeepCopyInto(out *MigrationList) {
+func (in *LibvirtMigrationList) DeepCopyInto(out *LibvirtMigrationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Migration, len(*in))
+		*out = make([]LibvirtMigration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationList.
-func (in *MigrationList) DeepCopy() *MigrationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LibvirtMigrationList.
+func (in *LibvirtMigrationList) DeepCopy() *LibvirtMigrationList {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationList)
+	out := new(LibvirtMigrationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigrationList) DeepCopyObject() runtime.Object {
+func (in *LibvirtMigrationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1042,26 +1397,43 @@ func (in *MigrationList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationPlan) DeepCopyInto(out *MigrationPlan) {
+func (in *LibvirtMigrationSpec) DeepCopyInto(out *LibvirtMigrationSpec) {
+	*out = *in
+	out.LibvirtCredsRef = in.LibvirtCredsRef
+	in.ImportTargetSpec.DeepCopyInto(&out.ImportTargetSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LibvirtMigrationSpec.
+func (in *LibvirtMigrationSpec) DeepCopy() *LibvirtMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LibvirtMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Migration) DeepCopyInto(out *Migration) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlan.
-func (in *MigrationPlan) DeepCopy() *MigrationPlan {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Migration.
+func (in *Migration) DeepCopy() *Migration {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationPlan)
+	out := new(Migration)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigrationPlan) DeepCopyObject() runtime.Object {
+func (in *Migration) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1069,31 +1441,26 @@ func (in *MigrationPlan) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationPlanList) DeepCopyInto(out *MigrationPlanList) {
+func (in *MigrationAssessment) DeepCopyInto(out *MigrationAssessment) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]MigrationPlan, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlanList.
-func (in *MigrationPlanList) DeepCopy() *MigrationPlanList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationAssessment.
+func (in *MigrationAssessment) DeepCopy() *MigrationAssessment {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationPlanList)
+	out := new(MigrationAssessment)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigrationPlanList) DeepCopyObject() runtime.Object {
+func (in *MigrationAssessment) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1101,122 +1468,814 @@ func (in *MigrationPlanList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationPlanSpec) DeepCopyInto(out *MigrationPlanSpec) {
+func (in *MigrationAssessmentList) DeepCopyInto(out *MigrationAssessmentList) {
 	*out = *in
-	in.MigrationPlanSpecPerVM.DeepCopyInto(&out.MigrationPlanSpecPerVM)
-	if in.VirtualMachines != nil {
-		in, out := &in.VirtualMachines, &out.VirtualMachines
-		*out = make([][]string, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MigrationAssessment, len(*in))
 		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = make([]string, len(*in))
-				copy(*out, *in)
-			}
-		}
-	}
-	if in.SecurityGroups != nil {
-		in, out := &in.SecurityGroups, &out.SecurityGroups
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.NetworkOverridesPerVM != nil {
-		in, out := &in.NetworkOverridesPerVM, &out.NetworkOverridesPerVM
-		*out = make(map[string][]NICOverride, len(*in))
-		for key, val := range *in {
-			var outVal []NICOverride
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = make([]NICOverride, len(*in))
-				for i := range *in {
-					(*in)[i].DeepCopyInto(&(*out)[i])
-				}
-			}
-			(*out)[key] = outVal
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlanSpec.
-func (in *MigrationPlanSpec) DeepCopy() *MigrationPlanSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationAssessmentList.
+func (in *MigrationAssessmentList) DeepCopy() *MigrationAssessmentList {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationPlanSpec)
+	out := new(MigrationAssessmentList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationAssessmentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationPlanSpecPerVM) DeepCopyInto(out *MigrationPlanSpecPerVM) {
+func (in *MigrationAssessmentSpec) DeepCopyInto(out *MigrationAssessmentSpec) {
 	*out = *in
-	in.MigrationStrategy.DeepCopyInto(&out.MigrationStrategy)
-	in.AdvancedOptions.DeepCopyInto(&out.AdvancedOptions)
-	if in.PostMigrationAction != nil {
-		in, out := &in.PostMigrationAction, &out.PostMigrationAction
-		*out = new(PostMigrationAction)
-		(*in).DeepCopyInto(*out)
+	out.VMwareCredsRef = in.VMwareCredsRef
+	if in.OpenstackCredsRef != nil {
+		in, out := &in.OpenstackCredsRef, &out.OpenstackCredsRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
 	}
-	if in.CustomMetadata != nil {
-		in, out := &in.CustomMetadata, &out.CustomMetadata
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.VMs != nil {
+		in, out := &in.VMs, &out.VMs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlanSpecPerVM.
-func (in *MigrationPlanSpecPerVM) DeepCopy() *MigrationPlanSpecPerVM {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationAssessmentSpec.
+func (in *MigrationAssessmentSpec) DeepCopy() *MigrationAssessmentSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationPlanSpecPerVM)
+	out := new(MigrationAssessmentSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationPlanStatus) DeepCopyInto(out *MigrationPlanStatus) {
+func (in *MigrationAssessmentStatus) DeepCopyInto(out *MigrationAssessmentStatus) {
 	*out = *in
+	if in.AssessedAt != nil {
+		in, out := &in.AssessedAt, &out.AssessedAt
+		*out = (*in).DeepCopy()
+	}
+	out.Summary = in.Summary
+	if in.Issues != nil {
+		in, out := &in.Issues, &out.Issues
+		*out = make([]AssessmentIssueCount, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlanStatus.
-func (in *MigrationPlanStatus) DeepCopy() *MigrationPlanStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationAssessmentStatus.
+func (in *MigrationAssessmentStatus) DeepCopy() *MigrationAssessmentStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationPlanStatus)
+	out := new(MigrationAssessmentStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationPlanStrategy) DeepCopyInto(out *MigrationPlanStrategy) {
+func (in *MigrationBlueprint) DeepCopyInto(out *MigrationBlueprint) {
 	*out = *in
-	in.DataCopyStart.DeepCopyInto(&out.DataCopyStart)
-	in.VMCutoverStart.DeepCopyInto(&out.VMCutoverStart)
-	in.VMCutoverEnd.DeepCopyInto(&out.VMCutoverEnd)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlanStrategy.
-func (in *MigrationPlanStrategy) DeepCopy() *MigrationPlanStrategy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationBlueprint.
+func (in *MigrationBlueprint) DeepCopy() *MigrationBlueprint {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationPlanStrategy)
+	out := new(MigrationBlueprint)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationSpec) DeepCopyInto(out *MigrationSpec) {
-	*out = *in
-	if in.ImageMetadata != nil {
-		in, out := &in.ImageMetadata, &out.ImageMetadata
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationBlueprint) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationBlueprintList) DeepCopyInto(out *MigrationBlueprintList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MigrationBlueprint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationBlueprintList.
+func (in *MigrationBlueprintList) DeepCopy() *MigrationBlueprintList {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationBlueprintList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationBlueprintList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationBlueprintSpec) DeepCopyInto(out *MigrationBlueprintSpec) {
+	*out = *in
+	if in.NetworkMappings != nil {
+		in, out := &in.NetworkMappings, &out.NetworkMappings
+		*out = make([]Network, len(*in))
+		copy(*out, *in)
+	}
+	if in.StorageMappings != nil {
+		in, out := &in.StorageMappings, &out.StorageMappings
+		*out = make([]Storage, len(*in))
+		copy(*out, *in)
+	}
+	if in.ArrayCredsMappings != nil {
+		in, out := &in.ArrayCredsMappings, &out.ArrayCredsMappings
+		*out = make([]DatastoreArrayCredsMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProxyVMRef != nil {
+		in, out := &in.ProxyVMRef, &out.ProxyVMRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.MigrationStrategy != nil {
+		in, out := &in.MigrationStrategy, &out.MigrationStrategy
+		*out = new(MigrationPlanStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	in.AdvancedOptions.DeepCopyInto(&out.AdvancedOptions)
+	if in.PostMigrationAction != nil {
+		in, out := &in.PostMigrationAction, &out.PostMigrationAction
+		*out = new(PostMigrationAction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CustomMetadata != nil {
+		in, out := &in.CustomMetadata, &out.CustomMetadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationBlueprintSpec.
+func (in *MigrationBlueprintSpec) DeepCopy() *MigrationBlueprintSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationBlueprintSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationList) DeepCopyInto(out *MigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Migration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationList.
+func (in *MigrationList) DeepCopy() *MigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPlan) DeepCopyInto(out *MigrationPlan) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlan.
+func (in *MigrationPlan) DeepCopy() *MigrationPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationPlan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPlanList) DeepCopyInto(out *MigrationPlanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MigrationPlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlanList.
+func (in *MigrationPlanList) DeepCopy() *MigrationPlanList {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPlanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationPlanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPlanSpec) DeepCopyInto(out *MigrationPlanSpec) {
+	*out = *in
+	in.MigrationPlanSpecPerVM.DeepCopyInto(&out.MigrationPlanSpecPerVM)
+	if in.VirtualMachines != nil {
+		in, out := &in.VirtualMachines, &out.VirtualMachines
+		*out = make([][]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+		}
+	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NetworkOverridesPerVM != nil {
+		in, out := &in.NetworkOverridesPerVM, &out.NetworkOverridesPerVM
+		*out = make(map[string][]NICOverride, len(*in))
+		for key, val := range *in {
+			var outVal []NICOverride
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]NICOverride, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlanSpec.
+func (in *MigrationPlanSpec) DeepCopy() *MigrationPlanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPlanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPlanSpecPerVM) DeepCopyInto(out *MigrationPlanSpecPerVM) {
+	*out = *in
+	in.MigrationStrategy.DeepCopyInto(&out.MigrationStrategy)
+	in.AdvancedOptions.DeepCopyInto(&out.AdvancedOptions)
+	if in.PostMigrationAction != nil {
+		in, out := &in.PostMigrationAction, &out.PostMigrationAction
+		*out = new(PostMigrationAction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CustomMetadata != nil {
+		in, out := &in.CustomMetadata, &out.CustomMetadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlanSpecPerVM.
+func (in *MigrationPlanSpecPerVM) DeepCopy() *MigrationPlanSpecPerVM {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPlanSpecPerVM)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPlanStatus) DeepCopyInto(out *MigrationPlanStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlanStatus.
+func (in *MigrationPlanStatus) DeepCopy() *MigrationPlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPlanStrategy) DeepCopyInto(out *MigrationPlanStrategy) {
+	*out = *in
+	in.DataCopyStart.DeepCopyInto(&out.DataCopyStart)
+	in.VMCutoverStart.DeepCopyInto(&out.VMCutoverStart)
+	in.VMCutoverEnd.DeepCopyInto(&out.VMCutoverEnd)
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = new(ExportOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPlanStrategy.
+func (in *MigrationPlanStrategy) DeepCopy() *MigrationPlanStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPlanStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationSpec) DeepCopyInto(out *MigrationSpec) {
+	*out = *in
+	if in.ImageMetadata != nil {
+		in, out := &in.ImageMetadata, &out.ImageMetadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Export != nil {
+		in, out := &in.Export, &out.Export
+		*out = new(ExportOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationSpec.
+func (in *MigrationSpec) DeepCopy() *MigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationStatus) DeepCopyInto(out *MigrationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.PodCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Retryable != nil {
+		in, out := &in.Retryable, &out.Retryable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.StagedVolumeIDs != nil {
+		in, out := &in.StagedVolumeIDs, &out.StagedVolumeIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExportedImages != nil {
+		in, out := &in.ExportedImages, &out.ExportedImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiskVerification != nil {
+		in, out := &in.DiskVerification, &out.DiskVerification
+		*out = make([]DiskVerification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DiskProgress != nil {
+		in, out := &in.DiskProgress, &out.DiskProgress
+		*out = make([]DiskCopyProgress, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiskTransfers != nil {
+		in, out := &in.DiskTransfers, &out.DiskTransfers
+		*out = make([]DiskTransfer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CutoverReadiness != nil {
+		in, out := &in.CutoverReadiness, &out.CutoverReadiness
+		*out = new(CutoverReadiness)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GuestStorage != nil {
+		in, out := &in.GuestStorage, &out.GuestStorage
+		*out = make([]GuestStorageFinding, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationStatus.
+func (in *MigrationStatus) DeepCopy() *MigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationTemplate) DeepCopyInto(out *MigrationTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTemplate.
+func (in *MigrationTemplate) DeepCopy() *MigrationTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationTemplateDestination) DeepCopyInto(out *MigrationTemplateDestination) {
+	*out = *in
+	if in.Kubevirt != nil {
+		in, out := &in.Kubevirt, &out.Kubevirt
+		*out = new(KubevirtDestination)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTemplateDestination.
+func (in *MigrationTemplateDestination) DeepCopy() *MigrationTemplateDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationTemplateDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationTemplateList) DeepCopyInto(out *MigrationTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MigrationTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTemplateList.
+func (in *MigrationTemplateList) DeepCopy() *MigrationTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationTemplateSource) DeepCopyInto(out *MigrationTemplateSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTemplateSource.
+func (in *MigrationTemplateSource) DeepCopy() *MigrationTemplateSource {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationTemplateSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationTemplateSpec) DeepCopyInto(out *MigrationTemplateSpec) {
+	*out = *in
+	if in.ProxyVMRef != nil {
+		in, out := &in.ProxyVMRef, &out.ProxyVMRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	out.Source = in.Source
+	in.Destination.DeepCopyInto(&out.Destination)
+	if in.VDDKTransport != nil {
+		in, out := &in.VDDKTransport, &out.VDDKTransport
+		*out = new(VDDKTransport)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTemplateSpec.
+func (in *MigrationTemplateSpec) DeepCopy() *MigrationTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NIC) DeepCopyInto(out *NIC) {
+	*out = *in
+	if in.IPAddress != nil {
+		in, out := &in.IPAddress, &out.IPAddress
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NIC.
+func (in *NIC) DeepCopy() *NIC {
+	if in == nil {
+		return nil
+	}
+	out := new(NIC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NICOverride) DeepCopyInto(out *NICOverride) {
+	*out = *in
+	if in.PreserveIP != nil {
+		in, out := &in.PreserveIP, &out.PreserveIP
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PreserveMAC != nil {
+		in, out := &in.PreserveMAC, &out.PreserveMAC
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NICOverride.
+func (in *NICOverride) DeepCopy() *NICOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(NICOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetAppConfig) DeepCopyInto(out *NetAppConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetAppConfig.
+func (in *NetAppConfig) DeepCopy() *NetAppConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NetAppConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Network) DeepCopyInto(out *Network) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Network.
+func (in *Network) DeepCopy() *Network {
+	if in == nil {
+		return nil
+	}
+	out := new(Network)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkMapping) DeepCopyInto(out *NetworkMapping) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkMapping.
+func (in *NetworkMapping) DeepCopy() *NetworkMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkMapping) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkMappingList) DeepCopyInto(out *NetworkMappingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkMappingList.
+func (in *NetworkMappingList) DeepCopy() *NetworkMappingList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkMappingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkMappingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkMappingSpec) DeepCopyInto(out *NetworkMappingSpec) {
+	*out = *in
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = make([]Network, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkMappingSpec.
+func (in *NetworkMappingSpec) DeepCopy() *NetworkMappingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkMappingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkMappingStatus) DeepCopyInto(out *NetworkMappingStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkMappingStatus.
+func (in *NetworkMappingStatus) DeepCopy() *NetworkMappingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkMappingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenStackCredsInfo) DeepCopyInto(out *OpenStackCredsInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackCredsInfo.
+func (in *OpenStackCredsInfo) DeepCopy() *OpenStackCredsInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenStackCredsInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenStackVolumeRefInfo) DeepCopyInto(out *OpenStackVolumeRefInfo) {
+	*out = *in
+	if in.VolumeRef != nil {
+		in, out := &in.VolumeRef, &out.VolumeRef
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
@@ -1224,68 +2283,208 @@ func (in *MigrationSpec) DeepCopyInto(out *MigrationSpec) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationSpec.
-func (in *MigrationSpec) DeepCopy() *MigrationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackVolumeRefInfo.
+func (in *OpenStackVolumeRefInfo) DeepCopy() *OpenStackVolumeRefInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationSpec)
+	out := new(OpenStackVolumeRefInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationStatus) DeepCopyInto(out *MigrationStatus) {
+func (in *OpenstackCreds) DeepCopyInto(out *OpenstackCreds) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.PodCondition, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackCreds.
+func (in *OpenstackCreds) DeepCopy() *OpenstackCreds {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenstackCreds)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpenstackCreds) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenstackCredsList) DeepCopyInto(out *OpenstackCredsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpenstackCreds, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackCredsList.
+func (in *OpenstackCredsList) DeepCopy() *OpenstackCredsList {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenstackCredsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpenstackCredsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackCredsSpec.
+func (in *OpenstackCredsSpec) DeepCopy() *OpenstackCredsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenstackCredsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenstackCredsStatus) DeepCopyInto(out *OpenstackCredsStatus) {
+	*out = *in
+	in.Openstack.DeepCopyInto(&out.Openstack)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackCredsStatus.
+func (in *OpenstackCredsStatus) DeepCopy() *OpenstackCredsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenstackCredsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenstackInfo) DeepCopyInto(out *OpenstackInfo) {
+	*out = *in
+	if in.VolumeTypes != nil {
+		in, out := &in.VolumeTypes, &out.VolumeTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VolumeBackends != nil {
+		in, out := &in.VolumeBackends, &out.VolumeBackends
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BackendVolumeTypeMap != nil {
+		in, out := &in.BackendVolumeTypeMap, &out.BackendVolumeTypeMap
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = make([]PCDNetworkInfo, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Retryable != nil {
-		in, out := &in.Retryable, &out.Retryable
-		*out = new(bool)
-		**out = **in
-	}
-	if in.StagedVolumeIDs != nil {
-		in, out := &in.StagedVolumeIDs, &out.StagedVolumeIDs
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]SecurityGroupInfo, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServerGroups != nil {
+		in, out := &in.ServerGroups, &out.ServerGroups
+		*out = make([]ServerGroupInfo, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackInfo.
+func (in *OpenstackInfo) DeepCopy() *OpenstackInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenstackInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenstackMapping) DeepCopyInto(out *OpenstackMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackMapping.
+func (in *OpenstackMapping) DeepCopy() *OpenstackMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenstackMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenstackVolumeRef) DeepCopyInto(out *OpenstackVolumeRef) {
+	*out = *in
+	if in.VolumeRef != nil {
+		in, out := &in.VolumeRef, &out.VolumeRef
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationStatus.
-func (in *MigrationStatus) DeepCopy() *MigrationStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackVolumeRef.
+func (in *OpenstackVolumeRef) DeepCopy() *OpenstackVolumeRef {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationStatus)
+	out := new(OpenstackVolumeRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationTemplate) DeepCopyInto(out *MigrationTemplate) {
+func (in *PCDCluster) DeepCopyInto(out *PCDCluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTemplate.
-func (in *MigrationTemplate) DeepCopy() *MigrationTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDCluster.
+func (in *PCDCluster) DeepCopy() *PCDCluster {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationTemplate)
+	out := new(PCDCluster)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigrationTemplate) DeepCopyObject() runtime.Object {
+func (in *PCDCluster) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1293,46 +2492,31 @@ func (in *MigrationTemplate) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationTemplateDestination) DeepCopyInto(out *MigrationTemplateDestination) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTemplateDestination.
-func (in *MigrationTemplateDestination) DeepCopy() *MigrationTemplateDestination {
-	if in == nil {
-		return nil
-	}
-	out := new(MigrationTemplateDestination)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationTemplateList) DeepCopyInto(out *MigrationTemplateList) {
+func (in *PCDClusterList) DeepCopyInto(out *PCDClusterList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]MigrationTemplate, len(*in))
+		*out = make([]PCDCluster, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTemplateList.
-func (in *MigrationTemplateList) DeepCopy() *MigrationTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDClusterList.
+func (in *PCDClusterList) DeepCopy() *PCDClusterList {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationTemplateList)
+	out := new(PCDClusterList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigrationTemplateList) DeepCopyObject() runtime.Object {
+func (in *PCDClusterList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1340,138 +2524,113 @@ func (in *MigrationTemplateList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationTemplateSource) DeepCopyInto(out *MigrationTemplateSource) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTemplateSource.
-func (in *MigrationTemplateSource) DeepCopy() *MigrationTemplateSource {
-	if in == nil {
-		return nil
-	}
-	out := new(MigrationTemplateSource)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigrationTemplateSpec) DeepCopyInto(out *MigrationTemplateSpec) {
+func (in *PCDClusterSpec) DeepCopyInto(out *PCDClusterSpec) {
 	*out = *in
-	if in.ProxyVMRef != nil {
-		in, out := &in.ProxyVMRef, &out.ProxyVMRef
-		*out = new(v1.LocalObjectReference)
-		**out = **in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	out.Source = in.Source
-	out.Destination = in.Destination
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationTemplateSpec.
-func (in *MigrationTemplateSpec) DeepCopy() *MigrationTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDClusterSpec.
+func (in *PCDClusterSpec) DeepCopy() *PCDClusterSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MigrationTemplateSpec)
+	out := new(PCDClusterSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NIC) DeepCopyInto(out *NIC) {
+func (in *PCDClusterStatus) DeepCopyInto(out *PCDClusterStatus) {
 	*out = *in
-	if in.IPAddress != nil {
-		in, out := &in.IPAddress, &out.IPAddress
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NIC.
-func (in *NIC) DeepCopy() *NIC {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDClusterStatus.
+func (in *PCDClusterStatus) DeepCopy() *PCDClusterStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NIC)
+	out := new(PCDClusterStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NICOverride) DeepCopyInto(out *NICOverride) {
+func (in *PCDHost) DeepCopyInto(out *PCDHost) {
 	*out = *in
-	if in.PreserveIP != nil {
-		in, out := &in.PreserveIP, &out.PreserveIP
-		*out = new(bool)
-		**out = **in
-	}
-	if in.PreserveMAC != nil {
-		in, out := &in.PreserveMAC, &out.PreserveMAC
-		*out = new(bool)
-		**out = **in
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NICOverride.
-func (in *NICOverride) DeepCopy() *NICOverride {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDHost.
+func (in *PCDHost) DeepCopy() *PCDHost {
 	if in == nil {
 		return nil
 	}
-	out := new(NICOverride)
+	out := new(PCDHost)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetAppConfig) DeepCopyInto(out *NetAppConfig) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetAppConfig.
-func (in *NetAppConfig) DeepCopy() *NetAppConfig {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PCDHost) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(NetAppConfig)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Network) DeepCopyInto(out *Network) {
+func (in *PCDHostInterface) DeepCopyInto(out *PCDHostInterface) {
 	*out = *in
+	if in.IPAddresses != nil {
+		in, out := &in.IPAddresses, &out.IPAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Network.
-func (in *Network) DeepCopy() *Network {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDHostInterface.
+func (in *PCDHostInterface) DeepCopy() *PCDHostInterface {
 	if in == nil {
 		return nil
 	}
-	out := new(Network)
+	out := new(PCDHostInterface)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkMapping) DeepCopyInto(out *NetworkMapping) {
+func (in *PCDHostList) DeepCopyInto(out *PCDHostList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PCDHost, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkMapping.
-func (in *NetworkMapping) DeepCopy() *NetworkMapping {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDHostList.
+func (in *PCDHostList) DeepCopy() *PCDHostList {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkMapping)
+	out := new(PCDHostList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NetworkMapping) DeepCopyObject() runtime.Object {
+func (in *PCDHostList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1479,130 +2638,128 @@ func (in *NetworkMapping) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkMappingList) DeepCopyInto(out *NetworkMappingList) {
+func (in *PCDHostSpec) DeepCopyInto(out *PCDHostSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]NetworkMapping, len(*in))
+	if in.RolesAssigned != nil {
+		in, out := &in.RolesAssigned, &out.RolesAssigned
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]PCDHostInterface, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkMappingList.
-func (in *NetworkMappingList) DeepCopy() *NetworkMappingList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDHostSpec.
+func (in *PCDHostSpec) DeepCopy() *PCDHostSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkMappingList)
+	out := new(PCDHostSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NetworkMappingList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkMappingSpec) DeepCopyInto(out *NetworkMappingSpec) {
+func (in *PCDHostStatus) DeepCopyInto(out *PCDHostStatus) {
 	*out = *in
-	if in.Networks != nil {
-		in, out := &in.Networks, &out.Networks
-		*out = make([]Network, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkMappingSpec.
-func (in *NetworkMappingSpec) DeepCopy() *NetworkMappingSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDHostStatus.
+func (in *PCDHostStatus) DeepCopy() *PCDHostStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkMappingSpec)
+	out := new(PCDHostStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkMappingStatus) DeepCopyInto(out *NetworkMappingStatus) {
+func (in *PCDNetworkInfo) DeepCopyInto(out *PCDNetworkInfo) {
 	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkMappingStatus.
-func (in *NetworkMappingStatus) DeepCopy() *NetworkMappingStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDNetworkInfo.
+func (in *PCDNetworkInfo) DeepCopy() *PCDNetworkInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkMappingStatus)
+	out := new(PCDNetworkInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OpenStackCredsInfo) DeepCopyInto(out *OpenStackCredsInfo) {
+func (in *PostMigrationAction) DeepCopyInto(out *PostMigrationAction) {
 	*out = *in
+	if in.RenameVM != nil {
+		in, out := &in.RenameVM, &out.RenameVM
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MoveToFolder != nil {
+		in, out := &in.MoveToFolder, &out.MoveToFolder
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackCredsInfo.
-func (in *OpenStackCredsInfo) DeepCopy() *OpenStackCredsInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostMigrationAction.
+func (in *PostMigrationAction) DeepCopy() *PostMigrationAction {
 	if in == nil {
 		return nil
 	}
-	out := new(OpenStackCredsInfo)
+	out := new(PostMigrationAction)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OpenStackVolumeRefInfo) DeepCopyInto(out *OpenStackVolumeRefInfo) {
+func (in *PowerStoreConfig) DeepCopyInto(out *PowerStoreConfig) {
 	*out = *in
-	if in.VolumeRef != nil {
-		in, out := &in.VolumeRef, &out.VolumeRef
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenStackVolumeRefInfo.
-func (in *OpenStackVolumeRefInfo) DeepCopy() *OpenStackVolumeRefInfo {
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerStoreConfig.
+func (in *PowerStoreConfig) DeepCopy() *PowerStoreConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(OpenStackVolumeRefInfo)
+	out := new(PowerStoreConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OpenstackCreds) DeepCopyInto(out *OpenstackCreds) {
+func (in *ProxmoxCreds) DeepCopyInto(out *ProxmoxCreds) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.Spec = in.Spec
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackCreds.
-func (in *OpenstackCreds) DeepCopy() *OpenstackCreds {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxCreds.
+func (in *ProxmoxCreds) DeepCopy() *ProxmoxCreds {
 	if in == nil {
 		return nil
 	}
-	out := new(OpenstackCreds)
+	out := new(ProxmoxCreds)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *OpenstackCreds) DeepCopyObject() runtime.Object {
+func (in *ProxmoxCreds) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1610,170 +2767,104 @@ func (in *OpenstackCreds) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OpenstackCredsList) DeepCopyInto(out *OpenstackCredsList) {
+func (in *ProxmoxCredsList) DeepCopyInto(out *ProxmoxCredsList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]OpenstackCreds, len(*in))
+		*out = make([]ProxmoxCreds, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackCredsList.
-func (in *OpenstackCredsList) DeepCopy() *OpenstackCredsList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxCredsList.
+func (in *ProxmoxCredsList) DeepCopy() *ProxmoxCredsList {
 	if in == nil {
 		return nil
 	}
-	out := new(OpenstackCredsList)
+	out := new(ProxmoxCredsList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *OpenstackCredsList) DeepCopyObject() runtime.Object {
+func (in *ProxmoxCredsList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackCredsSpec.
-func (in *OpenstackCredsSpec) DeepCopy() *OpenstackCredsSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(OpenstackCredsSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OpenstackCredsStatus) DeepCopyInto(out *OpenstackCredsStatus) {
-	*out = *in
-	in.Openstack.DeepCopyInto(&out.Openstack)
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackCredsStatus.
-func (in *OpenstackCredsStatus) DeepCopy() *OpenstackCredsStatus {
-	if in == nil {
-		return nil
-	}
-	out := new(OpenstackCredsStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OpenstackInfo) DeepCopyInto(out *OpenstackInfo) {
+func (in *ProxmoxCredsSpec) DeepCopyInto(out *ProxmoxCredsSpec) {
 	*out = *in
-	if in.VolumeTypes != nil {
-		in, out := &in.VolumeTypes, &out.VolumeTypes
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.VolumeBackends != nil {
-		in, out := &in.VolumeBackends, &out.VolumeBackends
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.BackendVolumeTypeMap != nil {
-		in, out := &in.BackendVolumeTypeMap, &out.BackendVolumeTypeMap
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Networks != nil {
-		in, out := &in.Networks, &out.Networks
-		*out = make([]PCDNetworkInfo, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.SecurityGroups != nil {
-		in, out := &in.SecurityGroups, &out.SecurityGroups
-		*out = make([]SecurityGroupInfo, len(*in))
-		copy(*out, *in)
-	}
-	if in.ServerGroups != nil {
-		in, out := &in.ServerGroups, &out.ServerGroups
-		*out = make([]ServerGroupInfo, len(*in))
-		copy(*out, *in)
-	}
+	out.SecretRef = in.SecretRef
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackInfo.
-func (in *OpenstackInfo) DeepCopy() *OpenstackInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxCredsSpec.
+func (in *ProxmoxCredsSpec) DeepCopy() *ProxmoxCredsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(OpenstackInfo)
+	out := new(ProxmoxCredsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OpenstackMapping) DeepCopyInto(out *OpenstackMapping) {
+func (in *ProxmoxCredsStatus) DeepCopyInto(out *ProxmoxCredsStatus) {
 	*out = *in
+	in.LastValidationTime.DeepCopyInto(&out.LastValidationTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackMapping.
-func (in *OpenstackMapping) DeepCopy() *OpenstackMapping {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxCredsStatus.
+func (in *ProxmoxCredsStatus) DeepCopy() *ProxmoxCredsStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(OpenstackMapping)
+	out := new(ProxmoxCredsStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OpenstackVolumeRef) DeepCopyInto(out *OpenstackVolumeRef) {
+func (in *ProxmoxDisk) DeepCopyInto(out *ProxmoxDisk) {
 	*out = *in
-	if in.VolumeRef != nil {
-		in, out := &in.VolumeRef, &out.VolumeRef
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenstackVolumeRef.
-func (in *OpenstackVolumeRef) DeepCopy() *OpenstackVolumeRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxDisk.
+func (in *ProxmoxDisk) DeepCopy() *ProxmoxDisk {
 	if in == nil {
 		return nil
 	}
-	out := new(OpenstackVolumeRef)
+	out := new(ProxmoxDisk)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCDCluster) DeepCopyInto(out *PCDCluster) {
+func (in *ProxmoxMachine) DeepCopyInto(out *ProxmoxMachine) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDCluster.
-func (in *PCDCluster) DeepCopy() *PCDCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxMachine.
+func (in *ProxmoxMachine) DeepCopy() *ProxmoxMachine {
 	if in == nil {
 		return nil
 	}
-	out := new(PCDCluster)
+	out := new(ProxmoxMachine)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PCDCluster) DeepCopyObject() runtime.Object {
+func (in *ProxmoxMachine) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1781,31 +2872,31 @@ func (in *PCDCluster) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCDClusterList) DeepCopyInto(out *PCDClusterList) {
+func (in *ProxmoxMachineList) DeepCopyInto(out *ProxmoxMachineList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]PCDCluster, len(*in))
+		*out = make([]ProxmoxMachine, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDClusterList.
-func (in *PCDClusterList) DeepCopy() *PCDClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxMachineList.
+func (in *ProxmoxMachineList) DeepCopy() *ProxmoxMachineList {
 	if in == nil {
 		return nil
 	}
-	out := new(PCDClusterList)
+	out := new(ProxmoxMachineList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PCDClusterList) DeepCopyObject() runtime.Object {
+func (in *ProxmoxMachineList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1813,42 +2904,23 @@ func (in *PCDClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCDClusterSpec) DeepCopyInto(out *PCDClusterSpec) {
-	*out = *in
-	if in.Hosts != nil {
-		in, out := &in.Hosts, &out.Hosts
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDClusterSpec.
-func (in *PCDClusterSpec) DeepCopy() *PCDClusterSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(PCDClusterSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCDClusterStatus) DeepCopyInto(out *PCDClusterStatus) {
+func (in *ProxmoxMachineSpec) DeepCopyInto(out *ProxmoxMachineSpec) {
 	*out = *in
+	in.VMInfo.DeepCopyInto(&out.VMInfo)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDClusterStatus.
-func (in *PCDClusterStatus) DeepCopy() *PCDClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxMachineSpec.
+func (in *ProxmoxMachineSpec) DeepCopy() *ProxmoxMachineSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PCDClusterStatus)
+	out := new(ProxmoxMachineSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCDHost) DeepCopyInto(out *PCDHost) {
+func (in *ProxmoxMigration) DeepCopyInto(out *ProxmoxMigration) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1856,18 +2928,18 @@ func (in *PCDHost) DeepCopyInto(out *PCDHost) {
 	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDHost.
-func (in *PCDHost) DeepCopy() *PCDHost {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxMigration.
+func (in *ProxmoxMigration) DeepCopy() *ProxmoxMigration {
 	if in == nil {
 		return nil
 	}
-	out := new(PCDHost)
+	out := new(ProxmoxMigration)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PCDHost) DeepCopyObject() runtime.Object {
+func (in *ProxmoxMigration) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1875,51 +2947,31 @@ func (in *PCDHost) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCDHostInterface) DeepCopyInto(out *PCDHostInterface) {
-	*out = *in
-	if in.IPAddresses != nil {
-		in, out := &in.IPAddresses, &out.IPAddresses
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDHostInterface.
-func (in *PCDHostInterface) DeepCopy() *PCDHostInterface {
-	if in == nil {
-		return nil
-	}
-	out := new(PCDHostInterface)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCDHostList) DeepCopyInto(out *PCDHostList) {
+func (in *ProxmoxMigrationList) DeepCopyInto(out *ProxmoxMigrationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]PCDHost, len(*in))
+		*out = make([]ProxmoxMigration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDHostList.
-func (in *PCDHostList) DeepCopy() *PCDHostList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxMigrationList.
+func (in *ProxmoxMigrationList) DeepCopy() *ProxmoxMigrationList {
 	if in == nil {
 		return nil
 	}
-	out := new(PCDHostList)
+	out := new(ProxmoxMigrationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PCDHostList) DeepCopyObject() runtime.Object {
+func (in *ProxmoxMigrationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1927,88 +2979,58 @@ func (in *PCDHostList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCDHostSpec) DeepCopyInto(out *PCDHostSpec) {
+func (in *ProxmoxMigrationSpec) DeepCopyInto(out *ProxmoxMigrationSpec) {
 	*out = *in
-	if in.RolesAssigned != nil {
-		in, out := &in.RolesAssigned, &out.RolesAssigned
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Interfaces != nil {
-		in, out := &in.Interfaces, &out.Interfaces
-		*out = make([]PCDHostInterface, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	out.ProxmoxMachineRef = in.ProxmoxMachineRef
+	in.ImportTargetSpec.DeepCopyInto(&out.ImportTargetSpec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDHostSpec.
-func (in *PCDHostSpec) DeepCopy() *PCDHostSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxMigrationSpec.
+func (in *ProxmoxMigrationSpec) DeepCopy() *ProxmoxMigrationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PCDHostSpec)
+	out := new(ProxmoxMigrationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCDHostStatus) DeepCopyInto(out *PCDHostStatus) {
+func (in *ProxmoxNIC) DeepCopyInto(out *ProxmoxNIC) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDHostStatus.
-func (in *PCDHostStatus) DeepCopy() *PCDHostStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxNIC.
+func (in *ProxmoxNIC) DeepCopy() *ProxmoxNIC {
 	if in == nil {
 		return nil
 	}
-	out := new(PCDHostStatus)
+	out := new(ProxmoxNIC)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PCDNetworkInfo) DeepCopyInto(out *PCDNetworkInfo) {
+func (in *ProxmoxVMInfo) DeepCopyInto(out *ProxmoxVMInfo) {
 	*out = *in
-	if in.Tags != nil {
-		in, out := &in.Tags, &out.Tags
-		*out = make([]string, len(*in))
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]ProxmoxDisk, len(*in))
 		copy(*out, *in)
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PCDNetworkInfo.
-func (in *PCDNetworkInfo) DeepCopy() *PCDNetworkInfo {
-	if in == nil {
-		return nil
-	}
-	out := new(PCDNetworkInfo)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostMigrationAction) DeepCopyInto(out *PostMigrationAction) {
-	*out = *in
-	if in.RenameVM != nil {
-		in, out := &in.RenameVM, &out.RenameVM
-		*out = new(bool)
-		**out = **in
-	}
-	if in.MoveToFolder != nil {
-		in, out := &in.MoveToFolder, &out.MoveToFolder
-		*out = new(bool)
-		**out = **in
+	if in.NICs != nil {
+		in, out := &in.NICs, &out.NICs
+		*out = make([]ProxmoxNIC, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostMigrationAction.
-func (in *PostMigrationAction) DeepCopy() *PostMigrationAction {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxmoxVMInfo.
+func (in *ProxmoxVMInfo) DeepCopy() *ProxmoxVMInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(PostMigrationAction)
+	out := new(ProxmoxVMInfo)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -2407,6 +3429,21 @@ func (in *ServerGroupInfo) DeepCopy() *ServerGroupInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SimulatorConfig) DeepCopyInto(out *SimulatorConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SimulatorConfig.
+func (in *SimulatorConfig) DeepCopy() *SimulatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SimulatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Storage) DeepCopyInto(out *Storage) {
 	*out = *in
@@ -2516,6 +3553,46 @@ func (in *StorageMappingStatus) DeepCopy() *StorageMappingStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VDDKTransport) DeepCopyInto(out *VDDKTransport) {
+	*out = *in
+	if in.Modes != nil {
+		in, out := &in.Modes, &out.Modes
+		*out = make([]VDDKTransportMode, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VDDKTransport.
+func (in *VDDKTransport) DeepCopy() *VDDKTransport {
+	if in == nil {
+		return nil
+	}
+	out := new(VDDKTransport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VMHardware) DeepCopyInto(out *VMHardware) {
+	*out = *in
+	if in.IndependentDisks != nil {
+		in, out := &in.IndependentDisks, &out.IndependentDisks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMHardware.
+func (in *VMHardware) DeepCopy() *VMHardware {
+	if in == nil {
+		return nil
+	}
+	out := new(VMHardware)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VMInfo) DeepCopyInto(out *VMInfo) {
 	*out = *in
@@ -2554,6 +3631,7 @@ func (in *VMInfo) DeepCopyInto(out *VMInfo) {
 		}
 	}
 	out.GPU = in.GPU
+	in.Hardware.DeepCopyInto(&out.Hardware)
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = make(map[string]string, len(*in))
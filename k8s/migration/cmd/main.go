@@ -301,6 +301,7 @@ func SetupControllers(mgr ctrl.Manager, local bool, maxConcurrentReconciles int)
 	if err := (&controller.MigrationPlanReconciler{
 		Client:                  mgr.GetClient(),
 		Scheme:                  mgr.GetScheme(),
+		APIReader:               mgr.GetAPIReader(),
 		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MigrationPlan")
@@ -322,5 +323,47 @@ func SetupControllers(mgr ctrl.Manager, local bool, maxConcurrentReconciles int)
 		setupLog.Error(err, "unable to create controller", "controller", "RollingMigrationPlan")
 		return err
 	}
+	if err := (&controller.ApplianceImportReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ApplianceImport")
+		return err
+	}
+	if err := (&controller.MigrationAssessmentReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MigrationAssessment")
+		return err
+	}
+	if err := (&controller.LibvirtCredsReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LibvirtCreds")
+		return err
+	}
+	if err := (&controller.LibvirtMigrationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "LibvirtMigration")
+		return err
+	}
+	if err := (&controller.ProxmoxCredsReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ProxmoxCreds")
+		return err
+	}
+	if err := (&controller.ProxmoxMigrationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ProxmoxMigration")
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,222 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/k8s/migration/pkg/utils"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// applianceImportLabel marks the v2v-helper pod of an ApplianceImport
+const applianceImportLabel = "vjailbreak.k8s.pf9.io/appliance-import"
+
+// ApplianceImportReconciler reconciles an ApplianceImport object
+type ApplianceImportReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=applianceimports,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=applianceimports/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=applianceimports/finalizers,verbs=update
+
+// Reconcile runs a v2v-helper job for the ApplianceImport and mirrors the job state into its status.
+func (r *ApplianceImportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctxlog := log.FromContext(ctx).WithName(constants.ApplianceImportControllerName)
+
+	applianceImport := &vjailbreakv1alpha1.ApplianceImport{}
+	if err := r.Get(ctx, req.NamespacedName, applianceImport); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !applianceImport.DeletionTimestamp.IsZero() {
+		// The job and config maps are owned by the import and garbage collected with it
+		return ctrl.Result{}, nil
+	}
+
+	switch applianceImport.Status.Phase {
+	case vjailbreakv1alpha1.ImportPhaseSucceeded, vjailbreakv1alpha1.ImportPhaseFailed:
+		return ctrl.Result{}, nil
+	}
+
+	ctxlog.Info("Reconciling ApplianceImport", "name", applianceImport.Name)
+
+	if err := validateApplianceSource(applianceImport.Spec); err != nil {
+		return ctrl.Result{}, r.setPhase(ctx, applianceImport, vjailbreakv1alpha1.ImportPhaseFailed, err.Error())
+	}
+
+	openstackcreds := &vjailbreakv1alpha1.OpenstackCreds{}
+	if err := r.Get(ctx, types.NamespacedName{Name: applianceImport.Spec.OpenstackCredsRef.Name, Namespace: applianceImport.Namespace}, openstackcreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.setPhase(ctx, applianceImport, vjailbreakv1alpha1.ImportPhaseFailed,
+				fmt.Sprintf("OpenstackCreds '%s' not found", applianceImport.Spec.OpenstackCredsRef.Name))
+		}
+		return ctrl.Result{}, errors.Wrap(err, "failed to get openstack credentials")
+	}
+
+	job, err := r.ensureImportJob(ctx, applianceImport, openstackcreds)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	phase, message := importPhaseFromJob(job, "Appliance imported successfully")
+	applianceImport.Status.JobName = job.Name
+	return ctrl.Result{}, r.setPhase(ctx, applianceImport, phase, message)
+}
+
+// validateApplianceSource checks the source against the format. Every format
+// accepts a path inside the import directory or an nfs:// or smb:// share; an
+// OVA may also be downloaded over http(s).
+func validateApplianceSource(spec vjailbreakv1alpha1.ApplianceImportSpec) error {
+	source := strings.TrimSpace(spec.Source)
+	if source == "" {
+		return errors.New("source must be set")
+	}
+	name := source
+	if u, err := url.Parse(source); err == nil && u.Scheme != "" {
+		switch u.Scheme {
+		case "nfs", "smb":
+			// The helper mounts the parent directory of the path
+			if u.Host == "" || path.Dir(path.Clean(u.Path)) == "/" {
+				return errors.Errorf("source %q must name a host and a path below the share", source)
+			}
+		case "http", "https":
+			if spec.Format != vjailbreakv1alpha1.ApplianceFormatOVA {
+				return errors.Errorf("a %s source cannot be downloaded, use a path in %s or a file share", spec.Format, constants.ApplianceImportHostDir)
+			}
+		default:
+			return errors.Errorf("unsupported source scheme %q", u.Scheme)
+		}
+		name = u.Path
+	} else {
+		cleaned := filepath.Clean(source)
+		if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return errors.Errorf("source path %q must be relative to %s", source, constants.ApplianceImportHostDir)
+		}
+	}
+
+	switch spec.Format {
+	case vjailbreakv1alpha1.ApplianceFormatOVA:
+		switch strings.ToLower(path.Ext(name)) {
+		case ".ova", ".ovf":
+		default:
+			return errors.Errorf("source %q is not an .ova or .ovf", source)
+		}
+	case vjailbreakv1alpha1.ApplianceFormatHyperV:
+		// The configuration does not record the guest OS
+		if spec.OSFamily == "" {
+			return errors.New("osFamily must be set for a Hyper-V import")
+		}
+	default:
+		return errors.Errorf("unsupported format %q", spec.Format)
+	}
+	return nil
+}
+
+// applianceImportK8sName is the name the v2v-helper pod uses to find its config
+// map and log file, prefixed so it never collides with a VMwareMachine
+func applianceImportK8sName(applianceImport *vjailbreakv1alpha1.ApplianceImport) string {
+	return "import-" + applianceImport.Name
+}
+
+func (r *ApplianceImportReconciler) setPhase(ctx context.Context, applianceImport *vjailbreakv1alpha1.ApplianceImport,
+	phase vjailbreakv1alpha1.ImportPhase, message string,
+) error {
+	return setImportPhase(ctx, r.Client, applianceImport, &applianceImport.Status, phase, message)
+}
+
+// ensureImportJob creates the config maps and job of the import if they do not exist yet
+func (r *ApplianceImportReconciler) ensureImportJob(ctx context.Context,
+	applianceImport *vjailbreakv1alpha1.ApplianceImport,
+	openstackcreds *vjailbreakv1alpha1.OpenstackCreds,
+) (*batchv1.Job, error) {
+	var env []corev1.EnvVar
+	if ref := applianceImport.Spec.SourceSecretRef; ref != nil && ref.Name != "" {
+		for _, secretEnv := range []struct{ name, key string }{
+			{name: constants.ApplianceSourceUsernameEnv, key: "username"},
+			{name: constants.ApplianceSourcePasswordEnv, key: "password"},
+		} {
+			env = append(env, corev1.EnvVar{
+				Name: secretEnv.name,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: *ref,
+						Key:                  secretEnv.key,
+					},
+				},
+			})
+		}
+	}
+
+	return ensureImportJob(ctx, r.Client, r.Scheme, importJob{
+		owner:          applianceImport,
+		k8sName:        applianceImportK8sName(applianceImport),
+		label:          applianceImportLabel,
+		target:         applianceImport.Spec.ImportTargetSpec,
+		openstackcreds: openstackcreds,
+		sourceConfig: map[string]string{
+			constants.SourceTypeKey:      constants.SourceTypeAppliance,
+			constants.ApplianceFormatKey: string(applianceImport.Spec.Format),
+			constants.ApplianceSourceKey: strings.TrimSpace(applianceImport.Spec.Source),
+		},
+		env: env,
+		volumes: []corev1.Volume{
+			{
+				Name: "imports",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: constants.ApplianceImportHostDir,
+						Type: utils.NewHostPathType("DirectoryOrCreate"),
+					},
+				},
+			},
+		},
+		volumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "imports",
+				MountPath: constants.ApplianceImportDir,
+			},
+		},
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ApplianceImportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&vjailbreakv1alpha1.ApplianceImport{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
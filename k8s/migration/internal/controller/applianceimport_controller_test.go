@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/k8s/migration/pkg/utils"
+	constants "github.com/platform9/vjailbreak/pkg/common/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func applianceImportTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := aggregateTestScheme(t)
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add core scheme: %v", err)
+	}
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add batch scheme: %v", err)
+	}
+	return scheme
+}
+
+func newApplianceImportReconciler(t *testing.T, source string) (*ApplianceImportReconciler, client.Client) {
+	t.Helper()
+	scheme := applianceImportTestScheme(t)
+	ns := constants.NamespaceMigrationSystem
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: constants.VjailbreakSettingsConfigMapName, Namespace: ns}},
+			&vjailbreakv1alpha1.OpenstackCreds{
+				ObjectMeta: metav1.ObjectMeta{Name: "pcd", Namespace: ns},
+				Spec: vjailbreakv1alpha1.OpenstackCredsSpec{
+					SecretRef: corev1.ObjectReference{Name: "pcd-openstack-secret"},
+				},
+			},
+			&vjailbreakv1alpha1.ApplianceImport{
+				ObjectMeta: metav1.ObjectMeta{Name: "firewall", Namespace: ns},
+				Spec: vjailbreakv1alpha1.ApplianceImportSpec{
+					Format: vjailbreakv1alpha1.ApplianceFormatOVA,
+					Source: source,
+					ImportTargetSpec: vjailbreakv1alpha1.ImportTargetSpec{
+						OpenstackCredsRef: corev1.LocalObjectReference{Name: "pcd"},
+						NetworkNames:      []string{"mgmt", "data"},
+						VolumeTypes:       []string{"ssd"},
+					},
+				},
+			},
+		).
+		WithStatusSubresource(&vjailbreakv1alpha1.ApplianceImport{}).
+		Build()
+	return &ApplianceImportReconciler{Client: fakeClient, Scheme: scheme}, fakeClient
+}
+
+func reconcileApplianceImport(t *testing.T, r *ApplianceImportReconciler) *vjailbreakv1alpha1.ApplianceImport {
+	t.Helper()
+	key := types.NamespacedName{Name: "firewall", Namespace: constants.NamespaceMigrationSystem}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+	applianceImport := &vjailbreakv1alpha1.ApplianceImport{}
+	if err := r.Get(context.Background(), key, applianceImport); err != nil {
+		t.Fatal(err)
+	}
+	return applianceImport
+}
+
+func TestApplianceImportCreatesJob(t *testing.T) {
+	r, c := newApplianceImportReconciler(t, "vendor/firewall.ova")
+	ctx := context.Background()
+	ns := constants.NamespaceMigrationSystem
+
+	applianceImport := reconcileApplianceImport(t, r)
+	if applianceImport.Status.Phase != vjailbreakv1alpha1.ImportPhasePending {
+		t.Errorf("Phase = %q, want Pending", applianceImport.Status.Phase)
+	}
+	if applianceImport.Status.JobName == "" {
+		t.Fatal("JobName not set")
+	}
+
+	job := &batchv1.Job{}
+	if err := c.Get(ctx, types.NamespacedName{Name: applianceImport.Status.JobName, Namespace: ns}, job); err != nil {
+		t.Fatalf("job not created: %v", err)
+	}
+	if len(job.OwnerReferences) != 1 || job.OwnerReferences[0].Name != "firewall" {
+		t.Errorf("job owner references = %+v, want the ApplianceImport", job.OwnerReferences)
+	}
+	container := job.Spec.Template.Spec.Containers[0]
+	if container.EnvFrom[0].SecretRef.Name != "pcd-openstack-secret" {
+		t.Errorf("job uses secret %q, want pcd-openstack-secret", container.EnvFrom[0].SecretRef.Name)
+	}
+	mounted := false
+	for _, m := range container.VolumeMounts {
+		if m.Name == "imports" && m.MountPath == constants.ApplianceImportDir {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Error("import directory is not mounted in the job")
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Name: utils.GetMigrationConfigMapName("import-firewall"), Namespace: ns}, configMap); err != nil {
+		t.Fatalf("migration config map not created: %v", err)
+	}
+	want := map[string]string{
+		constants.SourceTypeKey:      constants.SourceTypeAppliance,
+		constants.ApplianceSourceKey: "vendor/firewall.ova",
+		"NEUTRON_NETWORK_NAMES":      "mgmt,data",
+		"CINDER_VOLUME_TYPES":        "ssd",
+		"VMWARE_MACHINE_OBJECT_NAME": "import-firewall",
+	}
+	for k, v := range want {
+		if configMap.Data[k] != v {
+			t.Errorf("config map %s = %q, want %q", k, configMap.Data[k], v)
+		}
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: utils.GetFirstbootConfigMapName("import-firewall"), Namespace: ns}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("firstboot config map not created: %v", err)
+	}
+
+	job.Status.Succeeded = 1
+	if err := c.Status().Update(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	applianceImport = reconcileApplianceImport(t, r)
+	if applianceImport.Status.Phase != vjailbreakv1alpha1.ImportPhaseSucceeded {
+		t.Errorf("Phase = %q after job success, want Succeeded", applianceImport.Status.Phase)
+	}
+}
+
+func TestApplianceImportRejectsInvalidSource(t *testing.T) {
+	r, c := newApplianceImportReconciler(t, "../etc/firewall.ova")
+
+	applianceImport := reconcileApplianceImport(t, r)
+	if applianceImport.Status.Phase != vjailbreakv1alpha1.ImportPhaseFailed {
+		t.Errorf("Phase = %q, want Failed", applianceImport.Status.Phase)
+	}
+	jobs := &batchv1.JobList{}
+	if err := c.List(context.Background(), jobs); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) != 0 {
+		t.Errorf("created %d jobs for an invalid source, want 0", len(jobs.Items))
+	}
+}
+
+func TestValidateApplianceSource(t *testing.T) {
+	ova := vjailbreakv1alpha1.ApplianceFormatOVA
+	hyperv := vjailbreakv1alpha1.ApplianceFormatHyperV
+	tests := []struct {
+		format   vjailbreakv1alpha1.ApplianceFormat
+		source   string
+		osFamily string
+		wantErr  bool
+	}{
+		{format: ova, source: "https://downloads.example.com/firewall.ova"},
+		{format: ova, source: "vendor/firewall.OVF"},
+		{format: ova, source: "firewall.ova"},
+		{format: ova, source: "nfs://filer/exports/appliances/firewall.ova"},
+		{format: ova, source: "", wantErr: true},
+		{format: ova, source: "/etc/firewall.ova", wantErr: true},
+		{format: ova, source: "../firewall.ova", wantErr: true},
+		{format: ova, source: "ftp://example.com/firewall.ova", wantErr: true},
+		{format: ova, source: "firewall.qcow2", wantErr: true},
+		{format: ova, source: "nfs://filer/firewall.ova", wantErr: true},
+		{format: hyperv, source: "smb://fileserver/exports/app01", osFamily: "windowsGuest"},
+		{format: hyperv, source: "app01/Virtual Machines/5A1B.xml", osFamily: "linuxGuest"},
+		{format: hyperv, source: "app01", wantErr: true},
+		{format: hyperv, source: "https://example.com/app01", osFamily: "windowsGuest", wantErr: true},
+	}
+	for _, tt := range tests {
+		spec := vjailbreakv1alpha1.ApplianceImportSpec{Format: tt.format, Source: tt.source}
+		spec.OSFamily = tt.osFamily
+		err := validateApplianceSource(spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateApplianceSource(%s, %q) error = %v, wantErr %v", tt.format, tt.source, err, tt.wantErr)
+		}
+	}
+}
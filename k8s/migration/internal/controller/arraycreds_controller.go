@@ -31,7 +31,10 @@ import (
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 
+	hpesdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/hpalletra"
 	netappsdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/netapp"
+	powerstoresdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/powerstore"
+	simulatorsdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/simulator"
 
 	// Blank import registers all storage providers via their init() so
 	// storage.NewStorageProvider resolves by vendor type string.
@@ -190,7 +193,7 @@ func (r *ArrayCredsReconciler) reconcileNormal(ctx context.Context, scope *scope
 	ctxlog.Info("Successfully authenticated to storage array", "vendor", arraycreds.Spec.VendorType, "hostname", arrayCredential.Hostname)
 
 	// Discover datastores backed by this array
-	datastores, err := r.discoverDatastores(ctx, arraycreds.Spec.VendorType, arrayCredential, scope)
+	datastores, err := r.discoverDatastores(ctx, arraycreds.Spec.VendorType, arrayCredential, providerOptions, scope)
 	if err != nil {
 		ctxlog.Error(err, "Failed to discover datastores", "arraycreds", scope.ArrayCreds.Name)
 		// Don't fail validation, just log warning
@@ -233,6 +236,30 @@ func (r *ArrayCredsReconciler) reconcileNormal(ctx context.Context, scope *scope
 			validationMessage = fmt.Sprintf("Invalid NetApp target selection: %v", err)
 		}
 	}
+	if arraycreds.Spec.VendorType == powerstoresdk.VendorName {
+		if err := validatePowerStoreTargetSelection(arraycreds.Spec.PowerStoreConfig, backendTargets); err != nil {
+			ctxlog.Error(err, "PowerStore appliance selection invalid", "arraycreds", scope.ArrayCreds.Name)
+			phase = constants.ArrayCredsPhaseFailed
+			validationStatus = constants.ArrayCredsStatusFailed
+			validationMessage = fmt.Sprintf("Invalid PowerStore target selection: %v", err)
+		}
+	}
+	if arraycreds.Spec.VendorType == hpesdk.VendorName {
+		if err := validateHPETargetSelection(arraycreds.Spec.HPEAlletraConfig, backendTargets); err != nil {
+			ctxlog.Error(err, "HPE CPG selection invalid", "arraycreds", scope.ArrayCreds.Name)
+			phase = constants.ArrayCredsPhaseFailed
+			validationStatus = constants.ArrayCredsStatusFailed
+			validationMessage = fmt.Sprintf("Invalid HPE target selection: %v", err)
+		}
+	}
+	if arraycreds.Spec.ArrayNativeCopy {
+		if err := validateArrayNativeCopy(arraycreds.Spec.VendorType); err != nil {
+			ctxlog.Error(err, "Array-native copy not supported", "arraycreds", scope.ArrayCreds.Name)
+			phase = constants.ArrayCredsPhaseFailed
+			validationStatus = constants.ArrayCredsStatusFailed
+			validationMessage = fmt.Sprintf("Invalid array-native copy setting: %v", err)
+		}
+	}
 
 	scope.ArrayCreds.Status.Phase = phase
 	scope.ArrayCreds.Status.ArrayValidationStatus = validationStatus
@@ -337,6 +364,27 @@ func buildProviderOptionsFromSpec(spec vjailbreakv1alpha1.ArrayCredsSpec) map[st
 			opts[netappsdk.OptionFlexVol] = spec.NetAppConfig.FlexVol
 		}
 	}
+	if spec.VendorType == powerstoresdk.VendorName && spec.PowerStoreConfig != nil && spec.PowerStoreConfig.Appliance != "" {
+		opts[powerstoresdk.OptionAppliance] = spec.PowerStoreConfig.Appliance
+	}
+	if spec.VendorType == hpesdk.VendorName && spec.HPEAlletraConfig != nil && spec.HPEAlletraConfig.CPG != "" {
+		opts[hpesdk.OptionCPG] = spec.HPEAlletraConfig.CPG
+	}
+	if spec.VendorType == simulatorsdk.VendorName && spec.SimulatorConfig != nil {
+		cfg := spec.SimulatorConfig
+		if cfg.StateFile != "" {
+			opts[simulatorsdk.OptionStateFile] = cfg.StateFile
+		}
+		if cfg.CreateDelay != "" {
+			opts[simulatorsdk.OptionCreateDelay] = cfg.CreateDelay
+		}
+		if cfg.FailMapping {
+			opts[simulatorsdk.OptionFailMapping] = "true"
+		}
+		if cfg.NAACollision {
+			opts[simulatorsdk.OptionNAACollision] = "true"
+		}
+	}
 	if len(opts) == 0 {
 		return nil
 	}
@@ -417,8 +465,64 @@ func validateNetAppTargetSelection(cfg *vjailbreakv1alpha1.NetAppConfig, targets
 	return fmt.Errorf("SVM %q not found on the NetApp array", cfg.SVM)
 }
 
+// validatePowerStoreTargetSelection checks that the appliance named in
+// spec.PowerStoreConfig is one of the discovered cluster appliances. No
+// appliance is a valid selection, PowerStore then places the volumes.
+func validatePowerStoreTargetSelection(cfg *vjailbreakv1alpha1.PowerStoreConfig, targets []vjailbreakv1alpha1.BackendTargetGroup) error {
+	if cfg == nil || cfg.Appliance == "" {
+		return nil
+	}
+	for _, g := range targets {
+		for _, c := range g.Children {
+			if c.Name == cfg.Appliance {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("appliance %q not found on the PowerStore cluster", cfg.Appliance)
+}
+
+// validateArrayNativeCopy checks that spec.ArrayNativeCopy can work. Besides a
+// provider that can snapshot and clone volumes, the copy needs a VMFS FUSE
+// driver in the v2v-helper image to mount the clone. The image ships none yet,
+// so the setting is rejected for every vendor until it does.
+func validateArrayNativeCopy(vendorType string) error {
+	provider, err := storagesdk.NewStorageProvider(vendorType)
+	if err != nil {
+		return err
+	}
+	if _, ok := provider.(storagesdk.ArrayCloner); !ok {
+		return fmt.Errorf("%s arrays do not support snapshot clones, unset spec.arrayNativeCopy", vendorType)
+	}
+	return fmt.Errorf("the v2v-helper image has no VMFS FUSE driver to mount array clones with, unset spec.arrayNativeCopy")
+}
+
+// validateHPETargetSelection checks that the CPG named in
+// spec.HPEAlletraConfig is one of the discovered CPGs of the array. No CPG is
+// a valid selection only when the array has a single one.
+func validateHPETargetSelection(cfg *vjailbreakv1alpha1.HPEAlletraConfig, targets []vjailbreakv1alpha1.BackendTargetGroup) error {
+	var cpgs []string
+	for _, g := range targets {
+		for _, c := range g.Children {
+			cpgs = append(cpgs, c.Name)
+		}
+	}
+	if cfg == nil || cfg.CPG == "" {
+		if len(cpgs) > 1 {
+			return fmt.Errorf("the array has %d CPGs, set spec.hpeAlletraConfig.cpg to one of %v", len(cpgs), cpgs)
+		}
+		return nil
+	}
+	for _, cpg := range cpgs {
+		if cpg == cfg.CPG {
+			return nil
+		}
+	}
+	return fmt.Errorf("CPG %q not found on the HPE array", cfg.CPG)
+}
+
 // discoverDatastores discovers vCenter datastores backed by volumes from this storage array
-func (r *ArrayCredsReconciler) discoverDatastores(ctx context.Context, vendorType string, creds vjailbreakv1alpha1.ArrayCredsInfo, scope *scope.ArrayCredsScope) ([]vjailbreakv1alpha1.DatastoreInfo, error) {
+func (r *ArrayCredsReconciler) discoverDatastores(ctx context.Context, vendorType string, creds vjailbreakv1alpha1.ArrayCredsInfo, providerOptions map[string]string, scope *scope.ArrayCredsScope) ([]vjailbreakv1alpha1.DatastoreInfo, error) {
 	ctxlog := scope.Logger
 	ctxlog.Info("Discovering datastores", "vendorType", vendorType)
 	// Step 1: Get all volume NAAs from the storage array
@@ -435,6 +539,7 @@ func (r *ArrayCredsReconciler) discoverDatastores(ctx context.Context, vendorTyp
 		Password:            creds.Password,
 		SkipSSLVerification: creds.SkipSSLVerification,
 		VendorType:          vendorType,
+		ProviderOptions:     providerOptions,
 	}
 
 	// Connect to storage array
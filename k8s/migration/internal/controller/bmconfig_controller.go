@@ -110,7 +110,7 @@ func (r *BMConfigReconciler) reconcileNormal(ctx context.Context, scope *scope.B
 	})
 	if err != nil {
 		bmConfig.Status.ValidationStatus = string(corev1.PodFailed)
-		bmConfig.Status.ValidationMessage = fmt.Sprintf("Error connecting to MAAS: %s", err)
+		bmConfig.Status.ValidationMessage = fmt.Sprintf("Error connecting to %s: %s", bmConfig.Spec.ProviderType, err)
 		if updateErr := r.Status().Update(ctx, bmConfig); updateErr != nil {
 			return ctrl.Result{}, errors.Wrap(
 				errors.Wrap(updateErr, fmt.Sprintf("Error updating status of BMConfig '%s'", bmConfig.Name)),
@@ -120,12 +120,12 @@ func (r *BMConfigReconciler) reconcileNormal(ctx context.Context, scope *scope.B
 	}
 	defer func() {
 		if err := provider.Disconnect(); err != nil {
-			scope.Error(err, "Error disconnecting from MAAS provider")
+			scope.Error(err, "Error disconnecting from BM provider", "provider", bmConfig.Spec.ProviderType)
 		}
 	}()
 
 	bmConfig.Status.ValidationStatus = string(corev1.PodSucceeded)
-	bmConfig.Status.ValidationMessage = "Successfully connected to MAAS"
+	bmConfig.Status.ValidationMessage = fmt.Sprintf("Successfully connected to %s", bmConfig.Spec.ProviderType)
 	if updateErr := r.Status().Update(ctx, bmConfig); updateErr != nil {
 		return ctrl.Result{}, errors.Wrap(
 			updateErr, fmt.Sprintf("Error updating status of BMConfig '%s'", bmConfig.Name))
@@ -0,0 +1,330 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/k8s/migration/pkg/utils"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	commonutils "github.com/platform9/vjailbreak/pkg/common/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// importJob describes the single v2v-helper job of an import that does not go
+// through a MigrationPlan, such as an ApplianceImport or a LibvirtMigration
+type importJob struct {
+	// owner owns the job and its config maps, which are garbage collected with it
+	owner client.Object
+	// k8sName is the name the v2v-helper pod uses to find its config map and log
+	// file. It is prefixed per source kind so it never collides with a VMwareMachine.
+	k8sName string
+	// label is set on the pod with the owner name as value
+	label string
+	// target is the instance to create
+	target vjailbreakv1alpha1.ImportTargetSpec
+	// openstackcreds is the project of the instance
+	openstackcreds *vjailbreakv1alpha1.OpenstackCreds
+	// sourceConfig holds the keys of the migration config map that locate the source
+	sourceConfig map[string]string
+	// env, volumes and volumeMounts are added to what every import pod gets
+	env          []corev1.EnvVar
+	volumes      []corev1.Volume
+	volumeMounts []corev1.VolumeMount
+}
+
+func (j *importJob) jobName() string {
+	return fmt.Sprintf("v2v-helper-%s-%s", j.k8sName[:min(len(j.k8sName), constants.MaxJobNameLength)],
+		commonutils.GenerateSha256Hash(j.owner.GetName())[:constants.HashSuffixLength])
+}
+
+// importPhaseFromJob maps the job state to an import phase
+func importPhaseFromJob(job *batchv1.Job, successMessage string) (vjailbreakv1alpha1.ImportPhase, string) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return vjailbreakv1alpha1.ImportPhaseSucceeded, successMessage
+		case batchv1.JobFailed:
+			return vjailbreakv1alpha1.ImportPhaseFailed,
+				fmt.Sprintf("Import job %s failed: %s, see the v2v-helper pod logs", job.Name, c.Message)
+		}
+	}
+	if job.Status.Succeeded > 0 {
+		return vjailbreakv1alpha1.ImportPhaseSucceeded, successMessage
+	}
+	if job.Status.Failed > 0 {
+		return vjailbreakv1alpha1.ImportPhaseFailed, fmt.Sprintf("Import job %s failed, see the v2v-helper pod logs", job.Name)
+	}
+	if job.Status.Active > 0 {
+		return vjailbreakv1alpha1.ImportPhaseRunning, fmt.Sprintf("Import job %s is running", job.Name)
+	}
+	return vjailbreakv1alpha1.ImportPhasePending, fmt.Sprintf("Import job %s created", job.Name)
+}
+
+// setImportPhase records phase and message in the status of an import
+func setImportPhase(ctx context.Context, c client.Client, owner client.Object, status *vjailbreakv1alpha1.ImportStatus,
+	phase vjailbreakv1alpha1.ImportPhase, message string,
+) error {
+	if status.Phase == phase && status.Message == message {
+		return nil
+	}
+	status.Phase = phase
+	status.Message = message
+	if err := c.Status().Update(ctx, owner); err != nil {
+		return errors.Wrapf(err, "failed to update status of '%s'", owner.GetName())
+	}
+	return nil
+}
+
+// ensureImportJob creates the config maps and job of the import if they do not exist yet
+func ensureImportJob(ctx context.Context, c client.Client, scheme *runtime.Scheme, spec importJob) (*batchv1.Job, error) {
+	owner := spec.owner
+	target := spec.target
+
+	virtiodrivers := target.VirtioWinDriver
+	if virtiodrivers == "" {
+		virtiodrivers = resolveVirtioDriverURL(&vjailbreakv1alpha1.MigrationTemplate{})
+	}
+	configMapData := map[string]string{
+		"SOURCE_VM_NAME":             target.TargetName,
+		"CONVERT":                    "true",
+		"NEUTRON_NETWORK_NAMES":      strings.Join(target.NetworkNames, ","),
+		"CINDER_VOLUME_TYPES":        strings.Join(target.VolumeTypes, ","),
+		"VIRTIO_WIN_DRIVER":          virtiodrivers,
+		"OS_FAMILY":                  target.OSFamily,
+		"TARGET_FLAVOR_ID":           target.TargetFlavorID,
+		"TARGET_AVAILABILITY_ZONE":   target.TargetAvailabilityZone,
+		"SECURITY_GROUPS":            strings.Join(target.SecurityGroups, ","),
+		"VMWARE_MACHINE_OBJECT_NAME": spec.k8sName,
+		// No guest IPs are read from these sources, the ports always use DHCP
+		"FALLBACK_TO_DHCP": "true",
+	}
+	for k, v := range spec.sourceConfig {
+		configMapData[k] = v
+	}
+	if err := ensureOwnedConfigMap(ctx, c, scheme, owner, utils.GetMigrationConfigMapName(spec.k8sName), configMapData); err != nil {
+		return nil, err
+	}
+	firstbootConfigMapName := utils.GetFirstbootConfigMapName(spec.k8sName)
+	if err := ensureOwnedConfigMap(ctx, c, scheme, owner, firstbootConfigMapName, map[string]string{
+		"user_firstboot.sh": target.FirstBootScript,
+	}); err != nil {
+		return nil, err
+	}
+
+	jobName := spec.jobName()
+	job := &batchv1.Job{}
+	err := c.Get(ctx, types.NamespacedName{Name: jobName, Namespace: owner.GetNamespace()}, job)
+	if err == nil {
+		return job, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to get job '%s'", jobName)
+	}
+
+	vjailbreakSettings, err := k8sutils.GetVjailbreakSettings(ctx, c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get vjailbreak settings for pod resources")
+	}
+
+	env := append([]corev1.EnvVar{
+		{
+			Name: "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
+		{
+			Name:  "VMWARE_MACHINE_OBJECT_NAME",
+			Value: spec.k8sName,
+		},
+	}, spec.env...)
+
+	job = &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: owner.GetNamespace(),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						spec.label: owner.GetName(),
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                 corev1.RestartPolicyNever,
+					ServiceAccountName:            "migration-controller-manager",
+					TerminationGracePeriodSeconds: ptr.To(constants.TerminationPeriod),
+					HostNetwork:                   true,
+					DNSPolicy:                     corev1.DNSClusterFirstWithHostNet,
+					Containers: []corev1.Container{
+						{
+							Name:            "fedora",
+							Image:           v2vimage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"/home/fedora/manager"},
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: ptr.To(true),
+							},
+							Env: env,
+							EnvFrom: []corev1.EnvFromSource{
+								{
+									SecretRef: &corev1.SecretEnvSource{
+										LocalObjectReference: corev1.LocalObjectReference{
+											Name: spec.openstackcreds.Spec.SecretRef.Name,
+										},
+									},
+								},
+								{
+									ConfigMapRef: &corev1.ConfigMapEnvSource{
+										LocalObjectReference: corev1.LocalObjectReference{
+											Name: "pf9-env",
+										},
+									},
+								},
+							},
+							VolumeMounts: append([]corev1.VolumeMount{
+								{
+									Name:      "dev",
+									MountPath: "/dev",
+								},
+								{
+									Name:      "firstboot",
+									MountPath: "/home/fedora/scripts",
+								},
+								{
+									Name:      "logs",
+									MountPath: "/var/log/pf9",
+								},
+								{
+									Name:      "virtio-driver",
+									MountPath: "/home/fedora/virtio-win",
+								},
+							}, spec.volumeMounts...),
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:              resource.MustParse(vjailbreakSettings.V2VHelperPodCPURequest),
+									corev1.ResourceMemory:           resource.MustParse(vjailbreakSettings.V2VHelperPodMemoryRequest),
+									corev1.ResourceEphemeralStorage: resource.MustParse(vjailbreakSettings.V2VHelperPodEphemeralStorageRequest),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:              resource.MustParse(vjailbreakSettings.V2VHelperPodCPULimit),
+									corev1.ResourceMemory:           resource.MustParse(vjailbreakSettings.V2VHelperPodMemoryLimit),
+									corev1.ResourceEphemeralStorage: resource.MustParse(vjailbreakSettings.V2VHelperPodEphemeralStorageLimit),
+								},
+							},
+						},
+					},
+					Volumes: append([]corev1.Volume{
+						{
+							Name: "dev",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/dev",
+									Type: utils.NewHostPathType("Directory"),
+								},
+							},
+						},
+						{
+							Name: "firstboot",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: firstbootConfigMapName,
+									},
+								},
+							},
+						},
+						{
+							Name: "logs",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/var/log/pf9",
+									Type: utils.NewHostPathType("DirectoryOrCreate"),
+								},
+							},
+						},
+						{
+							Name: "virtio-driver",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/home/ubuntu/virtio-win",
+									Type: utils.NewHostPathType("DirectoryOrCreate"),
+								},
+							},
+						},
+					}, spec.volumes...),
+				},
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(owner, job, scheme); err != nil {
+		return nil, errors.Wrap(err, "failed to set owner reference on job")
+	}
+	if err := c.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, errors.Wrapf(err, "failed to create job '%s'", jobName)
+	}
+	return job, nil
+}
+
+func ensureOwnedConfigMap(ctx context.Context, c client.Client, scheme *runtime.Scheme,
+	owner client.Object, name string, data map[string]string,
+) error {
+	configMap := &corev1.ConfigMap{}
+	err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: owner.GetNamespace()}, configMap)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get config map '%s'", name)
+	}
+	configMap = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: owner.GetNamespace(),
+		},
+		Data: data,
+	}
+	if err := controllerutil.SetControllerReference(owner, configMap, scheme); err != nil {
+		return errors.Wrap(err, "failed to set owner reference on config map")
+	}
+	if err := c.Create(ctx, configMap); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create config map '%s'", name)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/pkg/common/libvirt"
+	esxissh "github.com/platform9/vjailbreak/v2v-helper/esxi-ssh"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// libvirtSSHPrivateKeyKey is the key of the SSH private key in the LibvirtCreds secret
+const libvirtSSHPrivateKeyKey = "privateKey"
+
+// LibvirtCredsReconciler reconciles a LibvirtCreds object
+type LibvirtCredsReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=libvirtcreds,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=libvirtcreds/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=libvirtcreds/finalizers,verbs=update
+
+// Reconcile checks that the KVM host is reachable and lists its domains
+func (r *LibvirtCredsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctxlog := log.FromContext(ctx).WithName(constants.LibvirtCredsControllerName)
+
+	libvirtCreds := &vjailbreakv1alpha1.LibvirtCreds{}
+	if err := r.Get(ctx, req.NamespacedName, libvirtCreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !libvirtCreds.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	ctxlog.Info("Validating LibvirtCreds", "name", libvirtCreds.Name, "uri", libvirtCreds.Spec.URI)
+	libvirtCreds.Status.ValidationStatus = constants.LibvirtCredsStatusValidating
+	libvirtCreds.Status.ValidationMessage = "Validating connection to the KVM host..."
+	if err := r.Status().Update(ctx, libvirtCreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	domains, err := r.validate(ctx, libvirtCreds)
+	libvirtCreds.Status.LastValidationTime = metav1.Now()
+	if err != nil {
+		ctxlog.Info("LibvirtCreds validation failed", "name", libvirtCreds.Name, "error", err.Error())
+		libvirtCreds.Status.ValidationStatus = constants.LibvirtCredsStatusFailed
+		libvirtCreds.Status.ValidationMessage = err.Error()
+		libvirtCreds.Status.Domains = nil
+	} else {
+		libvirtCreds.Status.ValidationStatus = constants.LibvirtCredsStatusSucceeded
+		libvirtCreds.Status.ValidationMessage = fmt.Sprintf("Connected to the KVM host, found %d domain(s)", len(domains))
+		libvirtCreds.Status.Domains = domains
+	}
+	if err := r.Status().Update(ctx, libvirtCreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Requeue periodically to pick up new domains
+	return ctrl.Result{RequeueAfter: 15 * time.Minute}, nil
+}
+
+// validate connects to the host over SSH, checks the tools a migration needs and
+// returns the defined domains
+func (r *LibvirtCredsReconciler) validate(ctx context.Context, libvirtCreds *vjailbreakv1alpha1.LibvirtCreds) ([]string, error) {
+	conn, err := libvirt.ParseURI(libvirtCreds.Spec.URI)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := r.getPrivateKey(ctx, libvirtCreds)
+	if err != nil {
+		return nil, err
+	}
+
+	sshClient := esxissh.NewClientWithTimeout(30 * time.Second)
+	connectCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	if err := sshClient.Connect(connectCtx, conn.Host, conn.User, privateKey); err != nil {
+		return nil, errors.Wrapf(err, "SSH connection to %s failed", conn.Host)
+	}
+	defer func() {
+		if err := sshClient.Disconnect(); err != nil {
+			log.FromContext(ctx).V(1).Info("Failed to disconnect SSH client", "host", conn.Host, "error", err)
+		}
+	}()
+
+	// qemu-nbd serves the disks to the v2v-helper pod during a migration
+	if _, err := sshClient.ExecuteCommand("command -v qemu-nbd"); err != nil {
+		return nil, errors.Errorf("qemu-nbd is not installed on %s, it is needed to copy the disks", conn.Host)
+	}
+	out, err := sshClient.ExecuteCommand(conn.VirshCommand("list", "--all", "--name"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list domains on %s: %s", conn.Host, strings.TrimSpace(out))
+	}
+	return libvirt.ParseDomainList(out), nil
+}
+
+// getPrivateKey reads and checks the SSH private key from the referenced secret
+func (r *LibvirtCredsReconciler) getPrivateKey(ctx context.Context, libvirtCreds *vjailbreakv1alpha1.LibvirtCreds) ([]byte, error) {
+	// Migration pods read the key through an env var, so the secret must share the namespace
+	secretName, secretNamespace := libvirtCreds.Spec.SecretRef.Name, libvirtCreds.Namespace
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: secretNamespace}, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to get secret %s/%s", secretNamespace, secretName)
+	}
+	privateKey, ok := secret.Data[libvirtSSHPrivateKeyKey]
+	if !ok {
+		return nil, errors.Errorf("secret %s/%s does not contain '%s' key", secretNamespace, secretName, libvirtSSHPrivateKeyKey)
+	}
+	if _, err := ssh.ParsePrivateKey(privateKey); err != nil {
+		return nil, errors.Errorf("invalid SSH private key in secret %s/%s: %v", secretNamespace, secretName, err)
+	}
+	return privateKey, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LibvirtCredsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&vjailbreakv1alpha1.LibvirtCreds{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
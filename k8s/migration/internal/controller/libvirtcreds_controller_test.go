@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	constants "github.com/platform9/vjailbreak/pkg/common/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLibvirtCredsValidationFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		secret  map[string][]byte
+		wantMsg string
+	}{
+		{
+			name:    "unsupported transport",
+			uri:     "qemu+tcp://kvm01.example.com/system",
+			wantMsg: "qemu+ssh transport",
+		},
+		{
+			name:    "missing secret",
+			uri:     "qemu+ssh://kvm01.example.com/system",
+			wantMsg: "failed to get secret",
+		},
+		{
+			name:    "secret without key",
+			uri:     "qemu+ssh://kvm01.example.com/system",
+			secret:  map[string][]byte{"id_rsa": []byte("not a key")},
+			wantMsg: "does not contain 'privateKey'",
+		},
+		{
+			name:    "invalid key",
+			uri:     "qemu+ssh://kvm01.example.com/system",
+			secret:  map[string][]byte{"privateKey": []byte("not a key")},
+			wantMsg: "invalid SSH private key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := applianceImportTestScheme(t)
+			ns := constants.NamespaceMigrationSystem
+			builder := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(&vjailbreakv1alpha1.LibvirtCreds{
+					ObjectMeta: metav1.ObjectMeta{Name: "kvm01", Namespace: ns},
+					Spec: vjailbreakv1alpha1.LibvirtCredsSpec{
+						URI:       tt.uri,
+						SecretRef: corev1.ObjectReference{Name: "kvm01-ssh"},
+					},
+				}).
+				WithStatusSubresource(&vjailbreakv1alpha1.LibvirtCreds{})
+			if tt.secret != nil {
+				builder = builder.WithObjects(&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "kvm01-ssh", Namespace: ns},
+					Data:       tt.secret,
+				})
+			}
+			r := &LibvirtCredsReconciler{Client: builder.Build(), Scheme: scheme}
+
+			key := types.NamespacedName{Name: "kvm01", Namespace: ns}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile() unexpected error: %v", err)
+			}
+			libvirtCreds := &vjailbreakv1alpha1.LibvirtCreds{}
+			if err := r.Get(context.Background(), key, libvirtCreds); err != nil {
+				t.Fatal(err)
+			}
+			if libvirtCreds.Status.ValidationStatus != constants.LibvirtCredsStatusFailed {
+				t.Errorf("ValidationStatus = %q, want Failed", libvirtCreds.Status.ValidationStatus)
+			}
+			if !strings.Contains(libvirtCreds.Status.ValidationMessage, tt.wantMsg) {
+				t.Errorf("ValidationMessage = %q, want it to contain %q", libvirtCreds.Status.ValidationMessage, tt.wantMsg)
+			}
+		})
+	}
+}
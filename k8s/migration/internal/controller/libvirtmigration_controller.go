@@ -0,0 +1,152 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// libvirtMigrationLabel marks the v2v-helper pod of a LibvirtMigration
+const libvirtMigrationLabel = "vjailbreak.k8s.pf9.io/libvirt-migration"
+
+// LibvirtMigrationReconciler reconciles a LibvirtMigration object
+type LibvirtMigrationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=libvirtmigrations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=libvirtmigrations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=libvirtmigrations/finalizers,verbs=update
+
+// Reconcile runs a v2v-helper job for the LibvirtMigration once its KVM host is
+// validated, and mirrors the job state into its status.
+func (r *LibvirtMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctxlog := log.FromContext(ctx).WithName(constants.LibvirtMigrationControllerName)
+
+	libvirtMigration := &vjailbreakv1alpha1.LibvirtMigration{}
+	if err := r.Get(ctx, req.NamespacedName, libvirtMigration); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !libvirtMigration.DeletionTimestamp.IsZero() {
+		// The job and config maps are owned by the migration and garbage collected with it
+		return ctrl.Result{}, nil
+	}
+
+	switch libvirtMigration.Status.Phase {
+	case vjailbreakv1alpha1.ImportPhaseSucceeded, vjailbreakv1alpha1.ImportPhaseFailed:
+		return ctrl.Result{}, nil
+	}
+
+	ctxlog.Info("Reconciling LibvirtMigration", "name", libvirtMigration.Name)
+
+	domainName := strings.TrimSpace(libvirtMigration.Spec.DomainName)
+	if domainName == "" {
+		return ctrl.Result{}, r.setPhase(ctx, libvirtMigration, vjailbreakv1alpha1.ImportPhaseFailed, "domainName must be set")
+	}
+
+	libvirtCreds := &vjailbreakv1alpha1.LibvirtCreds{}
+	credsName := libvirtMigration.Spec.LibvirtCredsRef.Name
+	if err := r.Get(ctx, types.NamespacedName{Name: credsName, Namespace: libvirtMigration.Namespace}, libvirtCreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.setPhase(ctx, libvirtMigration, vjailbreakv1alpha1.ImportPhaseFailed,
+				fmt.Sprintf("LibvirtCreds '%s' not found", credsName))
+		}
+		return ctrl.Result{}, errors.Wrap(err, "failed to get libvirt credentials")
+	}
+	if libvirtCreds.Status.ValidationStatus != constants.LibvirtCredsStatusSucceeded {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.setPhase(ctx, libvirtMigration, vjailbreakv1alpha1.ImportPhasePending,
+			fmt.Sprintf("Waiting for LibvirtCreds '%s' to be validated", credsName))
+	}
+	if !slices.Contains(libvirtCreds.Status.Domains, domainName) {
+		return ctrl.Result{}, r.setPhase(ctx, libvirtMigration, vjailbreakv1alpha1.ImportPhaseFailed,
+			fmt.Sprintf("domain '%s' is not defined on %s", domainName, libvirtCreds.Spec.URI))
+	}
+
+	openstackcreds := &vjailbreakv1alpha1.OpenstackCreds{}
+	if err := r.Get(ctx, types.NamespacedName{Name: libvirtMigration.Spec.OpenstackCredsRef.Name, Namespace: libvirtMigration.Namespace}, openstackcreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.setPhase(ctx, libvirtMigration, vjailbreakv1alpha1.ImportPhaseFailed,
+				fmt.Sprintf("OpenstackCreds '%s' not found", libvirtMigration.Spec.OpenstackCredsRef.Name))
+		}
+		return ctrl.Result{}, errors.Wrap(err, "failed to get openstack credentials")
+	}
+
+	job, err := ensureImportJob(ctx, r.Client, r.Scheme, importJob{
+		owner:          libvirtMigration,
+		k8sName:        "libvirt-" + libvirtMigration.Name,
+		label:          libvirtMigrationLabel,
+		target:         libvirtMigration.Spec.ImportTargetSpec,
+		openstackcreds: openstackcreds,
+		sourceConfig: map[string]string{
+			constants.SourceTypeKey:    constants.SourceTypeLibvirt,
+			constants.LibvirtURIKey:    strings.TrimSpace(libvirtCreds.Spec.URI),
+			constants.LibvirtDomainKey: domainName,
+		},
+		env: []corev1.EnvVar{
+			{
+				Name: constants.LibvirtSSHPrivateKeyEnv,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: libvirtCreds.Spec.SecretRef.Name},
+						Key:                  libvirtSSHPrivateKeyKey,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	phase, message := importPhaseFromJob(job, "Domain migrated successfully")
+	libvirtMigration.Status.JobName = job.Name
+	return ctrl.Result{}, r.setPhase(ctx, libvirtMigration, phase, message)
+}
+
+func (r *LibvirtMigrationReconciler) setPhase(ctx context.Context, libvirtMigration *vjailbreakv1alpha1.LibvirtMigration,
+	phase vjailbreakv1alpha1.ImportPhase, message string,
+) error {
+	return setImportPhase(ctx, r.Client, libvirtMigration, &libvirtMigration.Status, phase, message)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LibvirtMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&vjailbreakv1alpha1.LibvirtMigration{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/k8s/migration/pkg/utils"
+	constants "github.com/platform9/vjailbreak/pkg/common/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newLibvirtMigrationReconciler(t *testing.T, credsStatus vjailbreakv1alpha1.LibvirtCredsStatus) (*LibvirtMigrationReconciler, client.Client) {
+	t.Helper()
+	scheme := applianceImportTestScheme(t)
+	ns := constants.NamespaceMigrationSystem
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: constants.VjailbreakSettingsConfigMapName, Namespace: ns}},
+			&vjailbreakv1alpha1.OpenstackCreds{
+				ObjectMeta: metav1.ObjectMeta{Name: "pcd", Namespace: ns},
+				Spec: vjailbreakv1alpha1.OpenstackCredsSpec{
+					SecretRef: corev1.ObjectReference{Name: "pcd-openstack-secret"},
+				},
+			},
+			&vjailbreakv1alpha1.LibvirtCreds{
+				ObjectMeta: metav1.ObjectMeta{Name: "kvm01", Namespace: ns},
+				Spec: vjailbreakv1alpha1.LibvirtCredsSpec{
+					URI:       "qemu+ssh://root@kvm01.example.com/system",
+					SecretRef: corev1.ObjectReference{Name: "kvm01-ssh"},
+				},
+				Status: credsStatus,
+			},
+			&vjailbreakv1alpha1.LibvirtMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "web01", Namespace: ns},
+				Spec: vjailbreakv1alpha1.LibvirtMigrationSpec{
+					LibvirtCredsRef: corev1.LocalObjectReference{Name: "kvm01"},
+					DomainName:      "web01",
+					ImportTargetSpec: vjailbreakv1alpha1.ImportTargetSpec{
+						OpenstackCredsRef: corev1.LocalObjectReference{Name: "pcd"},
+						NetworkNames:      []string{"mgmt"},
+					},
+				},
+			},
+		).
+		WithStatusSubresource(&vjailbreakv1alpha1.LibvirtMigration{}, &vjailbreakv1alpha1.LibvirtCreds{}).
+		Build()
+	return &LibvirtMigrationReconciler{Client: fakeClient, Scheme: scheme}, fakeClient
+}
+
+func reconcileLibvirtMigration(t *testing.T, r *LibvirtMigrationReconciler) *vjailbreakv1alpha1.LibvirtMigration {
+	t.Helper()
+	key := types.NamespacedName{Name: "web01", Namespace: constants.NamespaceMigrationSystem}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+	libvirtMigration := &vjailbreakv1alpha1.LibvirtMigration{}
+	if err := r.Get(context.Background(), key, libvirtMigration); err != nil {
+		t.Fatal(err)
+	}
+	return libvirtMigration
+}
+
+func countJobs(t *testing.T, c client.Client) int {
+	t.Helper()
+	jobs := &batchv1.JobList{}
+	if err := c.List(context.Background(), jobs); err != nil {
+		t.Fatal(err)
+	}
+	return len(jobs.Items)
+}
+
+func TestLibvirtMigrationCreatesJob(t *testing.T) {
+	r, c := newLibvirtMigrationReconciler(t, vjailbreakv1alpha1.LibvirtCredsStatus{
+		ValidationStatus: constants.LibvirtCredsStatusSucceeded,
+		Domains:          []string{"db01", "web01"},
+	})
+	ctx := context.Background()
+	ns := constants.NamespaceMigrationSystem
+
+	libvirtMigration := reconcileLibvirtMigration(t, r)
+	if libvirtMigration.Status.Phase != vjailbreakv1alpha1.ImportPhasePending || libvirtMigration.Status.JobName == "" {
+		t.Fatalf("Status = %+v, want Pending with a job", libvirtMigration.Status)
+	}
+
+	job := &batchv1.Job{}
+	if err := c.Get(ctx, types.NamespacedName{Name: libvirtMigration.Status.JobName, Namespace: ns}, job); err != nil {
+		t.Fatalf("job not created: %v", err)
+	}
+	if job.Spec.Template.Labels[libvirtMigrationLabel] != "web01" {
+		t.Errorf("pod labels = %v, want %s=web01", job.Spec.Template.Labels, libvirtMigrationLabel)
+	}
+	var keyRef *corev1.SecretKeySelector
+	for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == constants.LibvirtSSHPrivateKeyEnv && env.ValueFrom != nil {
+			keyRef = env.ValueFrom.SecretKeyRef
+		}
+	}
+	if keyRef == nil || keyRef.Name != "kvm01-ssh" || keyRef.Key != "privateKey" {
+		t.Errorf("SSH key env references %+v, want kvm01-ssh/privateKey", keyRef)
+	}
+	for _, m := range job.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if m.Name == "imports" {
+			t.Error("import directory is mounted in a libvirt migration job")
+		}
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Name: utils.GetMigrationConfigMapName("libvirt-web01"), Namespace: ns}, configMap); err != nil {
+		t.Fatalf("migration config map not created: %v", err)
+	}
+	want := map[string]string{
+		constants.SourceTypeKey:      constants.SourceTypeLibvirt,
+		constants.LibvirtURIKey:      "qemu+ssh://root@kvm01.example.com/system",
+		constants.LibvirtDomainKey:   "web01",
+		"NEUTRON_NETWORK_NAMES":      "mgmt",
+		"VMWARE_MACHINE_OBJECT_NAME": "libvirt-web01",
+	}
+	for k, v := range want {
+		if configMap.Data[k] != v {
+			t.Errorf("config map %s = %q, want %q", k, configMap.Data[k], v)
+		}
+	}
+
+	job.Status.Failed = 1
+	if err := c.Status().Update(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	libvirtMigration = reconcileLibvirtMigration(t, r)
+	if libvirtMigration.Status.Phase != vjailbreakv1alpha1.ImportPhaseFailed {
+		t.Errorf("Phase = %q after job failure, want Failed", libvirtMigration.Status.Phase)
+	}
+}
+
+func TestLibvirtMigrationWaitsForValidatedCreds(t *testing.T) {
+	r, c := newLibvirtMigrationReconciler(t, vjailbreakv1alpha1.LibvirtCredsStatus{
+		ValidationStatus: constants.LibvirtCredsStatusValidating,
+	})
+
+	libvirtMigration := reconcileLibvirtMigration(t, r)
+	if libvirtMigration.Status.Phase != vjailbreakv1alpha1.ImportPhasePending {
+		t.Errorf("Phase = %q, want Pending", libvirtMigration.Status.Phase)
+	}
+	if n := countJobs(t, c); n != 0 {
+		t.Errorf("created %d jobs before the host was validated, want 0", n)
+	}
+}
+
+func TestLibvirtMigrationRejectsUnknownDomain(t *testing.T) {
+	r, c := newLibvirtMigrationReconciler(t, vjailbreakv1alpha1.LibvirtCredsStatus{
+		ValidationStatus: constants.LibvirtCredsStatusSucceeded,
+		Domains:          []string{"db01"},
+	})
+
+	libvirtMigration := reconcileLibvirtMigration(t, r)
+	if libvirtMigration.Status.Phase != vjailbreakv1alpha1.ImportPhaseFailed {
+		t.Errorf("Phase = %q, want Failed", libvirtMigration.Status.Phase)
+	}
+	if n := countJobs(t, c); n != 0 {
+		t.Errorf("created %d jobs for an unknown domain, want 0", n)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"regexp"
 	"slices"
 	"sort"
 	"strconv"
@@ -85,6 +86,7 @@ const migrationFinalizer = "migration.vjailbreak.k8s.pf9.io/finalizer"
 // +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=bmconfigs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=bmconfigs/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=namespaces;serviceaccounts;services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings;roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
@@ -120,6 +122,15 @@ func (r *MigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
+	// Ahead of the Failed check below, a failed promotion can be run again
+	requeue, err := r.reconcilePromote(ctx, migration)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to promote staged volumes")
+	}
+	if requeue {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
 	if migration.Status.Phase == vjailbreakv1alpha1.VMMigrationPhaseValidationFailed {
 		ctxlog.Info(
 			"Migration is ValidationFailed; skipping reconciliation and requeue",
@@ -219,6 +230,7 @@ func (r *MigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	migration.Status.Conditions = utils.CreateStorageAcceleratedCopyCondition(migration, filteredEvents)
 	migration.Status.Conditions = utils.CreateDataCopyCondition(migration, filteredEvents)
 	migration.Status.Conditions = utils.CreateCutoverTriggeredCondition(migration, filteredEvents)
+	migration.Status.Conditions = utils.CreateReadyForCutoverCondition(migration)
 	migration.Status.Conditions = utils.CreateMigratingCondition(migration, filteredEvents)
 	migration.Status.Conditions = utils.CreateFailedCondition(migration, filteredEvents)
 	migration.Status.Conditions = utils.CreateSucceededCondition(migration, filteredEvents)
@@ -230,6 +242,7 @@ func (r *MigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	// Extract current disk being copied from events
 	r.ExtractCurrentDisk(migration, filteredEvents)
+	r.ExtractDiskProgress(migration, filteredEvents)
 
 	// Extract sync warning state from events
 	r.ExtractSyncWarning(migration, filteredEvents)
@@ -296,6 +309,7 @@ func (r *MigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		migration.Status.Phase != vjailbreakv1alpha1.VMMigrationPhaseValidationFailed &&
 		migration.Status.Phase != "" {
 		migrationmetrics.RecordMigrationProgress(migration.Name, migration.Spec.VMName, migration.Namespace, migration.CreationTimestamp.Time)
+		migrationmetrics.RecordDiskTransfers(migration.Name, migration.Namespace, migration.Status.DiskTransfers)
 	}
 
 	// Record completion when transitioning to a terminal state from a non-terminal state
@@ -639,7 +653,15 @@ func (r *MigrationReconciler) GetPod(ctx context.Context, scope *scope.Migration
 	if len(podList.Items) == 0 {
 		return nil, apierrors.NewNotFound(corev1.Resource("pods"), fmt.Sprintf("migration pod not found for vm %s", migration.Spec.VMName))
 	}
-	return &podList.Items[0], nil
+	// A promoted data-only migration has the pod of its promote job next to the
+	// one that staged the volumes; the newer one is followed
+	pod := &podList.Items[0]
+	for i := range podList.Items {
+		if podList.Items[i].CreationTimestamp.After(pod.CreationTimestamp.Time) {
+			pod = &podList.Items[i]
+		}
+	}
+	return pod, nil
 }
 
 // ExtractCurrentDisk extracts which disk is currently being copied from pod events
@@ -681,6 +703,47 @@ func (r *MigrationReconciler) ExtractCurrentDisk(migration *vjailbreakv1alpha1.M
 	}
 }
 
+// diskProgressRe matches the progress events of a disk copy, e.g. "Copying disk 1, Completed: 40%"
+var diskProgressRe = regexp.MustCompile(`Copying disk (\d+), Completed: (\d+)%`)
+
+// ExtractDiskProgress keeps the latest copy progress of every disk from pod events.
+// Disks of a VM can be copied in parallel, so CurrentDisk alone does not show how
+// far the others have got. Disks without a progress event left keep their last value.
+func (r *MigrationReconciler) ExtractDiskProgress(migration *vjailbreakv1alpha1.Migration, events *corev1.EventList) {
+	percents := map[string]int{}
+	for _, progress := range migration.Status.DiskProgress {
+		percents[progress.Disk] = progress.Percent
+	}
+	seen := map[string]bool{}
+	// Events are sorted by timestamp (newest first)
+	for i := range events.Items {
+		match := diskProgressRe.FindStringSubmatch(events.Items[i].Message)
+		if match == nil || seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		percents[match[1]], _ = strconv.Atoi(match[2])
+	}
+	if len(percents) == 0 {
+		return
+	}
+
+	disks := make([]string, 0, len(percents))
+	for disk := range percents {
+		disks = append(disks, disk)
+	}
+	sort.Slice(disks, func(i, j int) bool {
+		a, _ := strconv.Atoi(disks[i])
+		b, _ := strconv.Atoi(disks[j])
+		return a < b
+	})
+	migration.Status.DiskProgress = make([]vjailbreakv1alpha1.DiskCopyProgress, 0, len(disks))
+	for _, disk := range disks {
+		migration.Status.DiskProgress = append(migration.Status.DiskProgress,
+			vjailbreakv1alpha1.DiskCopyProgress{Disk: disk, Percent: percents[disk]})
+	}
+}
+
 // ExtractSyncWarning extracts the periodic sync warning state from pod events
 // It looks for "WARNING" messages which indicate the sync failed but will auto-retry
 func (r *MigrationReconciler) ExtractSyncWarning(migration *vjailbreakv1alpha1.Migration, events *corev1.EventList) {
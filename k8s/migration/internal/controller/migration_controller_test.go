@@ -203,3 +203,38 @@ func TestIsMigrationAppEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractDiskProgress(t *testing.T) {
+	// Newest first, as GetEventsSorted returns them
+	events := &corev1.EventList{Items: []corev1.Event{
+		{Message: "Copying disk 1, Completed: 40%"},
+		{Message: "Copying disk 0, Completed: 70%"},
+		{Message: "Copying disk 1, Completed: 30%"},
+		{Message: "Copying disk 10, Completed: 10%"},
+		{Message: "Copying disk 2/3: /var/lib/libvirt/images/vm-disk2.qcow2"},
+	}}
+	migration := &vjailbreakv1alpha1.Migration{
+		Status: vjailbreakv1alpha1.MigrationStatus{
+			// disk 2's events have expired
+			DiskProgress: []vjailbreakv1alpha1.DiskCopyProgress{{Disk: "0", Percent: 50}, {Disk: "2", Percent: 100}},
+		},
+	}
+
+	r := &MigrationReconciler{}
+	r.ExtractDiskProgress(migration, events)
+
+	want := []vjailbreakv1alpha1.DiskCopyProgress{
+		{Disk: "0", Percent: 70},
+		{Disk: "1", Percent: 40},
+		{Disk: "2", Percent: 100},
+		{Disk: "10", Percent: 10},
+	}
+	if len(migration.Status.DiskProgress) != len(want) {
+		t.Fatalf("DiskProgress = %v, want %v", migration.Status.DiskProgress, want)
+	}
+	for i := range want {
+		if migration.Status.DiskProgress[i] != want[i] {
+			t.Errorf("DiskProgress[%d] = %v, want %v", i, migration.Status.DiskProgress[i], want[i])
+		}
+	}
+}
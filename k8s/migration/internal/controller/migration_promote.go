@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	utils "github.com/platform9/vjailbreak/k8s/migration/pkg/utils"
+	constants "github.com/platform9/vjailbreak/pkg/common/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcilePromote starts the promote job of a data-only migration that asks for
+// Promote. The job runs the pod template of the staging job, so it gets the same
+// credentials and ConfigMaps, with PROMOTE_STAGED_VOLUMES set: the v2v-helper
+// then creates the instance from the staged volumes and never connects to the
+// source. A promotion that failed is started again once the Migration has been
+// edited since. It reports whether the caller should requeue to follow the job.
+func (r *MigrationReconciler) reconcilePromote(ctx context.Context, migration *vjailbreakv1alpha1.Migration) (bool, error) {
+	if !migration.Spec.Promote || !migration.Spec.DataOnly || len(migration.Status.StagedVolumeIDs) == 0 {
+		return false, nil
+	}
+	if migration.Status.Phase != vjailbreakv1alpha1.VMMigrationPhaseDataCopied &&
+		migration.Status.Phase != vjailbreakv1alpha1.VMMigrationPhaseFailed {
+		return false, nil
+	}
+	ctxlog := log.FromContext(ctx).WithName(constants.MigrationControllerName)
+
+	vmwareCredsName, err := utils.GetVMwareCredsNameFromMigration(ctx, r.Client, migration)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get vmware credentials name")
+	}
+	vmKey := getVMKeyFromMigration(migration)
+	promoteJobName, err := utils.GetPromoteJobNameForVMName(vmKey, vmwareCredsName)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get promote job name")
+	}
+
+	job := &batchv1.Job{}
+	err = r.Get(ctx, types.NamespacedName{Name: promoteJobName, Namespace: migration.Namespace}, job)
+	switch {
+	case err == nil:
+		if !job.DeletionTimestamp.IsZero() {
+			return true, nil
+		}
+		if migration.Status.Phase != vjailbreakv1alpha1.VMMigrationPhaseFailed ||
+			job.Annotations[constants.PromoteJobGenerationAnnotation] == strconv.FormatInt(migration.Generation, 10) {
+			return false, nil
+		}
+		ctxlog.Info("Migration edited after a failed promotion, promoting again", "migration", migration.Name, "job", promoteJobName)
+		if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return false, errors.Wrapf(err, "failed to delete promote job '%s'", promoteJobName)
+		}
+		return true, nil
+	case !apierrors.IsNotFound(err):
+		return false, errors.Wrapf(err, "failed to get promote job '%s'", promoteJobName)
+	}
+
+	stagingJobName, err := utils.GetJobNameForVMName(vmKey, vmwareCredsName)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get job name")
+	}
+	stagingJob := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: stagingJobName, Namespace: migration.Namespace}, stagingJob); err != nil {
+		return false, errors.Wrapf(err, "failed to get job '%s' of the data-only migration", stagingJobName)
+	}
+
+	job = newPromoteJob(stagingJob, promoteJobName, migration.Generation)
+	if err := ctrl.SetControllerReference(migration, job, r.Scheme); err != nil {
+		return false, errors.Wrap(err, "failed to set controller reference")
+	}
+	ctxlog.Info(fmt.Sprintf("Creating promote Job '%s' for staged volumes %v", promoteJobName, migration.Status.StagedVolumeIDs))
+	if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+		return false, errors.Wrapf(err, "failed to create promote job '%s'", promoteJobName)
+	}
+
+	// Back from Failed so that the migration is followed again
+	if migration.Status.Phase != vjailbreakv1alpha1.VMMigrationPhaseDataCopied {
+		migration.Status.Phase = vjailbreakv1alpha1.VMMigrationPhaseDataCopied
+		if err := r.Status().Update(ctx, migration); err != nil {
+			return false, errors.Wrapf(err, "failed to update status of Migration '%s'", migration.Name)
+		}
+	}
+	return true, nil
+}
+
+// newPromoteJob copies the pod template of the staging job of a data-only migration
+// into a job that promotes its staged volumes
+func newPromoteJob(stagingJob *batchv1.Job, name string, generation int64) *batchv1.Job {
+	template := stagingJob.Spec.Template.DeepCopy()
+	// The job controller adds the selector labels of the staging job to its template
+	template.Labels = map[string]string{
+		constants.VMNameLabel: stagingJob.Spec.Template.Labels[constants.VMNameLabel],
+		"startCutover":        "yes",
+	}
+	for i := range template.Spec.Containers {
+		template.Spec.Containers[i].Env = append(template.Spec.Containers[i].Env, corev1.EnvVar{
+			Name:  constants.PromoteStagedVolumesEnv,
+			Value: constants.TrueString,
+		})
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: stagingJob.Namespace,
+			Annotations: map[string]string{
+				constants.PromoteJobGenerationAnnotation: strconv.FormatInt(generation, 10),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			PodFailurePolicy: stagingJob.Spec.PodFailurePolicy.DeepCopy(),
+			Template:         *template,
+		},
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	utils "github.com/platform9/vjailbreak/k8s/migration/pkg/utils"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcilePromote(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = vjailbreakv1alpha1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	const ns = constants.NamespaceMigrationSystem
+	stagingJobName, err := utils.GetJobNameForVMName("vm-a", "vmware-creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	promoteJobName, err := utils.GetPromoteJobNameForVMName("vm-a", "vmware-creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		promote     bool
+		phase       vjailbreakv1alpha1.VMMigrationPhase
+		promoteJob  string // generation annotation of an existing promote job
+		wantRequeue bool
+		wantJob     bool
+		wantJobGen  string
+		wantPhase   vjailbreakv1alpha1.VMMigrationPhase
+	}{
+		{name: "promote not asked for", phase: vjailbreakv1alpha1.VMMigrationPhaseDataCopied,
+			wantPhase: vjailbreakv1alpha1.VMMigrationPhaseDataCopied},
+		{name: "still staging", promote: true, phase: vjailbreakv1alpha1.VMMigrationPhaseCopying,
+			wantPhase: vjailbreakv1alpha1.VMMigrationPhaseCopying},
+		{name: "staged volumes promoted", promote: true, phase: vjailbreakv1alpha1.VMMigrationPhaseDataCopied,
+			wantRequeue: true, wantJob: true, wantJobGen: "3", wantPhase: vjailbreakv1alpha1.VMMigrationPhaseDataCopied},
+		{name: "promotion running", promote: true, phase: vjailbreakv1alpha1.VMMigrationPhaseDataCopied, promoteJob: "3",
+			wantJob: true, wantJobGen: "3", wantPhase: vjailbreakv1alpha1.VMMigrationPhaseDataCopied},
+		{name: "failed promotion not edited since", promote: true, phase: vjailbreakv1alpha1.VMMigrationPhaseFailed, promoteJob: "3",
+			wantJob: true, wantJobGen: "3", wantPhase: vjailbreakv1alpha1.VMMigrationPhaseFailed},
+		{name: "failed promotion edited since", promote: true, phase: vjailbreakv1alpha1.VMMigrationPhaseFailed, promoteJob: "2",
+			wantRequeue: true, wantPhase: vjailbreakv1alpha1.VMMigrationPhaseFailed},
+		{name: "failed promotion job deleted", promote: true, phase: vjailbreakv1alpha1.VMMigrationPhaseFailed,
+			wantRequeue: true, wantJob: true, wantJobGen: "3", wantPhase: vjailbreakv1alpha1.VMMigrationPhaseDataCopied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migration := &vjailbreakv1alpha1.Migration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "migration-vm-a",
+					Namespace:   ns,
+					Generation:  3,
+					Annotations: map[string]string{constants.OriginalVMNameAnnotation: "vm-a"},
+				},
+				Spec: vjailbreakv1alpha1.MigrationSpec{
+					MigrationPlan: "plan",
+					VMName:        "vm-a",
+					DataOnly:      true,
+					Promote:       tt.promote,
+				},
+				Status: vjailbreakv1alpha1.MigrationStatus{
+					Phase:           tt.phase,
+					StagedVolumeIDs: []string{"vol-1", "vol-2"},
+				},
+			}
+			plan := &vjailbreakv1alpha1.MigrationPlan{
+				ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: ns},
+				Spec: vjailbreakv1alpha1.MigrationPlanSpec{
+					MigrationPlanSpecPerVM: vjailbreakv1alpha1.MigrationPlanSpecPerVM{MigrationTemplate: "template"},
+				},
+			}
+			template := &vjailbreakv1alpha1.MigrationTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "template", Namespace: ns},
+				Spec: vjailbreakv1alpha1.MigrationTemplateSpec{
+					Source: vjailbreakv1alpha1.MigrationTemplateSource{VMwareRef: "vmware-creds"},
+				},
+			}
+			stagingJob := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: stagingJobName, Namespace: ns},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+							constants.VMNameLabel:            "vm-a",
+							"startCutover":                   "no",
+							"batch.kubernetes.io/job-name":   stagingJobName,
+							"batch.kubernetes.io/controller": "uid",
+						}},
+						Spec: corev1.PodSpec{Containers: []corev1.Container{{
+							Name: "fedora",
+							Env:  []corev1.EnvVar{{Name: "VMWARE_MACHINE_OBJECT_NAME", Value: "vm-a"}},
+						}}},
+					},
+				},
+			}
+			objs := []client.Object{migration, plan, template, stagingJob}
+			if tt.promoteJob != "" {
+				objs = append(objs, &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+					Name:        promoteJobName,
+					Namespace:   ns,
+					Annotations: map[string]string{constants.PromoteJobGenerationAnnotation: tt.promoteJob},
+				}})
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objs...).
+				WithStatusSubresource(&vjailbreakv1alpha1.Migration{}).
+				Build()
+			r := &MigrationReconciler{Client: fakeClient, Scheme: scheme}
+
+			requeue, err := r.reconcilePromote(context.Background(), migration)
+			if err != nil {
+				t.Fatalf("reconcilePromote() unexpected error = %v", err)
+			}
+			if requeue != tt.wantRequeue {
+				t.Errorf("requeue = %v, want %v", requeue, tt.wantRequeue)
+			}
+
+			job := &batchv1.Job{}
+			err = fakeClient.Get(context.Background(), types.NamespacedName{Name: promoteJobName, Namespace: ns}, job)
+			if !tt.wantJob {
+				if !apierrors.IsNotFound(err) {
+					t.Fatalf("expected no promote job, got err = %v", err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("promote job not found: %v", err)
+				}
+				if got := job.Annotations[constants.PromoteJobGenerationAnnotation]; got != tt.wantJobGen {
+					t.Errorf("promote job generation = %q, want %q", got, tt.wantJobGen)
+				}
+			}
+
+			got := &vjailbreakv1alpha1.Migration{}
+			if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: migration.Name, Namespace: ns}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.Phase != tt.wantPhase {
+				t.Errorf("phase = %v, want %v", got.Status.Phase, tt.wantPhase)
+			}
+		})
+	}
+}
+
+func TestNewPromoteJob(t *testing.T) {
+	failJob := &batchv1.PodFailurePolicy{Rules: []batchv1.PodFailurePolicyRule{{Action: batchv1.PodFailurePolicyActionFailJob}}}
+	stagingJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "v2v-helper-vm-a", Namespace: "ns"},
+		Spec: batchv1.JobSpec{
+			PodFailurePolicy: failJob,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+					constants.VMNameLabel:          "vm-a",
+					"startCutover":                 "no",
+					"batch.kubernetes.io/job-name": "v2v-helper-vm-a",
+				}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name: "fedora",
+					Env:  []corev1.EnvVar{{Name: "VMWARE_MACHINE_OBJECT_NAME", Value: "vm-a"}},
+				}}},
+			},
+		},
+	}
+
+	job := newPromoteJob(stagingJob, "v2v-promote-vm-a", 7)
+
+	if job.Name != "v2v-promote-vm-a" || job.Namespace != "ns" {
+		t.Errorf("job = %s/%s, want ns/v2v-promote-vm-a", job.Namespace, job.Name)
+	}
+	if got := job.Annotations[constants.PromoteJobGenerationAnnotation]; got != "7" {
+		t.Errorf("generation annotation = %q, want 7", got)
+	}
+	wantLabels := map[string]string{constants.VMNameLabel: "vm-a", "startCutover": "yes"}
+	if len(job.Spec.Template.Labels) != len(wantLabels) {
+		t.Errorf("template labels = %v, want %v", job.Spec.Template.Labels, wantLabels)
+	}
+	for k, v := range wantLabels {
+		if job.Spec.Template.Labels[k] != v {
+			t.Errorf("template label %s = %q, want %q", k, job.Spec.Template.Labels[k], v)
+		}
+	}
+	env := job.Spec.Template.Spec.Containers[0].Env
+	if len(env) != 2 || env[1].Name != constants.PromoteStagedVolumesEnv || env[1].Value != constants.TrueString {
+		t.Errorf("env = %v, want VMWARE_MACHINE_OBJECT_NAME and %s=true", env, constants.PromoteStagedVolumesEnv)
+	}
+	if len(stagingJob.Spec.Template.Spec.Containers[0].Env) != 1 {
+		t.Error("staging job template was modified")
+	}
+	if job.Spec.PodFailurePolicy == nil || job.Spec.PodFailurePolicy == failJob {
+		t.Error("pod failure policy not copied from the staging job")
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	constants "github.com/platform9/vjailbreak/pkg/common/constants"
+	commonutils "github.com/platform9/vjailbreak/pkg/common/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// migrationScheduler admits migrations while their ESXi host, each of their
+// datastores and the vjailbreak agents run fewer migrations than the vjailbreak
+// settings allow. Migrations of all plans count from the time they are admitted
+// until they end; one waiting for its cutover keeps its place, as it still syncs.
+type migrationScheduler struct {
+	perESXiHost  int
+	perDatastore int
+	// total caps all migrations: the per-agent cap times the number of agents,
+	// as the agent a job lands on is only known once its pod is scheduled
+	total int
+
+	running     int
+	onHost      map[string]int
+	onDatastore map[string]int
+}
+
+// newMigrationScheduler counts the migrations that hold a place out of migrations
+func newMigrationScheduler(perESXiHost, perDatastore, perAgent, agents int,
+	migrations []vjailbreakv1alpha1.Migration,
+) *migrationScheduler {
+	s := &migrationScheduler{
+		perESXiHost:  perESXiHost,
+		perDatastore: perDatastore,
+		onHost:       map[string]int{},
+		onDatastore:  map[string]int{},
+	}
+	if perAgent > 0 {
+		s.total = perAgent * max(agents, 1)
+	}
+	for i := range migrations {
+		if !migrationIsAdmitted(&migrations[i]) || migrationIsFinished(&migrations[i]) {
+			continue
+		}
+		s.add(migrations[i].Labels[constants.ESXiHostLabel], migrationDatastores(&migrations[i]))
+	}
+	return s
+}
+
+func (s *migrationScheduler) add(host string, datastores []string) {
+	s.running++
+	if host != "" {
+		s.onHost[host]++
+	}
+	for _, ds := range datastores {
+		s.onDatastore[ds]++
+	}
+}
+
+// admit takes a place for a migration from host with disks on datastores, or
+// returns why there is none
+func (s *migrationScheduler) admit(host string, datastores []string) (bool, string) {
+	if s.total > 0 && s.running >= s.total {
+		return false, fmt.Sprintf("the vjailbreak agents already run %d of %d migrations", s.running, s.total)
+	}
+	if s.perESXiHost > 0 && host != "" && s.onHost[host] >= s.perESXiHost {
+		return false, fmt.Sprintf("ESXi host %s already runs %d of %d migrations", host, s.onHost[host], s.perESXiHost)
+	}
+	if s.perDatastore > 0 {
+		for _, ds := range datastores {
+			if s.onDatastore[ds] >= s.perDatastore {
+				return false, fmt.Sprintf("datastore %s already has %d of %d migrations", ds, s.onDatastore[ds], s.perDatastore)
+			}
+		}
+	}
+	s.add(host, datastores)
+	return true, ""
+}
+
+// migrationIsAdmitted reports whether the job of a migration may run. Migrations
+// from before the scheduler are admitted once they are past Pending.
+func migrationIsAdmitted(migration *vjailbreakv1alpha1.Migration) bool {
+	if migration.Annotations[constants.MigrationAdmittedAnnotation] != "" {
+		return true
+	}
+	switch migration.Status.Phase {
+	case "", vjailbreakv1alpha1.VMMigrationPhasePending, vjailbreakv1alpha1.VMMigrationPhaseQueued:
+		return false
+	}
+	return true
+}
+
+func migrationIsFinished(migration *vjailbreakv1alpha1.Migration) bool {
+	switch migration.Status.Phase {
+	case vjailbreakv1alpha1.VMMigrationPhaseSucceeded,
+		vjailbreakv1alpha1.VMMigrationPhaseFailed,
+		vjailbreakv1alpha1.VMMigrationPhaseValidationFailed,
+		vjailbreakv1alpha1.VMMigrationPhaseDataCopied:
+		return true
+	}
+	return false
+}
+
+// vmDatastores lists the datastores the disks of a VM are on
+func vmDatastores(vminfo *vjailbreakv1alpha1.VMInfo) []string {
+	datastores := []string{}
+	for _, disk := range vminfo.Disks {
+		if disk.Datastore != "" && !slices.Contains(datastores, disk.Datastore) {
+			datastores = append(datastores, disk.Datastore)
+		}
+	}
+	slices.Sort(datastores)
+	return datastores
+}
+
+// migrationDatastores reads the datastores recorded on a Migration
+func migrationDatastores(migration *vjailbreakv1alpha1.Migration) []string {
+	datastores := []string{}
+	if v := migration.Annotations[constants.MigrationDatastoresAnnotation]; v != "" {
+		_ = json.Unmarshal([]byte(v), &datastores)
+	}
+	return datastores
+}
+
+// migrationAdmissionMu serializes admissions across the concurrent reconciles of
+// all plans, so that each one counts the places the others took
+var migrationAdmissionMu sync.Mutex
+
+// scheduleMigration admits migrationobj if there is a place for it, see
+// admitMigration. The places are counted from the API server rather than the
+// cache, which may not have seen the admissions of other reconciles yet.
+func (r *MigrationPlanReconciler) scheduleMigration(ctx context.Context, migrationobj *vjailbreakv1alpha1.Migration,
+	vmMachine *vjailbreakv1alpha1.VMwareMachine, agents int,
+) (bool, error) {
+	migrationAdmissionMu.Lock()
+	defer migrationAdmissionMu.Unlock()
+
+	latest := &vjailbreakv1alpha1.Migration{}
+	if err := r.apiReader().Get(ctx, client.ObjectKeyFromObject(migrationobj), latest); err != nil {
+		return false, errors.Wrapf(err, "failed to get Migration '%s'", migrationobj.Name)
+	}
+	*migrationobj = *latest
+	if migrationIsAdmitted(migrationobj) {
+		return true, nil
+	}
+	scheduler, err := r.loadMigrationScheduler(ctx, migrationobj.Namespace, agents)
+	if err != nil {
+		return false, err
+	}
+	return r.admitMigration(ctx, scheduler, migrationobj, vmMachine)
+}
+
+// apiReader returns APIReader, or the cached client where none is set
+func (r *MigrationPlanReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// loadMigrationScheduler reads the limits from the vjailbreak settings and counts
+// the migrations of all plans in the namespace
+func (r *MigrationPlanReconciler) loadMigrationScheduler(ctx context.Context, namespace string, agents int) (*migrationScheduler, error) {
+	vjailbreakSettings, err := k8sutils.GetVjailbreakSettings(ctx, r.Client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get vjailbreak settings for the migration limits")
+	}
+	migrations := &vjailbreakv1alpha1.MigrationList{}
+	if err := r.apiReader().List(ctx, migrations, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, "failed to list migrations")
+	}
+	return newMigrationScheduler(vjailbreakSettings.MaxMigrationsPerESXiHost, vjailbreakSettings.MaxMigrationsPerDatastore,
+		vjailbreakSettings.MaxMigrationsPerAgent, agents, migrations.Items), nil
+}
+
+// admitMigration reports whether the job of migrationobj may be created. A
+// migration the scheduler has no place for is moved to the Queued phase with the
+// reason; once admitted it goes back to Pending until its pod runs.
+func (r *MigrationPlanReconciler) admitMigration(ctx context.Context, scheduler *migrationScheduler,
+	migrationobj *vjailbreakv1alpha1.Migration, vmMachine *vjailbreakv1alpha1.VMwareMachine,
+) (bool, error) {
+	if migrationIsAdmitted(migrationobj) {
+		return true, nil
+	}
+	datastores := vmDatastores(&vmMachine.Spec.VMInfo)
+	admitted, reason := scheduler.admit(commonutils.SanitizeLabelValue(vmMachine.Spec.VMInfo.ESXiName), datastores)
+
+	if admitted {
+		datastoresJSON, err := json.Marshal(datastores)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to marshal datastores")
+		}
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			latest := &vjailbreakv1alpha1.Migration{}
+			if getErr := r.apiReader().Get(ctx, types.NamespacedName{Name: migrationobj.Name, Namespace: migrationobj.Namespace}, latest); getErr != nil {
+				return getErr
+			}
+			if latest.Annotations == nil {
+				latest.Annotations = map[string]string{}
+			}
+			latest.Annotations[constants.MigrationAdmittedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+			latest.Annotations[constants.MigrationDatastoresAnnotation] = string(datastoresJSON)
+			if updateErr := r.Update(ctx, latest); updateErr != nil {
+				return updateErr
+			}
+			migrationobj.ObjectMeta = latest.ObjectMeta
+			return nil
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to admit Migration '%s'", migrationobj.Name)
+		}
+	}
+
+	phase := vjailbreakv1alpha1.VMMigrationPhaseQueued
+	if admitted {
+		phase = vjailbreakv1alpha1.VMMigrationPhasePending
+		r.ctxlog.Info("Migration admitted", "migration", migrationobj.Name)
+	} else {
+		r.ctxlog.Info("Migration queued", "migration", migrationobj.Name, "reason", reason)
+	}
+	if migrationobj.Status.Phase == phase && migrationobj.Status.QueuedReason == reason {
+		return admitted, nil
+	}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &vjailbreakv1alpha1.Migration{}
+		if getErr := r.apiReader().Get(ctx, types.NamespacedName{Name: migrationobj.Name, Namespace: migrationobj.Namespace}, latest); getErr != nil {
+			return getErr
+		}
+		latest.Status.Phase = phase
+		latest.Status.QueuedReason = reason
+		if updateErr := r.Status().Update(ctx, latest); updateErr != nil {
+			return updateErr
+		}
+		migrationobj.Status = latest.Status
+		return nil
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to update status of Migration '%s'", migrationobj.Name)
+	}
+	return admitted, nil
+}
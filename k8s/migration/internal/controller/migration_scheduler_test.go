@@ -0,0 +1,244 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func schedulerTestMigration(name, host, datastores string, phase vjailbreakv1alpha1.VMMigrationPhase, admitted bool) vjailbreakv1alpha1.Migration {
+	migration := vjailbreakv1alpha1.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   constants.NamespaceMigrationSystem,
+			Labels:      map[string]string{constants.ESXiHostLabel: host},
+			Annotations: map[string]string{constants.MigrationDatastoresAnnotation: datastores},
+		},
+		Status: vjailbreakv1alpha1.MigrationStatus{Phase: phase},
+	}
+	if admitted {
+		migration.Annotations[constants.MigrationAdmittedAnnotation] = "2026-01-01T00:00:00Z"
+	}
+	return migration
+}
+
+func TestMigrationScheduler(t *testing.T) {
+	running := []vjailbreakv1alpha1.Migration{
+		schedulerTestMigration("copying", "esxi-1", `["ds-1"]`, vjailbreakv1alpha1.VMMigrationPhaseCopying, true),
+		schedulerTestMigration("starting", "esxi-1", `["ds-2"]`, vjailbreakv1alpha1.VMMigrationPhasePending, true),
+		// Not counted
+		schedulerTestMigration("queued", "esxi-1", `["ds-1"]`, vjailbreakv1alpha1.VMMigrationPhaseQueued, false),
+		schedulerTestMigration("done", "esxi-1", `["ds-1"]`, vjailbreakv1alpha1.VMMigrationPhaseSucceeded, true),
+		// Started before the scheduler
+		schedulerTestMigration("legacy", "esxi-2", "", vjailbreakv1alpha1.VMMigrationPhaseAwaitingAdminCutOver, false),
+	}
+
+	tests := []struct {
+		name                            string
+		perHost, perDatastore, perAgent int
+		agents                          int
+		host                            string
+		datastores                      []string
+		wantAdmitted                    bool
+		wantReason                      string
+	}{
+		{name: "unlimited", host: "esxi-1", datastores: []string{"ds-1"}, wantAdmitted: true},
+		{name: "host full", perHost: 2, host: "esxi-1", wantAdmitted: false,
+			wantReason: "ESXi host esxi-1 already runs 2 of 2 migrations"},
+		{name: "other host", perHost: 2, host: "esxi-3", wantAdmitted: true},
+		{name: "datastore full", perDatastore: 1, host: "esxi-3", datastores: []string{"ds-3", "ds-2"}, wantAdmitted: false,
+			wantReason: "datastore ds-2 already has 1 of 1 migrations"},
+		{name: "agents full", perAgent: 1, agents: 3, host: "esxi-3", wantAdmitted: false,
+			wantReason: "the vjailbreak agents already run 3 of 3 migrations"},
+		{name: "agents free", perAgent: 2, agents: 2, host: "esxi-3", wantAdmitted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newMigrationScheduler(tt.perHost, tt.perDatastore, tt.perAgent, tt.agents, running)
+			admitted, reason := s.admit(tt.host, tt.datastores)
+			if admitted != tt.wantAdmitted || reason != tt.wantReason {
+				t.Errorf("admit() = %v, %q, want %v, %q", admitted, reason, tt.wantAdmitted, tt.wantReason)
+			}
+		})
+	}
+
+	// An admitted migration takes its place at once
+	s := newMigrationScheduler(3, 0, 0, 1, running)
+	if admitted, _ := s.admit("esxi-1", nil); !admitted {
+		t.Fatal("third migration on esxi-1 not admitted")
+	}
+	if admitted, _ := s.admit("esxi-1", nil); admitted {
+		t.Error("fourth migration on esxi-1 admitted")
+	}
+}
+
+func TestAdmitMigration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = vjailbreakv1alpha1.AddToScheme(scheme)
+
+	running := schedulerTestMigration("migration-running", "esxi-1", `["ds-1"]`, vjailbreakv1alpha1.VMMigrationPhaseCopying, true)
+	waiting := schedulerTestMigration("migration-waiting", "esxi-1", "", vjailbreakv1alpha1.VMMigrationPhasePending, false)
+	delete(waiting.Annotations, constants.MigrationDatastoresAnnotation)
+	vmMachine := &vjailbreakv1alpha1.VMwareMachine{
+		Spec: vjailbreakv1alpha1.VMwareMachineSpec{
+			VMInfo: vjailbreakv1alpha1.VMInfo{
+				ESXiName: "esxi-1",
+				Disks: []vjailbreakv1alpha1.Disk{
+					{Name: "disk-1", Datastore: "ds-2"},
+					{Name: "disk-2", Datastore: "ds-1"},
+					{Name: "disk-3", Datastore: "ds-2"},
+				},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(&running, &waiting).
+		WithStatusSubresource(&vjailbreakv1alpha1.Migration{}).
+		Build()
+	r := &MigrationPlanReconciler{Client: fakeClient, Scheme: scheme, ctxlog: logr.Discard()}
+	ctx := context.Background()
+	key := types.NamespacedName{Name: waiting.Name, Namespace: waiting.Namespace}
+
+	// Queued while esxi-1 runs its one migration
+	migrationobj := &vjailbreakv1alpha1.Migration{}
+	if err := fakeClient.Get(ctx, key, migrationobj); err != nil {
+		t.Fatal(err)
+	}
+	scheduler := newMigrationScheduler(1, 0, 0, 1, []vjailbreakv1alpha1.Migration{running, waiting})
+	admitted, err := r.admitMigration(ctx, scheduler, migrationobj, vmMachine)
+	if err != nil {
+		t.Fatalf("admitMigration() unexpected error = %v", err)
+	}
+	if admitted {
+		t.Error("migration admitted while its ESXi host is full")
+	}
+	got := &vjailbreakv1alpha1.Migration{}
+	if err := fakeClient.Get(ctx, key, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Phase != vjailbreakv1alpha1.VMMigrationPhaseQueued ||
+		got.Status.QueuedReason != "ESXi host esxi-1 already runs 1 of 1 migrations" {
+		t.Errorf("status = %s %q, want Queued with the ESXi host as reason", got.Status.Phase, got.Status.QueuedReason)
+	}
+
+	// Admitted once the limit allows it, with its datastores recorded
+	scheduler = newMigrationScheduler(2, 0, 0, 1, []vjailbreakv1alpha1.Migration{running, *got})
+	admitted, err = r.admitMigration(ctx, scheduler, got, vmMachine)
+	if err != nil {
+		t.Fatalf("admitMigration() unexpected error = %v", err)
+	}
+	if !admitted {
+		t.Error("migration not admitted")
+	}
+	if err := fakeClient.Get(ctx, key, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Phase != vjailbreakv1alpha1.VMMigrationPhasePending || got.Status.QueuedReason != "" {
+		t.Errorf("status = %s %q, want Pending without reason", got.Status.Phase, got.Status.QueuedReason)
+	}
+	if got.Annotations[constants.MigrationAdmittedAnnotation] == "" {
+		t.Error("admitted annotation not set")
+	}
+	if ds := got.Annotations[constants.MigrationDatastoresAnnotation]; ds != `["ds-1","ds-2"]` {
+		t.Errorf("datastores annotation = %s, want [\"ds-1\",\"ds-2\"]", ds)
+	}
+
+	// Counted from then on, even before its pod runs
+	scheduler = newMigrationScheduler(2, 0, 0, 1, []vjailbreakv1alpha1.Migration{running, *got})
+	if admitted, _ := scheduler.admit("esxi-1", nil); admitted {
+		t.Error("admitted migration not counted")
+	}
+}
+
+// slowLister lists like its Reader, then gives a competing reconcile time to
+// list the same migrations before the caller acts on them
+type slowLister struct {
+	client.Reader
+}
+
+func (l slowLister) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	err := l.Reader.List(ctx, list, opts...)
+	time.Sleep(100 * time.Millisecond)
+	return err
+}
+
+// TestScheduleMigrationCompetingPlans checks that two plans reconciled at once
+// cannot both take the one place their ESXi host has
+func TestScheduleMigrationCompetingPlans(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = vjailbreakv1alpha1.AddToScheme(scheme)
+
+	settings := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: constants.VjailbreakSettingsConfigMapName, Namespace: constants.NamespaceMigrationSystem},
+		Data:       map[string]string{constants.MaxMigrationsPerESXiHostKey: "1"},
+	}
+	planA := schedulerTestMigration("migration-plan-a", "esxi-1", "", vjailbreakv1alpha1.VMMigrationPhasePending, false)
+	planB := schedulerTestMigration("migration-plan-b", "esxi-1", "", vjailbreakv1alpha1.VMMigrationPhasePending, false)
+	vmMachine := &vjailbreakv1alpha1.VMwareMachine{
+		Spec: vjailbreakv1alpha1.VMwareMachineSpec{VMInfo: vjailbreakv1alpha1.VMInfo{ESXiName: "esxi-1"}},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(settings, &planA, &planB).
+		WithStatusSubresource(&vjailbreakv1alpha1.Migration{}).
+		Build()
+	r := &MigrationPlanReconciler{Client: fakeClient, Scheme: scheme, APIReader: slowLister{fakeClient}, ctxlog: logr.Discard()}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make([]bool, 2)
+	for i, migration := range []vjailbreakv1alpha1.Migration{planA, planB} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			migrationobj := migration.DeepCopy()
+			admitted, err := r.scheduleMigration(ctx, migrationobj, vmMachine, 1)
+			if err != nil {
+				t.Errorf("scheduleMigration(%s) unexpected error = %v", migrationobj.Name, err)
+			}
+			results[i] = admitted
+		}()
+	}
+	wg.Wait()
+	if results[0] == results[1] {
+		t.Errorf("admitted = %v, want exactly one of the two plans admitted", results)
+	}
+
+	// The one admitted keeps its place when its plan is reconciled again
+	winner := planA.DeepCopy()
+	if results[1] {
+		winner = planB.DeepCopy()
+	}
+	if admitted, err := r.scheduleMigration(ctx, winner, vmMachine, 1); err != nil || !admitted {
+		t.Errorf("scheduleMigration(%s) = %v, %v, want admitted again", winner.Name, admitted, err)
+	}
+}
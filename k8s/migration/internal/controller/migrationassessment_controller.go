@@ -0,0 +1,260 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/k8s/migration/pkg/utils"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// assessmentReportLimit keeps the report ConfigMap under the 1 MiB object size
+// limit, with room for its metadata
+const assessmentReportLimit = 1000 * 1024
+
+// MigrationAssessmentReconciler reconciles a MigrationAssessment object
+type MigrationAssessmentReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=migrationassessments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=migrationassessments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=migrationassessments/finalizers,verbs=update
+
+// Reconcile assesses the VMs of the MigrationAssessment, writes the per-VM
+// report to a ConfigMap and summarises it in the status. The VMs are assessed
+// again every MigrationAssessmentRefreshInterval and whenever the spec changes.
+func (r *MigrationAssessmentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctxlog := log.FromContext(ctx).WithName(constants.MigrationAssessmentControllerName)
+
+	assessment := &vjailbreakv1alpha1.MigrationAssessment{}
+	if err := r.Get(ctx, req.NamespacedName, assessment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !assessment.DeletionTimestamp.IsZero() {
+		// The report ConfigMap is owned by the assessment and garbage collected with it
+		return ctrl.Result{}, nil
+	}
+
+	if due := assessmentDue(assessment, time.Now()); due > 0 {
+		return ctrl.Result{RequeueAfter: due}, nil
+	}
+
+	ctxlog.Info("Assessing VMs", "name", assessment.Name, "vmwarecreds", assessment.Spec.VMwareCredsRef.Name)
+	targets, err := r.assessmentTargets(ctx, assessment)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{RequeueAfter: constants.MigrationAssessmentRefreshInterval},
+				r.setAssessmentStatus(ctx, assessment, vjailbreakv1alpha1.AssessmentPhaseFailed, err.Error())
+		}
+		return ctrl.Result{}, err
+	}
+
+	vmwvms, missing, err := r.assessedMachines(ctx, assessment)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	assessments := make([]utils.VMAssessment, 0, len(vmwvms))
+	for i := range vmwvms {
+		assessments = append(assessments, utils.AssessVM(&vmwvms[i], targets))
+	}
+
+	message, err := r.writeAssessmentReport(ctx, assessment, assessments)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(missing) > 0 {
+		message += fmt.Sprintf(". VMs not found: %s", strings.Join(missing, ", "))
+	}
+
+	assessment.Status.Summary, assessment.Status.Issues = utils.SummarizeAssessments(assessments)
+	assessment.Status.ReportConfigMap = assessmentReportName(assessment)
+	now := metav1.Now()
+	assessment.Status.AssessedAt = &now
+	ctxlog.Info("Assessed VMs", "name", assessment.Name, "total", assessment.Status.Summary.TotalVMs,
+		"ready", assessment.Status.Summary.Ready, "blocked", assessment.Status.Summary.Blocked)
+	return ctrl.Result{RequeueAfter: constants.MigrationAssessmentRefreshInterval},
+		r.setAssessmentStatus(ctx, assessment, vjailbreakv1alpha1.AssessmentPhaseCompleted, message)
+}
+
+// assessmentDue returns how long until the VMs of an assessment are due to be
+// assessed again, 0 when they are due now
+func assessmentDue(assessment *vjailbreakv1alpha1.MigrationAssessment, now time.Time) time.Duration {
+	status := &assessment.Status
+	if status.AssessedAt == nil || status.ObservedGeneration != assessment.Generation {
+		return 0
+	}
+	return max(status.AssessedAt.Add(constants.MigrationAssessmentRefreshInterval).Sub(now), 0)
+}
+
+// assessmentTargets fetches the credentials and mappings the VMs are assessed
+// against. A missing one is returned as a NotFound error.
+func (r *MigrationAssessmentReconciler) assessmentTargets(ctx context.Context,
+	assessment *vjailbreakv1alpha1.MigrationAssessment,
+) (utils.AssessmentTargets, error) {
+	targets := utils.AssessmentTargets{}
+	get := func(kind, name string, obj client.Object) error {
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: assessment.Namespace}, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				return err
+			}
+			return errors.Wrapf(err, "failed to get %s '%s'", kind, name)
+		}
+		return nil
+	}
+
+	if err := get("VMwareCreds", assessment.Spec.VMwareCredsRef.Name, &vjailbreakv1alpha1.VMwareCreds{}); err != nil {
+		return targets, err
+	}
+	if ref := assessment.Spec.OpenstackCredsRef; ref != nil && ref.Name != "" {
+		targets.OpenstackCreds = &vjailbreakv1alpha1.OpenstackCreds{}
+		if err := get("OpenstackCreds", ref.Name, targets.OpenstackCreds); err != nil {
+			return targets, err
+		}
+	}
+	if name := assessment.Spec.NetworkMapping; name != "" {
+		targets.NetworkMapping = &vjailbreakv1alpha1.NetworkMapping{}
+		if err := get("NetworkMapping", name, targets.NetworkMapping); err != nil {
+			return targets, err
+		}
+	}
+	if name := assessment.Spec.StorageMapping; name != "" {
+		targets.StorageMapping = &vjailbreakv1alpha1.StorageMapping{}
+		if err := get("StorageMapping", name, targets.StorageMapping); err != nil {
+			return targets, err
+		}
+	}
+	return targets, nil
+}
+
+// assessedMachines lists the VMwareMachines of the credentials, limited to the
+// VMs of the spec when it names some, sorted by VM name. It also returns the
+// VMs of the spec that were not found.
+func (r *MigrationAssessmentReconciler) assessedMachines(ctx context.Context,
+	assessment *vjailbreakv1alpha1.MigrationAssessment,
+) ([]vjailbreakv1alpha1.VMwareMachine, []string, error) {
+	vmwvmList := &vjailbreakv1alpha1.VMwareMachineList{}
+	if err := r.List(ctx, vmwvmList, client.InNamespace(assessment.Namespace),
+		client.MatchingLabels{constants.VMwareCredsLabel: assessment.Spec.VMwareCredsRef.Name}); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list VMwareMachines")
+	}
+	vmwvms := vmwvmList.Items
+	var missing []string
+	if len(assessment.Spec.VMs) > 0 {
+		byName := make(map[string]vjailbreakv1alpha1.VMwareMachine, len(vmwvms))
+		for _, vmwvm := range vmwvms {
+			byName[vmwvm.Spec.VMInfo.Name] = vmwvm
+		}
+		vmwvms = make([]vjailbreakv1alpha1.VMwareMachine, 0, len(assessment.Spec.VMs))
+		for _, name := range assessment.Spec.VMs {
+			vmwvm, ok := byName[name]
+			if !ok {
+				missing = append(missing, name)
+				continue
+			}
+			vmwvms = append(vmwvms, vmwvm)
+		}
+	}
+	sort.Slice(vmwvms, func(i, j int) bool {
+		return vmwvms[i].Spec.VMInfo.Name < vmwvms[j].Spec.VMInfo.Name
+	})
+	return vmwvms, missing, nil
+}
+
+// assessmentReportName is the name of the ConfigMap holding the report of an assessment
+func assessmentReportName(assessment *vjailbreakv1alpha1.MigrationAssessment) string {
+	return assessment.Name + constants.MigrationAssessmentReportSuffix
+}
+
+// writeAssessmentReport writes the report as JSON and CSV to the report
+// ConfigMap and returns the status message. The JSON is left out when both
+// would not fit in a ConfigMap, the CSV is smaller.
+func (r *MigrationAssessmentReconciler) writeAssessmentReport(ctx context.Context,
+	assessment *vjailbreakv1alpha1.MigrationAssessment, assessments []utils.VMAssessment,
+) (string, error) {
+	reportJSON, err := utils.AssessmentReportJSON(assessments)
+	if err != nil {
+		return "", err
+	}
+	reportCSV, err := utils.AssessmentReportCSV(assessments)
+	if err != nil {
+		return "", err
+	}
+	data := map[string]string{
+		constants.AssessmentReportJSONKey: reportJSON,
+		constants.AssessmentReportCSVKey:  reportCSV,
+	}
+	message := fmt.Sprintf("Assessed %d VMs", len(assessments))
+	if len(reportJSON)+len(reportCSV) > assessmentReportLimit {
+		delete(data, constants.AssessmentReportJSONKey)
+		message += fmt.Sprintf(", %s was left out as the report is too large for a ConfigMap", constants.AssessmentReportJSONKey)
+	}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      assessmentReportName(assessment),
+		Namespace: assessment.Namespace,
+	}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Data = data
+		return controllerutil.SetControllerReference(assessment, configMap, r.Scheme)
+	}); err != nil {
+		return "", errors.Wrapf(err, "failed to write assessment report '%s'", configMap.Name)
+	}
+	return message, nil
+}
+
+// setAssessmentStatus records the phase of the assessment and the generation it was made for
+func (r *MigrationAssessmentReconciler) setAssessmentStatus(ctx context.Context,
+	assessment *vjailbreakv1alpha1.MigrationAssessment, phase vjailbreakv1alpha1.AssessmentPhase, message string,
+) error {
+	assessment.Status.Phase = phase
+	assessment.Status.Message = message
+	assessment.Status.ObservedGeneration = assessment.Generation
+	if err := r.Status().Update(ctx, assessment); err != nil {
+		return errors.Wrapf(err, "failed to update status of '%s'", assessment.Name)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MigrationAssessmentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&vjailbreakv1alpha1.MigrationAssessment{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/k8s/migration/pkg/utils"
+	constants "github.com/platform9/vjailbreak/pkg/common/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func assessmentTestVMwareMachine(name, creds string, vminfo vjailbreakv1alpha1.VMInfo) *vjailbreakv1alpha1.VMwareMachine {
+	vminfo.Name = name
+	return &vjailbreakv1alpha1.VMwareMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-" + creds,
+			Namespace: constants.NamespaceMigrationSystem,
+			Labels:    map[string]string{constants.VMwareCredsLabel: creds},
+		},
+		Spec: vjailbreakv1alpha1.VMwareMachineSpec{VMInfo: vminfo},
+	}
+}
+
+func newMigrationAssessmentReconciler(t *testing.T, spec vjailbreakv1alpha1.MigrationAssessmentSpec) (*MigrationAssessmentReconciler, client.Client) {
+	t.Helper()
+	scheme := applianceImportTestScheme(t)
+	ns := constants.NamespaceMigrationSystem
+	ready := vjailbreakv1alpha1.VMInfo{
+		OSFamily: "windowsGuest",
+		Networks: []string{"VM Network"},
+		Disks:    []vjailbreakv1alpha1.Disk{{Name: "Hard disk 1", Datastore: "ds1"}},
+		Hardware: vjailbreakv1alpha1.VMHardware{Version: 19, CBTEnabled: true},
+	}
+	blocked := ready
+	blocked.Networks = []string{"DMZ"}
+	warned := ready
+	warned.Hardware.SerialPorts = 1
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&vjailbreakv1alpha1.VMwareCreds{ObjectMeta: metav1.ObjectMeta{Name: "vcenter", Namespace: ns}},
+			&vjailbreakv1alpha1.NetworkMapping{
+				ObjectMeta: metav1.ObjectMeta{Name: "nwmap", Namespace: ns},
+				Spec: vjailbreakv1alpha1.NetworkMappingSpec{Networks: []vjailbreakv1alpha1.Network{
+					{Source: "VM Network", Target: "provider"},
+				}},
+			},
+			assessmentTestVMwareMachine("app", "vcenter", ready),
+			assessmentTestVMwareMachine("dmz", "vcenter", blocked),
+			assessmentTestVMwareMachine("console", "vcenter", warned),
+			assessmentTestVMwareMachine("other", "othervcenter", blocked),
+			&vjailbreakv1alpha1.MigrationAssessment{
+				ObjectMeta: metav1.ObjectMeta{Name: "inventory", Namespace: ns, Generation: 1},
+				Spec:       spec,
+			},
+		).
+		WithStatusSubresource(&vjailbreakv1alpha1.MigrationAssessment{}).
+		Build()
+	return &MigrationAssessmentReconciler{Client: fakeClient, Scheme: scheme}, fakeClient
+}
+
+func reconcileMigrationAssessment(t *testing.T, r *MigrationAssessmentReconciler) (ctrl.Result, *vjailbreakv1alpha1.MigrationAssessment) {
+	t.Helper()
+	key := types.NamespacedName{Name: "inventory", Namespace: constants.NamespaceMigrationSystem}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+	assessment := &vjailbreakv1alpha1.MigrationAssessment{}
+	if err := r.Get(context.Background(), key, assessment); err != nil {
+		t.Fatal(err)
+	}
+	return result, assessment
+}
+
+func TestMigrationAssessmentReport(t *testing.T) {
+	r, c := newMigrationAssessmentReconciler(t, vjailbreakv1alpha1.MigrationAssessmentSpec{
+		VMwareCredsRef: corev1.LocalObjectReference{Name: "vcenter"},
+		NetworkMapping: "nwmap",
+	})
+	result, assessment := reconcileMigrationAssessment(t, r)
+
+	if assessment.Status.Phase != vjailbreakv1alpha1.AssessmentPhaseCompleted {
+		t.Fatalf("phase = %s, want Completed: %s", assessment.Status.Phase, assessment.Status.Message)
+	}
+	if result.RequeueAfter != constants.MigrationAssessmentRefreshInterval {
+		t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, constants.MigrationAssessmentRefreshInterval)
+	}
+	expectedSummary := vjailbreakv1alpha1.AssessmentSummary{TotalVMs: 3, Ready: 1, NeedsAttention: 1, Blocked: 1, AverageScore: 61}
+	if assessment.Status.Summary != expectedSummary {
+		t.Errorf("summary = %+v, want %+v", assessment.Status.Summary, expectedSummary)
+	}
+	if len(assessment.Status.Issues) != 2 || assessment.Status.Issues[0].Check != vjailbreakv1alpha1.AssessmentCheckNetworkMapping {
+		t.Errorf("issues = %+v, want the network mapping first", assessment.Status.Issues)
+	}
+	if assessment.Status.ObservedGeneration != 1 || assessment.Status.AssessedAt == nil {
+		t.Errorf("status = %+v, want generation 1 assessed", assessment.Status)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(context.Background(), types.NamespacedName{
+		Name: assessment.Status.ReportConfigMap, Namespace: constants.NamespaceMigrationSystem,
+	}, configMap); err != nil {
+		t.Fatalf("report ConfigMap not written: %v", err)
+	}
+	if len(configMap.OwnerReferences) != 1 || configMap.OwnerReferences[0].Name != "inventory" {
+		t.Errorf("report ConfigMap owners = %+v, want the assessment", configMap.OwnerReferences)
+	}
+	var report []utils.VMAssessment
+	if err := json.Unmarshal([]byte(configMap.Data[constants.AssessmentReportJSONKey]), &report); err != nil {
+		t.Fatalf("report.json does not decode: %v", err)
+	}
+	names := make([]string, 0, len(report))
+	for _, vm := range report {
+		names = append(names, vm.Name)
+	}
+	if strings.Join(names, ",") != "app,console,dmz" {
+		t.Errorf("report VMs = %v, want the VMs of vcenter by name", names)
+	}
+	if !strings.HasPrefix(configMap.Data[constants.AssessmentReportCSVKey], "name,vmwareMachine,") {
+		t.Errorf("report.csv = %q", configMap.Data[constants.AssessmentReportCSVKey])
+	}
+
+	// A second reconcile before the refresh interval assesses nothing
+	result, _ = reconcileMigrationAssessment(t, r)
+	if result.RequeueAfter <= 0 || result.RequeueAfter > constants.MigrationAssessmentRefreshInterval {
+		t.Errorf("RequeueAfter = %v, want the time left until the refresh", result.RequeueAfter)
+	}
+}
+
+func TestMigrationAssessmentSelectedVMs(t *testing.T) {
+	r, _ := newMigrationAssessmentReconciler(t, vjailbreakv1alpha1.MigrationAssessmentSpec{
+		VMwareCredsRef: corev1.LocalObjectReference{Name: "vcenter"},
+		VMs:            []string{"dmz", "gone"},
+	})
+	_, assessment := reconcileMigrationAssessment(t, r)
+	if assessment.Status.Summary.TotalVMs != 1 || assessment.Status.Summary.Ready != 1 {
+		t.Errorf("summary = %+v, want dmz alone, ready without a network mapping", assessment.Status.Summary)
+	}
+	if !strings.Contains(assessment.Status.Message, "VMs not found: gone") {
+		t.Errorf("message = %q, want the missing VM", assessment.Status.Message)
+	}
+}
+
+func TestMigrationAssessmentMissingReference(t *testing.T) {
+	r, _ := newMigrationAssessmentReconciler(t, vjailbreakv1alpha1.MigrationAssessmentSpec{
+		VMwareCredsRef: corev1.LocalObjectReference{Name: "vcenter"},
+		StorageMapping: "missing",
+	})
+	_, assessment := reconcileMigrationAssessment(t, r)
+	if assessment.Status.Phase != vjailbreakv1alpha1.AssessmentPhaseFailed || !strings.Contains(assessment.Status.Message, "missing") {
+		t.Errorf("status = %s %q, want Failed naming the StorageMapping", assessment.Status.Phase, assessment.Status.Message)
+	}
+}
+
+func TestAssessmentDue(t *testing.T) {
+	now := time.Now()
+	assessment := &vjailbreakv1alpha1.MigrationAssessment{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	if due := assessmentDue(assessment, now); due != 0 {
+		t.Errorf("never assessed: due in %v, want now", due)
+	}
+	assessedAt := metav1.NewTime(now.Add(-10 * time.Minute))
+	assessment.Status.AssessedAt = &assessedAt
+	assessment.Status.ObservedGeneration = 1
+	if due := assessmentDue(assessment, now); due != 0 {
+		t.Errorf("spec changed: due in %v, want now", due)
+	}
+	assessment.Status.ObservedGeneration = 2
+	if due := assessmentDue(assessment, now); due != constants.MigrationAssessmentRefreshInterval-10*time.Minute {
+		t.Errorf("due in %v, want %v", due, constants.MigrationAssessmentRefreshInterval-10*time.Minute)
+	}
+	assessedAt = metav1.NewTime(now.Add(-2 * constants.MigrationAssessmentRefreshInterval))
+	if due := assessmentDue(assessment, now); due != 0 {
+		t.Errorf("stale: due in %v, want now", due)
+	}
+}
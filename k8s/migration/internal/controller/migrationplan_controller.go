@@ -34,10 +34,14 @@ import (
 	utils "github.com/platform9/vjailbreak/k8s/migration/pkg/utils"
 
 	"github.com/platform9/vjailbreak/k8s/migration/pkg/verrors"
+	"github.com/platform9/vjailbreak/pkg/common/bandwidth"
 	"github.com/platform9/vjailbreak/pkg/common/constants"
 	openstackpkg "github.com/platform9/vjailbreak/pkg/common/openstack"
 	commonutils "github.com/platform9/vjailbreak/pkg/common/utils"
+	hpesdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/hpalletra"
 	netappsdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/netapp"
+	powerstoresdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/powerstore"
+	simulatorsdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/simulator"
 	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
 	"github.com/platform9/vjailbreak/v2v-helper/vcenter"
 
@@ -74,7 +78,10 @@ const StorageCopyMethod = "StorageAcceleratedCopy"
 // MigrationPlanReconciler reconciles a MigrationPlan object
 type MigrationPlanReconciler struct {
 	client.Client
-	Scheme                  *runtime.Scheme
+	Scheme *runtime.Scheme
+	// APIReader reads past the cache where reconciles of different plans must
+	// see each other's writes, see scheduleMigration
+	APIReader               client.Reader
 	ctxlog                  logr.Logger
 	MaxConcurrentReconciles int
 }
@@ -89,6 +96,7 @@ var v2vimage = "platform9/v2v-helper:v0.1"
 // +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get;list
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 
 // +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=migrationplans,verbs=get;list;watch;create;update;patch;delete
@@ -101,6 +109,13 @@ var v2vimage = "platform9/v2v-helper:v0.1"
 // +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=migrationtemplates/finalizers,verbs=update
 // +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=proxyvms,verbs=get;list;watch;update;patch
 
+// The v2v-helper pods run with this role and create the VM on a KubeVirt destination
+// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=datavolumes,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=upload.cdi.kubevirt.io,resources=uploadtokenrequests,verbs=create
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachines,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=k8s.cni.cncf.io,resources=network-attachment-definitions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+
 // Reconcile reads that state of the cluster for a MigrationPlan object and makes necessary changes
 func (r *MigrationPlanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	r.ctxlog = log.FromContext(ctx)
@@ -659,11 +674,24 @@ func (r *MigrationPlanReconciler) ReconcileMigrationPlanJob(ctx context.Context,
 	if ok, err := r.checkStatusSuccess(ctx, migrationtemplate.Namespace, migrationtemplate.Spec.Source.VMwareRef, true, vmwcreds); !ok {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to check vmwarecreds status '%s'", migrationtemplate.Spec.Source.VMwareRef)
 	}
-	// Fetch OpenStackCreds CR
-	openstackcreds := &vjailbreakv1alpha1.OpenstackCreds{}
-	if ok, err := r.checkStatusSuccess(ctx, migrationtemplate.Namespace, migrationtemplate.Spec.Destination.OpenstackRef,
-		false, openstackcreds); !ok {
-		return ctrl.Result{}, errors.Wrapf(err, "failed to check openstackcreds status '%s'", migrationtemplate.Spec.Destination.OpenstackRef)
+	if err := validateESXiHostSource(migrationplan, vmwcreds); err != nil {
+		return ctrl.Result{}, err
+	}
+	// Fetch OpenStackCreds CR. A KubeVirt destination has none and openstackcreds stays nil.
+	var openstackcreds *vjailbreakv1alpha1.OpenstackCreds
+	if migrationtemplate.Spec.Destination.IsKubevirt() {
+		if err := r.validateKubevirtDestination(ctx, migrationtemplate, validVMs); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else {
+		openstackcreds = &vjailbreakv1alpha1.OpenstackCreds{}
+		if ok, err := r.checkStatusSuccess(ctx, migrationtemplate.Namespace, migrationtemplate.Spec.Destination.OpenstackRef,
+			false, openstackcreds); !ok {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to check openstackcreds status '%s'", migrationtemplate.Spec.Destination.OpenstackRef)
+		}
+	}
+	if err := r.validateExportOptions(ctx, migrationplan, migrationtemplate); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	var arraycreds *vjailbreakv1alpha1.ArrayCreds
@@ -763,6 +791,12 @@ func (r *MigrationPlanReconciler) ReconcileMigrationPlanJob(ctx context.Context,
 		}
 
 		if !allFinished {
+			// Queued migrations may wait for migrations of other plans to end
+			for i := range allMigrations.Items {
+				if allMigrations.Items[i].Status.Phase == vjailbreakv1alpha1.VMMigrationPhaseQueued {
+					return ctrl.Result{RequeueAfter: constants.MigrationQueuedRequeueInterval}, nil
+				}
+			}
 			// Don't requeue - rely on event-driven reconciliation when Migrations reach terminal states
 			return ctrl.Result{}, nil
 		}
@@ -779,6 +813,70 @@ func (r *MigrationPlanReconciler) ReconcileMigrationPlanJob(ctx context.Context,
 	return ctrl.Result{}, nil
 }
 
+// validateKubevirtDestination rejects the options a KubeVirt destination cannot honour
+func (r *MigrationPlanReconciler) validateKubevirtDestination(ctx context.Context,
+	migrationtemplate *vjailbreakv1alpha1.MigrationTemplate,
+	vmMachines []*vjailbreakv1alpha1.VMwareMachine,
+) error {
+	if err := utils.VerifyKubevirtDestination(ctx, r.Client, migrationtemplate.Spec.Destination); err != nil {
+		return err
+	}
+	if migrationtemplate.Spec.StorageCopyMethod == StorageCopyMethod {
+		return errors.Errorf("StorageCopyMethod %s imports array volumes into Cinder and is not supported on a KubeVirt destination", StorageCopyMethod)
+	}
+	for _, vmMachine := range vmMachines {
+		if len(vmMachine.Spec.VMInfo.RDMDisks) > 0 {
+			return errors.Errorf("VM %s has RDM disks, which are not supported on a KubeVirt destination", vmMachine.Spec.VMInfo.Name)
+		}
+	}
+	return nil
+}
+
+// validateESXiHostSource rejects the migration types a standalone ESXi host
+// cannot honour. Without vCenter there are no snapshots to copy a running VM
+// from, so the VM is powered off for the whole copy and only cold migrations
+// do what they say.
+func validateESXiHostSource(migrationplan *vjailbreakv1alpha1.MigrationPlan, vmwcreds *vjailbreakv1alpha1.VMwareCreds) error {
+	if !vmwcreds.IsStandaloneESXi() {
+		return nil
+	}
+	if migrationType := migrationplan.Spec.MigrationStrategy.Type; migrationType != "cold" {
+		return errors.Errorf("VMwareCreds '%s' point at a standalone ESXi host, which only supports migration type 'cold', not '%s'",
+			vmwcreds.Name, migrationType)
+	}
+	return nil
+}
+
+// validateExportOptions checks the export options of a MigrationPlan. Exports read the
+// converted Cinder volumes, so they need an OpenStack destination.
+func (r *MigrationPlanReconciler) validateExportOptions(ctx context.Context,
+	migrationplan *vjailbreakv1alpha1.MigrationPlan,
+	migrationtemplate *vjailbreakv1alpha1.MigrationTemplate,
+) error {
+	export := migrationplan.Spec.MigrationStrategy.Export
+	if export == nil {
+		return nil
+	}
+	if migrationtemplate.Spec.Destination.IsKubevirt() {
+		return errors.New("export is not supported on a KubeVirt destination")
+	}
+	switch export.Target {
+	case vjailbreakv1alpha1.ExportTargetGlance:
+		return nil
+	case vjailbreakv1alpha1.ExportTargetPVC:
+		if export.PVCName == "" {
+			return errors.New("export target pvc requires pvcName")
+		}
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := r.Get(ctx, types.NamespacedName{Name: export.PVCName, Namespace: migrationplan.Namespace}, pvc); err != nil {
+			return errors.Wrapf(err, "failed to get export PVC '%s'", export.PVCName)
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown export target '%s'", export.Target)
+	}
+}
+
 // checkAndHandlePausedPlan checks if migration plan is paused and handles it
 func (r *MigrationPlanReconciler) checkAndHandlePausedPlan(ctx context.Context, migrationplan *vjailbreakv1alpha1.MigrationPlan) (bool, error) {
 	if !utils.IsMigrationPlanPaused(ctx, migrationplan.Name, r.Client) {
@@ -991,6 +1089,8 @@ func (r *MigrationPlanReconciler) CreateMigration(ctx context.Context,
 					"migrationplan":               migrationplan.Name,
 					constants.NumberOfDisksLabel:  strconv.Itoa(len(vminfo.Disks)),
 					constants.MigrationVMKeyLabel: commonutils.SanitizeLabelValue(vm),
+					// v2v-helper shares the NFC connections of the host between the migrations copying from it
+					constants.ESXiHostLabel: commonutils.SanitizeLabelValue(vminfo.ESXiName),
 				},
 				Annotations: map[string]string{
 					constants.OriginalVMNameAnnotation: vm,
@@ -1005,7 +1105,8 @@ func (r *MigrationPlanReconciler) CreateMigration(ctx context.Context,
 				NetworkOverrides:        networkOverrides,
 				MigrationType:           migrationplan.Spec.MigrationStrategy.Type,
 				PreserveSourceTags:      migrationplan.Spec.PreserveSourceTags,
-				DataOnly:                migrationplan.Spec.MigrationStrategy.DataOnly,
+				DataOnly:                migrationplan.Spec.MigrationStrategy.DataOnly || migrationplan.Spec.MigrationStrategy.Export != nil,
+				Export:                  migrationplan.Spec.MigrationStrategy.Export.DeepCopy(),
 			},
 			Status: vjailbreakv1alpha1.MigrationStatus{
 				Phase:      vjailbreakv1alpha1.VMMigrationPhasePending,
@@ -1049,7 +1150,8 @@ func (r *MigrationPlanReconciler) CreateMigration(ctx context.Context,
 			}
 			latest.Spec.NetworkOverrides = networkOverrides
 			latest.Spec.PreserveSourceTags = migrationplan.Spec.PreserveSourceTags
-			latest.Spec.DataOnly = migrationplan.Spec.MigrationStrategy.DataOnly
+			latest.Spec.DataOnly = migrationplan.Spec.MigrationStrategy.DataOnly || migrationplan.Spec.MigrationStrategy.Export != nil
+			latest.Spec.Export = migrationplan.Spec.MigrationStrategy.Export.DeepCopy()
 			if updateErr := r.Update(ctx, latest); updateErr != nil {
 				return updateErr
 			}
@@ -1074,6 +1176,8 @@ func (r *MigrationPlanReconciler) CreateJob(ctx context.Context,
 	openstackSecretRef string,
 	arrayCredsSecretRef string,
 ) error {
+	// Without OpenStack credentials the destination is KubeVirt
+	kubevirtDestination := openstackSecretRef == ""
 	vmwarecreds, err := utils.GetVMwareCredsNameFromMigrationPlan(ctx, r.Client, migrationplan)
 	if err != nil {
 		return errors.Wrap(err, "failed to get vmware credentials")
@@ -1174,13 +1278,15 @@ func (r *MigrationPlanReconciler) CreateJob(ctx context.Context,
 												},
 											},
 										},
-										{
+									}
+									if !kubevirtDestination {
+										envFrom = append(envFrom, corev1.EnvFromSource{
 											SecretRef: &corev1.SecretEnvSource{
 												LocalObjectReference: corev1.LocalObjectReference{
 													Name: openstackSecretRef,
 												},
 											},
-										},
+										})
 									}
 									if arrayCredsSecretRef != "" {
 										envFrom = append(envFrom, corev1.EnvFromSource{
@@ -1302,6 +1408,12 @@ func (r *MigrationPlanReconciler) CreateJob(ctx context.Context,
 				},
 			},
 		}
+		if kubevirtDestination {
+			addKubevirtStagingVolume(&job.Spec.Template.Spec)
+		}
+		if export := migrationobj.Spec.Export; export != nil && export.Target == vjailbreakv1alpha1.ExportTargetPVC {
+			addExportVolume(&job.Spec.Template.Spec, export.PVCName)
+		}
 		if err := r.createResource(ctx, migrationobj, job); err != nil {
 			r.ctxlog.Error(err, fmt.Sprintf("Failed to create Job '%s'", jobName))
 			return errors.Wrap(err, fmt.Sprintf("failed to create job '%s'", jobName))
@@ -1310,6 +1422,47 @@ func (r *MigrationPlanReconciler) CreateJob(ctx context.Context,
 	return nil
 }
 
+// addKubevirtStagingVolume mounts the node directory a KubeVirt destination stages
+// disks in. Disks are converted there before they are uploaded to their DataVolumes,
+// so it must have room for the largest VM and is not ephemeral storage of the pod.
+// Each pod stages in its own subdirectory, checks for free space before it creates
+// a disk and removes the subdirectories of pods that are gone when it starts.
+func addKubevirtStagingVolume(podSpec *corev1.PodSpec) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: "kubevirt-staging",
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: constants.KubevirtStagingHostDir,
+				Type: utils.NewHostPathType("DirectoryOrCreate"),
+			},
+		},
+	})
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      "kubevirt-staging",
+			MountPath: constants.KubevirtStagingDir,
+		})
+	}
+}
+
+// addExportVolume mounts the PVC a pvc export writes its qcow2 files and manifest to
+func addExportVolume(podSpec *corev1.PodSpec, pvcName string) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: constants.ExportVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pvcName,
+			},
+		},
+	})
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      constants.ExportVolumeName,
+			MountPath: constants.ExportMountPath,
+		})
+	}
+}
+
 // CreateFirstbootConfigMap creates a firstboot config map for migration
 func (r *MigrationPlanReconciler) CreateFirstbootConfigMap(ctx context.Context,
 	migrationplan *vjailbreakv1alpha1.MigrationPlan, migrationobj *vjailbreakv1alpha1.Migration, vm string,
@@ -1396,7 +1549,7 @@ func (r *MigrationPlanReconciler) buildNewMigrationConfigMap(ctx context.Context
 		return nil, errors.Wrap(err, "failed to reconcile mapping")
 	}
 
-	openstackports, err := r.processAdvancedOptions(ctx, migrationplan, openstackcreds, &openstacknws, &openstackvolumetypes)
+	openstackports, err := r.processAdvancedOptions(ctx, migrationplan, migrationtemplate, openstackcreds, &openstacknws, &openstackvolumetypes)
 	if err != nil {
 		return nil, err
 	}
@@ -1412,15 +1565,29 @@ func (r *MigrationPlanReconciler) buildNewMigrationConfigMap(ctx context.Context
 		configMapData[constants.HTTPTimeoutSecondsKey] = strconv.Itoa(vjailbreakSettings.HTTPTimeoutSeconds)
 	}
 
-	if utils.IsOpenstackPCD(*openstackcreds) {
+	if openstackcreds != nil && utils.IsOpenstackPCD(*openstackcreds) {
 		configMapData["TARGET_AVAILABILITY_ZONE"] = migrationtemplate.Spec.TargetPCDClusterName
 	}
+	setDestinationEnv(configMapData, migrationtemplate)
 
 	r.setMigrationSpecificFields(configMapData, migrationobj)
-
-	if err := r.determineAndSetTargetFlavor(ctx, configMapData, vmMachine, migrationtemplate, openstackcreds); err != nil {
+	if err := setBandwidthLimitEnv(configMapData, migrationplan.Spec.AdvancedOptions.BandwidthLimit); err != nil {
 		return nil, err
 	}
+	setVerificationEnv(configMapData, migrationplan.Spec.AdvancedOptions.Verification)
+	setParallelDiskCopiesEnv(configMapData, migrationplan.Spec.AdvancedOptions.ParallelDiskCopies)
+	setVDDKTransportEnv(configMapData, migrationtemplate.Spec.VDDKTransport, migrationplan.Spec.AdvancedOptions.VDDKTransport)
+
+	if vmwcreds.IsStandaloneESXi() {
+		configMapData["SOURCE_HOST_TYPE"] = string(vjailbreakv1alpha1.VMwareHostTypeESXi)
+	}
+
+	// A KubeVirt VirtualMachine is sized from the source VM, there are no flavors
+	if openstackcreds != nil {
+		if err := r.determineAndSetTargetFlavor(ctx, configMapData, vmMachine, migrationtemplate, openstackcreds); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := r.setMigrationEnv(configMapData, vmMachine, migrationtemplate, arraycreds, proxyVM); err != nil {
 		return nil, err
@@ -1460,6 +1627,7 @@ func resolveVirtioDriverURL(migrationtemplate *vjailbreakv1alpha1.MigrationTempl
 
 func (r *MigrationPlanReconciler) processAdvancedOptions(ctx context.Context,
 	migrationplan *vjailbreakv1alpha1.MigrationPlan,
+	migrationtemplate *vjailbreakv1alpha1.MigrationTemplate,
 	openstackcreds *vjailbreakv1alpha1.OpenstackCreds,
 	openstacknws *[]string,
 	openstackvolumetypes *[]string,
@@ -1470,21 +1638,38 @@ func (r *MigrationPlanReconciler) processAdvancedOptions(ctx context.Context,
 		return openstackports, nil
 	}
 
+	kubevirt := migrationtemplate.Spec.Destination.IsKubevirt()
+
 	if len(migrationplan.Spec.AdvancedOptions.GranularNetworks) > 0 {
-		if err := utils.VerifyNetworks(ctx, r.Client, openstackcreds, migrationplan.Spec.AdvancedOptions.GranularNetworks); err != nil {
+		var err error
+		if kubevirt {
+			err = utils.VerifyKubevirtNetworks(ctx, r.Client, migrationtemplate.Spec.Destination.Kubevirt.Namespace, migrationplan.Spec.AdvancedOptions.GranularNetworks)
+		} else {
+			err = utils.VerifyNetworks(ctx, r.Client, openstackcreds, migrationplan.Spec.AdvancedOptions.GranularNetworks)
+		}
+		if err != nil {
 			return nil, errors.Wrap(err, "failed to verify networks in advanced mapping")
 		}
 		*openstacknws = migrationplan.Spec.AdvancedOptions.GranularNetworks
 	}
 
 	if len(migrationplan.Spec.AdvancedOptions.GranularVolumeTypes) > 0 {
-		if err := utils.VerifyStorage(ctx, r.Client, openstackcreds, migrationplan.Spec.AdvancedOptions.GranularVolumeTypes); err != nil {
+		var err error
+		if kubevirt {
+			err = utils.VerifyKubevirtStorage(ctx, r.Client, migrationplan.Spec.AdvancedOptions.GranularVolumeTypes)
+		} else {
+			err = utils.VerifyStorage(ctx, r.Client, openstackcreds, migrationplan.Spec.AdvancedOptions.GranularVolumeTypes)
+		}
+		if err != nil {
 			return nil, errors.Wrap(err, "failed to verify volume types in advanced mapping")
 		}
 		*openstackvolumetypes = migrationplan.Spec.AdvancedOptions.GranularVolumeTypes
 	}
 
 	if len(migrationplan.Spec.AdvancedOptions.GranularPorts) > 0 {
+		if kubevirt {
+			return nil, errors.New("pre-created ports are not supported on a KubeVirt destination")
+		}
 		if err := utils.VerifyPorts(ctx, r.Client, openstackcreds, migrationplan.Spec.AdvancedOptions.GranularPorts); err != nil {
 			return nil, errors.Wrap(err, "failed to verify ports in advanced mapping")
 		}
@@ -1570,11 +1755,28 @@ func (r *MigrationPlanReconciler) buildBaseConfigMapData(
 	}
 }
 
+// setDestinationEnv tells v2v-helper which destination to create the VM on
+func setDestinationEnv(configMapData map[string]string, migrationtemplate *vjailbreakv1alpha1.MigrationTemplate) {
+	if !migrationtemplate.Spec.Destination.IsKubevirt() {
+		configMapData[constants.DestinationTypeKey] = constants.DestinationTypeOpenstack
+		return
+	}
+	configMapData[constants.DestinationTypeKey] = constants.DestinationTypeKubevirt
+	configMapData[constants.KubevirtNamespaceKey] = migrationtemplate.Spec.Destination.Kubevirt.Namespace
+	configMapData[constants.KubevirtUploadProxyURLKey] = migrationtemplate.Spec.Destination.Kubevirt.UploadProxyURL
+}
+
 func (r *MigrationPlanReconciler) setMigrationSpecificFields(configMapData map[string]string, migrationobj *vjailbreakv1alpha1.Migration) {
 	if migrationobj.Spec.NetworkOverrides != "" {
 		configMapData["NETWORK_OVERRIDES"] = migrationobj.Spec.NetworkOverrides
 	}
 	configMapData["DATA_ONLY"] = strconv.FormatBool(migrationobj.Spec.DataOnly)
+	if export := migrationobj.Spec.Export; export != nil {
+		configMapData[constants.ExportTargetKey] = string(export.Target)
+		configMapData[constants.ExportCompressKey] = strconv.FormatBool(export.CompressEnabled())
+		configMapData[constants.ExportImageVisibilityKey] = export.ImageVisibility
+		configMapData[constants.ExportKeepVolumesKey] = strconv.FormatBool(export.KeepVolumes)
+	}
 }
 
 func (r *MigrationPlanReconciler) determineAndSetTargetFlavor(ctx context.Context,
@@ -1646,10 +1848,23 @@ func (r *MigrationPlanReconciler) setMigrationEnv(
 		configMapData["STORAGE_COPY_METHOD"] = StorageCopyMethod
 		configMapData["VENDOR_TYPE"] = arraycreds.Spec.VendorType
 		configMapData["ARRAY_CREDS_MAPPING"] = migrationtemplate.Spec.ArrayCredsMapping
+		configMapData["ARRAY_NATIVE_COPY"] = strconv.FormatBool(arraycreds.Spec.ArrayNativeCopy)
 		if arraycreds.Spec.VendorType == netappsdk.VendorName && arraycreds.Spec.NetAppConfig != nil {
 			configMapData["NETAPP_SVM"] = arraycreds.Spec.NetAppConfig.SVM
 			configMapData["NETAPP_FLEXVOL"] = arraycreds.Spec.NetAppConfig.FlexVol
 		}
+		if arraycreds.Spec.VendorType == powerstoresdk.VendorName && arraycreds.Spec.PowerStoreConfig != nil {
+			configMapData["POWERSTORE_APPLIANCE"] = arraycreds.Spec.PowerStoreConfig.Appliance
+		}
+		if arraycreds.Spec.VendorType == hpesdk.VendorName && arraycreds.Spec.HPEAlletraConfig != nil {
+			configMapData["HPE_CPG"] = arraycreds.Spec.HPEAlletraConfig.CPG
+		}
+		if arraycreds.Spec.VendorType == simulatorsdk.VendorName && arraycreds.Spec.SimulatorConfig != nil {
+			configMapData["SIMULATOR_STATE_FILE"] = arraycreds.Spec.SimulatorConfig.StateFile
+			configMapData["SIMULATOR_CREATE_DELAY"] = arraycreds.Spec.SimulatorConfig.CreateDelay
+			configMapData["SIMULATOR_FAIL_MAPPING"] = strconv.FormatBool(arraycreds.Spec.SimulatorConfig.FailMapping)
+			configMapData["SIMULATOR_NAA_COLLISION"] = strconv.FormatBool(arraycreds.Spec.SimulatorConfig.NAACollision)
+		}
 	} else if migrationtemplate.Spec.StorageCopyMethod == constants.HotAddCopyMethod && proxyVM != nil {
 		configMapData["STORAGE_COPY_METHOD"] = constants.HotAddCopyMethod
 		configMapData["PROXY_VM_IP"] = proxyVM.Status.IPAddress
@@ -1809,6 +2024,87 @@ func setTagsAndCustomMetadata(configMapData map[string]string,
 	return nil
 }
 
+// setBandwidthLimitEnv writes the plan's bandwidth cap and its daily windows into the
+// migration ConfigMap, the global cap is read by v2v-helper from the vjailbreak settings.
+func setBandwidthLimitEnv(configMapData map[string]string, limit *vjailbreakv1alpha1.BandwidthLimit) error {
+	delete(configMapData, constants.BandwidthLimitMbpsKey)
+	delete(configMapData, constants.BandwidthScheduleKey)
+	if limit == nil {
+		return nil
+	}
+	windows := make([]bandwidth.Window, 0, len(limit.Schedule))
+	for _, entry := range limit.Schedule {
+		window, err := bandwidth.NewWindow(entry.Start, entry.End, entry.RateMbps)
+		if err != nil {
+			return errors.Wrap(err, "invalid bandwidth schedule")
+		}
+		windows = append(windows, window)
+	}
+	configMapData[constants.BandwidthLimitMbpsKey] = strconv.Itoa(limit.RateMbps)
+	if len(windows) > 0 {
+		configMapData[constants.BandwidthScheduleKey] = bandwidth.FormatWindows(windows)
+	}
+	return nil
+}
+
+// setVerificationEnv writes the plan's integrity verification mode into the migration
+// ConfigMap, a Full check compares every block so it has no sample percentage.
+func setVerificationEnv(configMapData map[string]string, verification *vjailbreakv1alpha1.IntegrityVerification) {
+	delete(configMapData, constants.VerifyModeKey)
+	delete(configMapData, constants.VerifySamplePercentKey)
+	if verification == nil || verification.Mode == "" {
+		return
+	}
+	configMapData[constants.VerifyModeKey] = string(verification.Mode)
+	if verification.Mode == vjailbreakv1alpha1.VerificationModeSampled {
+		samplePercent := verification.SamplePercent
+		if samplePercent <= 0 {
+			samplePercent = 1
+		}
+		configMapData[constants.VerifySamplePercentKey] = strconv.Itoa(samplePercent)
+	}
+}
+
+// setParallelDiskCopiesEnv writes how many disks of a VM the plan lets v2v-helper copy
+// at once into the migration ConfigMap, v2v-helper picks its default when unset.
+func setParallelDiskCopiesEnv(configMapData map[string]string, parallelDiskCopies int) {
+	delete(configMapData, constants.ParallelDiskCopiesKey)
+	if parallelDiskCopies > 0 {
+		configMapData[constants.ParallelDiskCopiesKey] = strconv.Itoa(parallelDiskCopies)
+	}
+}
+
+// setVDDKTransportEnv writes the VDDK transport modes and compression of the NBD copy into
+// the migration ConfigMap. Those of the plan override those of the template, field by
+// field, and v2v-helper uses its defaults for what neither sets.
+func setVDDKTransportEnv(configMapData map[string]string, template, plan *vjailbreakv1alpha1.VDDKTransport) {
+	delete(configMapData, constants.VDDKTransportsKey)
+	delete(configMapData, constants.VDDKCompressionKey)
+	var modes []vjailbreakv1alpha1.VDDKTransportMode
+	var compression vjailbreakv1alpha1.VDDKCompression
+	for _, transport := range []*vjailbreakv1alpha1.VDDKTransport{template, plan} {
+		if transport == nil {
+			continue
+		}
+		if len(transport.Modes) > 0 {
+			modes = transport.Modes
+		}
+		if transport.Compression != "" {
+			compression = transport.Compression
+		}
+	}
+	if len(modes) > 0 {
+		names := make([]string, 0, len(modes))
+		for _, mode := range modes {
+			names = append(names, string(mode))
+		}
+		configMapData[constants.VDDKTransportsKey] = strings.Join(names, ":")
+	}
+	if compression != "" {
+		configMapData[constants.VDDKCompressionKey] = string(compression)
+	}
+}
+
 // updateMigrationConfigMap updates the mutable fields of an existing migration ConfigMap.
 func (r *MigrationPlanReconciler) updateMigrationConfigMap(ctx context.Context, configMap *corev1.ConfigMap,
 	migrationplan *vjailbreakv1alpha1.MigrationPlan, migrationobj *vjailbreakv1alpha1.Migration,
@@ -1831,6 +2127,11 @@ func (r *MigrationPlanReconciler) updateMigrationConfigMap(ctx context.Context,
 	if err := setTagsAndCustomMetadata(configMap.Data, migrationplan, migrationobj, vmMachine); err != nil {
 		return err
 	}
+	if err := setBandwidthLimitEnv(configMap.Data, migrationplan.Spec.AdvancedOptions.BandwidthLimit); err != nil {
+		return err
+	}
+	setVerificationEnv(configMap.Data, migrationplan.Spec.AdvancedOptions.Verification)
+	setParallelDiskCopiesEnv(configMap.Data, migrationplan.Spec.AdvancedOptions.ParallelDiskCopies)
 	if err := r.Update(ctx, configMap); err != nil {
 		r.ctxlog.Error(err, fmt.Sprintf("Failed to update ConfigMap '%s'", configMapName))
 		return errors.Wrapf(err, "failed to update config map '%s'", configMapName)
@@ -1991,7 +2292,11 @@ func (r *MigrationPlanReconciler) reconcileNetwork(ctx context.Context,
 	}
 
 	if networkmap.Status.NetworkmappingValidationStatus != string(corev1.PodSucceeded) {
-		err = utils.VerifyNetworks(ctx, r.Client, openstackcreds, uniqueTargetList)
+		if migrationtemplate.Spec.Destination.IsKubevirt() {
+			err = utils.VerifyKubevirtNetworks(ctx, r.Client, migrationtemplate.Spec.Destination.Kubevirt.Namespace, uniqueTargetList)
+		} else {
+			err = utils.VerifyNetworks(ctx, r.Client, openstackcreds, uniqueTargetList)
+		}
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to verify networks")
 		}
@@ -2037,7 +2342,11 @@ func (r *MigrationPlanReconciler) reconcileStorage(ctx context.Context,
 		}
 	}
 	if storagemap.Status.StoragemappingValidationStatus != string(corev1.PodSucceeded) {
-		err = utils.VerifyStorage(ctx, r.Client, openstackcreds, openstackvolumetypes)
+		if migrationtemplate.Spec.Destination.IsKubevirt() {
+			err = utils.VerifyKubevirtStorage(ctx, r.Client, openstackvolumetypes)
+		} else {
+			err = utils.VerifyStorage(ctx, r.Client, openstackcreds, openstackvolumetypes)
+		}
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to verify datastores")
 		}
@@ -2104,7 +2413,15 @@ func (r *MigrationPlanReconciler) TriggerMigration(ctx context.Context,
 			continue
 		}
 
+		// The job is not created while the ESXi host, a datastore or the agents are busy
+		admitted, err := r.scheduleMigration(ctx, migrationobj, vmMachineObj, len(nodeList.Items))
+		if err != nil {
+			return errors.Wrapf(err, "failed to schedule Migration for VM %s", vm)
+		}
 		migrationobjs.Items = append(migrationobjs.Items, *migrationobj)
+		if !admitted {
+			continue
+		}
 
 		_, err = r.CreateMigrationConfigMap(ctx, migrationplan, migrationtemplate, migrationobj, openstackcreds, vmwcreds, vm, vmMachineObj, arraycreds, proxyVM)
 		if err != nil {
@@ -2123,6 +2440,10 @@ func (r *MigrationPlanReconciler) TriggerMigration(ctx context.Context,
 		if arraycreds != nil {
 			arraycredsSecretRef = arraycreds.Spec.SecretRef.Name
 		}
+		openstackSecretRef := ""
+		if openstackcreds != nil {
+			openstackSecretRef = openstackcreds.Spec.SecretRef.Name
+		}
 
 		err = r.CreateJob(ctx,
 			migrationplan,
@@ -2130,7 +2451,7 @@ func (r *MigrationPlanReconciler) TriggerMigration(ctx context.Context,
 			vm,
 			fbcm.Name,
 			vmwcreds.Spec.SecretRef.Name,
-			openstackcreds.Spec.SecretRef.Name,
+			openstackSecretRef,
 			arraycredsSecretRef)
 		if err != nil {
 			return errors.Wrap(err, fmt.Sprintf("failed to create Job for VM %s", vm))
@@ -36,6 +36,7 @@ import (
 
 	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
 	"github.com/platform9/vjailbreak/k8s/migration/pkg/scope"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
 )
 
 var _ = ginkgo.Describe("MigrationPlan Controller", func() {
@@ -271,6 +272,7 @@ func TestDataOnlyPropagation(t *testing.T) {
 	tests := []struct {
 		name         string
 		dataOnly     bool
+		export       *vjailbreakv1alpha1.ExportOptions
 		wantDataOnly bool
 	}{
 		{
@@ -283,6 +285,12 @@ func TestDataOnlyPropagation(t *testing.T) {
 			dataOnly:     false,
 			wantDataOnly: false,
 		},
+		{
+			name:         "Export implies DataOnly",
+			dataOnly:     false,
+			export:       &vjailbreakv1alpha1.ExportOptions{Target: vjailbreakv1alpha1.ExportTargetGlance},
+			wantDataOnly: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -322,6 +330,7 @@ func TestDataOnlyPropagation(t *testing.T) {
 						MigrationStrategy: vjailbreakv1alpha1.MigrationPlanStrategy{
 							Type:     "cold",
 							DataOnly: tt.dataOnly,
+							Export:   tt.export,
 						},
 					},
 					VirtualMachines: [][]string{{"vm-a"}},
@@ -357,6 +366,9 @@ func TestDataOnlyPropagation(t *testing.T) {
 			if got != tt.wantDataOnly {
 				t.Errorf("Migration.Spec.DataOnly = %v, want %v", got, tt.wantDataOnly)
 			}
+			if gotExport := migrationList.Items[0].Spec.Export; (gotExport != nil) != (tt.export != nil) {
+				t.Errorf("Migration.Spec.Export = %+v, want %+v", gotExport, tt.export)
+			}
 		})
 	}
 }
@@ -542,6 +554,212 @@ func TestSetMigrationSpecificFields_DataOnly(t *testing.T) {
 	}
 }
 
+// TestSetMigrationSpecificFields_Export verifies the export keys are only written for exports
+func TestSetMigrationSpecificFields_Export(t *testing.T) {
+	r := &MigrationPlanReconciler{}
+	noCompress := false
+
+	tests := []struct {
+		name         string
+		export       *vjailbreakv1alpha1.ExportOptions
+		wantTarget   string
+		wantCompress string
+	}{
+		{name: "no export"},
+		{
+			name:         "pvc export compresses by default",
+			export:       &vjailbreakv1alpha1.ExportOptions{Target: vjailbreakv1alpha1.ExportTargetPVC, PVCName: "images"},
+			wantTarget:   constants.ExportTargetPVC,
+			wantCompress: "true",
+		},
+		{
+			name:         "glance export without compression",
+			export:       &vjailbreakv1alpha1.ExportOptions{Target: vjailbreakv1alpha1.ExportTargetGlance, Compress: &noCompress},
+			wantTarget:   constants.ExportTargetGlance,
+			wantCompress: "false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMapData := map[string]string{}
+			migration := &vjailbreakv1alpha1.Migration{
+				Spec: vjailbreakv1alpha1.MigrationSpec{DataOnly: tt.export != nil, Export: tt.export},
+			}
+			r.setMigrationSpecificFields(configMapData, migration)
+			if got := configMapData[constants.ExportTargetKey]; got != tt.wantTarget {
+				t.Errorf("%s = %q, want %q", constants.ExportTargetKey, got, tt.wantTarget)
+			}
+			if got := configMapData[constants.ExportCompressKey]; got != tt.wantCompress {
+				t.Errorf("%s = %q, want %q", constants.ExportCompressKey, got, tt.wantCompress)
+			}
+		})
+	}
+}
+
+// TestValidateExportOptions verifies the export target checks of a MigrationPlan
+func TestValidateExportOptions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = vjailbreakv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "images", Namespace: "migration-system"}}
+	r := &MigrationPlanReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(pvc).Build()}
+
+	openstackTemplate := &vjailbreakv1alpha1.MigrationTemplate{}
+	kubevirtTemplate := &vjailbreakv1alpha1.MigrationTemplate{Spec: vjailbreakv1alpha1.MigrationTemplateSpec{
+		Destination: vjailbreakv1alpha1.MigrationTemplateDestination{Type: vjailbreakv1alpha1.DestinationTypeKubevirt},
+	}}
+	plan := func(export *vjailbreakv1alpha1.ExportOptions) *vjailbreakv1alpha1.MigrationPlan {
+		p := &vjailbreakv1alpha1.MigrationPlan{ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: "migration-system"}}
+		p.Spec.MigrationStrategy.Export = export
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		export   *vjailbreakv1alpha1.ExportOptions
+		template *vjailbreakv1alpha1.MigrationTemplate
+		wantErr  bool
+	}{
+		{name: "no export", template: kubevirtTemplate},
+		{name: "glance", export: &vjailbreakv1alpha1.ExportOptions{Target: vjailbreakv1alpha1.ExportTargetGlance}, template: openstackTemplate},
+		{name: "pvc", export: &vjailbreakv1alpha1.ExportOptions{Target: vjailbreakv1alpha1.ExportTargetPVC, PVCName: "images"}, template: openstackTemplate},
+		{name: "pvc without name", export: &vjailbreakv1alpha1.ExportOptions{Target: vjailbreakv1alpha1.ExportTargetPVC}, template: openstackTemplate, wantErr: true},
+		{name: "missing pvc", export: &vjailbreakv1alpha1.ExportOptions{Target: vjailbreakv1alpha1.ExportTargetPVC, PVCName: "absent"}, template: openstackTemplate, wantErr: true},
+		{name: "kubevirt destination", export: &vjailbreakv1alpha1.ExportOptions{Target: vjailbreakv1alpha1.ExportTargetGlance}, template: kubevirtTemplate, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.validateExportOptions(context.Background(), plan(tt.export), tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateExportOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestAddExportVolume verifies the export PVC is mounted into every container
+func TestAddExportVolume(t *testing.T) {
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "fedora"}}}
+	addExportVolume(podSpec, "images")
+
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].PersistentVolumeClaim == nil || podSpec.Volumes[0].PersistentVolumeClaim.ClaimName != "images" {
+		t.Fatalf("unexpected volumes %+v", podSpec.Volumes)
+	}
+	mounts := podSpec.Containers[0].VolumeMounts
+	if len(mounts) != 1 || mounts[0].MountPath != constants.ExportMountPath {
+		t.Errorf("unexpected mounts %+v", mounts)
+	}
+}
+
+// TestSetDestinationEnv verifies the destination keys written for OpenStack and KubeVirt templates
+func TestSetDestinationEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination vjailbreakv1alpha1.MigrationTemplateDestination
+		want        map[string]string
+	}{
+		{
+			name:        "openstack is the default",
+			destination: vjailbreakv1alpha1.MigrationTemplateDestination{OpenstackRef: "pcd"},
+			want:        map[string]string{constants.DestinationTypeKey: constants.DestinationTypeOpenstack},
+		},
+		{
+			name: "kubevirt sets namespace and upload proxy",
+			destination: vjailbreakv1alpha1.MigrationTemplateDestination{
+				Type:     vjailbreakv1alpha1.DestinationTypeKubevirt,
+				Kubevirt: &vjailbreakv1alpha1.KubevirtDestination{Namespace: "vms", UploadProxyURL: "https://upload.example.com"},
+			},
+			want: map[string]string{
+				constants.DestinationTypeKey:        constants.DestinationTypeKubevirt,
+				constants.KubevirtNamespaceKey:      "vms",
+				constants.KubevirtUploadProxyURLKey: "https://upload.example.com",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configMapData := map[string]string{}
+			setDestinationEnv(configMapData, &vjailbreakv1alpha1.MigrationTemplate{
+				Spec: vjailbreakv1alpha1.MigrationTemplateSpec{Destination: tt.destination},
+			})
+			if !reflect.DeepEqual(configMapData, tt.want) {
+				t.Errorf("configmap data = %v, want %v", configMapData, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidateKubevirtDestination verifies the options a KubeVirt destination rejects
+func TestValidateKubevirtDestination(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = vjailbreakv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "vms"}}
+	r := &MigrationPlanReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).Build()}
+
+	kubevirtTemplate := func(namespace, copyMethod string) *vjailbreakv1alpha1.MigrationTemplate {
+		return &vjailbreakv1alpha1.MigrationTemplate{
+			Spec: vjailbreakv1alpha1.MigrationTemplateSpec{
+				StorageCopyMethod: copyMethod,
+				Destination: vjailbreakv1alpha1.MigrationTemplateDestination{
+					Type:     vjailbreakv1alpha1.DestinationTypeKubevirt,
+					Kubevirt: &vjailbreakv1alpha1.KubevirtDestination{Namespace: namespace},
+				},
+			},
+		}
+	}
+	rdmVM := &vjailbreakv1alpha1.VMwareMachine{}
+	rdmVM.Spec.VMInfo.Name = "db01"
+	rdmVM.Spec.VMInfo.RDMDisks = []string{"rdm-1"}
+	plainVM := &vjailbreakv1alpha1.VMwareMachine{}
+	plainVM.Spec.VMInfo.Name = "web01"
+
+	tests := []struct {
+		name     string
+		template *vjailbreakv1alpha1.MigrationTemplate
+		vms      []*vjailbreakv1alpha1.VMwareMachine
+		wantErr  bool
+	}{
+		{name: "valid", template: kubevirtTemplate("vms", ""), vms: []*vjailbreakv1alpha1.VMwareMachine{plainVM}},
+		{name: "missing namespace", template: kubevirtTemplate("absent", ""), vms: []*vjailbreakv1alpha1.VMwareMachine{plainVM}, wantErr: true},
+		{name: "storage accelerated copy", template: kubevirtTemplate("vms", StorageCopyMethod), vms: []*vjailbreakv1alpha1.VMwareMachine{plainVM}, wantErr: true},
+		{name: "RDM disks", template: kubevirtTemplate("vms", ""), vms: []*vjailbreakv1alpha1.VMwareMachine{plainVM, rdmVM}, wantErr: true},
+		{
+			name: "kubevirt section missing",
+			template: &vjailbreakv1alpha1.MigrationTemplate{Spec: vjailbreakv1alpha1.MigrationTemplateSpec{
+				Destination: vjailbreakv1alpha1.MigrationTemplateDestination{Type: vjailbreakv1alpha1.DestinationTypeKubevirt},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.validateKubevirtDestination(context.Background(), tt.template, tt.vms)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateKubevirtDestination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestAddKubevirtStagingVolume verifies the staging directory is mounted into every container
+func TestAddKubevirtStagingVolume(t *testing.T) {
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "fedora"}}}
+	addKubevirtStagingVolume(podSpec)
+
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].HostPath == nil || podSpec.Volumes[0].HostPath.Path != constants.KubevirtStagingHostDir {
+		t.Fatalf("unexpected volumes %+v", podSpec.Volumes)
+	}
+	mounts := podSpec.Containers[0].VolumeMounts
+	if len(mounts) != 1 || mounts[0].MountPath != constants.KubevirtStagingDir || mounts[0].Name != podSpec.Volumes[0].Name {
+		t.Errorf("unexpected volume mounts %+v", mounts)
+	}
+}
+
 func TestIsVMSucceededInPlan(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = vjailbreakv1alpha1.AddToScheme(scheme)
@@ -645,3 +863,180 @@ func TestIsVMSucceededInPlan(t *testing.T) {
 		})
 	}
 }
+
+// TestSetBandwidthLimitEnv verifies the bandwidth limit keys written for a
+// flat cap and for a schedule, and that an invalid schedule is rejected
+func TestSetBandwidthLimitEnv(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit        *vjailbreakv1alpha1.BandwidthLimit
+		wantRate     string
+		wantSchedule string
+		wantErr      bool
+	}{
+		{name: "no limit"},
+		{
+			name:     "flat cap",
+			limit:    &vjailbreakv1alpha1.BandwidthLimit{RateMbps: 500},
+			wantRate: "500",
+		},
+		{
+			name: "full speed at night, 200 Mbps during the day",
+			limit: &vjailbreakv1alpha1.BandwidthLimit{Schedule: []vjailbreakv1alpha1.BandwidthWindow{
+				{Start: "08:00", End: "18:00", RateMbps: 200},
+			}},
+			wantRate:     "0",
+			wantSchedule: "08:00-18:00=200",
+		},
+		{
+			name: "empty window",
+			limit: &vjailbreakv1alpha1.BandwidthLimit{Schedule: []vjailbreakv1alpha1.BandwidthWindow{
+				{Start: "08:00", End: "08:00", RateMbps: 200},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// stale keys from a previous ConfigMap revision must not survive
+			configMapData := map[string]string{
+				constants.BandwidthLimitMbpsKey: "1",
+				constants.BandwidthScheduleKey:  "00:00-01:00=1",
+			}
+			err := setBandwidthLimitEnv(configMapData, tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setBandwidthLimitEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := configMapData[constants.BandwidthLimitMbpsKey]; got != tt.wantRate {
+				t.Errorf("%s = %q, want %q", constants.BandwidthLimitMbpsKey, got, tt.wantRate)
+			}
+			if got := configMapData[constants.BandwidthScheduleKey]; got != tt.wantSchedule {
+				t.Errorf("%s = %q, want %q", constants.BandwidthScheduleKey, got, tt.wantSchedule)
+			}
+		})
+	}
+}
+
+// TestSetVerificationEnv verifies the verification keys written for each
+// mode, and that the sample percentage defaults to 1 for a sampled check
+func TestSetVerificationEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        vjailbreakv1alpha1.VerificationMode
+		percent     int
+		wantMode    string
+		wantPercent string
+	}{
+		{name: "off"},
+		{name: "full", mode: vjailbreakv1alpha1.VerificationModeFull, percent: 5, wantMode: "Full"},
+		{name: "sampled", mode: vjailbreakv1alpha1.VerificationModeSampled, percent: 5, wantMode: "Sampled", wantPercent: "5"},
+		{name: "sampled without a percentage", mode: vjailbreakv1alpha1.VerificationModeSampled, wantMode: "Sampled", wantPercent: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a plan switched from a sampled check must not keep its keys
+			configMapData := map[string]string{
+				constants.VerifyModeKey:          "Sampled",
+				constants.VerifySamplePercentKey: "50",
+			}
+			var verification *vjailbreakv1alpha1.IntegrityVerification
+			if tt.mode != "" {
+				verification = &vjailbreakv1alpha1.IntegrityVerification{Mode: tt.mode, SamplePercent: tt.percent}
+			}
+			setVerificationEnv(configMapData, verification)
+			if got := configMapData[constants.VerifyModeKey]; got != tt.wantMode {
+				t.Errorf("%s = %q, want %q", constants.VerifyModeKey, got, tt.wantMode)
+			}
+			if got := configMapData[constants.VerifySamplePercentKey]; got != tt.wantPercent {
+				t.Errorf("%s = %q, want %q", constants.VerifySamplePercentKey, got, tt.wantPercent)
+			}
+		})
+	}
+}
+
+// TestSetVDDKTransportEnv verifies the VDDK transport keys written from the
+// template and the plan, the plan overriding the template field by field
+func TestSetVDDKTransportEnv(t *testing.T) {
+	san := []vjailbreakv1alpha1.VDDKTransportMode{vjailbreakv1alpha1.VDDKTransportModeSAN, vjailbreakv1alpha1.VDDKTransportModeNBDSSL}
+	nbd := []vjailbreakv1alpha1.VDDKTransportMode{vjailbreakv1alpha1.VDDKTransportModeNBD}
+
+	tests := []struct {
+		name            string
+		template, plan  *vjailbreakv1alpha1.VDDKTransport
+		wantTransports  string
+		wantCompression string
+	}{
+		{name: "defaults"},
+		{
+			name:            "template",
+			template:        &vjailbreakv1alpha1.VDDKTransport{Modes: san, Compression: vjailbreakv1alpha1.VDDKCompressionZlib},
+			wantTransports:  "san:nbdssl",
+			wantCompression: "zlib",
+		},
+		{
+			name:            "plan overrides the modes only",
+			template:        &vjailbreakv1alpha1.VDDKTransport{Modes: san, Compression: vjailbreakv1alpha1.VDDKCompressionZlib},
+			plan:            &vjailbreakv1alpha1.VDDKTransport{Modes: nbd},
+			wantTransports:  "nbd",
+			wantCompression: "zlib",
+		},
+		{
+			name:            "plan only",
+			plan:            &vjailbreakv1alpha1.VDDKTransport{Compression: vjailbreakv1alpha1.VDDKCompressionNone},
+			wantCompression: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the defaults leave both keys empty, whatever an earlier template set
+			configMapData := map[string]string{
+				constants.VDDKTransportsKey:  "hotadd",
+				constants.VDDKCompressionKey: "skipz",
+			}
+			setVDDKTransportEnv(configMapData, tt.template, tt.plan)
+			if got := configMapData[constants.VDDKTransportsKey]; got != tt.wantTransports {
+				t.Errorf("%s = %q, want %q", constants.VDDKTransportsKey, got, tt.wantTransports)
+			}
+			if got := configMapData[constants.VDDKCompressionKey]; got != tt.wantCompression {
+				t.Errorf("%s = %q, want %q", constants.VDDKCompressionKey, got, tt.wantCompression)
+			}
+		})
+	}
+}
+
+// TestValidateESXiHostSource verifies that only cold migrations are accepted
+// from a standalone ESXi host
+func TestValidateESXiHostSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		hostType      vjailbreakv1alpha1.VMwareHostType
+		migrationType string
+		wantErr       bool
+	}{
+		{name: "vcenter hot", hostType: vjailbreakv1alpha1.VMwareHostTypeVCenter, migrationType: "hot"},
+		{name: "esxi cold", hostType: vjailbreakv1alpha1.VMwareHostTypeESXi, migrationType: "cold"},
+		{name: "esxi hot", hostType: vjailbreakv1alpha1.VMwareHostTypeESXi, migrationType: "hot", wantErr: true},
+		{name: "esxi mock", hostType: vjailbreakv1alpha1.VMwareHostTypeESXi, migrationType: "mock", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrationplan := &vjailbreakv1alpha1.MigrationPlan{}
+			migrationplan.Spec.MigrationStrategy.Type = tt.migrationType
+			vmwcreds := &vjailbreakv1alpha1.VMwareCreds{
+				ObjectMeta: metav1.ObjectMeta{Name: "esxi-01"},
+				Spec:       vjailbreakv1alpha1.VMwareCredsSpec{HostType: tt.hostType},
+			}
+			err := validateESXiHostSource(migrationplan, vmwcreds)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateESXiHostSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
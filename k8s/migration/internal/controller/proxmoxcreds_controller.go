@@ -0,0 +1,243 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/pkg/common/proxmox"
+	commonutils "github.com/platform9/vjailbreak/pkg/common/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Keys of the ProxmoxCreds secret
+const (
+	proxmoxTokenIDKey       = "tokenId"
+	proxmoxTokenSecretKey   = "tokenSecret" //nolint:gosec // not a password string
+	proxmoxSSHPrivateKeyKey = "privateKey"
+)
+
+// ProxmoxCredsReconciler reconciles a ProxmoxCreds object
+type ProxmoxCredsReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=proxmoxcreds,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=proxmoxcreds/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=proxmoxcreds/finalizers,verbs=update
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=proxmoxmachines,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile validates the API token and records the VMs of the cluster as ProxmoxMachines
+func (r *ProxmoxCredsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctxlog := log.FromContext(ctx).WithName(constants.ProxmoxCredsControllerName)
+
+	proxmoxCreds := &vjailbreakv1alpha1.ProxmoxCreds{}
+	if err := r.Get(ctx, req.NamespacedName, proxmoxCreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !proxmoxCreds.DeletionTimestamp.IsZero() {
+		// The ProxmoxMachines are owned by the creds and garbage collected with them
+		return ctrl.Result{}, nil
+	}
+
+	ctxlog.Info("Validating ProxmoxCreds", "name", proxmoxCreds.Name, "apiUrl", proxmoxCreds.Spec.APIURL)
+	proxmoxCreds.Status.ValidationStatus = constants.ProxmoxCredsStatusValidating
+	proxmoxCreds.Status.ValidationMessage = "Validating connection to the Proxmox VE API..."
+	if err := r.Status().Update(ctx, proxmoxCreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	version, found, skipped, err := r.validateAndDiscover(ctx, proxmoxCreds)
+	proxmoxCreds.Status.LastValidationTime = metav1.Now()
+	if err != nil {
+		ctxlog.Info("ProxmoxCreds validation failed", "name", proxmoxCreds.Name, "error", err.Error())
+		proxmoxCreds.Status.ValidationStatus = constants.ProxmoxCredsStatusFailed
+		proxmoxCreds.Status.ValidationMessage = err.Error()
+	} else {
+		proxmoxCreds.Status.ValidationStatus = constants.ProxmoxCredsStatusSucceeded
+		proxmoxCreds.Status.Version = version
+		proxmoxCreds.Status.ValidationMessage = fmt.Sprintf("Connected to Proxmox VE %s, found %d VM(s)", version, found)
+		if skipped > 0 {
+			proxmoxCreds.Status.ValidationMessage += fmt.Sprintf(", skipped %d that cannot be migrated, see the controller logs", skipped)
+		}
+	}
+	if err := r.Status().Update(ctx, proxmoxCreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Requeue periodically to pick up new and removed VMs
+	return ctrl.Result{RequeueAfter: 15 * time.Minute}, nil
+}
+
+// validateAndDiscover checks the secret and the API token, then syncs the
+// ProxmoxMachines with the VMs of the cluster. It returns the Proxmox VE version
+// and how many VMs were recorded and skipped.
+func (r *ProxmoxCredsReconciler) validateAndDiscover(ctx context.Context, proxmoxCreds *vjailbreakv1alpha1.ProxmoxCreds) (string, int, int, error) {
+	secret, err := r.getSecret(ctx, proxmoxCreds)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	pveClient, err := proxmox.NewClient(proxmoxCreds.Spec.APIURL,
+		string(secret.Data[proxmoxTokenIDKey]), string(secret.Data[proxmoxTokenSecretKey]), proxmoxCreds.Spec.Insecure)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	version, err := pveClient.Version(ctx)
+	if err != nil {
+		return "", 0, 0, errors.Wrap(err, "failed to connect to the Proxmox VE API")
+	}
+	found, skipped, err := r.syncProxmoxMachines(ctx, proxmoxCreds, pveClient)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return version, found, skipped, nil
+}
+
+// getSecret reads and checks the referenced secret
+func (r *ProxmoxCredsReconciler) getSecret(ctx context.Context, proxmoxCreds *vjailbreakv1alpha1.ProxmoxCreds) (*corev1.Secret, error) {
+	// Migration pods read the secret through env vars, so it must share the namespace
+	secretName, secretNamespace := proxmoxCreds.Spec.SecretRef.Name, proxmoxCreds.Namespace
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Name: secretName, Namespace: secretNamespace}, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to get secret %s/%s", secretNamespace, secretName)
+	}
+	for _, key := range []string{proxmoxTokenIDKey, proxmoxTokenSecretKey, proxmoxSSHPrivateKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			return nil, errors.Errorf("secret %s/%s does not contain '%s' key", secretNamespace, secretName, key)
+		}
+	}
+	if _, err := ssh.ParsePrivateKey(secret.Data[proxmoxSSHPrivateKeyKey]); err != nil {
+		return nil, errors.Errorf("invalid SSH private key in secret %s/%s: %v", secretNamespace, secretName, err)
+	}
+	return secret, nil
+}
+
+// syncProxmoxMachines creates or updates a ProxmoxMachine per VM of the cluster
+// and deletes those of VMs that are gone. VMs whose configuration cannot be
+// migrated, such as one with a passed through disk, are skipped.
+func (r *ProxmoxCredsReconciler) syncProxmoxMachines(ctx context.Context, proxmoxCreds *vjailbreakv1alpha1.ProxmoxCreds,
+	pveClient *proxmox.Client,
+) (int, int, error) {
+	ctxlog := log.FromContext(ctx).WithName(constants.ProxmoxCredsControllerName)
+
+	vms, err := pveClient.ListVMs(ctx)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to list VMs")
+	}
+
+	seen := map[string]bool{}
+	skipped := 0
+	for _, res := range vms {
+		vm, err := pveClient.VMConfig(ctx, res.Node, res.VMID)
+		if err != nil {
+			ctxlog.Info("Skipping Proxmox VM", "vmid", res.VMID, "name", res.Name, "reason", err.Error())
+			skipped++
+			continue
+		}
+		name, err := commonutils.GetVMK8sCompatibleName(vm.Name, strconv.Itoa(vm.VMID), proxmoxCreds.Name)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to get k8s name of VM %d", vm.VMID)
+		}
+		seen[name] = true
+
+		proxmoxMachine := &vjailbreakv1alpha1.ProxmoxMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: proxmoxCreds.Namespace},
+		}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, proxmoxMachine, func() error {
+			if proxmoxMachine.Labels == nil {
+				proxmoxMachine.Labels = map[string]string{}
+			}
+			proxmoxMachine.Labels[constants.ProxmoxCredsLabel] = proxmoxCreds.Name
+			proxmoxMachine.Spec.VMInfo = proxmoxVMInfo(vm, res.Status)
+			return controllerutil.SetControllerReference(proxmoxCreds, proxmoxMachine, r.Scheme)
+		}); err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to create or update ProxmoxMachine '%s'", name)
+		}
+	}
+
+	existing := &vjailbreakv1alpha1.ProxmoxMachineList{}
+	if err := r.List(ctx, existing, client.InNamespace(proxmoxCreds.Namespace),
+		client.MatchingLabels{constants.ProxmoxCredsLabel: proxmoxCreds.Name}); err != nil {
+		return 0, 0, errors.Wrap(err, "failed to list ProxmoxMachines")
+	}
+	for i := range existing.Items {
+		if seen[existing.Items[i].Name] {
+			continue
+		}
+		if err := r.Delete(ctx, &existing.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			return 0, 0, errors.Wrapf(err, "failed to delete stale ProxmoxMachine '%s'", existing.Items[i].Name)
+		}
+	}
+	return len(seen), skipped, nil
+}
+
+func proxmoxVMInfo(vm *proxmox.VM, state string) vjailbreakv1alpha1.ProxmoxVMInfo {
+	info := vjailbreakv1alpha1.ProxmoxVMInfo{
+		VMID:     vm.VMID,
+		Name:     vm.Name,
+		Node:     vm.Node,
+		VMState:  state,
+		CPU:      vm.CPU,
+		MemoryMB: vm.MemoryMB,
+		UEFI:     vm.UEFI,
+		OSType:   vm.OSType,
+		OSFamily: vm.OSFamily,
+	}
+	for _, d := range vm.Disks {
+		info.Disks = append(info.Disks, vjailbreakv1alpha1.ProxmoxDisk{Key: d.Key, Volume: d.Volume, SizeBytes: d.SizeBytes, Bus: d.Bus})
+	}
+	for _, n := range vm.NICs {
+		info.NICs = append(info.NICs, vjailbreakv1alpha1.ProxmoxNIC{Key: n.Key, Model: n.Model, MAC: n.MAC, Bridge: n.Bridge})
+	}
+	return info
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ProxmoxCredsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&vjailbreakv1alpha1.ProxmoxCreds{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Complete(r)
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	constants "github.com/platform9/vjailbreak/pkg/common/constants"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newPVEStandIn serves the parts of the Proxmox VE API the ProxmoxCreds
+// controller reads, for a cluster with two VMs, a template and a container
+func newPVEStandIn(t *testing.T) *httptest.Server {
+	t.Helper()
+	responses := map[string]string{
+		"/api2/json/version": `{"version":"8.2.4"}`,
+		"/api2/json/cluster/resources": `[
+			{"type":"qemu","vmid":100,"name":"web01","node":"pve1","status":"stopped"},
+			{"type":"qemu","vmid":101,"name":"db01","node":"pve2","status":"running"},
+			{"type":"qemu","vmid":102,"name":"gpu01","node":"pve2","status":"stopped"},
+			{"type":"qemu","vmid":9000,"name":"tmpl","node":"pve1","status":"stopped","template":1},
+			{"type":"lxc","vmid":200,"name":"ct01","node":"pve1","status":"running"}
+		]`,
+		"/api2/json/nodes/pve1/qemu/100/config": `{"name":"web01","cores":2,"memory":2048,"ostype":"l26",` +
+			`"virtio0":"local-lvm:vm-100-disk-0,size=32G","net0":"virtio=BC:24:11:00:00:01,bridge=vmbr0"}`,
+		"/api2/json/nodes/pve2/qemu/101/config": `{"name":"db01","cores":4,"memory":8192,"ostype":"win2k22","bios":"ovmf",` +
+			`"scsi0":"ceph:vm-101-disk-0,size=100G","net0":"e1000=BC:24:11:00:00:02,bridge=vmbr1"}`,
+		"/api2/json/nodes/pve2/qemu/102/config": `{"name":"gpu01","scsi0":"/dev/disk/by-id/nvme-disk,size=1T"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "PVEAPIToken=root@pam!vjailbreak=5e3c1d2a" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":` + body + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testSSHPrivateKey(t *testing.T) []byte {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(block)
+}
+
+func newProxmoxCredsReconciler(t *testing.T, apiURL, tokenSecret string, objs ...client.Object) *ProxmoxCredsReconciler {
+	t.Helper()
+	scheme := applianceImportTestScheme(t)
+	ns := constants.NamespaceMigrationSystem
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(append(objs,
+			&vjailbreakv1alpha1.ProxmoxCreds{
+				ObjectMeta: metav1.ObjectMeta{Name: "lab", Namespace: ns},
+				Spec: vjailbreakv1alpha1.ProxmoxCredsSpec{
+					APIURL:    apiURL,
+					SecretRef: corev1.ObjectReference{Name: "lab-pve"},
+				},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "lab-pve", Namespace: ns},
+				Data: map[string][]byte{
+					"tokenId":     []byte("root@pam!vjailbreak"),
+					"tokenSecret": []byte(tokenSecret),
+					"privateKey":  testSSHPrivateKey(t),
+				},
+			},
+		)...).
+		WithStatusSubresource(&vjailbreakv1alpha1.ProxmoxCreds{}).
+		Build()
+	return &ProxmoxCredsReconciler{Client: fakeClient, Scheme: scheme}
+}
+
+func reconcileProxmoxCreds(t *testing.T, r *ProxmoxCredsReconciler) *vjailbreakv1alpha1.ProxmoxCreds {
+	t.Helper()
+	key := types.NamespacedName{Name: "lab", Namespace: constants.NamespaceMigrationSystem}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+	proxmoxCreds := &vjailbreakv1alpha1.ProxmoxCreds{}
+	if err := r.Get(context.Background(), key, proxmoxCreds); err != nil {
+		t.Fatal(err)
+	}
+	return proxmoxCreds
+}
+
+func TestProxmoxCredsDiscoversVMs(t *testing.T) {
+	server := newPVEStandIn(t)
+	ns := constants.NamespaceMigrationSystem
+	stale := &vjailbreakv1alpha1.ProxmoxMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "old-vm-99",
+			Namespace: ns,
+			Labels:    map[string]string{constants.ProxmoxCredsLabel: "lab"},
+		},
+	}
+	r := newProxmoxCredsReconciler(t, server.URL, "5e3c1d2a", stale)
+
+	proxmoxCreds := reconcileProxmoxCreds(t, r)
+	if proxmoxCreds.Status.ValidationStatus != constants.ProxmoxCredsStatusSucceeded || proxmoxCreds.Status.Version != "8.2.4" {
+		t.Fatalf("Status = %+v, want Succeeded on 8.2.4", proxmoxCreds.Status)
+	}
+	if !strings.Contains(proxmoxCreds.Status.ValidationMessage, "found 2 VM(s), skipped 1") {
+		t.Errorf("ValidationMessage = %q, want 2 VMs found and the passthrough VM skipped", proxmoxCreds.Status.ValidationMessage)
+	}
+
+	machines := &vjailbreakv1alpha1.ProxmoxMachineList{}
+	if err := r.List(context.Background(), machines, client.MatchingLabels{constants.ProxmoxCredsLabel: "lab"}); err != nil {
+		t.Fatal(err)
+	}
+	byVMID := map[int]vjailbreakv1alpha1.ProxmoxVMInfo{}
+	for _, m := range machines.Items {
+		if len(m.OwnerReferences) != 1 || m.OwnerReferences[0].Name != "lab" {
+			t.Errorf("ProxmoxMachine %s owners = %v, want the ProxmoxCreds", m.Name, m.OwnerReferences)
+		}
+		byVMID[m.Spec.VMInfo.VMID] = m.Spec.VMInfo
+	}
+	if len(byVMID) != 2 {
+		t.Fatalf("ProxmoxMachines = %v, want VMs 100 and 101 only", byVMID)
+	}
+	db := byVMID[101]
+	if db.Node != "pve2" || db.VMState != "running" || db.CPU != 4 || db.MemoryMB != 8192 || !db.UEFI ||
+		db.OSFamily != "windowsGuest" || len(db.Disks) != 1 || db.Disks[0].SizeBytes != 100<<30 || db.NICs[0].MAC != "bc:24:11:00:00:02" {
+		t.Errorf("VM 101 = %+v", db)
+	}
+}
+
+func TestProxmoxCredsValidationFailures(t *testing.T) {
+	server := newPVEStandIn(t)
+	tests := []struct {
+		name        string
+		apiURL      string
+		tokenSecret string
+		wantMsg     string
+	}{
+		{
+			name:        "wrong token",
+			apiURL:      server.URL,
+			tokenSecret: "wrong",
+			wantMsg:     "401",
+		},
+		{
+			name:        "invalid URL",
+			apiURL:      "pve01.example.com:8006",
+			tokenSecret: "5e3c1d2a",
+			wantMsg:     "http(s) URL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newProxmoxCredsReconciler(t, tt.apiURL, tt.tokenSecret)
+			proxmoxCreds := reconcileProxmoxCreds(t, r)
+			if proxmoxCreds.Status.ValidationStatus != constants.ProxmoxCredsStatusFailed {
+				t.Errorf("ValidationStatus = %q, want Failed", proxmoxCreds.Status.ValidationStatus)
+			}
+			if !strings.Contains(proxmoxCreds.Status.ValidationMessage, tt.wantMsg) {
+				t.Errorf("ValidationMessage = %q, want it to contain %q", proxmoxCreds.Status.ValidationMessage, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// proxmoxMigrationLabel marks the v2v-helper pod of a ProxmoxMigration
+const proxmoxMigrationLabel = "vjailbreak.k8s.pf9.io/proxmox-migration"
+
+// ProxmoxMigrationReconciler reconciles a ProxmoxMigration object
+type ProxmoxMigrationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=proxmoxmigrations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=proxmoxmigrations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=vjailbreak.k8s.pf9.io,resources=proxmoxmigrations/finalizers,verbs=update
+
+// Reconcile runs a v2v-helper job for the ProxmoxMigration once the credentials
+// of its cluster are validated, and mirrors the job state into its status.
+func (r *ProxmoxMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctxlog := log.FromContext(ctx).WithName(constants.ProxmoxMigrationControllerName)
+
+	proxmoxMigration := &vjailbreakv1alpha1.ProxmoxMigration{}
+	if err := r.Get(ctx, req.NamespacedName, proxmoxMigration); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !proxmoxMigration.DeletionTimestamp.IsZero() {
+		// The job and config maps are owned by the migration and garbage collected with it
+		return ctrl.Result{}, nil
+	}
+
+	switch proxmoxMigration.Status.Phase {
+	case vjailbreakv1alpha1.ImportPhaseSucceeded, vjailbreakv1alpha1.ImportPhaseFailed:
+		return ctrl.Result{}, nil
+	}
+
+	ctxlog.Info("Reconciling ProxmoxMigration", "name", proxmoxMigration.Name)
+
+	proxmoxMachine := &vjailbreakv1alpha1.ProxmoxMachine{}
+	machineName := proxmoxMigration.Spec.ProxmoxMachineRef.Name
+	if err := r.Get(ctx, types.NamespacedName{Name: machineName, Namespace: proxmoxMigration.Namespace}, proxmoxMachine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.setPhase(ctx, proxmoxMigration, vjailbreakv1alpha1.ImportPhaseFailed,
+				fmt.Sprintf("ProxmoxMachine '%s' not found", machineName))
+		}
+		return ctrl.Result{}, errors.Wrap(err, "failed to get proxmox machine")
+	}
+
+	proxmoxCreds := &vjailbreakv1alpha1.ProxmoxCreds{}
+	credsName := proxmoxMachine.Labels[constants.ProxmoxCredsLabel]
+	if err := r.Get(ctx, types.NamespacedName{Name: credsName, Namespace: proxmoxMigration.Namespace}, proxmoxCreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.setPhase(ctx, proxmoxMigration, vjailbreakv1alpha1.ImportPhaseFailed,
+				fmt.Sprintf("ProxmoxCreds '%s' of ProxmoxMachine '%s' not found", credsName, machineName))
+		}
+		return ctrl.Result{}, errors.Wrap(err, "failed to get proxmox credentials")
+	}
+	if proxmoxCreds.Status.ValidationStatus != constants.ProxmoxCredsStatusSucceeded {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, r.setPhase(ctx, proxmoxMigration, vjailbreakv1alpha1.ImportPhasePending,
+			fmt.Sprintf("Waiting for ProxmoxCreds '%s' to be validated", credsName))
+	}
+
+	openstackcreds := &vjailbreakv1alpha1.OpenstackCreds{}
+	if err := r.Get(ctx, types.NamespacedName{Name: proxmoxMigration.Spec.OpenstackCredsRef.Name, Namespace: proxmoxMigration.Namespace}, openstackcreds); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.setPhase(ctx, proxmoxMigration, vjailbreakv1alpha1.ImportPhaseFailed,
+				fmt.Sprintf("OpenstackCreds '%s' not found", proxmoxMigration.Spec.OpenstackCredsRef.Name))
+		}
+		return ctrl.Result{}, errors.Wrap(err, "failed to get openstack credentials")
+	}
+
+	secretEnv := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: proxmoxCreds.Spec.SecretRef.Name},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	job, err := ensureImportJob(ctx, r.Client, r.Scheme, importJob{
+		owner:          proxmoxMigration,
+		k8sName:        "proxmox-" + proxmoxMigration.Name,
+		label:          proxmoxMigrationLabel,
+		target:         proxmoxMigration.Spec.ImportTargetSpec,
+		openstackcreds: openstackcreds,
+		// The node is looked up again by the job, the VM may have moved in the meantime
+		sourceConfig: map[string]string{
+			constants.SourceTypeKey:      constants.SourceTypeProxmox,
+			constants.ProxmoxAPIURLKey:   proxmoxCreds.Spec.APIURL,
+			constants.ProxmoxInsecureKey: strconv.FormatBool(proxmoxCreds.Spec.Insecure),
+			constants.ProxmoxVMIDKey:     strconv.Itoa(proxmoxMachine.Spec.VMInfo.VMID),
+		},
+		env: []corev1.EnvVar{
+			secretEnv(constants.ProxmoxTokenIDEnv, proxmoxTokenIDKey),
+			secretEnv(constants.ProxmoxTokenSecretEnv, proxmoxTokenSecretKey),
+			secretEnv(constants.ProxmoxSSHPrivateKeyEnv, proxmoxSSHPrivateKeyKey),
+		},
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	phase, message := importPhaseFromJob(job, "VM migrated successfully")
+	proxmoxMigration.Status.JobName = job.Name
+	return ctrl.Result{}, r.setPhase(ctx, proxmoxMigration, phase, message)
+}
+
+func (r *ProxmoxMigrationReconciler) setPhase(ctx context.Context, proxmoxMigration *vjailbreakv1alpha1.ProxmoxMigration,
+	phase vjailbreakv1alpha1.ImportPhase, message string,
+) error {
+	return setImportPhase(ctx, r.Client, proxmoxMigration, &proxmoxMigration.Status, phase, message)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ProxmoxMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&vjailbreakv1alpha1.ProxmoxMigration{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
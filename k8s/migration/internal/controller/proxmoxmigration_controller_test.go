@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/k8s/migration/pkg/utils"
+	constants "github.com/platform9/vjailbreak/pkg/common/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newProxmoxMigrationReconciler(t *testing.T, credsStatus string) (*ProxmoxMigrationReconciler, client.Client) {
+	t.Helper()
+	scheme := applianceImportTestScheme(t)
+	ns := constants.NamespaceMigrationSystem
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: constants.VjailbreakSettingsConfigMapName, Namespace: ns}},
+			&vjailbreakv1alpha1.OpenstackCreds{
+				ObjectMeta: metav1.ObjectMeta{Name: "pcd", Namespace: ns},
+				Spec: vjailbreakv1alpha1.OpenstackCredsSpec{
+					SecretRef: corev1.ObjectReference{Name: "pcd-openstack-secret"},
+				},
+			},
+			&vjailbreakv1alpha1.ProxmoxCreds{
+				ObjectMeta: metav1.ObjectMeta{Name: "lab", Namespace: ns},
+				Spec: vjailbreakv1alpha1.ProxmoxCredsSpec{
+					APIURL:    "https://pve01.example.com:8006",
+					SecretRef: corev1.ObjectReference{Name: "lab-pve"},
+					Insecure:  true,
+				},
+				Status: vjailbreakv1alpha1.ProxmoxCredsStatus{ValidationStatus: credsStatus},
+			},
+			&vjailbreakv1alpha1.ProxmoxMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "web01-100-abcde",
+					Namespace: ns,
+					Labels:    map[string]string{constants.ProxmoxCredsLabel: "lab"},
+				},
+				Spec: vjailbreakv1alpha1.ProxmoxMachineSpec{
+					VMInfo: vjailbreakv1alpha1.ProxmoxVMInfo{VMID: 100, Name: "web01", Node: "pve1"},
+				},
+			},
+			&vjailbreakv1alpha1.ProxmoxMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "web01", Namespace: ns},
+				Spec: vjailbreakv1alpha1.ProxmoxMigrationSpec{
+					ProxmoxMachineRef: corev1.LocalObjectReference{Name: "web01-100-abcde"},
+					ImportTargetSpec: vjailbreakv1alpha1.ImportTargetSpec{
+						OpenstackCredsRef: corev1.LocalObjectReference{Name: "pcd"},
+						NetworkNames:      []string{"mgmt"},
+					},
+				},
+			},
+		).
+		WithStatusSubresource(&vjailbreakv1alpha1.ProxmoxMigration{}, &vjailbreakv1alpha1.ProxmoxCreds{}).
+		Build()
+	return &ProxmoxMigrationReconciler{Client: fakeClient, Scheme: scheme}, fakeClient
+}
+
+func reconcileProxmoxMigration(t *testing.T, r *ProxmoxMigrationReconciler) *vjailbreakv1alpha1.ProxmoxMigration {
+	t.Helper()
+	key := types.NamespacedName{Name: "web01", Namespace: constants.NamespaceMigrationSystem}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() unexpected error: %v", err)
+	}
+	proxmoxMigration := &vjailbreakv1alpha1.ProxmoxMigration{}
+	if err := r.Get(context.Background(), key, proxmoxMigration); err != nil {
+		t.Fatal(err)
+	}
+	return proxmoxMigration
+}
+
+func TestProxmoxMigrationCreatesJob(t *testing.T) {
+	r, c := newProxmoxMigrationReconciler(t, constants.ProxmoxCredsStatusSucceeded)
+	ctx := context.Background()
+	ns := constants.NamespaceMigrationSystem
+
+	proxmoxMigration := reconcileProxmoxMigration(t, r)
+	if proxmoxMigration.Status.Phase != vjailbreakv1alpha1.ImportPhasePending || proxmoxMigration.Status.JobName == "" {
+		t.Fatalf("Status = %+v, want Pending with a job", proxmoxMigration.Status)
+	}
+
+	job := &batchv1.Job{}
+	if err := c.Get(ctx, types.NamespacedName{Name: proxmoxMigration.Status.JobName, Namespace: ns}, job); err != nil {
+		t.Fatalf("job not created: %v", err)
+	}
+	if job.Spec.Template.Labels[proxmoxMigrationLabel] != "web01" {
+		t.Errorf("pod labels = %v, want %s=web01", job.Spec.Template.Labels, proxmoxMigrationLabel)
+	}
+	wantEnv := map[string]string{
+		constants.ProxmoxTokenIDEnv:       "tokenId",
+		constants.ProxmoxTokenSecretEnv:   "tokenSecret",
+		constants.ProxmoxSSHPrivateKeyEnv: "privateKey",
+	}
+	for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+		key, ok := wantEnv[env.Name]
+		if !ok {
+			continue
+		}
+		if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil ||
+			env.ValueFrom.SecretKeyRef.Name != "lab-pve" || env.ValueFrom.SecretKeyRef.Key != key {
+			t.Errorf("env %s = %+v, want lab-pve/%s", env.Name, env.ValueFrom, key)
+		}
+		delete(wantEnv, env.Name)
+	}
+	if len(wantEnv) != 0 {
+		t.Errorf("job is missing env %v", wantEnv)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Name: utils.GetMigrationConfigMapName("proxmox-web01"), Namespace: ns}, configMap); err != nil {
+		t.Fatalf("migration config map not created: %v", err)
+	}
+	want := map[string]string{
+		constants.SourceTypeKey:      constants.SourceTypeProxmox,
+		constants.ProxmoxAPIURLKey:   "https://pve01.example.com:8006",
+		constants.ProxmoxInsecureKey: "true",
+		constants.ProxmoxVMIDKey:     "100",
+		"NEUTRON_NETWORK_NAMES":      "mgmt",
+	}
+	for k, v := range want {
+		if configMap.Data[k] != v {
+			t.Errorf("config map %s = %q, want %q", k, configMap.Data[k], v)
+		}
+	}
+
+	job.Status.Succeeded = 1
+	if err := c.Status().Update(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	proxmoxMigration = reconcileProxmoxMigration(t, r)
+	if proxmoxMigration.Status.Phase != vjailbreakv1alpha1.ImportPhaseSucceeded {
+		t.Errorf("Phase = %q after job success, want Succeeded", proxmoxMigration.Status.Phase)
+	}
+}
+
+func TestProxmoxMigrationWaitsForValidatedCreds(t *testing.T) {
+	r, c := newProxmoxMigrationReconciler(t, constants.ProxmoxCredsStatusFailed)
+
+	proxmoxMigration := reconcileProxmoxMigration(t, r)
+	if proxmoxMigration.Status.Phase != vjailbreakv1alpha1.ImportPhasePending {
+		t.Errorf("Phase = %q, want Pending", proxmoxMigration.Status.Phase)
+	}
+	if n := countJobs(t, c); n != 0 {
+		t.Errorf("created %d jobs before the cluster was validated, want 0", n)
+	}
+}
@@ -18,6 +18,9 @@ limitations under the License.
 package migrationmetrics
 
 import (
+	"maps"
+	"slices"
+	"sync"
 	"time"
 
 	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
@@ -81,8 +84,76 @@ var (
 		},
 		[]string{"migration_name", "vm_name", "namespace", "migration_plan", "agent_name"},
 	)
+
+	// The disk transfer metrics follow the copy of each disk as the migration pod
+	// reports it in the DiskTransfers of the Migration status
+	// Labels: migration_name, namespace, disk, method
+	// method values from DiskTransferMethod: nbd, hot-add, storage-accelerated
+
+	// MigrationDiskBytesCopied tracks how much of the current copy pass of each disk is done
+	MigrationDiskBytesCopied = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vjailbreak_migration_disk_bytes_copied",
+			Help: "Bytes copied in the current copy pass of a disk, the whole disk or the changed blocks of a CBT sync",
+		},
+		diskTransferLabels,
+	)
+
+	// MigrationDiskBytesRemaining tracks how much of the current copy pass of each disk is left
+	MigrationDiskBytesRemaining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vjailbreak_migration_disk_bytes_remaining",
+			Help: "Bytes left to copy in the current copy pass of a disk",
+		},
+		diskTransferLabels,
+	)
+
+	// MigrationDiskThroughput tracks the recent copy rate of each disk
+	MigrationDiskThroughput = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vjailbreak_migration_disk_throughput_megabytes_per_second",
+			Help: "Recent copy rate of a disk in MB/s",
+		},
+		diskTransferLabels,
+	)
+
+	// MigrationDiskCBTDeltaBytes tracks the size of the changed blocks of the last CBT sync of each disk
+	MigrationDiskCBTDeltaBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vjailbreak_migration_disk_cbt_delta_bytes",
+			Help: "Size of the changed blocks found by the last CBT sync of a disk",
+		},
+		diskTransferLabels,
+	)
+
+	// MigrationDiskETASeconds estimates when the current copy pass of each disk ends
+	MigrationDiskETASeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "vjailbreak_migration_disk_eta_seconds",
+			Help: "Estimated seconds until the current copy pass of a disk is done at its recent rate, absent while there is no rate",
+		},
+		diskTransferLabels,
+	)
 )
 
+var diskTransferLabels = []string{"migration_name", "namespace", "disk", "method"}
+
+var (
+	// diskTransfers holds the labels RecordDiskTransfers last set per migration,
+	// so that disks a migration stops reporting can be removed
+	diskTransfers   = map[string][]prometheus.Labels{}
+	diskTransfersMu sync.Mutex
+)
+
+// diskTransferGauges are the gauges RecordDiskTransfers sets, for cleanup
+var diskTransferGauges = []*prometheus.GaugeVec{
+	MigrationDiskBytesCopied,
+	MigrationDiskBytesRemaining,
+	MigrationDiskThroughput,
+	MigrationDiskCBTDeltaBytes,
+	MigrationDiskETASeconds,
+}
+
 func init() {
 	// Register custom metrics with the global prometheus registry
 	metrics.Registry.MustRegister(
@@ -92,6 +163,11 @@ func init() {
 		MigrationCompletedTotal,
 		MigrationExpectedDurationSeconds,
 		MigrationInfo,
+		MigrationDiskBytesCopied,
+		MigrationDiskBytesRemaining,
+		MigrationDiskThroughput,
+		MigrationDiskCBTDeltaBytes,
+		MigrationDiskETASeconds,
 	)
 }
 
@@ -107,6 +183,7 @@ func UpdateMigrationPhase(migrationName, vmName, namespace, agentName string, ph
 	// Get all possible phases
 	allPhases := []vjailbreakv1alpha1.VMMigrationPhase{
 		vjailbreakv1alpha1.VMMigrationPhasePending,
+		vjailbreakv1alpha1.VMMigrationPhaseQueued,
 		vjailbreakv1alpha1.VMMigrationPhaseValidating,
 		vjailbreakv1alpha1.VMMigrationPhaseValidationFailed,
 		vjailbreakv1alpha1.VMMigrationPhaseAwaitingDataCopyStart,
@@ -147,8 +224,9 @@ func RecordMigrationCompleted(migrationName, vmName, namespace, agentName string
 	MigrationCompletedTotal.WithLabelValues(vmName, status, namespace).Inc()
 	UpdateMigrationPhase(migrationName, vmName, namespace, agentName, phase)
 
-	// Clear duration metric for completed migrations
+	// Clear duration and disk transfer metrics for completed migrations
 	MigrationDurationSeconds.DeleteLabelValues(migrationName, vmName, namespace)
+	cleanupDiskTransferMetrics(migrationName, namespace)
 }
 
 // SetExpectedDuration sets the expected duration threshold for alerting
@@ -156,11 +234,58 @@ func SetExpectedDuration(namespace string, durationSeconds float64) {
 	MigrationExpectedDurationSeconds.WithLabelValues(namespace).Set(durationSeconds)
 }
 
+// RecordDiskTransfers sets the disk transfer metrics of a migration to the
+// transfers its pod reported. Disks it no longer reports are removed.
+func RecordDiskTransfers(migrationName, namespace string, transfers []vjailbreakv1alpha1.DiskTransfer) {
+	reported := make([]prometheus.Labels, 0, len(transfers))
+	for i := range transfers {
+		transfer := &transfers[i]
+		labels := prometheus.Labels{
+			"migration_name": migrationName,
+			"namespace":      namespace,
+			"disk":           transfer.Disk,
+			"method":         string(transfer.Method),
+		}
+		reported = append(reported, labels)
+		MigrationDiskBytesCopied.With(labels).Set(float64(transfer.BytesCopied))
+		MigrationDiskBytesRemaining.With(labels).Set(float64(transfer.BytesRemaining()))
+		MigrationDiskThroughput.With(labels).Set(float64(transfer.BytesPerSecond) / 1e6)
+		MigrationDiskCBTDeltaBytes.With(labels).Set(float64(transfer.CBTDeltaBytes))
+		if eta, ok := transfer.ETA(); ok {
+			MigrationDiskETASeconds.With(labels).Set(eta.Seconds())
+		} else {
+			MigrationDiskETASeconds.Delete(labels)
+		}
+	}
+
+	key := namespace + "/" + migrationName
+	diskTransfersMu.Lock()
+	defer diskTransfersMu.Unlock()
+	for _, labels := range diskTransfers[key] {
+		if !slices.ContainsFunc(reported, func(l prometheus.Labels) bool { return maps.Equal(l, labels) }) {
+			for _, gauge := range diskTransferGauges {
+				gauge.Delete(labels)
+			}
+		}
+	}
+	diskTransfers[key] = reported
+}
+
+func cleanupDiskTransferMetrics(migrationName, namespace string) {
+	diskTransfersMu.Lock()
+	delete(diskTransfers, namespace+"/"+migrationName)
+	diskTransfersMu.Unlock()
+	for _, gauge := range diskTransferGauges {
+		gauge.DeletePartialMatch(prometheus.Labels{"migration_name": migrationName, "namespace": namespace})
+	}
+}
+
 // CleanupMigrationMetrics removes all metrics for a specific migration (when CR is deleted)
 func CleanupMigrationMetrics(migrationName, vmName, namespace, migrationPlan, agentName string) {
 	// Clean up all phase metrics
 	allPhases := []vjailbreakv1alpha1.VMMigrationPhase{
 		vjailbreakv1alpha1.VMMigrationPhasePending,
+		vjailbreakv1alpha1.VMMigrationPhaseQueued,
 		vjailbreakv1alpha1.VMMigrationPhaseValidating,
 		vjailbreakv1alpha1.VMMigrationPhaseValidationFailed,
 		vjailbreakv1alpha1.VMMigrationPhaseAwaitingDataCopyStart,
@@ -180,4 +305,5 @@ func CleanupMigrationMetrics(migrationName, vmName, namespace, migrationPlan, ag
 
 	MigrationDurationSeconds.DeleteLabelValues(migrationName, vmName, namespace)
 	MigrationInfo.DeleteLabelValues(migrationName, vmName, namespace, migrationPlan, agentName)
+	cleanupDiskTransferMetrics(migrationName, namespace)
 }
@@ -0,0 +1,49 @@
+package migrationmetrics
+
+import (
+	"testing"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordDiskTransfers(t *testing.T) {
+	transfers := []vjailbreakv1alpha1.DiskTransfer{
+		{Disk: "disk-1", Method: vjailbreakv1alpha1.DiskTransferMethodNBD,
+			BytesCopied: 30e6, BytesTotal: 100e6, BytesPerSecond: 10e6, CBTDeltaBytes: 5e6},
+		// No rate yet
+		{Disk: "disk-2", Method: vjailbreakv1alpha1.DiskTransferMethodNBD, BytesTotal: 100e6},
+	}
+	RecordDiskTransfers("migration-1", "ns", transfers)
+	defer cleanupDiskTransferMetrics("migration-1", "ns")
+
+	disk1 := []string{"migration-1", "ns", "disk-1", "nbd"}
+	for name, tc := range map[string]struct {
+		got  float64
+		want float64
+	}{
+		"bytes copied":    {testutil.ToFloat64(MigrationDiskBytesCopied.WithLabelValues(disk1...)), 30e6},
+		"bytes remaining": {testutil.ToFloat64(MigrationDiskBytesRemaining.WithLabelValues(disk1...)), 70e6},
+		"throughput":      {testutil.ToFloat64(MigrationDiskThroughput.WithLabelValues(disk1...)), 10},
+		"cbt delta":       {testutil.ToFloat64(MigrationDiskCBTDeltaBytes.WithLabelValues(disk1...)), 5e6},
+		"eta":             {testutil.ToFloat64(MigrationDiskETASeconds.WithLabelValues(disk1...)), 7},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %v, want %v", name, tc.got, tc.want)
+		}
+	}
+	if n := testutil.CollectAndCount(MigrationDiskETASeconds); n != 1 {
+		t.Errorf("%d ETA series, want only the one of disk-1", n)
+	}
+
+	// A disk that is no longer reported is removed
+	RecordDiskTransfers("migration-1", "ns", transfers[:1])
+	if n := testutil.CollectAndCount(MigrationDiskBytesCopied); n != 1 {
+		t.Errorf("%d bytes copied series, want 1", n)
+	}
+
+	CleanupMigrationMetrics("migration-1", "vm-1", "ns", "plan-1", "agent-1")
+	if n := testutil.CollectAndCount(MigrationDiskBytesCopied); n != 0 {
+		t.Errorf("%d bytes copied series after cleanup, want 0", n)
+	}
+}
@@ -0,0 +1,384 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/flavors"
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	openstackcommon "github.com/platform9/vjailbreak/pkg/common/openstack"
+)
+
+// assessmentWarningPenalty is what each warning takes off the readiness score
+// of a VM, which never falls below assessmentMinScore unless it is blocked
+const (
+	assessmentWarningPenalty = 15
+	assessmentMinScore       = 10
+)
+
+// vCenterLinuxGuestNames are Linux distributions virt-v2v converts that vCenter
+// names differently from constants.SupportedLinuxOS
+var vCenterLinuxGuestNames = []string{"suse linux enterprise", "almalinux"}
+
+// AssessmentFinding is one thing the assessment of a VM found
+type AssessmentFinding struct {
+	Check    vjailbreakv1alpha1.AssessmentCheck    `json:"check"`
+	Severity vjailbreakv1alpha1.AssessmentSeverity `json:"severity"`
+	Message  string                                `json:"message"`
+}
+
+// VMAssessment is the assessment of one VM, as written to the report
+type VMAssessment struct {
+	Name          string                                 `json:"name"`
+	VMwareMachine string                                 `json:"vmwareMachine"`
+	OSFamily      string                                 `json:"osFamily,omitempty"`
+	GuestOS       string                                 `json:"guestOS,omitempty"`
+	Readiness     vjailbreakv1alpha1.AssessmentReadiness `json:"readiness"`
+	Score         int                                    `json:"score"`
+	// Flavor is the flavor the VM would be created with, when flavors were checked
+	Flavor   string              `json:"flavor,omitempty"`
+	Findings []AssessmentFinding `json:"findings,omitempty"`
+}
+
+// AssessmentTargets is what the VMs are assessed against. A nil mapping or
+// nil OpenstackCreds leaves its check out.
+type AssessmentTargets struct {
+	NetworkMapping *vjailbreakv1alpha1.NetworkMapping
+	StorageMapping *vjailbreakv1alpha1.StorageMapping
+	OpenstackCreds *vjailbreakv1alpha1.OpenstackCreds
+}
+
+// AssessVM assesses a VMwareMachine for what is in the way of its migration
+// and scores how ready it is
+func AssessVM(vmwvm *vjailbreakv1alpha1.VMwareMachine, targets AssessmentTargets) VMAssessment {
+	vm := &vmwvm.Spec.VMInfo
+	assessment := VMAssessment{
+		Name:          vm.Name,
+		VMwareMachine: vmwvm.Name,
+		OSFamily:      vm.OSFamily,
+		GuestOS:       vm.GuestOS,
+	}
+	add := func(check vjailbreakv1alpha1.AssessmentCheck, severity vjailbreakv1alpha1.AssessmentSeverity, format string, args ...any) {
+		assessment.Findings = append(assessment.Findings, AssessmentFinding{
+			Check: check, Severity: severity, Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	assessOS(vm, add)
+	assessHardware(vm, add)
+	if targets.NetworkMapping != nil {
+		assessNetworkMapping(vm, targets.NetworkMapping, add)
+	}
+	if targets.StorageMapping != nil {
+		assessStorageMapping(vm, targets.StorageMapping, add)
+	}
+	if targets.OpenstackCreds != nil {
+		assessment.Flavor = assessFlavor(vmwvm, targets.OpenstackCreds.Spec.Flavors, add)
+	} else if vm.GPU.HasGPU() {
+		add(vjailbreakv1alpha1.AssessmentCheckFlavor, vjailbreakv1alpha1.AssessmentSeverityInfo,
+			"%d passthrough GPU(s) and %d vGPU(s), the flavor has to provide them", vm.GPU.PassthroughCount, vm.GPU.VGPUCount)
+	}
+
+	assessment.Readiness, assessment.Score = scoreFindings(assessment.Findings)
+	return assessment
+}
+
+// assessmentAdder records a finding of the VM being assessed
+type assessmentAdder func(check vjailbreakv1alpha1.AssessmentCheck, severity vjailbreakv1alpha1.AssessmentSeverity, format string, args ...any)
+
+// assessOS checks the guest is one virt-v2v converts. vCenter only knows the
+// guest OS configured on the VM, conversion checks the installed one again.
+func assessOS(vm *vjailbreakv1alpha1.VMInfo, add assessmentAdder) {
+	guest := strings.ToLower(vm.GuestOS)
+	family := strings.ToLower(vm.OSFamily)
+	if family == "" {
+		switch {
+		case strings.Contains(guest, "windows"):
+			family = constants.OSFamilyWindows
+		case isSupportedLinuxGuest(guest):
+			family = constants.OSFamilyLinux
+		}
+	}
+	switch family {
+	case constants.OSFamilyWindows:
+	case constants.OSFamilyLinux:
+		if guest != "" && !isSupportedLinuxGuest(guest) {
+			add(vjailbreakv1alpha1.AssessmentCheckOperatingSystem, vjailbreakv1alpha1.AssessmentSeverityWarning,
+				"%s is not one of the Linux distributions virt-v2v converts, conversion fails unless the installed OS is", vm.GuestOS)
+		}
+	case "":
+		add(vjailbreakv1alpha1.AssessmentCheckOperatingSystem, vjailbreakv1alpha1.AssessmentSeverityWarning,
+			"The guest OS is unknown, start the VM with VMware Tools running so it is discovered")
+	default:
+		add(vjailbreakv1alpha1.AssessmentCheckOperatingSystem, vjailbreakv1alpha1.AssessmentSeverityBlocking,
+			"virt-v2v converts Linux and Windows guests only, not %s", guestOSName(vm))
+	}
+}
+
+// isSupportedLinuxGuest tells whether a lower case guest OS name is one of the
+// Linux distributions virt-v2v converts
+func isSupportedLinuxGuest(guest string) bool {
+	matches := func(name string) bool { return strings.Contains(guest, name) }
+	return slices.ContainsFunc(constants.SupportedLinuxOS, matches) || slices.ContainsFunc(vCenterLinuxGuestNames, matches)
+}
+
+// guestOSName names the guest OS of a VM in a finding
+func guestOSName(vm *vjailbreakv1alpha1.VMInfo) string {
+	if vm.GuestOS != "" {
+		return vm.GuestOS
+	}
+	return vm.OSFamily
+}
+
+// assessHardware checks the firmware, devices, disks and snapshots of the VM
+func assessHardware(vm *vjailbreakv1alpha1.VMInfo, add assessmentAdder) {
+	hardware := &vm.Hardware
+	if strings.EqualFold(hardware.Firmware, "efi") {
+		if hardware.SecureBoot {
+			add(vjailbreakv1alpha1.AssessmentCheckFirmware, vjailbreakv1alpha1.AssessmentSeverityWarning,
+				"UEFI Secure Boot is enabled, the migrated VM boots with UEFI and Secure Boot off")
+		} else {
+			add(vjailbreakv1alpha1.AssessmentCheckFirmware, vjailbreakv1alpha1.AssessmentSeverityInfo,
+				"UEFI firmware, the migrated VM boots with UEFI")
+		}
+	}
+	if len(vm.Disks) == 0 && len(vm.RDMDisks) == 0 {
+		add(vjailbreakv1alpha1.AssessmentCheckDisks, vjailbreakv1alpha1.AssessmentSeverityBlocking,
+			"The VM has no disks to migrate")
+	}
+	if len(vm.RDMDisks) > 0 {
+		add(vjailbreakv1alpha1.AssessmentCheckRDMDisks, vjailbreakv1alpha1.AssessmentSeverityWarning,
+			"RDM disks %s are not copied, each needs an RDMDisk naming the Cinder volume that takes its place",
+			strings.Join(vm.RDMDisks, ", "))
+	}
+	if len(hardware.IndependentDisks) > 0 {
+		add(vjailbreakv1alpha1.AssessmentCheckIndependentDisks, vjailbreakv1alpha1.AssessmentSeverityBlocking,
+			"VDDK cannot read disks in independent mode: %s. Switch them to dependent mode.",
+			strings.Join(hardware.IndependentDisks, ", "))
+	}
+	if hardware.USBDevices > 0 {
+		add(vjailbreakv1alpha1.AssessmentCheckUSBDevices, vjailbreakv1alpha1.AssessmentSeverityWarning,
+			"%d USB controller(s) or device(s) are not migrated", hardware.USBDevices)
+	}
+	if hardware.SerialPorts > 0 {
+		add(vjailbreakv1alpha1.AssessmentCheckSerialPorts, vjailbreakv1alpha1.AssessmentSeverityWarning,
+			"%d serial port(s) are not migrated", hardware.SerialPorts)
+	}
+	switch {
+	case hardware.SnapshotDepth >= constants.MaxSnapshotChainDepth:
+		add(vjailbreakv1alpha1.AssessmentCheckSnapshots, vjailbreakv1alpha1.AssessmentSeverityBlocking,
+			"The VM runs on a chain of %d snapshots, the longest vSphere supports, which leaves no room for the migration snapshot. Consolidate or delete them.",
+			hardware.SnapshotDepth)
+	case hardware.SnapshotDepth > 0:
+		add(vjailbreakv1alpha1.AssessmentCheckSnapshots, vjailbreakv1alpha1.AssessmentSeverityWarning,
+			"The VM runs on a chain of %d snapshot(s), which slows the copy down. Consolidate or delete them.",
+			hardware.SnapshotDepth)
+	}
+	switch {
+	case hardware.Version > 0 && hardware.Version < constants.MinCBTHardwareVersion:
+		add(vjailbreakv1alpha1.AssessmentCheckHardwareVersion, vjailbreakv1alpha1.AssessmentSeverityWarning,
+			"Virtual hardware version %d has no Changed Block Tracking, which version %d adds. Migrate it cold or upgrade its hardware.",
+			hardware.Version, constants.MinCBTHardwareVersion)
+	case !hardware.CBTEnabled:
+		add(vjailbreakv1alpha1.AssessmentCheckChangedBlockTracking, vjailbreakv1alpha1.AssessmentSeverityInfo,
+			"Changed Block Tracking is off, a hot migration turns it on")
+	}
+}
+
+// assessNetworkMapping checks every network of the VM has a target
+func assessNetworkMapping(vm *vjailbreakv1alpha1.VMInfo, networkmap *vjailbreakv1alpha1.NetworkMapping, add assessmentAdder) {
+	mapped := make(map[string]bool, len(networkmap.Spec.Networks))
+	for _, network := range networkmap.Spec.Networks {
+		mapped[network.Source] = true
+	}
+	if missing := unmapped(vm.Networks, mapped); len(missing) > 0 {
+		add(vjailbreakv1alpha1.AssessmentCheckNetworkMapping, vjailbreakv1alpha1.AssessmentSeverityBlocking,
+			"Networks %s are not in NetworkMapping %s", strings.Join(missing, ", "), networkmap.Name)
+	}
+}
+
+// assessStorageMapping checks the datastore of every disk of the VM has a target
+func assessStorageMapping(vm *vjailbreakv1alpha1.VMInfo, storagemap *vjailbreakv1alpha1.StorageMapping, add assessmentAdder) {
+	mapped := make(map[string]bool, len(storagemap.Spec.Storages))
+	for _, storage := range storagemap.Spec.Storages {
+		mapped[storage.Source] = true
+	}
+	datastores := vm.Datastores
+	if len(vm.Disks) > 0 {
+		datastores = make([]string, 0, len(vm.Disks))
+		for _, disk := range vm.Disks {
+			datastores = append(datastores, disk.Datastore)
+		}
+	}
+	if missing := unmapped(datastores, mapped); len(missing) > 0 {
+		add(vjailbreakv1alpha1.AssessmentCheckStorageMapping, vjailbreakv1alpha1.AssessmentSeverityBlocking,
+			"Datastores %s are not in StorageMapping %s", strings.Join(missing, ", "), storagemap.Name)
+	}
+}
+
+// unmapped returns the sources without a mapping, once each and in order
+func unmapped(sources []string, mapped map[string]bool) []string {
+	var missing []string
+	for _, source := range sources {
+		if !mapped[source] && !slices.Contains(missing, source) {
+			missing = append(missing, source)
+		}
+	}
+	return missing
+}
+
+// assessFlavor finds the flavor the VM would be created with, the one set on
+// the VMwareMachine or else the closest that fits, and returns its name
+func assessFlavor(vmwvm *vjailbreakv1alpha1.VMwareMachine, allFlavors []flavors.Flavor, add assessmentAdder) string {
+	vm := &vmwvm.Spec.VMInfo
+	if id := vmwvm.Spec.TargetFlavorID; id != "" {
+		for _, flavor := range allFlavors {
+			if flavor.ID != id {
+				continue
+			}
+			if flavor.VCPUs < vm.CPU || flavor.RAM < vm.Memory {
+				add(vjailbreakv1alpha1.AssessmentCheckFlavor, vjailbreakv1alpha1.AssessmentSeverityWarning,
+					"Target flavor %s has %d vCPUs and %d MB RAM, less than the %d vCPUs and %d MB of the VM",
+					flavor.Name, flavor.VCPUs, flavor.RAM, vm.CPU, vm.Memory)
+			}
+			return flavor.Name
+		}
+		add(vjailbreakv1alpha1.AssessmentCheckFlavor, vjailbreakv1alpha1.AssessmentSeverityBlocking,
+			"Target flavor %s does not exist", id)
+		return ""
+	}
+	flavor, err := openstackcommon.GetClosestFlavour(vm.CPU, vm.Memory, vm.GPU.PassthroughCount, vm.GPU.VGPUCount, allFlavors, false)
+	if err != nil || flavor == nil {
+		message := fmt.Sprintf("no flavor fits %d vCPUs and %d MB RAM", vm.CPU, vm.Memory)
+		if err != nil {
+			message = err.Error()
+		}
+		add(vjailbreakv1alpha1.AssessmentCheckFlavor, vjailbreakv1alpha1.AssessmentSeverityBlocking, "%s", message)
+		return ""
+	}
+	return flavor.Name
+}
+
+// scoreFindings rates a VM from its findings: 0 when one is blocking, else 100
+// less assessmentWarningPenalty for each warning. Info findings cost nothing.
+func scoreFindings(findings []AssessmentFinding) (vjailbreakv1alpha1.AssessmentReadiness, int) {
+	warnings := 0
+	for _, finding := range findings {
+		switch finding.Severity {
+		case vjailbreakv1alpha1.AssessmentSeverityBlocking:
+			return vjailbreakv1alpha1.AssessmentReadinessBlocked, 0
+		case vjailbreakv1alpha1.AssessmentSeverityWarning:
+			warnings++
+		}
+	}
+	if warnings == 0 {
+		return vjailbreakv1alpha1.AssessmentReadinessReady, 100
+	}
+	return vjailbreakv1alpha1.AssessmentReadinessNeedsAttention, max(100-warnings*assessmentWarningPenalty, assessmentMinScore)
+}
+
+// severityRank orders severities from the most to the least severe
+var severityRank = map[vjailbreakv1alpha1.AssessmentSeverity]int{
+	vjailbreakv1alpha1.AssessmentSeverityBlocking: 0,
+	vjailbreakv1alpha1.AssessmentSeverityWarning:  1,
+	vjailbreakv1alpha1.AssessmentSeverityInfo:     2,
+}
+
+// SummarizeAssessments counts the VMs by readiness and, for every check and
+// severity, the VMs with such a finding, the most severe and common first
+func SummarizeAssessments(assessments []VMAssessment) (vjailbreakv1alpha1.AssessmentSummary, []vjailbreakv1alpha1.AssessmentIssueCount) {
+	summary := vjailbreakv1alpha1.AssessmentSummary{TotalVMs: len(assessments)}
+	type issue struct {
+		check    vjailbreakv1alpha1.AssessmentCheck
+		severity vjailbreakv1alpha1.AssessmentSeverity
+	}
+	counts := map[issue]int{}
+	totalScore := 0
+	for _, assessment := range assessments {
+		switch assessment.Readiness {
+		case vjailbreakv1alpha1.AssessmentReadinessReady:
+			summary.Ready++
+		case vjailbreakv1alpha1.AssessmentReadinessNeedsAttention:
+			summary.NeedsAttention++
+		case vjailbreakv1alpha1.AssessmentReadinessBlocked:
+			summary.Blocked++
+		}
+		totalScore += assessment.Score
+		seen := map[issue]bool{}
+		for _, finding := range assessment.Findings {
+			key := issue{check: finding.Check, severity: finding.Severity}
+			if !seen[key] {
+				seen[key] = true
+				counts[key]++
+			}
+		}
+	}
+	if len(assessments) > 0 {
+		summary.AverageScore = totalScore / len(assessments)
+	}
+
+	issues := make([]vjailbreakv1alpha1.AssessmentIssueCount, 0, len(counts))
+	for key, vms := range counts {
+		issues = append(issues, vjailbreakv1alpha1.AssessmentIssueCount{Check: key.check, Severity: key.severity, VMs: vms})
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if ri, rj := severityRank[issues[i].Severity], severityRank[issues[j].Severity]; ri != rj {
+			return ri < rj
+		}
+		if issues[i].VMs != issues[j].VMs {
+			return issues[i].VMs > issues[j].VMs
+		}
+		return issues[i].Check < issues[j].Check
+	})
+	return summary, issues
+}
+
+// AssessmentReportJSON renders the assessments as a JSON array
+func AssessmentReportJSON(assessments []VMAssessment) (string, error) {
+	if assessments == nil {
+		assessments = []VMAssessment{}
+	}
+	data, err := json.MarshalIndent(assessments, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal assessment report")
+	}
+	return string(data), nil
+}
+
+// AssessmentReportCSV renders the assessments as CSV, one row per VM with its
+// findings joined by severity
+func AssessmentReportCSV(assessments []VMAssessment) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	rows := [][]string{{"name", "vmwareMachine", "osFamily", "guestOS", "readiness", "score", "flavor", "blocking", "warnings", "info"}}
+	for _, assessment := range assessments {
+		findings := map[vjailbreakv1alpha1.AssessmentSeverity][]string{}
+		for _, finding := range assessment.Findings {
+			findings[finding.Severity] = append(findings[finding.Severity], fmt.Sprintf("%s: %s", finding.Check, finding.Message))
+		}
+		rows = append(rows, []string{
+			assessment.Name,
+			assessment.VMwareMachine,
+			assessment.OSFamily,
+			assessment.GuestOS,
+			string(assessment.Readiness),
+			strconv.Itoa(assessment.Score),
+			assessment.Flavor,
+			strings.Join(findings[vjailbreakv1alpha1.AssessmentSeverityBlocking], "; "),
+			strings.Join(findings[vjailbreakv1alpha1.AssessmentSeverityWarning], "; "),
+			strings.Join(findings[vjailbreakv1alpha1.AssessmentSeverityInfo], "; "),
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return "", errors.Wrap(err, "failed to write assessment report")
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,293 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/flavors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func assessmentTestMachine(mutate func(*vjailbreakv1alpha1.VMwareMachine)) *vjailbreakv1alpha1.VMwareMachine {
+	vmwvm := &vjailbreakv1alpha1.VMwareMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-01-vm-101-vmware"},
+		Spec: vjailbreakv1alpha1.VMwareMachineSpec{
+			VMInfo: vjailbreakv1alpha1.VMInfo{
+				Name:     "web-01",
+				OSFamily: "linuxGuest",
+				GuestOS:  "Ubuntu Linux (64-bit)",
+				CPU:      2,
+				Memory:   4096,
+				Networks: []string{"VM Network"},
+				Disks:    []vjailbreakv1alpha1.Disk{{Name: "Hard disk 1", Datastore: "ds1"}},
+				Hardware: vjailbreakv1alpha1.VMHardware{Firmware: "bios", Version: 19, CBTEnabled: true},
+			},
+		},
+	}
+	if mutate != nil {
+		mutate(vmwvm)
+	}
+	return vmwvm
+}
+
+func assessmentTestTargets() AssessmentTargets {
+	return AssessmentTargets{
+		NetworkMapping: &vjailbreakv1alpha1.NetworkMapping{
+			ObjectMeta: metav1.ObjectMeta{Name: "nwmap"},
+			Spec: vjailbreakv1alpha1.NetworkMappingSpec{Networks: []vjailbreakv1alpha1.Network{
+				{Source: "VM Network", Target: "provider"},
+			}},
+		},
+		StorageMapping: &vjailbreakv1alpha1.StorageMapping{
+			ObjectMeta: metav1.ObjectMeta{Name: "stmap"},
+			Spec: vjailbreakv1alpha1.StorageMappingSpec{Storages: []vjailbreakv1alpha1.Storage{
+				{Source: "ds1", Target: "ssd"},
+			}},
+		},
+		OpenstackCreds: &vjailbreakv1alpha1.OpenstackCreds{
+			Spec: vjailbreakv1alpha1.OpenstackCredsSpec{Flavors: []flavors.Flavor{
+				{ID: "1", Name: "m1.small", VCPUs: 1, RAM: 2048},
+				{ID: "2", Name: "m1.medium", VCPUs: 2, RAM: 4096},
+				{ID: "3", Name: "m1.large", VCPUs: 4, RAM: 8192},
+			}},
+		},
+	}
+}
+
+func findingChecks(assessment VMAssessment) map[vjailbreakv1alpha1.AssessmentCheck]vjailbreakv1alpha1.AssessmentSeverity {
+	checks := map[vjailbreakv1alpha1.AssessmentCheck]vjailbreakv1alpha1.AssessmentSeverity{}
+	for _, finding := range assessment.Findings {
+		checks[finding.Check] = finding.Severity
+	}
+	return checks
+}
+
+func TestAssessVM(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(*vjailbreakv1alpha1.VMwareMachine)
+		targets   func(*AssessmentTargets)
+		readiness vjailbreakv1alpha1.AssessmentReadiness
+		score     int
+		checks    map[vjailbreakv1alpha1.AssessmentCheck]vjailbreakv1alpha1.AssessmentSeverity
+	}{
+		{
+			name:      "ready",
+			readiness: vjailbreakv1alpha1.AssessmentReadinessReady,
+			score:     100,
+			checks:    map[vjailbreakv1alpha1.AssessmentCheck]vjailbreakv1alpha1.AssessmentSeverity{},
+		},
+		{
+			name: "info findings cost nothing",
+			mutate: func(vmwvm *vjailbreakv1alpha1.VMwareMachine) {
+				vmwvm.Spec.VMInfo.Hardware.Firmware = "efi"
+				vmwvm.Spec.VMInfo.Hardware.CBTEnabled = false
+			},
+			readiness: vjailbreakv1alpha1.AssessmentReadinessReady,
+			score:     100,
+			checks: map[vjailbreakv1alpha1.AssessmentCheck]vjailbreakv1alpha1.AssessmentSeverity{
+				vjailbreakv1alpha1.AssessmentCheckFirmware:             vjailbreakv1alpha1.AssessmentSeverityInfo,
+				vjailbreakv1alpha1.AssessmentCheckChangedBlockTracking: vjailbreakv1alpha1.AssessmentSeverityInfo,
+			},
+		},
+		{
+			name: "warnings",
+			mutate: func(vmwvm *vjailbreakv1alpha1.VMwareMachine) {
+				vm := &vmwvm.Spec.VMInfo
+				vm.GuestOS = "VMware Photon OS (64-bit)"
+				vm.RDMDisks = []string{"vml.0200"}
+				vm.Hardware = vjailbreakv1alpha1.VMHardware{
+					Firmware: "efi", SecureBoot: true, Version: 4, USBDevices: 1, SerialPorts: 2, SnapshotDepth: 3,
+				}
+			},
+			readiness: vjailbreakv1alpha1.AssessmentReadinessNeedsAttention,
+			score:     10,
+			checks: map[vjailbreakv1alpha1.AssessmentCheck]vjailbreakv1alpha1.AssessmentSeverity{
+				vjailbreakv1alpha1.AssessmentCheckOperatingSystem: vjailbreakv1alpha1.AssessmentSeverityWarning,
+				vjailbreakv1alpha1.AssessmentCheckFirmware:        vjailbreakv1alpha1.AssessmentSeverityWarning,
+				vjailbreakv1alpha1.AssessmentCheckRDMDisks:        vjailbreakv1alpha1.AssessmentSeverityWarning,
+				vjailbreakv1alpha1.AssessmentCheckUSBDevices:      vjailbreakv1alpha1.AssessmentSeverityWarning,
+				vjailbreakv1alpha1.AssessmentCheckSerialPorts:     vjailbreakv1alpha1.AssessmentSeverityWarning,
+				vjailbreakv1alpha1.AssessmentCheckSnapshots:       vjailbreakv1alpha1.AssessmentSeverityWarning,
+				vjailbreakv1alpha1.AssessmentCheckHardwareVersion: vjailbreakv1alpha1.AssessmentSeverityWarning,
+			},
+		},
+		{
+			name: "blocked",
+			mutate: func(vmwvm *vjailbreakv1alpha1.VMwareMachine) {
+				vm := &vmwvm.Spec.VMInfo
+				vm.OSFamily = "solarisGuest"
+				vm.GuestOS = "Oracle Solaris 11 (64-bit)"
+				vm.CPU = 8
+				vm.Networks = append(vm.Networks, "DMZ", "DMZ")
+				vm.Disks = append(vm.Disks, vjailbreakv1alpha1.Disk{Name: "Hard disk 2", Datastore: "ds2"})
+				vm.Hardware.IndependentDisks = []string{"Hard disk 2"}
+				vm.Hardware.SnapshotDepth = 32
+			},
+			readiness: vjailbreakv1alpha1.AssessmentReadinessBlocked,
+			score:     0,
+			checks: map[vjailbreakv1alpha1.AssessmentCheck]vjailbreakv1alpha1.AssessmentSeverity{
+				vjailbreakv1alpha1.AssessmentCheckOperatingSystem:  vjailbreakv1alpha1.AssessmentSeverityBlocking,
+				vjailbreakv1alpha1.AssessmentCheckIndependentDisks: vjailbreakv1alpha1.AssessmentSeverityBlocking,
+				vjailbreakv1alpha1.AssessmentCheckSnapshots:        vjailbreakv1alpha1.AssessmentSeverityBlocking,
+				vjailbreakv1alpha1.AssessmentCheckNetworkMapping:   vjailbreakv1alpha1.AssessmentSeverityBlocking,
+				vjailbreakv1alpha1.AssessmentCheckStorageMapping:   vjailbreakv1alpha1.AssessmentSeverityBlocking,
+				vjailbreakv1alpha1.AssessmentCheckFlavor:           vjailbreakv1alpha1.AssessmentSeverityBlocking,
+			},
+		},
+		{
+			name: "no disks and a missing target flavor",
+			mutate: func(vmwvm *vjailbreakv1alpha1.VMwareMachine) {
+				vmwvm.Spec.VMInfo.Disks = nil
+				vmwvm.Spec.TargetFlavorID = "42"
+			},
+			readiness: vjailbreakv1alpha1.AssessmentReadinessBlocked,
+			checks: map[vjailbreakv1alpha1.AssessmentCheck]vjailbreakv1alpha1.AssessmentSeverity{
+				vjailbreakv1alpha1.AssessmentCheckDisks:  vjailbreakv1alpha1.AssessmentSeverityBlocking,
+				vjailbreakv1alpha1.AssessmentCheckFlavor: vjailbreakv1alpha1.AssessmentSeverityBlocking,
+			},
+		},
+		{
+			name: "checks without targets are left out",
+			mutate: func(vmwvm *vjailbreakv1alpha1.VMwareMachine) {
+				vmwvm.Spec.VMInfo.Networks = []string{"DMZ"}
+				vmwvm.Spec.VMInfo.CPU = 64
+			},
+			targets: func(targets *AssessmentTargets) {
+				*targets = AssessmentTargets{}
+			},
+			readiness: vjailbreakv1alpha1.AssessmentReadinessReady,
+			score:     100,
+			checks:    map[vjailbreakv1alpha1.AssessmentCheck]vjailbreakv1alpha1.AssessmentSeverity{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets := assessmentTestTargets()
+			if tt.targets != nil {
+				tt.targets(&targets)
+			}
+			got := AssessVM(assessmentTestMachine(tt.mutate), targets)
+			if got.Readiness != tt.readiness || got.Score != tt.score {
+				t.Errorf("AssessVM() = %s %d, want %s %d: %+v", got.Readiness, got.Score, tt.readiness, tt.score, got.Findings)
+			}
+			if checks := findingChecks(got); !reflect.DeepEqual(checks, tt.checks) {
+				t.Errorf("AssessVM() findings = %v, want %v", checks, tt.checks)
+			}
+		})
+	}
+}
+
+func TestAssessVMFlavor(t *testing.T) {
+	got := AssessVM(assessmentTestMachine(nil), assessmentTestTargets())
+	if got.Flavor != "m1.medium" {
+		t.Errorf("AssessVM() flavor = %q, want m1.medium", got.Flavor)
+	}
+
+	got = AssessVM(assessmentTestMachine(func(vmwvm *vjailbreakv1alpha1.VMwareMachine) {
+		vmwvm.Spec.TargetFlavorID = "1"
+	}), assessmentTestTargets())
+	if got.Flavor != "m1.small" || findingChecks(got)[vjailbreakv1alpha1.AssessmentCheckFlavor] != vjailbreakv1alpha1.AssessmentSeverityWarning {
+		t.Errorf("AssessVM() with an undersized target flavor = %q %+v", got.Flavor, got.Findings)
+	}
+
+	got = AssessVM(assessmentTestMachine(func(vmwvm *vjailbreakv1alpha1.VMwareMachine) {
+		vmwvm.Spec.VMInfo.Networks = []string{"VM Network", "DMZ", "Backup", "DMZ"}
+	}), assessmentTestTargets())
+	if len(got.Findings) != 1 || !strings.Contains(got.Findings[0].Message, "Networks DMZ, Backup are not in NetworkMapping nwmap") {
+		t.Errorf("AssessVM() network findings = %+v", got.Findings)
+	}
+}
+
+func TestAssessOSGuestNames(t *testing.T) {
+	for _, guest := range []string{
+		"Red Hat Enterprise Linux 9 (64-bit)", "CentOS 7 (64-bit)", "SUSE Linux Enterprise 15 (64-bit)",
+		"AlmaLinux (64-bit)", "Rocky Linux (64-bit)", "Debian GNU/Linux 12 (64-bit)", "Oracle Linux 8 (64-bit)",
+	} {
+		vmwvm := assessmentTestMachine(func(vmwvm *vjailbreakv1alpha1.VMwareMachine) {
+			vmwvm.Spec.VMInfo.GuestOS = guest
+			vmwvm.Spec.VMInfo.OSFamily = ""
+		})
+		if got := AssessVM(vmwvm, AssessmentTargets{}); len(got.Findings) != 0 {
+			t.Errorf("AssessVM() for %s = %+v, want no findings", guest, got.Findings)
+		}
+	}
+}
+
+func TestSummarizeAssessments(t *testing.T) {
+	assessments := []VMAssessment{
+		{Name: "a", Readiness: vjailbreakv1alpha1.AssessmentReadinessReady, Score: 100},
+		{Name: "b", Readiness: vjailbreakv1alpha1.AssessmentReadinessNeedsAttention, Score: 70, Findings: []AssessmentFinding{
+			{Check: vjailbreakv1alpha1.AssessmentCheckUSBDevices, Severity: vjailbreakv1alpha1.AssessmentSeverityWarning},
+			{Check: vjailbreakv1alpha1.AssessmentCheckSnapshots, Severity: vjailbreakv1alpha1.AssessmentSeverityWarning},
+		}},
+		{Name: "c", Readiness: vjailbreakv1alpha1.AssessmentReadinessBlocked, Score: 0, Findings: []AssessmentFinding{
+			{Check: vjailbreakv1alpha1.AssessmentCheckSnapshots, Severity: vjailbreakv1alpha1.AssessmentSeverityWarning},
+			{Check: vjailbreakv1alpha1.AssessmentCheckFlavor, Severity: vjailbreakv1alpha1.AssessmentSeverityBlocking},
+			{Check: vjailbreakv1alpha1.AssessmentCheckFlavor, Severity: vjailbreakv1alpha1.AssessmentSeverityBlocking},
+		}},
+	}
+	summary, issues := SummarizeAssessments(assessments)
+	expectedSummary := vjailbreakv1alpha1.AssessmentSummary{TotalVMs: 3, Ready: 1, NeedsAttention: 1, Blocked: 1, AverageScore: 56}
+	if summary != expectedSummary {
+		t.Errorf("SummarizeAssessments() summary = %+v, want %+v", summary, expectedSummary)
+	}
+	expectedIssues := []vjailbreakv1alpha1.AssessmentIssueCount{
+		{Check: vjailbreakv1alpha1.AssessmentCheckFlavor, Severity: vjailbreakv1alpha1.AssessmentSeverityBlocking, VMs: 1},
+		{Check: vjailbreakv1alpha1.AssessmentCheckSnapshots, Severity: vjailbreakv1alpha1.AssessmentSeverityWarning, VMs: 2},
+		{Check: vjailbreakv1alpha1.AssessmentCheckUSBDevices, Severity: vjailbreakv1alpha1.AssessmentSeverityWarning, VMs: 1},
+	}
+	if !reflect.DeepEqual(issues, expectedIssues) {
+		t.Errorf("SummarizeAssessments() issues = %+v, want %+v", issues, expectedIssues)
+	}
+}
+
+func TestAssessmentReports(t *testing.T) {
+	assessments := []VMAssessment{
+		AssessVM(assessmentTestMachine(nil), assessmentTestTargets()),
+		AssessVM(assessmentTestMachine(func(vmwvm *vjailbreakv1alpha1.VMwareMachine) {
+			vmwvm.Spec.VMInfo.Name = "db, primary"
+			vmwvm.Spec.VMInfo.Hardware.USBDevices = 1
+			vmwvm.Spec.VMInfo.Hardware.IndependentDisks = []string{"Hard disk 1"}
+		}), assessmentTestTargets()),
+	}
+
+	reportJSON, err := AssessmentReportJSON(assessments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []VMAssessment
+	if err := json.Unmarshal([]byte(reportJSON), &decoded); err != nil {
+		t.Fatalf("report.json does not decode: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, assessments) {
+		t.Errorf("report.json = %s", reportJSON)
+	}
+
+	reportCSV, err := AssessmentReportCSV(assessments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(reportCSV)).ReadAll()
+	if err != nil {
+		t.Fatalf("report.csv does not parse: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("report.csv has %d rows, want a header and 2 VMs", len(rows))
+	}
+	expected := []string{"db, primary", "web-01-vm-101-vmware", "linuxGuest", "Ubuntu Linux (64-bit)", "Blocked", "0", "m1.medium",
+		"IndependentDisks: VDDK cannot read disks in independent mode: Hard disk 1. Switch them to dependent mode.",
+		"USBDevices: 1 USB controller(s) or device(s) are not migrated", ""}
+	if !reflect.DeepEqual(rows[2], expected) {
+		t.Errorf("report.csv row = %q, want %q", rows[2], expected)
+	}
+
+	if reportJSON, _ := AssessmentReportJSON(nil); reportJSON != "[]" {
+		t.Errorf("AssessmentReportJSON(nil) = %s, want []", reportJSON)
+	}
+}
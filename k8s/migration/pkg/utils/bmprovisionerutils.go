@@ -27,8 +27,12 @@ import (
 
 	// Import for side effects - registers the base provider implementation
 	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers/base"
+	// Import for side effects - registers the ironic provider implementation
+	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers/ironic"
 	// Import for side effects - registers the maas provider implementation
 	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers/maas"
+	// Import for side effects - registers the redfish provider implementation
+	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers/redfish"
 	"gopkg.in/yaml.v3"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -198,6 +202,8 @@ func ReclaimESXi(ctx context.Context, scope *scope.ESXIMigrationScope, bmProvide
 		AccessInfo: &service.BMProvisionerAccessInfo{
 			BaseUrl:     bmConfig.Spec.APIUrl,
 			ApiKey:      bmConfig.Spec.APIKey,
+			Username:    bmConfig.Spec.UserName,
+			Password:    bmConfig.Spec.Password,
 			UseInsecure: bmConfig.Spec.Insecure,
 		},
 		UserData:           cloudInit,
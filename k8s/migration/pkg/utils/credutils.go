@@ -682,7 +682,10 @@ func GetAndCreateAllVMs(ctx context.Context, scope *scope.VMwareCredsScope, data
 	// then preserved instead of being wiped.
 	var vmTagsByRef map[string]map[string]string
 	vmwareCredsInfo, err := GetVMwareCredentialsFromSecret(ctx, scope.Client, scope.VMwareCreds.Spec.SecretRef.Name)
-	if err != nil {
+	if scope.VMwareCreds.IsStandaloneESXi() {
+		// The tagging service is part of vCenter, a standalone ESXi host has no tags
+		log.Info("Standalone ESXi host, skipping tag discovery")
+	} else if err != nil {
 		log.Error(err, "failed to get vCenter credentials for tag discovery, skipping tags")
 	} else {
 		vmRefs := make([]types.ManagedObjectReference, 0, len(allVMs))
@@ -2079,6 +2082,7 @@ func processSingleVM(ctx context.Context, scope *scope.VMwareCredsScope, vm *obj
 		IPAddress:         vmProps.Guest.IpAddress,
 		VMState:           vmProps.Guest.GuestState,
 		OSFamily:          osFamily,
+		GuestOS:           vmProps.Config.GuestFullName,
 		CPU:               int(vmProps.Config.Hardware.NumCPU),
 		Memory:            int(vmProps.Config.Hardware.MemoryMB),
 		ESXiName:          host.Name,
@@ -2087,6 +2091,7 @@ func processSingleVM(ctx context.Context, scope *scope.VMwareCredsScope, vm *obj
 		NetworkInterfaces: nicList,
 		GuestNetworks:     guestNetworks,
 		GPU:               gpuInfo,
+		Hardware:          ExtractVMHardware(&vmProps),
 		Tags:              vmTags,
 		CustomAttributes:  ExtractCustomAttributes(&vmProps),
 	}
@@ -2346,7 +2351,7 @@ func getCinderVolumeServiceHosts(ctx context.Context, cinderClient *gophercloud.
 }
 
 // GetArrayVendor normalizes and returns the storage array vendor name from a vendor string
-// Supports Pure Storage and NetApp arrays (issue #1421)
+// Supports Pure Storage, NetApp, Dell PowerStore and HPE Alletra/Primera/3PAR arrays (issue #1421)
 func GetArrayVendor(vendor string) string {
 	// Convert vendor to lowercase
 	vendor = strings.ToLower(vendor)
@@ -2357,6 +2362,16 @@ func GetArrayVendor(vendor string) string {
 	if strings.Contains(vendor, "netapp") {
 		return "netapp"
 	}
+	if strings.Contains(vendor, "powerstore") {
+		return "powerstore"
+	}
+	// The Cinder HPE 3PAR driver, which also serves Primera and Alletra 9000,
+	// reports "Hewlett Packard Enterprise"
+	for _, name := range []string{"hewlett packard enterprise", "hpe", "3par", "primera", "alletra"} {
+		if strings.Contains(vendor, name) {
+			return "hpalletra"
+		}
+	}
 	return "unsupported"
 }
 
@@ -0,0 +1,68 @@
+// Package utils provides utility functions for handling KubeVirt destinations
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var networkAttachmentDefinitionGVK = schema.GroupVersionKind{
+	Group:   "k8s.cni.cncf.io",
+	Version: "v1",
+	Kind:    "NetworkAttachmentDefinition",
+}
+
+// VerifyKubevirtDestination verifies that the destination namespace of a KubeVirt
+// MigrationTemplate exists
+func VerifyKubevirtDestination(ctx context.Context, k3sclient client.Client, destination vjailbreakv1alpha1.MigrationTemplateDestination) error {
+	if destination.Kubevirt == nil {
+		return errors.New("destination type is kubevirt but spec.destination.kubevirt is not set")
+	}
+	ns := &corev1.Namespace{}
+	if err := k3sclient.Get(ctx, types.NamespacedName{Name: destination.Kubevirt.Namespace}, ns); err != nil {
+		return errors.Wrapf(err, "failed to get KubeVirt destination namespace '%s'", destination.Kubevirt.Namespace)
+	}
+	return nil
+}
+
+// VerifyKubevirtNetworks verifies that each target network is the pod network or a
+// NetworkAttachmentDefinition, named as name in the destination namespace or namespace/name
+func VerifyKubevirtNetworks(ctx context.Context, k3sclient client.Client, namespace string, targetnetworks []string) error {
+	for _, targetNetwork := range targetnetworks {
+		if targetNetwork == constants.KubevirtPodNetwork {
+			continue
+		}
+		nadNamespace, nadName := namespace, targetNetwork
+		if parts := strings.Split(targetNetwork, "/"); len(parts) == 2 {
+			nadNamespace, nadName = parts[0], parts[1]
+		}
+		nad := &unstructured.Unstructured{}
+		nad.SetGroupVersionKind(networkAttachmentDefinitionGVK)
+		if err := k3sclient.Get(ctx, types.NamespacedName{Name: nadName, Namespace: nadNamespace}, nad); err != nil {
+			return errors.Wrap(fmt.Errorf("network '%s' not found as a NetworkAttachmentDefinition: %w", targetNetwork, err), "failed to verify networks")
+		}
+	}
+	return nil
+}
+
+// VerifyKubevirtStorage verifies the existence of the target StorageClasses
+func VerifyKubevirtStorage(ctx context.Context, k3sclient client.Client, targetstorages []string) error {
+	for _, targetstorage := range targetstorages {
+		sc := &storagev1.StorageClass{}
+		if err := k3sclient.Get(ctx, types.NamespacedName{Name: targetstorage}, sc); err != nil {
+			return errors.Wrap(fmt.Errorf("StorageClass '%s' not found: %w", targetstorage, err), "failed to verify storage classes")
+		}
+	}
+	return nil
+}
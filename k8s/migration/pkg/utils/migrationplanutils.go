@@ -68,3 +68,14 @@ func GetJobNameForVMName(vmname string, credName string) (string, error) {
 	}
 	return fmt.Sprintf("v2v-helper-%s-%s", vmk8sname[:min(len(vmk8sname), constants.MaxJobNameLength)], commonutils.GenerateSha256Hash(vmname)[:constants.HashSuffixLength]), nil
 }
+
+// GetPromoteJobNameForVMName generates the name of the job that creates the instance
+// of a data-only migration from its staged volumes
+func GetPromoteJobNameForVMName(vmname string, credName string) (string, error) {
+	vmk8sname, err := commonutils.GetK8sCompatibleVMWareObjectName(vmname, credName)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to convert vm name to k8s name")
+	}
+	// v2v-promote- is one character longer than v2v-helper-
+	return fmt.Sprintf("v2v-promote-%s-%s", vmk8sname[:min(len(vmk8sname), constants.MaxJobNameLength-1)], commonutils.GenerateSha256Hash(vmname)[:constants.HashSuffixLength]), nil
+}
@@ -6,6 +6,7 @@
 package utils
 
 import (
+	"fmt"
 	"slices"
 	"sort"
 	"strings"
@@ -190,6 +191,45 @@ func CreateCutoverTriggeredCondition(migration *vjailbreakv1alpha1.Migration, ev
 		"Admin cutover triggered")
 }
 
+// CreateReadyForCutoverCondition creates a condition telling whether the predicted downtime of
+// cutting over a warm migration now fits the downtime target, from the cutover readiness the
+// migration pod reports after each changed-block sync. It is dropped while there is none.
+func CreateReadyForCutoverCondition(migration *vjailbreakv1alpha1.Migration) []corev1.PodCondition {
+	existingConditions := migration.Status.Conditions
+	idx := GetConditonIndex(existingConditions, constants.MigrationConditionTypeReadyForCutover,
+		constants.MigrationReasonDowntimeFits, constants.MigrationReasonDowntimeTooLong)
+	readiness := migration.Status.CutoverReadiness
+	if readiness == nil {
+		if idx != -1 {
+			existingConditions = slices.Delete(existingConditions, idx, idx+1)
+		}
+		return existingConditions
+	}
+
+	status, reason := corev1.ConditionFalse, constants.MigrationReasonDowntimeTooLong
+	if readiness.Ready {
+		status, reason = corev1.ConditionTrue, constants.MigrationReasonDowntimeFits
+	}
+	// The transition time only moves when the readiness changes
+	timestamp := readiness.UpdatedAt
+	if idx != -1 && existingConditions[idx].Status == status {
+		timestamp = existingConditions[idx].LastTransitionTime
+	}
+	statuscondition := GeneratePodCondition(constants.MigrationConditionTypeReadyForCutover,
+		status,
+		reason,
+		fmt.Sprintf("Predicted cutover downtime %s, target %s, after %d changed-block syncs",
+			readiness.PredictedDowntime.Duration, readiness.DowntimeTarget.Duration, readiness.Syncs),
+		timestamp)
+
+	if idx == -1 {
+		existingConditions = append(existingConditions, *statuscondition)
+	} else {
+		existingConditions[idx] = *statuscondition
+	}
+	return existingConditions
+}
+
 // isFailureEventMessage reports whether an event message represents a genuine terminal
 // migration failure, matched case-insensitively. Warning messages are excluded since they
 // don't represent a real failure.
@@ -550,6 +550,57 @@ func TestCreateCutoverTriggeredCondition(t *testing.T) {
 	}
 }
 
+func TestCreateReadyForCutoverCondition(t *testing.T) {
+	migration := makeMigration()
+	if got := CreateReadyForCutoverCondition(migration); len(got) != 0 {
+		t.Fatalf("got %d conditions without a cutover readiness, want 0", len(got))
+	}
+
+	firstUpdate := metav1.NewTime(time.Now().Add(-time.Hour))
+	migration.Status.CutoverReadiness = &vjailbreakv1alpha1.CutoverReadiness{
+		PredictedDowntime: metav1.Duration{Duration: 12 * time.Minute},
+		DowntimeTarget:    metav1.Duration{Duration: 5 * time.Minute},
+		Syncs:             2,
+		UpdatedAt:         firstUpdate,
+	}
+	migration.Status.Conditions = CreateReadyForCutoverCondition(migration)
+	if len(migration.Status.Conditions) != 1 {
+		t.Fatalf("got %d conditions, want 1", len(migration.Status.Conditions))
+	}
+	c := migration.Status.Conditions[0]
+	if c.Type != constants.MigrationConditionTypeReadyForCutover || c.Status != corev1.ConditionFalse ||
+		c.Reason != constants.MigrationReasonDowntimeTooLong {
+		t.Errorf("condition = %s %s %s, want ReadyForCutover False DowntimeTooLong", c.Type, c.Status, c.Reason)
+	}
+	if want := "Predicted cutover downtime 12m0s, target 5m0s, after 2 changed-block syncs"; c.Message != want {
+		t.Errorf("message = %q, want %q", c.Message, want)
+	}
+
+	// Still not ready: the transition time stays
+	migration.Status.CutoverReadiness.PredictedDowntime.Duration = 8 * time.Minute
+	migration.Status.CutoverReadiness.UpdatedAt = metav1.Now()
+	migration.Status.Conditions = CreateReadyForCutoverCondition(migration)
+	if c := migration.Status.Conditions[0]; !c.LastTransitionTime.Equal(&firstUpdate) {
+		t.Errorf("LastTransitionTime = %v, want %v", c.LastTransitionTime, firstUpdate)
+	}
+
+	// Ready replaces the condition
+	migration.Status.CutoverReadiness.Ready = true
+	migration.Status.Conditions = CreateReadyForCutoverCondition(migration)
+	if len(migration.Status.Conditions) != 1 {
+		t.Fatalf("got %d conditions, want 1", len(migration.Status.Conditions))
+	}
+	if c := migration.Status.Conditions[0]; c.Status != corev1.ConditionTrue || c.Reason != constants.MigrationReasonDowntimeFits ||
+		!c.LastTransitionTime.Equal(&migration.Status.CutoverReadiness.UpdatedAt) {
+		t.Errorf("condition = %s %s at %v, want True DowntimeFits at the update", c.Status, c.Reason, c.LastTransitionTime)
+	}
+
+	migration.Status.CutoverReadiness = nil
+	if got := CreateReadyForCutoverCondition(migration); len(got) != 0 {
+		t.Errorf("got %d conditions after the cutover readiness is gone, want 0", len(got))
+	}
+}
+
 func TestCleanFailureMessage(t *testing.T) {
 	tests := []struct {
 		name     string
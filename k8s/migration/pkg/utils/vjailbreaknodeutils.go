@@ -986,6 +986,7 @@ func GetActiveMigrations(ctx context.Context, nodeName string, k3sclient client.
 	}
 
 	ignorePhases := []vjailbreakv1alpha1.VMMigrationPhase{vjailbreakv1alpha1.VMMigrationPhasePending,
+		vjailbreakv1alpha1.VMMigrationPhaseQueued,
 		vjailbreakv1alpha1.VMMigrationPhaseFailed,
 		vjailbreakv1alpha1.VMMigrationPhaseSucceeded,
 		vjailbreakv1alpha1.VMMigrationPhaseUnknown,
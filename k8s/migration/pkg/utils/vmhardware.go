@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ExtractVMHardware reads the virtual hardware that decides how a VM can be
+// migrated from its config property
+func ExtractVMHardware(vmProps *mo.VirtualMachine) vjailbreakv1alpha1.VMHardware {
+	hardware := vjailbreakv1alpha1.VMHardware{}
+	if vmProps == nil || vmProps.Config == nil {
+		return hardware
+	}
+	config := vmProps.Config
+	hardware.Firmware = config.Firmware
+	if config.BootOptions != nil && config.BootOptions.EfiSecureBootEnabled != nil {
+		hardware.SecureBoot = *config.BootOptions.EfiSecureBootEnabled
+	}
+	hardware.Version = parseHardwareVersion(config.Version)
+	if config.ChangeTrackingEnabled != nil {
+		hardware.CBTEnabled = *config.ChangeTrackingEnabled
+	}
+
+	for _, device := range config.Hardware.Device {
+		switch d := device.(type) {
+		case *types.VirtualUSBController, *types.VirtualUSBXHCIController, *types.VirtualUSB:
+			hardware.USBDevices++
+		case *types.VirtualSerialPort:
+			hardware.SerialPorts++
+		case *types.VirtualDisk:
+			if isIndependentDisk(d) {
+				hardware.IndependentDisks = append(hardware.IndependentDisks, d.DeviceInfo.GetDescription().Label)
+			}
+			hardware.SnapshotDepth = max(hardware.SnapshotDepth, diskSnapshotDepth(d))
+		}
+	}
+	return hardware
+}
+
+// parseHardwareVersion returns the number of a virtual hardware version such as
+// vmx-19, or 0 when it cannot be read
+func parseHardwareVersion(version string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(version, "vmx-"))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// isIndependentDisk tells whether a copied disk is in independent mode. RDM
+// disks are not copied and are left out.
+func isIndependentDisk(disk *types.VirtualDisk) bool {
+	var mode string
+	switch backing := disk.Backing.(type) {
+	case *types.VirtualDiskFlatVer2BackingInfo:
+		mode = backing.DiskMode
+	case *types.VirtualDiskSparseVer2BackingInfo:
+		mode = backing.DiskMode
+	}
+	return mode == string(types.VirtualDiskModeIndependent_persistent) ||
+		mode == string(types.VirtualDiskModeIndependent_nonpersistent)
+}
+
+// diskSnapshotDepth returns how many snapshot delta disks the disk runs on,
+// the length of the parent chain of its backing
+func diskSnapshotDepth(disk *types.VirtualDisk) int {
+	depth := 0
+	switch backing := disk.Backing.(type) {
+	case *types.VirtualDiskFlatVer2BackingInfo:
+		for parent := backing.Parent; parent != nil; parent = parent.Parent {
+			depth++
+		}
+	case *types.VirtualDiskSparseVer2BackingInfo:
+		for parent := backing.Parent; parent != nil; parent = parent.Parent {
+			depth++
+		}
+	}
+	return depth
+}
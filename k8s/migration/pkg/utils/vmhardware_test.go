@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func hardwareTestDisk(label, mode string) *types.VirtualDisk {
+	return &types.VirtualDisk{
+		VirtualDevice: types.VirtualDevice{
+			DeviceInfo: &types.Description{Label: label},
+			Backing: &types.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: "[ds1] vm/" + label + ".vmdk"},
+				DiskMode:                     mode,
+			},
+		},
+	}
+}
+
+func TestExtractVMHardware(t *testing.T) {
+	secureBoot := true
+	cbt := true
+	// Hard disk 1 runs on three snapshot deltas, the base disk at the bottom
+	snapshotted := hardwareTestDisk("Hard disk 1", string(types.VirtualDiskModePersistent))
+	backing := snapshotted.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+	for i := 0; i < 3; i++ {
+		parent := *backing
+		backing.Parent = &parent
+	}
+
+	vmProps := &mo.VirtualMachine{
+		Config: &types.VirtualMachineConfigInfo{
+			Version:               "vmx-19",
+			Firmware:              "efi",
+			BootOptions:           &types.VirtualMachineBootOptions{EfiSecureBootEnabled: &secureBoot},
+			ChangeTrackingEnabled: &cbt,
+			Hardware: types.VirtualHardware{
+				Device: []types.BaseVirtualDevice{
+					snapshotted,
+					hardwareTestDisk("Hard disk 2", string(types.VirtualDiskModeIndependent_persistent)),
+					&types.VirtualUSBXHCIController{},
+					&types.VirtualUSB{},
+					&types.VirtualSerialPort{},
+				},
+			},
+		},
+	}
+
+	got := ExtractVMHardware(vmProps)
+	expected := vjailbreakv1alpha1.VMHardware{
+		Firmware:         "efi",
+		SecureBoot:       true,
+		Version:          19,
+		CBTEnabled:       true,
+		SnapshotDepth:    3,
+		USBDevices:       2,
+		SerialPorts:      1,
+		IndependentDisks: []string{"Hard disk 2"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ExtractVMHardware() = %+v, want %+v", got, expected)
+	}
+
+	if got := ExtractVMHardware(&mo.VirtualMachine{}); !reflect.DeepEqual(got, vjailbreakv1alpha1.VMHardware{}) {
+		t.Errorf("ExtractVMHardware() without config = %+v, want empty", got)
+	}
+}
+
+func TestParseHardwareVersion(t *testing.T) {
+	tests := map[string]int{"vmx-19": 19, "vmx-07": 7, "": 0, "vmx-": 0, "19": 19}
+	for version, expected := range tests {
+		if got := parseHardwareVersion(version); got != expected {
+			t.Errorf("parseHardwareVersion(%q) = %d, want %d", version, got, expected)
+		}
+	}
+}
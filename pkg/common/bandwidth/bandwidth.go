@@ -0,0 +1,158 @@
+// Package bandwidth parses and evaluates the disk copy bandwidth caps set on a
+// MigrationPlan and in the vjailbreak settings ConfigMap.
+//
+// A cap is a rate in megabits per second, where 0 means unlimited, plus an
+// optional list of daily windows that override it, written as
+//
+//	08:00-18:00=200,22:00-06:00=0
+//
+// A window whose end is before its start wraps past midnight. When windows
+// overlap the first one listed wins.
+package bandwidth
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is a daily time-of-day range with its own rate.
+type Window struct {
+	// Start and End are minutes after midnight, End is exclusive
+	Start int
+	End   int
+	// RateMbps is the cap inside the window, 0 is unlimited
+	RateMbps int
+}
+
+// Schedule is a bandwidth cap that can vary with the time of day.
+type Schedule struct {
+	// RateMbps applies outside every window, 0 is unlimited
+	RateMbps int
+	Windows  []Window
+}
+
+// ParseClock parses "HH:MM" into minutes after midnight.
+func ParseClock(clock string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(clock), ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 23 {
+		return 0, fmt.Errorf("invalid hour in %q", clock)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("invalid minute in %q", clock)
+	}
+	return hours*60 + minutes, nil
+}
+
+// NewWindow builds a Window from "HH:MM" start and end times.
+func NewWindow(start, end string, rateMbps int) (Window, error) {
+	startMinute, err := ParseClock(start)
+	if err != nil {
+		return Window{}, err
+	}
+	endMinute, err := ParseClock(end)
+	if err != nil {
+		return Window{}, err
+	}
+	if startMinute == endMinute {
+		return Window{}, fmt.Errorf("window %s-%s is empty, start and end must differ", start, end)
+	}
+	if rateMbps < 0 {
+		return Window{}, fmt.Errorf("window %s-%s has negative rate %d", start, end, rateMbps)
+	}
+	return Window{Start: startMinute, End: endMinute, RateMbps: rateMbps}, nil
+}
+
+// ParseWindows parses a comma separated list of "HH:MM-HH:MM=Mbps" windows.
+// An empty string yields no windows.
+func ParseWindows(spec string) ([]Window, error) {
+	var windows []Window
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		span, rate, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid bandwidth window %q, expected HH:MM-HH:MM=Mbps", entry)
+		}
+		start, end, found := strings.Cut(span, "-")
+		if !found {
+			return nil, fmt.Errorf("invalid bandwidth window %q, expected HH:MM-HH:MM=Mbps", entry)
+		}
+		rateMbps, err := strconv.Atoi(strings.TrimSpace(rate))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate in bandwidth window %q: %v", entry, err)
+		}
+		window, err := NewWindow(start, end, rateMbps)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// FormatWindows is the inverse of ParseWindows.
+func FormatWindows(windows []Window) string {
+	entries := make([]string, 0, len(windows))
+	for _, window := range windows {
+		entries = append(entries, fmt.Sprintf("%02d:%02d-%02d:%02d=%d",
+			window.Start/60, window.Start%60, window.End/60, window.End%60, window.RateMbps))
+	}
+	return strings.Join(entries, ",")
+}
+
+// contains reports whether minute of the day falls inside the window
+func (window Window) contains(minute int) bool {
+	if window.Start < window.End {
+		return minute >= window.Start && minute < window.End
+	}
+	return minute >= window.Start || minute < window.End
+}
+
+// RateAt returns the cap in Mbps in effect at the given time, 0 is unlimited.
+func (schedule Schedule) RateAt(now time.Time) int {
+	minute := now.Hour()*60 + now.Minute()
+	for _, window := range schedule.Windows {
+		if window.contains(minute) {
+			return window.RateMbps
+		}
+	}
+	return schedule.RateMbps
+}
+
+// IsUnlimited reports whether the schedule never caps anything.
+func (schedule Schedule) IsUnlimited() bool {
+	if schedule.RateMbps > 0 {
+		return false
+	}
+	for _, window := range schedule.Windows {
+		if window.RateMbps > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Min returns the tightest of the given caps, ignoring unlimited (0) ones.
+func Min(rates ...int) int {
+	tightest := 0
+	for _, rate := range rates {
+		if rate > 0 && (tightest == 0 || rate < tightest) {
+			tightest = rate
+		}
+	}
+	return tightest
+}
+
+// BytesPerSecond converts a rate in Mbps to bytes per second.
+func BytesPerSecond(rateMbps int) int64 {
+	return int64(rateMbps) * 1000 * 1000 / 8
+}
@@ -0,0 +1,106 @@
+package bandwidth
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseWindows(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    []Window
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "08:00-18:00=200", want: []Window{{Start: 480, End: 1080, RateMbps: 200}}},
+		{in: " 22:30-06:00=0 , 08:00-18:00=200", want: []Window{{Start: 1350, End: 360}, {Start: 480, End: 1080, RateMbps: 200}}},
+		{in: "08:00-18:00", wantErr: true},
+		{in: "08:00=200", wantErr: true},
+		{in: "8:00-18:00=200", wantErr: true},
+		{in: "08:00-24:00=200", wantErr: true},
+		{in: "08:00-08:00=200", wantErr: true},
+		{in: "08:00-18:00=-1", wantErr: true},
+		{in: "08:00-18:00=fast", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := ParseWindows(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseWindows(%q) = %v, want error", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseWindows(%q) error: %v", tc.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseWindows(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+		if formatted := FormatWindows(got); len(got) > 0 {
+			if back, err := ParseWindows(formatted); err != nil || !reflect.DeepEqual(back, got) {
+				t.Errorf("FormatWindows round trip of %q gave %q", tc.in, formatted)
+			}
+		}
+	}
+}
+
+func TestScheduleRateAt(t *testing.T) {
+	// full speed at night, 200 Mbps during working hours, 500 Mbps otherwise
+	schedule := Schedule{
+		RateMbps: 500,
+		Windows: []Window{
+			{Start: 22 * 60, End: 6 * 60, RateMbps: 0},
+			{Start: 8 * 60, End: 18 * 60, RateMbps: 200},
+		},
+	}
+	cases := []struct {
+		clock string
+		want  int
+	}{
+		{"23:15", 0},
+		{"00:00", 0},
+		{"05:59", 0},
+		{"06:00", 500},
+		{"08:00", 200},
+		{"17:59", 200},
+		{"18:00", 500},
+		{"22:00", 0},
+	}
+	for _, tc := range cases {
+		at, err := time.Parse("15:04", tc.clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := schedule.RateAt(at); got != tc.want {
+			t.Errorf("RateAt(%s) = %d, want %d", tc.clock, got, tc.want)
+		}
+	}
+	if schedule.IsUnlimited() {
+		t.Error("IsUnlimited() = true for a capped schedule")
+	}
+	if !(Schedule{Windows: []Window{{Start: 0, End: 60}}}).IsUnlimited() {
+		t.Error("IsUnlimited() = false for an all-zero schedule")
+	}
+}
+
+func TestMin(t *testing.T) {
+	cases := []struct {
+		rates []int
+		want  int
+	}{
+		{nil, 0},
+		{[]int{0, 0}, 0},
+		{[]int{0, 300}, 300},
+		{[]int{500, 0, 200}, 200},
+	}
+	for _, tc := range cases {
+		if got := Min(tc.rates...); got != tc.want {
+			t.Errorf("Min(%v) = %d, want %d", tc.rates, got, tc.want)
+		}
+	}
+	if got := BytesPerSecond(200); got != 25_000_000 {
+		t.Errorf("BytesPerSecond(200) = %d, want 25000000", got)
+	}
+}
@@ -40,6 +40,13 @@ type VjailbreakSettings struct {
 	V2VHelperPodEphemeralStorageRequest string
 	V2VHelperPodEphemeralStorageLimit   string
 	HTTPTimeoutSeconds                  int
+	GlobalBandwidthLimitMbps            int
+	GlobalBandwidthSchedule             string
+	NFCConnectionsPerESXiHost           int
+	MaxMigrationsPerESXiHost            int
+	MaxMigrationsPerDatastore           int
+	MaxMigrationsPerAgent               int
+	CutoverDowntimeTarget               string
 }
 
 // Atoi is a helper function to convert string to int with a default value of 0
@@ -87,6 +94,11 @@ func GetVjailbreakSettings(ctx context.Context, k8sClient client.Client) (*Vjail
 			V2VHelperPodEphemeralStorageRequest: constants.V2VHelperPodEphemeralStorageRequest,
 			V2VHelperPodEphemeralStorageLimit:   constants.V2VHelperPodEphemeralStorageLimit,
 			HTTPTimeoutSeconds:                  constants.HTTPTimeoutSeconds,
+			NFCConnectionsPerESXiHost:           constants.NFCConnectionsPerESXiHost,
+			MaxMigrationsPerESXiHost:            constants.MaxMigrationsPerESXiHost,
+			MaxMigrationsPerDatastore:           constants.MaxMigrationsPerDatastore,
+			MaxMigrationsPerAgent:               constants.MaxMigrationsPerAgent,
+			CutoverDowntimeTarget:               constants.CutoverDowntimeTarget,
 		}, nil
 	}
 
@@ -189,6 +201,26 @@ func GetVjailbreakSettings(ctx context.Context, k8sClient client.Client) (*Vjail
 		vjailbreakSettingsCM.Data[constants.HTTPTimeoutSecondsKey] = strconv.Itoa(constants.HTTPTimeoutSeconds)
 	}
 
+	if vjailbreakSettingsCM.Data[constants.NFCConnectionsPerESXiHostKey] == "" {
+		vjailbreakSettingsCM.Data[constants.NFCConnectionsPerESXiHostKey] = strconv.Itoa(constants.NFCConnectionsPerESXiHost)
+	}
+
+	if vjailbreakSettingsCM.Data[constants.MaxMigrationsPerESXiHostKey] == "" {
+		vjailbreakSettingsCM.Data[constants.MaxMigrationsPerESXiHostKey] = strconv.Itoa(constants.MaxMigrationsPerESXiHost)
+	}
+
+	if vjailbreakSettingsCM.Data[constants.MaxMigrationsPerDatastoreKey] == "" {
+		vjailbreakSettingsCM.Data[constants.MaxMigrationsPerDatastoreKey] = strconv.Itoa(constants.MaxMigrationsPerDatastore)
+	}
+
+	if vjailbreakSettingsCM.Data[constants.MaxMigrationsPerAgentKey] == "" {
+		vjailbreakSettingsCM.Data[constants.MaxMigrationsPerAgentKey] = strconv.Itoa(constants.MaxMigrationsPerAgent)
+	}
+
+	if vjailbreakSettingsCM.Data[constants.CutoverDowntimeTargetKey] == "" {
+		vjailbreakSettingsCM.Data[constants.CutoverDowntimeTargetKey] = constants.CutoverDowntimeTarget
+	}
+
 	return &VjailbreakSettings{
 		ChangedBlocksCopyIterationThreshold: Atoi(vjailbreakSettingsCM.Data["CHANGED_BLOCKS_COPY_ITERATION_THRESHOLD"]),
 		PeriodicSyncInterval:                vjailbreakSettingsCM.Data["PERIODIC_SYNC_INTERVAL"],
@@ -216,5 +248,12 @@ func GetVjailbreakSettings(ctx context.Context, k8sClient client.Client) (*Vjail
 		V2VHelperPodEphemeralStorageRequest: vjailbreakSettingsCM.Data[constants.V2VHelperPodEphemeralStorageRequestKey],
 		V2VHelperPodEphemeralStorageLimit:   vjailbreakSettingsCM.Data[constants.V2VHelperPodEphemeralStorageLimitKey],
 		HTTPTimeoutSeconds:                  Atoi(vjailbreakSettingsCM.Data[constants.HTTPTimeoutSecondsKey]),
+		GlobalBandwidthLimitMbps:            Atoi(vjailbreakSettingsCM.Data[constants.GlobalBandwidthLimitMbpsKey]),
+		GlobalBandwidthSchedule:             vjailbreakSettingsCM.Data[constants.GlobalBandwidthScheduleKey],
+		NFCConnectionsPerESXiHost:           Atoi(vjailbreakSettingsCM.Data[constants.NFCConnectionsPerESXiHostKey]),
+		MaxMigrationsPerESXiHost:            Atoi(vjailbreakSettingsCM.Data[constants.MaxMigrationsPerESXiHostKey]),
+		MaxMigrationsPerDatastore:           Atoi(vjailbreakSettingsCM.Data[constants.MaxMigrationsPerDatastoreKey]),
+		MaxMigrationsPerAgent:               Atoi(vjailbreakSettingsCM.Data[constants.MaxMigrationsPerAgentKey]),
+		CutoverDowntimeTarget:               vjailbreakSettingsCM.Data[constants.CutoverDowntimeTargetKey],
 	}, nil
 }
@@ -154,6 +154,12 @@ const (
 
 	// PostMigrationCompleteAnnotation is the annotation for tracking post-migration completion
 	PostMigrationCompleteAnnotation = "vjailbreak.k8s.pf9.io/post-migration-complete"
+	// MigrationAdmittedAnnotation records when the migration scheduler let a Migration
+	// start; until then its job is not created
+	MigrationAdmittedAnnotation = "vjailbreak.k8s.pf9.io/admitted"
+	// MigrationDatastoresAnnotation holds the JSON list of the datastores of the disks
+	// of a migrated VM, counted by the migration scheduler
+	MigrationDatastoresAnnotation = "vjailbreak.k8s.pf9.io/datastores"
 
 	// PauseMigrationLabel is the label for pausing rolling migration plan
 	PauseMigrationLabel = "vjailbreak.k8s.pf9.io/pause"
@@ -178,6 +184,8 @@ const (
 
 	// NumberOfDisksLabel is the label for number of disks
 	NumberOfDisksLabel = "vjailbreak.k8s.pf9.io/disk-count"
+	// ESXiHostLabel is the label for the ESXi host a migrated VM runs on
+	ESXiHostLabel = "vjailbreak.k8s.pf9.io/esxi-host"
 
 	// OpenstackCredsFinalizer is the finalizer for openstack credentials
 	OpenstackCredsFinalizer = "openstackcreds.k8s.pf9.io/finalizer" //nolint:gosec // not a password string
@@ -261,6 +269,9 @@ const (
 
 	// MigrationTriggerDelay is the delay for migration trigger
 	MigrationTriggerDelay = 5 * time.Second
+	// MigrationQueuedRequeueInterval is how often a plan with queued migrations
+	// checks whether they can start
+	MigrationQueuedRequeueInterval = 30 * time.Second
 
 	// MigrationReason is the reason for migration
 	MigrationReason = "Migration"
@@ -378,6 +389,38 @@ const (
 	// AutoPXEBootOnConversionKey is the key for enabling/disabling automatic PXE boot during cluster conversion
 	AutoPXEBootOnConversionKey = "AUTO_PXE_BOOT_ON_CONVERSION"
 
+	// GlobalBandwidthLimitMbpsKey caps the combined disk copy rate of all running
+	// migrations in Mbps, 0 is unlimited
+	GlobalBandwidthLimitMbpsKey = "GLOBAL_BANDWIDTH_LIMIT_MBPS"
+	// GlobalBandwidthScheduleKey holds daily windows overriding the global cap,
+	// e.g. "08:00-18:00=200,22:00-06:00=0"
+	GlobalBandwidthScheduleKey = "GLOBAL_BANDWIDTH_SCHEDULE"
+	// NFCConnectionsPerESXiHostKey is how many disks may be copied at once from one
+	// ESXi host, shared by all the migrations copying from it
+	NFCConnectionsPerESXiHostKey = "NFC_CONNECTIONS_PER_ESXI_HOST"
+	// NFCConnectionsPerESXiHost is the default of NFCConnectionsPerESXiHostKey
+	NFCConnectionsPerESXiHost = 8
+	// MaxMigrationsPerESXiHostKey caps the migrations running at once from one ESXi
+	// host, across all plans. 0 is unlimited.
+	MaxMigrationsPerESXiHostKey = "MAX_MIGRATIONS_PER_ESXI_HOST"
+	// MaxMigrationsPerESXiHost is the default of MaxMigrationsPerESXiHostKey
+	MaxMigrationsPerESXiHost = 4
+	// MaxMigrationsPerDatastoreKey caps the migrations running at once with a disk
+	// on one datastore, across all plans. 0 is unlimited.
+	MaxMigrationsPerDatastoreKey = "MAX_MIGRATIONS_PER_DATASTORE"
+	// MaxMigrationsPerDatastore is the default of MaxMigrationsPerDatastoreKey
+	MaxMigrationsPerDatastore = 8
+	// MaxMigrationsPerAgentKey caps the migrations running at once per vjailbreak
+	// agent, across all plans. 0 is unlimited.
+	MaxMigrationsPerAgentKey = "MAX_MIGRATIONS_PER_AGENT"
+	// MaxMigrationsPerAgent is the default of MaxMigrationsPerAgentKey
+	MaxMigrationsPerAgent = 5
+	// CutoverDowntimeTargetKey is the longest final sync a warm migration is ready
+	// for cutover with, as a duration
+	CutoverDowntimeTargetKey = "CUTOVER_DOWNTIME_TARGET"
+	// CutoverDowntimeTarget is the default of CutoverDowntimeTargetKey
+	CutoverDowntimeTarget = "5m"
+
 	// AnnotationValueTrue is the string value "true" used for annotations
 	AnnotationValueTrue = "true"
 
@@ -429,6 +472,9 @@ const (
 	EventDisconnect                               = "Disconnected network interfaces"
 	// EventMessageDataCopied is sent by v2v-helper when data-only mode completes disk copy/conversion.
 	EventMessageDataCopied = "DataOnly mode: disk copy and conversion complete, skipping VM creation"
+	// EventMessagePromotingStagedVolumes is sent by v2v-helper when it starts creating
+	// the instance of a data-only migration from the staged volumes.
+	EventMessagePromotingStagedVolumes = "Promoting staged volumes"
 
 	// StorageAcceleratedCopy specific event messages
 	EventMessageEsxiSSHConnect                       = "Connecting to ESXi"
@@ -687,6 +733,14 @@ runcmd:
 	// an admin actually triggered cutover.
 	MigrationConditionTypeCutoverTriggered corev1.PodConditionType = "CutoverTriggered"
 
+	// MigrationConditionTypeReadyForCutover represents the condition type telling whether the
+	// predicted downtime of a warm migration cutover fits the CUTOVER_DOWNTIME_TARGET setting.
+	MigrationConditionTypeReadyForCutover corev1.PodConditionType = "ReadyForCutover"
+	// MigrationReasonDowntimeFits is the ReadyForCutover reason when the predicted downtime fits the target
+	MigrationReasonDowntimeFits = "DowntimeFits"
+	// MigrationReasonDowntimeTooLong is the ReadyForCutover reason when the predicted downtime exceeds the target
+	MigrationReasonDowntimeTooLong = "DowntimeTooLong"
+
 	// MigrationConditionTypeValidated represents the condition type for validated phase
 	MigrationConditionTypeValidated corev1.PodConditionType = "Validated"
 	MigrationConditionTypeFailed    corev1.PodConditionType = "Failed"
@@ -703,6 +757,7 @@ runcmd:
 	// VMMigrationStatesEnum is a map of migration phase to state
 	VMMigrationStatesEnum = map[vjailbreakv1alpha1.VMMigrationPhase]int{
 		vjailbreakv1alpha1.VMMigrationPhasePending:               0,
+		vjailbreakv1alpha1.VMMigrationPhaseQueued:                0,
 		vjailbreakv1alpha1.VMMigrationPhaseValidating:            1,
 		vjailbreakv1alpha1.VMMigrationPhaseValidationFailed:      2,
 		vjailbreakv1alpha1.VMMigrationPhaseFailed:                3,
@@ -851,9 +906,199 @@ const (
 	ProxyVMOVAURLDefault = "https://vjailbreak-dev.s3.us-west-2.amazonaws.com/hot-add/ha-proxy-vm.ova"
 	ProxyVMOVADir        = "/home/ubuntu/proxy-vm-template"
 	ProxyVMOVAFileName   = "ha-proxy-vm.ova"
+
+	// ApplianceImportControllerName is the name of the ApplianceImport controller
+	ApplianceImportControllerName = "applianceimport-controller"
+
+	// ApplianceImportHostDir is where appliances are uploaded on the vjailbreak node,
+	// it is mounted at ApplianceImportDir in the v2v-helper pod
+	ApplianceImportHostDir = "/home/ubuntu/imports"
+	ApplianceImportDir     = "/home/fedora/imports"
+
+	// SourceTypeKey in the migration ConfigMap selects how v2v-helper reads the
+	// source. It is unset for vCenter and ESXi sources.
+	SourceTypeKey = "SOURCE_TYPE"
+	// SourceTypeAppliance imports the appliance named by ApplianceSourceKey
+	SourceTypeAppliance = "appliance"
+	// ApplianceFormatKey and ApplianceSourceKey carry ApplianceImport.Spec.Format and Source
+	ApplianceFormatKey = "APPLIANCE_FORMAT"
+	ApplianceSourceKey = "APPLIANCE_SOURCE"
+	// ApplianceSourceUsernameEnv and ApplianceSourcePasswordEnv carry the
+	// credentials of an smb:// appliance source into the v2v-helper pod
+	ApplianceSourceUsernameEnv = "APPLIANCE_SOURCE_USERNAME"
+	ApplianceSourcePasswordEnv = "APPLIANCE_SOURCE_PASSWORD"
+
+	// LibvirtCredsControllerName is the name of the LibvirtCreds controller
+	LibvirtCredsControllerName = "libvirtcreds-controller" //nolint:gosec // not a password string
+	// LibvirtMigrationControllerName is the name of the LibvirtMigration controller
+	LibvirtMigrationControllerName = "libvirtmigration-controller"
+
+	// LibvirtCreds validation statuses
+	LibvirtCredsStatusValidating = "Validating"
+	LibvirtCredsStatusSucceeded  = "Succeeded"
+	LibvirtCredsStatusFailed     = "Failed"
+
+	// SourceTypeLibvirt migrates the libvirt domain named by LibvirtDomainKey
+	SourceTypeLibvirt = "libvirt"
+	// LibvirtURIKey and LibvirtDomainKey carry LibvirtCreds.Spec.URI and LibvirtMigration.Spec.DomainName
+	LibvirtURIKey    = "LIBVIRT_URI"
+	LibvirtDomainKey = "LIBVIRT_DOMAIN"
+	// LibvirtSSHPrivateKeyEnv carries the SSH private key of the KVM host into the v2v-helper pod
+	LibvirtSSHPrivateKeyEnv = "LIBVIRT_SSH_PRIVATE_KEY" //nolint:gosec // not a password string
+
+	// ProxmoxCredsControllerName is the name of the ProxmoxCreds controller
+	ProxmoxCredsControllerName = "proxmoxcreds-controller" //nolint:gosec // not a password string
+	// ProxmoxMigrationControllerName is the name of the ProxmoxMigration controller
+	ProxmoxMigrationControllerName = "proxmoxmigration-controller"
+
+	// ProxmoxCreds validation statuses
+	ProxmoxCredsStatusValidating = "Validating"
+	ProxmoxCredsStatusSucceeded  = "Succeeded"
+	ProxmoxCredsStatusFailed     = "Failed"
+
+	// ProxmoxCredsLabel is set on a ProxmoxMachine with the name of its ProxmoxCreds
+	ProxmoxCredsLabel = "vjailbreak.k8s.pf9.io/proxmoxcreds" //nolint:gosec // not a password string
+
+	// SourceTypeProxmox migrates the Proxmox VE VM identified by ProxmoxVMIDKey
+	SourceTypeProxmox = "proxmox"
+	// ProxmoxAPIURLKey, ProxmoxInsecureKey and ProxmoxVMIDKey carry ProxmoxCreds.Spec.APIURL,
+	// ProxmoxCreds.Spec.Insecure and the VMID of the ProxmoxMachine
+	ProxmoxAPIURLKey   = "PROXMOX_API_URL"
+	ProxmoxInsecureKey = "PROXMOX_INSECURE"
+	ProxmoxVMIDKey     = "PROXMOX_VMID"
+	// ProxmoxTokenIDEnv, ProxmoxTokenSecretEnv and ProxmoxSSHPrivateKeyEnv carry the
+	// ProxmoxCreds secret into the v2v-helper pod
+	ProxmoxTokenIDEnv       = "PROXMOX_TOKEN_ID"        //nolint:gosec // not a password string
+	ProxmoxTokenSecretEnv   = "PROXMOX_TOKEN_SECRET"    //nolint:gosec // not a password string
+	ProxmoxSSHPrivateKeyEnv = "PROXMOX_SSH_PRIVATE_KEY" //nolint:gosec // not a password string
+
+	// DestinationTypeKey in the migration ConfigMap selects where v2v-helper
+	// creates the volumes and the VM. It is unset for OpenStack.
+	DestinationTypeKey = "DESTINATION_TYPE"
+	// DestinationTypeOpenstack lands VMs on Nova with Cinder volumes and Neutron ports
+	DestinationTypeOpenstack = "openstack"
+	// DestinationTypeKubevirt lands VMs on KubeVirt with CDI DataVolumes and Multus networks
+	DestinationTypeKubevirt = "kubevirt"
+	// KubevirtNamespaceKey and KubevirtUploadProxyURLKey carry the KubeVirt destination
+	// of the MigrationTemplate
+	KubevirtNamespaceKey      = "KUBEVIRT_NAMESPACE"
+	KubevirtUploadProxyURLKey = "KUBEVIRT_UPLOAD_PROXY_URL"
+	// KubevirtUploadProxyURLDefault is the in-cluster service of the CDI upload proxy
+	KubevirtUploadProxyURLDefault = "https://cdi-uploadproxy.cdi.svc"
+	// KubevirtPodNetwork is the network mapping target for the pod network
+	// rather than a NetworkAttachmentDefinition
+	KubevirtPodNetwork = "pod"
+	// KubevirtStagingHostDir is where disks for a KubeVirt destination are staged
+	// on the vjailbreak node before upload, it is mounted at KubevirtStagingDir
+	// in the v2v-helper pod
+	KubevirtStagingHostDir = "/home/ubuntu/kubevirt-staging"
+	KubevirtStagingDir     = "/home/fedora/kubevirt-staging"
+	// KubevirtDataVolumeTimeout bounds the wait for a DataVolume to become ready
+	// for upload and to finish processing an upload
+	KubevirtDataVolumeTimeout = 30 * time.Minute
+
+	// ExportTargetKey, ExportCompressKey, ExportImageVisibilityKey and ExportKeepVolumesKey
+	// carry the MigrationPlan export options. ExportTargetKey is unset when not exporting.
+	ExportTargetKey          = "EXPORT_TARGET"
+	ExportCompressKey        = "EXPORT_COMPRESS"
+	ExportImageVisibilityKey = "EXPORT_IMAGE_VISIBILITY"
+	ExportKeepVolumesKey     = "EXPORT_KEEP_VOLUMES"
+	// ExportTargetPVC and ExportTargetGlance write qcow2 files to a PVC or upload Glance images
+	ExportTargetPVC    = "pvc"
+	ExportTargetGlance = "glance"
+	// ExportMountPath is where the export PVC is mounted in the v2v-helper pod
+	ExportMountPath = "/home/fedora/export"
+	// ExportVolumeName is the name of the export PVC volume in the v2v-helper pod
+	ExportVolumeName = "export"
+	// ExportManifestFile is the manifest written next to the exported qcow2 files
+	ExportManifestFile = "manifest.json"
+	// ExportManifestKey is the key of the manifest in the export manifest ConfigMap
+	ExportManifestKey = "manifest.json"
+	// ExportImageTimeout bounds the wait for Cinder to upload a volume to Glance
+	ExportImageTimeout = 2 * time.Hour
+	// ExportImagePollInterval is how often the status of an uploading image is checked
+	ExportImagePollInterval = 15 * time.Second
+
+	// BandwidthLimitMbpsKey and BandwidthScheduleKey carry the MigrationPlan
+	// bandwidth cap and its daily windows, in the same format as the global ones
+	BandwidthLimitMbpsKey = "BANDWIDTH_LIMIT_MBPS"
+	BandwidthScheduleKey  = "BANDWIDTH_SCHEDULE"
+	// BandwidthRefreshInterval is how often v2v-helper re-evaluates the bandwidth
+	// schedules and re-reads the global cap from the vjailbreak settings
+	BandwidthRefreshInterval = 1 * time.Minute
+
+	// CopyCheckpointSuffix names the ConfigMap, next to the Migration, in which
+	// v2v-helper keeps its disk copy checkpoint
+	CopyCheckpointSuffix = "-copy-checkpoint"
+	// CopyCheckpointKey is the key of the checkpoint in that ConfigMap
+	CopyCheckpointKey = "checkpoint.json"
+	// CopyCheckpointDetachTimeout bounds the detach of a checkpointed volume from
+	// the vJailbreak node the previous v2v-helper pod ran on
+	CopyCheckpointDetachTimeout = 5 * time.Minute
+
+	// VerifyModeKey and VerifySamplePercentKey carry the MigrationPlan integrity
+	// verification mode, Sampled or Full, and the share of each disk sampled
+	VerifyModeKey          = "VERIFY_MODE"
+	VerifySamplePercentKey = "VERIFY_SAMPLE_PERCENT"
+	// VerifyRepairAttempts is how many times ranges that differ from the source
+	// are copied again before the migration fails
+	VerifyRepairAttempts = 2
+	// VerifyMismatchReportLimit caps the mismatched ranges listed per disk in the
+	// Migration status
+	VerifyMismatchReportLimit = 20
+
+	// PromoteStagedVolumesEnv is set on the job that creates the instance of a
+	// data-only migration from its staged volumes
+	PromoteStagedVolumesEnv = "PROMOTE_STAGED_VOLUMES"
+	// PromoteJobGenerationAnnotation records the Migration generation a promote
+	// job was created for. A failed promotion is run again once the Migration
+	// has been edited since.
+	PromoteJobGenerationAnnotation = "vjailbreak.k8s.pf9.io/promote-generation"
+	// StagedVMKey is the key of the staged VM in the ConfigMap a data-only
+	// migration records it in
+	StagedVMKey = "stagedvm.json"
+
+	// ParallelDiskCopiesKey carries how many disks of a VM the MigrationPlan lets
+	// v2v-helper copy at once
+	ParallelDiskCopiesKey = "PARALLEL_DISK_COPIES"
+
+	// VDDKTransportsKey carries the VDDK transport modes of the NBD copy, colon
+	// separated in the order they are tried, and VDDKCompressionKey the
+	// compression of the nbd and nbdssl modes
+	VDDKTransportsKey  = "VDDK_TRANSPORTS"
+	VDDKCompressionKey = "VDDK_COMPRESSION"
+
+	// MinCBTHardwareVersion is the minimum VMware virtual hardware version that
+	// supports Changed Block Tracking (CBT). VMs on older hardware versions cannot
+	// use CBT and must be migrated using cold migration. See VMware KB 1020128.
+	MinCBTHardwareVersion = 7
+	// MaxSnapshotChainDepth is the longest snapshot chain vSphere supports on a disk
+	MaxSnapshotChainDepth = 32
+
+	// MigrationAssessmentControllerName is the name of the MigrationAssessment controller
+	MigrationAssessmentControllerName = "migrationassessment-controller"
+	// MigrationAssessmentReportSuffix names the ConfigMap, next to the
+	// MigrationAssessment, that holds its per-VM report
+	MigrationAssessmentReportSuffix = "-report"
+	// AssessmentReportJSONKey and AssessmentReportCSVKey are the keys of the
+	// report in that ConfigMap
+	AssessmentReportJSONKey = "report.json"
+	AssessmentReportCSVKey  = "report.csv"
+	// MigrationAssessmentRefreshInterval is how often an assessment is run again
+	// to follow the changes of the inventory
+	MigrationAssessmentRefreshInterval = 1 * time.Hour
 )
 
 var (
 	// ProxyVMRequiredComponents lists the binaries that must be present on the Proxy VM
 	ProxyVMRequiredComponents = []string{"qemu-nbd"}
+
+	// SupportedLinuxOS are the Linux distributions virt-v2v converts, matched
+	// case-insensitively against the product name of the guest
+	SupportedLinuxOS = []string{
+		"redhat", "red hat", "rhel", "centos", "scientific linux",
+		"oracle linux", "fedora", "sles", "sled", "opensuse",
+		"alt linux", "debian", "ubuntu", "rocky linux",
+		"suse linux enterprise server", "suse linux enterprise desktop", "alma linux",
+	}
 )
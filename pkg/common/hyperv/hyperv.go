@@ -0,0 +1,278 @@
+// Package hyperv reads the configuration of an exported Hyper-V virtual machine.
+package hyperv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// VirtualMachinesDir and VirtualHardDisksDir are the folders Export-VM writes
+	VirtualMachinesDir  = "Virtual Machines"
+	VirtualHardDisksDir = "Virtual Hard Disks"
+)
+
+var driveElement = regexp.MustCompile(`^drive[0-9]+$`)
+
+// VM is the part of a Hyper-V configuration needed to recreate the VM. The
+// configuration does not record the guest OS, so there is no OS family.
+type VM struct {
+	Name     string
+	CPU      int
+	MemoryMB int
+	// UEFI is set for generation 2 VMs
+	UEFI  bool
+	Disks []Disk
+	NICs  []NIC
+}
+
+// Disk is a virtual hard disk attached to the VM, in configuration order
+type Disk struct {
+	// Path is the path recorded on the Hyper-V host, e.g. C:\Hyper-V\Virtual Hard Disks\vm.vhdx
+	Path string
+}
+
+// FileName is the base name of the disk, for finding it in an export
+func (d Disk) FileName() string {
+	return filepath.Base(strings.ReplaceAll(d.Path, `\`, "/"))
+}
+
+// NIC is a network adapter of the VM, in configuration order
+type NIC struct {
+	Switch string
+	// MAC is lower case with colons, empty for a dynamic address not yet assigned
+	MAC string
+}
+
+// node is a generic element. The configuration is a tree of elements named
+// after device GUIDs, so it is walked rather than unmarshalled into structs.
+type node struct {
+	XMLName xml.Name
+	Content string `xml:",chardata"`
+	Nodes   []node `xml:",any"`
+}
+
+func (n *node) child(name string) *node {
+	for i := range n.Nodes {
+		if strings.EqualFold(n.Nodes[i].XMLName.Local, name) {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+func (n *node) path(names ...string) *node {
+	cur := n
+	for _, name := range names {
+		if cur = cur.child(name); cur == nil {
+			return nil
+		}
+	}
+	return cur
+}
+
+func (n *node) text(names ...string) string {
+	if c := n.path(names...); c != nil {
+		return strings.TrimSpace(c.Content)
+	}
+	return ""
+}
+
+func (n *node) find(name string) *node {
+	if strings.EqualFold(n.XMLName.Local, name) {
+		return n
+	}
+	for i := range n.Nodes {
+		if found := n.Nodes[i].find(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// FindConfig returns the configuration XML of an export directory, or path
+// itself when it already names the XML file
+func FindConfig(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+
+	vmcx := false
+	for _, dir := range []string{filepath.Join(path, VirtualMachinesDir), path} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".xml":
+				return filepath.Join(dir, e.Name()), nil
+			case ".vmcx":
+				vmcx = true
+			}
+		}
+	}
+	if vmcx {
+		return "", errors.Errorf("%s only has a binary .vmcx configuration, export the VM configuration as XML", path)
+	}
+	return "", errors.Errorf("no VM configuration XML found in %s", path)
+}
+
+// ExportRoot is the export directory a configuration file belongs to
+func ExportRoot(configPath string) string {
+	dir := filepath.Dir(configPath)
+	if strings.EqualFold(filepath.Base(dir), VirtualMachinesDir) {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// FindDisk locates a disk of the configuration inside the export
+func FindDisk(configPath string, disk Disk) (string, error) {
+	root := ExportRoot(configPath)
+	name := disk.FileName()
+	for _, candidate := range []string{
+		filepath.Join(root, VirtualHardDisksDir, name),
+		filepath.Join(root, name),
+		filepath.Join(filepath.Dir(configPath), name),
+	} {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", errors.Errorf("disk %s not found in export %s", name, root)
+}
+
+// ReadConfig parses the configuration file at path
+func ReadConfig(path string) (*VM, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseConfig(f)
+}
+
+// ParseConfig reads a Hyper-V VM configuration XML. Hyper-V writes it as UTF-16.
+func ParseConfig(r io.Reader) (*VM, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = toUTF8(data)
+
+	var root node
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// The content is UTF-8 by now whatever the declaration says
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := decoder.Decode(&root); err != nil {
+		return nil, errors.Wrap(err, "failed to parse Hyper-V configuration")
+	}
+	if !strings.EqualFold(root.XMLName.Local, "configuration") {
+		return nil, errors.Errorf("unexpected root element %q, not a Hyper-V configuration", root.XMLName.Local)
+	}
+
+	vm := &VM{Name: root.text("properties", "name")}
+	if vm.CPU, err = intValue(root.text("settings", "processors", "count")); err != nil {
+		return nil, errors.Wrap(err, "invalid processor count")
+	}
+	if vm.MemoryMB, err = intValue(root.text("settings", "memory", "bank", "size")); err != nil {
+		return nil, errors.Wrap(err, "invalid memory size")
+	}
+	// Generation 2 VMs have subtype 1 and are the only ones with secure boot settings
+	vm.UEFI = root.text("properties", "subtype") == "1" || root.find("secure_boot_enabled") != nil
+
+	root.walk(func(n *node) {
+		switch {
+		case driveElement.MatchString(strings.ToLower(n.XMLName.Local)):
+			if strings.EqualFold(n.text("type"), "VHD") && n.text("pathname") != "" {
+				vm.Disks = append(vm.Disks, Disk{Path: n.text("pathname")})
+			}
+		case n.child("ChannelInstanceGuid") != nil && n.find("AltSwitchName") != nil:
+			vm.NICs = append(vm.NICs, NIC{
+				Switch: strings.TrimSpace(n.find("AltSwitchName").Content),
+				MAC:    formatMAC(n.text("mac_address")),
+			})
+		}
+	})
+
+	if len(vm.Disks) == 0 {
+		return nil, errors.New("Hyper-V configuration has no virtual hard disks")
+	}
+	for _, d := range vm.Disks {
+		if strings.EqualFold(filepath.Ext(d.FileName()), ".avhdx") || strings.EqualFold(filepath.Ext(d.FileName()), ".avhd") {
+			return nil, errors.Errorf("disk %s is a checkpoint, delete the checkpoints to merge them before exporting", d.FileName())
+		}
+	}
+	return vm, nil
+}
+
+// walk calls fn for n and every element below it in document order
+func (n *node) walk(fn func(*node)) {
+	fn(n)
+	for i := range n.Nodes {
+		n.Nodes[i].walk(fn)
+	}
+}
+
+func intValue(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// formatMAC turns the 00155D0A0B0C form Hyper-V records into 00:15:5d:0a:0b:0c
+func formatMAC(raw string) string {
+	raw = strings.ToLower(strings.NewReplacer("-", "", ":", "").Replace(strings.TrimSpace(raw)))
+	if len(raw) != 12 || raw == "000000000000" {
+		return ""
+	}
+	if _, err := strconv.ParseUint(raw, 16, 64); err != nil {
+		return ""
+	}
+	parts := make([]string, 0, 6)
+	for i := 0; i < 12; i += 2 {
+		parts = append(parts, raw[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
+
+// toUTF8 decodes UTF-16 content marked by a byte order mark
+func toUTF8(data []byte) []byte {
+	if len(data) < 2 {
+		return data
+	}
+	var bigEndian bool
+	switch {
+	case data[0] == 0xff && data[1] == 0xfe:
+		bigEndian = false
+	case data[0] == 0xfe && data[1] == 0xff:
+		bigEndian = true
+	default:
+		return bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	}
+	data = data[2:]
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return []byte(string(utf16.Decode(units)))
+}
@@ -0,0 +1,186 @@
+package hyperv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+const testConfig = `<?xml version="1.0" encoding="UTF-16" standalone="yes"?>
+<configuration>
+  <_83f8638b-8dca-4152-9eda-2ca8b33039b4_>
+    <controller0>
+      <drive0>
+        <pathname type="string">C:\Hyper-V\Virtual Hard Disks\app01.vhdx</pathname>
+        <type type="string">VHD</type>
+      </drive0>
+      <drive1>
+        <pathname type="string">C:\iso\install.iso</pathname>
+        <type type="string">ISO</type>
+      </drive1>
+    </controller0>
+  </_83f8638b-8dca-4152-9eda-2ca8b33039b4_>
+  <_d422512d-2bf2-4752-809d-7b82b5fcb1b4_>
+    <ChannelInstanceGuid type="string">{aaaa}</ChannelInstanceGuid>
+    <controller0>
+      <drive0>
+        <pathname type="string">D:\VMs\app01-data.vhd</pathname>
+        <type type="string">VHD</type>
+      </drive0>
+    </controller0>
+  </_d422512d-2bf2-4752-809d-7b82b5fcb1b4_>
+  <_8e3a359f-559a-4b6a-98a9-1690a6100ed7_>
+    <ChannelInstanceGuid type="string">{bbbb}</ChannelInstanceGuid>
+    <Connection>
+      <AltPortName type="string">Network Adapter</AltPortName>
+      <AltSwitchName type="string">External</AltSwitchName>
+    </Connection>
+    <is_static type="bool">True</is_static>
+    <mac_address type="string">00155D0A0B0C</mac_address>
+  </_8e3a359f-559a-4b6a-98a9-1690a6100ed7_>
+  <_8e3a359f-559a-4b6a-98a9-1690a6100ed8_>
+    <ChannelInstanceGuid type="string">{cccc}</ChannelInstanceGuid>
+    <Connection>
+      <AltSwitchName type="string">Internal</AltSwitchName>
+    </Connection>
+    <is_static type="bool">False</is_static>
+    <mac_address type="string">000000000000</mac_address>
+  </_8e3a359f-559a-4b6a-98a9-1690a6100ed8_>
+  <properties>
+    <name type="string">app01</name>
+    <subtype type="integer">1</subtype>
+  </properties>
+  <settings>
+    <processors>
+      <count type="integer">4</count>
+    </processors>
+    <memory>
+      <bank>
+        <dynamic_memory_enabled type="bool">True</dynamic_memory_enabled>
+        <size type="integer">8192</size>
+      </bank>
+    </memory>
+  </settings>
+</configuration>
+`
+
+// utf16LE encodes s the way Hyper-V writes its configuration files
+func utf16LE(s string) []byte {
+	out := []byte{0xff, 0xfe}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(u), byte(u>>8))
+	}
+	return out
+}
+
+func TestParseConfig(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"utf-8":  []byte(strings.Replace(testConfig, "UTF-16", "UTF-8", 1)),
+		"utf-16": utf16LE(testConfig),
+	} {
+		t.Run(name, func(t *testing.T) {
+			vm, err := ParseConfig(strings.NewReader(string(data)))
+			if err != nil {
+				t.Fatalf("ParseConfig() unexpected error: %v", err)
+			}
+			if vm.Name != "app01" || vm.CPU != 4 || vm.MemoryMB != 8192 || !vm.UEFI {
+				t.Errorf("ParseConfig() = %+v, want app01 with 4 CPU, 8192 MB, UEFI", vm)
+			}
+			wantDisks := []string{"app01.vhdx", "app01-data.vhd"}
+			if len(vm.Disks) != len(wantDisks) {
+				t.Fatalf("got %d disks, want %d", len(vm.Disks), len(wantDisks))
+			}
+			for i, want := range wantDisks {
+				if vm.Disks[i].FileName() != want {
+					t.Errorf("Disks[%d] = %q, want %q", i, vm.Disks[i].FileName(), want)
+				}
+			}
+			wantNICs := []NIC{{Switch: "External", MAC: "00:15:5d:0a:0b:0c"}, {Switch: "Internal"}}
+			if len(vm.NICs) != len(wantNICs) {
+				t.Fatalf("got %d NICs, want %d", len(vm.NICs), len(wantNICs))
+			}
+			for i, want := range wantNICs {
+				if vm.NICs[i] != want {
+					t.Errorf("NICs[%d] = %+v, want %+v", i, vm.NICs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseConfigRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "checkpoint disk",
+			config:  strings.Replace(testConfig, "app01-data.vhd", "app01-data_1234.avhdx", 1),
+			wantErr: "is a checkpoint",
+		},
+		{
+			name:    "no disks",
+			config:  strings.ReplaceAll(testConfig, ">VHD<", ">NONE<"),
+			wantErr: "no virtual hard disks",
+		},
+		{
+			name:    "not a configuration",
+			config:  `<domain type="kvm"><name>app01</name></domain>`,
+			wantErr: "not a Hyper-V configuration",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig(strings.NewReader(tt.config))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParseConfig() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindConfigAndDisk(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "app01")
+	for _, dir := range []string{VirtualMachinesDir, VirtualHardDisksDir} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configPath := filepath.Join(root, VirtualMachinesDir, "5A1B.xml")
+	if err := os.WriteFile(configPath, utf16LE(testConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, VirtualHardDisksDir, "app01.vhdx"), []byte("disk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FindConfig(root)
+	if err != nil || got != configPath {
+		t.Fatalf("FindConfig() = %q, %v; want %q", got, err, configPath)
+	}
+	if ExportRoot(configPath) != root {
+		t.Errorf("ExportRoot() = %q, want %q", ExportRoot(configPath), root)
+	}
+
+	vm, err := ReadConfig(configPath)
+	if err != nil {
+		t.Fatalf("ReadConfig() unexpected error: %v", err)
+	}
+	disk, err := FindDisk(configPath, vm.Disks[0])
+	if err != nil || disk != filepath.Join(root, VirtualHardDisksDir, "app01.vhdx") {
+		t.Errorf("FindDisk() = %q, %v", disk, err)
+	}
+	if _, err := FindDisk(configPath, vm.Disks[1]); err == nil {
+		t.Error("FindDisk() of a disk missing from the export succeeded, want error")
+	}
+
+	if err := os.Rename(configPath, filepath.Join(root, VirtualMachinesDir, "5A1B.vmcx")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FindConfig(root); err == nil || !strings.Contains(err.Error(), ".vmcx") {
+		t.Errorf("FindConfig() error = %v, want a .vmcx error", err)
+	}
+}
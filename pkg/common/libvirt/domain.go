@@ -0,0 +1,243 @@
+// Package libvirt reads libvirt domain definitions and builds the virsh commands
+// used to read them from a KVM host.
+package libvirt
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	osFamilyWindows = "windowsGuest"
+	osFamilyLinux   = "linuxGuest"
+
+	// BusVirtio is the disk bus of a guest that needs no driver injection
+	BusVirtio = "virtio"
+)
+
+// linuxOSInfoVendors are the libosinfo OS id prefixes of Linux distributions
+var linuxOSInfoVendors = []string{
+	"http://almalinux.org/", "http://alpinelinux.org/", "http://archlinux.org/",
+	"http://centos.org/", "http://debian.org/", "http://fedoraproject.org/",
+	"http://libosinfo.org/linux/", "http://opensuse.org/", "http://oracle.com/",
+	"http://redhat.com/", "http://rockylinux.org/", "http://suse.com/", "http://ubuntu.com/",
+}
+
+// Domain is the part of a libvirt domain definition needed to recreate it
+type Domain struct {
+	Name     string
+	CPU      int
+	MemoryMB int
+	UEFI     bool
+	// OSFamily is read from the libosinfo metadata virt-install records, empty
+	// when the domain has none or names an OS that is neither Windows nor Linux
+	OSFamily string
+	Disks    []Disk
+	NICs     []NIC
+}
+
+// Disk is a disk of the domain, in definition order
+type Disk struct {
+	// Target is the device name in the guest, e.g. vda
+	Target string
+	// Bus is virtio, scsi, sata or ide
+	Bus string
+	// Format is the image format, qcow2 or raw
+	Format string
+	// Source is the image file or block device on the host
+	Source string
+}
+
+// NIC is a network interface of the domain, in definition order
+type NIC struct {
+	// Network is the libvirt network, host bridge or direct device the NIC uses
+	Network string
+	// MAC is lower case with colons
+	MAC string
+	// Model is the emulated device, e.g. virtio or e1000
+	Model string
+}
+
+// AllDisksVirtio reports whether every disk is on the virtio bus, in which case
+// the guest already has the drivers it needs on OpenStack
+func (d *Domain) AllDisksVirtio() bool {
+	for _, disk := range d.Disks {
+		if disk.Bus != BusVirtio {
+			return false
+		}
+	}
+	return true
+}
+
+type domainXML struct {
+	XMLName xml.Name `xml:"domain"`
+	Name    string   `xml:"name"`
+	Memory  struct {
+		Value string `xml:",chardata"`
+		Unit  string `xml:"unit,attr"`
+	} `xml:"memory"`
+	VCPU string `xml:"vcpu"`
+	OS   struct {
+		Firmware string `xml:"firmware,attr"`
+		Loader   *struct {
+			Type string `xml:"type,attr"`
+		} `xml:"loader"`
+	} `xml:"os"`
+	Metadata struct {
+		LibOSInfo struct {
+			OS struct {
+				ID string `xml:"id,attr"`
+			} `xml:"os"`
+		} `xml:"libosinfo"`
+	} `xml:"metadata"`
+	Devices struct {
+		Disks []struct {
+			Type   string `xml:"type,attr"`
+			Device string `xml:"device,attr"`
+			Driver struct {
+				Type string `xml:"type,attr"`
+			} `xml:"driver"`
+			Source struct {
+				File string `xml:"file,attr"`
+				Dev  string `xml:"dev,attr"`
+			} `xml:"source"`
+			BackingStore *struct {
+				Source *struct{} `xml:"source"`
+			} `xml:"backingStore"`
+			Target struct {
+				Dev string `xml:"dev,attr"`
+				Bus string `xml:"bus,attr"`
+			} `xml:"target"`
+		} `xml:"disk"`
+		Interfaces []struct {
+			Type string `xml:"type,attr"`
+			MAC  struct {
+				Address string `xml:"address,attr"`
+			} `xml:"mac"`
+			Source struct {
+				Network string `xml:"network,attr"`
+				Bridge  string `xml:"bridge,attr"`
+				Dev     string `xml:"dev,attr"`
+			} `xml:"source"`
+			Model struct {
+				Type string `xml:"type,attr"`
+			} `xml:"model"`
+		} `xml:"interface"`
+	} `xml:"devices"`
+}
+
+// ParseDomain reads a domain definition as written by virsh dumpxml
+func ParseDomain(r io.Reader) (*Domain, error) {
+	var x domainXML
+	if err := xml.NewDecoder(r).Decode(&x); err != nil {
+		return nil, errors.Wrap(err, "failed to parse domain XML")
+	}
+
+	domain := &Domain{
+		Name:     strings.TrimSpace(x.Name),
+		OSFamily: osFamily(x.Metadata.LibOSInfo.OS.ID),
+		UEFI:     x.OS.Firmware == "efi" || (x.OS.Loader != nil && x.OS.Loader.Type == "pflash"),
+	}
+	var err error
+	if domain.CPU, err = strconv.Atoi(strings.TrimSpace(x.VCPU)); err != nil {
+		return nil, errors.Wrapf(err, "invalid vcpu count %q", x.VCPU)
+	}
+	if domain.MemoryMB, err = memoryMB(strings.TrimSpace(x.Memory.Value), x.Memory.Unit); err != nil {
+		return nil, err
+	}
+
+	for _, d := range x.Devices.Disks {
+		switch d.Device {
+		case "cdrom", "floppy":
+			continue
+		case "", "disk":
+		default:
+			return nil, errors.Errorf("disk %s is a %s device, only disks can be migrated", d.Target.Dev, d.Device)
+		}
+		disk := Disk{Target: d.Target.Dev, Bus: d.Target.Bus, Format: d.Driver.Type}
+		switch d.Type {
+		case "file":
+			disk.Source = d.Source.File
+		case "block":
+			disk.Source = d.Source.Dev
+		default:
+			return nil, errors.Errorf("disk %s is of type %q, only file and block disks can be migrated", disk.Target, d.Type)
+		}
+		if disk.Source == "" {
+			return nil, errors.Errorf("disk %s has no source", disk.Target)
+		}
+		switch disk.Format {
+		case "qcow2", "raw":
+		case "":
+			disk.Format = "raw"
+		default:
+			return nil, errors.Errorf("disk %s has format %q, only qcow2 and raw are supported", disk.Target, disk.Format)
+		}
+		if d.BackingStore != nil && d.BackingStore.Source != nil {
+			return nil, errors.Errorf("disk %s has a backing chain, flatten it with virsh blockpull first", disk.Target)
+		}
+		domain.Disks = append(domain.Disks, disk)
+	}
+	if len(domain.Disks) == 0 {
+		return nil, errors.Errorf("domain %s has no disks", domain.Name)
+	}
+
+	for _, i := range x.Devices.Interfaces {
+		nic := NIC{MAC: strings.ToLower(i.MAC.Address), Model: i.Model.Type}
+		switch {
+		case i.Source.Network != "":
+			nic.Network = i.Source.Network
+		case i.Source.Bridge != "":
+			nic.Network = i.Source.Bridge
+		default:
+			nic.Network = i.Source.Dev
+		}
+		domain.NICs = append(domain.NICs, nic)
+	}
+	return domain, nil
+}
+
+// memoryMB converts a libvirt memory element to MiB. libvirt defaults to KiB.
+func memoryMB(value, unit string) (int, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid memory size %q", value)
+	}
+	var bytes int64
+	switch unit {
+	case "b", "bytes":
+		bytes = n
+	case "", "k", "KiB":
+		bytes = n << 10
+	case "KB":
+		bytes = n * 1000
+	case "M", "MiB":
+		bytes = n << 20
+	case "MB":
+		bytes = n * 1000 * 1000
+	case "G", "GiB":
+		bytes = n << 30
+	case "GB":
+		bytes = n * 1000 * 1000 * 1000
+	default:
+		return 0, errors.Errorf("unsupported memory unit %q", unit)
+	}
+	return int(bytes >> 20), nil
+}
+
+// osFamily maps a libosinfo OS id such as http://microsoft.com/win/2k19 to an OS family
+func osFamily(id string) string {
+	if strings.HasPrefix(id, "http://microsoft.com/win") {
+		return osFamilyWindows
+	}
+	for _, vendor := range linuxOSInfoVendors {
+		if strings.HasPrefix(id, vendor) {
+			return osFamilyLinux
+		}
+	}
+	return ""
+}
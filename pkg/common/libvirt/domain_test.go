@@ -0,0 +1,192 @@
+package libvirt
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDomain = `<domain type='kvm' id='3'>
+  <name>web01</name>
+  <uuid>6f1c7e3a-2b1d-4c55-9a0e-0b1f6e0d8a11</uuid>
+  <metadata>
+    <libosinfo:libosinfo xmlns:libosinfo="http://libosinfo.org/xmlns/libvirt/domain/1.0">
+      <libosinfo:os id="http://ubuntu.com/ubuntu/22.04"/>
+    </libosinfo:libosinfo>
+  </metadata>
+  <memory unit='KiB'>4194304</memory>
+  <currentMemory unit='KiB'>4194304</currentMemory>
+  <vcpu placement='static'>2</vcpu>
+  <os firmware='efi'>
+    <type arch='x86_64' machine='pc-q35-6.2'>hvm</type>
+    <boot dev='hd'/>
+  </os>
+  <devices>
+    <emulator>/usr/bin/qemu-system-x86_64</emulator>
+    <disk type='file' device='disk'>
+      <driver name='qemu' type='qcow2' discard='unmap'/>
+      <source file='/var/lib/libvirt/images/web01.qcow2'/>
+      <backingStore/>
+      <target dev='vda' bus='virtio'/>
+    </disk>
+    <disk type='block' device='disk'>
+      <driver name='qemu' type='raw' cache='none'/>
+      <source dev='/dev/vg0/web01-data'/>
+      <target dev='vdb' bus='virtio'/>
+    </disk>
+    <disk type='file' device='cdrom'>
+      <driver name='qemu' type='raw'/>
+      <target dev='sda' bus='sata'/>
+      <readonly/>
+    </disk>
+    <interface type='network'>
+      <mac address='52:54:00:12:34:56'/>
+      <source network='default'/>
+      <model type='virtio'/>
+    </interface>
+    <interface type='bridge'>
+      <mac address='52:54:00:AB:CD:EF'/>
+      <source bridge='br0'/>
+      <model type='e1000'/>
+    </interface>
+  </devices>
+</domain>
+`
+
+func TestParseDomain(t *testing.T) {
+	domain, err := ParseDomain(strings.NewReader(testDomain))
+	if err != nil {
+		t.Fatalf("ParseDomain() unexpected error: %v", err)
+	}
+	if domain.Name != "web01" {
+		t.Errorf("Name = %q, want web01", domain.Name)
+	}
+	if domain.CPU != 2 || domain.MemoryMB != 4096 {
+		t.Errorf("CPU/MemoryMB = %d/%d, want 2/4096", domain.CPU, domain.MemoryMB)
+	}
+	if !domain.UEFI {
+		t.Error("UEFI = false, want true")
+	}
+	if domain.OSFamily != osFamilyLinux {
+		t.Errorf("OSFamily = %q, want %q", domain.OSFamily, osFamilyLinux)
+	}
+	wantDisks := []Disk{
+		{Target: "vda", Bus: "virtio", Format: "qcow2", Source: "/var/lib/libvirt/images/web01.qcow2"},
+		{Target: "vdb", Bus: "virtio", Format: "raw", Source: "/dev/vg0/web01-data"},
+	}
+	if len(domain.Disks) != len(wantDisks) {
+		t.Fatalf("got %d disks, want %d", len(domain.Disks), len(wantDisks))
+	}
+	for i, want := range wantDisks {
+		if domain.Disks[i] != want {
+			t.Errorf("Disks[%d] = %+v, want %+v", i, domain.Disks[i], want)
+		}
+	}
+	wantNICs := []NIC{
+		{Network: "default", MAC: "52:54:00:12:34:56", Model: "virtio"},
+		{Network: "br0", MAC: "52:54:00:ab:cd:ef", Model: "e1000"},
+	}
+	if len(domain.NICs) != len(wantNICs) {
+		t.Fatalf("got %d NICs, want %d", len(domain.NICs), len(wantNICs))
+	}
+	for i, want := range wantNICs {
+		if domain.NICs[i] != want {
+			t.Errorf("NICs[%d] = %+v, want %+v", i, domain.NICs[i], want)
+		}
+	}
+	if !domain.AllDisksVirtio() {
+		t.Error("AllDisksVirtio() = false, want true")
+	}
+}
+
+func TestParseDomainVariants(t *testing.T) {
+	tests := []struct {
+		name       string
+		edit       func(string) string
+		wantErr    string
+		wantMemory int
+		wantUEFI   bool
+		wantOS     string
+		wantVirtio bool
+	}{
+		{
+			name: "bios guest on sata with memory in GiB",
+			edit: func(s string) string {
+				s = strings.Replace(s, `<os firmware='efi'>`, `<os>`, 1)
+				s = strings.Replace(s, `<memory unit='KiB'>4194304</memory>`, `<memory unit='GiB'>8</memory>`, 1)
+				return strings.Replace(s, `<target dev='vdb' bus='virtio'/>`, `<target dev='sdb' bus='sata'/>`, 1)
+			},
+			wantMemory: 8192,
+			wantOS:     osFamilyLinux,
+		},
+		{
+			name: "pflash loader and windows metadata",
+			edit: func(s string) string {
+				s = strings.Replace(s, `<os firmware='efi'>`, `<os><loader readonly='yes' type='pflash'>/usr/share/OVMF/OVMF_CODE.fd</loader>`, 1)
+				return strings.Replace(s, "http://ubuntu.com/ubuntu/22.04", "http://microsoft.com/win/2k19", 1)
+			},
+			wantMemory: 4096,
+			wantUEFI:   true,
+			wantOS:     osFamilyWindows,
+			wantVirtio: true,
+		},
+		{
+			name: "no metadata",
+			edit: func(s string) string {
+				start := strings.Index(s, "<metadata>")
+				end := strings.Index(s, "</metadata>") + len("</metadata>")
+				return s[:start] + s[end:]
+			},
+			wantMemory: 4096,
+			wantUEFI:   true,
+			wantVirtio: true,
+		},
+		{
+			name: "backing chain",
+			edit: func(s string) string {
+				return strings.Replace(s, `<backingStore/>`, `<backingStore type='file'><format type='qcow2'/><source file='/var/lib/libvirt/images/base.qcow2'/></backingStore>`, 1)
+			},
+			wantErr: "backing chain",
+		},
+		{
+			name: "network disk",
+			edit: func(s string) string {
+				return strings.Replace(s, `<disk type='block' device='disk'>`, `<disk type='network' device='disk'>`, 1)
+			},
+			wantErr: `type "network"`,
+		},
+		{
+			name: "vmdk disk",
+			edit: func(s string) string {
+				return strings.Replace(s, `type='qcow2'`, `type='vmdk'`, 1)
+			},
+			wantErr: "only qcow2 and raw",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domain, err := ParseDomain(strings.NewReader(tt.edit(testDomain)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseDomain() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDomain() unexpected error: %v", err)
+			}
+			if domain.MemoryMB != tt.wantMemory {
+				t.Errorf("MemoryMB = %d, want %d", domain.MemoryMB, tt.wantMemory)
+			}
+			if domain.UEFI != tt.wantUEFI {
+				t.Errorf("UEFI = %v, want %v", domain.UEFI, tt.wantUEFI)
+			}
+			if domain.OSFamily != tt.wantOS {
+				t.Errorf("OSFamily = %q, want %q", domain.OSFamily, tt.wantOS)
+			}
+			if domain.AllDisksVirtio() != tt.wantVirtio {
+				t.Errorf("AllDisksVirtio() = %v, want %v", domain.AllDisksVirtio(), tt.wantVirtio)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package libvirt
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DomainStateShutOff is what virsh domstate prints for a stopped domain
+const DomainStateShutOff = "shut off"
+
+// Connection is a qemu+ssh libvirt URI split into the SSH login and the URI
+// virsh uses on the host itself
+type Connection struct {
+	User string
+	Host string
+	// LocalURI is qemu:///system or qemu:///session
+	LocalURI string
+}
+
+// ParseURI parses a URI such as qemu+ssh://root@kvm01.example.com/system. Only
+// the SSH transport is accepted: the disks are served from the host with
+// qemu-nbd, which needs a shell on it besides the libvirt connection.
+func ParseURI(raw string) (*Connection, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid libvirt URI %q", raw)
+	}
+	if u.Scheme != "qemu+ssh" {
+		return nil, errors.Errorf("libvirt URI %q must use the qemu+ssh transport", raw)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.Errorf("libvirt URI %q has no host", raw)
+	}
+	if u.Port() != "" && u.Port() != "22" {
+		return nil, errors.Errorf("libvirt URI %q uses SSH port %s, only port 22 is supported", raw, u.Port())
+	}
+	if u.RawQuery != "" {
+		return nil, errors.Errorf("libvirt URI %q has query parameters, which are not supported", raw)
+	}
+	switch u.Path {
+	case "/system", "/session":
+	default:
+		return nil, errors.Errorf("libvirt URI %q must end in /system or /session", raw)
+	}
+
+	conn := &Connection{
+		User:     u.User.Username(),
+		Host:     u.Hostname(),
+		LocalURI: "qemu://" + u.Path,
+	}
+	if conn.User == "" {
+		conn.User = "root"
+	}
+	return conn, nil
+}
+
+// VirshCommand is the shell command running virsh with args against the
+// connection's hypervisor
+func (c *Connection) VirshCommand(args ...string) string {
+	quoted := []string{"virsh", "-c", ShellQuote(c.LocalURI)}
+	for _, arg := range args {
+		quoted = append(quoted, ShellQuote(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ShellQuote quotes s for a POSIX shell
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ParseDomainList reads the output of virsh list --all --name
+func ParseDomainList(out string) []string {
+	var domains []string
+	for _, line := range strings.Split(out, "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			domains = append(domains, name)
+		}
+	}
+	return domains
+}
+
+// ParseBlockCapacity reads the virtual size in bytes from virsh domblkinfo output
+func ParseBlockCapacity(out string) (int64, error) {
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "Capacity" {
+			continue
+		}
+		capacity, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid capacity %q", strings.TrimSpace(value))
+		}
+		return capacity, nil
+	}
+	return 0, errors.New("virsh domblkinfo reported no capacity")
+}
@@ -0,0 +1,73 @@
+package libvirt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    *Connection
+		wantErr string
+	}{
+		{
+			uri:  "qemu+ssh://admin@kvm01.example.com/system",
+			want: &Connection{User: "admin", Host: "kvm01.example.com", LocalURI: "qemu:///system"},
+		},
+		{
+			uri:  "qemu+ssh://10.0.0.5:22/session",
+			want: &Connection{User: "root", Host: "10.0.0.5", LocalURI: "qemu:///session"},
+		},
+		{uri: "qemu+tcp://kvm01/system", wantErr: "qemu+ssh transport"},
+		{uri: "qemu+ssh:///system", wantErr: "no host"},
+		{uri: "qemu+ssh://kvm01:2222/system", wantErr: "port 2222"},
+		{uri: "qemu+ssh://kvm01/system?keyfile=/root/.ssh/id", wantErr: "query parameters"},
+		{uri: "qemu+ssh://kvm01/", wantErr: "/system or /session"},
+	}
+	for _, tt := range tests {
+		got, err := ParseURI(tt.uri)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseURI(%q) error = %v, want error containing %q", tt.uri, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseURI(%q) unexpected error: %v", tt.uri, err)
+			continue
+		}
+		if *got != *tt.want {
+			t.Errorf("ParseURI(%q) = %+v, want %+v", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestVirshCommand(t *testing.T) {
+	conn := &Connection{LocalURI: "qemu:///system"}
+	got := conn.VirshCommand("dumpxml", "--inactive", "it's web01")
+	want := `virsh -c 'qemu:///system' 'dumpxml' '--inactive' 'it'\''s web01'`
+	if got != want {
+		t.Errorf("VirshCommand() = %s, want %s", got, want)
+	}
+}
+
+func TestParseDomainList(t *testing.T) {
+	got := ParseDomainList("web01\ndb01\n\n  build agent  \n")
+	want := []string{"web01", "db01", "build agent"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDomainList() = %v, want %v", got, want)
+	}
+}
+
+func TestParseBlockCapacity(t *testing.T) {
+	out := "Capacity:       21474836480\nAllocation:     5368709120\nPhysical:       5369757696\n"
+	got, err := ParseBlockCapacity(out)
+	if err != nil || got != 21474836480 {
+		t.Errorf("ParseBlockCapacity() = %d, %v; want 21474836480", got, err)
+	}
+	if _, err := ParseBlockCapacity("error: failed to get domain 'web02'\n"); err == nil {
+		t.Error("ParseBlockCapacity() of an error message succeeded, want error")
+	}
+}
@@ -0,0 +1,295 @@
+// Package ova reads OVA archives and OVF descriptors.
+package ova
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/ovf"
+)
+
+// OS families as reported for VMware guests, which the rest of the pipeline expects
+const (
+	osFamilyWindows = "windowsGuest"
+	osFamilyLinux   = "linuxGuest"
+)
+
+// Appliance is the part of an OVF descriptor needed to recreate the VM
+type Appliance struct {
+	Name     string
+	CPU      int
+	MemoryMB int
+	UEFI     bool
+	// OSFamily is windowsGuest or linuxGuest, empty when the descriptor does not say
+	OSFamily string
+	Disks    []Disk
+	NICs     []NIC
+}
+
+// Disk is a virtual disk of the appliance, in descriptor order
+type Disk struct {
+	ID string
+	// Href is the file name of the disk image, relative to the descriptor
+	Href string
+	// Capacity is the virtual size in bytes
+	Capacity int64
+}
+
+// NIC is a network adapter of the appliance, in descriptor order
+type NIC struct {
+	Network string
+	// MAC is empty unless the descriptor pins an address
+	MAC string
+}
+
+// FindDescriptorEntry scans an OVA (tar) for the first .ovf entry.
+func FindDescriptorEntry(ovaPath string) (string, error) {
+	f, err := os.Open(filepath.Clean(ovaPath))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	r := tar.NewReader(f)
+	first := ""
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("reading archive: %v", err)
+		}
+		name := filepath.Base(h.Name)
+		if first == "" {
+			first = name
+		}
+		if strings.HasSuffix(strings.ToLower(name), ".ovf") {
+			return name, nil
+		}
+	}
+	if first == "" {
+		return "", fmt.Errorf("archive %q is empty", ovaPath)
+	}
+	return first, nil
+}
+
+// IsArchive reports whether path names an OVA rather than a bare OVF descriptor
+func IsArchive(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".ova")
+}
+
+// ReadAppliance parses the descriptor of an .ova archive or of an .ovf file
+func ReadAppliance(path string) (*Appliance, error) {
+	if !IsArchive(path) {
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ParseDescriptor(f)
+	}
+
+	entry, err := FindDescriptorEntry(path)
+	if err != nil {
+		return nil, err
+	}
+	var appliance *Appliance
+	err = walkArchive(path, func(name string, r io.Reader) (bool, error) {
+		if name != entry {
+			return false, nil
+		}
+		parsed, parseErr := ParseDescriptor(r)
+		appliance = parsed
+		return true, parseErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	if appliance == nil {
+		return nil, errors.Errorf("descriptor %s not found in %s", entry, path)
+	}
+	return appliance, nil
+}
+
+// ExtractFile copies the archive member name of an OVA to dest
+func ExtractFile(ovaPath, name, dest string) error {
+	found := false
+	err := walkArchive(ovaPath, func(entry string, r io.Reader) (bool, error) {
+		if entry != name {
+			return false, nil
+		}
+		found = true
+		out, err := os.Create(filepath.Clean(dest))
+		if err != nil {
+			return true, err
+		}
+		if _, err := io.Copy(out, r); err != nil {
+			out.Close()
+			return true, errors.Wrapf(err, "failed to extract %s", name)
+		}
+		return true, out.Close()
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.Errorf("%s not found in %s", name, ovaPath)
+	}
+	return nil
+}
+
+// walkArchive calls fn with the base name and content of every regular file in
+// the archive until fn reports it is done
+func walkArchive(ovaPath string, fn func(name string, r io.Reader) (bool, error)) error {
+	f, err := os.Open(filepath.Clean(ovaPath))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := tar.NewReader(f)
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %v", err)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		done, err := fn(filepath.Base(h.Name), r)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// ParseDescriptor reads an OVF descriptor
+func ParseDescriptor(r io.Reader) (*Appliance, error) {
+	env, err := ovf.Unmarshal(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse OVF descriptor")
+	}
+	if env.VirtualSystem == nil {
+		return nil, errors.New("OVF descriptor has no VirtualSystem, multi-VM appliances are not supported")
+	}
+	vs := env.VirtualSystem
+
+	appliance := &Appliance{Name: vs.ID}
+	if vs.Name != nil && *vs.Name != "" {
+		appliance.Name = *vs.Name
+	}
+	if vs.OperatingSystem != nil {
+		appliance.OSFamily = osFamily(vs.OperatingSystem)
+	}
+
+	files := map[string]ovf.File{}
+	for _, f := range env.References {
+		files[f.ID] = f
+	}
+
+	for _, hw := range vs.VirtualHardware {
+		for _, configs := range [][]ovf.Config{hw.Config, hw.ExtraConfig} {
+			for _, c := range configs {
+				if c.Key == "firmware" && c.Value == "efi" {
+					appliance.UEFI = true
+				}
+			}
+		}
+		for _, item := range hw.Item {
+			if item.ResourceType == nil {
+				continue
+			}
+			switch *item.ResourceType {
+			case ovf.Processor:
+				if item.VirtualQuantity != nil {
+					appliance.CPU = int(*item.VirtualQuantity)
+				}
+			case ovf.Memory:
+				if item.VirtualQuantity != nil {
+					units := "byte * 2^20"
+					if item.AllocationUnits != nil {
+						units = *item.AllocationUnits
+					}
+					appliance.MemoryMB = int(int64(*item.VirtualQuantity) * ovf.ParseCapacityAllocationUnits(units) / (1 << 20))
+				}
+			case ovf.EthernetAdapter:
+				nic := NIC{}
+				if len(item.Connection) > 0 {
+					nic.Network = item.Connection[0]
+				}
+				if item.Address != nil {
+					nic.MAC = strings.ToLower(*item.Address)
+				}
+				appliance.NICs = append(appliance.NICs, nic)
+			}
+		}
+	}
+
+	if env.Disk == nil || len(env.Disk.Disks) == 0 {
+		return nil, errors.New("OVF descriptor has no disks")
+	}
+	for _, d := range env.Disk.Disks {
+		if d.ParentRef != nil && *d.ParentRef != "" {
+			return nil, errors.Errorf("disk %s is a delta disk, only appliances without snapshots are supported", d.DiskID)
+		}
+		if d.FileRef == nil {
+			return nil, errors.Errorf("disk %s has no backing file", d.DiskID)
+		}
+		file, ok := files[*d.FileRef]
+		if !ok {
+			return nil, errors.Errorf("disk %s references unknown file %s", d.DiskID, *d.FileRef)
+		}
+		if file.Compression != nil && *file.Compression != "" {
+			return nil, errors.Errorf("file %s is %s compressed, decompress the appliance first", file.Href, *file.Compression)
+		}
+		if file.ChunkSize != nil && *file.ChunkSize > 0 {
+			return nil, errors.Errorf("file %s is split into chunks, which is not supported", file.Href)
+		}
+		capacity, err := strconv.ParseInt(d.Capacity, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid capacity %q for disk %s", d.Capacity, d.DiskID)
+		}
+		units := "byte"
+		if d.CapacityAllocationUnits != nil {
+			units = *d.CapacityAllocationUnits
+		}
+		appliance.Disks = append(appliance.Disks, Disk{
+			ID:       d.DiskID,
+			Href:     file.Href,
+			Capacity: capacity * ovf.ParseCapacityAllocationUnits(units),
+		})
+	}
+	return appliance, nil
+}
+
+// osFamily maps the OVF operating system section to windowsGuest or linuxGuest
+func osFamily(section *ovf.OperatingSystemSection) string {
+	desc := ""
+	if section.OSType != nil {
+		desc = *section.OSType
+	}
+	if section.Description != nil {
+		desc += " " + *section.Description
+	}
+	desc = strings.ToLower(strings.TrimSpace(desc))
+	switch {
+	case desc == "":
+		return ""
+	case strings.Contains(desc, "windows"):
+		return osFamilyWindows
+	case strings.Contains(desc, "other") && !strings.Contains(desc, "linux"):
+		return ""
+	default:
+		return osFamilyLinux
+	}
+}
@@ -0,0 +1,260 @@
+package ova
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDescriptor = `<?xml version="1.0" encoding="UTF-8"?>
+<Envelope vmw:buildId="build-123" xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:cim="http://schemas.dmtf.org/wbem/wscim/1/common" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1" xmlns:rasd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData" xmlns:vmw="http://www.vmware.com/schema/ovf" xmlns:vssd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_VirtualSystemSettingData" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <References>
+    <File ovf:href="appliance-disk1.vmdk" ovf:id="file1" ovf:size="1024"/>
+    <File ovf:href="appliance-disk2.vmdk" ovf:id="file2" ovf:size="512"/>
+  </References>
+  <DiskSection>
+    <Info>Virtual disk information</Info>
+    <Disk ovf:capacity="16" ovf:capacityAllocationUnits="byte * 2^30" ovf:diskId="vmdisk1" ovf:fileRef="file1" ovf:format="http://www.vmware.com/interfaces/specifications/vmdk.html#streamOptimized"/>
+    <Disk ovf:capacity="1073741824" ovf:diskId="vmdisk2" ovf:fileRef="file2" ovf:format="http://www.vmware.com/interfaces/specifications/vmdk.html#streamOptimized"/>
+  </DiskSection>
+  <NetworkSection>
+    <Info>The list of logical networks</Info>
+    <Network ovf:name="Management">
+      <Description>The Management network</Description>
+    </Network>
+  </NetworkSection>
+  <VirtualSystem ovf:id="vendor-appliance">
+    <Info>A virtual machine</Info>
+    <Name>vendor-appliance</Name>
+    <OperatingSystemSection ovf:id="80" vmw:osType="rhel8_64Guest">
+      <Info>The kind of installed guest operating system</Info>
+    </OperatingSystemSection>
+    <VirtualHardwareSection>
+      <Info>Virtual hardware requirements</Info>
+      <System>
+        <vssd:ElementName>Virtual Hardware Family</vssd:ElementName>
+        <vssd:InstanceID>0</vssd:InstanceID>
+        <vssd:VirtualSystemType>vmx-19</vssd:VirtualSystemType>
+      </System>
+      <Item>
+        <rasd:AllocationUnits>hertz * 10^6</rasd:AllocationUnits>
+        <rasd:ElementName>4 virtual CPU(s)</rasd:ElementName>
+        <rasd:InstanceID>1</rasd:InstanceID>
+        <rasd:ResourceType>3</rasd:ResourceType>
+        <rasd:VirtualQuantity>4</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:AllocationUnits>byte * 2^20</rasd:AllocationUnits>
+        <rasd:ElementName>8192MB of memory</rasd:ElementName>
+        <rasd:InstanceID>2</rasd:InstanceID>
+        <rasd:ResourceType>4</rasd:ResourceType>
+        <rasd:VirtualQuantity>8192</rasd:VirtualQuantity>
+      </Item>
+      <Item>
+        <rasd:AddressOnParent>0</rasd:AddressOnParent>
+        <rasd:ElementName>Hard disk 1</rasd:ElementName>
+        <rasd:HostResource>ovf:/disk/vmdisk1</rasd:HostResource>
+        <rasd:InstanceID>8</rasd:InstanceID>
+        <rasd:ResourceType>17</rasd:ResourceType>
+      </Item>
+      <Item>
+        <rasd:Address>00:50:56:AB:CD:EF</rasd:Address>
+        <rasd:AddressOnParent>7</rasd:AddressOnParent>
+        <rasd:Connection>Management</rasd:Connection>
+        <rasd:ElementName>Network adapter 1</rasd:ElementName>
+        <rasd:InstanceID>10</rasd:InstanceID>
+        <rasd:ResourceSubType>VmxNet3</rasd:ResourceSubType>
+        <rasd:ResourceType>10</rasd:ResourceType>
+      </Item>
+      <Item>
+        <rasd:AddressOnParent>8</rasd:AddressOnParent>
+        <rasd:Connection>Management</rasd:Connection>
+        <rasd:ElementName>Network adapter 2</rasd:ElementName>
+        <rasd:InstanceID>11</rasd:InstanceID>
+        <rasd:ResourceSubType>VmxNet3</rasd:ResourceSubType>
+        <rasd:ResourceType>10</rasd:ResourceType>
+      </Item>
+      <vmw:Config ovf:required="false" vmw:key="firmware" vmw:value="efi"/>
+    </VirtualHardwareSection>
+  </VirtualSystem>
+</Envelope>
+`
+
+func TestParseDescriptor(t *testing.T) {
+	appliance, err := ParseDescriptor(strings.NewReader(testDescriptor))
+	if err != nil {
+		t.Fatalf("ParseDescriptor() unexpected error: %v", err)
+	}
+	if appliance.Name != "vendor-appliance" {
+		t.Errorf("Name = %q, want vendor-appliance", appliance.Name)
+	}
+	if appliance.CPU != 4 || appliance.MemoryMB != 8192 {
+		t.Errorf("CPU/MemoryMB = %d/%d, want 4/8192", appliance.CPU, appliance.MemoryMB)
+	}
+	if !appliance.UEFI {
+		t.Error("UEFI = false, want true")
+	}
+	if appliance.OSFamily != osFamilyLinux {
+		t.Errorf("OSFamily = %q, want %q", appliance.OSFamily, osFamilyLinux)
+	}
+	wantDisks := []Disk{
+		{ID: "vmdisk1", Href: "appliance-disk1.vmdk", Capacity: 16 << 30},
+		{ID: "vmdisk2", Href: "appliance-disk2.vmdk", Capacity: 1 << 30},
+	}
+	if len(appliance.Disks) != len(wantDisks) {
+		t.Fatalf("got %d disks, want %d", len(appliance.Disks), len(wantDisks))
+	}
+	for i, want := range wantDisks {
+		if appliance.Disks[i] != want {
+			t.Errorf("Disks[%d] = %+v, want %+v", i, appliance.Disks[i], want)
+		}
+	}
+	wantNICs := []NIC{
+		{Network: "Management", MAC: "00:50:56:ab:cd:ef"},
+		{Network: "Management"},
+	}
+	if len(appliance.NICs) != len(wantNICs) {
+		t.Fatalf("got %d NICs, want %d", len(appliance.NICs), len(wantNICs))
+	}
+	for i, want := range wantNICs {
+		if appliance.NICs[i] != want {
+			t.Errorf("NICs[%d] = %+v, want %+v", i, appliance.NICs[i], want)
+		}
+	}
+}
+
+func TestParseDescriptorRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(string) string
+		wantErr string
+	}{
+		{
+			name: "compressed disk",
+			edit: func(s string) string {
+				return strings.Replace(s, `ovf:id="file1"`, `ovf:id="file1" ovf:compression="gzip"`, 1)
+			},
+			wantErr: "gzip compressed",
+		},
+		{
+			name: "delta disk",
+			edit: func(s string) string {
+				return strings.Replace(s, `ovf:diskId="vmdisk2"`, `ovf:diskId="vmdisk2" ovf:parentRef="vmdisk1"`, 1)
+			},
+			wantErr: "delta disk",
+		},
+		{
+			name: "unknown file reference",
+			edit: func(s string) string {
+				return strings.Replace(s, `ovf:fileRef="file2"`, `ovf:fileRef="file9"`, 1)
+			},
+			wantErr: "unknown file file9",
+		},
+		{
+			name: "no virtual system",
+			edit: func(s string) string {
+				start := strings.Index(s, "<VirtualSystem ")
+				end := strings.Index(s, "</VirtualSystem>") + len("</VirtualSystem>")
+				return s[:start] + s[end:]
+			},
+			wantErr: "no VirtualSystem",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDescriptor(strings.NewReader(tt.edit(testDescriptor)))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParseDescriptor() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOSFamily(t *testing.T) {
+	tests := []struct {
+		osType string
+		want   string
+	}{
+		{osType: "windows2019srv_64Guest", want: osFamilyWindows},
+		{osType: "ubuntu64Guest", want: osFamilyLinux},
+		{osType: "otherGuest64", want: ""},
+		{osType: "other4xLinux64Guest", want: osFamilyLinux},
+		{osType: "", want: ""},
+	}
+	for _, tt := range tests {
+		descriptor := strings.Replace(testDescriptor, "rhel8_64Guest", tt.osType, 1)
+		appliance, err := ParseDescriptor(strings.NewReader(descriptor))
+		if err != nil {
+			t.Fatalf("ParseDescriptor(%q) unexpected error: %v", tt.osType, err)
+		}
+		if appliance.OSFamily != tt.want {
+			t.Errorf("OSFamily for %q = %q, want %q", tt.osType, appliance.OSFamily, tt.want)
+		}
+	}
+}
+
+func writeTestOVA(t *testing.T, files map[string]string, order []string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "appliance.ova")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := tar.NewWriter(f)
+	for _, name := range order {
+		content := files[name]
+		if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadApplianceAndExtractFile(t *testing.T) {
+	files := map[string]string{
+		"appliance.ovf":        testDescriptor,
+		"appliance.mf":         "SHA256(appliance.ovf)= 00",
+		"appliance-disk1.vmdk": "disk one",
+		"appliance-disk2.vmdk": "disk two",
+	}
+	ovaPath := writeTestOVA(t, files, []string{"appliance.ovf", "appliance.mf", "appliance-disk1.vmdk", "appliance-disk2.vmdk"})
+
+	entry, err := FindDescriptorEntry(ovaPath)
+	if err != nil || entry != "appliance.ovf" {
+		t.Fatalf("FindDescriptorEntry() = %q, %v; want appliance.ovf", entry, err)
+	}
+
+	appliance, err := ReadAppliance(ovaPath)
+	if err != nil {
+		t.Fatalf("ReadAppliance() unexpected error: %v", err)
+	}
+	if len(appliance.Disks) != 2 {
+		t.Fatalf("got %d disks, want 2", len(appliance.Disks))
+	}
+
+	dest := filepath.Join(t.TempDir(), "disk2.vmdk")
+	if err := ExtractFile(ovaPath, appliance.Disks[1].Href, dest); err != nil {
+		t.Fatalf("ExtractFile() unexpected error: %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "disk two" {
+		t.Errorf("extracted %q, want %q", got, "disk two")
+	}
+
+	if err := ExtractFile(ovaPath, "missing.vmdk", dest); err == nil {
+		t.Error("ExtractFile() of a missing member succeeded, want error")
+	}
+}
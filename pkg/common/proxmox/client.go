@@ -0,0 +1,220 @@
+package proxmox
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// VMStatusStopped is the status of a VM that is powered off
+const VMStatusStopped = "stopped"
+
+// Client is a minimal client of the Proxmox VE REST API, authenticated with an
+// API token so no ticket has to be renewed during a long migration
+type Client struct {
+	baseURL    string
+	auth       string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the API at apiURL, e.g. https://pve01.example.com:8006.
+// tokenID is the full token id such as root@pam!vjailbreak.
+func NewClient(apiURL, tokenID, tokenSecret string, insecure bool) (*Client, error) {
+	u, err := url.Parse(strings.TrimSpace(apiURL))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid Proxmox API URL %q", apiURL)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, errors.Errorf("Proxmox API URL %q must be an http(s) URL", apiURL)
+	}
+	if tokenID == "" || tokenSecret == "" {
+		return nil, errors.New("Proxmox API token id and secret must be set")
+	}
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/api2/json")
+
+	return &Client{
+		baseURL: strings.TrimSuffix(u.String(), "/") + "/api2/json",
+		auth:    fmt.Sprintf("PVEAPIToken=%s=%s", tokenID, tokenSecret),
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				//nolint:gosec // Proxmox VE ships with a self-signed certificate, verification is opt-out per ProxmoxCreds
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+			},
+		},
+	}, nil
+}
+
+// get decodes the data member of the response to GET path into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	reqURL := c.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build request for %s", path)
+	}
+	req.Header.Set("Authorization", c.auth)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "GET %s failed", path)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read response of %s", path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// The API puts the reason in the status line, e.g. "401 authentication failure"
+		return errors.Errorf("GET %s returned %s", path, resp.Status)
+	}
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return errors.Wrapf(err, "failed to decode response of %s", path)
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return errors.Wrapf(err, "failed to decode data of %s", path)
+	}
+	return nil
+}
+
+// Version returns the Proxmox VE version, e.g. 8.2.4
+func (c *Client) Version(ctx context.Context) (string, error) {
+	v := struct {
+		Version string `json:"version"`
+	}{}
+	if err := c.get(ctx, "/version", nil, &v); err != nil {
+		return "", err
+	}
+	return v.Version, nil
+}
+
+// VMResource is a QEMU VM as listed by /cluster/resources
+type VMResource struct {
+	VMID     int    `json:"vmid"`
+	Name     string `json:"name"`
+	Node     string `json:"node"`
+	Status   string `json:"status"`
+	Type     string `json:"type"`
+	Template int    `json:"template"`
+}
+
+// ListVMs returns the QEMU VMs of the cluster, containers and templates excluded
+func (c *Client) ListVMs(ctx context.Context) ([]VMResource, error) {
+	var resources []VMResource
+	if err := c.get(ctx, "/cluster/resources", url.Values{"type": {"vm"}}, &resources); err != nil {
+		return nil, err
+	}
+	vms := make([]VMResource, 0, len(resources))
+	for _, r := range resources {
+		if r.Type == "qemu" && r.Template == 0 {
+			vms = append(vms, r)
+		}
+	}
+	return vms, nil
+}
+
+// FindVM returns the VM with the given id
+func (c *Client) FindVM(ctx context.Context, vmid int) (*VMResource, error) {
+	vms, err := c.ListVMs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vms {
+		if vms[i].VMID == vmid {
+			return &vms[i], nil
+		}
+	}
+	return nil, errors.Errorf("VM %d not found", vmid)
+}
+
+// VMConfig returns the parsed configuration of a VM
+func (c *Client) VMConfig(ctx context.Context, node string, vmid int) (*VM, error) {
+	var raw map[string]any
+	if err := c.get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", url.PathEscape(node), vmid), nil, &raw); err != nil {
+		return nil, err
+	}
+	config := make(map[string]string, len(raw))
+	for k, v := range raw {
+		// Numbers come back as JSON numbers or strings depending on the PVE version
+		if f, ok := v.(float64); ok {
+			config[k] = strconv.FormatFloat(f, 'f', -1, 64)
+			continue
+		}
+		config[k] = fmt.Sprint(v)
+	}
+	return ParseVMConfig(vmid, node, config)
+}
+
+// VMStatus returns the current status of a VM, e.g. running or stopped
+func (c *Client) VMStatus(ctx context.Context, node string, vmid int) (string, error) {
+	s := struct {
+		Status string `json:"status"`
+	}{}
+	if err := c.get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/status/current", url.PathEscape(node), vmid), nil, &s); err != nil {
+		return "", err
+	}
+	return s.Status, nil
+}
+
+// Volume locates a disk volume on its node
+type Volume struct {
+	// Path is what qemu-nbd opens on the node, a file, a device or an rbd: URI
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	Size   int64  `json:"size"`
+}
+
+// VolumeInfo returns the path, format and size of a volume such as local-lvm:vm-100-disk-0
+func (c *Client) VolumeInfo(ctx context.Context, node, volid string) (*Volume, error) {
+	storage, _, ok := strings.Cut(volid, ":")
+	if !ok {
+		return nil, errors.Errorf("volume %q has no storage", volid)
+	}
+	v := &Volume{}
+	path := fmt.Sprintf("/nodes/%s/storage/%s/content/%s", url.PathEscape(node), url.PathEscape(storage), url.PathEscape(volid))
+	if err := c.get(ctx, path, nil, v); err != nil {
+		return nil, err
+	}
+	if v.Path == "" {
+		return nil, errors.Errorf("storage %s has no path for volume %s", storage, volid)
+	}
+	if v.Format == "" {
+		v.Format = "raw"
+	}
+	return v, nil
+}
+
+// NodeAddress returns the address of a cluster node, falling back to its name
+func (c *Client) NodeAddress(ctx context.Context, node string) (string, error) {
+	var entries []struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+		IP   string `json:"ip"`
+	}
+	if err := c.get(ctx, "/cluster/status", nil, &entries); err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		if e.Type == "node" && e.Name == node && e.IP != "" {
+			return e.IP, nil
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,114 @@
+package proxmox
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testTokenAuth = "PVEAPIToken=root@pam!vjailbreak=5e3c1d2a"
+
+// newPVEServer stands in for the Proxmox VE API, serving responses by request path
+func newPVEServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != testTokenAuth {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":` + body + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient(t *testing.T) {
+	server := newPVEServer(t, map[string]string{
+		"/api2/json/version": `{"version":"8.2.4","release":"8.2"}`,
+		"/api2/json/cluster/resources": `[
+			{"type":"qemu","vmid":100,"name":"web01","node":"pve1","status":"stopped","template":0},
+			{"type":"qemu","vmid":9000,"name":"debian-tmpl","node":"pve1","status":"stopped","template":1},
+			{"type":"lxc","vmid":200,"name":"ct01","node":"pve2","status":"running"}
+		]`,
+		"/api2/json/nodes/pve1/qemu/100/config":                                   `{"name":"web01","cores":2,"memory":"2048","scsi0":"local-lvm:vm-100-disk-0,size=32G","net0":"virtio=BC:24:11:00:00:01,bridge=vmbr0"}`,
+		"/api2/json/nodes/pve1/qemu/100/status/current":                           `{"status":"stopped","vmid":100}`,
+		"/api2/json/nodes/pve1/storage/local-lvm/content/local-lvm:vm-100-disk-0": `{"path":"/dev/pve/vm-100-disk-0","format":"raw","size":34359738368}`,
+		"/api2/json/cluster/status":                                               `[{"type":"cluster","name":"lab"},{"type":"node","name":"pve1","ip":"10.0.0.11"}]`,
+	})
+	ctx := context.Background()
+
+	c, err := NewClient(server.URL+"/api2/json/", "root@pam!vjailbreak", "5e3c1d2a", false)
+	if err != nil {
+		t.Fatalf("NewClient() unexpected error: %v", err)
+	}
+	if v, err := c.Version(ctx); err != nil || v != "8.2.4" {
+		t.Errorf("Version() = %q, %v, want 8.2.4", v, err)
+	}
+
+	vms, err := c.ListVMs(ctx)
+	if err != nil {
+		t.Fatalf("ListVMs() unexpected error: %v", err)
+	}
+	if len(vms) != 1 || vms[0].VMID != 100 || vms[0].Node != "pve1" {
+		t.Errorf("ListVMs() = %+v, want only VM 100", vms)
+	}
+	if _, err := c.FindVM(ctx, 200); err == nil {
+		t.Error("FindVM(200) found a container, want an error")
+	}
+
+	vm, err := c.VMConfig(ctx, "pve1", 100)
+	if err != nil {
+		t.Fatalf("VMConfig() unexpected error: %v", err)
+	}
+	if vm.CPU != 2 || vm.MemoryMB != 2048 || len(vm.Disks) != 1 || len(vm.NICs) != 1 {
+		t.Errorf("VMConfig() = %+v, want 2 CPU, 2048 MB, one disk and one NIC", vm)
+	}
+
+	if status, err := c.VMStatus(ctx, "pve1", 100); err != nil || status != VMStatusStopped {
+		t.Errorf("VMStatus() = %q, %v, want stopped", status, err)
+	}
+
+	volume, err := c.VolumeInfo(ctx, "pve1", vm.Disks[0].Volume)
+	if err != nil {
+		t.Fatalf("VolumeInfo() unexpected error: %v", err)
+	}
+	if *volume != (Volume{Path: "/dev/pve/vm-100-disk-0", Format: "raw", Size: 32 << 30}) {
+		t.Errorf("VolumeInfo() = %+v", volume)
+	}
+
+	if addr, err := c.NodeAddress(ctx, "pve1"); err != nil || addr != "10.0.0.11" {
+		t.Errorf("NodeAddress(pve1) = %q, %v, want 10.0.0.11", addr, err)
+	}
+	if addr, err := c.NodeAddress(ctx, "pve9"); err != nil || addr != "pve9" {
+		t.Errorf("NodeAddress(pve9) = %q, %v, want the node name", addr, err)
+	}
+}
+
+func TestClientAuthFailure(t *testing.T) {
+	server := newPVEServer(t, map[string]string{"/api2/json/version": `{"version":"8.2.4"}`})
+
+	c, err := NewClient(server.URL, "root@pam!vjailbreak", "wrong", false)
+	if err != nil {
+		t.Fatalf("NewClient() unexpected error: %v", err)
+	}
+	if _, err := c.Version(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Version() error = %v, want a 401 error", err)
+	}
+}
+
+func TestNewClientValidation(t *testing.T) {
+	if _, err := NewClient("pve01.example.com:8006", "root@pam!vjailbreak", "secret", false); err == nil {
+		t.Error("NewClient() accepted a URL without scheme")
+	}
+	if _, err := NewClient("https://pve01.example.com:8006", "", "", false); err == nil {
+		t.Error("NewClient() accepted an empty token")
+	}
+}
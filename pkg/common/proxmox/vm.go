@@ -0,0 +1,234 @@
+package proxmox
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BusVirtio is the bus of a virtioN disk
+const BusVirtio = "virtio"
+
+// VM is the part of a Proxmox VE VM configuration a migration needs
+type VM struct {
+	VMID     int
+	Name     string
+	Node     string
+	CPU      int
+	MemoryMB int
+	UEFI     bool
+	// OSType is the Proxmox ostype, e.g. l26 or win11
+	OSType string
+	// OSFamily is windowsGuest or linuxGuest, empty when OSType does not say
+	OSFamily string
+	// Disks are in boot order, the boot disk first
+	Disks []Disk
+	NICs  []NIC
+}
+
+// Disk is a disk of a VM
+type Disk struct {
+	// Key is the config key, e.g. scsi0
+	Key string
+	// Volume is the volume id, e.g. local-lvm:vm-100-disk-0
+	Volume string
+	// SizeBytes is the size recorded in the config
+	SizeBytes int64
+	// Bus is ide, sata, scsi or virtio
+	Bus string
+}
+
+// NIC is a network device of a VM
+type NIC struct {
+	Key    string
+	Model  string
+	MAC    string
+	Bridge string
+}
+
+var (
+	diskKeyRE = regexp.MustCompile(`^(ide|sata|scsi|virtio)(\d+)$`)
+	netKeyRE  = regexp.MustCompile(`^net(\d+)$`)
+	// busOrder sorts the disks that are not the boot disk
+	busOrder = map[string]int{"virtio": 0, "scsi": 1, "sata": 2, "ide": 3}
+)
+
+// AllDisksVirtio reports whether the guest already boots from virtio-blk disks
+func (vm *VM) AllDisksVirtio() bool {
+	for _, d := range vm.Disks {
+		if d.Bus != BusVirtio {
+			return false
+		}
+	}
+	return len(vm.Disks) > 0
+}
+
+// ParseVMConfig parses the key/value configuration returned by
+// /nodes/{node}/qemu/{vmid}/config. CD-ROMs are skipped, physical disks passed
+// through to the guest are rejected since they live outside any storage.
+func ParseVMConfig(vmid int, node string, config map[string]string) (*VM, error) {
+	vm := &VM{
+		VMID:     vmid,
+		Name:     config["name"],
+		Node:     node,
+		CPU:      1,
+		MemoryMB: 512,
+		UEFI:     config["bios"] == "ovmf",
+		OSType:   config["ostype"],
+		OSFamily: osFamily(config["ostype"]),
+	}
+	if vm.Name == "" {
+		vm.Name = "VM " + strconv.Itoa(vmid)
+	}
+	if cores, err := strconv.Atoi(config["cores"]); err == nil && cores > 0 {
+		vm.CPU = cores
+	}
+	if sockets, err := strconv.Atoi(config["sockets"]); err == nil && sockets > 0 {
+		vm.CPU *= sockets
+	}
+	if memory, err := strconv.Atoi(config["memory"]); err == nil && memory > 0 {
+		vm.MemoryMB = memory
+	}
+
+	for key, value := range config {
+		if m := diskKeyRE.FindStringSubmatch(key); m != nil {
+			disk, skip, err := parseDisk(key, m[1], value)
+			if err != nil {
+				return nil, err
+			}
+			if !skip {
+				vm.Disks = append(vm.Disks, disk)
+			}
+			continue
+		}
+		if netKeyRE.MatchString(key) {
+			vm.NICs = append(vm.NICs, parseNIC(key, value))
+		}
+	}
+	sort.Slice(vm.NICs, func(i, j int) bool { return keyIndex(vm.NICs[i].Key) < keyIndex(vm.NICs[j].Key) })
+	sortDisks(vm.Disks, bootDisk(config, vm.Disks))
+	return vm, nil
+}
+
+func parseDisk(key, bus, value string) (Disk, bool, error) {
+	volume, opts := splitOptions(value)
+	if opts["media"] == "cdrom" {
+		return Disk{}, true, nil
+	}
+	if strings.HasPrefix(volume, "/") {
+		return Disk{}, false, errors.Errorf("disk %s is the passed through device %s, which cannot be migrated", key, volume)
+	}
+	disk := Disk{Key: key, Volume: volume, Bus: bus}
+	if size, ok := opts["size"]; ok {
+		bytes, err := parseSize(size)
+		if err != nil {
+			return Disk{}, false, errors.Wrapf(err, "disk %s", key)
+		}
+		disk.SizeBytes = bytes
+	}
+	return disk, false, nil
+}
+
+// parseNIC parses e.g. virtio=BC:24:11:AA:BB:CC,bridge=vmbr0,firewall=1, the
+// first option being the model and MAC
+func parseNIC(key, value string) NIC {
+	nic := NIC{Key: key}
+	for idx, part := range strings.Split(value, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch {
+		case idx == 0:
+			nic.Model, nic.MAC = k, strings.ToLower(v)
+		case k == "bridge":
+			nic.Bridge = v
+		}
+	}
+	return nic
+}
+
+// bootDisk returns the first of disks in the boot order, from either
+// "boot: order=ide2;scsi0;net0" or the legacy "bootdisk: scsi0"
+func bootDisk(config map[string]string, disks []Disk) string {
+	order := []string{config["bootdisk"]}
+	if o, ok := strings.CutPrefix(config["boot"], "order="); ok {
+		order = strings.Split(o, ";")
+	}
+	for _, dev := range order {
+		for _, d := range disks {
+			if d.Key == dev {
+				return dev
+			}
+		}
+	}
+	return ""
+}
+
+// sortDisks puts the boot disk first, the volume the instance boots from, and
+// the others by bus and index
+func sortDisks(disks []Disk, boot string) {
+	sort.Slice(disks, func(i, j int) bool {
+		a, b := disks[i], disks[j]
+		if (a.Key == boot) != (b.Key == boot) {
+			return a.Key == boot
+		}
+		if a.Bus != b.Bus {
+			return busOrder[a.Bus] < busOrder[b.Bus]
+		}
+		return keyIndex(a.Key) < keyIndex(b.Key)
+	})
+}
+
+func keyIndex(key string) int {
+	idx, _ := strconv.Atoi(strings.TrimLeft(key, "abcdefghijklmnopqrstuvwxyz"))
+	return idx
+}
+
+// splitOptions splits "local-lvm:vm-100-disk-0,size=32G" into the volume and its options
+func splitOptions(value string) (string, map[string]string) {
+	parts := strings.Split(value, ",")
+	opts := map[string]string{}
+	for _, part := range parts[1:] {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			opts[k] = v
+		}
+	}
+	return parts[0], opts
+}
+
+// parseSize parses a disk size such as 32G, bytes when there is no unit
+func parseSize(size string) (int64, error) {
+	multiplier := int64(1)
+	if n := len(size); n > 0 {
+		switch size[n-1] {
+		case 'K', 'k':
+			multiplier = 1 << 10
+		case 'M', 'm':
+			multiplier = 1 << 20
+		case 'G', 'g':
+			multiplier = 1 << 30
+		case 'T', 't':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			size = size[:n-1]
+		}
+	}
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil || value < 0 {
+		return 0, errors.Errorf("invalid size %q", size)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// osFamily maps the Proxmox ostype to the OS family names used for VMware VMs
+func osFamily(ostype string) string {
+	switch {
+	case ostype == "l24" || ostype == "l26":
+		return "linuxGuest"
+	case strings.HasPrefix(ostype, "w"):
+		return "windowsGuest"
+	}
+	return ""
+}
@@ -0,0 +1,104 @@
+package proxmox
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVMConfig(t *testing.T) {
+	vm, err := ParseVMConfig(100, "pve1", map[string]string{
+		"name":     "web01",
+		"cores":    "2",
+		"sockets":  "2",
+		"memory":   "4096",
+		"bios":     "ovmf",
+		"ostype":   "l26",
+		"boot":     "order=ide2;scsi1;net0",
+		"scsihw":   "virtio-scsi-single",
+		"scsi0":    "local-lvm:vm-100-disk-1,iothread=1,size=100G",
+		"scsi1":    "local-lvm:vm-100-disk-0,size=32G",
+		"virtio0":  "ceph:vm-100-disk-2,size=512M",
+		"ide2":     "local:iso/debian-12.iso,media=cdrom,size=628M",
+		"efidisk0": "local-lvm:vm-100-disk-3,efitype=4m,size=4M",
+		"net1":     "e1000=BC:24:11:00:00:02,bridge=vmbr1",
+		"net0":     "virtio=BC:24:11:00:00:01,bridge=vmbr0,firewall=1",
+	})
+	if err != nil {
+		t.Fatalf("ParseVMConfig() unexpected error: %v", err)
+	}
+	want := &VM{
+		VMID:     100,
+		Name:     "web01",
+		Node:     "pve1",
+		CPU:      4,
+		MemoryMB: 4096,
+		UEFI:     true,
+		OSType:   "l26",
+		OSFamily: "linuxGuest",
+		Disks: []Disk{
+			{Key: "scsi1", Volume: "local-lvm:vm-100-disk-0", SizeBytes: 32 << 30, Bus: "scsi"},
+			{Key: "virtio0", Volume: "ceph:vm-100-disk-2", SizeBytes: 512 << 20, Bus: "virtio"},
+			{Key: "scsi0", Volume: "local-lvm:vm-100-disk-1", SizeBytes: 100 << 30, Bus: "scsi"},
+		},
+		NICs: []NIC{
+			{Key: "net0", Model: "virtio", MAC: "bc:24:11:00:00:01", Bridge: "vmbr0"},
+			{Key: "net1", Model: "e1000", MAC: "bc:24:11:00:00:02", Bridge: "vmbr1"},
+		},
+	}
+	if !reflect.DeepEqual(vm, want) {
+		t.Errorf("ParseVMConfig() = %+v, want %+v", vm, want)
+	}
+	if vm.AllDisksVirtio() {
+		t.Error("AllDisksVirtio() = true for a VM with SCSI disks")
+	}
+}
+
+func TestParseVMConfigDefaults(t *testing.T) {
+	vm, err := ParseVMConfig(101, "pve2", map[string]string{
+		"ostype":   "win11",
+		"bootdisk": "virtio1",
+		"virtio0":  "local:101/vm-101-disk-0.qcow2,size=10G",
+		"virtio1":  "local:101/vm-101-disk-1.qcow2,size=60G",
+	})
+	if err != nil {
+		t.Fatalf("ParseVMConfig() unexpected error: %v", err)
+	}
+	if vm.Name != "VM 101" || vm.CPU != 1 || vm.MemoryMB != 512 || vm.UEFI || vm.OSFamily != "windowsGuest" {
+		t.Errorf("ParseVMConfig() = %+v, want the Proxmox defaults", vm)
+	}
+	if len(vm.Disks) != 2 || vm.Disks[0].Key != "virtio1" {
+		t.Errorf("Disks = %+v, want the legacy boot disk virtio1 first", vm.Disks)
+	}
+	if !vm.AllDisksVirtio() {
+		t.Error("AllDisksVirtio() = false for a VM with only virtio disks")
+	}
+}
+
+func TestParseVMConfigRejectsPassthrough(t *testing.T) {
+	_, err := ParseVMConfig(102, "pve1", map[string]string{
+		"scsi0": "/dev/disk/by-id/ata-SAMSUNG_SSD,size=931G",
+	})
+	if err == nil || !strings.Contains(err.Error(), "passed through") {
+		t.Errorf("ParseVMConfig() error = %v, want a passthrough error", err)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"32G":   32 << 30,
+		"1.5T":  3 << 39,
+		"4M":    4 << 20,
+		"512K":  512 << 10,
+		"65536": 65536,
+	}
+	for in, want := range tests {
+		got, err := parseSize(in)
+		if err != nil || got != want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+	if _, err := parseSize("lots"); err == nil {
+		t.Error("parseSize(\"lots\") succeeded, want an error")
+	}
+}
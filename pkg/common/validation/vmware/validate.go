@@ -179,6 +179,18 @@ func Validate(ctx context.Context, k8sClient client.Client, vmwcreds *vjailbreak
 		}
 	}
 
+	// A standalone ESXi host reports the HostAgent API type, vCenter reports
+	// VirtualCenter. The esxi host type copies disks over SSH from the host
+	// itself, so it must not be pointed at a vCenter.
+	if vmwcreds.IsStandaloneESXi() && c.IsVC() {
+		err := fmt.Errorf("%s is a vCenter Server", host)
+		return ValidationResult{
+			Valid:   false,
+			Message: fmt.Sprintf("%s, set hostType to vcenter or point the credentials at a standalone ESXi host", err.Error()),
+			Error:   err,
+		}
+	}
+
 	// Check if the datacenter exists (only if datacenter is provided)
 	if datacenter != "" {
 		finder := find.NewFinder(c, false)
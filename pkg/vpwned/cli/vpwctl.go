@@ -6,7 +6,9 @@ import (
 	"strings"
 
 	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers/base"
+	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers/ironic"
 	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers/maas"
+	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers/redfish"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -1,14 +1,209 @@
 package ironic
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	api "github.com/platform9/vjailbreak/pkg/vpwned/api/proto/v1/service"
 	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers"
 	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers/base"
+	"github.com/sirupsen/logrus"
 )
 
+const (
+	IronicProviderName = "ironic"
+)
+
+// IronicProvider implements the Provider interface for OpenStack Ironic.
+// APIKey is sent as a keystone token (X-Auth-Token); without it Username and
+// Password are used for HTTP basic auth, and with neither the noauth mode of
+// a standalone Ironic is assumed.
 type IronicProvider struct {
 	base.UnimplementedBaseProvider
+	client *IronicClient
+}
+
+// Connect establishes a connection to the Ironic API
+func (p *IronicProvider) Connect(auth providers.BMAccessInfo) error {
+	client, err := NewIronicClient(IronicAccessInfo{
+		BaseURL:     auth.BaseURL,
+		AuthToken:   auth.APIKey,
+		Username:    auth.Username,
+		Password:    auth.Password,
+		UseInsecure: auth.UseInsecure,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create ironic client")
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		return errors.Wrap(err, "failed to connect to ironic")
+	}
+	p.client = client
+	return nil
+}
+
+func (p *IronicProvider) connectFromAccessInfo(accessInfo *api.BMProvisionerAccessInfo) error {
+	if p.client != nil {
+		return nil
+	}
+	if accessInfo == nil {
+		return errors.New("client not initialized")
+	}
+	return p.Connect(providers.BMAccessInfo{
+		BaseURL:     accessInfo.BaseUrl,
+		APIKey:      accessInfo.ApiKey,
+		Username:    accessInfo.Username,
+		Password:    accessInfo.Password,
+		UseInsecure: accessInfo.UseInsecure,
+	})
+}
+
+func (p *IronicProvider) Disconnect() error {
+	return nil
+}
+
+func (p *IronicProvider) WhoAmI() string {
+	return IronicProviderName
+}
+
+// ListResources retrieves a list of nodes
+func (p *IronicProvider) ListResources(ctx context.Context) ([]api.MachineInfo, error) {
+	if p.client == nil {
+		return nil, errors.New("client not initialized")
+	}
+	return p.client.ListMachines(ctx)
+}
+
+// GetResourceInfo retrieves information about a node
+func (p *IronicProvider) GetResourceInfo(ctx context.Context, resourceID string) (api.MachineInfo, error) {
+	if p.client == nil {
+		return api.MachineInfo{}, errors.New("client not initialized")
+	}
+	return p.client.GetMachine(ctx, resourceID)
+}
+
+// SetResourcePower changes the power state of a node
+func (p *IronicProvider) SetResourcePower(ctx context.Context, resourceID string, action api.PowerStatus) error {
+	if p.client == nil {
+		return errors.New("client not initialized")
+	}
+	var target string
+	switch action {
+	case api.PowerStatus_POWERED_ON:
+		target = PowerStateOn
+	case api.PowerStatus_POWERED_OFF:
+		target = PowerStateOff
+	default:
+		return fmt.Errorf("unsupported power action: %v", action)
+	}
+	if err := p.client.SetPowerState(ctx, resourceID, target); err != nil {
+		logrus.Errorf("Failed to change power state: %v", err)
+		return err
+	}
+	logrus.Infof("Node %s requested %s", resourceID, target)
+	return nil
+}
+
+// SetBM2PXEBoot sets a one-time PXE boot and optionally power cycles the node.
+// ipmi_interface is ignored, Ironic talks to the BMC through the node's own driver.
+func (p *IronicProvider) SetBM2PXEBoot(ctx context.Context, resourceID string, power_cycle bool, ipmi_interface *api.IpmiType) error {
+	if p.client == nil {
+		return errors.New("client not initialized")
+	}
+	node, err := p.client.GetNode(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+	if err := p.client.SetBootDevice(ctx, resourceID, "pxe", false); err != nil {
+		return err
+	}
+	if power_cycle && node.PowerState == PowerStateOn {
+		err = p.client.SetPowerState(ctx, resourceID, "rebooting")
+	} else if node.PowerState != PowerStateOn {
+		err = p.client.SetPowerState(ctx, resourceID, PowerStateOn)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to power node %s", resourceID)
+	}
+	logrus.Infof("Successfully set node %s to PXE boot", resourceID)
+	return nil
+}
+
+func (p *IronicProvider) DeployMachine(ctx context.Context, req api.DeployMachineRequest) (api.DeployMachineResponse, error) {
+	if err := p.connectFromAccessInfo(req.AccessInfo); err != nil {
+		return api.DeployMachineResponse{}, errors.Wrap(err, "Deploy Machine Failed")
+	}
+	node, err := p.client.GetNode(ctx, req.ResourceId)
+	if err != nil {
+		return api.DeployMachineResponse{}, errors.Wrap(err, "Deploy Machine Failed")
+	}
+	if node.ProvisionState != ProvisionStateAvailable {
+		return api.DeployMachineResponse{}, errors.Errorf("Deploy Machine Failed: node %s is %s, expected %s",
+			req.ResourceId, node.ProvisionState, ProvisionStateAvailable)
+	}
+	if req.OsReleaseName != "" {
+		if err := p.client.SetImageSource(ctx, req.ResourceId, req.OsReleaseName); err != nil {
+			return api.DeployMachineResponse{}, errors.Wrap(err, "Deploy Machine Failed")
+		}
+	}
+	if err := p.client.SetProvisionState(ctx, req.ResourceId, ProvisionTargetActive, req.UserData); err != nil {
+		return api.DeployMachineResponse{}, errors.Wrap(err, "Deploy Machine Failed")
+	}
+	return api.DeployMachineResponse{Success: true}, nil
+}
+
+func (p *IronicProvider) ReclaimBM(ctx context.Context, req api.ReclaimBMRequest) error {
+	if err := p.connectFromAccessInfo(req.AccessInfo); err != nil {
+		return errors.Wrap(err, "Reclaim VM Failed")
+	}
+	return p.client.Reclaim(ctx, &req)
+}
+
+func (p *IronicProvider) ListBootSource(ctx context.Context, req api.ListBootSourceRequest) ([]api.BootsourceSelections, error) {
+	if err := p.connectFromAccessInfo(req.AccessInfo); err != nil {
+		return nil, errors.Wrap(err, "List Boot Source Failed")
+	}
+	return p.client.ListBootSources(ctx)
+}
+
+func (p *IronicProvider) IsBMReady(ctx context.Context, req api.IsBMReadyRequest) (api.IsBMReadyResponse, error) {
+	if p.client == nil {
+		return api.IsBMReadyResponse{}, errors.New("client not initialized")
+	}
+	node, err := p.client.GetNode(ctx, req.ResourceId)
+	if err != nil {
+		return api.IsBMReadyResponse{}, errors.Wrap(err, "IsBMReady Failed")
+	}
+	return api.IsBMReadyResponse{IsReady: node.ProvisionState == ProvisionStateAvailable}, nil
+}
+
+func (p *IronicProvider) IsBMRunning(ctx context.Context, req api.IsBMRunningRequest) (api.IsBMRunningResponse, error) {
+	if p.client == nil {
+		return api.IsBMRunningResponse{}, errors.New("client not initialized")
+	}
+	node, err := p.client.GetNode(ctx, req.ResourceId)
+	if err != nil {
+		return api.IsBMRunningResponse{}, errors.Wrap(err, "IsBMRunning Failed")
+	}
+	return api.IsBMRunningResponse{IsRunning: node.ProvisionState == ProvisionStateActive}, nil
+}
+
+func (p *IronicProvider) StartBM(ctx context.Context, req api.StartBMRequest) (api.StartBMResponse, error) {
+	if err := p.SetResourcePower(ctx, req.ResourceId, api.PowerStatus_POWERED_ON); err != nil {
+		return api.StartBMResponse{}, errors.Wrap(err, "StartBM Failed")
+	}
+	return api.StartBMResponse{Success: true}, nil
+}
+
+func (p *IronicProvider) StopBM(ctx context.Context, req api.StopBMRequest) (api.StopBMResponse, error) {
+	if err := p.SetResourcePower(ctx, req.ResourceId, api.PowerStatus_POWERED_OFF); err != nil {
+		return api.StopBMResponse{}, errors.Wrap(err, "StopBM Failed")
+	}
+	return api.StopBMResponse{Success: true}, nil
 }
 
 func init() {
-	providers.RegisterProvider("ironic", &IronicProvider{})
+	providers.RegisterProvider(IronicProviderName, &IronicProvider{client: nil})
 }
@@ -0,0 +1,546 @@
+package ironic
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	api "github.com/platform9/vjailbreak/pkg/vpwned/api/proto/v1/service"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// IronicAPIVersion is the microversion requested on every call. 1.56 is the
+	// first version that accepts a JSON configdrive carrying user_data.
+	IronicAPIVersion = "1.56"
+
+	// Ironic provision states used by the provider
+	ProvisionStateEnroll     = "enroll"
+	ProvisionStateManageable = "manageable"
+	ProvisionStateAvailable  = "available"
+	ProvisionStateActive     = "active"
+	ProvisionStateCleaning   = "cleaning"
+	ProvisionStateCleanWait  = "clean wait"
+	ProvisionStateDeployFail = "deploy failed"
+	ProvisionStateCleanFail  = "clean failed"
+	ProvisionStateError      = "error"
+
+	// Ironic provision state targets
+	ProvisionTargetManage  = "manage"
+	ProvisionTargetProvide = "provide"
+	ProvisionTargetActive  = "active"
+	ProvisionTargetDeleted = "deleted"
+
+	// Ironic power states
+	PowerStateOn  = "power on"
+	PowerStateOff = "power off"
+)
+
+// IronicAccessInfo contains credentials and connection details for Ironic
+type IronicAccessInfo struct {
+	BaseURL     string
+	AuthToken   string
+	Username    string
+	Password    string
+	UseInsecure bool
+}
+
+// IronicClient represents a client for interacting with the Ironic REST API
+type IronicClient struct {
+	BaseURL      string
+	AuthToken    string
+	Username     string
+	Password     string
+	HTTPClient   *http.Client
+	PollInterval time.Duration
+}
+
+// IronicNode is the subset of the Ironic node resource used by the provider
+type IronicNode struct {
+	UUID           string                 `json:"uuid"`
+	Name           string                 `json:"name"`
+	PowerState     string                 `json:"power_state"`
+	ProvisionState string                 `json:"provision_state"`
+	Maintenance    bool                   `json:"maintenance"`
+	LastError      string                 `json:"last_error"`
+	Driver         string                 `json:"driver"`
+	Description    string                 `json:"description"`
+	ResourceClass  string                 `json:"resource_class"`
+	ConductorGroup string                 `json:"conductor_group"`
+	BootInterface  string                 `json:"boot_interface"`
+	InstanceUUID   string                 `json:"instance_uuid"`
+	Properties     map[string]interface{} `json:"properties"`
+	InstanceInfo   map[string]interface{} `json:"instance_info"`
+	Extra          map[string]interface{} `json:"extra"`
+	Traits         []string               `json:"traits"`
+}
+
+type ironicNodeList struct {
+	Nodes []IronicNode `json:"nodes"`
+}
+
+// IronicPort is the subset of the Ironic port resource used by the provider
+type IronicPort struct {
+	UUID       string `json:"uuid"`
+	Address    string `json:"address"`
+	NodeUUID   string `json:"node_uuid"`
+	PXEEnabled bool   `json:"pxe_enabled"`
+}
+
+type ironicPortList struct {
+	Ports []IronicPort `json:"ports"`
+}
+
+type ironicError struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+// NewIronicClient creates a new Ironic API client
+func NewIronicClient(accessInfo IronicAccessInfo) (*IronicClient, error) {
+	baseURL := strings.TrimRight(accessInfo.BaseURL, "/")
+	baseURL = strings.TrimSuffix(baseURL, "/v1")
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return nil, errors.New("invalid base URL")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if accessInfo.UseInsecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // user opted in
+	}
+	return &IronicClient{
+		BaseURL:      baseURL,
+		AuthToken:    accessInfo.AuthToken,
+		Username:     accessInfo.Username,
+		Password:     accessInfo.Password,
+		HTTPClient:   &http.Client{Transport: transport, Timeout: 60 * time.Second},
+		PollInterval: 10 * time.Second,
+	}, nil
+}
+
+func (c *IronicClient) doRequest(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-OpenStack-Ironic-API-Version", IronicAPIVersion)
+	if body != nil {
+		if method == http.MethodPatch {
+			req.Header.Set("Content-Type", "application/json-patch+json")
+		} else {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	}
+	if c.AuthToken != "" {
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+	} else if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s failed", method, path)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response")
+	}
+	if resp.StatusCode >= 300 {
+		return errors.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, parseIronicError(respBody))
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return errors.Wrap(err, "failed to decode response")
+		}
+	}
+	return nil
+}
+
+// parseIronicError extracts the human readable message from an Ironic error body.
+// Ironic wraps the JSON error document inside the "error_message" string.
+func parseIronicError(body []byte) string {
+	var wrapped ironicError
+	if err := json.Unmarshal(body, &wrapped); err != nil || wrapped.ErrorMessage == "" {
+		return strings.TrimSpace(string(body))
+	}
+	var inner struct {
+		Faultstring string `json:"faultstring"`
+	}
+	if err := json.Unmarshal([]byte(wrapped.ErrorMessage), &inner); err == nil && inner.Faultstring != "" {
+		return inner.Faultstring
+	}
+	return wrapped.ErrorMessage
+}
+
+// Ping checks that the Ironic API is reachable and the credentials are accepted
+func (c *IronicClient) Ping(ctx context.Context) error {
+	return c.doRequest(ctx, http.MethodGet, "/v1/nodes?limit=1", nil, nil)
+}
+
+// ListNodes retrieves all nodes known to Ironic
+func (c *IronicClient) ListNodes(ctx context.Context) ([]IronicNode, error) {
+	var list ironicNodeList
+	if err := c.doRequest(ctx, http.MethodGet, "/v1/nodes/detail", nil, &list); err != nil {
+		return nil, errors.Wrap(err, "failed to list nodes")
+	}
+	return list.Nodes, nil
+}
+
+// GetNode retrieves a single node by UUID or name
+func (c *IronicClient) GetNode(ctx context.Context, nodeID string) (*IronicNode, error) {
+	var node IronicNode
+	if err := c.doRequest(ctx, http.MethodGet, "/v1/nodes/"+nodeID, nil, &node); err != nil {
+		return nil, errors.Wrap(err, "failed to get node")
+	}
+	return &node, nil
+}
+
+// ListPorts retrieves all ports known to Ironic
+func (c *IronicClient) ListPorts(ctx context.Context) ([]IronicPort, error) {
+	var list ironicPortList
+	if err := c.doRequest(ctx, http.MethodGet, "/v1/ports/detail", nil, &list); err != nil {
+		return nil, errors.Wrap(err, "failed to list ports")
+	}
+	return list.Ports, nil
+}
+
+// ListNodePorts retrieves the ports of a single node
+func (c *IronicClient) ListNodePorts(ctx context.Context, nodeID string) ([]IronicPort, error) {
+	var list ironicPortList
+	if err := c.doRequest(ctx, http.MethodGet, "/v1/nodes/"+nodeID+"/ports/detail", nil, &list); err != nil {
+		return nil, errors.Wrap(err, "failed to list node ports")
+	}
+	return list.Ports, nil
+}
+
+// SetPowerState requests a power state change for a node
+func (c *IronicClient) SetPowerState(ctx context.Context, nodeID, target string) error {
+	body := map[string]interface{}{"target": target}
+	if err := c.doRequest(ctx, http.MethodPut, "/v1/nodes/"+nodeID+"/states/power", body, nil); err != nil {
+		return errors.Wrap(err, "failed to set power state")
+	}
+	return nil
+}
+
+// SetBootDevice sets the boot device of a node
+func (c *IronicClient) SetBootDevice(ctx context.Context, nodeID, device string, persistent bool) error {
+	body := map[string]interface{}{"boot_device": device, "persistent": persistent}
+	if err := c.doRequest(ctx, http.MethodPut, "/v1/nodes/"+nodeID+"/management/boot_device", body, nil); err != nil {
+		return errors.Wrap(err, "failed to set boot device")
+	}
+	return nil
+}
+
+// SetProvisionState requests a provision state transition for a node.
+// userData is only sent with the "active" target, as the configdrive payload.
+func (c *IronicClient) SetProvisionState(ctx context.Context, nodeID, target, userData string) error {
+	body := map[string]interface{}{"target": target}
+	if target == ProvisionTargetActive && userData != "" {
+		body["configdrive"] = map[string]interface{}{"user_data": userData}
+	}
+	if err := c.doRequest(ctx, http.MethodPut, "/v1/nodes/"+nodeID+"/states/provision", body, nil); err != nil {
+		return errors.Wrapf(err, "failed to move node to %s", target)
+	}
+	return nil
+}
+
+// SetImageSource points the node's instance_info at the image to deploy
+func (c *IronicClient) SetImageSource(ctx context.Context, nodeID, imageSource string) error {
+	patch := []map[string]interface{}{
+		{"op": "add", "path": "/instance_info/image_source", "value": imageSource},
+	}
+	if err := c.doRequest(ctx, http.MethodPatch, "/v1/nodes/"+nodeID, patch, nil); err != nil {
+		return errors.Wrap(err, "failed to set image source")
+	}
+	return nil
+}
+
+// WaitForProvisionState polls the node until it reaches the desired provision state or times out
+func (c *IronicClient) WaitForProvisionState(ctx context.Context, nodeID, desiredState string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		node, err := c.GetNode(ctx, nodeID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get node status")
+		}
+		logrus.Debugf("Node %s current provision state: %s (waiting for %s)", nodeID, node.ProvisionState, desiredState)
+
+		if strings.EqualFold(node.ProvisionState, desiredState) {
+			logrus.Infof("Node %s reached desired provision state: %s", nodeID, desiredState)
+			return nil
+		}
+		switch node.ProvisionState {
+		case ProvisionStateDeployFail, ProvisionStateCleanFail, ProvisionStateError:
+			return errors.Errorf("node %s entered failed state: %s - %s", nodeID, node.ProvisionState, node.LastError)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "context cancelled while waiting for node state")
+		case <-time.After(c.PollInterval):
+		}
+	}
+	return errors.Errorf("timeout waiting for node %s to reach state %s after %v", nodeID, desiredState, timeout)
+}
+
+// Reclaim does the following:
+//  1. Brings the node to "available" (manage/provide from enroll or manageable,
+//     undeploy from active or deploy failed). Ironic runs its configured
+//     automated cleaning on the way, which is where disks get erased.
+//  2. Points instance_info at the requested boot source image, if any
+//  3. Deploys the node with the cloud-init script as configdrive user data
+//  4. Waits for the node to become active
+//
+// Ironic drives power and PXE itself during deployment, so no IPMI calls are made here.
+func (c *IronicClient) Reclaim(ctx context.Context, req *api.ReclaimBMRequest) error {
+	nodeID := req.ResourceId
+	node, err := c.GetNode(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+	if node.Maintenance {
+		return errors.Errorf("node %s is in maintenance mode", nodeID)
+	}
+
+	switch node.ProvisionState {
+	case ProvisionStateEnroll:
+		logrus.Infof("%s Node %s is enrolled, moving it to manageable", ctx, nodeID)
+		if err := c.SetProvisionState(ctx, nodeID, ProvisionTargetManage, ""); err != nil {
+			return err
+		}
+		if err := c.WaitForProvisionState(ctx, nodeID, ProvisionStateManageable, 10*time.Minute); err != nil {
+			return errors.Wrap(err, "failed waiting for node to become manageable")
+		}
+		fallthrough
+	case ProvisionStateManageable:
+		logrus.Infof("%s Providing node %s", ctx, nodeID)
+		if err := c.SetProvisionState(ctx, nodeID, ProvisionTargetProvide, ""); err != nil {
+			return err
+		}
+	case ProvisionStateActive, ProvisionStateDeployFail:
+		logrus.Infof("%s Undeploying node %s from state %s", ctx, nodeID, node.ProvisionState)
+		if err := c.SetProvisionState(ctx, nodeID, ProvisionTargetDeleted, ""); err != nil {
+			return err
+		}
+	case ProvisionStateAvailable, ProvisionStateCleaning, ProvisionStateCleanWait:
+		logrus.Infof("%s Node %s is %s", ctx, nodeID, node.ProvisionState)
+	default:
+		return errors.Errorf("node %s is in state %s and cannot be reclaimed", nodeID, node.ProvisionState)
+	}
+
+	// Cleaning after undeploy can take a long time on baremetal.
+	if err := c.WaitForProvisionState(ctx, nodeID, ProvisionStateAvailable, 60*time.Minute); err != nil {
+		return errors.Wrap(err, "failed waiting for node to become available")
+	}
+
+	if req.BootSource != nil && req.BootSource.Release != "" {
+		imageSource := req.BootSource.Release
+		if req.BootSource.ResourceURI != "" {
+			imageSource = req.BootSource.ResourceURI
+		}
+		logrus.Infof("%s Setting image source of node %s to %s", ctx, nodeID, imageSource)
+		if err := c.SetImageSource(ctx, nodeID, imageSource); err != nil {
+			return err
+		}
+	}
+
+	logrus.Infof("%s Deploying node %s", ctx, nodeID)
+	if err := c.SetProvisionState(ctx, nodeID, ProvisionTargetActive, req.UserData); err != nil {
+		return err
+	}
+
+	// OS install + cloud-init on baremetal can take 45+ minutes.
+	logrus.Infof("%s Waiting for node %s to become active", ctx, nodeID)
+	if err := c.WaitForProvisionState(ctx, nodeID, ProvisionStateActive, 60*time.Minute); err != nil {
+		return errors.Wrap(err, "failed waiting for node to be deployed")
+	}
+	return nil
+}
+
+// ListBootSources returns the distinct images referenced by the nodes' instance_info.
+// Ironic has no boot source catalogue of its own; images are Glance UUIDs or URLs.
+func (c *IronicClient) ListBootSources(ctx context.Context) ([]api.BootsourceSelections, error) {
+	nodes, err := c.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]int)
+	var result []api.BootsourceSelections
+	for i := range nodes {
+		image := stringProperty(nodes[i].InstanceInfo, "image_source")
+		if image == "" {
+			continue
+		}
+		arch := stringProperty(nodes[i].Properties, "cpu_arch")
+		if idx, ok := seen[image]; ok {
+			if arch != "" && !containsString(result[idx].Arches, arch) {
+				result[idx].Arches = append(result[idx].Arches, arch)
+			}
+			continue
+		}
+		var arches []string
+		if arch != "" {
+			arches = []string{arch}
+		}
+		seen[image] = len(result)
+		result = append(result, api.BootsourceSelections{
+			OS:          stringProperty(nodes[i].InstanceInfo, "image_os"),
+			Release:     image,
+			ResourceURI: image,
+			Arches:      arches,
+			ID:          int32(len(result)),
+		})
+	}
+	return result, nil
+}
+
+// ListMachines retrieves all nodes and converts them to MachineInfo
+func (c *IronicClient) ListMachines(ctx context.Context) ([]api.MachineInfo, error) {
+	nodes, err := c.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := c.ListPorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	macs := pxeMACsByNode(ports)
+	result := make([]api.MachineInfo, len(nodes))
+	for i := range nodes {
+		result[i] = nodeToMachineInfo(&nodes[i], macs[nodes[i].UUID])
+	}
+	return result, nil
+}
+
+// GetMachine retrieves a single node and converts it to MachineInfo
+func (c *IronicClient) GetMachine(ctx context.Context, nodeID string) (api.MachineInfo, error) {
+	node, err := c.GetNode(ctx, nodeID)
+	if err != nil {
+		return api.MachineInfo{}, err
+	}
+	ports, err := c.ListNodePorts(ctx, node.UUID)
+	if err != nil {
+		return api.MachineInfo{}, err
+	}
+	return nodeToMachineInfo(node, pxeMACsByNode(ports)[node.UUID]), nil
+}
+
+// pxeMACsByNode returns one MAC per node, preferring a PXE-enabled port
+func pxeMACsByNode(ports []IronicPort) map[string]string {
+	macs := make(map[string]string)
+	pxe := make(map[string]bool)
+	for _, p := range ports {
+		if _, ok := macs[p.NodeUUID]; ok && (pxe[p.NodeUUID] || !p.PXEEnabled) {
+			continue
+		}
+		macs[p.NodeUUID] = strings.ToLower(p.Address)
+		pxe[p.NodeUUID] = p.PXEEnabled
+	}
+	return macs
+}
+
+func nodeToMachineInfo(node *IronicNode, mac string) api.MachineInfo {
+	powerParams := ""
+	if node.Driver != "" {
+		powerParams = fmt.Sprintf("driver=%s\n", node.Driver)
+	}
+	return api.MachineInfo{
+		Id:             node.UUID,
+		Hostname:       node.Name,
+		Fqdn:           node.Name,
+		PowerState:     normalizePowerState(node.PowerState),
+		Architecture:   stringProperty(node.Properties, "cpu_arch"),
+		Memory:         stringProperty(node.Properties, "memory_mb"),
+		CpuCount:       stringProperty(node.Properties, "cpus"),
+		BootDiskSize:   stringProperty(node.Properties, "local_gb"),
+		Status:         node.ProvisionState,
+		StatusMessage:  node.LastError,
+		Description:    node.Description,
+		Zone:           node.ConductorGroup,
+		Pool:           node.ResourceClass,
+		TagNames:       strings.Join(node.Traits, ","),
+		Netboot:        strings.Contains(node.BootInterface, "pxe"),
+		PowerType:      node.Driver,
+		PowerParams:    powerParams,
+		BiosBootMethod: bootMode(node.Properties),
+		HardwareUuid:   systemUUID(node),
+		MacAddress:     mac,
+	}
+}
+
+// normalizePowerState maps Ironic power states to the "on"/"off" values reported by other providers
+func normalizePowerState(state string) string {
+	switch state {
+	case PowerStateOn:
+		return "on"
+	case PowerStateOff:
+		return "off"
+	case "":
+		return "unknown"
+	default:
+		return state
+	}
+}
+
+// bootMode reads the boot mode from the node capabilities, e.g. "boot_mode:uefi,secure_boot:true"
+func bootMode(properties map[string]interface{}) string {
+	for _, capability := range strings.Split(stringProperty(properties, "capabilities"), ",") {
+		key, value, ok := strings.Cut(capability, ":")
+		if ok && strings.TrimSpace(key) == "boot_mode" {
+			if strings.TrimSpace(value) == "uefi" {
+				return "efi"
+			}
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// systemUUID returns the SMBIOS system UUID recorded by inspection, if any
+func systemUUID(node *IronicNode) string {
+	if v := stringProperty(node.Extra, "system_uuid"); v != "" {
+		return v
+	}
+	return stringProperty(node.Properties, "system_uuid")
+}
+
+func stringProperty(m map[string]interface{}, key string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return fmt.Sprintf("%d", int64(val))
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
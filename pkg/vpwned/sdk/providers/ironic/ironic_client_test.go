@@ -0,0 +1,383 @@
+package ironic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/platform9/vjailbreak/pkg/vpwned/api/proto/v1/service"
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers"
+)
+
+// fakeIronic is a minimal in-memory stand-in for the Ironic REST API.
+// Provision state transitions complete immediately.
+type fakeIronic struct {
+	mu          sync.Mutex
+	nodes       map[string]*IronicNode
+	ports       []IronicPort
+	bootDevices map[string]string
+	configDrive map[string]string
+	token       string
+}
+
+func newFakeIronic(t *testing.T) (*fakeIronic, *httptest.Server) {
+	t.Helper()
+	f := &fakeIronic{
+		nodes: map[string]*IronicNode{
+			"node-1": {
+				UUID:           "node-1",
+				Name:           "esxi-01",
+				PowerState:     PowerStateOn,
+				ProvisionState: ProvisionStateActive,
+				Driver:         "ipmi",
+				BootInterface:  "ipxe",
+				Properties: map[string]interface{}{
+					"cpus":         float64(32),
+					"memory_mb":    float64(262144),
+					"local_gb":     float64(480),
+					"cpu_arch":     "x86_64",
+					"capabilities": "boot_mode:uefi",
+				},
+				InstanceInfo: map[string]interface{}{"image_source": "http://images/ubuntu-jammy.qcow2"},
+				Extra:        map[string]interface{}{"system_uuid": "4c4c4544-0042"},
+				Traits:       []string{"CUSTOM_GOLD"},
+			},
+			"node-2": {
+				UUID:           "node-2",
+				Name:           "spare-01",
+				PowerState:     PowerStateOff,
+				ProvisionState: ProvisionStateEnroll,
+				Driver:         "redfish",
+			},
+		},
+		ports: []IronicPort{
+			{UUID: "port-1", NodeUUID: "node-1", Address: "AA:BB:CC:00:00:01", PXEEnabled: false},
+			{UUID: "port-2", NodeUUID: "node-1", Address: "AA:BB:CC:00:00:02", PXEEnabled: true},
+			{UUID: "port-3", NodeUUID: "node-2", Address: "aa:bb:cc:00:00:03", PXEEnabled: true},
+		},
+		bootDevices: map[string]string{},
+		configDrive: map[string]string{},
+		token:       "secret-token",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func writeIronicError(w http.ResponseWriter, code int, msg string) {
+	inner, _ := json.Marshal(map[string]string{"faultstring": msg})
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error_message": string(inner)})
+}
+
+func (f *fakeIronic) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Auth-Token") != f.token {
+		writeIronicError(w, http.StatusUnauthorized, "Authentication required")
+		return
+	}
+	if r.Header.Get("X-OpenStack-Ironic-API-Version") == "" {
+		writeIronicError(w, http.StatusNotAcceptable, "missing microversion")
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case len(parts) == 2 && parts[1] == "nodes", len(parts) == 3 && parts[1] == "nodes" && parts[2] == "detail":
+		list := ironicNodeList{}
+		for _, n := range f.nodes {
+			list.Nodes = append(list.Nodes, *n)
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case len(parts) == 3 && parts[1] == "ports" && parts[2] == "detail":
+		_ = json.NewEncoder(w).Encode(ironicPortList{Ports: f.ports})
+	case len(parts) >= 3 && parts[1] == "nodes":
+		node, ok := f.nodes[parts[2]]
+		if !ok {
+			writeIronicError(w, http.StatusNotFound, "Node "+parts[2]+" could not be found.")
+			return
+		}
+		f.serveNode(w, r, node, parts[3:])
+	default:
+		writeIronicError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (f *fakeIronic) serveNode(w http.ResponseWriter, r *http.Request, node *IronicNode, sub []string) {
+	path := strings.Join(sub, "/")
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(node)
+	case path == "" && r.Method == http.MethodPatch:
+		var patch []map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&patch)
+		for _, op := range patch {
+			if op["path"] == "/instance_info/image_source" {
+				if node.InstanceInfo == nil {
+					node.InstanceInfo = map[string]interface{}{}
+				}
+				node.InstanceInfo["image_source"] = op["value"]
+			}
+		}
+		_ = json.NewEncoder(w).Encode(node)
+	case path == "ports/detail":
+		list := ironicPortList{}
+		for _, p := range f.ports {
+			if p.NodeUUID == node.UUID {
+				list.Ports = append(list.Ports, p)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case path == "states/power" && r.Method == http.MethodPut:
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch body["target"] {
+		case PowerStateOn, "rebooting":
+			node.PowerState = PowerStateOn
+		case PowerStateOff:
+			node.PowerState = PowerStateOff
+		default:
+			writeIronicError(w, http.StatusBadRequest, "invalid power target")
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	case path == "management/boot_device" && r.Method == http.MethodPut:
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.bootDevices[node.UUID], _ = body["boot_device"].(string)
+		w.WriteHeader(http.StatusNoContent)
+	case path == "states/provision" && r.Method == http.MethodPut:
+		var body struct {
+			Target      string            `json:"target"`
+			ConfigDrive map[string]string `json:"configdrive"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		next, ok := map[string]map[string]string{
+			ProvisionTargetManage:  {ProvisionStateEnroll: ProvisionStateManageable},
+			ProvisionTargetProvide: {ProvisionStateManageable: ProvisionStateAvailable},
+			ProvisionTargetDeleted: {ProvisionStateActive: ProvisionStateAvailable, ProvisionStateDeployFail: ProvisionStateAvailable},
+			ProvisionTargetActive:  {ProvisionStateAvailable: ProvisionStateActive},
+		}[body.Target][node.ProvisionState]
+		if !ok {
+			writeIronicError(w, http.StatusBadRequest, "The requested action \""+body.Target+"\" can not be performed on node \""+node.UUID+"\" while it is in state \""+node.ProvisionState+"\".")
+			return
+		}
+		node.ProvisionState = next
+		if body.Target == ProvisionTargetActive {
+			f.configDrive[node.UUID] = body.ConfigDrive["user_data"]
+		}
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		writeIronicError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func connectFake(t *testing.T, srv *httptest.Server) *IronicProvider {
+	t.Helper()
+	p := &IronicProvider{}
+	if err := p.Connect(providers.BMAccessInfo{BaseURL: srv.URL + "/v1/", APIKey: "secret-token"}); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	p.client.PollInterval = time.Millisecond
+	return p
+}
+
+func TestConnectRejectsBadToken(t *testing.T) {
+	_, srv := newFakeIronic(t)
+	p := &IronicProvider{}
+	err := p.Connect(providers.BMAccessInfo{BaseURL: srv.URL, APIKey: "wrong"})
+	if err == nil || !strings.Contains(err.Error(), "Authentication required") {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+}
+
+func TestConnectRejectsInvalidURL(t *testing.T) {
+	p := &IronicProvider{}
+	if err := p.Connect(providers.BMAccessInfo{BaseURL: "ironic:6385"}); err == nil {
+		t.Fatal("expected invalid base URL error")
+	}
+}
+
+func TestListResources(t *testing.T) {
+	_, srv := newFakeIronic(t)
+	p := connectFake(t, srv)
+
+	machines, err := p.ListResources(context.Background())
+	if err != nil {
+		t.Fatalf("ListResources: %v", err)
+	}
+	if len(machines) != 2 {
+		t.Fatalf("expected 2 machines, got %d", len(machines))
+	}
+	var m *api.MachineInfo
+	for i := range machines {
+		if machines[i].Id == "node-1" {
+			m = &machines[i]
+		}
+	}
+	if m == nil {
+		t.Fatal("node-1 not listed")
+	}
+	if m.Hostname != "esxi-01" || m.PowerState != "on" || m.Status != ProvisionStateActive {
+		t.Errorf("unexpected identity/state: %+v", m)
+	}
+	if m.CpuCount != "32" || m.Memory != "262144" || m.BootDiskSize != "480" || m.Architecture != "x86_64" {
+		t.Errorf("unexpected hardware: cpus=%s mem=%s disk=%s arch=%s", m.CpuCount, m.Memory, m.BootDiskSize, m.Architecture)
+	}
+	if m.MacAddress != "aa:bb:cc:00:00:02" {
+		t.Errorf("expected PXE-enabled port MAC, got %s", m.MacAddress)
+	}
+	if m.HardwareUuid != "4c4c4544-0042" || m.BiosBootMethod != "efi" || m.TagNames != "CUSTOM_GOLD" {
+		t.Errorf("unexpected uuid/boot/tags: %s %s %s", m.HardwareUuid, m.BiosBootMethod, m.TagNames)
+	}
+}
+
+func TestGetResourceInfo(t *testing.T) {
+	_, srv := newFakeIronic(t)
+	p := connectFake(t, srv)
+
+	m, err := p.GetResourceInfo(context.Background(), "node-2")
+	if err != nil {
+		t.Fatalf("GetResourceInfo: %v", err)
+	}
+	if m.PowerState != "off" || m.MacAddress != "aa:bb:cc:00:00:03" || m.PowerType != "redfish" {
+		t.Errorf("unexpected machine info: %+v", &m)
+	}
+
+	_, err = p.GetResourceInfo(context.Background(), "missing")
+	if err == nil || !strings.Contains(err.Error(), "could not be found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestSetResourcePower(t *testing.T) {
+	f, srv := newFakeIronic(t)
+	p := connectFake(t, srv)
+
+	if err := p.SetResourcePower(context.Background(), "node-1", api.PowerStatus_POWERED_OFF); err != nil {
+		t.Fatalf("power off: %v", err)
+	}
+	if f.nodes["node-1"].PowerState != PowerStateOff {
+		t.Errorf("expected node-1 powered off, got %s", f.nodes["node-1"].PowerState)
+	}
+	if err := p.SetResourcePower(context.Background(), "node-1", api.PowerStatus_POWERED_ON); err != nil {
+		t.Fatalf("power on: %v", err)
+	}
+	if f.nodes["node-1"].PowerState != PowerStateOn {
+		t.Errorf("expected node-1 powered on, got %s", f.nodes["node-1"].PowerState)
+	}
+}
+
+func TestSetBM2PXEBoot(t *testing.T) {
+	f, srv := newFakeIronic(t)
+	p := connectFake(t, srv)
+
+	if err := p.SetBM2PXEBoot(context.Background(), "node-2", false, nil); err != nil {
+		t.Fatalf("SetBM2PXEBoot: %v", err)
+	}
+	if f.bootDevices["node-2"] != "pxe" {
+		t.Errorf("expected pxe boot device, got %q", f.bootDevices["node-2"])
+	}
+	if f.nodes["node-2"].PowerState != PowerStateOn {
+		t.Errorf("expected powered off node to be powered on")
+	}
+}
+
+func TestReclaimBMFromActive(t *testing.T) {
+	f, srv := newFakeIronic(t)
+	p := connectFake(t, srv)
+
+	err := p.ReclaimBM(context.Background(), api.ReclaimBMRequest{
+		ResourceId: "node-1",
+		UserData:   "#cloud-config\nruncmd: [echo hi]\n",
+		BootSource: &api.BootsourceSelections{Release: "http://images/pcd-host.qcow2"},
+	})
+	if err != nil {
+		t.Fatalf("ReclaimBM: %v", err)
+	}
+	node := f.nodes["node-1"]
+	if node.ProvisionState != ProvisionStateActive {
+		t.Errorf("expected active, got %s", node.ProvisionState)
+	}
+	if node.InstanceInfo["image_source"] != "http://images/pcd-host.qcow2" {
+		t.Errorf("image source not updated: %v", node.InstanceInfo["image_source"])
+	}
+	if !strings.Contains(f.configDrive["node-1"], "runcmd") {
+		t.Errorf("user data not passed as configdrive: %q", f.configDrive["node-1"])
+	}
+}
+
+func TestReclaimBMFromEnroll(t *testing.T) {
+	f, srv := newFakeIronic(t)
+	p := connectFake(t, srv)
+
+	if err := p.ReclaimBM(context.Background(), api.ReclaimBMRequest{ResourceId: "node-2"}); err != nil {
+		t.Fatalf("ReclaimBM: %v", err)
+	}
+	if f.nodes["node-2"].ProvisionState != ProvisionStateActive {
+		t.Errorf("expected active, got %s", f.nodes["node-2"].ProvisionState)
+	}
+}
+
+func TestReclaimBMMaintenance(t *testing.T) {
+	f, srv := newFakeIronic(t)
+	p := connectFake(t, srv)
+	f.nodes["node-1"].Maintenance = true
+
+	err := p.ReclaimBM(context.Background(), api.ReclaimBMRequest{ResourceId: "node-1"})
+	if err == nil || !strings.Contains(err.Error(), "maintenance") {
+		t.Fatalf("expected maintenance error, got %v", err)
+	}
+}
+
+func TestDeployMachineRequiresAvailable(t *testing.T) {
+	f, srv := newFakeIronic(t)
+	p := connectFake(t, srv)
+
+	_, err := p.DeployMachine(context.Background(), api.DeployMachineRequest{ResourceId: "node-1"})
+	if err == nil {
+		t.Fatal("expected error deploying an active node")
+	}
+
+	f.nodes["node-1"].ProvisionState = ProvisionStateAvailable
+	resp, err := p.DeployMachine(context.Background(), api.DeployMachineRequest{ResourceId: "node-1", UserData: "#cloud-config"})
+	if err != nil || !resp.Success {
+		t.Fatalf("DeployMachine: %v", err)
+	}
+	if f.nodes["node-1"].ProvisionState != ProvisionStateActive {
+		t.Errorf("expected active, got %s", f.nodes["node-1"].ProvisionState)
+	}
+}
+
+func TestListBootSource(t *testing.T) {
+	_, srv := newFakeIronic(t)
+	p := connectFake(t, srv)
+
+	sources, err := p.ListBootSource(context.Background(), api.ListBootSourceRequest{})
+	if err != nil {
+		t.Fatalf("ListBootSource: %v", err)
+	}
+	if len(sources) != 1 || sources[0].Release != "http://images/ubuntu-jammy.qcow2" {
+		t.Fatalf("unexpected boot sources: %+v", sources)
+	}
+	if len(sources[0].Arches) != 1 || sources[0].Arches[0] != "x86_64" {
+		t.Errorf("unexpected arches: %v", sources[0].Arches)
+	}
+}
+
+func TestProviderRegistered(t *testing.T) {
+	p, err := providers.GetProvider("IRONIC")
+	if err != nil {
+		t.Fatalf("GetProvider: %v", err)
+	}
+	if p.WhoAmI() != IronicProviderName {
+		t.Errorf("unexpected provider %s", p.WhoAmI())
+	}
+}
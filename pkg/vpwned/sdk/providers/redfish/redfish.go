@@ -0,0 +1,240 @@
+package redfish
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	api "github.com/platform9/vjailbreak/pkg/vpwned/api/proto/v1/service"
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers"
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers/base"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RedfishProviderName = "redfish"
+)
+
+// RedfishProvider implements the Provider interface directly against BMCs
+// (iDRAC, iLO, XCC, ...) without a provisioning service in front of them.
+// BaseURL holds one or more BMC URLs separated by commas; all BMCs share the
+// Username/Password of the access info.
+//
+// With no provisioning service there is nothing to hand cloud-init user data
+// to: the boot source (a PXE target or an installer ISO URL) must fetch its
+// own configuration, e.g. through a NoCloud seed baked into the image.
+type RedfishProvider struct {
+	base.UnimplementedBaseProvider
+	client *RedfishClient
+}
+
+// Connect validates every configured BMC
+func (p *RedfishProvider) Connect(auth providers.BMAccessInfo) error {
+	client, err := NewRedfishClient(RedfishAccessInfo{
+		Endpoints:   ParseEndpoints(auth.BaseURL),
+		Username:    auth.Username,
+		Password:    auth.Password,
+		UseInsecure: auth.UseInsecure,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create redfish client")
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		return errors.Wrap(err, "failed to connect to redfish")
+	}
+	p.client = client
+	return nil
+}
+
+func (p *RedfishProvider) connectFromAccessInfo(accessInfo *api.BMProvisionerAccessInfo) error {
+	if p.client != nil {
+		return nil
+	}
+	if accessInfo == nil {
+		return errors.New("client not initialized")
+	}
+	return p.Connect(providers.BMAccessInfo{
+		BaseURL:     accessInfo.BaseUrl,
+		Username:    accessInfo.Username,
+		Password:    accessInfo.Password,
+		UseInsecure: accessInfo.UseInsecure,
+	})
+}
+
+func (p *RedfishProvider) Disconnect() error {
+	return nil
+}
+
+func (p *RedfishProvider) WhoAmI() string {
+	return RedfishProviderName
+}
+
+// ListResources retrieves every ComputerSystem of every BMC
+func (p *RedfishProvider) ListResources(ctx context.Context) ([]api.MachineInfo, error) {
+	if p.client == nil {
+		return nil, errors.New("client not initialized")
+	}
+	return p.client.ListMachines(ctx)
+}
+
+// GetResourceInfo retrieves information about a system
+func (p *RedfishProvider) GetResourceInfo(ctx context.Context, resourceID string) (api.MachineInfo, error) {
+	if p.client == nil {
+		return api.MachineInfo{}, errors.New("client not initialized")
+	}
+	return p.client.GetMachine(ctx, resourceID)
+}
+
+// SetResourcePower changes the power state of a system
+func (p *RedfishProvider) SetResourcePower(ctx context.Context, resourceID string, action api.PowerStatus) error {
+	if p.client == nil {
+		return errors.New("client not initialized")
+	}
+	var resetType string
+	switch action {
+	case api.PowerStatus_POWERED_ON:
+		resetType = ResetTypeOn
+	case api.PowerStatus_POWERED_OFF:
+		resetType = ResetTypeForceOff
+	default:
+		return fmt.Errorf("unsupported power action: %v", action)
+	}
+	if err := p.client.Reset(ctx, resourceID, resetType); err != nil {
+		logrus.Errorf("Failed to change power state: %v", err)
+		return err
+	}
+	logrus.Infof("System %s requested %s", resourceID, resetType)
+	return nil
+}
+
+// SetBM2PXEBoot sets a one-time PXE boot and powers the system on, or
+// restarts it when power_cycle is set. ipmi_interface is ignored.
+func (p *RedfishProvider) SetBM2PXEBoot(ctx context.Context, resourceID string, power_cycle bool, ipmi_interface *api.IpmiType) error {
+	if p.client == nil {
+		return errors.New("client not initialized")
+	}
+	if err := p.client.BootFromSource(ctx, resourceID, BootTargetPxe, power_cycle); err != nil {
+		return errors.Wrapf(err, "failed to set system %s to PXE boot", resourceID)
+	}
+	logrus.Infof("Successfully set system %s to PXE boot", resourceID)
+	return nil
+}
+
+// DeployMachine boots the system once from OsReleaseName (an ISO URL or a
+// Redfish boot target such as Pxe) and power cycles it
+func (p *RedfishProvider) DeployMachine(ctx context.Context, req api.DeployMachineRequest) (api.DeployMachineResponse, error) {
+	if err := p.connectFromAccessInfo(req.AccessInfo); err != nil {
+		return api.DeployMachineResponse{}, errors.Wrap(err, "Deploy Machine Failed")
+	}
+	if req.UserData != "" {
+		logrus.Warnf("Redfish cannot deliver user data to %s, the boot image must carry its own configuration", req.ResourceId)
+	}
+	if err := p.client.BootFromSource(ctx, req.ResourceId, req.OsReleaseName, true); err != nil {
+		return api.DeployMachineResponse{}, errors.Wrap(err, "Deploy Machine Failed")
+	}
+	return api.DeployMachineResponse{Success: true}, nil
+}
+
+// ReclaimBM boots the ESXi host once from the boot source and waits for it to
+// power on. ManualPowerControl leaves power to the operator: only the boot
+// override is set.
+func (p *RedfishProvider) ReclaimBM(ctx context.Context, req api.ReclaimBMRequest) error {
+	if err := p.connectFromAccessInfo(req.AccessInfo); err != nil {
+		return errors.Wrap(err, "Reclaim VM Failed")
+	}
+	bootSource := BootTargetPxe
+	if req.BootSource != nil {
+		if req.BootSource.ResourceURI != "" {
+			bootSource = req.BootSource.ResourceURI
+		} else if isImageURL(req.BootSource.Release) {
+			bootSource = req.BootSource.Release
+		}
+	}
+	if req.UserData != "" {
+		logrus.Warnf("Redfish cannot deliver user data to %s, the boot image must carry its own configuration", req.ResourceId)
+	}
+	if req.ManualPowerControl {
+		logrus.Infof("%s Manual power control requested, only setting boot source %s on %s", ctx, bootSource, req.ResourceId)
+		_, err := p.client.PrepareBoot(ctx, req.ResourceId, bootSource)
+		return err
+	}
+	logrus.Infof("%s Booting %s from %s", ctx, req.ResourceId, bootSource)
+	if err := p.client.BootFromSource(ctx, req.ResourceId, bootSource, req.PowerCycle); err != nil {
+		return errors.Wrap(err, "failed to boot from boot source")
+	}
+	if err := p.client.WaitForPowerState(ctx, req.ResourceId, PowerStateOn, 10*time.Minute); err != nil {
+		return errors.Wrap(err, "failed waiting for system to power on")
+	}
+	return nil
+}
+
+// ListBootSource lists the boot override targets the BMCs allow
+func (p *RedfishProvider) ListBootSource(ctx context.Context, req api.ListBootSourceRequest) ([]api.BootsourceSelections, error) {
+	if err := p.connectFromAccessInfo(req.AccessInfo); err != nil {
+		return nil, errors.Wrap(err, "List Boot Source Failed")
+	}
+	systems, err := p.client.ListSystems(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "List Boot Source Failed")
+	}
+	seen := make(map[string]bool)
+	var result []api.BootsourceSelections
+	for _, ref := range systems {
+		for _, target := range ref.System.Boot.BootSourceOverrideTargetValues {
+			if seen[target] || strings.EqualFold(target, "None") {
+				continue
+			}
+			seen[target] = true
+			result = append(result, api.BootsourceSelections{
+				OS:      RedfishProviderName,
+				Release: target,
+				ID:      int32(len(result)),
+			})
+		}
+	}
+	return result, nil
+}
+
+func (p *RedfishProvider) IsBMReady(ctx context.Context, req api.IsBMReadyRequest) (api.IsBMReadyResponse, error) {
+	if p.client == nil {
+		return api.IsBMReadyResponse{}, errors.New("client not initialized")
+	}
+	system, err := p.client.GetSystem(ctx, req.ResourceId)
+	if err != nil {
+		return api.IsBMReadyResponse{}, errors.Wrap(err, "IsBMReady Failed")
+	}
+	ready := system.Status.Health == "" || system.Status.Health == "OK"
+	return api.IsBMReadyResponse{IsReady: ready && system.Status.State != "Disabled"}, nil
+}
+
+func (p *RedfishProvider) IsBMRunning(ctx context.Context, req api.IsBMRunningRequest) (api.IsBMRunningResponse, error) {
+	if p.client == nil {
+		return api.IsBMRunningResponse{}, errors.New("client not initialized")
+	}
+	system, err := p.client.GetSystem(ctx, req.ResourceId)
+	if err != nil {
+		return api.IsBMRunningResponse{}, errors.Wrap(err, "IsBMRunning Failed")
+	}
+	return api.IsBMRunningResponse{IsRunning: strings.EqualFold(system.PowerState, PowerStateOn)}, nil
+}
+
+func (p *RedfishProvider) StartBM(ctx context.Context, req api.StartBMRequest) (api.StartBMResponse, error) {
+	if err := p.SetResourcePower(ctx, req.ResourceId, api.PowerStatus_POWERED_ON); err != nil {
+		return api.StartBMResponse{}, errors.Wrap(err, "StartBM Failed")
+	}
+	return api.StartBMResponse{Success: true}, nil
+}
+
+func (p *RedfishProvider) StopBM(ctx context.Context, req api.StopBMRequest) (api.StopBMResponse, error) {
+	if err := p.SetResourcePower(ctx, req.ResourceId, api.PowerStatus_POWERED_OFF); err != nil {
+		return api.StopBMResponse{}, errors.Wrap(err, "StopBM Failed")
+	}
+	return api.StopBMResponse{Success: true}, nil
+}
+
+func init() {
+	providers.RegisterProvider(RedfishProviderName, &RedfishProvider{client: nil})
+}
@@ -0,0 +1,551 @@
+package redfish
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	api "github.com/platform9/vjailbreak/pkg/vpwned/api/proto/v1/service"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Redfish ComputerSystem.Reset types
+	ResetTypeOn           = "On"
+	ResetTypeForceOff     = "ForceOff"
+	ResetTypeForceRestart = "ForceRestart"
+
+	// Redfish BootSourceOverrideTarget values
+	BootTargetPxe = "Pxe"
+	BootTargetCd  = "Cd"
+
+	// Redfish power states
+	PowerStateOn  = "On"
+	PowerStateOff = "Off"
+
+	systemsPath = "/redfish/v1/Systems"
+)
+
+// RedfishAccessInfo contains credentials and connection details for one or more BMCs
+type RedfishAccessInfo struct {
+	// Endpoints are the BMC base URLs, e.g. https://idrac-01.example.com
+	Endpoints   []string
+	Username    string
+	Password    string
+	UseInsecure bool
+}
+
+// RedfishClient talks to one or more Redfish BMCs with shared credentials.
+// Resources are addressed by the absolute URL of their ComputerSystem, so a
+// resource ID alone is enough to find the BMC that owns it.
+type RedfishClient struct {
+	Endpoints    []string
+	Username     string
+	Password     string
+	HTTPClient   *http.Client
+	PollInterval time.Duration
+}
+
+type odataRef struct {
+	ODataID string `json:"@odata.id"`
+}
+
+type collection struct {
+	Members []odataRef `json:"Members"`
+}
+
+type status struct {
+	State  string `json:"State"`
+	Health string `json:"Health"`
+}
+
+// ComputerSystem is the subset of the Redfish ComputerSystem resource used by the provider
+type ComputerSystem struct {
+	ODataID          string `json:"@odata.id"`
+	ID               string `json:"Id"`
+	Name             string `json:"Name"`
+	HostName         string `json:"HostName"`
+	Manufacturer     string `json:"Manufacturer"`
+	Model            string `json:"Model"`
+	SerialNumber     string `json:"SerialNumber"`
+	UUID             string `json:"UUID"`
+	PowerState       string `json:"PowerState"`
+	Status           status `json:"Status"`
+	ProcessorSummary struct {
+		Count int    `json:"Count"`
+		Model string `json:"Model"`
+	} `json:"ProcessorSummary"`
+	MemorySummary struct {
+		TotalSystemMemoryGiB float64 `json:"TotalSystemMemoryGiB"`
+	} `json:"MemorySummary"`
+	Boot struct {
+		BootSourceOverrideEnabled      string   `json:"BootSourceOverrideEnabled"`
+		BootSourceOverrideTarget       string   `json:"BootSourceOverrideTarget"`
+		BootSourceOverrideMode         string   `json:"BootSourceOverrideMode"`
+		BootSourceOverrideTargetValues []string `json:"BootSourceOverrideTarget@Redfish.AllowableValues"`
+	} `json:"Boot"`
+	EthernetInterfaces odataRef `json:"EthernetInterfaces"`
+	Links              struct {
+		ManagedBy []odataRef `json:"ManagedBy"`
+	} `json:"Links"`
+}
+
+// EthernetInterface is the subset of the Redfish EthernetInterface resource used by the provider
+type EthernetInterface struct {
+	ID         string `json:"Id"`
+	MACAddress string `json:"MACAddress"`
+	Status     status `json:"Status"`
+}
+
+// Manager is the subset of the Redfish Manager resource used by the provider
+type Manager struct {
+	VirtualMedia odataRef `json:"VirtualMedia"`
+}
+
+// VirtualMedia is the subset of the Redfish VirtualMedia resource used by the provider
+type VirtualMedia struct {
+	ODataID    string   `json:"@odata.id"`
+	ID         string   `json:"Id"`
+	MediaTypes []string `json:"MediaTypes"`
+	Image      string   `json:"Image"`
+	Inserted   bool     `json:"Inserted"`
+}
+
+// NewRedfishClient creates a new Redfish client
+func NewRedfishClient(accessInfo RedfishAccessInfo) (*RedfishClient, error) {
+	if len(accessInfo.Endpoints) == 0 {
+		return nil, errors.New("no redfish endpoints configured")
+	}
+	endpoints := make([]string, 0, len(accessInfo.Endpoints))
+	for _, e := range accessInfo.Endpoints {
+		endpoint, err := normalizeEndpoint(e)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if accessInfo.UseInsecure {
+		// BMCs almost always ship self-signed certificates
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // user opted in
+	}
+	return &RedfishClient{
+		Endpoints:    endpoints,
+		Username:     accessInfo.Username,
+		Password:     accessInfo.Password,
+		HTTPClient:   &http.Client{Transport: transport, Timeout: 60 * time.Second},
+		PollInterval: 10 * time.Second,
+	}, nil
+}
+
+// ParseEndpoints splits a comma or whitespace separated list of BMC URLs
+func ParseEndpoints(baseURL string) []string {
+	return strings.FieldsFunc(baseURL, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\t'
+	})
+}
+
+// normalizeEndpoint reduces a BMC URL to scheme://host[:port]
+func normalizeEndpoint(endpoint string) (string, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", errors.Errorf("invalid redfish endpoint %q", endpoint)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// splitResourceID splits a resource ID (absolute ComputerSystem URL) into endpoint and odata path
+func splitResourceID(resourceID string) (string, string, error) {
+	u, err := url.Parse(resourceID)
+	if err != nil || u.Host == "" || !strings.HasPrefix(u.Path, systemsPath+"/") {
+		return "", "", errors.Errorf("invalid redfish resource id %q", resourceID)
+	}
+	return u.Scheme + "://" + u.Host, u.Path, nil
+}
+
+func (c *RedfishClient) doRequest(ctx context.Context, method, endpoint, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint+path, reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "%s %s%s failed", method, endpoint, path)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response")
+	}
+	if resp.StatusCode >= 300 {
+		return errors.Errorf("%s %s%s returned %d: %s", method, endpoint, path, resp.StatusCode, parseRedfishError(respBody))
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return errors.Wrap(err, "failed to decode response")
+		}
+	}
+	return nil
+}
+
+// parseRedfishError extracts the message from a Redfish error response
+func parseRedfishError(body []byte) string {
+	var e struct {
+		Error struct {
+			Message  string `json:"message"`
+			Extended []struct {
+				Message string `json:"Message"`
+			} `json:"@Message.ExtendedInfo"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &e); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+	if len(e.Error.Extended) > 0 && e.Error.Extended[0].Message != "" {
+		return e.Error.Extended[0].Message
+	}
+	if e.Error.Message != "" {
+		return e.Error.Message
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// Ping checks that every BMC answers and accepts the credentials
+func (c *RedfishClient) Ping(ctx context.Context) error {
+	for _, endpoint := range c.Endpoints {
+		if err := c.doRequest(ctx, http.MethodGet, endpoint, systemsPath, nil, &collection{}); err != nil {
+			return errors.Wrapf(err, "failed to reach BMC %s", endpoint)
+		}
+	}
+	return nil
+}
+
+// SystemRef ties a ComputerSystem to the BMC endpoint it was read from
+type SystemRef struct {
+	ResourceID string
+	Endpoint   string
+	System     *ComputerSystem
+}
+
+// ListSystems returns every ComputerSystem on every endpoint, in endpoint order
+func (c *RedfishClient) ListSystems(ctx context.Context) ([]SystemRef, error) {
+	var systems []SystemRef
+	for _, endpoint := range c.Endpoints {
+		var members collection
+		if err := c.doRequest(ctx, http.MethodGet, endpoint, systemsPath, nil, &members); err != nil {
+			return nil, errors.Wrapf(err, "failed to list systems on %s", endpoint)
+		}
+		for _, m := range members.Members {
+			system, err := c.getSystem(ctx, endpoint, m.ODataID)
+			if err != nil {
+				return nil, err
+			}
+			systems = append(systems, SystemRef{ResourceID: endpoint + m.ODataID, Endpoint: endpoint, System: system})
+		}
+	}
+	return systems, nil
+}
+
+// GetSystem retrieves a ComputerSystem by resource ID
+func (c *RedfishClient) GetSystem(ctx context.Context, resourceID string) (*ComputerSystem, error) {
+	endpoint, path, err := splitResourceID(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return c.getSystem(ctx, endpoint, path)
+}
+
+func (c *RedfishClient) getSystem(ctx context.Context, endpoint, path string) (*ComputerSystem, error) {
+	var system ComputerSystem
+	if err := c.doRequest(ctx, http.MethodGet, endpoint, path, nil, &system); err != nil {
+		return nil, errors.Wrap(err, "failed to get system")
+	}
+	if system.ODataID == "" {
+		system.ODataID = path
+	}
+	return &system, nil
+}
+
+// GetMACAddress returns the MAC address of the first enabled ethernet interface
+func (c *RedfishClient) GetMACAddress(ctx context.Context, endpoint string, system *ComputerSystem) (string, error) {
+	if system.EthernetInterfaces.ODataID == "" {
+		return "", nil
+	}
+	var members collection
+	if err := c.doRequest(ctx, http.MethodGet, endpoint, system.EthernetInterfaces.ODataID, nil, &members); err != nil {
+		return "", errors.Wrap(err, "failed to list ethernet interfaces")
+	}
+	first := ""
+	for _, m := range members.Members {
+		var nic EthernetInterface
+		if err := c.doRequest(ctx, http.MethodGet, endpoint, m.ODataID, nil, &nic); err != nil {
+			return "", errors.Wrap(err, "failed to get ethernet interface")
+		}
+		if nic.MACAddress == "" {
+			continue
+		}
+		if first == "" {
+			first = nic.MACAddress
+		}
+		if nic.Status.State == "" || nic.Status.State == "Enabled" {
+			return strings.ToLower(nic.MACAddress), nil
+		}
+	}
+	return strings.ToLower(first), nil
+}
+
+// Reset issues a ComputerSystem.Reset action
+func (c *RedfishClient) Reset(ctx context.Context, resourceID, resetType string) error {
+	endpoint, path, err := splitResourceID(resourceID)
+	if err != nil {
+		return err
+	}
+	body := map[string]interface{}{"ResetType": resetType}
+	if err := c.doRequest(ctx, http.MethodPost, endpoint, path+"/Actions/ComputerSystem.Reset", body, nil); err != nil {
+		return errors.Wrapf(err, "failed to reset system with %s", resetType)
+	}
+	return nil
+}
+
+// SetOneTimeBoot sets the boot source override for the next boot only
+func (c *RedfishClient) SetOneTimeBoot(ctx context.Context, resourceID, target, mode string) error {
+	endpoint, path, err := splitResourceID(resourceID)
+	if err != nil {
+		return err
+	}
+	boot := map[string]interface{}{
+		"BootSourceOverrideEnabled": "Once",
+		"BootSourceOverrideTarget":  target,
+	}
+	if mode != "" {
+		boot["BootSourceOverrideMode"] = mode
+	}
+	if err := c.doRequest(ctx, http.MethodPatch, endpoint, path, map[string]interface{}{"Boot": boot}, nil); err != nil {
+		return errors.Wrapf(err, "failed to set one-time boot to %s", target)
+	}
+	return nil
+}
+
+// findCDVirtualMedia returns the first CD/DVD capable virtual media slot of the system's manager
+func (c *RedfishClient) findCDVirtualMedia(ctx context.Context, endpoint string, system *ComputerSystem) (*VirtualMedia, error) {
+	if len(system.Links.ManagedBy) == 0 {
+		return nil, errors.New("system has no manager")
+	}
+	var manager Manager
+	if err := c.doRequest(ctx, http.MethodGet, endpoint, system.Links.ManagedBy[0].ODataID, nil, &manager); err != nil {
+		return nil, errors.Wrap(err, "failed to get manager")
+	}
+	if manager.VirtualMedia.ODataID == "" {
+		return nil, errors.New("manager does not support virtual media")
+	}
+	var members collection
+	if err := c.doRequest(ctx, http.MethodGet, endpoint, manager.VirtualMedia.ODataID, nil, &members); err != nil {
+		return nil, errors.Wrap(err, "failed to list virtual media")
+	}
+	for _, m := range members.Members {
+		var vm VirtualMedia
+		if err := c.doRequest(ctx, http.MethodGet, endpoint, m.ODataID, nil, &vm); err != nil {
+			return nil, errors.Wrap(err, "failed to get virtual media")
+		}
+		if vm.ODataID == "" {
+			vm.ODataID = m.ODataID
+		}
+		for _, t := range vm.MediaTypes {
+			if t == "CD" || t == "DVD" {
+				return &vm, nil
+			}
+		}
+	}
+	return nil, errors.New("no CD/DVD virtual media slot found")
+}
+
+// InsertVirtualMedia ejects whatever is in the system's virtual CD and inserts image
+func (c *RedfishClient) InsertVirtualMedia(ctx context.Context, resourceID, image string) error {
+	endpoint, path, err := splitResourceID(resourceID)
+	if err != nil {
+		return err
+	}
+	system, err := c.getSystem(ctx, endpoint, path)
+	if err != nil {
+		return err
+	}
+	vm, err := c.findCDVirtualMedia(ctx, endpoint, system)
+	if err != nil {
+		return err
+	}
+	if vm.Inserted {
+		logrus.Infof("Ejecting %s from %s", vm.Image, resourceID)
+		if err := c.doRequest(ctx, http.MethodPost, endpoint, vm.ODataID+"/Actions/VirtualMedia.EjectMedia", map[string]interface{}{}, nil); err != nil {
+			return errors.Wrap(err, "failed to eject virtual media")
+		}
+	}
+	body := map[string]interface{}{"Image": image, "Inserted": true, "WriteProtected": true}
+	if err := c.doRequest(ctx, http.MethodPost, endpoint, vm.ODataID+"/Actions/VirtualMedia.InsertMedia", body, nil); err != nil {
+		return errors.Wrap(err, "failed to insert virtual media")
+	}
+	return nil
+}
+
+// WaitForPowerState polls the system until it reaches the desired power state or times out
+func (c *RedfishClient) WaitForPowerState(ctx context.Context, resourceID, desiredState string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		system, err := c.GetSystem(ctx, resourceID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get system power state")
+		}
+		if strings.EqualFold(system.PowerState, desiredState) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "context cancelled while waiting for power state")
+		case <-time.After(c.PollInterval):
+		}
+	}
+	return errors.Errorf("timeout waiting for system %s to reach power state %s after %v", resourceID, desiredState, timeout)
+}
+
+// PrepareBoot sets a one-time boot from bootSource without touching power.
+// An http(s) or nfs/cifs URL is mounted as virtual CD, anything else is used as
+// the BootSourceOverrideTarget (defaulting to Pxe).
+func (c *RedfishClient) PrepareBoot(ctx context.Context, resourceID, bootSource string) (*ComputerSystem, error) {
+	system, err := c.GetSystem(ctx, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	target := BootTargetPxe
+	switch {
+	case isImageURL(bootSource):
+		logrus.Infof("Inserting %s as virtual media on %s", bootSource, resourceID)
+		if err := c.InsertVirtualMedia(ctx, resourceID, bootSource); err != nil {
+			return nil, err
+		}
+		target = BootTargetCd
+	case bootSource != "":
+		target = bootSource
+	}
+	if err := c.SetOneTimeBoot(ctx, resourceID, target, system.Boot.BootSourceOverrideMode); err != nil {
+		return nil, err
+	}
+	return system, nil
+}
+
+// BootFromSource prepares a one-time boot from bootSource and then powers the
+// system on, or restarts it when it is already on and powerCycle is set
+func (c *RedfishClient) BootFromSource(ctx context.Context, resourceID, bootSource string, powerCycle bool) error {
+	system, err := c.PrepareBoot(ctx, resourceID, bootSource)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(system.PowerState, PowerStateOn) {
+		if !powerCycle {
+			logrus.Infof("System %s is on and power cycle was not requested, boot override applies on next reboot", resourceID)
+			return nil
+		}
+		return c.Reset(ctx, resourceID, ResetTypeForceRestart)
+	}
+	return c.Reset(ctx, resourceID, ResetTypeOn)
+}
+
+func isImageURL(s string) bool {
+	for _, scheme := range []string{"http://", "https://", "nfs://", "cifs://", "smb://"} {
+		if strings.HasPrefix(strings.ToLower(s), scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListMachines retrieves all systems and converts them to MachineInfo
+func (c *RedfishClient) ListMachines(ctx context.Context) ([]api.MachineInfo, error) {
+	systems, err := c.ListSystems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]api.MachineInfo, len(systems))
+	for i, ref := range systems {
+		mac, err := c.GetMACAddress(ctx, ref.Endpoint, ref.System)
+		if err != nil {
+			logrus.Warnf("Failed to get MAC address for %s: %v", ref.ResourceID, err)
+		}
+		result[i] = systemToMachineInfo(ref.ResourceID, ref.System, mac)
+	}
+	return result, nil
+}
+
+// GetMachine retrieves a single system and converts it to MachineInfo
+func (c *RedfishClient) GetMachine(ctx context.Context, resourceID string) (api.MachineInfo, error) {
+	endpoint, path, err := splitResourceID(resourceID)
+	if err != nil {
+		return api.MachineInfo{}, err
+	}
+	system, err := c.getSystem(ctx, endpoint, path)
+	if err != nil {
+		return api.MachineInfo{}, err
+	}
+	mac, err := c.GetMACAddress(ctx, endpoint, system)
+	if err != nil {
+		return api.MachineInfo{}, err
+	}
+	return systemToMachineInfo(resourceID, system, mac), nil
+}
+
+func systemToMachineInfo(resourceID string, system *ComputerSystem, mac string) api.MachineInfo {
+	hostname := system.HostName
+	if hostname == "" {
+		hostname = system.Name
+	}
+	bootMethod := ""
+	switch system.Boot.BootSourceOverrideMode {
+	case "UEFI":
+		bootMethod = "efi"
+	case "Legacy":
+		bootMethod = "bios"
+	}
+	return api.MachineInfo{
+		Id:             resourceID,
+		Hostname:       hostname,
+		Fqdn:           system.HostName,
+		PowerState:     strings.ToLower(system.PowerState),
+		Architecture:   system.ProcessorSummary.Model,
+		Memory:         fmt.Sprintf("%d", int64(system.MemorySummary.TotalSystemMemoryGiB*1024)),
+		CpuCount:       fmt.Sprintf("%d", system.ProcessorSummary.Count),
+		Status:         system.Status.Health,
+		StatusAction:   system.Status.State,
+		Description:    strings.TrimSpace(system.Manufacturer + " " + system.Model + " " + system.SerialNumber),
+		PowerType:      "redfish",
+		Netboot:        system.Boot.BootSourceOverrideTarget == BootTargetPxe,
+		BiosBootMethod: bootMethod,
+		HardwareUuid:   strings.ToLower(system.UUID),
+		MacAddress:     mac,
+	}
+}
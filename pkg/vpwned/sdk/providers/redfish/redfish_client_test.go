@@ -0,0 +1,365 @@
+package redfish
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/platform9/vjailbreak/pkg/vpwned/api/proto/v1/service"
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/providers"
+)
+
+const (
+	mockSystemPath  = "/redfish/v1/Systems/System.Embedded.1"
+	mockManagerPath = "/redfish/v1/Managers/iDRAC.Embedded.1"
+	mockCDPath      = mockManagerPath + "/VirtualMedia/CD"
+)
+
+// mockBMC is a minimal in-memory stand-in for a single-system Redfish BMC
+type mockBMC struct {
+	mu           sync.Mutex
+	powerState   string
+	bootTarget   string
+	bootEnabled  string
+	mediaImage   string
+	mediaIn      bool
+	resets       []string
+	ejectCount   int
+	username     string
+	password     string
+	noVirtualMed bool
+}
+
+func newMockBMC(t *testing.T) (*mockBMC, *httptest.Server) {
+	t.Helper()
+	m := &mockBMC{powerState: PowerStateOn, bootTarget: "None", bootEnabled: "Disabled", username: "root", password: "calvin"}
+	srv := httptest.NewServer(http.HandlerFunc(m.serveHTTP))
+	t.Cleanup(srv.Close)
+	return m, srv
+}
+
+func writeRedfishError(w http.ResponseWriter, code int, msg string) {
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":                  "Base.1.8.GeneralError",
+			"message":               "A general error has occurred.",
+			"@Message.ExtendedInfo": []map[string]string{{"Message": msg}},
+		},
+	})
+}
+
+func (m *mockBMC) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != m.username || pass != m.password {
+		writeRedfishError(w, http.StatusUnauthorized, "Unable to complete the operation because of invalid credentials.")
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+
+	switch {
+	case r.URL.Path == "/redfish/v1/Systems" && r.Method == http.MethodGet:
+		_ = enc.Encode(map[string]interface{}{"Members": []map[string]string{{"@odata.id": mockSystemPath}}})
+	case r.URL.Path == mockSystemPath && r.Method == http.MethodGet:
+		_ = enc.Encode(map[string]interface{}{
+			"@odata.id":        mockSystemPath,
+			"Id":               "System.Embedded.1",
+			"Name":             "System",
+			"HostName":         "esxi-01.example.com",
+			"Manufacturer":     "Dell Inc.",
+			"Model":            "PowerEdge R650",
+			"SerialNumber":     "ABC1234",
+			"UUID":             "4C4C4544-0042-3010-8052-B4C04F4B3233",
+			"PowerState":       m.powerState,
+			"Status":           map[string]string{"State": "Enabled", "Health": "OK"},
+			"ProcessorSummary": map[string]interface{}{"Count": 2, "Model": "Intel(R) Xeon(R) Gold 6338"},
+			"MemorySummary":    map[string]interface{}{"TotalSystemMemoryGiB": 256},
+			"Boot": map[string]interface{}{
+				"BootSourceOverrideEnabled":                        m.bootEnabled,
+				"BootSourceOverrideTarget":                         m.bootTarget,
+				"BootSourceOverrideMode":                           "UEFI",
+				"BootSourceOverrideTarget@Redfish.AllowableValues": []string{"None", "Pxe", "Cd", "Hdd", "UefiHttp"},
+			},
+			"EthernetInterfaces": map[string]string{"@odata.id": mockSystemPath + "/EthernetInterfaces"},
+			"Links":              map[string]interface{}{"ManagedBy": []map[string]string{{"@odata.id": mockManagerPath}}},
+		})
+	case r.URL.Path == mockSystemPath && r.Method == http.MethodPatch:
+		var body struct {
+			Boot struct {
+				BootSourceOverrideEnabled string
+				BootSourceOverrideTarget  string
+			}
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		m.bootEnabled = body.Boot.BootSourceOverrideEnabled
+		m.bootTarget = body.Boot.BootSourceOverrideTarget
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == mockSystemPath+"/EthernetInterfaces":
+		_ = enc.Encode(map[string]interface{}{"Members": []map[string]string{
+			{"@odata.id": mockSystemPath + "/EthernetInterfaces/NIC.Embedded.1-1-1"},
+			{"@odata.id": mockSystemPath + "/EthernetInterfaces/NIC.Embedded.2-1-1"},
+		}})
+	case r.URL.Path == mockSystemPath+"/EthernetInterfaces/NIC.Embedded.1-1-1":
+		_ = enc.Encode(map[string]interface{}{"Id": "NIC.Embedded.1-1-1", "MACAddress": "B4:96:91:00:00:01", "Status": map[string]string{"State": "Disabled"}})
+	case r.URL.Path == mockSystemPath+"/EthernetInterfaces/NIC.Embedded.2-1-1":
+		_ = enc.Encode(map[string]interface{}{"Id": "NIC.Embedded.2-1-1", "MACAddress": "B4:96:91:00:00:02", "Status": map[string]string{"State": "Enabled"}})
+	case r.URL.Path == mockSystemPath+"/Actions/ComputerSystem.Reset" && r.Method == http.MethodPost:
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch body["ResetType"] {
+		case ResetTypeOn, ResetTypeForceRestart:
+			m.powerState = PowerStateOn
+		case ResetTypeForceOff:
+			m.powerState = PowerStateOff
+		default:
+			writeRedfishError(w, http.StatusBadRequest, "The value for ResetType is not supported.")
+			return
+		}
+		m.resets = append(m.resets, body["ResetType"])
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == mockManagerPath:
+		if m.noVirtualMed {
+			_ = enc.Encode(map[string]interface{}{"Id": "iDRAC.Embedded.1"})
+			return
+		}
+		_ = enc.Encode(map[string]interface{}{"Id": "iDRAC.Embedded.1", "VirtualMedia": map[string]string{"@odata.id": mockManagerPath + "/VirtualMedia"}})
+	case r.URL.Path == mockManagerPath+"/VirtualMedia":
+		_ = enc.Encode(map[string]interface{}{"Members": []map[string]string{
+			{"@odata.id": mockManagerPath + "/VirtualMedia/RemovableDisk"},
+			{"@odata.id": mockCDPath},
+		}})
+	case r.URL.Path == mockManagerPath+"/VirtualMedia/RemovableDisk":
+		_ = enc.Encode(map[string]interface{}{"@odata.id": mockManagerPath + "/VirtualMedia/RemovableDisk", "MediaTypes": []string{"USBStick"}})
+	case r.URL.Path == mockCDPath:
+		_ = enc.Encode(map[string]interface{}{"@odata.id": mockCDPath, "MediaTypes": []string{"CD", "DVD"}, "Image": m.mediaImage, "Inserted": m.mediaIn})
+	case r.URL.Path == mockCDPath+"/Actions/VirtualMedia.InsertMedia" && r.Method == http.MethodPost:
+		if m.mediaIn {
+			writeRedfishError(w, http.StatusBadRequest, "Virtual Media is already connected.")
+			return
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		m.mediaImage, _ = body["Image"].(string)
+		m.mediaIn = true
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == mockCDPath+"/Actions/VirtualMedia.EjectMedia" && r.Method == http.MethodPost:
+		m.mediaImage = ""
+		m.mediaIn = false
+		m.ejectCount++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeRedfishError(w, http.StatusNotFound, "The resource "+r.URL.Path+" was not found.")
+	}
+}
+
+func connectMock(t *testing.T, endpoints ...string) *RedfishProvider {
+	t.Helper()
+	p := &RedfishProvider{}
+	err := p.Connect(providers.BMAccessInfo{BaseURL: strings.Join(endpoints, ","), Username: "root", Password: "calvin"})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	p.client.PollInterval = time.Millisecond
+	return p
+}
+
+func TestConnectRejectsBadCredentials(t *testing.T) {
+	_, srv := newMockBMC(t)
+	p := &RedfishProvider{}
+	err := p.Connect(providers.BMAccessInfo{BaseURL: srv.URL, Username: "root", Password: "wrong"})
+	if err == nil || !strings.Contains(err.Error(), "invalid credentials") {
+		t.Fatalf("expected credentials error, got %v", err)
+	}
+}
+
+func TestConnectRequiresEndpoint(t *testing.T) {
+	p := &RedfishProvider{}
+	if err := p.Connect(providers.BMAccessInfo{BaseURL: " , "}); err == nil {
+		t.Fatal("expected error without endpoints")
+	}
+}
+
+func TestListResourcesAcrossBMCs(t *testing.T) {
+	_, srv1 := newMockBMC(t)
+	_, srv2 := newMockBMC(t)
+	p := connectMock(t, srv1.URL, srv2.URL+"/redfish/v1/")
+
+	machines, err := p.ListResources(context.Background())
+	if err != nil {
+		t.Fatalf("ListResources: %v", err)
+	}
+	if len(machines) != 2 {
+		t.Fatalf("expected 2 machines, got %d", len(machines))
+	}
+	if machines[0].Id != srv1.URL+mockSystemPath || machines[1].Id != srv2.URL+mockSystemPath {
+		t.Errorf("unexpected resource ids: %s, %s", machines[0].Id, machines[1].Id)
+	}
+	m := &machines[0]
+	if m.HardwareUuid != "4c4c4544-0042-3010-8052-b4c04f4b3233" {
+		t.Errorf("expected lower-cased SMBIOS UUID, got %s", m.HardwareUuid)
+	}
+	if m.MacAddress != "b4:96:91:00:00:02" {
+		t.Errorf("expected MAC of enabled NIC, got %s", m.MacAddress)
+	}
+	if m.PowerState != "on" || m.CpuCount != "2" || m.Memory != "262144" || m.BiosBootMethod != "efi" {
+		t.Errorf("unexpected inventory: power=%s cpus=%s mem=%s boot=%s", m.PowerState, m.CpuCount, m.Memory, m.BiosBootMethod)
+	}
+	if m.Hostname != "esxi-01.example.com" || !strings.Contains(m.Description, "PowerEdge R650") {
+		t.Errorf("unexpected identity: %s %s", m.Hostname, m.Description)
+	}
+}
+
+func TestGetResourceInfoInvalidID(t *testing.T) {
+	_, srv := newMockBMC(t)
+	p := connectMock(t, srv.URL)
+	if _, err := p.GetResourceInfo(context.Background(), "System.Embedded.1"); err == nil {
+		t.Fatal("expected error for resource id without endpoint")
+	}
+}
+
+func TestSetResourcePower(t *testing.T) {
+	m, srv := newMockBMC(t)
+	p := connectMock(t, srv.URL)
+	id := srv.URL + mockSystemPath
+
+	if err := p.SetResourcePower(context.Background(), id, api.PowerStatus_POWERED_OFF); err != nil {
+		t.Fatalf("power off: %v", err)
+	}
+	if m.powerState != PowerStateOff {
+		t.Errorf("expected Off, got %s", m.powerState)
+	}
+	resp, err := p.IsBMRunning(context.Background(), api.IsBMRunningRequest{ResourceId: id})
+	if err != nil || resp.IsRunning {
+		t.Errorf("expected not running, got %v %v", resp.IsRunning, err)
+	}
+	if err := p.SetResourcePower(context.Background(), id, api.PowerStatus_POWERED_ON); err != nil {
+		t.Fatalf("power on: %v", err)
+	}
+	if m.powerState != PowerStateOn {
+		t.Errorf("expected On, got %s", m.powerState)
+	}
+}
+
+func TestSetBM2PXEBoot(t *testing.T) {
+	m, srv := newMockBMC(t)
+	p := connectMock(t, srv.URL)
+
+	if err := p.SetBM2PXEBoot(context.Background(), srv.URL+mockSystemPath, true, nil); err != nil {
+		t.Fatalf("SetBM2PXEBoot: %v", err)
+	}
+	if m.bootTarget != BootTargetPxe || m.bootEnabled != "Once" {
+		t.Errorf("expected one-time Pxe boot, got %s/%s", m.bootEnabled, m.bootTarget)
+	}
+	if len(m.resets) != 1 || m.resets[0] != ResetTypeForceRestart {
+		t.Errorf("expected a single ForceRestart, got %v", m.resets)
+	}
+}
+
+func TestReclaimBMWithVirtualMedia(t *testing.T) {
+	m, srv := newMockBMC(t)
+	p := connectMock(t, srv.URL)
+	m.mediaIn = true
+	m.mediaImage = "http://old/esxi.iso"
+
+	err := p.ReclaimBM(context.Background(), api.ReclaimBMRequest{
+		ResourceId: srv.URL + mockSystemPath,
+		PowerCycle: true,
+		BootSource: &api.BootsourceSelections{Release: "https://images.example.com/pcd-host.iso"},
+	})
+	if err != nil {
+		t.Fatalf("ReclaimBM: %v", err)
+	}
+	if m.ejectCount != 1 || m.mediaImage != "https://images.example.com/pcd-host.iso" {
+		t.Errorf("expected old media ejected and new inserted, eject=%d image=%s", m.ejectCount, m.mediaImage)
+	}
+	if m.bootTarget != BootTargetCd {
+		t.Errorf("expected Cd boot target, got %s", m.bootTarget)
+	}
+	if len(m.resets) != 1 || m.resets[0] != ResetTypeForceRestart {
+		t.Errorf("expected ForceRestart, got %v", m.resets)
+	}
+}
+
+func TestReclaimBMManualPowerControl(t *testing.T) {
+	m, srv := newMockBMC(t)
+	p := connectMock(t, srv.URL)
+	m.powerState = PowerStateOff
+
+	err := p.ReclaimBM(context.Background(), api.ReclaimBMRequest{
+		ResourceId:         srv.URL + mockSystemPath,
+		ManualPowerControl: true,
+		BootSource:         &api.BootsourceSelections{Release: "jammy"},
+	})
+	if err != nil {
+		t.Fatalf("ReclaimBM: %v", err)
+	}
+	if m.bootTarget != BootTargetPxe {
+		t.Errorf("expected Pxe fallback for non-URL release, got %s", m.bootTarget)
+	}
+	if len(m.resets) != 0 || m.powerState != PowerStateOff {
+		t.Errorf("expected power untouched, resets=%v power=%s", m.resets, m.powerState)
+	}
+}
+
+func TestReclaimBMNoVirtualMedia(t *testing.T) {
+	m, srv := newMockBMC(t)
+	p := connectMock(t, srv.URL)
+	m.noVirtualMed = true
+
+	err := p.ReclaimBM(context.Background(), api.ReclaimBMRequest{
+		ResourceId: srv.URL + mockSystemPath,
+		BootSource: &api.BootsourceSelections{ResourceURI: "https://images.example.com/pcd-host.iso"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "virtual media") {
+		t.Fatalf("expected virtual media error, got %v", err)
+	}
+}
+
+func TestDeployMachinePowersOnOffSystem(t *testing.T) {
+	m, srv := newMockBMC(t)
+	p := connectMock(t, srv.URL)
+	m.powerState = PowerStateOff
+
+	resp, err := p.DeployMachine(context.Background(), api.DeployMachineRequest{ResourceId: srv.URL + mockSystemPath})
+	if err != nil || !resp.Success {
+		t.Fatalf("DeployMachine: %v", err)
+	}
+	if len(m.resets) != 1 || m.resets[0] != ResetTypeOn {
+		t.Errorf("expected On reset, got %v", m.resets)
+	}
+}
+
+func TestListBootSource(t *testing.T) {
+	_, srv := newMockBMC(t)
+	p := connectMock(t, srv.URL)
+
+	sources, err := p.ListBootSource(context.Background(), api.ListBootSourceRequest{})
+	if err != nil {
+		t.Fatalf("ListBootSource: %v", err)
+	}
+	var targets []string
+	for i := range sources {
+		targets = append(targets, sources[i].Release)
+	}
+	if strings.Join(targets, ",") != "Pxe,Cd,Hdd,UefiHttp" {
+		t.Errorf("unexpected boot sources: %v", targets)
+	}
+}
+
+func TestProviderRegistered(t *testing.T) {
+	p, err := providers.GetProvider("REDFISH")
+	if err != nil {
+		t.Fatalf("GetProvider: %v", err)
+	}
+	if p.WhoAmI() != RedfishProviderName {
+		t.Errorf("unexpected provider %s", p.WhoAmI())
+	}
+}
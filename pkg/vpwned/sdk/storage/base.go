@@ -2,11 +2,14 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -193,3 +196,21 @@ func SliceContains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// HostNameForAdapters names the host object registered on an array for the
+// adapters of an ESXi host. Migrations from different ESXi hosts share the
+// prefix, so the name ends with a hash of the adapters; the prefix is cut
+// short when the name would exceed maxLen.
+func HostNameForAdapters(prefix string, hbaIdentifiers []string, maxLen int) string {
+	ids := make([]string, 0, len(hbaIdentifiers))
+	for _, id := range hbaIdentifiers {
+		ids = append(ids, strings.ToLower(id))
+	}
+	sort.Strings(ids)
+	sum := sha256.Sum256([]byte(strings.Join(ids, ",")))
+	suffix := "-" + hex.EncodeToString(sum[:])[:8]
+	if len(prefix)+len(suffix) > maxLen {
+		prefix = strings.TrimRight(prefix[:max(maxLen-len(suffix), 0)], "-")
+	}
+	return prefix + suffix
+}
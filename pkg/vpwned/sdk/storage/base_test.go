@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHostNameForAdapters(t *testing.T) {
+	name := HostNameForAdapters("vjailbreak-xcopy", []string{"iqn.b", "iqn.a"}, 64)
+	if name != HostNameForAdapters("vjailbreak-xcopy", []string{"IQN.A", "iqn.b"}, 64) {
+		t.Error("host name depends on the order or case of the adapters")
+	}
+	if name == HostNameForAdapters("vjailbreak-xcopy", []string{"iqn.c"}, 64) {
+		t.Error("different adapters share a host name")
+	}
+	if !strings.HasPrefix(name, "vjailbreak-xcopy-") || len(name) != len("vjailbreak-xcopy-")+8 {
+		t.Errorf("HostNameForAdapters() = %q, want the prefix and 8 hex digits", name)
+	}
+
+	short := HostNameForAdapters("vjailbreak-xcopy", []string{"iqn.a"}, 20)
+	if len(short) > 20 || !strings.HasPrefix(short, "vjailbreak-") || strings.Contains(short, "--") {
+		t.Errorf("HostNameForAdapters() with 20 characters = %q", short)
+	}
+}
@@ -0,0 +1,423 @@
+package hpalletra
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/fcutil"
+)
+
+const (
+	fakeUser     = "3paradm"
+	fakePassword = "Password123!"
+)
+
+// fakeWSAPI is an in-memory HPE WSAPI covering the endpoints the provider
+// uses. Every request but the login needs the key of an open session.
+type fakeWSAPI struct {
+	mu       sync.Mutex
+	server   *httptest.Server
+	system   HPESystem
+	cpgs     []HPECPG
+	volumes  []HPEVolume
+	hosts    []HPEHost
+	hostSets []HPEHostSet
+	vluns    []HPEVLUN
+	sessions int
+	key      string
+	nextID   int
+	// requests records "METHOD /path" of every request, for assertions
+	requests []string
+}
+
+func newFakeWSAPI(t *testing.T, cpgs ...string) *fakeWSAPI {
+	t.Helper()
+	f := &fakeWSAPI{
+		system: HPESystem{ID: 4711, Name: "primera-a", Model: "HPE Primera A630", SerialNumber: "4UW0001234", SystemVersion: "4.5.21"},
+	}
+	for _, name := range cpgs {
+		f.nextID++
+		f.cpgs = append(f.cpgs, HPECPG{ID: f.nextID, Name: name, UUID: fmt.Sprintf("cpg-uuid-%d", f.nextID)})
+	}
+	f.server = httptest.NewTLSServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// accessInfo returns the access info of the fake for Connect
+func (f *fakeWSAPI) accessInfo(options map[string]string) storage.StorageAccessInfo {
+	return storage.StorageAccessInfo{
+		Hostname:            strings.TrimPrefix(f.server.URL, "https://"),
+		Username:            fakeUser,
+		Password:            fakePassword,
+		SkipSSLVerification: true,
+		VendorType:          VendorName,
+		ProviderOptions:     options,
+	}
+}
+
+// expireSession invalidates the current session
+func (f *fakeWSAPI) expireSession() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.key = ""
+}
+
+func (f *fakeWSAPI) addVolume(name string, sizeMiB int64) HPEVolume {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.createVolume(name, f.cpgs[0].Name, sizeMiB)
+}
+
+func (f *fakeWSAPI) addHost(host HPEHost) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	host.ID = f.nextID
+	f.hosts = append(f.hosts, host)
+}
+
+func (f *fakeWSAPI) addHostSet(name string, members ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	f.hostSets = append(f.hostSets, HPEHostSet{ID: f.nextID, Name: name, SetMembers: members})
+}
+
+func (f *fakeWSAPI) hostSet(name string) (HPEHostSet, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, hs := range f.hostSets {
+		if hs.Name == name {
+			return hs, true
+		}
+	}
+	return HPEHostSet{}, false
+}
+
+func (f *fakeWSAPI) createVolume(name, cpg string, sizeMiB int64) HPEVolume {
+	f.nextID++
+	v := HPEVolume{
+		ID:               f.nextID,
+		Name:             name,
+		SizeMiB:          sizeMiB,
+		WWN:              fmt.Sprintf("%s%025X", strings.ToUpper(HPEProviderID), f.nextID),
+		UUID:             fmt.Sprintf("vol-uuid-%d", f.nextID),
+		UserCPG:          cpg,
+		CreationTime8601: "2026-10-17T00:00:00+00:00",
+	}
+	f.volumes = append(f.volumes, v)
+	return v
+}
+
+func (f *fakeWSAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+	var body map[string]json.RawMessage
+	if r.Body != nil && r.Method != http.MethodGet {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	if path == "/credentials" && r.Method == http.MethodPost {
+		var user, password string
+		_ = json.Unmarshal(body["user"], &user)
+		_ = json.Unmarshal(body["password"], &password)
+		if user != fakeUser || password != fakePassword {
+			writeFakeError(w, http.StatusForbidden, 5, "invalid username or password")
+			return
+		}
+		f.sessions++
+		f.key = fmt.Sprintf("0-key-%d", f.sessions)
+		writeFakeJSON(w, http.StatusCreated, map[string]string{"key": f.key})
+		return
+	}
+	if f.key == "" || r.Header.Get(sessionKeyHeader) != f.key {
+		writeFakeError(w, http.StatusForbidden, 6, "invalid session key")
+		return
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case segments[0] == "credentials" && len(segments) == 2 && r.Method == http.MethodDelete:
+		f.key = ""
+		w.WriteHeader(http.StatusOK)
+	case path == "/system":
+		writeFakeJSON(w, http.StatusOK, f.system)
+	case path == "/cpgs":
+		writeFakeJSON(w, http.StatusOK, members(f.cpgs))
+	case segments[0] == "volumes":
+		f.serveVolumes(w, r, segments, body)
+	case segments[0] == "hosts":
+		f.serveHosts(w, r, segments, body)
+	case segments[0] == "hostsets":
+		f.serveHostSets(w, r, segments, body)
+	case segments[0] == "vluns":
+		f.serveVLUNs(w, r, segments, body)
+	default:
+		writeFakeError(w, http.StatusNotFound, 0, "resource not found: "+path)
+	}
+}
+
+func (f *fakeWSAPI) serveVolumes(w http.ResponseWriter, r *http.Request, segments []string, body map[string]json.RawMessage) {
+	if len(segments) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeFakeJSON(w, http.StatusOK, members(f.volumes))
+		case http.MethodPost:
+			var name, cpg string
+			var sizeMiB int64
+			_ = json.Unmarshal(body["name"], &name)
+			_ = json.Unmarshal(body["cpg"], &cpg)
+			_ = json.Unmarshal(body["sizeMiB"], &sizeMiB)
+			if len(name) > maxVolumeName {
+				writeFakeError(w, http.StatusBadRequest, 36, "volume name exceeds 31 characters")
+				return
+			}
+			for _, v := range f.volumes {
+				if v.Name == name {
+					writeFakeError(w, http.StatusConflict, 22, "volume exists")
+					return
+				}
+			}
+			found := false
+			for _, c := range f.cpgs {
+				found = found || c.Name == cpg
+			}
+			if !found {
+				writeFakeError(w, http.StatusNotFound, 15, "CPG does not exist")
+				return
+			}
+			f.createVolume(name, cpg, sizeMiB)
+			w.WriteHeader(http.StatusCreated)
+		}
+		return
+	}
+
+	for i, v := range f.volumes {
+		if v.Name != segments[1] {
+			continue
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeFakeJSON(w, http.StatusOK, v)
+		case http.MethodPost:
+			f.serveVolumeAction(w, v, body)
+		case http.MethodDelete:
+			for _, vlun := range f.vluns {
+				if vlun.VolumeName == v.Name {
+					writeFakeError(w, http.StatusForbidden, 34, "volume is exported")
+					return
+				}
+			}
+			f.volumes = append(f.volumes[:i], f.volumes[i+1:]...)
+			w.WriteHeader(http.StatusOK)
+		}
+		return
+	}
+	writeFakeError(w, http.StatusNotFound, 23, "volume does not exist")
+}
+
+// serveVolumeAction takes snapshots of volumes and copies them
+func (f *fakeWSAPI) serveVolumeAction(w http.ResponseWriter, v HPEVolume, body map[string]json.RawMessage) {
+	var action string
+	var parameters map[string]json.RawMessage
+	_ = json.Unmarshal(body["action"], &action)
+	_ = json.Unmarshal(body["parameters"], &parameters)
+	var name, cpg string
+	switch action {
+	case "createSnapshot":
+		_ = json.Unmarshal(parameters["name"], &name)
+		cpg = v.UserCPG
+	case "createPhysicalCopy":
+		_ = json.Unmarshal(parameters["destVolume"], &name)
+		_ = json.Unmarshal(parameters["destCPG"], &cpg)
+	default:
+		writeFakeError(w, http.StatusBadRequest, 0, "unsupported action "+action)
+		return
+	}
+	if len(name) > maxVolumeName {
+		writeFakeError(w, http.StatusBadRequest, 36, "volume name exceeds 31 characters")
+		return
+	}
+	for _, existing := range f.volumes {
+		if existing.Name == name {
+			writeFakeError(w, http.StatusConflict, 22, "volume exists")
+			return
+		}
+	}
+	f.createVolume(name, cpg, v.SizeMiB)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (f *fakeWSAPI) serveHosts(w http.ResponseWriter, r *http.Request, segments []string, body map[string]json.RawMessage) {
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, members(f.hosts))
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		var host HPEHost
+		var persona int
+		var fcWWNs, iscsiNames []string
+		_ = json.Unmarshal(body["name"], &host.Name)
+		_ = json.Unmarshal(body["persona"], &persona)
+		_ = json.Unmarshal(body["FCWWNs"], &fcWWNs)
+		_ = json.Unmarshal(body["iSCSINames"], &iscsiNames)
+		if len(host.Name) > maxHostName || persona != personaVMware {
+			writeFakeError(w, http.StatusBadRequest, 29, "invalid host name or persona")
+			return
+		}
+		for _, h := range f.hosts {
+			if h.Name == host.Name {
+				writeFakeError(w, http.StatusConflict, 16, "host exists")
+				return
+			}
+			for _, p := range h.FCPaths {
+				for _, wwn := range fcWWNs {
+					if fcutil.EqualWWNs(p.WWN, wwn) {
+						writeFakeError(w, http.StatusConflict, 35, "WWN is used by host "+h.Name)
+						return
+					}
+				}
+			}
+		}
+		for _, wwn := range fcWWNs {
+			host.FCPaths = append(host.FCPaths, HPEFCPath{WWN: wwn})
+		}
+		for _, name := range iscsiNames {
+			host.ISCSIPaths = append(host.ISCSIPaths, HPEISCSIPath{Name: name})
+		}
+		f.nextID++
+		host.ID = f.nextID
+		f.hosts = append(f.hosts, host)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, 0, "unsupported operation")
+	}
+}
+
+func (f *fakeWSAPI) serveHostSets(w http.ResponseWriter, r *http.Request, segments []string, body map[string]json.RawMessage) {
+	if len(segments) == 1 && r.Method == http.MethodPost {
+		var hs HPEHostSet
+		_ = json.Unmarshal(body["name"], &hs.Name)
+		_ = json.Unmarshal(body["setmembers"], &hs.SetMembers)
+		if len(hs.Name) > maxSetName {
+			writeFakeError(w, http.StatusBadRequest, 36, "set name exceeds 27 characters")
+			return
+		}
+		for _, existing := range f.hostSets {
+			if existing.Name == hs.Name {
+				writeFakeError(w, http.StatusConflict, 101, "set exists")
+				return
+			}
+		}
+		f.nextID++
+		hs.ID = f.nextID
+		f.hostSets = append(f.hostSets, hs)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	if len(segments) != 2 {
+		writeFakeError(w, http.StatusMethodNotAllowed, 0, "unsupported operation")
+		return
+	}
+
+	for i := range f.hostSets {
+		if f.hostSets[i].Name != segments[1] {
+			continue
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeFakeJSON(w, http.StatusOK, f.hostSets[i])
+		case http.MethodPut:
+			var action int
+			var setMembers []string
+			_ = json.Unmarshal(body["action"], &action)
+			_ = json.Unmarshal(body["setmembers"], &setMembers)
+			if action != setMemberAdd {
+				writeFakeError(w, http.StatusBadRequest, 0, "unsupported action "+strconv.Itoa(action))
+				return
+			}
+			f.hostSets[i].SetMembers = append(f.hostSets[i].SetMembers, setMembers...)
+			w.WriteHeader(http.StatusOK)
+		}
+		return
+	}
+	writeFakeError(w, http.StatusNotFound, 102, "set does not exist")
+}
+
+func (f *fakeWSAPI) serveVLUNs(w http.ResponseWriter, r *http.Request, segments []string, body map[string]json.RawMessage) {
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		query := strings.Trim(r.URL.Query().Get("query"), `"`)
+		volumeName, ok := strings.CutPrefix(query, "volumeName EQ ")
+		if !ok {
+			writeFakeError(w, http.StatusBadRequest, 0, "unsupported query "+query)
+			return
+		}
+		out := []HPEVLUN{}
+		for _, vlun := range f.vluns {
+			if vlun.VolumeName == volumeName {
+				out = append(out, vlun)
+			}
+		}
+		writeFakeJSON(w, http.StatusOK, members(out))
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		var vlun HPEVLUN
+		var autoLun bool
+		_ = json.Unmarshal(body["volumeName"], &vlun.VolumeName)
+		_ = json.Unmarshal(body["hostname"], &vlun.Hostname)
+		_ = json.Unmarshal(body["autoLun"], &autoLun)
+		if !autoLun {
+			writeFakeError(w, http.StatusBadRequest, 0, "expected autoLun")
+			return
+		}
+		for _, existing := range f.vluns {
+			if existing.VolumeName == vlun.VolumeName && existing.Hostname == vlun.Hostname {
+				writeFakeError(w, http.StatusConflict, 73, "VLUN exists")
+				return
+			}
+		}
+		vlun.LUN = len(f.vluns) + 1
+		vlun.Type = 5 // host set VLUN
+		f.vluns = append(f.vluns, vlun)
+		w.WriteHeader(http.StatusCreated)
+	case len(segments) == 2 && r.Method == http.MethodDelete:
+		parts := strings.Split(segments[1], ",")
+		if len(parts) != 3 {
+			writeFakeError(w, http.StatusBadRequest, 0, "malformed VLUN id "+segments[1])
+			return
+		}
+		for i, vlun := range f.vluns {
+			if vlun.VolumeName == parts[0] && strconv.Itoa(vlun.LUN) == parts[1] && vlun.Hostname == parts[2] {
+				f.vluns = append(f.vluns[:i], f.vluns[i+1:]...)
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+		}
+		writeFakeError(w, http.StatusNotFound, 19, "VLUN does not exist")
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, 0, "unsupported operation")
+	}
+}
+
+func members[T any](items []T) hpeMembers[T] {
+	return hpeMembers[T]{Total: len(items), Members: append([]T{}, items...)}
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeFakeError(w http.ResponseWriter, status, code int, desc string) {
+	writeFakeJSON(w, status, map[string]interface{}{"code": code, "desc": desc})
+}
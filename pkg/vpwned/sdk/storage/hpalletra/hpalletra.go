@@ -0,0 +1,848 @@
+package hpalletra
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/fcutil"
+	"k8s.io/klog/v2"
+)
+
+// HPEProviderID is the NAA prefix of HPE 3PAR, Primera and Alletra 9000
+// volumes (NAA type 6 with the 3PAR OUI)
+const HPEProviderID = "60002ac"
+
+// VendorName is the canonical vendor-type string registered with the storage
+// SDK and persisted on ArrayCreds.spec.vendorType.
+const VendorName = "hpalletra"
+
+// ProviderOptions keys read by the HPE provider from
+// storage.StorageAccessInfo.ProviderOptions. Unknown keys are ignored.
+const (
+	// OptionCPG is the Common Provisioning Group new volumes are created in
+	OptionCPG = "cpg"
+)
+
+const (
+	// defaultWSAPIPort is the HTTPS port of the WSAPI service
+	defaultWSAPIPort = "443"
+	// sessionKeyHeader carries the key of the WSAPI session
+	sessionKeyHeader = "X-HP3PAR-WSAPI-SessionKey"
+	// maxVolumeName and maxHostName are the longest volume and host names
+	// the array accepts, maxSetName the longest host set name
+	maxVolumeName = 31
+	maxHostName   = 31
+	maxSetName    = 27
+	// volumeSizeAlignment is the size new volumes are rounded up to. The
+	// Cinder driver sizes managed volumes up to a whole number of GiB.
+	volumeSizeAlignment = 1024 * 1024 * 1024
+	// personaVMware is the host persona of ESXi hosts
+	personaVMware = 11
+	// setMemberAdd is the action adding members to a host set
+	setMemberAdd = 1
+)
+
+func init() {
+	storage.RegisterStorageProvider(VendorName, &HPEStorageProvider{})
+}
+
+// HPEStorageProvider implements StorageProvider for HPE Alletra 9000, Primera
+// and 3PAR arrays through the Web Services API (WSAPI). CPG is the Common
+// Provisioning Group new volumes are created in; when it is empty the
+// provider falls back to the only CPG of the array.
+type HPEStorageProvider struct {
+	storage.BaseStorageProvider
+	CPG        string
+	sessionKey string
+}
+
+// WSAPI response structures
+type HPESystem struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	Model         string `json:"model"`
+	SerialNumber  string `json:"serialNumber"`
+	SystemVersion string `json:"systemVersion"`
+}
+
+type HPECPG struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	UUID string `json:"uuid"`
+}
+
+type HPEVolume struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	SizeMiB          int64  `json:"sizeMiB"`
+	WWN              string `json:"wwn"`
+	UUID             string `json:"uuid"`
+	UserCPG          string `json:"userCPG"`
+	CreationTime8601 string `json:"creationTime8601"`
+}
+
+type HPEFCPath struct {
+	WWN string `json:"wwn"`
+}
+
+type HPEISCSIPath struct {
+	Name string `json:"name"`
+}
+
+type HPEHost struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	FCPaths    []HPEFCPath    `json:"FCPaths"`
+	ISCSIPaths []HPEISCSIPath `json:"iSCSIPaths"`
+}
+
+type HPEHostSet struct {
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	SetMembers []string `json:"setmembers"`
+}
+
+type HPEVLUN struct {
+	LUN        int    `json:"lun"`
+	VolumeName string `json:"volumeName"`
+	Hostname   string `json:"hostname"`
+	Type       int    `json:"type"`
+}
+
+type hpeMembers[T any] struct {
+	Total   int `json:"total"`
+	Members []T `json:"members"`
+}
+
+// APIError is an error response of the WSAPI
+type APIError struct {
+	StatusCode int
+	Code       int
+	Desc       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("HPE WSAPI error (status %d, code %d): %s", e.StatusCode, e.Code, e.Desc)
+}
+
+// isNotFound reports whether err is a WSAPI 404 response
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// isConflict reports whether err is a WSAPI 409 response, an object of that name exists
+func isConflict(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict
+}
+
+// Connect opens a WSAPI session. The hostname may carry the WSAPI port;
+// WSAPI listens on 443 on Primera and Alletra and on 8080 on 3PAR.
+func (h *HPEStorageProvider) Connect(ctx context.Context, accessInfo storage.StorageAccessInfo) error {
+	h.AccessInfo = accessInfo
+	h.Config = storage.VendorConfig{
+		NAAPrefix: HPEProviderID,
+		Name:      "HPE",
+	}
+	host := accessInfo.Hostname
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, defaultWSAPIPort)
+	}
+	h.BaseURL = fmt.Sprintf("https://%s/api/v1", host)
+	h.Username = accessInfo.Username
+	h.Password = accessInfo.Password
+	h.CPG = strings.TrimSpace(accessInfo.ProviderOptions[OptionCPG])
+
+	h.InitHTTPClient(accessInfo.SkipSSLVerification)
+	if err := h.login(ctx); err != nil {
+		return fmt.Errorf("failed to connect to HPE array: %w", err)
+	}
+	h.SetConnected(true)
+
+	system, err := h.getSystem(ctx)
+	if err != nil {
+		h.SetConnected(false)
+		return fmt.Errorf("failed to connect to HPE array: %w", err)
+	}
+	klog.Infof("Connected to HPE array: %s, Model: %s, Serial: %s, Version: %s (CPG: %q)",
+		system.Name, system.Model, system.SerialNumber, system.SystemVersion, h.CPG)
+	return nil
+}
+
+// Disconnect closes the WSAPI session; the array allows few sessions per user
+func (h *HPEStorageProvider) Disconnect() error {
+	if h.sessionKey != "" {
+		if err := h.doRequest(context.Background(), http.MethodDelete, "/credentials/"+h.sessionKey, nil, nil); err != nil {
+			klog.Warningf("Failed to close HPE WSAPI session: %v", err)
+		}
+	}
+	h.sessionKey = ""
+	h.SetConnected(false)
+	return nil
+}
+
+// ValidateCredentials validates the credentials
+func (h *HPEStorageProvider) ValidateCredentials(ctx context.Context) error {
+	if !h.GetConnected() {
+		if err := h.Connect(ctx, h.AccessInfo); err != nil {
+			return err
+		}
+	}
+
+	if _, err := h.getSystem(ctx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
+
+// CreateVolume creates a thin provisioned virtual volume in the CPG, rounded
+// up to a whole number of GiB. Names longer than the array allows are
+// shortened, the returned volume carries the name it was created with.
+func (h *HPEStorageProvider) CreateVolume(volumeName string, size int64) (storage.Volume, error) {
+	ctx := context.Background()
+
+	cpg, err := h.targetCPG(ctx)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	name := arrayVolumeName(volumeName)
+	alignedSize := (size + volumeSizeAlignment - 1) / volumeSizeAlignment * volumeSizeAlignment
+	reqBody := map[string]interface{}{
+		"name":    name,
+		"cpg":     cpg,
+		"sizeMiB": alignedSize / (1024 * 1024),
+		"tpvv":    true,
+		"comment": "Created by vJailbreak for " + volumeName,
+	}
+
+	klog.Infof("Creating HPE volume %s in CPG %s with size %d (requested %d)", name, cpg, alignedSize, size)
+	if err := h.doRequest(ctx, http.MethodPost, "/volumes", reqBody, nil); err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to create volume %s: %w", name, err)
+	}
+
+	v, err := h.getVolume(ctx, name)
+	if err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to get created volume %s: %w", name, err)
+	}
+	klog.Infof("Created HPE volume: %s, WWN: %s", v.Name, v.WWN)
+	return h.toVolume(*v), nil
+}
+
+// DeleteVolume deletes a virtual volume
+func (h *HPEStorageProvider) DeleteVolume(volumeName string) error {
+	klog.Infof("Deleting HPE volume: %s", volumeName)
+	if err := h.doRequest(context.Background(), http.MethodDelete, "/volumes/"+url.PathEscape(volumeName), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete volume %s: %w", volumeName, err)
+	}
+	return nil
+}
+
+// GetVolumeInfo retrieves information about a virtual volume
+func (h *HPEStorageProvider) GetVolumeInfo(volumeName string) (storage.VolumeInfo, error) {
+	v, err := h.getVolume(context.Background(), volumeName)
+	if err != nil {
+		return storage.VolumeInfo{}, fmt.Errorf("failed to get volume %s: %w", volumeName, err)
+	}
+	return h.toVolumeInfo(*v), nil
+}
+
+// ListAllVolumes retrieves all virtual volumes with their NAA identifiers
+func (h *HPEStorageProvider) ListAllVolumes() ([]storage.VolumeInfo, error) {
+	var response hpeMembers[HPEVolume]
+	if err := h.doRequest(context.Background(), http.MethodGet, "/volumes", nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	volumeInfos := make([]storage.VolumeInfo, 0, len(response.Members))
+	for _, v := range response.Members {
+		if v.WWN == "" {
+			continue
+		}
+		volumeInfos = append(volumeInfos, h.toVolumeInfo(v))
+	}
+	return volumeInfos, nil
+}
+
+// GetAllVolumeNAAs retrieves NAA identifiers for all virtual volumes
+func (h *HPEStorageProvider) GetAllVolumeNAAs() ([]string, error) {
+	return h.BaseStorageProvider.GetAllVolumeNAAs(h.ListAllVolumes)
+}
+
+// CreateOrUpdateInitiatorGroup makes sure the hosts holding the ESXi adapters
+// are members of the host set initiatorGroupName, which volumes are then
+// exported to. A host of the adapters is registered, with the VMware persona,
+// when the array has none. Supports iSCSI (IQN) and Fibre Channel
+// (fc.WWNN:WWPN) adapters.
+func (h *HPEStorageProvider) CreateOrUpdateInitiatorGroup(initiatorGroupName string, hbaIdentifiers []string) (storage.MappingContext, error) {
+	ctx := context.Background()
+
+	if len(initiatorGroupName) > maxSetName {
+		return nil, fmt.Errorf("host set name %q is longer than %d characters", initiatorGroupName, maxSetName)
+	}
+	wwpns, iqns, err := splitAdapters(hbaIdentifiers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalise HBA identifiers: %w", err)
+	}
+
+	var hosts hpeMembers[HPEHost]
+	if err := h.doRequest(ctx, http.MethodGet, "/hosts", nil, &hosts); err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %w", err)
+	}
+	var matchedHosts []string
+	for _, host := range hosts.Members {
+		if hostMatches(host, wwpns, iqns) {
+			klog.Infof("Matched HPE host %s", host.Name)
+			matchedHosts = append(matchedHosts, host.Name)
+		}
+	}
+
+	if len(matchedHosts) == 0 {
+		name, err := h.createHost(ctx, storage.HostNameForAdapters(initiatorGroupName, hbaIdentifiers, maxHostName), wwpns, iqns)
+		if err != nil {
+			return nil, err
+		}
+		matchedHosts = append(matchedHosts, name)
+	}
+
+	if err := h.ensureHostSet(ctx, initiatorGroupName, matchedHosts); err != nil {
+		return nil, err
+	}
+	return storage.MappingContext{"hostSets": []string{initiatorGroupName}}, nil
+}
+
+// MapVolumeToGroup exports a volume to the host sets of the mapping context,
+// letting the array pick the LUN
+func (h *HPEStorageProvider) MapVolumeToGroup(initiatorGroupName string, targetVolume storage.Volume, mappingCtx storage.MappingContext) (storage.Volume, error) {
+	ctx := context.Background()
+
+	hostSets, ok := mappingCtx["hostSets"].([]string)
+	if !ok || len(hostSets) == 0 {
+		return storage.Volume{}, errors.New("invalid or empty hostSets list in mapping context")
+	}
+
+	vluns, err := h.listVLUNs(ctx, targetVolume.Name)
+	if err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to get VLUNs of volume %s: %w", targetVolume.Name, err)
+	}
+	for _, hostSet := range hostSets {
+		if _, exported := findVLUN(vluns, hostSet); exported {
+			klog.Infof("Volume %s already exported to host set %s", targetVolume.Name, hostSet)
+			continue
+		}
+		klog.Infof("Exporting volume %s to host set %s", targetVolume.Name, hostSet)
+		reqBody := map[string]interface{}{
+			"volumeName": targetVolume.Name,
+			"hostname":   "set:" + hostSet,
+			"lun":        0,
+			"autoLun":    true,
+			"maxAutoLun": 0,
+		}
+		if err := h.doRequest(ctx, http.MethodPost, "/vluns", reqBody, nil); err != nil && !isConflict(err) {
+			return storage.Volume{}, fmt.Errorf("failed to export volume %s to host set %s: %w", targetVolume.Name, hostSet, err)
+		}
+	}
+
+	return targetVolume, nil
+}
+
+// UnmapVolumeFromGroup removes the exports of a volume to the host sets of the mapping context
+func (h *HPEStorageProvider) UnmapVolumeFromGroup(initiatorGroupName string, targetVolume storage.Volume, mappingCtx storage.MappingContext) error {
+	ctx := context.Background()
+
+	hostSets, ok := mappingCtx["hostSets"].([]string)
+	if !ok || len(hostSets) == 0 {
+		return nil
+	}
+
+	vluns, err := h.listVLUNs(ctx, targetVolume.Name)
+	if err != nil {
+		klog.Warningf("Failed to get VLUNs of volume %s for unexporting: %v", targetVolume.Name, err)
+		return nil // The volume might already be deleted
+	}
+	for _, hostSet := range hostSets {
+		vlun, exported := findVLUN(vluns, hostSet)
+		if !exported {
+			continue
+		}
+		klog.Infof("Unexporting volume %s from host set %s (LUN %d)", targetVolume.Name, hostSet, vlun.LUN)
+		endpoint := fmt.Sprintf("/vluns/%s,%d,%s", url.PathEscape(targetVolume.Name), vlun.LUN, url.PathEscape(vlun.Hostname))
+		if err := h.doRequest(ctx, http.MethodDelete, endpoint, nil, nil); err != nil && !isNotFound(err) {
+			klog.Warningf("Failed to unexport volume %s from host set %s: %v", targetVolume.Name, hostSet, err)
+		}
+	}
+	return nil
+}
+
+// GetMappedGroups returns the host sets, and hosts, the volume is exported to
+func (h *HPEStorageProvider) GetMappedGroups(targetVolume storage.Volume, mappingCtx storage.MappingContext) ([]string, error) {
+	vluns, err := h.listVLUNs(context.Background(), targetVolume.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VLUNs of volume %s: %w", targetVolume.Name, err)
+	}
+
+	var groups []string
+	for _, vlun := range vluns {
+		name := strings.TrimPrefix(vlun.Hostname, "set:")
+		if name != "" && !storage.SliceContains(groups, name) {
+			groups = append(groups, name)
+		}
+	}
+	return groups, nil
+}
+
+// ResolveCinderVolumeToLUN resolves a Cinder volume ID to its virtual volume.
+// The Cinder HPE 3PAR driver names volumes, managed ones included, osv-
+// followed by the base64 of the volume UUID.
+func (h *HPEStorageProvider) ResolveCinderVolumeToLUN(volumeID string) (storage.Volume, error) {
+	volumeName, err := CinderVolumeName(volumeID)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	v, err := h.getVolume(context.Background(), volumeName)
+	if err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to get volume %s of Cinder volume %s: %w", volumeName, volumeID, err)
+	}
+
+	klog.Infof("Resolved cinder volume %s to volume: %+v", volumeID, v)
+	return h.toVolume(*v), nil
+}
+
+// GetVolumeFromNAA retrieves a virtual volume by its NAA identifier
+func (h *HPEStorageProvider) GetVolumeFromNAA(naaID string) (storage.Volume, error) {
+	if !h.IsValidNAA(strings.ToLower(naaID)) {
+		return storage.Volume{}, fmt.Errorf("NAA ID %s is not from HPE (expected prefix: naa.%s)", naaID, HPEProviderID)
+	}
+	wwn := strings.TrimPrefix(strings.ToLower(naaID), "naa.")
+
+	var response hpeMembers[HPEVolume]
+	if err := h.doRequest(context.Background(), http.MethodGet, "/volumes", nil, &response); err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	for _, v := range response.Members {
+		if strings.EqualFold(v.WWN, wwn) {
+			klog.Infof("Found HPE volume %s with WWN %s matching NAA %s", v.Name, v.WWN, naaID)
+			return h.toVolume(v), nil
+		}
+	}
+	return storage.Volume{}, fmt.Errorf("no HPE volume found with NAA %s", naaID)
+}
+
+// CreateSnapshot takes a read-only virtual copy of a volume.
+// Implements storage.ArrayCloner.
+func (h *HPEStorageProvider) CreateSnapshot(volume storage.Volume, snapshotName string) (storage.VolumeSnapshot, error) {
+	ctx := context.Background()
+	name := arrayVolumeName(snapshotName)
+	reqBody := map[string]interface{}{
+		"action": "createSnapshot",
+		"parameters": map[string]interface{}{
+			"name":     name,
+			"readOnly": true,
+			"comment":  "Created by vJailbreak for " + snapshotName,
+		},
+	}
+
+	klog.Infof("Creating HPE snapshot %s of volume %s", name, volume.Name)
+	if err := h.doRequest(ctx, http.MethodPost, "/volumes/"+url.PathEscape(volume.Name), reqBody, nil); err != nil {
+		return storage.VolumeSnapshot{}, fmt.Errorf("failed to snapshot volume %s: %w", volume.Name, err)
+	}
+
+	v, err := h.getVolume(ctx, name)
+	if err != nil {
+		return storage.VolumeSnapshot{}, fmt.Errorf("failed to get snapshot %s: %w", name, err)
+	}
+	return storage.VolumeSnapshot{
+		Name:         v.Name,
+		Id:           v.UUID,
+		SourceVolume: volume.Name,
+		Size:         v.SizeMiB * 1024 * 1024,
+	}, nil
+}
+
+// CloneSnapshot creates a new volume in the target CPG from a snapshot with
+// an online physical copy, which the array carries out in the background
+// while the new volume can already be exported.
+// Implements storage.ArrayCloner.
+func (h *HPEStorageProvider) CloneSnapshot(snapshot storage.VolumeSnapshot, volumeName string) (storage.Volume, error) {
+	ctx := context.Background()
+
+	cpg, err := h.targetCPG(ctx)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	name := arrayVolumeName(volumeName)
+	reqBody := map[string]interface{}{
+		"action": "createPhysicalCopy",
+		"parameters": map[string]interface{}{
+			"destVolume": name,
+			"destCPG":    cpg,
+			"online":     true,
+			"tpvv":       true,
+		},
+	}
+
+	klog.Infof("Cloning HPE snapshot %s to volume %s in CPG %s", snapshot.Name, name, cpg)
+	if err := h.doRequest(ctx, http.MethodPost, "/volumes/"+url.PathEscape(snapshot.Name), reqBody, nil); err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to clone snapshot %s: %w", snapshot.Name, err)
+	}
+
+	v, err := h.getVolume(ctx, name)
+	if err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to get cloned volume %s: %w", name, err)
+	}
+	klog.Infof("Cloned HPE volume: %s, WWN: %s", v.Name, v.WWN)
+	return h.toVolume(*v), nil
+}
+
+// DeleteSnapshot deletes a snapshot taken with CreateSnapshot.
+// Implements storage.ArrayCloner.
+func (h *HPEStorageProvider) DeleteSnapshot(snapshot storage.VolumeSnapshot) error {
+	klog.Infof("Deleting HPE snapshot: %s", snapshot.Name)
+	if err := h.doRequest(context.Background(), http.MethodDelete, "/volumes/"+url.PathEscape(snapshot.Name), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete snapshot %s: %w", snapshot.Name, err)
+	}
+	return nil
+}
+
+// WhoAmI returns the provider name
+func (h *HPEStorageProvider) WhoAmI() string {
+	return VendorName
+}
+
+// DiscoverBackendTargets returns the array with its CPGs, the targets new
+// volumes are created in. Implements storage.BackendTargetDiscoverer.
+func (h *HPEStorageProvider) DiscoverBackendTargets(ctx context.Context) ([]storage.BackendTargetGroup, error) {
+	system, err := h.getSystem(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cpgs, err := h.listCPGs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]storage.BackendTarget, 0, len(cpgs))
+	for _, cpg := range cpgs {
+		children = append(children, storage.BackendTarget{Name: cpg.Name, UUID: cpg.UUID})
+	}
+	return []storage.BackendTargetGroup{{
+		Name:     system.Name,
+		UUID:     system.SerialNumber,
+		Children: children,
+	}}, nil
+}
+
+// CinderVolumeName returns the name the Cinder HPE 3PAR driver gives the
+// virtual volume of a Cinder volume: osv- and the base64 of the UUID, with
+// the characters the array does not accept replaced and the padding removed
+func CinderVolumeName(volumeID string) (string, error) {
+	id, err := uuid.Parse(volumeID)
+	if err != nil {
+		return "", fmt.Errorf("invalid Cinder volume ID %q: %w", volumeID, err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(id[:])
+	encoded = strings.NewReplacer("+", ".", "/", "-", "=", "").Replace(encoded)
+	return "osv-" + encoded, nil
+}
+
+// Helper methods
+
+// login opens a WSAPI session
+func (h *HPEStorageProvider) login(ctx context.Context) error {
+	h.sessionKey = ""
+	var session struct {
+		Key string `json:"key"`
+	}
+	body := map[string]string{"user": h.Username, "password": h.Password}
+	if err := h.doRequest(ctx, http.MethodPost, "/credentials", body, &session); err != nil {
+		return err
+	}
+	h.sessionKey = session.Key
+	return nil
+}
+
+// doRequest performs a WSAPI request, marshalling body to JSON and
+// unmarshalling the response into result when they are not nil. An expired
+// session is renewed once.
+func (h *HPEStorageProvider) doRequest(ctx context.Context, method, endpoint string, body, result interface{}) error {
+	resp, err := h.send(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusForbidden && h.sessionKey != "" && endpoint != "/credentials" {
+		resp.Body.Close()
+		klog.Infof("HPE WSAPI session expired, logging in again")
+		if err := h.login(ctx); err != nil {
+			return err
+		}
+		if resp, err = h.send(ctx, method, endpoint, body); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Desc: string(data)}
+		var errBody struct {
+			Code int    `json:"code"`
+			Desc string `json:"desc"`
+		}
+		if json.Unmarshal(data, &errBody) == nil && errBody.Desc != "" {
+			apiErr.Code, apiErr.Desc = errBody.Code, errBody.Desc
+		}
+		return apiErr
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func (h *HPEStorageProvider) send(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, h.BaseURL+endpoint, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if h.sessionKey != "" {
+		req.Header.Set(sessionKeyHeader, h.sessionKey)
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (h *HPEStorageProvider) getSystem(ctx context.Context) (*HPESystem, error) {
+	var system HPESystem
+	if err := h.doRequest(ctx, http.MethodGet, "/system", nil, &system); err != nil {
+		return nil, err
+	}
+	return &system, nil
+}
+
+func (h *HPEStorageProvider) listCPGs(ctx context.Context) ([]HPECPG, error) {
+	var response hpeMembers[HPECPG]
+	if err := h.doRequest(ctx, http.MethodGet, "/cpgs", nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list CPGs: %w", err)
+	}
+	return response.Members, nil
+}
+
+// targetCPG returns the configured CPG, or the only CPG of the array
+func (h *HPEStorageProvider) targetCPG(ctx context.Context) (string, error) {
+	if h.CPG != "" {
+		return h.CPG, nil
+	}
+	cpgs, err := h.listCPGs(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(cpgs) == 1 {
+		klog.Infof("Auto-picked single CPG %q for HPE target", cpgs[0].Name)
+		return cpgs[0].Name, nil
+	}
+	return "", fmt.Errorf(
+		"HPE CPG is not configured and cannot be auto-detected (found %d CPG(s)); "+
+			"set ArrayCreds.spec.hpeAlletraConfig.cpg explicitly", len(cpgs))
+}
+
+func (h *HPEStorageProvider) getVolume(ctx context.Context, name string) (*HPEVolume, error) {
+	var v HPEVolume
+	if err := h.doRequest(ctx, http.MethodGet, "/volumes/"+url.PathEscape(name), nil, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// listVLUNs returns the VLUNs of a volume
+func (h *HPEStorageProvider) listVLUNs(ctx context.Context, volumeName string) ([]HPEVLUN, error) {
+	query := url.Values{"query": {fmt.Sprintf("%q", "volumeName EQ "+volumeName)}}
+	var response hpeMembers[HPEVLUN]
+	if err := h.doRequest(ctx, http.MethodGet, "/vluns?"+query.Encode(), nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Members, nil
+}
+
+// createHost registers an ESXi host with its adapters and returns its name
+func (h *HPEStorageProvider) createHost(ctx context.Context, name string, wwpns, iqns []string) (string, error) {
+	if len(wwpns) > 0 && len(iqns) > 0 {
+		return "", fmt.Errorf(
+			"host has both FC and iSCSI adapters; cannot register a single HPE host — "+
+				"ensure the ESXi host uses a single transport type or register it on the array: %v", append(wwpns, iqns...))
+	}
+	reqBody := map[string]interface{}{
+		"name":    name,
+		"persona": personaVMware,
+	}
+	if len(wwpns) > 0 {
+		reqBody["FCWWNs"] = wwpns
+	} else {
+		reqBody["iSCSINames"] = iqns
+	}
+
+	klog.Infof("No HPE host holds the ESXi adapters, registering host %s", name)
+	if err := h.doRequest(ctx, http.MethodPost, "/hosts", reqBody, nil); err != nil && !isConflict(err) {
+		return "", fmt.Errorf("failed to register host %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// ensureHostSet makes sure the host set exists with the hosts among its members
+func (h *HPEStorageProvider) ensureHostSet(ctx context.Context, name string, hosts []string) error {
+	var hostSet HPEHostSet
+	err := h.doRequest(ctx, http.MethodGet, "/hostsets/"+url.PathEscape(name), nil, &hostSet)
+	if isNotFound(err) {
+		klog.Infof("Creating HPE host set %s with hosts %v", name, hosts)
+		reqBody := map[string]interface{}{
+			"name":       name,
+			"setmembers": hosts,
+			"comment":    "Created by vJailbreak",
+		}
+		err = h.doRequest(ctx, http.MethodPost, "/hostsets", reqBody, nil)
+		if err == nil {
+			return nil
+		}
+		if !isConflict(err) {
+			return fmt.Errorf("failed to create host set %s: %w", name, err)
+		}
+		// Concurrent migrations can race on creating the same host set
+		err = h.doRequest(ctx, http.MethodGet, "/hostsets/"+url.PathEscape(name), nil, &hostSet)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get host set %s: %w", name, err)
+	}
+
+	var missing []string
+	for _, host := range hosts {
+		if !storage.ContainsIgnoreCase(hostSet.SetMembers, host) {
+			missing = append(missing, host)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	klog.Infof("Adding hosts %v to HPE host set %s", missing, name)
+	reqBody := map[string]interface{}{"action": setMemberAdd, "setmembers": missing}
+	if err := h.doRequest(ctx, http.MethodPut, "/hostsets/"+url.PathEscape(name), reqBody, nil); err != nil {
+		return fmt.Errorf("failed to add hosts to host set %s: %w", name, err)
+	}
+	return nil
+}
+
+func (h *HPEStorageProvider) toVolume(v HPEVolume) storage.Volume {
+	return storage.Volume{
+		Name:         v.Name,
+		Size:         v.SizeMiB * 1024 * 1024,
+		Id:           v.UUID,
+		SerialNumber: h.serialFromWWN(v.WWN),
+		NAA:          "naa." + strings.ToLower(v.WWN),
+	}
+}
+
+func (h *HPEStorageProvider) toVolumeInfo(v HPEVolume) storage.VolumeInfo {
+	return storage.VolumeInfo{
+		Name:    v.Name,
+		Size:    v.SizeMiB * 1024 * 1024,
+		Created: v.CreationTime8601,
+		NAA:     "naa." + strings.ToLower(v.WWN),
+	}
+}
+
+// serialFromWWN returns the part of a volume WWN after the HPE NAA prefix
+func (h *HPEStorageProvider) serialFromWWN(wwn string) string {
+	return strings.TrimPrefix(strings.ToLower(wwn), HPEProviderID)
+}
+
+// arrayVolumeName returns name when the array accepts it, or else name cut
+// short and followed by a hash of the full name
+func arrayVolumeName(name string) string {
+	if len(name) <= maxVolumeName {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := "-" + hex.EncodeToString(sum[:])[:8]
+	return strings.TrimRight(name[:maxVolumeName-len(suffix)], "-_.") + suffix
+}
+
+// splitAdapters splits ESXi adapter identifiers into the WWPNs of the FC
+// adapters (fc.WWNN:WWPN), in the contiguous form the WSAPI uses, and the
+// IQNs of the iSCSI ones. Adapters of other transports are skipped.
+func splitAdapters(hbaIdentifiers []string) (wwpns, iqns []string, err error) {
+	for _, id := range hbaIdentifiers {
+		lower := strings.ToLower(id)
+		switch {
+		case strings.HasPrefix(lower, "fc."):
+			wwpn, err := fcutil.WWPNFromFCUID(id)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to parse FC adapter UID %q: %w", id, err)
+			}
+			wwpns = append(wwpns, wwpn)
+		case strings.HasPrefix(lower, "iqn."):
+			iqns = append(iqns, id)
+		default:
+			klog.Warningf("Skipping adapter %s, HPE hosts are matched by FC and iSCSI adapters", id)
+		}
+	}
+	return wwpns, iqns, nil
+}
+
+// hostMatches reports whether an HPE host has a path of the adapters
+func hostMatches(host HPEHost, wwpns, iqns []string) bool {
+	for _, path := range host.FCPaths {
+		for _, wwpn := range wwpns {
+			if fcutil.EqualWWNs(path.WWN, wwpn) {
+				return true
+			}
+		}
+	}
+	for _, path := range host.ISCSIPaths {
+		if storage.ContainsIgnoreCase(iqns, path.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findVLUN returns the VLUN exporting a volume to a host set
+func findVLUN(vluns []HPEVLUN, hostSet string) (HPEVLUN, bool) {
+	for _, vlun := range vluns {
+		if vlun.Hostname == "set:"+hostSet {
+			return vlun, true
+		}
+	}
+	return HPEVLUN{}, false
+}
@@ -0,0 +1,369 @@
+package hpalletra
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
+)
+
+const gib = 1024 * 1024 * 1024
+
+func connectFake(t *testing.T, f *fakeWSAPI, options map[string]string) *HPEStorageProvider {
+	t.Helper()
+	h := &HPEStorageProvider{}
+	if err := h.Connect(context.Background(), f.accessInfo(options)); err != nil {
+		t.Fatalf("Connect() unexpected error: %v", err)
+	}
+	return h
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := storage.NewStorageProvider("HPAlletra")
+	if err != nil {
+		t.Fatalf("NewStorageProvider() unexpected error: %v", err)
+	}
+	if provider.WhoAmI() != VendorName {
+		t.Errorf("WhoAmI() = %q, want %q", provider.WhoAmI(), VendorName)
+	}
+	if _, ok := provider.(storage.BackendTargetDiscoverer); !ok {
+		t.Error("provider does not implement BackendTargetDiscoverer")
+	}
+	if _, ok := provider.(storage.ArrayCloner); !ok {
+		t.Error("provider does not implement ArrayCloner")
+	}
+}
+
+func TestConnect(t *testing.T) {
+	f := newFakeWSAPI(t, "SSD_r6")
+	h := connectFake(t, f, nil)
+	if err := h.ValidateCredentials(context.Background()); err != nil {
+		t.Errorf("ValidateCredentials() unexpected error: %v", err)
+	}
+	if err := h.Disconnect(); err != nil {
+		t.Errorf("Disconnect() unexpected error: %v", err)
+	}
+	if f.key != "" {
+		t.Error("Disconnect() did not close the session")
+	}
+
+	info := f.accessInfo(nil)
+	info.Password = "wrong"
+	err := (&HPEStorageProvider{}).Connect(context.Background(), info)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 5 || !strings.Contains(err.Error(), "invalid username or password") {
+		t.Errorf("Connect() with a wrong password = %v, want the error of the array", err)
+	}
+}
+
+func TestSessionRenewal(t *testing.T) {
+	f := newFakeWSAPI(t, "SSD_r6")
+	h := connectFake(t, f, nil)
+	f.expireSession()
+
+	if _, err := h.CreateVolume("after-expiry", gib); err != nil {
+		t.Fatalf("CreateVolume() after the session expired: %v", err)
+	}
+	if f.sessions != 2 {
+		t.Errorf("logged in %d times, want 2", f.sessions)
+	}
+}
+
+func TestVolumes(t *testing.T) {
+	f := newFakeWSAPI(t, "SSD_r6")
+	h := connectFake(t, f, nil)
+
+	created, err := h.CreateVolume("vm1-disk1", 10*gib+512)
+	if err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+	if created.Size != 11*gib || f.volumes[0].UserCPG != "SSD_r6" {
+		t.Errorf("volume = %+v in CPG %s, want 11 GiB in SSD_r6", created, f.volumes[0].UserCPG)
+	}
+	if !strings.HasPrefix(created.NAA, "naa."+HPEProviderID) || created.NAA != "naa."+strings.ToLower(f.volumes[0].WWN) {
+		t.Errorf("NAA = %q, want the WWN %q", created.NAA, f.volumes[0].WWN)
+	}
+	if created.Id == "" || created.SerialNumber == "" {
+		t.Errorf("volume = %+v, want its ID and serial", created)
+	}
+
+	info, err := h.GetVolumeInfo("vm1-disk1")
+	if err != nil || info.NAA != created.NAA || info.Size != created.Size {
+		t.Errorf("GetVolumeInfo() = %+v, %v, want the created volume", info, err)
+	}
+	byNAA, err := h.GetVolumeFromNAA(strings.ToUpper(created.NAA))
+	if err != nil || byNAA.Name != "vm1-disk1" {
+		t.Errorf("GetVolumeFromNAA() = %+v, %v, want vm1-disk1", byNAA, err)
+	}
+	if _, err := h.GetVolumeFromNAA("naa.624a9370abcdef"); err == nil {
+		t.Error("GetVolumeFromNAA() of a Pure NAA succeeded")
+	}
+	naas, err := h.GetAllVolumeNAAs()
+	if err != nil || !reflect.DeepEqual(naas, []string{created.NAA}) {
+		t.Errorf("GetAllVolumeNAAs() = %v, %v, want %s", naas, err, created.NAA)
+	}
+
+	if _, err := h.CreateVolume("vm1-disk1", gib); !isConflict(err) {
+		t.Errorf("CreateVolume() of a duplicate = %v, want the conflict", err)
+	}
+
+	if err := h.DeleteVolume("vm1-disk1"); err != nil {
+		t.Fatalf("DeleteVolume() unexpected error: %v", err)
+	}
+	if _, err := h.GetVolumeInfo("vm1-disk1"); err == nil {
+		t.Error("volume still found after DeleteVolume()")
+	}
+	if err := h.DeleteVolume("vm1-disk1"); !isNotFound(err) {
+		t.Errorf("DeleteVolume() of a missing volume = %v, want not found", err)
+	}
+}
+
+func TestCreateVolumeLongName(t *testing.T) {
+	f := newFakeWSAPI(t, "SSD_r6")
+	h := connectFake(t, f, nil)
+
+	long := "vjailbreak-windows-2022-datacenter-disk-2"
+	created, err := h.CreateVolume(long, gib)
+	if err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+	if len(created.Name) > maxVolumeName || !strings.HasPrefix(created.Name, "vjailbreak-windows-") {
+		t.Errorf("name = %q, want %q shortened to %d characters", created.Name, long, maxVolumeName)
+	}
+	if created.Name != arrayVolumeName(long) || arrayVolumeName(long+"x") == created.Name {
+		t.Errorf("name = %q, want it stable and distinct per volume name", created.Name)
+	}
+}
+
+func TestCreateVolumeCPG(t *testing.T) {
+	f := newFakeWSAPI(t, "SSD_r6", "FC_r6")
+
+	h := connectFake(t, f, nil)
+	if _, err := h.CreateVolume("unplaced", gib); err == nil || !strings.Contains(err.Error(), "hpeAlletraConfig.cpg") {
+		t.Errorf("CreateVolume() with two CPGs and none configured = %v, want the option named", err)
+	}
+
+	h = connectFake(t, f, map[string]string{OptionCPG: "FC_r6"})
+	if _, err := h.CreateVolume("placed", gib); err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+	if f.volumes[0].UserCPG != "FC_r6" {
+		t.Errorf("volume created in CPG %s, want FC_r6", f.volumes[0].UserCPG)
+	}
+}
+
+func TestSnapshotClone(t *testing.T) {
+	f := newFakeWSAPI(t, "SSD_r6", "FC_r6")
+	h := connectFake(t, f, map[string]string{OptionCPG: "FC_r6"})
+	datastore := f.addVolume("datastore1", 100*1024)
+
+	lun, err := h.GetVolumeFromNAA("naa." + datastore.WWN)
+	if err != nil {
+		t.Fatalf("GetVolumeFromNAA() unexpected error: %v", err)
+	}
+	snapshot, err := h.CreateSnapshot(lun, "vjailbreak-datastore1-snapshot-1234")
+	if err != nil {
+		t.Fatalf("CreateSnapshot() unexpected error: %v", err)
+	}
+	if len(snapshot.Name) > maxVolumeName || snapshot.SourceVolume != "datastore1" || snapshot.Size != 100*gib {
+		t.Errorf("CreateSnapshot() = %+v", snapshot)
+	}
+
+	clone, err := h.CloneSnapshot(snapshot, "vjailbreak-datastore1-clone-1234")
+	if err != nil {
+		t.Fatalf("CloneSnapshot() unexpected error: %v", err)
+	}
+	if len(clone.Name) > maxVolumeName || clone.Size != 100*gib || clone.NAA == lun.NAA {
+		t.Errorf("CloneSnapshot() = %+v", clone)
+	}
+	if cloned := f.volumes[len(f.volumes)-1]; cloned.UserCPG != "FC_r6" {
+		t.Errorf("clone created in CPG %s, want FC_r6", cloned.UserCPG)
+	}
+
+	if err := h.DeleteSnapshot(snapshot); err != nil {
+		t.Fatalf("DeleteSnapshot() unexpected error: %v", err)
+	}
+	if _, err := h.GetVolumeInfo(snapshot.Name); err == nil {
+		t.Error("snapshot still exists after DeleteSnapshot()")
+	}
+	if _, err := h.GetVolumeInfo(clone.Name); err != nil {
+		t.Errorf("clone is gone after DeleteSnapshot(): %v", err)
+	}
+}
+
+func TestCreateOrUpdateInitiatorGroup(t *testing.T) {
+	iqn := "iqn.1998-01.com.vmware:esxi01-4f1c"
+	fcUID := "fc.20000090fa6e67a8:21000090fa6e67a8"
+
+	t.Run("registered host", func(t *testing.T) {
+		f := newFakeWSAPI(t, "SSD_r6")
+		f.addHost(HPEHost{Name: "esxi01", ISCSIPaths: []HPEISCSIPath{{Name: iqn}}})
+		h := connectFake(t, f, nil)
+
+		for i := 0; i < 2; i++ {
+			mappingCtx, err := h.CreateOrUpdateInitiatorGroup("vjailbreak-xcopy", []string{strings.ToUpper(iqn)})
+			if err != nil {
+				t.Fatalf("CreateOrUpdateInitiatorGroup() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(mappingCtx["hostSets"], []string{"vjailbreak-xcopy"}) {
+				t.Errorf("hostSets = %v, want vjailbreak-xcopy", mappingCtx["hostSets"])
+			}
+		}
+		hs, ok := f.hostSet("vjailbreak-xcopy")
+		if !ok || !reflect.DeepEqual(hs.SetMembers, []string{"esxi01"}) || len(f.hosts) != 1 {
+			t.Errorf("host set = %+v, hosts %+v, want esxi01 in the created set", hs, f.hosts)
+		}
+	})
+
+	t.Run("registered host outside of the host set", func(t *testing.T) {
+		f := newFakeWSAPI(t, "SSD_r6")
+		f.addHost(HPEHost{Name: "esxi01", FCPaths: []HPEFCPath{{WWN: "21000090FA6E67A8"}}})
+		f.addHostSet("vjailbreak-xcopy", "esxi02")
+		h := connectFake(t, f, nil)
+
+		if _, err := h.CreateOrUpdateInitiatorGroup("vjailbreak-xcopy", []string{fcUID}); err != nil {
+			t.Fatalf("CreateOrUpdateInitiatorGroup() unexpected error: %v", err)
+		}
+		hs, _ := f.hostSet("vjailbreak-xcopy")
+		if !reflect.DeepEqual(hs.SetMembers, []string{"esxi02", "esxi01"}) {
+			t.Errorf("host set members = %v, want esxi01 added", hs.SetMembers)
+		}
+	})
+
+	t.Run("unregistered host", func(t *testing.T) {
+		f := newFakeWSAPI(t, "SSD_r6")
+		h := connectFake(t, f, nil)
+
+		if _, err := h.CreateOrUpdateInitiatorGroup("vjailbreak-xcopy", []string{fcUID}); err != nil {
+			t.Fatalf("CreateOrUpdateInitiatorGroup() unexpected error: %v", err)
+		}
+		name := storage.HostNameForAdapters("vjailbreak-xcopy", []string{fcUID}, maxHostName)
+		if len(f.hosts) != 1 || f.hosts[0].Name != name || !reflect.DeepEqual(f.hosts[0].FCPaths, []HPEFCPath{{WWN: "21000090FA6E67A8"}}) {
+			t.Errorf("hosts = %+v, want %s registered with the WWPN", f.hosts, name)
+		}
+		hs, _ := f.hostSet("vjailbreak-xcopy")
+		if !reflect.DeepEqual(hs.SetMembers, []string{name}) {
+			t.Errorf("host set members = %v, want %s", hs.SetMembers, name)
+		}
+	})
+
+	t.Run("unregistered host with mixed adapters", func(t *testing.T) {
+		f := newFakeWSAPI(t, "SSD_r6")
+		h := connectFake(t, f, nil)
+		if _, err := h.CreateOrUpdateInitiatorGroup("vjailbreak-xcopy", []string{iqn, fcUID}); err == nil {
+			t.Error("CreateOrUpdateInitiatorGroup() with iSCSI and FC adapters succeeded")
+		}
+		if len(f.hosts) != 0 {
+			t.Errorf("hosts = %+v, want none registered", f.hosts)
+		}
+	})
+
+	t.Run("host set name too long", func(t *testing.T) {
+		f := newFakeWSAPI(t, "SSD_r6")
+		h := connectFake(t, f, nil)
+		if _, err := h.CreateOrUpdateInitiatorGroup(strings.Repeat("x", maxSetName+1), []string{iqn}); err == nil {
+			t.Error("CreateOrUpdateInitiatorGroup() with a long host set name succeeded")
+		}
+	})
+}
+
+func TestMapVolumeToGroup(t *testing.T) {
+	f := newFakeWSAPI(t, "SSD_r6")
+	f.addHost(HPEHost{Name: "esxi01", ISCSIPaths: []HPEISCSIPath{{Name: "iqn.1998-01.com.vmware:esxi01"}}})
+	f.addHostSet("vjailbreak-xcopy", "esxi01")
+	volume := f.addVolume("osv-Wo4MKw1vTEOhsH8OT10qEQ", 1024)
+	h := connectFake(t, f, nil)
+	mappingCtx := storage.MappingContext{"hostSets": []string{"vjailbreak-xcopy"}}
+	target := storage.Volume{Name: volume.Name}
+
+	for i := 0; i < 2; i++ {
+		if _, err := h.MapVolumeToGroup("vjailbreak-xcopy", target, mappingCtx); err != nil {
+			t.Fatalf("MapVolumeToGroup() unexpected error: %v", err)
+		}
+	}
+	if len(f.vluns) != 1 || f.vluns[0].Hostname != "set:vjailbreak-xcopy" {
+		t.Errorf("VLUNs = %+v, want one to the host set", f.vluns)
+	}
+	groups, err := h.GetMappedGroups(target, mappingCtx)
+	if err != nil || !reflect.DeepEqual(groups, []string{"vjailbreak-xcopy"}) {
+		t.Errorf("GetMappedGroups() = %v, %v, want vjailbreak-xcopy", groups, err)
+	}
+	if err := h.DeleteVolume(volume.Name); err == nil {
+		t.Error("DeleteVolume() of an exported volume succeeded")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := h.UnmapVolumeFromGroup("vjailbreak-xcopy", target, mappingCtx); err != nil {
+			t.Fatalf("UnmapVolumeFromGroup() unexpected error: %v", err)
+		}
+	}
+	if len(f.vluns) != 0 {
+		t.Errorf("VLUNs = %+v, want none", f.vluns)
+	}
+	if err := h.UnmapVolumeFromGroup("vjailbreak-xcopy", storage.Volume{Name: "gone"}, mappingCtx); err != nil {
+		t.Errorf("UnmapVolumeFromGroup() of a deleted volume = %v, want nil", err)
+	}
+	if _, err := h.MapVolumeToGroup("vjailbreak-xcopy", target, storage.MappingContext{}); err == nil {
+		t.Error("MapVolumeToGroup() without host sets succeeded")
+	}
+}
+
+func TestCinderVolumeName(t *testing.T) {
+	tests := []struct {
+		volumeID string
+		want     string
+	}{
+		{"5a8e0c2b-0d6f-4c43-a1b0-7f0e4f5d2a11", "osv-Wo4MKw1vTEOhsH8OT10qEQ"},
+		{"FBFFFBFF-0000-4000-8000-000000000000", "osv-.--7-wAAQACAAAAAAAAAAA"},
+	}
+	for _, tt := range tests {
+		got, err := CinderVolumeName(tt.volumeID)
+		if err != nil || got != tt.want {
+			t.Errorf("CinderVolumeName(%q) = %q, %v, want %q", tt.volumeID, got, err, tt.want)
+		}
+	}
+	if _, err := CinderVolumeName("not-a-uuid"); err == nil {
+		t.Error("CinderVolumeName() of an invalid ID succeeded")
+	}
+}
+
+func TestResolveCinderVolumeToLUN(t *testing.T) {
+	f := newFakeWSAPI(t, "SSD_r6")
+	volume := f.addVolume("osv-Wo4MKw1vTEOhsH8OT10qEQ", 20*1024)
+	h := connectFake(t, f, nil)
+
+	resolved, err := h.ResolveCinderVolumeToLUN("5a8e0c2b-0d6f-4c43-a1b0-7f0e4f5d2a11")
+	if err != nil {
+		t.Fatalf("ResolveCinderVolumeToLUN() unexpected error: %v", err)
+	}
+	if resolved.Name != volume.Name || resolved.NAA != "naa."+strings.ToLower(volume.WWN) || resolved.Size != 20*gib {
+		t.Errorf("ResolveCinderVolumeToLUN() = %+v, want %+v", resolved, volume)
+	}
+	if _, err := h.ResolveCinderVolumeToLUN("3c1b7e52-9d0a-4f6e-8b2c-1a2b3c4d5e6f"); err == nil {
+		t.Error("ResolveCinderVolumeToLUN() of a missing volume succeeded")
+	}
+}
+
+func TestDiscoverBackendTargets(t *testing.T) {
+	f := newFakeWSAPI(t, "SSD_r6", "FC_r6")
+	h := connectFake(t, f, nil)
+
+	groups, err := h.DiscoverBackendTargets(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverBackendTargets() unexpected error: %v", err)
+	}
+	expected := []storage.BackendTargetGroup{{
+		Name: "primera-a",
+		UUID: "4UW0001234",
+		Children: []storage.BackendTarget{
+			{Name: "SSD_r6", UUID: "cpg-uuid-1"},
+			{Name: "FC_r6", UUID: "cpg-uuid-2"},
+		},
+	}}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("DiscoverBackendTargets() = %+v, want %+v", groups, expected)
+	}
+}
@@ -0,0 +1,417 @@
+package powerstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
+)
+
+const (
+	fakeUser     = "admin"
+	fakePassword = "Password123!"
+	fakeCookie   = "auth_cookie"
+)
+
+// fakePowerStore is an in-memory PowerStore REST API covering the endpoints
+// the provider uses. It enforces the login session: requests that change
+// state need the session cookie and its DELL-EMC-TOKEN.
+type fakePowerStore struct {
+	mu         sync.Mutex
+	server     *httptest.Server
+	cluster    PowerStoreCluster
+	appliances []PowerStoreAppliance
+	volumes    []PowerStoreVolume
+	hosts      []PowerStoreHost
+	hostGroups []PowerStoreHostGroup
+	mappings   []PowerStoreMapping
+	sessions   int
+	token      string
+	nextID     int
+	// requests records "METHOD /path" of every request, for assertions
+	requests []string
+}
+
+func newFakePowerStore(t *testing.T) *fakePowerStore {
+	t.Helper()
+	f := &fakePowerStore{
+		cluster:    PowerStoreCluster{ID: "0", Name: "ps-cluster", GlobalID: "PS4f8a2c1d0e3b"},
+		appliances: []PowerStoreAppliance{{ID: "A1", Name: "ps-appliance-1"}, {ID: "A2", Name: "ps-appliance-2"}},
+	}
+	f.server = httptest.NewTLSServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// accessInfo returns the access info of the fake for Connect
+func (f *fakePowerStore) accessInfo(options map[string]string) storage.StorageAccessInfo {
+	return storage.StorageAccessInfo{
+		Hostname:            strings.TrimPrefix(f.server.URL, "https://"),
+		Username:            fakeUser,
+		Password:            fakePassword,
+		SkipSSLVerification: true,
+		VendorType:          VendorName,
+		ProviderOptions:     options,
+	}
+}
+
+// expireSession invalidates the current login session
+func (f *fakePowerStore) expireSession() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.token = ""
+}
+
+func (f *fakePowerStore) addVolume(name string, size int64) PowerStoreVolume {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.createVolume(name, size, "A1")
+}
+
+func (f *fakePowerStore) addHost(name, groupID string, initiators ...PowerStoreInitiator) PowerStoreHost {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	host := PowerStoreHost{ID: f.newID("host"), Name: name, HostGroupID: groupID, Initiators: initiators}
+	f.hosts = append(f.hosts, host)
+	return host
+}
+
+func (f *fakePowerStore) addHostGroup(name string) PowerStoreHostGroup {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	group := PowerStoreHostGroup{ID: f.newID("hg"), Name: name}
+	f.hostGroups = append(f.hostGroups, group)
+	return group
+}
+
+func (f *fakePowerStore) hostByName(name string) (PowerStoreHost, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, h := range f.hosts {
+		if h.Name == name {
+			return h, true
+		}
+	}
+	return PowerStoreHost{}, false
+}
+
+func (f *fakePowerStore) newID(kind string) string {
+	f.nextID++
+	return fmt.Sprintf("%s-%04d", kind, f.nextID)
+}
+
+func (f *fakePowerStore) createVolume(name string, size int64, applianceID string) PowerStoreVolume {
+	id := f.newID("vol")
+	v := PowerStoreVolume{
+		ID:                id,
+		Name:              name,
+		Size:              size,
+		WWN:               fmt.Sprintf("naa.%s%024x", PowerStoreProviderID, f.nextID),
+		CreationTimestamp: "2026-10-17T00:00:00.000+00:00",
+		ApplianceID:       applianceID,
+	}
+	f.volumes = append(f.volumes, v)
+	return v
+}
+
+func (f *fakePowerStore) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	user, password, ok := r.BasicAuth()
+	if !ok || user != fakeUser || password != fakePassword {
+		writeFakeError(w, http.StatusUnauthorized, "0xE09010010004", "Authentication failed")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/api/rest")
+	if path == "/login_session" {
+		f.sessions++
+		f.token = fmt.Sprintf("token-%d", f.sessions)
+		http.SetCookie(w, &http.Cookie{Name: fakeCookie, Value: f.token, Path: "/"})
+		w.Header().Set(tokenHeader, f.token)
+		writeFakeJSON(w, http.StatusOK, []map[string]string{{"id": "session", "user": fakeUser}})
+		return
+	}
+	if r.Method != http.MethodGet {
+		cookie, err := r.Cookie(fakeCookie)
+		if f.token == "" || err != nil || cookie.Value != f.token || r.Header.Get(tokenHeader) != f.token {
+			writeFakeError(w, http.StatusForbidden, "0xE09010010010", "Invalid or missing CSRF token")
+			return
+		}
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var body map[string]json.RawMessage
+	if r.Body != nil && r.Method != http.MethodGet {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	switch {
+	case path == "/logout" && r.Method == http.MethodPost:
+		f.token = ""
+		w.WriteHeader(http.StatusNoContent)
+	case path == "/cluster":
+		writeFakeJSON(w, http.StatusOK, []PowerStoreCluster{f.cluster})
+	case path == "/appliance":
+		writeFakeJSON(w, http.StatusOK, page(r.URL.Query(), f.appliances, func(a PowerStoreAppliance, field string) string {
+			return map[string]string{"id": a.ID, "name": a.Name}[field]
+		}))
+	case segments[0] == "volume":
+		f.serveVolume(w, r, segments, body)
+	case segments[0] == "host":
+		f.serveHost(w, r, segments, body)
+	case segments[0] == "host_group":
+		f.serveHostGroup(w, r, segments, body)
+	case path == "/host_volume_mapping":
+		writeFakeJSON(w, http.StatusOK, page(r.URL.Query(), f.mappings, func(m PowerStoreMapping, field string) string {
+			return map[string]string{"id": m.ID, "volume_id": m.VolumeID, "host_group_id": m.HostGroupID}[field]
+		}))
+	default:
+		writeFakeError(w, http.StatusNotFound, "0xE04040010001", "Resource not found: "+path)
+	}
+}
+
+func (f *fakePowerStore) serveVolume(w http.ResponseWriter, r *http.Request, segments []string, body map[string]json.RawMessage) {
+	if len(segments) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeFakeJSON(w, http.StatusOK, page(r.URL.Query(), f.volumes, func(v PowerStoreVolume, field string) string {
+				return map[string]string{"id": v.ID, "name": v.Name, "wwn": v.WWN}[field]
+			}))
+		case http.MethodPost:
+			var name, applianceID string
+			var size int64
+			_ = json.Unmarshal(body["name"], &name)
+			_ = json.Unmarshal(body["size"], &size)
+			_ = json.Unmarshal(body["appliance_id"], &applianceID)
+			for _, v := range f.volumes {
+				if v.Name == name {
+					writeFakeError(w, http.StatusUnprocessableEntity, "0xE0A08001000C", "Volume name "+name+" is already in use")
+					return
+				}
+			}
+			if size%8192 != 0 {
+				writeFakeError(w, http.StatusBadRequest, "0xE0A08001000D", "Size must be a multiple of 8192")
+				return
+			}
+			if applianceID == "" {
+				applianceID = f.appliances[0].ID
+			}
+			writeFakeJSON(w, http.StatusCreated, map[string]string{"id": f.createVolume(name, size, applianceID).ID})
+		}
+		return
+	}
+
+	idx := -1
+	for i, v := range f.volumes {
+		if v.ID == segments[1] {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		writeFakeError(w, http.StatusNotFound, "0xE04040010001", "Volume "+segments[1]+" not found")
+		return
+	}
+	volume := f.volumes[idx]
+
+	switch {
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, volume)
+	case len(segments) == 2 && r.Method == http.MethodDelete:
+		for _, m := range f.mappings {
+			if m.VolumeID == volume.ID {
+				writeFakeError(w, http.StatusUnprocessableEntity, "0xE0A08001001A", "Volume is mapped to hosts")
+				return
+			}
+		}
+		f.volumes = append(f.volumes[:idx], f.volumes[idx+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	case len(segments) == 3 && (segments[2] == "snapshot" || segments[2] == "clone") && r.Method == http.MethodPost:
+		var name string
+		_ = json.Unmarshal(body["name"], &name)
+		for _, v := range f.volumes {
+			if v.Name == name {
+				writeFakeError(w, http.StatusUnprocessableEntity, "0xE0A08001000C", "Volume name "+name+" is already in use")
+				return
+			}
+		}
+		writeFakeJSON(w, http.StatusCreated, map[string]string{"id": f.createVolume(name, volume.Size, volume.ApplianceID).ID})
+	case len(segments) == 3 && segments[2] == "attach":
+		var groupID string
+		_ = json.Unmarshal(body["host_group_id"], &groupID)
+		for _, m := range f.mappings {
+			if m.VolumeID == volume.ID && m.HostGroupID == groupID {
+				writeFakeError(w, http.StatusUnprocessableEntity, "0xE0A08001001B", "Volume is already attached to the host group")
+				return
+			}
+		}
+		f.mappings = append(f.mappings, PowerStoreMapping{
+			ID: f.newID("map"), HostGroupID: groupID, VolumeID: volume.ID, LogicalUnitNumber: len(f.mappings) + 1,
+		})
+		w.WriteHeader(http.StatusNoContent)
+	case len(segments) == 3 && segments[2] == "detach":
+		var groupID string
+		_ = json.Unmarshal(body["host_group_id"], &groupID)
+		for i, m := range f.mappings {
+			if m.VolumeID == volume.ID && m.HostGroupID == groupID {
+				f.mappings = append(f.mappings[:i], f.mappings[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeFakeError(w, http.StatusUnprocessableEntity, "0xE0A08001001C", "Volume is not attached to the host group")
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "0xE04040010002", "Unsupported operation")
+	}
+}
+
+func (f *fakePowerStore) serveHost(w http.ResponseWriter, r *http.Request, segments []string, body map[string]json.RawMessage) {
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, page(r.URL.Query(), f.hosts, func(h PowerStoreHost, field string) string {
+			return map[string]string{"id": h.ID, "name": h.Name}[field]
+		}))
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		var host PowerStoreHost
+		_ = json.Unmarshal(body["name"], &host.Name)
+		_ = json.Unmarshal(body["initiators"], &host.Initiators)
+		for _, h := range f.hosts {
+			for _, hi := range h.Initiators {
+				for _, i := range host.Initiators {
+					if strings.EqualFold(hi.PortName, i.PortName) {
+						writeFakeError(w, http.StatusUnprocessableEntity, "0xE0A08001003A", "Initiator "+i.PortName+" belongs to host "+h.Name)
+						return
+					}
+				}
+			}
+		}
+		host.ID = f.newID("host")
+		f.hosts = append(f.hosts, host)
+		writeFakeJSON(w, http.StatusCreated, map[string]string{"id": host.ID})
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		for _, h := range f.hosts {
+			if h.ID == segments[1] {
+				writeFakeJSON(w, http.StatusOK, h)
+				return
+			}
+		}
+		writeFakeError(w, http.StatusNotFound, "0xE04040010001", "Host "+segments[1]+" not found")
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "0xE04040010002", "Unsupported operation")
+	}
+}
+
+func (f *fakePowerStore) serveHostGroup(w http.ResponseWriter, r *http.Request, segments []string, body map[string]json.RawMessage) {
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		writeFakeJSON(w, http.StatusOK, page(r.URL.Query(), f.hostGroups, func(g PowerStoreHostGroup, field string) string {
+			return map[string]string{"id": g.ID, "name": g.Name}[field]
+		}))
+	case len(segments) == 1 && r.Method == http.MethodPost:
+		var group PowerStoreHostGroup
+		var hostIDs []string
+		_ = json.Unmarshal(body["name"], &group.Name)
+		_ = json.Unmarshal(body["host_ids"], &hostIDs)
+		for _, g := range f.hostGroups {
+			if g.Name == group.Name {
+				writeFakeError(w, http.StatusUnprocessableEntity, "0xE0A08001004A", "Host group name "+g.Name+" is already in use")
+				return
+			}
+		}
+		group.ID = f.newID("hg")
+		if !f.joinHostGroup(w, group.ID, hostIDs) {
+			return
+		}
+		f.hostGroups = append(f.hostGroups, group)
+		writeFakeJSON(w, http.StatusCreated, map[string]string{"id": group.ID})
+	case len(segments) == 2:
+		for _, g := range f.hostGroups {
+			if g.ID != segments[1] {
+				continue
+			}
+			if r.Method == http.MethodPatch {
+				var hostIDs []string
+				_ = json.Unmarshal(body["add_host_ids"], &hostIDs)
+				if f.joinHostGroup(w, g.ID, hostIDs) {
+					w.WriteHeader(http.StatusNoContent)
+				}
+				return
+			}
+			writeFakeJSON(w, http.StatusOK, g)
+			return
+		}
+		writeFakeError(w, http.StatusNotFound, "0xE04040010001", "Host group "+segments[1]+" not found")
+	default:
+		writeFakeError(w, http.StatusMethodNotAllowed, "0xE04040010002", "Unsupported operation")
+	}
+}
+
+// joinHostGroup adds hosts to a host group; a host belongs to one group at most
+func (f *fakePowerStore) joinHostGroup(w http.ResponseWriter, groupID string, hostIDs []string) bool {
+	for _, id := range hostIDs {
+		found := false
+		for i := range f.hosts {
+			if f.hosts[i].ID != id {
+				continue
+			}
+			if f.hosts[i].HostGroupID != "" && f.hosts[i].HostGroupID != groupID {
+				writeFakeError(w, http.StatusUnprocessableEntity, "0xE0A08001004B", "Host "+f.hosts[i].Name+" is already in a host group")
+				return false
+			}
+			f.hosts[i].HostGroupID = groupID
+			found = true
+		}
+		if !found {
+			writeFakeError(w, http.StatusNotFound, "0xE04040010001", "Host "+id+" not found")
+			return false
+		}
+	}
+	return true
+}
+
+// page applies the eq. filters, limit and offset of a collection query
+func page[T any](query url.Values, items []T, field func(T, string) string) []T {
+	out := []T{}
+	for _, item := range items {
+		matches := true
+		for key, values := range query {
+			if key == "select" || key == "limit" || key == "offset" {
+				continue
+			}
+			if field(item, key) != strings.TrimPrefix(values[0], "eq.") {
+				matches = false
+			}
+		}
+		if matches {
+			out = append(out, item)
+		}
+	}
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil {
+		limit = 100
+	}
+	if offset > len(out) {
+		offset = len(out)
+	}
+	return out[offset:min(offset+limit, len(out))]
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, code, message string) {
+	writeFakeJSON(w, status, map[string]interface{}{
+		"messages": []map[string]string{{"code": code, "severity": "Error", "message_l10n": message}},
+	})
+}
@@ -0,0 +1,899 @@
+package powerstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/fcutil"
+	"k8s.io/klog/v2"
+)
+
+// PowerStoreProviderID is the NAA prefix of PowerStore volumes (NAA type 6 with Dell's OUI)
+const PowerStoreProviderID = "68ccf098"
+
+// VendorName is the canonical vendor-type string registered with the storage
+// SDK and persisted on ArrayCreds.spec.vendorType.
+const VendorName = "powerstore"
+
+// ProviderOptions keys read by the PowerStore provider from
+// storage.StorageAccessInfo.ProviderOptions. Unknown keys are ignored.
+const (
+	// OptionAppliance pins new volumes to an appliance of the cluster, by
+	// name or ID. PowerStore places them itself when it is empty.
+	OptionAppliance = "appliance"
+)
+
+const (
+	// tokenHeader carries the CSRF token PowerStore requires on every request
+	// that changes state, obtained from the login session
+	tokenHeader = "DELL-EMC-TOKEN"
+	// maxHostName is the longest host name PowerStore accepts
+	maxHostName = 128
+	// pageSize is the largest page PowerStore returns for a collection query
+	pageSize = 2000
+	// volumeSizeAlignment is the size new volumes are rounded up to. The
+	// Cinder PowerStore driver only manages volumes that are a whole number of GiB.
+	volumeSizeAlignment = 1024 * 1024 * 1024
+
+	volumeFields    = "id,name,size,wwn,creation_timestamp,appliance_id"
+	hostFields      = "id,name,host_group_id,initiators"
+	hostGroupFields = "id,name"
+	mappingFields   = "id,host_id,host_group_id,volume_id,logical_unit_number"
+)
+
+// Initiator port types of PowerStore hosts
+const (
+	portTypeISCSI = "iSCSI"
+	portTypeFC    = "FC"
+	portTypeNVMe  = "NVMe"
+)
+
+func init() {
+	storage.RegisterStorageProvider(VendorName, &PowerStoreStorageProvider{})
+}
+
+// PowerStoreStorageProvider implements StorageProvider for Dell PowerStore
+// through the PowerStore REST API. Appliance optionally pins new volumes to
+// one appliance of the cluster.
+type PowerStoreStorageProvider struct {
+	storage.BaseStorageProvider
+	Appliance string
+	token     string
+}
+
+// PowerStore API response structures
+type PowerStoreCluster struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	GlobalID string `json:"global_id"`
+}
+
+type PowerStoreAppliance struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type PowerStoreVolume struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	Size              int64  `json:"size"`
+	WWN               string `json:"wwn"`
+	CreationTimestamp string `json:"creation_timestamp"`
+	ApplianceID       string `json:"appliance_id"`
+}
+
+type PowerStoreInitiator struct {
+	PortName string `json:"port_name"`
+	PortType string `json:"port_type"`
+}
+
+type PowerStoreHost struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	HostGroupID string                `json:"host_group_id"`
+	Initiators  []PowerStoreInitiator `json:"initiators"`
+}
+
+type PowerStoreHostGroup struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type PowerStoreMapping struct {
+	ID                string `json:"id"`
+	HostID            string `json:"host_id"`
+	HostGroupID       string `json:"host_group_id"`
+	VolumeID          string `json:"volume_id"`
+	LogicalUnitNumber int    `json:"logical_unit_number"`
+}
+
+type powerStoreCreated struct {
+	ID string `json:"id"`
+}
+
+// APIError is an error response of the PowerStore REST API
+type APIError struct {
+	StatusCode int
+	Messages   []string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("PowerStore API error (status %d): %s", e.StatusCode, strings.Join(e.Messages, "; "))
+}
+
+// Connect establishes a login session with the PowerStore cluster
+func (p *PowerStoreStorageProvider) Connect(ctx context.Context, accessInfo storage.StorageAccessInfo) error {
+	p.AccessInfo = accessInfo
+	p.Config = storage.VendorConfig{
+		NAAPrefix: PowerStoreProviderID,
+		Name:      "PowerStore",
+	}
+	p.BaseURL = fmt.Sprintf("https://%s/api/rest", accessInfo.Hostname)
+	p.Username = accessInfo.Username
+	p.Password = accessInfo.Password
+	p.Appliance = strings.TrimSpace(accessInfo.ProviderOptions[OptionAppliance])
+
+	p.InitHTTPClient(accessInfo.SkipSSLVerification)
+	// The session cookie must accompany the token on the requests that change state
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+	p.Client.Jar = jar
+
+	if err := p.login(ctx); err != nil {
+		return fmt.Errorf("failed to connect to PowerStore cluster: %w", err)
+	}
+	p.SetConnected(true)
+
+	cluster, err := p.getCluster(ctx)
+	if err != nil {
+		p.SetConnected(false)
+		return fmt.Errorf("failed to connect to PowerStore cluster: %w", err)
+	}
+	klog.Infof("Connected to PowerStore cluster: %s, ID: %s (appliance: %q)", cluster.Name, cluster.GlobalID, p.Appliance)
+	return nil
+}
+
+// Disconnect ends the login session
+func (p *PowerStoreStorageProvider) Disconnect() error {
+	if p.GetConnected() && p.token != "" {
+		if err := p.doRequest(context.Background(), http.MethodPost, "/logout", nil, nil); err != nil {
+			klog.Warningf("Failed to log out of PowerStore: %v", err)
+		}
+	}
+	p.token = ""
+	p.SetConnected(false)
+	return nil
+}
+
+// ValidateCredentials validates the credentials
+func (p *PowerStoreStorageProvider) ValidateCredentials(ctx context.Context) error {
+	if !p.GetConnected() {
+		if err := p.Connect(ctx, p.AccessInfo); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.getCluster(ctx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
+
+// CreateVolume creates a new volume, rounded up to a whole number of GiB, on
+// the configured appliance or where PowerStore places it
+func (p *PowerStoreStorageProvider) CreateVolume(volumeName string, size int64) (storage.Volume, error) {
+	ctx := context.Background()
+
+	alignedSize := (size + volumeSizeAlignment - 1) / volumeSizeAlignment * volumeSizeAlignment
+	reqBody := map[string]interface{}{
+		"name": volumeName,
+		"size": alignedSize,
+	}
+	if p.Appliance != "" {
+		appliance, err := p.getAppliance(ctx, p.Appliance)
+		if err != nil {
+			return storage.Volume{}, err
+		}
+		reqBody["appliance_id"] = appliance.ID
+	}
+
+	klog.Infof("Creating PowerStore volume %s with size %d (requested %d)", volumeName, alignedSize, size)
+	var created powerStoreCreated
+	if err := p.doRequest(ctx, http.MethodPost, "/volume", reqBody, &created); err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to create volume %s: %w", volumeName, err)
+	}
+
+	var v PowerStoreVolume
+	if err := p.doRequest(ctx, http.MethodGet, fmt.Sprintf("/volume/%s?select=%s", created.ID, volumeFields), nil, &v); err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to get created volume %s: %w", volumeName, err)
+	}
+	klog.Infof("Created PowerStore volume: %s, WWN: %s", v.Name, v.WWN)
+	return p.toVolume(v), nil
+}
+
+// DeleteVolume deletes a volume from the PowerStore cluster
+func (p *PowerStoreStorageProvider) DeleteVolume(volumeName string) error {
+	ctx := context.Background()
+	v, err := p.getVolumeByName(ctx, volumeName)
+	if err != nil {
+		return err
+	}
+
+	klog.Infof("Deleting PowerStore volume: %s (ID: %s)", v.Name, v.ID)
+	if err := p.doRequest(ctx, http.MethodDelete, "/volume/"+v.ID, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete volume %s: %w", volumeName, err)
+	}
+	return nil
+}
+
+// GetVolumeInfo retrieves information about a volume
+func (p *PowerStoreStorageProvider) GetVolumeInfo(volumeName string) (storage.VolumeInfo, error) {
+	v, err := p.getVolumeByName(context.Background(), volumeName)
+	if err != nil {
+		return storage.VolumeInfo{}, err
+	}
+	return p.toVolumeInfo(v), nil
+}
+
+// ListAllVolumes retrieves all volumes of the cluster with their NAA identifiers
+func (p *PowerStoreStorageProvider) ListAllVolumes() ([]storage.VolumeInfo, error) {
+	volumes, err := p.listVolumes(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+
+	volumeInfos := make([]storage.VolumeInfo, 0, len(volumes))
+	for _, v := range volumes {
+		volumeInfos = append(volumeInfos, p.toVolumeInfo(v))
+	}
+	return volumeInfos, nil
+}
+
+// GetAllVolumeNAAs retrieves NAA identifiers for all volumes of the cluster
+func (p *PowerStoreStorageProvider) GetAllVolumeNAAs() ([]string, error) {
+	return p.BaseStorageProvider.GetAllVolumeNAAs(p.ListAllVolumes)
+}
+
+// CreateOrUpdateInitiatorGroup makes sure the ESXi adapters belong to a host
+// group and returns the host groups to map volumes to. PowerStore maps volumes
+// to hosts, which hold the initiators, or to host groups of hosts.
+//
+// The hosts holding any of the adapters are looked up first, and a host
+// named after the group and the adapters is registered when there is none.
+// A host can only belong to one host group, so hosts already in a group are
+// mapped through their group and the others are added to initiatorGroupName.
+// Supports iSCSI (IQN), NVMe (NQN) and Fibre Channel (fc.WWNN:WWPN) adapters.
+func (p *PowerStoreStorageProvider) CreateOrUpdateInitiatorGroup(initiatorGroupName string, hbaIdentifiers []string) (storage.MappingContext, error) {
+	ctx := context.Background()
+
+	initiators, err := toPowerStoreInitiators(hbaIdentifiers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalise HBA identifiers: %w", err)
+	}
+
+	hosts, err := p.listHosts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %w", err)
+	}
+	var matchedHosts []PowerStoreHost
+	for _, h := range hosts {
+		if hostMatches(h, initiators) {
+			klog.Infof("Matched PowerStore host %s (host group: %q)", h.Name, h.HostGroupID)
+			matchedHosts = append(matchedHosts, h)
+		}
+	}
+
+	if len(matchedHosts) == 0 {
+		if mixedProtocols(initiators) {
+			return nil, fmt.Errorf(
+				"host has adapters of more than one protocol; cannot register a single PowerStore host — "+
+					"ensure the ESXi host uses a single transport type or register it on the PowerStore: %v", hbaIdentifiers)
+		}
+		host, err := p.createHost(ctx, storage.HostNameForAdapters(initiatorGroupName, hbaIdentifiers, maxHostName), initiators)
+		if err != nil {
+			return nil, err
+		}
+		matchedHosts = append(matchedHosts, host)
+	}
+
+	groupIDs := []string{}
+	var ungrouped []string
+	for _, h := range matchedHosts {
+		if h.HostGroupID == "" {
+			ungrouped = append(ungrouped, h.ID)
+		} else if !storage.SliceContains(groupIDs, h.HostGroupID) {
+			groupIDs = append(groupIDs, h.HostGroupID)
+		}
+	}
+	if len(ungrouped) > 0 {
+		group, err := p.ensureHostGroup(ctx, initiatorGroupName, ungrouped)
+		if err != nil {
+			return nil, err
+		}
+		if !storage.SliceContains(groupIDs, group.ID) {
+			groupIDs = append(groupIDs, group.ID)
+		}
+	}
+
+	groupNames := make([]string, 0, len(groupIDs))
+	for _, id := range groupIDs {
+		var group PowerStoreHostGroup
+		if err := p.doRequest(ctx, http.MethodGet, fmt.Sprintf("/host_group/%s?select=%s", id, hostGroupFields), nil, &group); err != nil {
+			return nil, fmt.Errorf("failed to get host group %s: %w", id, err)
+		}
+		groupNames = append(groupNames, group.Name)
+	}
+	return storage.MappingContext{"hostGroups": groupNames}, nil
+}
+
+// MapVolumeToGroup attaches a volume to the host groups of the mapping context
+func (p *PowerStoreStorageProvider) MapVolumeToGroup(initiatorGroupName string, targetVolume storage.Volume, mappingCtx storage.MappingContext) (storage.Volume, error) {
+	ctx := context.Background()
+
+	groupNames, ok := mappingCtx["hostGroups"].([]string)
+	if !ok || len(groupNames) == 0 {
+		return storage.Volume{}, errors.New("invalid or empty hostGroups list in mapping context")
+	}
+
+	v, err := p.getVolumeByName(ctx, targetVolume.Name)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	mappings, err := p.listMappings(ctx, v.ID)
+	if err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to get mappings of volume %s: %w", v.Name, err)
+	}
+
+	for _, groupName := range groupNames {
+		group, err := p.getHostGroupByName(ctx, groupName)
+		if err != nil {
+			return storage.Volume{}, err
+		}
+		if mappedToGroup(mappings, group.ID) {
+			klog.Infof("Volume %s already mapped to host group %s", v.Name, groupName)
+			continue
+		}
+		klog.Infof("Mapping volume %s to host group %s", v.Name, groupName)
+		body := map[string]interface{}{"host_group_id": group.ID}
+		if err := p.doRequest(ctx, http.MethodPost, fmt.Sprintf("/volume/%s/attach", v.ID), body, nil); err != nil {
+			return storage.Volume{}, fmt.Errorf("failed to map volume %s to host group %s: %w", v.Name, groupName, err)
+		}
+	}
+
+	return targetVolume, nil
+}
+
+// UnmapVolumeFromGroup detaches a volume from the host groups of the mapping context
+func (p *PowerStoreStorageProvider) UnmapVolumeFromGroup(initiatorGroupName string, targetVolume storage.Volume, mappingCtx storage.MappingContext) error {
+	ctx := context.Background()
+
+	groupNames, ok := mappingCtx["hostGroups"].([]string)
+	if !ok || len(groupNames) == 0 {
+		return nil
+	}
+
+	v, err := p.getVolumeByName(ctx, targetVolume.Name)
+	if err != nil {
+		klog.Warningf("Failed to get volume %s for unmapping: %v", targetVolume.Name, err)
+		return nil // The volume might already be deleted
+	}
+	mappings, err := p.listMappings(ctx, v.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get mappings of volume %s: %w", v.Name, err)
+	}
+
+	for _, groupName := range groupNames {
+		group, err := p.getHostGroupByName(ctx, groupName)
+		if err != nil {
+			klog.Warningf("Failed to get host group %s: %v", groupName, err)
+			continue
+		}
+		if !mappedToGroup(mappings, group.ID) {
+			continue
+		}
+		klog.Infof("Unmapping volume %s from host group %s", v.Name, groupName)
+		body := map[string]interface{}{"host_group_id": group.ID}
+		if err := p.doRequest(ctx, http.MethodPost, fmt.Sprintf("/volume/%s/detach", v.ID), body, nil); err != nil {
+			klog.Warningf("Failed to unmap volume %s from host group %s: %v", v.Name, groupName, err)
+		}
+	}
+	return nil
+}
+
+// GetMappedGroups returns the host groups, and the hosts outside of a host
+// group, the volume is mapped to
+func (p *PowerStoreStorageProvider) GetMappedGroups(targetVolume storage.Volume, mappingCtx storage.MappingContext) ([]string, error) {
+	ctx := context.Background()
+
+	v, err := p.getVolumeByName(ctx, targetVolume.Name)
+	if err != nil {
+		return nil, err
+	}
+	mappings, err := p.listMappings(ctx, v.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mappings of volume %s: %w", v.Name, err)
+	}
+
+	var groups []string
+	for _, m := range mappings {
+		endpoint := fmt.Sprintf("/host_group/%s?select=%s", m.HostGroupID, hostGroupFields)
+		if m.HostGroupID == "" {
+			endpoint = fmt.Sprintf("/host/%s?select=id,name", m.HostID)
+		}
+		var named PowerStoreHostGroup
+		if err := p.doRequest(ctx, http.MethodGet, endpoint, nil, &named); err != nil {
+			return nil, fmt.Errorf("failed to resolve mapping %s of volume %s: %w", m.ID, v.Name, err)
+		}
+		if !storage.SliceContains(groups, named.Name) {
+			groups = append(groups, named.Name)
+		}
+	}
+	return groups, nil
+}
+
+// ResolveCinderVolumeToLUN resolves a Cinder volume ID to its PowerStore volume.
+// The Cinder PowerStore driver names volumes, managed ones included, volume-<id>.
+func (p *PowerStoreStorageProvider) ResolveCinderVolumeToLUN(volumeID string) (storage.Volume, error) {
+	volumeName := fmt.Sprintf("volume-%s", volumeID)
+	v, err := p.getVolumeByName(context.Background(), volumeName)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+
+	klog.Infof("Resolved cinder volume %s to volume: %+v", volumeName, v)
+	return p.toVolume(v), nil
+}
+
+// GetVolumeFromNAA retrieves a PowerStore volume by its NAA identifier
+func (p *PowerStoreStorageProvider) GetVolumeFromNAA(naaID string) (storage.Volume, error) {
+	if !p.IsValidNAA(strings.ToLower(naaID)) {
+		return storage.Volume{}, fmt.Errorf("NAA ID %s is not from PowerStore (expected prefix: naa.%s)", naaID, PowerStoreProviderID)
+	}
+
+	volumes, err := p.listVolumes(context.Background(), url.Values{"wwn": {"eq." + strings.ToLower(naaID)}})
+	if err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	if len(volumes) == 0 {
+		return storage.Volume{}, fmt.Errorf("no PowerStore volume found with NAA %s", naaID)
+	}
+	klog.Infof("Found PowerStore volume %s matching NAA %s", volumes[0].Name, naaID)
+	return p.toVolume(volumes[0]), nil
+}
+
+// CreateSnapshot takes a snapshot of a volume. PowerStore snapshots are
+// volumes themselves, so they can be cloned like any other.
+// Implements storage.ArrayCloner.
+func (p *PowerStoreStorageProvider) CreateSnapshot(volume storage.Volume, snapshotName string) (storage.VolumeSnapshot, error) {
+	ctx := context.Background()
+	v, err := p.getVolumeByName(ctx, volume.Name)
+	if err != nil {
+		return storage.VolumeSnapshot{}, err
+	}
+
+	klog.Infof("Creating PowerStore snapshot %s of volume %s", snapshotName, v.Name)
+	var created powerStoreCreated
+	if err := p.doRequest(ctx, http.MethodPost, fmt.Sprintf("/volume/%s/snapshot", v.ID),
+		map[string]interface{}{"name": snapshotName}, &created); err != nil {
+		return storage.VolumeSnapshot{}, fmt.Errorf("failed to snapshot volume %s: %w", v.Name, err)
+	}
+	return storage.VolumeSnapshot{
+		Name:         snapshotName,
+		Id:           created.ID,
+		SourceVolume: v.Name,
+		Size:         v.Size,
+	}, nil
+}
+
+// CloneSnapshot creates a new volume from a snapshot.
+// Implements storage.ArrayCloner.
+func (p *PowerStoreStorageProvider) CloneSnapshot(snapshot storage.VolumeSnapshot, volumeName string) (storage.Volume, error) {
+	ctx := context.Background()
+
+	klog.Infof("Cloning PowerStore snapshot %s to volume %s", snapshot.Name, volumeName)
+	var created powerStoreCreated
+	if err := p.doRequest(ctx, http.MethodPost, fmt.Sprintf("/volume/%s/clone", snapshot.Id),
+		map[string]interface{}{"name": volumeName}, &created); err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to clone snapshot %s: %w", snapshot.Name, err)
+	}
+
+	var v PowerStoreVolume
+	if err := p.doRequest(ctx, http.MethodGet, fmt.Sprintf("/volume/%s?select=%s", created.ID, volumeFields), nil, &v); err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to get cloned volume %s: %w", volumeName, err)
+	}
+	klog.Infof("Cloned PowerStore volume: %s, WWN: %s", v.Name, v.WWN)
+	return p.toVolume(v), nil
+}
+
+// DeleteSnapshot deletes a snapshot taken with CreateSnapshot.
+// Implements storage.ArrayCloner.
+func (p *PowerStoreStorageProvider) DeleteSnapshot(snapshot storage.VolumeSnapshot) error {
+	klog.Infof("Deleting PowerStore snapshot: %s (ID: %s)", snapshot.Name, snapshot.Id)
+	if err := p.doRequest(context.Background(), http.MethodDelete, "/volume/"+snapshot.Id, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete snapshot %s: %w", snapshot.Name, err)
+	}
+	return nil
+}
+
+// WhoAmI returns the provider name
+func (p *PowerStoreStorageProvider) WhoAmI() string {
+	return VendorName
+}
+
+// DiscoverBackendTargets returns the cluster with its appliances, the targets
+// new volumes can be pinned to. Implements storage.BackendTargetDiscoverer.
+func (p *PowerStoreStorageProvider) DiscoverBackendTargets(ctx context.Context) ([]storage.BackendTargetGroup, error) {
+	cluster, err := p.getCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appliances, err := list[PowerStoreAppliance](ctx, p, "/appliance", url.Values{"select": {"id,name"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appliances: %w", err)
+	}
+
+	children := make([]storage.BackendTarget, 0, len(appliances))
+	for _, a := range appliances {
+		children = append(children, storage.BackendTarget{Name: a.Name, UUID: a.ID})
+	}
+	return []storage.BackendTargetGroup{{
+		Name:     cluster.Name,
+		UUID:     cluster.GlobalID,
+		Children: children,
+	}}, nil
+}
+
+// Helper methods
+
+// login opens a session and keeps the token PowerStore returns with it
+func (p *PowerStoreStorageProvider) login(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.BaseURL+"/login_session", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(p.Username, p.Password)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return apiError(resp)
+	}
+	p.token = resp.Header.Get(tokenHeader)
+	return nil
+}
+
+// doRequest performs a PowerStore API request, marshalling body to JSON and
+// unmarshalling the response into result when they are not nil. An expired
+// session is renewed once.
+func (p *PowerStoreStorageProvider) doRequest(ctx context.Context, method, endpoint string, body, result interface{}) error {
+	resp, err := p.send(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && p.token != "" {
+		resp.Body.Close()
+		klog.Infof("PowerStore session expired (status %d), logging in again", resp.StatusCode)
+		if err := p.login(ctx); err != nil {
+			return err
+		}
+		if resp, err = p.send(ctx, method, endpoint, body); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return apiError(resp)
+	}
+	if result == nil {
+		return nil
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func (p *PowerStoreStorageProvider) send(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.BaseURL+endpoint, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(p.Username, p.Password)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if p.token != "" {
+		req.Header.Set(tokenHeader, p.token)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// apiError builds an APIError from the messages of an error response
+func apiError(resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	data, _ := io.ReadAll(resp.Body)
+	var errBody struct {
+		Messages []struct {
+			Code    string `json:"code"`
+			Message string `json:"message_l10n"`
+		} `json:"messages"`
+	}
+	if json.Unmarshal(data, &errBody) == nil && len(errBody.Messages) > 0 {
+		for _, m := range errBody.Messages {
+			apiErr.Messages = append(apiErr.Messages, fmt.Sprintf("%s (%s)", m.Message, m.Code))
+		}
+	} else if len(data) > 0 {
+		apiErr.Messages = []string{string(data)}
+	}
+	return apiErr
+}
+
+// list fetches every item of a collection, a page at a time
+func list[T any](ctx context.Context, p *PowerStoreStorageProvider, collection string, query url.Values) ([]T, error) {
+	var items []T
+	for offset := 0; ; offset += pageSize {
+		pageQuery := url.Values{}
+		for k, v := range query {
+			pageQuery[k] = v
+		}
+		pageQuery.Set("limit", fmt.Sprint(pageSize))
+		pageQuery.Set("offset", fmt.Sprint(offset))
+
+		var page []T
+		if err := p.doRequest(ctx, http.MethodGet, collection+"?"+pageQuery.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if len(page) < pageSize {
+			return items, nil
+		}
+	}
+}
+
+func (p *PowerStoreStorageProvider) getCluster(ctx context.Context) (*PowerStoreCluster, error) {
+	clusters, err := list[PowerStoreCluster](ctx, p, "/cluster", url.Values{"select": {"id,name,global_id"}})
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters) == 0 {
+		return nil, errors.New("no PowerStore cluster returned")
+	}
+	return &clusters[0], nil
+}
+
+// getAppliance finds an appliance of the cluster by name or ID
+func (p *PowerStoreStorageProvider) getAppliance(ctx context.Context, nameOrID string) (*PowerStoreAppliance, error) {
+	appliances, err := list[PowerStoreAppliance](ctx, p, "/appliance", url.Values{"select": {"id,name"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appliances: %w", err)
+	}
+	for _, a := range appliances {
+		if a.Name == nameOrID || a.ID == nameOrID {
+			return &a, nil
+		}
+	}
+	return nil, fmt.Errorf("appliance %s not found on the PowerStore cluster", nameOrID)
+}
+
+func (p *PowerStoreStorageProvider) listVolumes(ctx context.Context, filter url.Values) ([]PowerStoreVolume, error) {
+	query := url.Values{"select": {volumeFields}}
+	for k, v := range filter {
+		query[k] = v
+	}
+	return list[PowerStoreVolume](ctx, p, "/volume", query)
+}
+
+func (p *PowerStoreStorageProvider) getVolumeByName(ctx context.Context, name string) (PowerStoreVolume, error) {
+	volumes, err := p.listVolumes(ctx, url.Values{"name": {"eq." + name}})
+	if err != nil {
+		return PowerStoreVolume{}, fmt.Errorf("failed to get volume %s: %w", name, err)
+	}
+	if len(volumes) == 0 {
+		return PowerStoreVolume{}, fmt.Errorf("volume %s not found", name)
+	}
+	return volumes[0], nil
+}
+
+func (p *PowerStoreStorageProvider) listHosts(ctx context.Context) ([]PowerStoreHost, error) {
+	return list[PowerStoreHost](ctx, p, "/host", url.Values{"select": {hostFields}})
+}
+
+func (p *PowerStoreStorageProvider) listMappings(ctx context.Context, volumeID string) ([]PowerStoreMapping, error) {
+	return list[PowerStoreMapping](ctx, p, "/host_volume_mapping", url.Values{
+		"select":    {mappingFields},
+		"volume_id": {"eq." + volumeID},
+	})
+}
+
+func (p *PowerStoreStorageProvider) getHostGroupByName(ctx context.Context, name string) (*PowerStoreHostGroup, error) {
+	group, err := p.findHostGroup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, fmt.Errorf("host group %s not found", name)
+	}
+	return group, nil
+}
+
+// findHostGroup returns the named host group, nil when there is none
+func (p *PowerStoreStorageProvider) findHostGroup(ctx context.Context, name string) (*PowerStoreHostGroup, error) {
+	groups, err := list[PowerStoreHostGroup](ctx, p, "/host_group", url.Values{
+		"select": {hostGroupFields},
+		"name":   {"eq." + name},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get host group %s: %w", name, err)
+	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	return &groups[0], nil
+}
+
+// createHost registers an ESXi host with its initiators
+func (p *PowerStoreStorageProvider) createHost(ctx context.Context, name string, initiators []PowerStoreInitiator) (PowerStoreHost, error) {
+	klog.Infof("No PowerStore host holds the ESXi adapters, registering host %s with initiators %v", name, initiators)
+	body := map[string]interface{}{
+		"name":       name,
+		"os_type":    "ESXi",
+		"initiators": initiators,
+	}
+	var created powerStoreCreated
+	if err := p.doRequest(ctx, http.MethodPost, "/host", body, &created); err != nil {
+		return PowerStoreHost{}, fmt.Errorf("failed to register host %s: %w", name, err)
+	}
+	return PowerStoreHost{ID: created.ID, Name: name, Initiators: initiators}, nil
+}
+
+// ensureHostGroup returns the named host group with hostIDs added to it,
+// creating the group when it does not exist yet
+func (p *PowerStoreStorageProvider) ensureHostGroup(ctx context.Context, name string, hostIDs []string) (*PowerStoreHostGroup, error) {
+	group, err := p.findHostGroup(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		klog.Infof("Creating PowerStore host group %s with hosts %v", name, hostIDs)
+		var created powerStoreCreated
+		body := map[string]interface{}{"name": name, "host_ids": hostIDs}
+		createErr := p.doRequest(ctx, http.MethodPost, "/host_group", body, &created)
+		if createErr == nil {
+			return &PowerStoreHostGroup{ID: created.ID, Name: name}, nil
+		}
+		// Concurrent migrations can race on creating the same group; join
+		// the group the other one created
+		if group, err = p.findHostGroup(ctx, name); err != nil || group == nil {
+			return nil, fmt.Errorf("failed to create host group %s: %w", name, createErr)
+		}
+	}
+
+	klog.Infof("Adding hosts %v to PowerStore host group %s", hostIDs, name)
+	body := map[string]interface{}{"add_host_ids": hostIDs}
+	if err := p.doRequest(ctx, http.MethodPatch, "/host_group/"+group.ID, body, nil); err != nil {
+		return nil, fmt.Errorf("failed to add hosts to host group %s: %w", name, err)
+	}
+	return group, nil
+}
+
+func (p *PowerStoreStorageProvider) toVolume(v PowerStoreVolume) storage.Volume {
+	serial := p.serialFromWWN(v.WWN)
+	return storage.Volume{
+		Name:         v.Name,
+		Size:         v.Size,
+		Id:           v.ID,
+		SerialNumber: serial,
+		NAA:          p.BuildNAA(serial),
+	}
+}
+
+func (p *PowerStoreStorageProvider) toVolumeInfo(v PowerStoreVolume) storage.VolumeInfo {
+	return storage.VolumeInfo{
+		Name:    v.Name,
+		Size:    v.Size,
+		Created: v.CreationTimestamp,
+		NAA:     p.BuildNAA(p.serialFromWWN(v.WWN)),
+	}
+}
+
+// serialFromWWN returns the part of a volume WWN (naa.68ccf098...) after the
+// PowerStore NAA prefix
+func (p *PowerStoreStorageProvider) serialFromWWN(wwn string) string {
+	serial, err := p.ExtractSerialFromNAA(strings.ToLower(wwn))
+	if err != nil {
+		return strings.TrimPrefix(strings.ToLower(wwn), "naa.")
+	}
+	return serial
+}
+
+// toPowerStoreInitiators converts ESXi adapter identifiers to PowerStore
+// initiators. FC adapter UIDs (fc.WWNN:WWPN) become colon-separated WWPNs.
+func toPowerStoreInitiators(hbaIdentifiers []string) ([]PowerStoreInitiator, error) {
+	initiators := make([]PowerStoreInitiator, 0, len(hbaIdentifiers))
+	for _, id := range hbaIdentifiers {
+		lower := strings.ToLower(id)
+		switch {
+		case strings.HasPrefix(lower, "fc."):
+			wwpn, err := fcutil.FormattedWWPNFromFCUID(id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse FC adapter UID %q: %w", id, err)
+			}
+			initiators = append(initiators, PowerStoreInitiator{PortName: strings.ToLower(wwpn), PortType: portTypeFC})
+		case strings.HasPrefix(lower, "nqn."):
+			initiators = append(initiators, PowerStoreInitiator{PortName: id, PortType: portTypeNVMe})
+		default:
+			initiators = append(initiators, PowerStoreInitiator{PortName: id, PortType: portTypeISCSI})
+		}
+	}
+	return initiators, nil
+}
+
+// hostMatches reports whether a PowerStore host holds any of the initiators.
+// WWPNs are compared without separators, IQNs and NQNs case-insensitively.
+func hostMatches(host PowerStoreHost, initiators []PowerStoreInitiator) bool {
+	for _, hi := range host.Initiators {
+		for _, want := range initiators {
+			if want.PortType == portTypeFC {
+				if strings.EqualFold(hi.PortType, portTypeFC) && fcutil.EqualWWNs(hi.PortName, want.PortName) {
+					return true
+				}
+			} else if strings.EqualFold(hi.PortName, want.PortName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mixedProtocols reports whether the initiators are not all of one port type
+func mixedProtocols(initiators []PowerStoreInitiator) bool {
+	for _, i := range initiators {
+		if i.PortType != initiators[0].PortType {
+			return true
+		}
+	}
+	return false
+}
+
+// mappedToGroup reports whether one of the mappings of a volume is to the host group
+func mappedToGroup(mappings []PowerStoreMapping, hostGroupID string) bool {
+	for _, m := range mappings {
+		if m.HostGroupID == hostGroupID {
+			return true
+		}
+	}
+	return false
+}
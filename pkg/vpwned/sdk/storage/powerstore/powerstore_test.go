@@ -0,0 +1,346 @@
+package powerstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
+)
+
+const gib = 1024 * 1024 * 1024
+
+func connectFake(t *testing.T, f *fakePowerStore, options map[string]string) *PowerStoreStorageProvider {
+	t.Helper()
+	p := &PowerStoreStorageProvider{}
+	if err := p.Connect(context.Background(), f.accessInfo(options)); err != nil {
+		t.Fatalf("Connect() unexpected error: %v", err)
+	}
+	return p
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := storage.NewStorageProvider("PowerStore")
+	if err != nil {
+		t.Fatalf("NewStorageProvider() unexpected error: %v", err)
+	}
+	if provider.WhoAmI() != VendorName {
+		t.Errorf("WhoAmI() = %q, want %q", provider.WhoAmI(), VendorName)
+	}
+	if _, ok := provider.(storage.BackendTargetDiscoverer); !ok {
+		t.Error("provider does not implement BackendTargetDiscoverer")
+	}
+	if _, ok := provider.(storage.ArrayCloner); !ok {
+		t.Error("provider does not implement ArrayCloner")
+	}
+}
+
+func TestConnect(t *testing.T) {
+	f := newFakePowerStore(t)
+	p := connectFake(t, f, nil)
+	if err := p.ValidateCredentials(context.Background()); err != nil {
+		t.Errorf("ValidateCredentials() unexpected error: %v", err)
+	}
+	if err := p.Disconnect(); err != nil {
+		t.Errorf("Disconnect() unexpected error: %v", err)
+	}
+	if f.token != "" {
+		t.Error("Disconnect() did not log out")
+	}
+
+	info := f.accessInfo(nil)
+	info.Password = "wrong"
+	err := (&PowerStoreStorageProvider{}).Connect(context.Background(), info)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 401 || !strings.Contains(err.Error(), "Authentication failed") {
+		t.Errorf("Connect() with a wrong password = %v, want the 401 of the array", err)
+	}
+}
+
+func TestSessionRenewal(t *testing.T) {
+	f := newFakePowerStore(t)
+	p := connectFake(t, f, nil)
+	f.expireSession()
+
+	if _, err := p.CreateVolume("after-expiry", gib); err != nil {
+		t.Fatalf("CreateVolume() after the session expired: %v", err)
+	}
+	if f.sessions != 2 {
+		t.Errorf("logged in %d times, want 2", f.sessions)
+	}
+}
+
+func TestVolumes(t *testing.T) {
+	f := newFakePowerStore(t)
+	p := connectFake(t, f, nil)
+
+	created, err := p.CreateVolume("vm1-disk1", 10*gib+512)
+	if err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+	if created.Size != 11*gib {
+		t.Errorf("size = %d, want rounded up to %d", created.Size, 11*gib)
+	}
+	if !strings.HasPrefix(created.NAA, "naa."+PowerStoreProviderID) || created.NAA != f.volumes[0].WWN {
+		t.Errorf("NAA = %q, want the WWN %q", created.NAA, f.volumes[0].WWN)
+	}
+	if created.Id == "" || created.SerialNumber == "" {
+		t.Errorf("volume = %+v, want its ID and serial", created)
+	}
+
+	info, err := p.GetVolumeInfo("vm1-disk1")
+	if err != nil || info.NAA != created.NAA || info.Size != created.Size {
+		t.Errorf("GetVolumeInfo() = %+v, %v, want the created volume", info, err)
+	}
+	byNAA, err := p.GetVolumeFromNAA(strings.ToUpper(created.NAA))
+	if err != nil || byNAA.Name != "vm1-disk1" {
+		t.Errorf("GetVolumeFromNAA() = %+v, %v, want vm1-disk1", byNAA, err)
+	}
+	if _, err := p.GetVolumeFromNAA("naa.624a9370abcdef"); err == nil {
+		t.Error("GetVolumeFromNAA() of a Pure NAA succeeded")
+	}
+
+	if _, err := p.CreateVolume("vm1-disk1", gib); err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Errorf("CreateVolume() of a duplicate = %v, want the array error", err)
+	}
+
+	if err := p.DeleteVolume("vm1-disk1"); err != nil {
+		t.Fatalf("DeleteVolume() unexpected error: %v", err)
+	}
+	if _, err := p.GetVolumeInfo("vm1-disk1"); err == nil {
+		t.Error("volume still found after DeleteVolume()")
+	}
+	if err := p.DeleteVolume("vm1-disk1"); err == nil {
+		t.Error("DeleteVolume() of a missing volume succeeded")
+	}
+}
+
+func TestListAllVolumesPages(t *testing.T) {
+	f := newFakePowerStore(t)
+	for i := 0; i < pageSize+5; i++ {
+		f.addVolume(fmt.Sprintf("volume-%d", i), gib)
+	}
+	p := connectFake(t, f, nil)
+
+	volumes, err := p.ListAllVolumes()
+	if err != nil {
+		t.Fatalf("ListAllVolumes() unexpected error: %v", err)
+	}
+	if len(volumes) != pageSize+5 {
+		t.Errorf("ListAllVolumes() returned %d volumes, want %d", len(volumes), pageSize+5)
+	}
+	naas, err := p.GetAllVolumeNAAs()
+	if err != nil || len(naas) != pageSize+5 || naas[0] != f.volumes[0].WWN {
+		t.Errorf("GetAllVolumeNAAs() = %d NAAs, %v", len(naas), err)
+	}
+}
+
+func TestCreateVolumeOnAppliance(t *testing.T) {
+	f := newFakePowerStore(t)
+	p := connectFake(t, f, map[string]string{OptionAppliance: "ps-appliance-2"})
+	if _, err := p.CreateVolume("pinned", gib); err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+	if f.volumes[0].ApplianceID != "A2" {
+		t.Errorf("volume created on appliance %s, want A2", f.volumes[0].ApplianceID)
+	}
+
+	p = connectFake(t, f, map[string]string{OptionAppliance: "ps-appliance-9"})
+	if _, err := p.CreateVolume("nowhere", gib); err == nil || !strings.Contains(err.Error(), "ps-appliance-9") {
+		t.Errorf("CreateVolume() on a missing appliance = %v, want it named", err)
+	}
+}
+
+func TestCreateOrUpdateInitiatorGroup(t *testing.T) {
+	iqn := "iqn.1998-01.com.vmware:esxi01-4f1c"
+	fcUID := "fc.20000090fa6e67a8:21000090fa6e67a8"
+
+	t.Run("host in a host group", func(t *testing.T) {
+		f := newFakePowerStore(t)
+		group := f.addHostGroup("esxi-cluster")
+		f.addHost("esxi01", group.ID, PowerStoreInitiator{PortName: iqn, PortType: portTypeISCSI})
+		p := connectFake(t, f, nil)
+
+		mappingCtx, err := p.CreateOrUpdateInitiatorGroup("vjailbreak-xcopy", []string{strings.ToUpper(iqn)})
+		if err != nil {
+			t.Fatalf("CreateOrUpdateInitiatorGroup() unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(mappingCtx["hostGroups"], []string{"esxi-cluster"}) {
+			t.Errorf("hostGroups = %v, want the group of the host", mappingCtx["hostGroups"])
+		}
+		if len(f.hostGroups) != 1 {
+			t.Errorf("host groups = %+v, want none created", f.hostGroups)
+		}
+	})
+
+	t.Run("host outside of a host group", func(t *testing.T) {
+		f := newFakePowerStore(t)
+		host := f.addHost("esxi01", "", PowerStoreInitiator{PortName: "21:00:00:90:fa:6e:67:a8", PortType: portTypeFC})
+		p := connectFake(t, f, nil)
+
+		for i := 0; i < 2; i++ {
+			mappingCtx, err := p.CreateOrUpdateInitiatorGroup("vjailbreak-xcopy", []string{fcUID})
+			if err != nil {
+				t.Fatalf("CreateOrUpdateInitiatorGroup() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(mappingCtx["hostGroups"], []string{"vjailbreak-xcopy"}) {
+				t.Errorf("hostGroups = %v, want vjailbreak-xcopy", mappingCtx["hostGroups"])
+			}
+		}
+		host, _ = f.hostByName(host.Name)
+		if len(f.hostGroups) != 1 || host.HostGroupID != f.hostGroups[0].ID {
+			t.Errorf("host %+v, groups %+v, want the host in the created group", host, f.hostGroups)
+		}
+	})
+
+	t.Run("unregistered host", func(t *testing.T) {
+		f := newFakePowerStore(t)
+		f.addHostGroup("vjailbreak-xcopy")
+		p := connectFake(t, f, nil)
+
+		mappingCtx, err := p.CreateOrUpdateInitiatorGroup("vjailbreak-xcopy", []string{fcUID})
+		if err != nil {
+			t.Fatalf("CreateOrUpdateInitiatorGroup() unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(mappingCtx["hostGroups"], []string{"vjailbreak-xcopy"}) {
+			t.Errorf("hostGroups = %v, want vjailbreak-xcopy", mappingCtx["hostGroups"])
+		}
+		host, ok := f.hostByName(storage.HostNameForAdapters("vjailbreak-xcopy", []string{fcUID}, maxHostName))
+		expected := []PowerStoreInitiator{{PortName: "21:00:00:90:fa:6e:67:a8", PortType: portTypeFC}}
+		if !ok || !reflect.DeepEqual(host.Initiators, expected) || host.HostGroupID != f.hostGroups[0].ID {
+			t.Errorf("registered host = %+v, want the WWPN in the existing group", host)
+		}
+	})
+
+	t.Run("unregistered host with mixed adapters", func(t *testing.T) {
+		f := newFakePowerStore(t)
+		p := connectFake(t, f, nil)
+		if _, err := p.CreateOrUpdateInitiatorGroup("vjailbreak-xcopy", []string{iqn, fcUID}); err == nil {
+			t.Error("CreateOrUpdateInitiatorGroup() with iSCSI and FC adapters succeeded")
+		}
+		if len(f.hosts) != 0 {
+			t.Errorf("hosts = %+v, want none registered", f.hosts)
+		}
+	})
+}
+
+func TestMapVolumeToGroup(t *testing.T) {
+	f := newFakePowerStore(t)
+	group := f.addHostGroup("vjailbreak-xcopy")
+	f.addHost("esxi01", group.ID, PowerStoreInitiator{PortName: "iqn.1998-01.com.vmware:esxi01", PortType: portTypeISCSI})
+	volume := f.addVolume("volume-5a8e", gib)
+	p := connectFake(t, f, nil)
+	mappingCtx := storage.MappingContext{"hostGroups": []string{"vjailbreak-xcopy"}}
+	target := storage.Volume{Name: volume.Name}
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.MapVolumeToGroup("vjailbreak-xcopy", target, mappingCtx); err != nil {
+			t.Fatalf("MapVolumeToGroup() unexpected error: %v", err)
+		}
+	}
+	if len(f.mappings) != 1 || f.mappings[0].HostGroupID != group.ID {
+		t.Errorf("mappings = %+v, want one to the host group", f.mappings)
+	}
+	groups, err := p.GetMappedGroups(target, mappingCtx)
+	if err != nil || !reflect.DeepEqual(groups, []string{"vjailbreak-xcopy"}) {
+		t.Errorf("GetMappedGroups() = %v, %v, want vjailbreak-xcopy", groups, err)
+	}
+	if err := p.DeleteVolume(volume.Name); err == nil {
+		t.Error("DeleteVolume() of a mapped volume succeeded")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := p.UnmapVolumeFromGroup("vjailbreak-xcopy", target, mappingCtx); err != nil {
+			t.Fatalf("UnmapVolumeFromGroup() unexpected error: %v", err)
+		}
+	}
+	if len(f.mappings) != 0 {
+		t.Errorf("mappings = %+v, want none", f.mappings)
+	}
+	if err := p.UnmapVolumeFromGroup("vjailbreak-xcopy", storage.Volume{Name: "gone"}, mappingCtx); err != nil {
+		t.Errorf("UnmapVolumeFromGroup() of a deleted volume = %v, want nil", err)
+	}
+	if _, err := p.MapVolumeToGroup("vjailbreak-xcopy", target, storage.MappingContext{}); err == nil {
+		t.Error("MapVolumeToGroup() without host groups succeeded")
+	}
+}
+
+func TestResolveCinderVolumeToLUN(t *testing.T) {
+	f := newFakePowerStore(t)
+	volume := f.addVolume("volume-5a8e0c2b-0d6f-4c43-a1b0-7f0e4f5d2a11", 20*gib)
+	p := connectFake(t, f, nil)
+
+	resolved, err := p.ResolveCinderVolumeToLUN("5a8e0c2b-0d6f-4c43-a1b0-7f0e4f5d2a11")
+	if err != nil {
+		t.Fatalf("ResolveCinderVolumeToLUN() unexpected error: %v", err)
+	}
+	if resolved.Name != volume.Name || resolved.NAA != volume.WWN || resolved.Size != 20*gib {
+		t.Errorf("ResolveCinderVolumeToLUN() = %+v, want %+v", resolved, volume)
+	}
+	if _, err := p.ResolveCinderVolumeToLUN("missing"); err == nil {
+		t.Error("ResolveCinderVolumeToLUN() of a missing volume succeeded")
+	}
+}
+
+func TestSnapshotClone(t *testing.T) {
+	f := newFakePowerStore(t)
+	p := connectFake(t, f, nil)
+	datastore := f.addVolume("datastore1", 100*gib)
+
+	lun, err := p.GetVolumeFromNAA(datastore.WWN)
+	if err != nil {
+		t.Fatalf("GetVolumeFromNAA() unexpected error: %v", err)
+	}
+	snapshot, err := p.CreateSnapshot(lun, "datastore1-snap")
+	if err != nil {
+		t.Fatalf("CreateSnapshot() unexpected error: %v", err)
+	}
+	if snapshot.SourceVolume != "datastore1" || snapshot.Size != 100*gib || snapshot.Id == "" {
+		t.Errorf("CreateSnapshot() = %+v", snapshot)
+	}
+
+	clone, err := p.CloneSnapshot(snapshot, "datastore1-clone")
+	if err != nil {
+		t.Fatalf("CloneSnapshot() unexpected error: %v", err)
+	}
+	if clone.Name != "datastore1-clone" || clone.Size != 100*gib || clone.NAA == "" || clone.NAA == lun.NAA {
+		t.Errorf("CloneSnapshot() = %+v", clone)
+	}
+	if _, err := p.CloneSnapshot(snapshot, "datastore1-clone"); err == nil {
+		t.Error("CloneSnapshot() to a taken name succeeded")
+	}
+
+	if err := p.DeleteSnapshot(snapshot); err != nil {
+		t.Fatalf("DeleteSnapshot() unexpected error: %v", err)
+	}
+	if _, err := p.GetVolumeInfo("datastore1-snap"); err == nil {
+		t.Error("snapshot still exists after DeleteSnapshot()")
+	}
+	if _, err := p.GetVolumeInfo("datastore1-clone"); err != nil {
+		t.Errorf("clone is gone after DeleteSnapshot(): %v", err)
+	}
+}
+
+func TestDiscoverBackendTargets(t *testing.T) {
+	f := newFakePowerStore(t)
+	p := connectFake(t, f, nil)
+
+	groups, err := p.DiscoverBackendTargets(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverBackendTargets() unexpected error: %v", err)
+	}
+	expected := []storage.BackendTargetGroup{{
+		Name: "ps-cluster",
+		UUID: "PS4f8a2c1d0e3b",
+		Children: []storage.BackendTarget{
+			{Name: "ps-appliance-1", UUID: "A1"},
+			{Name: "ps-appliance-2", UUID: "A2"},
+		},
+	}}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("DiscoverBackendTargets() = %+v, want %+v", groups, expected)
+	}
+}
@@ -2,6 +2,9 @@ package providers
 
 import (
 	// Import all storage providers to register them
+	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/hpalletra"
 	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/netapp"
+	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/powerstore"
 	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/pure"
+	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/simulator"
 )
@@ -329,6 +329,46 @@ func (p *PureStorageProvider) GetVolumeFromNAA(naaID string) (storage.Volume, er
 	return storage.Volume{}, fmt.Errorf("no Pure volume found with NAA %s (serial: %s)", naaID, serial)
 }
 
+// CreateSnapshot takes a snapshot of a volume. Pure names it
+// <volume>.<snapshotName>. Implements storage.ArrayCloner.
+func (p *PureStorageProvider) CreateSnapshot(volume storage.Volume, snapshotName string) (storage.VolumeSnapshot, error) {
+	snapshot, err := p.client.Volumes.CreateSnapshot(volume.Name, snapshotName)
+	if err != nil {
+		return storage.VolumeSnapshot{}, fmt.Errorf("failed to snapshot volume %s: %w", volume.Name, err)
+	}
+	klog.Infof("Created Pure snapshot %s of volume %s", snapshot.Name, volume.Name)
+	return storage.VolumeSnapshot{
+		Name:         snapshot.Name,
+		SourceVolume: volume.Name,
+		Size:         snapshot.Size,
+	}, nil
+}
+
+// CloneSnapshot creates a new volume from a snapshot. Implements storage.ArrayCloner.
+func (p *PureStorageProvider) CloneSnapshot(snapshot storage.VolumeSnapshot, volumeName string) (storage.Volume, error) {
+	volume, err := p.client.Volumes.CopyVolume(volumeName, snapshot.Name, false)
+	if err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to clone snapshot %s to volume %s: %w", snapshot.Name, volumeName, err)
+	}
+	klog.Infof("Cloned Pure snapshot %s to volume %s", snapshot.Name, volume.Name)
+	return storage.Volume{
+		Name:         volume.Name,
+		Size:         volume.Size,
+		SerialNumber: volume.Serial,
+		NAA:          p.BuildNAA(volume.Serial),
+	}, nil
+}
+
+// DeleteSnapshot destroys a snapshot taken with CreateSnapshot. Like
+// destroyed volumes, it is eradicated by the array after its retention period.
+// Implements storage.ArrayCloner.
+func (p *PureStorageProvider) DeleteSnapshot(snapshot storage.VolumeSnapshot) error {
+	if _, err := p.client.Volumes.DeleteVolume(snapshot.Name); err != nil {
+		return fmt.Errorf("failed to delete snapshot %s: %w", snapshot.Name, err)
+	}
+	return nil
+}
+
 // WhoAmI returns the provider name
 func (p *PureStorageProvider) WhoAmI() string {
 	return "pure"
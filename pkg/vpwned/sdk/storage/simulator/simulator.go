@@ -0,0 +1,708 @@
+// Package simulator provides a simulated storage array for exercising the
+// StorageAcceleratedCopy code paths without hardware. The array keeps its
+// volumes, initiator groups and mappings in memory, shared by every provider
+// in the process that connects to the same hostname, or in a JSON state file
+// when OptionStateFile is set so that several processes can share it. The
+// processes take turns through a flock on the state file's .lock sibling.
+//
+// No Cinder driver stands behind a simulated array, so nothing renames its
+// volumes when Cinder manages them. ManageVolume does what the driver would,
+// the provider is a storage.CinderManageRecorder for the
+// StorageAcceleratedCopy flow to call it after managing a volume.
+//
+// Faults are injected through ProviderOptions: OptionFailMapping makes
+// mapping fail, OptionCreateDelay slows volume creation down and
+// OptionNAACollision gives new volumes the NAA of an existing one.
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
+	"k8s.io/klog/v2"
+)
+
+// SimulatorProviderID is the NAA prefix of simulated volumes (NAA type 6 with
+// an all-zero OUI, which no array uses)
+const SimulatorProviderID = "6000000"
+
+// VendorName is the canonical vendor-type string registered with the storage
+// SDK and persisted on ArrayCreds.spec.vendorType.
+const VendorName = "simulator"
+
+// ProviderOptions keys read by the simulator from
+// storage.StorageAccessInfo.ProviderOptions. Unknown keys are ignored.
+const (
+	// OptionStateFile is the JSON file the array state is kept in. The state
+	// is kept in memory when it is empty.
+	OptionStateFile = "stateFile"
+	// OptionFailMapping makes every MapVolumeToGroup fail when "true"
+	OptionFailMapping = "failMapping"
+	// OptionCreateDelay is how long CreateVolume takes, as a Go duration
+	OptionCreateDelay = "createDelay"
+	// OptionNAACollision gives new volumes the NAA of the first volume of
+	// the array when "true"
+	OptionNAACollision = "naaCollision"
+)
+
+// ErrInjectedFault is wrapped by the errors of injected faults
+var ErrInjectedFault = errors.New("simulator: injected fault")
+
+var (
+	// stateMu serialises the operations on every simulated array of the process
+	stateMu sync.Mutex
+	// arrays holds the in-memory arrays by hostname
+	arrays = map[string]*SimulatorState{}
+)
+
+func init() {
+	storage.RegisterStorageProvider(VendorName, &SimulatorStorageProvider{})
+}
+
+// SimulatorStorageProvider implements StorageProvider for a simulated array
+type SimulatorStorageProvider struct {
+	storage.BaseStorageProvider
+	StateFile    string
+	FailMapping  bool
+	CreateDelay  time.Duration
+	NAACollision bool
+}
+
+// SimulatorState is the state of a simulated array, as kept in the state file
+type SimulatorState struct {
+	Volumes         []SimulatorVolume         `json:"volumes"`
+	InitiatorGroups []SimulatorInitiatorGroup `json:"initiatorGroups"`
+	Mappings        []SimulatorMapping        `json:"mappings"`
+	Snapshots       []SimulatorSnapshot       `json:"snapshots,omitempty"`
+	// NextSerial is the serial number of the next volume
+	NextSerial int64 `json:"nextSerial"`
+}
+
+type SimulatorVolume struct {
+	Name    string `json:"name"`
+	ID      string `json:"id"`
+	Serial  string `json:"serial"`
+	NAA     string `json:"naa"`
+	Size    int64  `json:"size"`
+	Created string `json:"created"`
+	// CinderID is the Cinder volume managing the volume, if any
+	CinderID string `json:"cinderID,omitempty"`
+}
+
+type SimulatorInitiatorGroup struct {
+	Name       string   `json:"name"`
+	Initiators []string `json:"initiators"`
+}
+
+type SimulatorSnapshot struct {
+	Name   string `json:"name"`
+	ID     string `json:"id"`
+	Volume string `json:"volume"`
+	Size   int64  `json:"size"`
+}
+
+type SimulatorMapping struct {
+	Volume         string `json:"volume"`
+	InitiatorGroup string `json:"initiatorGroup"`
+	LUN            int    `json:"lun"`
+}
+
+// Connect attaches to the simulated array named by the hostname and reads
+// the fault injection options. Any credentials are accepted.
+func (s *SimulatorStorageProvider) Connect(ctx context.Context, accessInfo storage.StorageAccessInfo) error {
+	if accessInfo.Hostname == "" {
+		return errors.New("failed to connect to simulated array: hostname is required")
+	}
+	s.AccessInfo = accessInfo
+	s.Config = storage.VendorConfig{
+		NAAPrefix: SimulatorProviderID,
+		Name:      "Simulator",
+	}
+	s.Username = accessInfo.Username
+	s.Password = accessInfo.Password
+
+	options := accessInfo.ProviderOptions
+	s.StateFile = strings.TrimSpace(options[OptionStateFile])
+	var err error
+	if s.FailMapping, err = parseBoolOption(options, OptionFailMapping); err != nil {
+		return err
+	}
+	if s.NAACollision, err = parseBoolOption(options, OptionNAACollision); err != nil {
+		return err
+	}
+	s.CreateDelay = 0
+	if value := strings.TrimSpace(options[OptionCreateDelay]); value != "" {
+		if s.CreateDelay, err = time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid %s option %q: %w", OptionCreateDelay, value, err)
+		}
+	}
+
+	if err := s.view(func(*SimulatorState) error { return nil }); err != nil {
+		return fmt.Errorf("failed to connect to simulated array: %w", err)
+	}
+	s.SetConnected(true)
+	klog.Infof("Connected to simulated array %s (state file: %q, fail mapping: %t, create delay: %s, NAA collision: %t)",
+		accessInfo.Hostname, s.StateFile, s.FailMapping, s.CreateDelay, s.NAACollision)
+	return nil
+}
+
+// Disconnect detaches from the simulated array
+func (s *SimulatorStorageProvider) Disconnect() error {
+	s.SetConnected(false)
+	return nil
+}
+
+// ValidateCredentials checks that the state of the array can be read
+func (s *SimulatorStorageProvider) ValidateCredentials(ctx context.Context) error {
+	if !s.GetConnected() {
+		return s.Connect(ctx, s.AccessInfo)
+	}
+	if err := s.view(func(*SimulatorState) error { return nil }); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
+
+// CreateVolume creates a volume, after OptionCreateDelay
+func (s *SimulatorStorageProvider) CreateVolume(volumeName string, size int64) (storage.Volume, error) {
+	if s.CreateDelay > 0 {
+		klog.Infof("Delaying creation of simulated volume %s by %s", volumeName, s.CreateDelay)
+		time.Sleep(s.CreateDelay)
+	}
+
+	var created SimulatorVolume
+	err := s.update(func(state *SimulatorState) error {
+		if _, ok := findVolume(state, volumeName); ok {
+			return fmt.Errorf("volume %s already exists", volumeName)
+		}
+		state.NextSerial++
+		serial := fmt.Sprintf("%025x", state.NextSerial)
+		created = SimulatorVolume{
+			Name:    volumeName,
+			ID:      fmt.Sprintf("sim-vol-%d", state.NextSerial),
+			Serial:  serial,
+			NAA:     s.BuildNAA(serial),
+			Size:    size,
+			Created: time.Now().UTC().Format(time.RFC3339),
+		}
+		if s.NAACollision && len(state.Volumes) > 0 {
+			klog.Warningf("Injecting NAA collision: volume %s gets the NAA %s of volume %s",
+				volumeName, state.Volumes[0].NAA, state.Volumes[0].Name)
+			created.Serial, created.NAA = state.Volumes[0].Serial, state.Volumes[0].NAA
+		}
+		state.Volumes = append(state.Volumes, created)
+		return nil
+	})
+	if err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to create volume %s: %w", volumeName, err)
+	}
+	klog.Infof("Created simulated volume: %s, NAA: %s", created.Name, created.NAA)
+	return toVolume(created), nil
+}
+
+// DeleteVolume deletes a volume; mapped volumes cannot be deleted
+func (s *SimulatorStorageProvider) DeleteVolume(volumeName string) error {
+	err := s.update(func(state *SimulatorState) error {
+		idx, ok := findVolume(state, volumeName)
+		if !ok {
+			return fmt.Errorf("volume %s not found", volumeName)
+		}
+		for _, m := range state.Mappings {
+			if m.Volume == volumeName {
+				return fmt.Errorf("volume %s is mapped to initiator group %s", volumeName, m.InitiatorGroup)
+			}
+		}
+		state.Volumes = append(state.Volumes[:idx], state.Volumes[idx+1:]...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete volume %s: %w", volumeName, err)
+	}
+	klog.Infof("Deleted simulated volume: %s", volumeName)
+	return nil
+}
+
+// GetVolumeInfo retrieves information about a volume
+func (s *SimulatorStorageProvider) GetVolumeInfo(volumeName string) (storage.VolumeInfo, error) {
+	var info storage.VolumeInfo
+	err := s.view(func(state *SimulatorState) error {
+		idx, ok := findVolume(state, volumeName)
+		if !ok {
+			return fmt.Errorf("volume %s not found", volumeName)
+		}
+		info = toVolumeInfo(state.Volumes[idx])
+		return nil
+	})
+	return info, err
+}
+
+// ListAllVolumes retrieves all volumes with their NAA identifiers
+func (s *SimulatorStorageProvider) ListAllVolumes() ([]storage.VolumeInfo, error) {
+	var volumeInfos []storage.VolumeInfo
+	err := s.view(func(state *SimulatorState) error {
+		for _, v := range state.Volumes {
+			volumeInfos = append(volumeInfos, toVolumeInfo(v))
+		}
+		return nil
+	})
+	return volumeInfos, err
+}
+
+// GetAllVolumeNAAs retrieves NAA identifiers for all volumes
+func (s *SimulatorStorageProvider) GetAllVolumeNAAs() ([]string, error) {
+	return s.BaseStorageProvider.GetAllVolumeNAAs(s.ListAllVolumes)
+}
+
+// CreateOrUpdateInitiatorGroup creates the initiator group, or adds the
+// missing adapters to it. Like on real arrays an adapter belongs to one
+// initiator group at most.
+func (s *SimulatorStorageProvider) CreateOrUpdateInitiatorGroup(initiatorGroupName string, hbaIdentifiers []string) (storage.MappingContext, error) {
+	err := s.update(func(state *SimulatorState) error {
+		for _, group := range state.InitiatorGroups {
+			if group.Name == initiatorGroupName {
+				continue
+			}
+			for _, id := range hbaIdentifiers {
+				if storage.ContainsIgnoreCase(group.Initiators, id) {
+					return fmt.Errorf("adapter %s belongs to initiator group %s", id, group.Name)
+				}
+			}
+		}
+
+		idx := -1
+		for i, group := range state.InitiatorGroups {
+			if group.Name == initiatorGroupName {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			klog.Infof("Creating simulated initiator group %s", initiatorGroupName)
+			state.InitiatorGroups = append(state.InitiatorGroups, SimulatorInitiatorGroup{Name: initiatorGroupName, Initiators: []string{}})
+			idx = len(state.InitiatorGroups) - 1
+		}
+		group := &state.InitiatorGroups[idx]
+		for _, id := range hbaIdentifiers {
+			if !storage.ContainsIgnoreCase(group.Initiators, id) {
+				group.Initiators = append(group.Initiators, id)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update initiator group %s: %w", initiatorGroupName, err)
+	}
+	return storage.MappingContext{"initiatorGroups": []string{initiatorGroupName}}, nil
+}
+
+// MapVolumeToGroup maps a volume to the initiator groups of the mapping
+// context at the next free LUN, or fails when OptionFailMapping is set
+func (s *SimulatorStorageProvider) MapVolumeToGroup(initiatorGroupName string, targetVolume storage.Volume, mappingCtx storage.MappingContext) (storage.Volume, error) {
+	groups, ok := mappingCtx["initiatorGroups"].([]string)
+	if !ok || len(groups) == 0 {
+		return storage.Volume{}, errors.New("invalid or empty initiatorGroups list in mapping context")
+	}
+	if s.FailMapping {
+		return storage.Volume{}, fmt.Errorf("failed to map volume %s: %w", targetVolume.Name, ErrInjectedFault)
+	}
+
+	err := s.update(func(state *SimulatorState) error {
+		if _, ok := findVolume(state, targetVolume.Name); !ok {
+			return fmt.Errorf("volume %s not found", targetVolume.Name)
+		}
+		for _, group := range groups {
+			if !hasInitiatorGroup(state, group) {
+				return fmt.Errorf("initiator group %s not found", group)
+			}
+		}
+		for _, group := range groups {
+			if _, mapped := findMapping(state, targetVolume.Name, group); mapped {
+				klog.Infof("Volume %s already mapped to initiator group %s", targetVolume.Name, group)
+				continue
+			}
+			lun := nextLUN(state, group)
+			klog.Infof("Mapping simulated volume %s to initiator group %s at LUN %d", targetVolume.Name, group, lun)
+			state.Mappings = append(state.Mappings, SimulatorMapping{Volume: targetVolume.Name, InitiatorGroup: group, LUN: lun})
+		}
+		return nil
+	})
+	if err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to map volume %s: %w", targetVolume.Name, err)
+	}
+	return targetVolume, nil
+}
+
+// UnmapVolumeFromGroup removes the mappings of a volume to the initiator groups of the mapping context
+func (s *SimulatorStorageProvider) UnmapVolumeFromGroup(initiatorGroupName string, targetVolume storage.Volume, mappingCtx storage.MappingContext) error {
+	groups, ok := mappingCtx["initiatorGroups"].([]string)
+	if !ok || len(groups) == 0 {
+		return nil
+	}
+
+	return s.update(func(state *SimulatorState) error {
+		for _, group := range groups {
+			if idx, mapped := findMapping(state, targetVolume.Name, group); mapped {
+				klog.Infof("Unmapping simulated volume %s from initiator group %s", targetVolume.Name, group)
+				state.Mappings = append(state.Mappings[:idx], state.Mappings[idx+1:]...)
+			}
+		}
+		return nil
+	})
+}
+
+// GetMappedGroups returns the initiator groups a volume is mapped to
+func (s *SimulatorStorageProvider) GetMappedGroups(targetVolume storage.Volume, mappingCtx storage.MappingContext) ([]string, error) {
+	var groups []string
+	err := s.view(func(state *SimulatorState) error {
+		if _, ok := findVolume(state, targetVolume.Name); !ok {
+			return fmt.Errorf("volume %s not found", targetVolume.Name)
+		}
+		for _, m := range state.Mappings {
+			if m.Volume == targetVolume.Name {
+				groups = append(groups, m.InitiatorGroup)
+			}
+		}
+		return nil
+	})
+	return groups, err
+}
+
+// ResolveCinderVolumeToLUN resolves a Cinder volume ID to the volume
+// ManageVolume handed to it
+func (s *SimulatorStorageProvider) ResolveCinderVolumeToLUN(volumeID string) (storage.Volume, error) {
+	var resolved SimulatorVolume
+	err := s.view(func(state *SimulatorState) error {
+		for _, v := range state.Volumes {
+			if v.CinderID == volumeID || v.Name == "volume-"+volumeID {
+				resolved = v
+				return nil
+			}
+		}
+		return fmt.Errorf("no volume found for Cinder volume %s", volumeID)
+	})
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	klog.Infof("Resolved cinder volume %s to volume: %+v", volumeID, resolved)
+	return toVolume(resolved), nil
+}
+
+// ManageVolume hands a volume over to a Cinder volume, renaming it
+// volume-<cinder-id> as Cinder drivers do on manage_existing
+func (s *SimulatorStorageProvider) ManageVolume(volumeName, cinderVolumeID string) error {
+	return s.update(func(state *SimulatorState) error {
+		idx, ok := findVolume(state, volumeName)
+		if !ok {
+			return fmt.Errorf("volume %s not found", volumeName)
+		}
+		newName := "volume-" + cinderVolumeID
+		for i := range state.Mappings {
+			if state.Mappings[i].Volume == volumeName {
+				state.Mappings[i].Volume = newName
+			}
+		}
+		state.Volumes[idx].Name = newName
+		state.Volumes[idx].CinderID = cinderVolumeID
+		return nil
+	})
+}
+
+// GetVolumeFromNAA retrieves a volume by its NAA identifier
+func (s *SimulatorStorageProvider) GetVolumeFromNAA(naaID string) (storage.Volume, error) {
+	var found SimulatorVolume
+	err := s.view(func(state *SimulatorState) error {
+		for _, v := range state.Volumes {
+			if strings.EqualFold(v.NAA, naaID) {
+				found = v
+				return nil
+			}
+		}
+		return fmt.Errorf("no simulated volume found with NAA %s", naaID)
+	})
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	return toVolume(found), nil
+}
+
+// CreateSnapshot takes a snapshot of a volume
+func (s *SimulatorStorageProvider) CreateSnapshot(volume storage.Volume, snapshotName string) (storage.VolumeSnapshot, error) {
+	var created SimulatorSnapshot
+	err := s.update(func(state *SimulatorState) error {
+		idx, ok := findVolume(state, volume.Name)
+		if !ok {
+			return fmt.Errorf("volume %s not found", volume.Name)
+		}
+		if _, ok := findSnapshot(state, snapshotName); ok {
+			return fmt.Errorf("snapshot %s already exists", snapshotName)
+		}
+		state.NextSerial++
+		created = SimulatorSnapshot{
+			Name:   snapshotName,
+			ID:     fmt.Sprintf("sim-snap-%d", state.NextSerial),
+			Volume: volume.Name,
+			Size:   state.Volumes[idx].Size,
+		}
+		state.Snapshots = append(state.Snapshots, created)
+		return nil
+	})
+	if err != nil {
+		return storage.VolumeSnapshot{}, fmt.Errorf("failed to create snapshot %s: %w", snapshotName, err)
+	}
+	klog.Infof("Created simulated snapshot %s of volume %s", created.Name, created.Volume)
+	return toVolumeSnapshot(created), nil
+}
+
+// CloneSnapshot creates a volume of the size of the snapshot
+func (s *SimulatorStorageProvider) CloneSnapshot(snapshot storage.VolumeSnapshot, volumeName string) (storage.Volume, error) {
+	var size int64
+	err := s.view(func(state *SimulatorState) error {
+		idx, ok := findSnapshot(state, snapshot.Name)
+		if !ok {
+			return fmt.Errorf("snapshot %s not found", snapshot.Name)
+		}
+		size = state.Snapshots[idx].Size
+		return nil
+	})
+	if err != nil {
+		return storage.Volume{}, fmt.Errorf("failed to clone snapshot %s: %w", snapshot.Name, err)
+	}
+	return s.CreateVolume(volumeName, size)
+}
+
+// DeleteSnapshot deletes a snapshot
+func (s *SimulatorStorageProvider) DeleteSnapshot(snapshot storage.VolumeSnapshot) error {
+	err := s.update(func(state *SimulatorState) error {
+		idx, ok := findSnapshot(state, snapshot.Name)
+		if !ok {
+			return fmt.Errorf("snapshot %s not found", snapshot.Name)
+		}
+		state.Snapshots = append(state.Snapshots[:idx], state.Snapshots[idx+1:]...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot %s: %w", snapshot.Name, err)
+	}
+	klog.Infof("Deleted simulated snapshot: %s", snapshot.Name)
+	return nil
+}
+
+// WhoAmI returns the provider name
+func (s *SimulatorStorageProvider) WhoAmI() string {
+	return VendorName
+}
+
+// Helper methods
+
+// view runs fn on the state of the array
+func (s *SimulatorStorageProvider) view(fn func(*SimulatorState) error) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	unlock, err := s.lockStateFile(syscall.LOCK_SH)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+	return fn(state)
+}
+
+// update runs fn on the state of the array and saves the state when fn
+// succeeds. fn must not change the state when it fails.
+func (s *SimulatorStorageProvider) update(fn func(*SimulatorState) error) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	unlock, err := s.lockStateFile(syscall.LOCK_EX)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	return s.save(state)
+}
+
+// lockStateFile takes the lock of the state file, a flock on a .lock file
+// beside it, which serialises the processes sharing the file as stateMu does
+// the goroutines of one process. It does nothing for an in-memory array.
+func (s *SimulatorStorageProvider) lockStateFile(how int) (func(), error) {
+	if s.StateFile == "" {
+		return func() {}, nil
+	}
+	f, err := os.OpenFile(s.StateFile+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state lock file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), how); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock state file: %w", err)
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// load returns the state of the array, from the state file when one is set
+func (s *SimulatorStorageProvider) load() (*SimulatorState, error) {
+	if s.StateFile == "" {
+		state, ok := arrays[s.AccessInfo.Hostname]
+		if !ok {
+			state = &SimulatorState{}
+			arrays[s.AccessInfo.Hostname] = state
+		}
+		return state, nil
+	}
+
+	data, err := os.ReadFile(s.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return &SimulatorState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	var state SimulatorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", s.StateFile, err)
+	}
+	return &state, nil
+}
+
+// save writes the state to the state file, when one is set. The in-memory
+// state was changed in place.
+func (s *SimulatorStorageProvider) save(state *SimulatorState) error {
+	if s.StateFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	// Write then rename so that other processes never read a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(s.StateFile), filepath.Base(s.StateFile)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.StateFile); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+func parseBoolOption(options map[string]string, key string) (bool, error) {
+	value := strings.TrimSpace(options[key])
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s option %q: %w", key, value, err)
+	}
+	return b, nil
+}
+
+func findVolume(state *SimulatorState, name string) (int, bool) {
+	for i, v := range state.Volumes {
+		if v.Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func findSnapshot(state *SimulatorState, name string) (int, bool) {
+	for i, snap := range state.Snapshots {
+		if snap.Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func hasInitiatorGroup(state *SimulatorState, name string) bool {
+	for _, group := range state.InitiatorGroups {
+		if group.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func findMapping(state *SimulatorState, volumeName, group string) (int, bool) {
+	for i, m := range state.Mappings {
+		if m.Volume == volumeName && m.InitiatorGroup == group {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// nextLUN returns the lowest LUN, from 1, not used by the initiator group
+func nextLUN(state *SimulatorState, group string) int {
+	used := map[int]bool{}
+	for _, m := range state.Mappings {
+		if m.InitiatorGroup == group {
+			used[m.LUN] = true
+		}
+	}
+	lun := 1
+	for used[lun] {
+		lun++
+	}
+	return lun
+}
+
+func toVolume(v SimulatorVolume) storage.Volume {
+	return storage.Volume{
+		Name:         v.Name,
+		Size:         v.Size,
+		Id:           v.ID,
+		SerialNumber: v.Serial,
+		NAA:          v.NAA,
+	}
+}
+
+func toVolumeInfo(v SimulatorVolume) storage.VolumeInfo {
+	return storage.VolumeInfo{
+		Name:    v.Name,
+		Size:    v.Size,
+		Created: v.Created,
+		NAA:     v.NAA,
+	}
+}
+
+func toVolumeSnapshot(snap SimulatorSnapshot) storage.VolumeSnapshot {
+	return storage.VolumeSnapshot{
+		Name:         snap.Name,
+		Id:           snap.ID,
+		SourceVolume: snap.Volume,
+		Size:         snap.Size,
+	}
+}
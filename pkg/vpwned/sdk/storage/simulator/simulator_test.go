@@ -0,0 +1,341 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
+)
+
+const gib = 1024 * 1024 * 1024
+
+// connectSim connects to an in-memory array of its own for the test
+func connectSim(t *testing.T, options map[string]string) *SimulatorStorageProvider {
+	t.Helper()
+	s := &SimulatorStorageProvider{}
+	info := storage.StorageAccessInfo{Hostname: t.Name(), Username: "admin", VendorType: VendorName, ProviderOptions: options}
+	if err := s.Connect(context.Background(), info); err != nil {
+		t.Fatalf("Connect() unexpected error: %v", err)
+	}
+	return s
+}
+
+func TestRegistered(t *testing.T) {
+	provider, err := storage.NewStorageProvider("Simulator")
+	if err != nil {
+		t.Fatalf("NewStorageProvider() unexpected error: %v", err)
+	}
+	if provider.WhoAmI() != VendorName {
+		t.Errorf("WhoAmI() = %q, want %q", provider.WhoAmI(), VendorName)
+	}
+	if _, ok := provider.(storage.ArrayCloner); !ok {
+		t.Error("provider does not implement ArrayCloner")
+	}
+}
+
+func TestConnectOptions(t *testing.T) {
+	for _, options := range []map[string]string{
+		{OptionFailMapping: "sometimes"},
+		{OptionNAACollision: "yes please"},
+		{OptionCreateDelay: "30"},
+	} {
+		info := storage.StorageAccessInfo{Hostname: t.Name(), ProviderOptions: options}
+		if err := (&SimulatorStorageProvider{}).Connect(context.Background(), info); err == nil {
+			t.Errorf("Connect() with options %v succeeded", options)
+		}
+	}
+	if err := (&SimulatorStorageProvider{}).Connect(context.Background(), storage.StorageAccessInfo{}); err == nil {
+		t.Error("Connect() without a hostname succeeded")
+	}
+
+	s := connectSim(t, map[string]string{OptionFailMapping: "true", OptionCreateDelay: "1m", OptionNAACollision: "1"})
+	if !s.FailMapping || s.CreateDelay != time.Minute || !s.NAACollision {
+		t.Errorf("provider = %+v, want the options applied", s)
+	}
+	if err := s.ValidateCredentials(context.Background()); err != nil {
+		t.Errorf("ValidateCredentials() unexpected error: %v", err)
+	}
+}
+
+func TestVolumes(t *testing.T) {
+	s := connectSim(t, nil)
+
+	created, err := s.CreateVolume("vm1-disk1", 10*gib)
+	if err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+	if created.Size != 10*gib || !strings.HasPrefix(created.NAA, "naa."+SimulatorProviderID) || len(created.NAA) != len("naa.")+32 {
+		t.Errorf("volume = %+v, want 10 GiB with a simulator NAA", created)
+	}
+	other, err := s.CreateVolume("vm1-disk2", gib)
+	if err != nil || other.NAA == created.NAA {
+		t.Errorf("CreateVolume() = %+v, %v, want a volume with its own NAA", other, err)
+	}
+	if _, err := s.CreateVolume("vm1-disk1", gib); err == nil {
+		t.Error("CreateVolume() of a duplicate succeeded")
+	}
+
+	info, err := s.GetVolumeInfo("vm1-disk1")
+	if err != nil || info.NAA != created.NAA {
+		t.Errorf("GetVolumeInfo() = %+v, %v, want the created volume", info, err)
+	}
+	byNAA, err := s.GetVolumeFromNAA(strings.ToUpper(created.NAA))
+	if err != nil || byNAA.Name != "vm1-disk1" {
+		t.Errorf("GetVolumeFromNAA() = %+v, %v, want vm1-disk1", byNAA, err)
+	}
+	naas, err := s.GetAllVolumeNAAs()
+	if err != nil || !reflect.DeepEqual(naas, []string{created.NAA, other.NAA}) {
+		t.Errorf("GetAllVolumeNAAs() = %v, %v", naas, err)
+	}
+
+	if err := s.DeleteVolume("vm1-disk1"); err != nil {
+		t.Fatalf("DeleteVolume() unexpected error: %v", err)
+	}
+	if _, err := s.GetVolumeInfo("vm1-disk1"); err == nil {
+		t.Error("volume still found after DeleteVolume()")
+	}
+	if err := s.DeleteVolume("vm1-disk1"); err == nil {
+		t.Error("DeleteVolume() of a missing volume succeeded")
+	}
+}
+
+func TestSharedInMemoryArray(t *testing.T) {
+	first := connectSim(t, nil)
+	if _, err := first.CreateVolume("shared", gib); err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+	second := connectSim(t, nil)
+	if _, err := second.GetVolumeInfo("shared"); err != nil {
+		t.Errorf("volume not visible to a second provider of the array: %v", err)
+	}
+
+	other := &SimulatorStorageProvider{}
+	if err := other.Connect(context.Background(), storage.StorageAccessInfo{Hostname: t.Name() + "-other"}); err != nil {
+		t.Fatalf("Connect() unexpected error: %v", err)
+	}
+	if _, err := other.GetVolumeInfo("shared"); err == nil {
+		t.Error("volume visible on another array")
+	}
+}
+
+func TestStateFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "array.json")
+	options := map[string]string{OptionStateFile: stateFile}
+
+	first := connectSim(t, options)
+	created, err := first.CreateVolume("persisted", gib)
+	if err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(stateFile); err != nil {
+		t.Fatalf("state file not written: %v", err)
+	}
+	if _, err := os.Stat(stateFile + ".lock"); err != nil {
+		t.Errorf("state lock file not created: %v", err)
+	}
+
+	// A provider of another process sees the state through the file only
+	delete(arrays, t.Name())
+	second := connectSim(t, options)
+	info, err := second.GetVolumeInfo("persisted")
+	if err != nil || info.NAA != created.NAA {
+		t.Errorf("GetVolumeInfo() from the state file = %+v, %v, want the created volume", info, err)
+	}
+	if _, err := connectSim(t, nil).GetVolumeInfo("persisted"); err == nil {
+		t.Error("volume visible on the in-memory array")
+	}
+
+	if err := os.WriteFile(stateFile, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	info2 := storage.StorageAccessInfo{Hostname: t.Name(), ProviderOptions: options}
+	if err := (&SimulatorStorageProvider{}).Connect(context.Background(), info2); err == nil {
+		t.Error("Connect() with a corrupt state file succeeded")
+	}
+}
+
+func TestInitiatorGroupsAndMappings(t *testing.T) {
+	s := connectSim(t, nil)
+	iqn := "iqn.1998-01.com.vmware:esxi01-4f1c"
+	volume, err := s.CreateVolume("vm1-disk1", gib)
+	if err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+
+	var mappingCtx storage.MappingContext
+	for i := 0; i < 2; i++ {
+		if mappingCtx, err = s.CreateOrUpdateInitiatorGroup("vjailbreak-xcopy", []string{iqn}); err != nil {
+			t.Fatalf("CreateOrUpdateInitiatorGroup() unexpected error: %v", err)
+		}
+	}
+	if !reflect.DeepEqual(mappingCtx["initiatorGroups"], []string{"vjailbreak-xcopy"}) {
+		t.Errorf("initiatorGroups = %v, want vjailbreak-xcopy", mappingCtx["initiatorGroups"])
+	}
+	if state := arrays[t.Name()]; !reflect.DeepEqual(state.InitiatorGroups[0].Initiators, []string{iqn}) {
+		t.Errorf("initiators = %v, want %s once", state.InitiatorGroups[0].Initiators, iqn)
+	}
+	if _, err := s.CreateOrUpdateInitiatorGroup("other-group", []string{strings.ToUpper(iqn)}); err == nil {
+		t.Error("CreateOrUpdateInitiatorGroup() with an adapter of another group succeeded")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.MapVolumeToGroup("vjailbreak-xcopy", volume, mappingCtx); err != nil {
+			t.Fatalf("MapVolumeToGroup() unexpected error: %v", err)
+		}
+	}
+	groups, err := s.GetMappedGroups(volume, mappingCtx)
+	if err != nil || !reflect.DeepEqual(groups, []string{"vjailbreak-xcopy"}) {
+		t.Errorf("GetMappedGroups() = %v, %v, want vjailbreak-xcopy", groups, err)
+	}
+	if err := s.DeleteVolume(volume.Name); err == nil {
+		t.Error("DeleteVolume() of a mapped volume succeeded")
+	}
+	if _, err := s.MapVolumeToGroup("vjailbreak-xcopy", volume, storage.MappingContext{"initiatorGroups": []string{"missing"}}); err == nil {
+		t.Error("MapVolumeToGroup() to a missing initiator group succeeded")
+	}
+
+	// A missing group fails the mapping to every group, not only to itself
+	other, _ := s.CreateVolume("vm1-disk2", gib)
+	partial := storage.MappingContext{"initiatorGroups": []string{"vjailbreak-xcopy", "missing"}}
+	if _, err := s.MapVolumeToGroup("vjailbreak-xcopy", other, partial); err == nil {
+		t.Error("MapVolumeToGroup() with a missing initiator group succeeded")
+	}
+	if groups, _ := s.GetMappedGroups(other, mappingCtx); len(groups) != 0 {
+		t.Errorf("GetMappedGroups() = %v after a failed mapping, want none", groups)
+	}
+
+	if err := s.UnmapVolumeFromGroup("vjailbreak-xcopy", volume, mappingCtx); err != nil {
+		t.Fatalf("UnmapVolumeFromGroup() unexpected error: %v", err)
+	}
+	if groups, _ := s.GetMappedGroups(volume, mappingCtx); len(groups) != 0 {
+		t.Errorf("GetMappedGroups() = %v after unmapping, want none", groups)
+	}
+	if err := s.DeleteVolume(volume.Name); err != nil {
+		t.Errorf("DeleteVolume() after unmapping: %v", err)
+	}
+}
+
+func TestNextLUN(t *testing.T) {
+	state := &SimulatorState{Mappings: []SimulatorMapping{
+		{Volume: "a", InitiatorGroup: "g", LUN: 1},
+		{Volume: "b", InitiatorGroup: "g", LUN: 3},
+		{Volume: "c", InitiatorGroup: "h", LUN: 2},
+	}}
+	if lun := nextLUN(state, "g"); lun != 2 {
+		t.Errorf("nextLUN() = %d, want 2", lun)
+	}
+	if lun := nextLUN(state, "h"); lun != 1 {
+		t.Errorf("nextLUN() = %d, want 1", lun)
+	}
+}
+
+func TestManageAndResolveCinderVolume(t *testing.T) {
+	s := connectSim(t, nil)
+	volume, err := s.CreateVolume("vjb-vm1-disk1", gib)
+	if err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+	cinderID := "5a8e0c2b-0d6f-4c43-a1b0-7f0e4f5d2a11"
+	if _, err := s.ResolveCinderVolumeToLUN(cinderID); err == nil {
+		t.Error("ResolveCinderVolumeToLUN() of an unmanaged volume succeeded")
+	}
+
+	// reached through the optional interface, as the StorageAcceleratedCopy flow does
+	recorder, ok := storage.StorageProvider(s).(storage.CinderManageRecorder)
+	if !ok {
+		t.Fatal("simulator provider is not a storage.CinderManageRecorder")
+	}
+	if err := recorder.ManageVolume(volume.Name, cinderID); err != nil {
+		t.Fatalf("ManageVolume() unexpected error: %v", err)
+	}
+	resolved, err := s.ResolveCinderVolumeToLUN(cinderID)
+	if err != nil || resolved.Name != "volume-"+cinderID || resolved.NAA != volume.NAA {
+		t.Errorf("ResolveCinderVolumeToLUN() = %+v, %v, want the renamed volume", resolved, err)
+	}
+}
+
+func TestSnapshotClone(t *testing.T) {
+	s := connectSim(t, nil)
+	volume, err := s.CreateVolume("datastore1-lun", 4*gib)
+	if err != nil {
+		t.Fatalf("CreateVolume() unexpected error: %v", err)
+	}
+	if _, err := s.CreateSnapshot(storage.Volume{Name: "missing"}, "snap1"); err == nil {
+		t.Error("CreateSnapshot() of a missing volume succeeded")
+	}
+
+	snapshot, err := s.CreateSnapshot(volume, "snap1")
+	if err != nil {
+		t.Fatalf("CreateSnapshot() unexpected error: %v", err)
+	}
+	if snapshot.SourceVolume != volume.Name || snapshot.Size != 4*gib {
+		t.Errorf("CreateSnapshot() = %+v, want a snapshot of %s", snapshot, volume.Name)
+	}
+	if _, err := s.CreateSnapshot(volume, "snap1"); err == nil {
+		t.Error("CreateSnapshot() with a duplicate name succeeded")
+	}
+
+	clone, err := s.CloneSnapshot(snapshot, "datastore1-clone")
+	if err != nil {
+		t.Fatalf("CloneSnapshot() unexpected error: %v", err)
+	}
+	if clone.Size != 4*gib || clone.NAA == volume.NAA {
+		t.Errorf("CloneSnapshot() = %+v, want a new volume of %d bytes", clone, 4*gib)
+	}
+
+	if err := s.DeleteSnapshot(snapshot); err != nil {
+		t.Fatalf("DeleteSnapshot() unexpected error: %v", err)
+	}
+	if _, err := s.CloneSnapshot(snapshot, "datastore1-clone2"); err == nil {
+		t.Error("CloneSnapshot() of a deleted snapshot succeeded")
+	}
+	if err := s.DeleteSnapshot(snapshot); err == nil {
+		t.Error("DeleteSnapshot() of a deleted snapshot succeeded")
+	}
+}
+
+func TestInjectedFaults(t *testing.T) {
+	t.Run("mapping failure", func(t *testing.T) {
+		s := connectSim(t, map[string]string{OptionFailMapping: "true"})
+		volume, _ := s.CreateVolume("vm1-disk1", gib)
+		mappingCtx, err := s.CreateOrUpdateInitiatorGroup("vjailbreak-xcopy", []string{"iqn.1998-01.com.vmware:esxi01"})
+		if err != nil {
+			t.Fatalf("CreateOrUpdateInitiatorGroup() unexpected error: %v", err)
+		}
+		if _, err := s.MapVolumeToGroup("vjailbreak-xcopy", volume, mappingCtx); !errors.Is(err, ErrInjectedFault) {
+			t.Errorf("MapVolumeToGroup() = %v, want the injected fault", err)
+		}
+		if len(arrays[t.Name()].Mappings) != 0 {
+			t.Errorf("mappings = %+v, want none", arrays[t.Name()].Mappings)
+		}
+	})
+
+	t.Run("slow creation", func(t *testing.T) {
+		s := connectSim(t, map[string]string{OptionCreateDelay: "50ms"})
+		start := time.Now()
+		if _, err := s.CreateVolume("slow", gib); err != nil {
+			t.Fatalf("CreateVolume() unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("CreateVolume() took %s, want at least 50ms", elapsed)
+		}
+	})
+
+	t.Run("NAA collision", func(t *testing.T) {
+		s := connectSim(t, map[string]string{OptionNAACollision: "true"})
+		first, _ := s.CreateVolume("first", gib)
+		second, err := s.CreateVolume("second", gib)
+		if err != nil {
+			t.Fatalf("CreateVolume() unexpected error: %v", err)
+		}
+		if second.NAA != first.NAA {
+			t.Errorf("NAA = %s, want the colliding %s", second.NAA, first.NAA)
+		}
+	})
+}
@@ -109,6 +109,45 @@ type BackendTargetDiscoverer interface {
 	DiscoverBackendTargets(ctx context.Context) ([]BackendTargetGroup, error)
 }
 
+// VolumeSnapshot is a point-in-time snapshot of a volume on the storage array
+type VolumeSnapshot struct {
+	Name string
+	Id   string
+	// SourceVolume is the name of the volume the snapshot was taken of
+	SourceVolume string
+	Size         int64
+}
+
+// ArrayCloner is implemented by providers that can snapshot a volume and
+// clone the snapshot into a new volume within the array, without any host
+// moving the data. It backs the array-native copy of the LUN of a VMFS
+// datastore. Callers type-assert the provider to this interface; providers
+// without snapshot clones simply omit it.
+type ArrayCloner interface {
+	// GetVolumeFromNAA retrieves the volume with the given NAA identifier
+	GetVolumeFromNAA(naaID string) (Volume, error)
+
+	// CreateSnapshot takes a snapshot of the volume
+	CreateSnapshot(volume Volume, snapshotName string) (VolumeSnapshot, error)
+
+	// CloneSnapshot creates a new volume holding the data of the snapshot
+	CloneSnapshot(snapshot VolumeSnapshot, volumeName string) (Volume, error)
+
+	// DeleteSnapshot deletes a snapshot taken with CreateSnapshot
+	DeleteSnapshot(snapshot VolumeSnapshot) error
+}
+
+// CinderManageRecorder is implemented by providers of arrays that no Cinder
+// driver stands behind, such as the simulator, so nothing renames a volume
+// when Cinder manages it. Callers type-assert the provider to this interface
+// after a manage and hand it the new Cinder volume; arrays with a driver
+// simply omit it.
+type CinderManageRecorder interface {
+	// ManageVolume renames the volume as the Cinder driver would have on
+	// manage_existing, for ResolveCinderVolumeToLUN to find it by cinderVolumeID
+	ManageVolume(volumeName, cinderVolumeID string) error
+}
+
 // ArrayInfo holds basic storage array information
 type ArrayInfo struct {
 	Name         string
@@ -1,7 +1,6 @@
 package server
 
 import (
-	"archive/tar"
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
@@ -9,7 +8,6 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
-	"io"
 	"net"
 	"net/url"
 	"os"
@@ -19,6 +17,7 @@ import (
 	"time"
 
 	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/pkg/common/ova"
 	"github.com/sirupsen/logrus"
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
@@ -214,7 +213,7 @@ func deployProxyVMFromOVA(ovaPath string, deployCfg ProxyVMDeployConfig) {
 		return
 	}
 
-	ovfEntry, err := findDescriptorEntry(ovaPath)
+	ovfEntry, err := ova.FindDescriptorEntry(ovaPath)
 	if err != nil {
 		logrus.Errorf("ova-deploy[%s]: find descriptor: %v", deployCfg.VMName, err)
 		setFailed(fmt.Sprintf("Failed to read OVA descriptor: %v", err))
@@ -636,35 +635,3 @@ func connectVCenterNoDC(ctx context.Context, host, username, password string) (*
 	finder := find.NewFinder(client.Client, true)
 	return client, finder, nil
 }
-
-// findDescriptorEntry scans an OVA (tar) for the first .ovf entry.
-func findDescriptorEntry(ovaPath string) (string, error) {
-	f, err := os.Open(filepath.Clean(ovaPath))
-	if err != nil {
-		return "", err
-	}
-	defer f.Close()
-
-	r := tar.NewReader(f)
-	first := ""
-	for {
-		h, err := r.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return "", fmt.Errorf("reading archive: %v", err)
-		}
-		name := filepath.Base(h.Name)
-		if first == "" {
-			first = name
-		}
-		if strings.HasSuffix(strings.ToLower(name), ".ovf") {
-			return name, nil
-		}
-	}
-	if first == "" {
-		return "", fmt.Errorf("archive %q is empty", ovaPath)
-	}
-	return first, nil
-}
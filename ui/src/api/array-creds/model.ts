@@ -50,6 +50,21 @@ export interface NetAppConfig {
   flexVol?: string
 }
 
+export interface PowerStoreConfig {
+  appliance?: string
+}
+
+export interface HPEAlletraConfig {
+  cpg?: string
+}
+
+export interface SimulatorConfig {
+  stateFile?: string
+  failMapping?: boolean
+  createDelay?: string
+  naaCollision?: boolean
+}
+
 export interface ArrayCredsSpec {
   vendorType: string
   secretRef?: {
@@ -58,6 +73,10 @@ export interface ArrayCredsSpec {
   openstackMapping?: OpenstackMapping
   autoDiscovered?: boolean
   netAppConfig?: NetAppConfig
+  powerStoreConfig?: PowerStoreConfig
+  hpeAlletraConfig?: HPEAlletraConfig
+  simulatorConfig?: SimulatorConfig
+  arrayNativeCopy?: boolean
 }
 
 export interface BackendTarget {
@@ -90,6 +109,8 @@ export const ARRAY_CREDS_PHASE_NEEDS_BACKEND_SELECTION = 'NeedsBackendSelection'
 export const ARRAY_VENDOR_TYPES = [
   { value: 'pure', label: 'Pure Storage' },
   { value: 'netapp', label: 'NetApp Storage' },
+  { value: 'powerstore', label: 'Dell PowerStore' },
+  { value: 'hpalletra', label: 'HPE Alletra/Primera' },
   { value: 'unsupported', label: 'N/A' }
 ] as const
 
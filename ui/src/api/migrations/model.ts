@@ -92,6 +92,29 @@ export interface StatusClass {
   totalDisks?: number
   retryable?: boolean
   syncWarningMessage?: string
+  queuedReason?: string
+  cutoverReadiness?: CutoverReadiness
+  guestStorage?: GuestStorageFinding[]
+}
+
+export interface CutoverReadiness {
+  ready: boolean
+  predictedDowntime: string
+  downtimeTarget: string
+  deltaBytes: number
+  dirtyBytesPerSecond: number
+  copyBytesPerSecond: number
+  syncs: number
+  nextSyncInterval?: string
+  updatedAt: string
+}
+
+export interface GuestStorageFinding {
+  device: string
+  kind: 'BitLocker' | 'DynamicDisk' | 'StorageSpaces' | 'UnsupportedFilesystem'
+  severity: 'Blocking' | 'Warning' | 'Remediated'
+  message: string
+  remediation?: string
 }
 
 export interface Condition {
@@ -128,6 +151,7 @@ export enum Type {
 
 export enum Phase {
   Pending = 'Pending',
+  Queued = 'Queued',
   Validating = 'Validating',
   ValidationFailed = 'ValidationFailed',
   AwaitingDataCopyStart = 'AwaitingDataCopyStart',
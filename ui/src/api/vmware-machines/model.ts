@@ -19,16 +19,29 @@ export interface VMwareVM {
   ipAddress?: string
   assignedIp?: string
   osFamily?: string
+  guestOS?: string
   networkInterfaces?: VmNetworkInterface[]
   rdmDisks?: string[]
   clusterName?: string
   useGPU?: boolean
+  hardware?: VMwareHardware
   // vSphere tag category name -> comma-separated tag names (e.g. "env" -> "production")
   tags?: Record<string, string>
   // vSphere custom attribute name -> value (e.g. "Owner" -> "alice@corp.com")
   customAttributes?: Record<string, string>
 }
 
+export interface VMwareHardware {
+  firmware?: string
+  secureBoot?: boolean
+  version?: number
+  cbtEnabled?: boolean
+  snapshotDepth?: number
+  usbDevices?: number
+  serialPorts?: number
+  independentDisks?: string[]
+}
+
 export interface VmNetworkInterface {
   mac: string
   network: string
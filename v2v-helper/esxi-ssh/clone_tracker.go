@@ -86,6 +86,7 @@ type CloneTracker struct {
 	processGoneSince  time.Time
 	logger            ProgressLogger
 	diskIndex         int
+	onProgress        func(percentDone float64)
 }
 
 // NewCloneTracker creates a new clone operation tracker.
@@ -107,6 +108,11 @@ func (ct *CloneTracker) SetPollInterval(interval time.Duration) {
 	ct.pollInterval = interval
 }
 
+// OnProgress sets a function called with the progress seen on every poll
+func (ct *CloneTracker) OnProgress(fn func(percentDone float64)) {
+	ct.onProgress = fn
+}
+
 // GetStatus returns the current status of the clone operation
 func (ct *CloneTracker) GetStatus() *CloneStatus {
 	// Order matters: check liveness before reading the log. The sentinel is
@@ -119,6 +125,9 @@ func (ct *CloneTracker) GetStatus() *CloneStatus {
 
 	status := ct.classify(logContent, processVisible, time.Now())
 	ct.logProgressIfNeeded(status.PercentDone)
+	if ct.onProgress != nil {
+		ct.onProgress(status.PercentDone)
+	}
 
 	return status
 }
@@ -0,0 +1,163 @@
+// Copyright © 2025 The vjailbreak authors
+
+package esxissh
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// streamBlockSize is the dd block size used when reading a flat extent
+const streamBlockSize = "1M"
+
+// parseFlatExtent returns the file name of the single flat extent described by
+// a VMDK descriptor. Snapshot deltas and sparse extents are rejected: a cold
+// copy reads the extent byte for byte, which is only correct for a flat disk
+// with no parent.
+func parseFlatExtent(descriptor string) (string, error) {
+	var extent string
+	scanner := bufio.NewScanner(strings.NewReader(descriptor))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			key = strings.TrimSpace(key)
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			if key == "parentFileNameHint" {
+				return "", fmt.Errorf("disk is a snapshot delta of %s, consolidate snapshots before migrating", value)
+			}
+			continue
+		}
+		// Extent lines look like: RW 16777216 VMFS "disk-flat.vmdk"
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		switch fields[0] {
+		case "RW", "RDONLY", "NOACCESS":
+		default:
+			continue
+		}
+		if fields[2] != "VMFS" && fields[2] != "FLAT" {
+			return "", fmt.Errorf("unsupported extent type %s, only flat disks can be copied", fields[2])
+		}
+		if extent != "" {
+			return "", fmt.Errorf("disk has more than one extent, only single extent disks can be copied")
+		}
+		start := strings.Index(line, `"`)
+		end := strings.LastIndex(line, `"`)
+		if start == -1 || end <= start {
+			return "", fmt.Errorf("malformed extent line: %s", line)
+		}
+		extent = line[start+1 : end]
+	}
+	if extent == "" {
+		return "", fmt.Errorf("no flat extent found in descriptor")
+	}
+	return extent, nil
+}
+
+// GetFlatExtentPath returns the filesystem path of the flat extent backing a
+// VMDK. diskPath may be a datastore path ([ds] vm/vm.vmdk) or a filesystem path.
+func (c *Client) GetFlatExtentPath(diskPath string) (string, error) {
+	if c.sshClient == nil {
+		return "", fmt.Errorf("not connected to ESXi host")
+	}
+	descriptorPath := convertDatastorePathToFilesystemPath(diskPath)
+	descriptor, err := c.ExecuteCommand(fmt.Sprintf("cat %s", shellQuote(descriptorPath)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read VMDK descriptor %s: %w", descriptorPath, err)
+	}
+	extent, err := parseFlatExtent(descriptor)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", descriptorPath, err)
+	}
+	if strings.HasPrefix(extent, "/") {
+		return extent, nil
+	}
+	return path.Join(path.Dir(descriptorPath), extent), nil
+}
+
+// StreamFile writes the content of a file on the ESXi host to w and returns
+// the number of bytes written. The command timeout does not apply, the copy
+// runs until it completes or ctx is cancelled.
+func (c *Client) StreamFile(ctx context.Context, filePath string, w io.Writer) (int64, error) {
+	if c.sshClient == nil {
+		return 0, fmt.Errorf("not connected to ESXi host")
+	}
+
+	session, err := c.sshClient.NewSession()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create SSH session: %w", err)
+	}
+	defer session.Close()
+
+	counter := &countingWriter{w: w}
+	var stderr strings.Builder
+	session.Stdout = counter
+	session.Stderr = &stderr
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(fmt.Sprintf("dd if=%s bs=%s", shellQuote(filePath), streamBlockSize))
+	}()
+
+	select {
+	case <-ctx.Done():
+		_ = session.Signal(ssh.SIGKILL)
+		_ = session.Close()
+		// Closing the session ends Run once its copy into w is over, w is
+		// the caller's again only after that
+		<-done
+		return counter.n, fmt.Errorf("copy of %s cancelled: %w", filePath, ctx.Err())
+	case err := <-done:
+		if err != nil {
+			return counter.n, fmt.Errorf("failed to read %s: %w: %s", filePath, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return counter.n, nil
+}
+
+// WaitForVMPowerState polls the VM until it reaches state ("on", "off" or
+// "suspended") or the timeout expires
+func (c *Client) WaitForVMPowerState(ctx context.Context, vmID, state string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		current, err := c.GetVMPowerState(vmID)
+		if err != nil {
+			return err
+		}
+		if current == state {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for VM %s to be powered %s, last state %s", vmID, state, current)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// countingWriter counts the bytes passed through to w
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,91 @@
+// Copyright © 2025 The vjailbreak authors
+
+package esxissh
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFlatExtent(t *testing.T) {
+	tests := []struct {
+		name       string
+		descriptor string
+		want       string
+		wantErr    string
+	}{
+		{
+			name: "thin provisioned vmfs disk",
+			descriptor: `# Disk DescriptorFile
+version=1
+encoding="UTF-8"
+CID=fffffffe
+parentCID=ffffffff
+createType="vmfs"
+
+# Extent description
+RW 16777216 VMFS "web01-flat.vmdk"
+
+# The Disk Data Base
+#DDB
+ddb.thinProvisioned = "1"
+`,
+			want: "web01-flat.vmdk",
+		},
+		{
+			name: "extent name with spaces",
+			descriptor: `createType="vmfs"
+RW 8388608 VMFS "my vm_1-flat.vmdk"
+`,
+			want: "my vm_1-flat.vmdk",
+		},
+		{
+			name: "snapshot delta is rejected",
+			descriptor: `CID=1a2b3c4d
+parentCID=fffffffe
+createType="seSparse"
+parentFileNameHint="web01.vmdk"
+RW 16777216 SESPARSE "web01-000001-sesparse.vmdk"
+`,
+			wantErr: "snapshot delta of web01.vmdk",
+		},
+		{
+			name: "sparse extent is rejected",
+			descriptor: `createType="monolithicSparse"
+RW 16777216 SPARSE "web01.vmdk"
+`,
+			wantErr: "unsupported extent type SPARSE",
+		},
+		{
+			name: "multiple extents are rejected",
+			descriptor: `createType="twoGbMaxExtentFlat"
+RW 4192256 FLAT "web01-f001.vmdk" 0
+RW 4192256 FLAT "web01-f002.vmdk" 0
+`,
+			wantErr: "more than one extent",
+		},
+		{
+			name:       "descriptor without extents",
+			descriptor: `createType="vmfs"`,
+			wantErr:    "no flat extent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFlatExtent(tt.descriptor)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseFlatExtent() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFlatExtent() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseFlatExtent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
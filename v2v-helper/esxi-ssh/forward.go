@@ -0,0 +1,110 @@
+// Copyright © 2025 The vjailbreak authors
+
+package esxissh
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// Forward is a listener on the loopback interface of this pod whose
+// connections are carried over the SSH connection to a unix socket on the
+// remote host, so a service there needs no port open on the host's network.
+type Forward struct {
+	listener net.Listener
+	dial     func() (net.Conn, error)
+
+	mu     sync.Mutex
+	open   map[net.Conn]struct{}
+	closed bool
+	served sync.WaitGroup
+}
+
+// ForwardUnixSocket forwards a new loopback port to socketPath on the remote
+// host until Close is called
+func (c *Client) ForwardUnixSocket(socketPath string) (*Forward, error) {
+	if c.sshClient == nil {
+		return nil, fmt.Errorf("not connected to %s", c.hostname)
+	}
+	sshClient := c.sshClient
+	return newForward(func() (net.Conn, error) {
+		return sshClient.Dial("unix", socketPath)
+	})
+}
+
+func newForward(dial func() (net.Conn, error)) (*Forward, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on loopback: %w", err)
+	}
+	f := &Forward{listener: listener, dial: dial, open: map[net.Conn]struct{}{}}
+	f.served.Add(1)
+	go f.serve()
+	return f, nil
+}
+
+// Port is the loopback port that reaches the remote socket
+func (f *Forward) Port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Close stops accepting connections, closes the open ones and waits for them
+// to end
+func (f *Forward) Close() error {
+	err := f.listener.Close()
+	f.mu.Lock()
+	f.closed = true
+	for conn := range f.open {
+		_ = conn.Close()
+	}
+	f.mu.Unlock()
+	f.served.Wait()
+	return err
+}
+
+func (f *Forward) serve() {
+	defer f.served.Done()
+	for {
+		local, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		if f.closed {
+			f.mu.Unlock()
+			_ = local.Close()
+			return
+		}
+		f.open[local] = struct{}{}
+		f.served.Add(1)
+		f.mu.Unlock()
+		go func() {
+			defer f.served.Done()
+			defer func() {
+				f.mu.Lock()
+				delete(f.open, local)
+				f.mu.Unlock()
+				_ = local.Close()
+			}()
+			remote, err := f.dial()
+			if err != nil {
+				return
+			}
+			defer remote.Close()
+			done := make(chan struct{})
+			go func() {
+				_, _ = io.Copy(remote, local)
+				// Let the remote end see EOF while its replies still flow back
+				if cw, ok := remote.(interface{ CloseWrite() error }); ok {
+					_ = cw.CloseWrite()
+				}
+				close(done)
+			}()
+			_, _ = io.Copy(local, remote)
+			_ = local.Close()
+			<-done
+		}()
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright © 2025 The vjailbreak authors
+
+package esxissh
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestForward(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "echo.sock")
+	server, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				fmt.Fprintf(conn, "echo %s", line)
+			}()
+		}
+	}()
+
+	forward, err := newForward(func() (net.Conn, error) { return net.Dial("unix", socket) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", forward.Port()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(conn, "hello\n")
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "echo hello\n" {
+		t.Errorf("reply = %q, want %q", reply, "echo hello\n")
+	}
+	conn.Close()
+
+	if err := forward.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", forward.Port())); err == nil {
+		t.Error("forward still accepts connections after Close")
+	}
+}
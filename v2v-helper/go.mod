@@ -15,6 +15,7 @@ require (
 	github.com/vmware/govmomi v0.51.0
 	golang.org/x/crypto v0.44.0
 	golang.org/x/sys v0.38.0
+	golang.org/x/time v0.12.0
 	k8s.io/api v0.33.3
 	k8s.io/apimachinery v0.33.3
 	k8s.io/client-go v0.33.1
@@ -52,7 +53,6 @@ require (
 	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/term v0.37.0 // indirect
 	golang.org/x/text v0.31.0 // indirect
-	golang.org/x/time v0.12.0 // indirect
 	google.golang.org/protobuf v1.36.10 // indirect
 	gopkg.in/evanphx/json-patch.v4 v4.12.0 // indirect
 	gopkg.in/inf.v0 v0.9.1 // indirect
@@ -0,0 +1,892 @@
+// Copyright © 2025 The vjailbreak authors
+
+package kubevirt
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/flavors"
+	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/v2/openstack/networking/v2/networks"
+	"github.com/gophercloud/gophercloud/v2/openstack/networking/v2/ports"
+	"github.com/gophercloud/gophercloud/v2/openstack/networking/v2/subnets"
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	commonutils "github.com/platform9/vjailbreak/pkg/common/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/openstack"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
+	pkgutils "github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KubevirtClients is the KubeVirt destination. It implements OpenstackOperations so
+// the migration flow runs unchanged: a volume is a CDI DataVolume staged as a raw
+// file on the vjailbreak node, a port is a NIC on a Multus network or the pod
+// network, and the server is a KubeVirt VirtualMachine. The staged disks are
+// uploaded through the CDI upload proxy when the VirtualMachine is created, after
+// virt-v2v has converted them in place. Each migration pod stages its disks in a
+// directory named after the pod, so the files of a pod that died can be found and
+// removed by the next one on the node.
+type KubevirtClients struct {
+	K8sClient      client.Client
+	Namespace      string
+	UploadProxyURL string
+	StagingDir     string
+	HTTPClient     *http.Client
+
+	mu      sync.Mutex
+	volumes map[string]*stagedVolume
+	ports   map[string]*ports.Port
+}
+
+var _ openstack.OpenstackOperations = (*KubevirtClients)(nil)
+
+// stagedVolume is the state of one DataVolume that OpenStack would keep on the volume
+type stagedVolume struct {
+	volume *volumes.Volume
+	// path is the raw file the disk is copied to and converted in, empty for a blank volume
+	path string
+	// size is the size of the raw file in bytes
+	size int64
+	// blank volumes are created empty by CDI and are never uploaded
+	blank    bool
+	uploaded bool
+	uefi     bool
+	bootable bool
+	// imageMetadata holds the image properties applied to the boot volume
+	imageMetadata map[string]string
+	// server is the VirtualMachine using the volume
+	server string
+}
+
+// NewKubevirtClients returns the KubeVirt destination placing VMs in namespace.
+// uploadProxyURL defaults to the in-cluster CDI upload proxy.
+func NewKubevirtClients(ctx context.Context, k8sClient client.Client, namespace, uploadProxyURL string) (*KubevirtClients, error) {
+	if namespace == "" {
+		return nil, errors.New("KubeVirt destination namespace is not set")
+	}
+	ns := &corev1.Namespace{}
+	if err := k8sClient.Get(ctx, k8stypes.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, errors.Wrapf(err, "failed to get KubeVirt destination namespace '%s'", namespace)
+	}
+	if uploadProxyURL == "" {
+		uploadProxyURL = constants.KubevirtUploadProxyURLDefault
+	}
+	podName := os.Getenv("POD_NAME")
+	if podName == "" {
+		return nil, errors.New("POD_NAME env var not set")
+	}
+	if err := removeStaleStaging(ctx, k8sClient, constants.KubevirtStagingDir, podName); err != nil {
+		return nil, err
+	}
+	stagingDir := filepath.Join(constants.KubevirtStagingDir, podName)
+	if err := os.MkdirAll(stagingDir, 0o755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create staging directory %s", stagingDir)
+	}
+	return &KubevirtClients{
+		K8sClient:      k8sClient,
+		Namespace:      namespace,
+		UploadProxyURL: strings.TrimSuffix(uploadProxyURL, "/"),
+		StagingDir:     stagingDir,
+		HTTPClient: &http.Client{
+			// No overall timeout, an upload takes as long as the disk is large
+			Transport: &http.Transport{
+				//nolint:gosec // the upload proxy serves a certificate signed by the CDI internal CA, the upload token authenticates the request
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+		volumes: map[string]*stagedVolume{},
+		ports:   map[string]*ports.Port{},
+	}, nil
+}
+
+// removeStaleStaging removes the staging directories of migration pods that are
+// gone or finished. Staged files are only removed by DeleteVolume, so a pod that
+// was killed leaves its disks behind on the node.
+func removeStaleStaging(ctx context.Context, k8sClient client.Client, dir, podName string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to read staging directory %s", dir)
+	}
+	for _, entry := range entries {
+		if entry.Name() == podName {
+			continue
+		}
+		if entry.IsDir() {
+			pod := &corev1.Pod{}
+			err := k8sClient.Get(ctx, k8stypes.NamespacedName{Name: entry.Name(), Namespace: constants.NamespaceMigrationSystem}, pod)
+			if err == nil && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+				continue
+			}
+			if err != nil && !apierrors.IsNotFound(err) {
+				return errors.Wrapf(err, "failed to get migration pod %s", entry.Name())
+			}
+		}
+		// Files directly in dir were staged before each pod had its own directory
+		pkgutils.PrintLog(fmt.Sprintf("KUBEVIRT API: Removing stale staging data %s", filepath.Join(dir, entry.Name())))
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return errors.Wrapf(err, "failed to remove stale staging data %s", filepath.Join(dir, entry.Name()))
+		}
+	}
+	return nil
+}
+
+// checkStagingSpace fails when the staging filesystem cannot hold a new file of
+// size bytes next to the staged files of this migration that are not yet fully
+// written. The files are sparse, so running out of space would otherwise only
+// show up part way through the copy, with the node's root disk full.
+func (kv *KubevirtClients) checkStagingSpace(size int64) error {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(kv.StagingDir, &fs); err != nil {
+		return errors.Wrapf(err, "failed to stat staging filesystem %s", kv.StagingDir)
+	}
+	available := int64(fs.Bavail) * int64(fs.Bsize)
+
+	needed := size
+	kv.mu.Lock()
+	for _, sv := range kv.volumes {
+		if sv.path == "" || sv.uploaded {
+			continue
+		}
+		needed += sv.size
+		var st syscall.Stat_t
+		if err := syscall.Stat(sv.path, &st); err == nil {
+			needed -= st.Blocks * 512
+		}
+	}
+	kv.mu.Unlock()
+
+	if needed > available {
+		return errors.Errorf("staging filesystem %s has %d bytes free, %d are needed", kv.StagingDir, available, needed)
+	}
+	return nil
+}
+
+func (kv *KubevirtClients) getStagedVolume(volumeID string) (*stagedVolume, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	sv, ok := kv.volumes[volumeID]
+	if !ok {
+		return nil, errors.Errorf("DataVolume %s was not created by this migration", volumeID)
+	}
+	return sv, nil
+}
+
+// CreateVolume creates an upload DataVolume and the sparse raw file the disk is
+// copied to. A size of 0 asks for the smallest volume, which is created blank.
+func (kv *KubevirtClients) CreateVolume(ctx context.Context, name string, size int64, ostype string, uefi bool, volumetype string, setRDMLabel bool) (*volumes.Volume, error) {
+	dvName, err := dataVolumeName(name)
+	if err != nil {
+		return nil, err
+	}
+	sizeGiB := sizeInGiB(size)
+	pkgutils.PrintLog(fmt.Sprintf("KUBEVIRT API: Creating DataVolume %s/%s for disk %s with size %dGi, storage class %q",
+		kv.Namespace, dvName, name, sizeGiB, volumetype))
+
+	if volumetype != "" {
+		sc := &storagev1.StorageClass{}
+		if err := kv.K8sClient.Get(ctx, k8stypes.NamespacedName{Name: volumetype}, sc); err != nil {
+			return nil, errors.Wrapf(err, "failed to get StorageClass '%s'", volumetype)
+		}
+	}
+
+	sv := &stagedVolume{
+		volume: &volumes.Volume{
+			ID:         dvName,
+			Name:       name,
+			Size:       sizeGiB,
+			VolumeType: volumetype,
+			Status:     "available",
+		},
+		blank: size == 0,
+		uefi:  uefi,
+	}
+	if !sv.blank {
+		if err := kv.checkStagingSpace(size); err != nil {
+			return nil, err
+		}
+		sv.path = filepath.Join(kv.StagingDir, dvName+".img")
+		sv.size = size
+		f, err := os.Create(sv.path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create staging file %s", sv.path)
+		}
+		err = f.Truncate(size)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(sv.path)
+			return nil, errors.Wrapf(err, "failed to size staging file %s", sv.path)
+		}
+	}
+
+	dv := newDataVolume(dataVolumeSpec{
+		Name:         dvName,
+		Namespace:    kv.Namespace,
+		SizeGiB:      sizeGiB,
+		StorageClass: volumetype,
+		Blank:        sv.blank,
+	})
+	if err := kv.K8sClient.Create(ctx, dv); err != nil {
+		if sv.path != "" {
+			_ = os.Remove(sv.path)
+		}
+		return nil, errors.Wrapf(err, "failed to create DataVolume '%s'", dvName)
+	}
+
+	kv.mu.Lock()
+	kv.volumes[dvName] = sv
+	kv.mu.Unlock()
+	return sv.volume, nil
+}
+
+// DeleteVolume deletes the DataVolume, its PVC with it, and the staging file
+func (kv *KubevirtClients) DeleteVolume(ctx context.Context, volumeID string) error {
+	pkgutils.PrintLog(fmt.Sprintf("KUBEVIRT API: Deleting DataVolume %s/%s", kv.Namespace, volumeID))
+	dv := &unstructured.Unstructured{}
+	dv.SetGroupVersionKind(dataVolumeGVK)
+	dv.SetName(volumeID)
+	dv.SetNamespace(kv.Namespace)
+	if err := kv.K8sClient.Delete(ctx, dv); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete DataVolume '%s'", volumeID)
+	}
+
+	kv.mu.Lock()
+	sv, ok := kv.volumes[volumeID]
+	delete(kv.volumes, volumeID)
+	kv.mu.Unlock()
+	if ok && sv.path != "" {
+		if err := os.Remove(sv.path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove staging file %s", sv.path)
+		}
+	}
+	return nil
+}
+
+// WaitForVolume returns at once, a staged file needs no attach or detach.
+// DeleteServer already waits for the VirtualMachine to release its DataVolumes.
+func (kv *KubevirtClients) WaitForVolume(ctx context.Context, volumeID string) error {
+	_, err := kv.getStagedVolume(volumeID)
+	return err
+}
+
+// AttachVolumeToVM is a no-op, the staged file is written directly
+func (kv *KubevirtClients) AttachVolumeToVM(ctx context.Context, volumeID string) error {
+	_, err := kv.getStagedVolume(volumeID)
+	return err
+}
+
+// WaitForVolumeAttachment is a no-op, see AttachVolumeToVM
+func (kv *KubevirtClients) WaitForVolumeAttachment(ctx context.Context, volumeID string) error {
+	return nil
+}
+
+// DetachVolumeFromVM is a no-op, see AttachVolumeToVM
+func (kv *KubevirtClients) DetachVolumeFromVM(ctx context.Context, volumeID string) error {
+	return nil
+}
+
+// FindDevice returns the staging file of the volume, which the copy and virt-v2v
+// use as they would the block device of an attached Cinder volume
+func (kv *KubevirtClients) FindDevice(volumeID string) (string, error) {
+	sv, err := kv.getStagedVolume(volumeID)
+	if err != nil {
+		return "", err
+	}
+	if sv.path == "" {
+		return "", errors.Errorf("DataVolume %s is blank and has no staging file", volumeID)
+	}
+	if sv.uploaded {
+		return "", errors.Errorf("DataVolume %s is already uploaded", volumeID)
+	}
+	return sv.path, nil
+}
+
+// SetVolumeUEFI records that the VirtualMachine boots with EFI firmware
+func (kv *KubevirtClients) SetVolumeUEFI(ctx context.Context, volume *volumes.Volume) error {
+	sv, err := kv.getStagedVolume(volume.ID)
+	if err != nil {
+		return err
+	}
+	kv.mu.Lock()
+	sv.uefi = true
+	kv.mu.Unlock()
+	return nil
+}
+
+// EnableQGA is a no-op, KubeVirt always adds the guest agent channel
+func (kv *KubevirtClients) EnableQGA(ctx context.Context, volume *volumes.Volume) error {
+	return nil
+}
+
+// SetVolumeImageMetadata is a no-op. The properties it sets for Windows select a
+// virtio bus and a Hyper-V enlightened machine, which a VirtualMachine has by default.
+func (kv *KubevirtClients) SetVolumeImageMetadata(ctx context.Context, volume *volumes.Volume, setRDMLabel bool) error {
+	return nil
+}
+
+// ApplyBootVolumeImageMetadata records the image properties of the boot volume.
+// hw_disk_bus and hw_firmware_type are honoured when the VirtualMachine is created.
+func (kv *KubevirtClients) ApplyBootVolumeImageMetadata(ctx context.Context, volume *volumes.Volume, metadata map[string]string) error {
+	sv, err := kv.getStagedVolume(volume.ID)
+	if err != nil {
+		return err
+	}
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if sv.imageMetadata == nil {
+		sv.imageMetadata = map[string]string{}
+	}
+	for k, v := range metadata {
+		sv.imageMetadata[k] = v
+	}
+	return nil
+}
+
+// SetVolumeBootable records that the VirtualMachine boots from the volume
+func (kv *KubevirtClients) SetVolumeBootable(ctx context.Context, volume *volumes.Volume) error {
+	sv, err := kv.getStagedVolume(volume.ID)
+	if err != nil {
+		return err
+	}
+	kv.mu.Lock()
+	sv.bootable = true
+	kv.mu.Unlock()
+	return nil
+}
+
+// GetClosestFlavour returns a flavor of exactly the requested size. A
+// VirtualMachine is sized directly, there is no flavor to round up to.
+func (kv *KubevirtClients) GetClosestFlavour(ctx context.Context, cpu int32, memory int32) (*flavors.Flavor, error) {
+	return &flavors.Flavor{
+		Name:  fmt.Sprintf("%dvcpu-%dmb", cpu, memory),
+		VCPUs: int(cpu),
+		RAM:   int(memory),
+	}, nil
+}
+
+// GetFlavor fails, OpenStack flavors do not apply to a KubeVirt destination
+func (kv *KubevirtClients) GetFlavor(ctx context.Context, flavorId string) (*flavors.Flavor, error) {
+	return nil, errors.Errorf("flavor %s cannot be used with a KubeVirt destination, the VM is sized from the source", flavorId)
+}
+
+// GetNetwork resolves a NetworkMapping target: "pod" for the pod network, otherwise
+// the name or namespace/name of a NetworkAttachmentDefinition
+func (kv *KubevirtClients) GetNetwork(ctx context.Context, networkname string) (*networks.Network, error) {
+	if networkname == constants.KubevirtPodNetwork {
+		return &networks.Network{ID: constants.KubevirtPodNetwork, Name: constants.KubevirtPodNetwork, Status: "ACTIVE"}, nil
+	}
+	namespace, name, err := splitNetworkName(networkname, kv.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	nad := &unstructured.Unstructured{}
+	nad.SetGroupVersionKind(networkAttachmentDefinitionGVK)
+	if err := kv.K8sClient.Get(ctx, k8stypes.NamespacedName{Name: name, Namespace: namespace}, nad); err != nil {
+		return nil, errors.Wrapf(err, "failed to get NetworkAttachmentDefinition '%s/%s'", namespace, name)
+	}
+	id := namespace + "/" + name
+	return &networks.Network{ID: id, Name: id, Status: "ACTIVE"}, nil
+}
+
+// GetIsSimpleNetwork reports a Multus network as L2, as vJailbreak knows no subnet
+// for it. The pod network is not, its addresses come from the KubeVirt DHCP server.
+func (kv *KubevirtClients) GetIsSimpleNetwork(ctx context.Context, networkID string) (bool, error) {
+	return networkID != constants.KubevirtPodNetwork, nil
+}
+
+// GetPort returns a NIC created by this migration. Pre-created ports do not exist on KubeVirt.
+func (kv *KubevirtClients) GetPort(ctx context.Context, portID string) (*ports.Port, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	port, ok := kv.ports[portID]
+	if !ok {
+		return nil, errors.Errorf("port %s does not exist, ports cannot be pre-created for a KubeVirt destination", portID)
+	}
+	return port, nil
+}
+
+// ValidateAndCreatePort records a NIC with the MAC of the source NIC. On a Multus
+// network the guest keeps its addresses. On the pod network it must use DHCP, so
+// the preserved addresses are dropped, which needs fallback to DHCP.
+func (kv *KubevirtClients) ValidateAndCreatePort(ctx context.Context, network *networks.Network, mac string, ipPerMac map[string][]vm.IpEntry, vmname string, securityGroups []string, fallbackToDHCP bool, gatewayIP map[string]string, subnetPortIndex map[string]int) (*ports.Port, error) {
+	ips := make([]string, 0, len(ipPerMac[mac]))
+	for _, entry := range ipPerMac[mac] {
+		ips = append(ips, entry.IP)
+	}
+	if network.ID == constants.KubevirtPodNetwork && len(ips) > 0 {
+		if !fallbackToDHCP {
+			return nil, errors.Errorf("the pod network assigns addresses by DHCP, cannot keep IP %v of MAC %s "+
+				"with fallback to DHCP disabled", ips, mac)
+		}
+		pkgutils.PrintLog(fmt.Sprintf("Could Not Use IP: %v on the pod network, the guest uses DHCP", ips))
+		ipPerMac[mac] = []vm.IpEntry{}
+		delete(gatewayIP, mac)
+		ips = nil
+	}
+	return kv.CreatePort(ctx, network, mac, ips, vmname, securityGroups, fallbackToDHCP, gatewayIP)
+}
+
+// CreatePort records a NIC, see ValidateAndCreatePort
+func (kv *KubevirtClients) CreatePort(ctx context.Context, networkid *networks.Network, mac string, ip []string, vmname string, securityGroups []string, fallbackToDHCP bool, gatewayIP map[string]string) (*ports.Port, error) {
+	if len(securityGroups) > 0 {
+		return nil, errors.New("security groups are not supported on a KubeVirt destination")
+	}
+	fixedIPs := make([]ports.IP, 0, len(ip))
+	for _, addr := range ip {
+		fixedIPs = append(fixedIPs, ports.IP{IPAddress: addr})
+	}
+
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	port := &ports.Port{
+		ID:         fmt.Sprintf("%s-nic%d", vmname, len(kv.ports)),
+		Name:       fmt.Sprintf("%s-nic%d", vmname, len(kv.ports)),
+		NetworkID:  networkid.ID,
+		MACAddress: mac,
+		FixedIPs:   fixedIPs,
+		Status:     "DOWN",
+	}
+	kv.ports[port.ID] = port
+	pkgutils.PrintLog(fmt.Sprintf("KUBEVIRT API: NIC %s on network %s with MAC address %s and IP addresses %v", port.ID, networkid.ID, mac, ip))
+	return port, nil
+}
+
+// DeletePort forgets a NIC, it only exists as part of the VirtualMachine
+func (kv *KubevirtClients) DeletePort(ctx context.Context, portID string) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	delete(kv.ports, portID)
+	return nil
+}
+
+// GetSubnet fails, a Multus network has no subnet vJailbreak knows of
+func (kv *KubevirtClients) GetSubnet(ctx context.Context, network []string, ip string) (*subnets.Subnet, error) {
+	return nil, errors.Errorf("no subnet is known for IP %s on a KubeVirt destination", ip)
+}
+
+// CreateVM uploads every disk that is not uploaded yet and creates the
+// VirtualMachine. The server ID is the name of the VirtualMachine.
+func (kv *KubevirtClients) CreateVM(ctx context.Context, flavor *flavors.Flavor, networkIDs, portIDs []string, vminfo vm.VMInfo, availabilityZone string, securityGroups []string, serverGroupID string, vjailbreakSettings k8sutils.VjailbreakSettings, espDiskIndex int) (*servers.Server, error) {
+	if len(securityGroups) > 0 {
+		return nil, errors.New("security groups are not supported on a KubeVirt destination")
+	}
+	vmName, err := commonutils.ConvertToK8sName(vminfo.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot name the VirtualMachine of VM %s", vminfo.Name)
+	}
+
+	bootableDiskIndex := -1
+	for idx, disk := range vminfo.VMDisks {
+		if disk.Boot {
+			bootableDiskIndex = idx
+			break
+		}
+	}
+	if bootableDiskIndex == -1 {
+		return nil, fmt.Errorf("unable to determine boot volume for VM: %s", vminfo.Name)
+	}
+	// A UEFI guest with the ESP on a separate disk boots from the ESP
+	firstBootIndex := bootableDiskIndex
+	if vminfo.UEFI && espDiskIndex >= 0 && espDiskIndex < len(vminfo.VMDisks) {
+		firstBootIndex = espDiskIndex
+	}
+
+	boot, err := kv.getStagedVolume(vminfo.VMDisks[bootableDiskIndex].OpenstackVol.ID)
+	if err != nil {
+		return nil, err
+	}
+	kv.mu.Lock()
+	bus := boot.imageMetadata[imagePropDiskBus]
+	uefi := vminfo.UEFI || boot.uefi || boot.imageMetadata[imagePropFirmwareType] == "uefi"
+	kv.mu.Unlock()
+
+	spec := virtualMachineSpec{
+		Name:       vmName,
+		Namespace:  kv.Namespace,
+		SourceName: vminfo.Name,
+		CPU:        flavor.VCPUs,
+		MemoryMB:   flavor.RAM,
+		UEFI:       uefi,
+	}
+	for idx, disk := range vminfo.VMDisks {
+		if disk.OpenstackVol == nil {
+			return nil, errors.Errorf("disk %s has no DataVolume", disk.Name)
+		}
+		if err := kv.uploadVolume(ctx, disk.OpenstackVol.ID); err != nil {
+			return nil, err
+		}
+		d := vmDisk{DataVolume: disk.OpenstackVol.ID, Bus: bus}
+		if idx == firstBootIndex {
+			d.BootOrder = 1
+		}
+		spec.Disks = append(spec.Disks, d)
+	}
+	if vminfo.LDMProbeVolumeID != "" {
+		// The probe makes Windows install the virtio storage driver while the
+		// guest itself still boots from SATA
+		spec.Disks = append(spec.Disks, vmDisk{DataVolume: vminfo.LDMProbeVolumeID, Bus: diskBusVirtio})
+	}
+
+	kv.mu.Lock()
+	for idx := range networkIDs {
+		nic := vmInterface{Network: networkIDs[idx]}
+		if idx < len(portIDs) {
+			if port, ok := kv.ports[portIDs[idx]]; ok {
+				nic.MACAddress = port.MACAddress
+			}
+		}
+		spec.Interfaces = append(spec.Interfaces, nic)
+	}
+	kv.mu.Unlock()
+
+	if len(vminfo.TargetMetadata) > 0 {
+		metadata, err := json.Marshal(vminfo.TargetMetadata)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode instance metadata")
+		}
+		spec.Metadata = string(metadata)
+	}
+
+	pkgutils.PrintLog(fmt.Sprintf("KUBEVIRT API: Creating VirtualMachine %s/%s with %d vCPUs, %dMB memory, %d disks and %d NICs",
+		kv.Namespace, vmName, spec.CPU, spec.MemoryMB, len(spec.Disks), len(spec.Interfaces)))
+	if err := kv.K8sClient.Create(ctx, newVirtualMachine(spec)); err != nil {
+		return nil, errors.Wrapf(err, "failed to create VirtualMachine '%s'", vmName)
+	}
+
+	kv.mu.Lock()
+	for _, d := range spec.Disks {
+		if sv, ok := kv.volumes[d.DataVolume]; ok {
+			sv.server = vmName
+		}
+	}
+	kv.mu.Unlock()
+	return &servers.Server{ID: vmName, Name: vminfo.Name, Status: "BUILD"}, nil
+}
+
+// uploadVolume sends the staged file of a volume to its DataVolume through the
+// CDI upload proxy, waits for CDI to finish processing it and removes the file
+func (kv *KubevirtClients) uploadVolume(ctx context.Context, volumeID string) error {
+	sv, err := kv.getStagedVolume(volumeID)
+	if err != nil {
+		return err
+	}
+	if sv.blank || sv.uploaded {
+		return nil
+	}
+
+	if err := kv.waitForDataVolumePhase(ctx, volumeID, dataVolumePhaseUploadReady); err != nil {
+		return err
+	}
+	token, err := kv.requestUploadToken(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(sv.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open staging file %s", sv.path)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat staging file %s", sv.path)
+	}
+
+	pkgutils.PrintLog(fmt.Sprintf("KUBEVIRT API: Uploading %s (%d bytes) to DataVolume %s/%s", sv.path, info.Size(), kv.Namespace, volumeID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, kv.UploadProxyURL+"/v1beta1/upload", f)
+	if err != nil {
+		return errors.Wrap(err, "failed to build upload request")
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := kv.HTTPClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload DataVolume '%s'", volumeID)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return errors.Errorf("upload of DataVolume '%s' returned %s: %s", volumeID, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := kv.waitForDataVolumePhase(ctx, volumeID, dataVolumePhaseSucceeded); err != nil {
+		return err
+	}
+
+	kv.mu.Lock()
+	sv.uploaded = true
+	kv.mu.Unlock()
+	if err := os.Remove(sv.path); err != nil && !os.IsNotExist(err) {
+		pkgutils.PrintLog(fmt.Sprintf("Failed to remove staging file %s: %v", sv.path, err))
+	}
+	pkgutils.PrintLog(fmt.Sprintf("DataVolume %s/%s uploaded successfully", kv.Namespace, volumeID))
+	return nil
+}
+
+// requestUploadToken returns a token that authorizes an upload to the PVC of the DataVolume
+func (kv *KubevirtClients) requestUploadToken(ctx context.Context, volumeID string) (string, error) {
+	req := newUploadTokenRequest(volumeID, kv.Namespace)
+	if err := kv.K8sClient.Create(ctx, req); err != nil {
+		return "", errors.Wrapf(err, "failed to request an upload token for DataVolume '%s'", volumeID)
+	}
+	token, _, _ := unstructured.NestedString(req.Object, "status", "token")
+	if token == "" {
+		return "", errors.Errorf("no upload token was issued for DataVolume '%s'", volumeID)
+	}
+	return token, nil
+}
+
+// waitForDataVolumePhase polls the DataVolume until it reaches phase
+func (kv *KubevirtClients) waitForDataVolumePhase(ctx context.Context, volumeID, phase string) error {
+	deadline := time.Now().Add(constants.KubevirtDataVolumeTimeout)
+	for {
+		dv := &unstructured.Unstructured{}
+		dv.SetGroupVersionKind(dataVolumeGVK)
+		if err := kv.K8sClient.Get(ctx, k8stypes.NamespacedName{Name: volumeID, Namespace: kv.Namespace}, dv); err != nil {
+			return errors.Wrapf(err, "failed to get DataVolume '%s'", volumeID)
+		}
+		current, _, _ := unstructured.NestedString(dv.Object, "status", "phase")
+		if current == phase {
+			return nil
+		}
+		if current == dataVolumePhaseFailed {
+			return errors.Errorf("DataVolume '%s' failed while waiting for phase %s", volumeID, phase)
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("DataVolume '%s' did not reach phase %s within %s, it is %q",
+				volumeID, phase, constants.KubevirtDataVolumeTimeout, current)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (kv *KubevirtClients) getVirtualMachine(ctx context.Context, name string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(virtualMachineGVK)
+	if err := kv.K8sClient.Get(ctx, k8stypes.NamespacedName{Name: name, Namespace: kv.Namespace}, obj); err != nil {
+		return nil, errors.Wrapf(err, "failed to get VirtualMachine '%s'", name)
+	}
+	return obj, nil
+}
+
+// GetServerGroups returns no server groups, they do not exist on KubeVirt
+func (kv *KubevirtClients) GetServerGroups(ctx context.Context, projectName string) ([]vjailbreakv1alpha1.ServerGroupInfo, error) {
+	return []vjailbreakv1alpha1.ServerGroupInfo{}, nil
+}
+
+// GetSecurityGroupIDs fails for any security group, they do not exist on KubeVirt
+func (kv *KubevirtClients) GetSecurityGroupIDs(ctx context.Context, groupNames []string, projectName string) ([]string, error) {
+	if len(groupNames) > 0 {
+		return nil, errors.Errorf("security groups %v are not supported on a KubeVirt destination", groupNames)
+	}
+	return nil, nil
+}
+
+// ManageExistingVolume fails, there is no Cinder to manage an array LUN into
+func (kv *KubevirtClients) ManageExistingVolume(name string, ref map[string]interface{}, host string, volumeType string) (*volumes.Volume, error) {
+	return nil, errors.New("storage accelerated copy is not supported on a KubeVirt destination")
+}
+
+// WaitUntilVMActive reports whether the VirtualMachine is running
+func (kv *KubevirtClients) WaitUntilVMActive(ctx context.Context, vmID string) (bool, error) {
+	status, err := kv.GetServerStatus(ctx, vmID)
+	if err != nil {
+		return false, err
+	}
+	switch status {
+	case "ACTIVE":
+		return true, nil
+	case "CRASHLOOPBACKOFF", "ERRORUNSCHEDULABLE", "ERRIMAGEPULL", "IMAGEPULLBACKOFF", "ERRORPVCNOTFOUND", "DATAVOLUMEERROR":
+		return false, errors.Errorf("VirtualMachine %s is in %s state", vmID, status)
+	}
+	return false, nil
+}
+
+// GetCinderVolumeServices fails, there is no Cinder
+func (kv *KubevirtClients) GetCinderVolumeServices(ctx context.Context) (interface{}, error) {
+	return nil, errors.New("there are no Cinder volume services on a KubeVirt destination")
+}
+
+// UploadVolumeToImage fails, there is no Glance to export to
+func (kv *KubevirtClients) UploadVolumeToImage(ctx context.Context, volumeID, imageName, diskFormat, visibility string) (string, error) {
+	return "", errors.New("export to Glance is not supported on a KubeVirt destination")
+}
+
+// WaitForImage fails, there is no Glance to export to
+func (kv *KubevirtClients) WaitForImage(ctx context.Context, imageID string, timeout time.Duration) error {
+	return errors.New("export to Glance is not supported on a KubeVirt destination")
+}
+
+// SetImageProperties fails, there is no Glance to export to
+func (kv *KubevirtClients) SetImageProperties(ctx context.Context, imageID string, properties map[string]string) error {
+	return errors.New("export to Glance is not supported on a KubeVirt destination")
+}
+
+// GetVolume returns the volume, attached to its VirtualMachine once one is created
+func (kv *KubevirtClients) GetVolume(ctx context.Context, volumeID string) (*volumes.Volume, error) {
+	sv, err := kv.getStagedVolume(volumeID)
+	if err != nil {
+		return nil, err
+	}
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	volume := *sv.volume
+	volume.Bootable = fmt.Sprint(sv.bootable)
+	volume.Attachments = nil
+	volume.Status = "available"
+	if sv.server != "" {
+		volume.Status = "in-use"
+		volume.Attachments = []volumes.Attachment{{ServerID: sv.server, VolumeID: volumeID}}
+	}
+	return &volume, nil
+}
+
+// DeleteServer deletes the VirtualMachine and waits until it is gone, which
+// releases its DataVolumes. The DataVolumes are not owned by it and remain.
+func (kv *KubevirtClients) DeleteServer(ctx context.Context, serverID string) error {
+	pkgutils.PrintLog(fmt.Sprintf("KUBEVIRT API: Deleting VirtualMachine %s/%s", kv.Namespace, serverID))
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(virtualMachineGVK)
+	obj.SetName(serverID)
+	obj.SetNamespace(kv.Namespace)
+	if err := kv.K8sClient.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationForeground)); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete VirtualMachine '%s'", serverID)
+		}
+	}
+
+	deadline := time.Now().Add(constants.KubevirtDataVolumeTimeout)
+	for {
+		err := kv.K8sClient.Get(ctx, k8stypes.NamespacedName{Name: serverID, Namespace: kv.Namespace}, obj)
+		if apierrors.IsNotFound(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("VirtualMachine '%s' was not deleted within %s", serverID, constants.KubevirtDataVolumeTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	kv.mu.Lock()
+	for _, sv := range kv.volumes {
+		if sv.server == serverID {
+			sv.server = ""
+		}
+	}
+	kv.mu.Unlock()
+	return nil
+}
+
+// StopServer halts the VirtualMachine, which shuts the guest down over ACPI
+func (kv *KubevirtClients) StopServer(ctx context.Context, serverID string) error {
+	pkgutils.PrintLog(fmt.Sprintf("KUBEVIRT API: Stopping VirtualMachine %s/%s", kv.Namespace, serverID))
+	obj, err := kv.getVirtualMachine(ctx, serverID)
+	if err != nil {
+		return err
+	}
+	patch := []byte(fmt.Sprintf(`{"spec":{"runStrategy":%q}}`, runStrategyHalted))
+	if err := kv.K8sClient.Patch(ctx, obj, client.RawPatch(k8stypes.MergePatchType, patch)); err != nil {
+		return errors.Wrapf(err, "failed to stop VirtualMachine '%s'", serverID)
+	}
+	return nil
+}
+
+// DetachVolumeFromServer removes the DataVolume from the VirtualMachine. A
+// volume that is not hotplugged is only released when the guest next restarts.
+func (kv *KubevirtClients) DetachVolumeFromServer(ctx context.Context, serverID, volumeID string) error {
+	obj, err := kv.getVirtualMachine(ctx, serverID)
+	if err != nil {
+		return err
+	}
+	vmVolumes, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "volumes")
+	vmDisks, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "domain", "devices", "disks")
+	removed := map[string]bool{}
+	keptVolumes := make([]interface{}, 0, len(vmVolumes))
+	for _, v := range vmVolumes {
+		volume, _ := v.(map[string]interface{})
+		dvName, _, _ := unstructured.NestedString(volume, "dataVolume", "name")
+		if dvName == volumeID {
+			name, _, _ := unstructured.NestedString(volume, "name")
+			removed[name] = true
+			continue
+		}
+		keptVolumes = append(keptVolumes, v)
+	}
+	if len(removed) == 0 {
+		return errors.Errorf("DataVolume %s is not attached to VirtualMachine %s", volumeID, serverID)
+	}
+	keptDisks := make([]interface{}, 0, len(vmDisks))
+	for _, d := range vmDisks {
+		disk, _ := d.(map[string]interface{})
+		name, _, _ := unstructured.NestedString(disk, "name")
+		if !removed[name] {
+			keptDisks = append(keptDisks, d)
+		}
+	}
+	if err := unstructured.SetNestedSlice(obj.Object, keptVolumes, "spec", "template", "spec", "volumes"); err != nil {
+		return errors.Wrap(err, "failed to update VirtualMachine volumes")
+	}
+	if err := unstructured.SetNestedSlice(obj.Object, keptDisks, "spec", "template", "spec", "domain", "devices", "disks"); err != nil {
+		return errors.Wrap(err, "failed to update VirtualMachine disks")
+	}
+	if err := kv.K8sClient.Update(ctx, obj); err != nil {
+		return errors.Wrapf(err, "failed to detach DataVolume %s from VirtualMachine %s", volumeID, serverID)
+	}
+
+	kv.mu.Lock()
+	if sv, ok := kv.volumes[volumeID]; ok {
+		sv.server = ""
+	}
+	kv.mu.Unlock()
+	return nil
+}
+
+// WaitForVolumeDetached returns at once, DetachVolumeFromServer takes effect on the spec immediately
+func (kv *KubevirtClients) WaitForVolumeDetached(ctx context.Context, volumeID string, timeout time.Duration) error {
+	return nil
+}
+
+// GetServerStatus returns the Nova equivalent of the printableStatus of the
+// VirtualMachine, ACTIVE when running and SHUTOFF when stopped
+func (kv *KubevirtClients) GetServerStatus(ctx context.Context, serverID string) (string, error) {
+	obj, err := kv.getVirtualMachine(ctx, serverID)
+	if err != nil {
+		return "", err
+	}
+	printableStatus, _, _ := unstructured.NestedString(obj.Object, "status", "printableStatus")
+	return printableStatusToServerStatus(printableStatus), nil
+}
@@ -0,0 +1,75 @@
+// Copyright © 2025 The vjailbreak authors
+
+package kubevirt
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRemoveStaleStaging(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"self", "running", "failed", "gone"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "disk.img"), []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "legacy.img"), []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	pod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: constants.NamespaceMigrationSystem},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		pod("self", corev1.PodRunning),
+		pod("running", corev1.PodRunning),
+		pod("failed", corev1.PodFailed),
+	).Build()
+
+	if err := removeStaleStaging(context.Background(), k8sClient, dir, "self"); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, wantKept := range map[string]bool{
+		"self":       true,
+		"running":    true,
+		"failed":     false,
+		"gone":       false,
+		"legacy.img": false,
+	} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if kept := err == nil; kept != wantKept {
+			t.Errorf("%s kept = %v, want %v", name, kept, wantKept)
+		}
+	}
+}
+
+func TestCheckStagingSpace(t *testing.T) {
+	kv := &KubevirtClients{StagingDir: t.TempDir(), volumes: map[string]*stagedVolume{}}
+	if err := kv.checkStagingSpace(1 << 20); err != nil {
+		t.Errorf("1MiB does not fit: %v", err)
+	}
+	if err := kv.checkStagingSpace(math.MaxInt64 / 2); err == nil {
+		t.Error("expected an error for a file larger than the filesystem")
+	}
+}
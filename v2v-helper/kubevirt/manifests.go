@@ -0,0 +1,325 @@
+// Copyright © 2025 The vjailbreak authors
+
+package kubevirt
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	commonutils "github.com/platform9/vjailbreak/pkg/common/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	dataVolumeGVK = schema.GroupVersionKind{
+		Group:   "cdi.kubevirt.io",
+		Version: "v1beta1",
+		Kind:    "DataVolume",
+	}
+	uploadTokenRequestGVK = schema.GroupVersionKind{
+		Group:   "upload.cdi.kubevirt.io",
+		Version: "v1beta1",
+		Kind:    "UploadTokenRequest",
+	}
+	virtualMachineGVK = schema.GroupVersionKind{
+		Group:   "kubevirt.io",
+		Version: "v1",
+		Kind:    "VirtualMachine",
+	}
+	networkAttachmentDefinitionGVK = schema.GroupVersionKind{
+		Group:   "k8s.cni.cncf.io",
+		Version: "v1",
+		Kind:    "NetworkAttachmentDefinition",
+	}
+)
+
+const (
+	// managedByLabel marks the DataVolumes and VirtualMachines created by a migration
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "vjailbreak"
+	// sourceVMAnnotation records the name of the source VM, which is rarely a valid k8s name
+	sourceVMAnnotation = "vjailbreak.k8s.pf9.io/source-vm"
+	// targetMetadataAnnotation carries the instance metadata OpenStack would have set on
+	// the server. Its keys, such as "tag:env", are not valid label or annotation keys.
+	targetMetadataAnnotation = "vjailbreak.k8s.pf9.io/target-metadata"
+	// bindImmediateAnnotation makes CDI bind the PVC of an upload DataVolume without
+	// waiting for a consumer pod, which never comes for a WaitForFirstConsumer class
+	bindImmediateAnnotation = "cdi.kubevirt.io/storage.bind.immediate.requested"
+
+	// dataVolumePhaseUploadReady, dataVolumePhaseSucceeded and dataVolumePhaseFailed
+	// are the DataVolume phases an upload goes through
+	dataVolumePhaseUploadReady = "UploadReady"
+	dataVolumePhaseSucceeded   = "Succeeded"
+	dataVolumePhaseFailed      = "Failed"
+
+	// runStrategyAlways and runStrategyHalted start and stop a VirtualMachine
+	runStrategyAlways = "Always"
+	runStrategyHalted = "Halted"
+
+	// imagePropDiskBus and imagePropFirmwareType are the Glance image properties
+	// that a KubeVirt VirtualMachine has an equivalent for
+	imagePropDiskBus      = "hw_disk_bus"
+	imagePropFirmwareType = "hw_firmware_type"
+	diskBusVirtio         = "virtio"
+
+	bytesPerGiB = 1024 * 1024 * 1024
+)
+
+// dataVolumeName returns a DNS-1123 name for the DataVolume of a disk. Disk names
+// such as "Hard disk 1" are not valid names, and a hash of the original keeps two
+// disks that sanitize to the same string apart.
+func dataVolumeName(name string) (string, error) {
+	k8sName, _ := commonutils.ConvertToK8sName(name)
+	hash := commonutils.GenerateSha256Hash(name)[:constants.HashSuffixLength]
+	// The PVC name ends up in pod and label values, which are capped at 63 characters
+	maxLen := constants.K8sNameMaxLength - constants.HashSuffixLength - 1
+	if len(k8sName) > maxLen {
+		k8sName = strings.TrimRight(k8sName[:maxLen], "-")
+	}
+	if k8sName == "" {
+		return "", errors.Errorf("cannot derive a DataVolume name from %q", name)
+	}
+	return fmt.Sprintf("%s-%s", k8sName, hash), nil
+}
+
+// sizeInGiB rounds size up to whole GiB and adds one, as a Cinder volume is sized
+func sizeInGiB(size int64) int {
+	return int(math.Ceil(float64(size)/bytesPerGiB)) + 1
+}
+
+// dataVolumeSpec describes the DataVolume backing one disk of the migrated VM
+type dataVolumeSpec struct {
+	Name         string
+	Namespace    string
+	SizeGiB      int
+	StorageClass string
+	// Blank creates an empty volume rather than one waiting for an upload
+	Blank bool
+}
+
+func newDataVolume(spec dataVolumeSpec) *unstructured.Unstructured {
+	source := map[string]interface{}{"upload": map[string]interface{}{}}
+	if spec.Blank {
+		source = map[string]interface{}{"blank": map[string]interface{}{}}
+	}
+	storage := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{
+				"storage": fmt.Sprintf("%dGi", spec.SizeGiB),
+			},
+		},
+	}
+	// An empty class leaves the choice to the default StorageClass
+	if spec.StorageClass != "" {
+		storage["storageClassName"] = spec.StorageClass
+	}
+
+	dv := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      spec.Name,
+			"namespace": spec.Namespace,
+			"labels": map[string]interface{}{
+				managedByLabel: managedByValue,
+			},
+			"annotations": map[string]interface{}{
+				bindImmediateAnnotation: "true",
+			},
+		},
+		"spec": map[string]interface{}{
+			"source":  source,
+			"storage": storage,
+		},
+	}}
+	dv.SetGroupVersionKind(dataVolumeGVK)
+	return dv
+}
+
+func newUploadTokenRequest(name, namespace string) *unstructured.Unstructured {
+	req := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": map[string]interface{}{
+			"pvcName": name,
+		},
+	}}
+	req.SetGroupVersionKind(uploadTokenRequestGVK)
+	return req
+}
+
+// vmDisk is one disk of the VirtualMachine
+type vmDisk struct {
+	// DataVolume is the DataVolume holding the disk
+	DataVolume string
+	// BootOrder is 1 for the disk the firmware boots from, 0 for no boot order
+	BootOrder int
+	// Bus is the disk bus, virtio, sata or scsi
+	Bus string
+}
+
+// vmInterface is one NIC of the VirtualMachine
+type vmInterface struct {
+	// Network is "pod" or the namespace/name of a NetworkAttachmentDefinition
+	Network    string
+	MACAddress string
+}
+
+// virtualMachineSpec describes the VirtualMachine created in place of a Nova server
+type virtualMachineSpec struct {
+	Name       string
+	Namespace  string
+	SourceName string
+	CPU        int
+	MemoryMB   int
+	UEFI       bool
+	Disks      []vmDisk
+	Interfaces []vmInterface
+	// Metadata is the instance metadata of the migrated VM, see targetMetadataAnnotation
+	Metadata string
+}
+
+func newVirtualMachine(spec virtualMachineSpec) *unstructured.Unstructured {
+	disks := make([]interface{}, 0, len(spec.Disks))
+	volumes := make([]interface{}, 0, len(spec.Disks))
+	for idx, d := range spec.Disks {
+		name := fmt.Sprintf("disk%d", idx)
+		bus := d.Bus
+		if bus == "" {
+			bus = diskBusVirtio
+		}
+		disk := map[string]interface{}{
+			"name": name,
+			"disk": map[string]interface{}{"bus": bus},
+		}
+		if d.BootOrder > 0 {
+			disk["bootOrder"] = int64(d.BootOrder)
+		}
+		disks = append(disks, disk)
+		volumes = append(volumes, map[string]interface{}{
+			"name":       name,
+			"dataVolume": map[string]interface{}{"name": d.DataVolume},
+		})
+	}
+
+	interfaces := make([]interface{}, 0, len(spec.Interfaces))
+	networks := make([]interface{}, 0, len(spec.Interfaces))
+	for idx, nic := range spec.Interfaces {
+		name := fmt.Sprintf("nic%d", idx)
+		iface := map[string]interface{}{"name": name}
+		if nic.MACAddress != "" {
+			iface["macAddress"] = nic.MACAddress
+		}
+		network := map[string]interface{}{"name": name}
+		if nic.Network == constants.KubevirtPodNetwork {
+			// The pod network only supports masquerade, the guest gets its address by DHCP
+			iface["masquerade"] = map[string]interface{}{}
+			network["pod"] = map[string]interface{}{}
+		} else {
+			iface["bridge"] = map[string]interface{}{}
+			network["multus"] = map[string]interface{}{"networkName": nic.Network}
+		}
+		interfaces = append(interfaces, iface)
+		networks = append(networks, network)
+	}
+
+	firmware := map[string]interface{}{}
+	if spec.UEFI {
+		// Secure boot needs SMM and signed shims, which a converted guest cannot be assumed to have
+		firmware["bootloader"] = map[string]interface{}{
+			"efi": map[string]interface{}{"secureBoot": false},
+		}
+	}
+
+	memory := fmt.Sprintf("%dMi", spec.MemoryMB)
+	domain := map[string]interface{}{
+		"cpu": map[string]interface{}{
+			"cores": int64(spec.CPU),
+		},
+		"memory": map[string]interface{}{
+			"guest": memory,
+		},
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{
+				"memory": memory,
+			},
+		},
+		"devices": map[string]interface{}{
+			"disks":      disks,
+			"interfaces": interfaces,
+		},
+	}
+	if len(firmware) > 0 {
+		domain["firmware"] = firmware
+	}
+	if len(interfaces) == 0 {
+		// Without this KubeVirt adds a default pod network interface
+		domain["devices"].(map[string]interface{})["autoattachPodInterface"] = false
+	}
+
+	annotations := map[string]interface{}{
+		sourceVMAnnotation: spec.SourceName,
+	}
+	if spec.Metadata != "" {
+		annotations[targetMetadataAnnotation] = spec.Metadata
+	}
+
+	vm := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      spec.Name,
+			"namespace": spec.Namespace,
+			"labels": map[string]interface{}{
+				managedByLabel: managedByValue,
+			},
+			"annotations": annotations,
+		},
+		"spec": map[string]interface{}{
+			"runStrategy": runStrategyAlways,
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{
+						"kubevirt.io/domain": spec.Name,
+					},
+				},
+				"spec": map[string]interface{}{
+					"domain":   domain,
+					"networks": networks,
+					"volumes":  volumes,
+				},
+			},
+		},
+	}}
+	vm.SetGroupVersionKind(virtualMachineGVK)
+	return vm
+}
+
+// splitNetworkName resolves a NetworkMapping target to the namespace and name of a
+// NetworkAttachmentDefinition, defaulting the namespace to the destination namespace
+func splitNetworkName(network, namespace string) (string, string, error) {
+	parts := strings.Split(strings.TrimSpace(network), "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		return namespace, parts[0], nil
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return parts[0], parts[1], nil
+	default:
+		return "", "", errors.Errorf("network %q is neither a NetworkAttachmentDefinition name nor namespace/name", network)
+	}
+}
+
+// printableStatusToServerStatus maps the printableStatus of a VirtualMachine to the
+// Nova server status the migration flow checks for
+func printableStatusToServerStatus(printableStatus string) string {
+	switch printableStatus {
+	case "Running":
+		return "ACTIVE"
+	case "Stopped":
+		return "SHUTOFF"
+	default:
+		return strings.ToUpper(printableStatus)
+	}
+}
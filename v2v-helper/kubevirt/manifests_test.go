@@ -0,0 +1,233 @@
+// Copyright © 2025 The vjailbreak authors
+
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDataVolumeName(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantPrefix string
+	}{
+		{name: "disk label", input: "Hard disk 1", wantPrefix: "hard-disk-1-"},
+		{name: "already valid", input: "web01-disk0", wantPrefix: "web01-disk0-"},
+		{name: "long name is truncated", input: strings.Repeat("a", 100), wantPrefix: strings.Repeat("a", 57) + "-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dataVolumeName(tt.input)
+			if err != nil {
+				t.Fatalf("dataVolumeName(%q) error = %v", tt.input, err)
+			}
+			if !strings.HasPrefix(got, tt.wantPrefix) {
+				t.Errorf("dataVolumeName(%q) = %q, want prefix %q", tt.input, got, tt.wantPrefix)
+			}
+			if len(got) > 63 {
+				t.Errorf("dataVolumeName(%q) = %q is longer than 63 characters", tt.input, got)
+			}
+		})
+	}
+
+	a, _ := dataVolumeName("Hard disk 1")
+	b, _ := dataVolumeName("hard_disk_1")
+	if a == b {
+		t.Errorf("disks sanitizing to the same name got the same DataVolume name %q", a)
+	}
+}
+
+func TestSizeInGiB(t *testing.T) {
+	tests := []struct {
+		size int64
+		want int
+	}{
+		{size: 0, want: 1},
+		{size: 1, want: 2},
+		{size: bytesPerGiB, want: 2},
+		{size: 10*bytesPerGiB + 1, want: 12},
+	}
+	for _, tt := range tests {
+		if got := sizeInGiB(tt.size); got != tt.want {
+			t.Errorf("sizeInGiB(%d) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestNewDataVolume(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       dataVolumeSpec
+		wantSource string
+		wantClass  string
+	}{
+		{
+			name:       "upload with storage class",
+			spec:       dataVolumeSpec{Name: "disk-a", Namespace: "vms", SizeGiB: 21, StorageClass: "ceph-rbd"},
+			wantSource: "upload",
+			wantClass:  "ceph-rbd",
+		},
+		{
+			name:       "blank with default class",
+			spec:       dataVolumeSpec{Name: "probe", Namespace: "vms", SizeGiB: 1, Blank: true},
+			wantSource: "blank",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dv := newDataVolume(tt.spec)
+			if dv.GetKind() != "DataVolume" || dv.GetName() != tt.spec.Name || dv.GetNamespace() != tt.spec.Namespace {
+				t.Fatalf("unexpected object %s %s/%s", dv.GetKind(), dv.GetNamespace(), dv.GetName())
+			}
+			if _, found, _ := unstructured.NestedMap(dv.Object, "spec", "source", tt.wantSource); !found {
+				t.Errorf("source %q not set: %v", tt.wantSource, dv.Object["spec"])
+			}
+			class, _, _ := unstructured.NestedString(dv.Object, "spec", "storage", "storageClassName")
+			if class != tt.wantClass {
+				t.Errorf("storageClassName = %q, want %q", class, tt.wantClass)
+			}
+			size, _, _ := unstructured.NestedString(dv.Object, "spec", "storage", "resources", "requests", "storage")
+			if want := fmt.Sprintf("%dGi", tt.spec.SizeGiB); size != want {
+				t.Errorf("storage request = %q, want %q", size, want)
+			}
+		})
+	}
+}
+
+func TestNewVirtualMachine(t *testing.T) {
+	vm := newVirtualMachine(virtualMachineSpec{
+		Name:       "web01",
+		Namespace:  "vms",
+		SourceName: "Web 01",
+		CPU:        4,
+		MemoryMB:   8192,
+		UEFI:       true,
+		Disks: []vmDisk{
+			{DataVolume: "esp-abcde", BootOrder: 1},
+			{DataVolume: "data-fghij", Bus: "sata"},
+		},
+		Interfaces: []vmInterface{
+			{Network: "pod", MACAddress: "00:50:56:00:00:01"},
+			{Network: "vms/vlan100", MACAddress: "00:50:56:00:00:02"},
+		},
+		Metadata: `{"tag:env":"prod"}`,
+	})
+
+	if got, _, _ := unstructured.NestedString(vm.Object, "spec", "runStrategy"); got != runStrategyAlways {
+		t.Errorf("runStrategy = %q, want %q", got, runStrategyAlways)
+	}
+	if got := vm.GetAnnotations()[sourceVMAnnotation]; got != "Web 01" {
+		t.Errorf("source VM annotation = %q", got)
+	}
+	if got := vm.GetAnnotations()[targetMetadataAnnotation]; got != `{"tag:env":"prod"}` {
+		t.Errorf("target metadata annotation = %q", got)
+	}
+
+	domain, _, _ := unstructured.NestedMap(vm.Object, "spec", "template", "spec", "domain")
+	if cores, _, _ := unstructured.NestedInt64(domain, "cpu", "cores"); cores != 4 {
+		t.Errorf("cpu cores = %d, want 4", cores)
+	}
+	if mem, _, _ := unstructured.NestedString(domain, "memory", "guest"); mem != "8192Mi" {
+		t.Errorf("guest memory = %q, want 8192Mi", mem)
+	}
+	if secureBoot, found, _ := unstructured.NestedBool(domain, "firmware", "bootloader", "efi", "secureBoot"); !found || secureBoot {
+		t.Errorf("efi bootloader not set without secure boot: %v", domain["firmware"])
+	}
+
+	disks, _, _ := unstructured.NestedSlice(domain, "devices", "disks")
+	if len(disks) != 2 {
+		t.Fatalf("got %d disks, want 2", len(disks))
+	}
+	first := disks[0].(map[string]interface{})
+	if order, _, _ := unstructured.NestedInt64(first, "bootOrder"); order != 1 {
+		t.Errorf("boot disk bootOrder = %d, want 1", order)
+	}
+	if bus, _, _ := unstructured.NestedString(first, "disk", "bus"); bus != diskBusVirtio {
+		t.Errorf("default bus = %q, want %q", bus, diskBusVirtio)
+	}
+	second := disks[1].(map[string]interface{})
+	if _, found := second["bootOrder"]; found {
+		t.Errorf("data disk has a bootOrder")
+	}
+	if bus, _, _ := unstructured.NestedString(second, "disk", "bus"); bus != "sata" {
+		t.Errorf("data disk bus = %q, want sata", bus)
+	}
+
+	networks, _, _ := unstructured.NestedSlice(vm.Object, "spec", "template", "spec", "networks")
+	interfaces, _, _ := unstructured.NestedSlice(domain, "devices", "interfaces")
+	if len(networks) != 2 || len(interfaces) != 2 {
+		t.Fatalf("got %d networks and %d interfaces, want 2 of each", len(networks), len(interfaces))
+	}
+	if _, found := networks[0].(map[string]interface{})["pod"]; !found {
+		t.Errorf("first network is not the pod network: %v", networks[0])
+	}
+	if _, found := interfaces[0].(map[string]interface{})["masquerade"]; !found {
+		t.Errorf("pod network interface is not masquerade: %v", interfaces[0])
+	}
+	if name, _, _ := unstructured.NestedString(networks[1].(map[string]interface{}), "multus", "networkName"); name != "vms/vlan100" {
+		t.Errorf("multus networkName = %q, want vms/vlan100", name)
+	}
+	if mac, _, _ := unstructured.NestedString(interfaces[1].(map[string]interface{}), "macAddress"); mac != "00:50:56:00:00:02" {
+		t.Errorf("macAddress = %q", mac)
+	}
+	if _, found, _ := unstructured.NestedBool(domain, "devices", "autoattachPodInterface"); found {
+		t.Errorf("autoattachPodInterface set on a VM with NICs")
+	}
+}
+
+func TestNewVirtualMachineWithoutNICs(t *testing.T) {
+	vm := newVirtualMachine(virtualMachineSpec{Name: "isolated", Namespace: "vms", CPU: 1, MemoryMB: 1024,
+		Disks: []vmDisk{{DataVolume: "root", BootOrder: 1}}})
+	autoattach, found, _ := unstructured.NestedBool(vm.Object, "spec", "template", "spec", "domain", "devices", "autoattachPodInterface")
+	if !found || autoattach {
+		t.Errorf("autoattachPodInterface = %v (found %v), want false", autoattach, found)
+	}
+	if _, found, _ := unstructured.NestedMap(vm.Object, "spec", "template", "spec", "domain", "firmware"); found {
+		t.Errorf("firmware set on a BIOS VM")
+	}
+}
+
+func TestSplitNetworkName(t *testing.T) {
+	tests := []struct {
+		network       string
+		wantNamespace string
+		wantName      string
+		wantErr       bool
+	}{
+		{network: "vlan100", wantNamespace: "vms", wantName: "vlan100"},
+		{network: "net/vlan100", wantNamespace: "net", wantName: "vlan100"},
+		{network: "", wantErr: true},
+		{network: "a/b/c", wantErr: true},
+		{network: "/vlan100", wantErr: true},
+	}
+	for _, tt := range tests {
+		ns, name, err := splitNetworkName(tt.network, "vms")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitNetworkName(%q) error = %v, wantErr %v", tt.network, err, tt.wantErr)
+			continue
+		}
+		if ns != tt.wantNamespace || name != tt.wantName {
+			t.Errorf("splitNetworkName(%q) = %q, %q, want %q, %q", tt.network, ns, name, tt.wantNamespace, tt.wantName)
+		}
+	}
+}
+
+func TestPrintableStatusToServerStatus(t *testing.T) {
+	tests := map[string]string{
+		"Running":            "ACTIVE",
+		"Stopped":            "SHUTOFF",
+		"Starting":           "STARTING",
+		"ErrorUnschedulable": "ERRORUNSCHEDULABLE",
+		"":                   "",
+	}
+	for in, want := range tests {
+		if got := printableStatusToServerStatus(in); got != want {
+			t.Errorf("printableStatusToServerStatus(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"strings"
 	"time"
 
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/bandwidth"
 	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/kubevirt"
 	"github.com/platform9/vjailbreak/v2v-helper/migrate"
 	"github.com/platform9/vjailbreak/v2v-helper/nbd"
 	"github.com/platform9/vjailbreak/v2v-helper/openstack"
@@ -19,6 +22,7 @@ import (
 	"github.com/platform9/vjailbreak/v2v-helper/vcenter"
 	"github.com/platform9/vjailbreak/v2v-helper/vm"
 	"github.com/vmware/govmomi/vim25/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func main() {
@@ -94,22 +98,132 @@ func main() {
 	cutstart, _ := time.Parse(time.RFC3339, migrationparams.VMcutoverStart)
 	cutend, _ := time.Parse(time.RFC3339, migrationparams.VMcutoverEnd)
 
-	// Validate vCenter connection
+	// Parse network overrides if present
+	var networkOverrides []migrate.NICOverride
+	if migrationparams.NetworkOverrides != "" {
+		if err := json.Unmarshal([]byte(migrationparams.NetworkOverrides), &networkOverrides); err != nil {
+			handleError(fmt.Sprintf("Failed to parse network overrides: %v", err))
+			return
+		}
+	}
+
+	if strings.EqualFold(strings.TrimSpace(os.Getenv(constants.PromoteStagedVolumesEnv)), constants.TrueString) {
+		// The volumes were staged by an earlier data-only run, the source is not needed
+		openstackclients, err := newDestinationClients(ctx, client, migrationparams, openstackInsecure)
+		if err != nil {
+			handleError(err.Error())
+			return
+		}
+
+		migrationobj := migrate.Migrate{
+			Networknames:           utils.RemoveEmptyStrings(strings.Split(migrationparams.OpenstackNetworkNames, ",")),
+			Networkports:           utils.RemoveEmptyStrings(strings.Split(migrationparams.OpenstackNetworkPorts, ",")),
+			Ostype:                 migrationparams.OpenstackOSType,
+			Openstackclients:       openstackclients,
+			EventReporter:          eventReporterChan,
+			PodLabelWatcher:        podLabelWatcherChan,
+			LDMBootStatusWatcher:   ldmBootStatusChan,
+			InPod:                  reporter.IsRunningInPod(),
+			PerformHealthChecks:    migrationparams.PerformHealthChecks,
+			HealthCheckPort:        migrationparams.HealthCheckPort,
+			K8sClient:              client,
+			TargetFlavorId:         migrationparams.TARGET_FLAVOR_ID,
+			TargetAvailabilityZone: migrationparams.TargetAvailabilityZone,
+			SecurityGroups:         utils.RemoveEmptyStrings(strings.Split(migrationparams.SecurityGroups, ",")),
+			ServerGroup:            migrationparams.ServerGroup,
+			TenantName:             openstackProjectName,
+			Reporter:               eventReporter,
+			FallbackToDHCP:         migrationparams.FallbackToDHCP,
+			NetworkOverrides:       networkOverrides,
+			TargetMetadata:         utils.BuildTargetMetadata(migrationparams.SourceTagsMetadata, migrationparams.CustomMetadata),
+			DestinationType:        migrationparams.DestinationType,
+		}
+		if err := migrationobj.PromoteStagedVolumes(ctx); err != nil {
+			handleError(fmt.Sprintf("Failed to promote staged volumes: %v", err))
+			utils.PrintLog(fmt.Sprintf("----- Promotion completed with errors at %s for VM %s -----", time.Now().Format(time.RFC3339), migrationparams.SourceVMName))
+			return
+		}
+		utils.PrintLog(fmt.Sprintf("----- Promotion completed successfully at %s for VM %s -----", time.Now().Format(time.RFC3339), migrationparams.SourceVMName))
+		return
+	}
+
+	if migrationparams.SourceType == constants.SourceTypeAppliance || migrationparams.SourceType == constants.SourceTypeLibvirt ||
+		migrationparams.SourceType == constants.SourceTypeProxmox {
+		// None of these sources goes through vCenter, only the destination is needed
+		openstackclients, err := newDestinationClients(ctx, client, migrationparams, openstackInsecure)
+		if err != nil {
+			handleError(err.Error())
+			return
+		}
+
+		migrationobj := migrate.Migrate{
+			Networknames:           utils.RemoveEmptyStrings(strings.Split(migrationparams.OpenstackNetworkNames, ",")),
+			Volumetypes:            utils.RemoveEmptyStrings(strings.Split(migrationparams.OpenstackVolumeTypes, ",")),
+			Virtiowin:              migrationparams.OpenstackVirtioWin,
+			Ostype:                 migrationparams.OpenstackOSType,
+			Convert:                migrationparams.OpenstackConvert,
+			Openstackclients:       openstackclients,
+			EventReporter:          eventReporterChan,
+			PodLabelWatcher:        podLabelWatcherChan,
+			LDMBootStatusWatcher:   ldmBootStatusChan,
+			InPod:                  reporter.IsRunningInPod(),
+			K8sClient:              client,
+			TargetFlavorId:         migrationparams.TARGET_FLAVOR_ID,
+			TargetAvailabilityZone: migrationparams.TargetAvailabilityZone,
+			SecurityGroups:         utils.RemoveEmptyStrings(strings.Split(migrationparams.SecurityGroups, ",")),
+			TenantName:             openstackProjectName,
+			Reporter:               eventReporter,
+			FallbackToDHCP:         migrationparams.FallbackToDHCP,
+			DestinationType:        migrationparams.DestinationType,
+			Bandwidth:              migrationparams.Bandwidth,
+		}
+		migrationobj.StartBandwidthThrottle(ctx)
+		if migrationparams.SourceType == constants.SourceTypeProxmox {
+			if err := migrationobj.MigrateProxmoxVM(ctx, migrationparams.ProxmoxAPIURL, migrationparams.ProxmoxInsecure, migrationparams.ProxmoxVMID, migrationparams.SourceVMName); err != nil {
+				handleError(fmt.Sprintf("Failed to migrate Proxmox VM: %v", err))
+				utils.PrintLog(fmt.Sprintf("----- Proxmox migration completed with errors at %s for VM %s -----", time.Now().Format(time.RFC3339), migrationparams.ProxmoxVMID))
+				return
+			}
+			utils.PrintLog(fmt.Sprintf("----- Proxmox migration completed successfully at %s for VM %s -----", time.Now().Format(time.RFC3339), migrationparams.ProxmoxVMID))
+			return
+		}
+		if migrationparams.SourceType == constants.SourceTypeLibvirt {
+			if err := migrationobj.MigrateLibvirtDomain(ctx, migrationparams.LibvirtURI, migrationparams.LibvirtDomain, migrationparams.SourceVMName); err != nil {
+				handleError(fmt.Sprintf("Failed to migrate libvirt domain: %v", err))
+				utils.PrintLog(fmt.Sprintf("----- Libvirt migration completed with errors at %s for %s -----", time.Now().Format(time.RFC3339), migrationparams.LibvirtDomain))
+				return
+			}
+			utils.PrintLog(fmt.Sprintf("----- Libvirt migration completed successfully at %s for %s -----", time.Now().Format(time.RFC3339), migrationparams.LibvirtDomain))
+			return
+		}
+		if err := migrationobj.ImportAppliance(ctx, migrationparams.ApplianceFormat, migrationparams.ApplianceSource, migrationparams.SourceVMName); err != nil {
+			handleError(fmt.Sprintf("Failed to import appliance: %v", err))
+			utils.PrintLog(fmt.Sprintf("----- Appliance import completed with errors at %s for %s -----", time.Now().Format(time.RFC3339), migrationparams.ApplianceSource))
+			return
+		}
+		utils.PrintLog(fmt.Sprintf("----- Appliance import completed successfully at %s for %s -----", time.Now().Format(time.RFC3339), migrationparams.ApplianceSource))
+		return
+	}
+
+	// Validate vCenter connection. For a standalone ESXi source VCENTER_HOST is
+	// the ESXi host, whose host agent serves the same API for reading the VM.
+	sourceKind := "vCenter"
+	if migrationparams.SourceHostType == string(vjailbreakv1alpha1.VMwareHostTypeESXi) {
+		sourceKind = "ESXi host"
+	}
 	vcclient, err := vcenter.VCenterClientBuilder(ctx, vCenterUserName, vCenterPassword, vCenterURL, vCenterInsecure)
 	if err != nil {
-		handleError(fmt.Sprintf("Failed to validate vCenter connection: %v", err))
+		handleError(fmt.Sprintf("Failed to validate %s connection: %v", sourceKind, err))
 		return
 	}
-	utils.PrintLog(fmt.Sprintf("Connected to vCenter: %s\n", vCenterURL))
+	utils.PrintLog(fmt.Sprintf("Connected to %s: %s\n", sourceKind, vCenterURL))
 	defer vcclient.VCClient.CloseIdleConnections()
-	// Validate OpenStack connection
-	openstackclients, err := openstack.NewOpenStackClients(ctx, openstackInsecure)
+	// Validate the destination connection
+	openstackclients, err := newDestinationClients(ctx, client, migrationparams, openstackInsecure)
 	if err != nil {
-		handleError(fmt.Sprintf("Failed to validate OpenStack connection: %v", err))
+		handleError(err.Error())
 		return
 	}
-	openstackclients.K8sClient = client
-	utils.PrintLog("Connected to OpenStack")
 
 	// Get thumbprint
 	thumbprint, err := vcenter.GetThumbprint(vCenterURL)
@@ -125,14 +239,6 @@ func main() {
 		handleError(fmt.Sprintf("Failed to get source VM: %v", err))
 		return
 	}
-	// Parse network overrides if present
-	var networkOverrides []migrate.NICOverride
-	if migrationparams.NetworkOverrides != "" {
-		if err := json.Unmarshal([]byte(migrationparams.NetworkOverrides), &networkOverrides); err != nil {
-			handleError(fmt.Sprintf("Failed to parse network overrides: %v", err))
-			return
-		}
-	}
 
 	migrationobj := migrate.Migrate{
 		URL:                     vCenterURL,
@@ -184,11 +290,33 @@ func main() {
 		ProxyVMK8sName:         migrationparams.ProxyVMK8sName,
 		NetAppSVM:              migrationparams.NetAppSVM,
 		NetAppFlexVol:          migrationparams.NetAppFlexVol,
+		PowerStoreAppliance:    migrationparams.PowerStoreAppliance,
+		HPECPG:                 migrationparams.HPECPG,
+		SimulatorStateFile:     migrationparams.SimulatorStateFile,
+		SimulatorCreateDelay:   migrationparams.SimulatorCreateDelay,
+		SimulatorFailMapping:   migrationparams.SimulatorFailMapping,
+		SimulatorNAACollision:  migrationparams.SimulatorNAACollision,
+		ArrayNativeCopy:        migrationparams.ArrayNativeCopy,
 		NetworkOverrides:       networkOverrides,
 		ImageMetadata:          migrationparams.ImageMetadata,
 		TargetMetadata:         utils.BuildTargetMetadata(migrationparams.SourceTagsMetadata, migrationparams.CustomMetadata),
 		DataOnly:               migrationparams.DataOnly,
+		ExportTarget:           migrationparams.ExportTarget,
+		ExportCompress:         migrationparams.ExportCompress,
+		ExportImageVisibility:  migrationparams.ExportImageVisibility,
+		ExportKeepVolumes:      migrationparams.ExportKeepVolumes,
+		SourceHostType:         migrationparams.SourceHostType,
+		DestinationType:        migrationparams.DestinationType,
+		Bandwidth:              migrationparams.Bandwidth,
+		VerifyMode:             migrationparams.VerifyMode,
+		VerifySamplePercent:    migrationparams.VerifySamplePercent,
+		ParallelDiskCopies:     migrationparams.ParallelDiskCopies,
+		VDDKTransport: nbd.VDDKTransport{
+			Modes:       migrationparams.VDDKTransports,
+			Compression: migrationparams.VDDKCompression,
+		},
 	}
+	migrationobj.StartBandwidthThrottle(ctx)
 
 	if migrationobj.ServerGroup != "" {
 		utils.PrintLog(fmt.Sprintf("Server group configured: %s", migrationobj.ServerGroup))
@@ -224,6 +352,29 @@ func main() {
 	utils.PrintLog(fmt.Sprintf("----- Migration completed successfully at %s for VM %s -----", time.Now().Format(time.RFC3339), migrationparams.SourceVMName))
 }
 
+// newDestinationClients connects to the destination the VM is migrated to, a
+// KubeVirt namespace when DESTINATION_TYPE is kubevirt and OpenStack otherwise
+func newDestinationClients(ctx context.Context, k8sClient client.Client, migrationparams *utils.MigrationParams, openstackInsecure bool) (openstack.OpenstackOperations, error) {
+	if migrationparams.DestinationType == constants.DestinationTypeKubevirt {
+		if migrationparams.DataOnly {
+			return nil, fmt.Errorf("data-only migration is not supported on a KubeVirt destination")
+		}
+		kubevirtclients, err := kubevirt.NewKubevirtClients(ctx, k8sClient, migrationparams.KubevirtNamespace, migrationparams.KubevirtUploadProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to validate KubeVirt destination: %v", err)
+		}
+		utils.PrintLog(fmt.Sprintf("Using KubeVirt destination namespace %s", migrationparams.KubevirtNamespace))
+		return kubevirtclients, nil
+	}
+	openstackclients, err := openstack.NewOpenStackClients(ctx, openstackInsecure)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to validate OpenStack connection: %v", err)
+	}
+	openstackclients.K8sClient = k8sClient
+	utils.PrintLog("Connected to OpenStack")
+	return openstackclients, nil
+}
+
 func logMigrationParams(migrationparams *utils.MigrationParams) {
 	openstackAuthURL := strings.TrimSpace(os.Getenv("OS_AUTH_URL"))
 	utils.PrintLog(fmt.Sprintf(
@@ -246,7 +397,26 @@ REMOVE_VMWARE_TOOLS=%v
 STORAGE_COPY_METHOD=%v
 VENDOR_TYPE=%v
 ARRAY_CREDS_MAPPING=%v
-ACKNOWLEDGE_NETWORK_CONFLICT_RISK=%v`,
+ACKNOWLEDGE_NETWORK_CONFLICT_RISK=%v
+SOURCE_HOST_TYPE=%v
+SOURCE_TYPE=%v
+APPLIANCE_FORMAT=%v
+APPLIANCE_SOURCE=%v
+LIBVIRT_URI=%v
+LIBVIRT_DOMAIN=%v
+PROXMOX_API_URL=%v
+PROXMOX_VMID=%v
+DESTINATION_TYPE=%v
+KUBEVIRT_NAMESPACE=%v
+DATA_ONLY=%v
+EXPORT_TARGET=%v
+BANDWIDTH_LIMIT_MBPS=%v
+BANDWIDTH_SCHEDULE=%v
+VERIFY_MODE=%v
+VERIFY_SAMPLE_PERCENT=%v
+PARALLEL_DISK_COPIES=%v
+VDDK_TRANSPORTS=%v
+VDDK_COMPRESSION=%v`,
 		migrationparams.SourceVMName,
 		migrationparams.OpenstackOSType,
 		migrationparams.MigrationType,
@@ -266,5 +436,24 @@ ACKNOWLEDGE_NETWORK_CONFLICT_RISK=%v`,
 		migrationparams.VendorType,
 		migrationparams.ArrayCredsMapping,
 		migrationparams.AcknowledgeNetworkConflictRisk,
+		migrationparams.SourceHostType,
+		migrationparams.SourceType,
+		migrationparams.ApplianceFormat,
+		migrationparams.ApplianceSource,
+		migrationparams.LibvirtURI,
+		migrationparams.LibvirtDomain,
+		migrationparams.ProxmoxAPIURL,
+		migrationparams.ProxmoxVMID,
+		migrationparams.DestinationType,
+		migrationparams.KubevirtNamespace,
+		migrationparams.DataOnly,
+		migrationparams.ExportTarget,
+		migrationparams.Bandwidth.RateMbps,
+		bandwidth.FormatWindows(migrationparams.Bandwidth.Windows),
+		migrationparams.VerifyMode,
+		migrationparams.VerifySamplePercent,
+		migrationparams.ParallelDiskCopies,
+		strings.Join(migrationparams.VDDKTransports, ":"),
+		migrationparams.VDDKCompression,
 	))
 }
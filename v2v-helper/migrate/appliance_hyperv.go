@@ -0,0 +1,89 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/platform9/vjailbreak/pkg/common/hyperv"
+)
+
+// qemuImageInfo is the part of `qemu-img info --output=json` the import reads
+type qemuImageInfo struct {
+	Format          string `json:"format"`
+	VirtualSize     int64  `json:"virtual-size"`
+	BackingFilename string `json:"backing-filename"`
+}
+
+// readHyperVAppliance reads a Hyper-V export directory or configuration XML.
+// The configuration records neither the disk sizes nor the guest OS, the sizes
+// are read from the VHD/VHDX files and the OS family comes from the import.
+func readHyperVAppliance(ctx context.Context, localPath string) (*applianceSource, error) {
+	configPath, err := hyperv.FindConfig(localPath)
+	if err != nil {
+		return nil, err
+	}
+	config, err := hyperv.ReadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	src := &applianceSource{
+		name:     config.Name,
+		cpu:      config.CPU,
+		memoryMB: config.MemoryMB,
+		uefi:     config.UEFI,
+	}
+	if src.name == "" {
+		src.name = filepath.Base(hyperv.ExportRoot(configPath))
+	}
+	for _, nic := range config.NICs {
+		src.macs = append(src.macs, nic.MAC)
+	}
+	for idx, disk := range config.Disks {
+		image, err := hyperv.FindDisk(configPath, disk)
+		if err != nil {
+			return nil, err
+		}
+		info, err := readQemuImageInfo(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		if info.BackingFilename != "" {
+			return nil, errors.Errorf("disk %s is a differencing disk, merge it into its parent before exporting", filepath.Base(image))
+		}
+		src.disks = append(src.disks, applianceDisk{
+			name:   fmt.Sprintf("disk%d", idx),
+			size:   info.VirtualSize,
+			image:  image,
+			format: info.Format,
+		})
+	}
+	return src, nil
+}
+
+func readQemuImageInfo(ctx context.Context, image string) (*qemuImageInfo, error) {
+	//nolint:gosec // image was found inside the export directory
+	out, err := exec.CommandContext(ctx, "qemu-img", "info", "--output=json", image).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, errors.Errorf("failed to read %s: %s", filepath.Base(image), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, errors.Wrapf(err, "failed to read %s", filepath.Base(image))
+	}
+	info := &qemuImageInfo{}
+	if err := json.Unmarshal(out, info); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse image info of %s", filepath.Base(image))
+	}
+	if info.VirtualSize <= 0 {
+		return nil, errors.Errorf("disk %s reports no virtual size", filepath.Base(image))
+	}
+	return info, nil
+}
@@ -0,0 +1,404 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/pkg/common/ova"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+)
+
+// ImportAppliance creates an OpenStack instance from an appliance or an exported
+// VM that is not read from a live hypervisor. The descriptor or configuration
+// stands in for GetVMInfo, the disk images are written into freshly created
+// volumes with qemu-img, and from there the import follows the regular
+// conversion and instance creation path.
+func (migobj *Migrate) ImportAppliance(ctx context.Context, format, source, targetName string) error {
+	localPath, release, err := migobj.fetchApplianceSource(ctx, source)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch appliance")
+	}
+	defer release()
+
+	var appliance *applianceSource
+	switch format {
+	case string(vjailbreakv1alpha1.ApplianceFormatOVA):
+		appliance, err = readOVAAppliance(localPath)
+	case string(vjailbreakv1alpha1.ApplianceFormatHyperV):
+		appliance, err = readHyperVAppliance(ctx, localPath)
+	default:
+		return errors.Errorf("unsupported appliance format %q", format)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read appliance")
+	}
+	migobj.logMessage(fmt.Sprintf("Read appliance %s: %d CPU, %d MB memory, %d disk(s), %d NIC(s)",
+		appliance.name, appliance.cpu, appliance.memoryMB, len(appliance.disks), len(appliance.macs)))
+
+	vminfo, err := applianceVMInfo(appliance, migobj.Ostype, len(migobj.Networknames))
+	if err != nil {
+		return err
+	}
+	if targetName != "" {
+		vminfo.Name = targetName
+	}
+	return migobj.createInstanceFromDisks(ctx, vminfo, func(vminfo vm.VMInfo) error {
+		return migobj.writeApplianceDisks(ctx, appliance, vminfo)
+	})
+}
+
+// createInstanceFromDisks creates the volumes of vminfo, has writeDisks fill the
+// attached volumes at vminfo.VMDisks[i].Path, and converts them and creates the
+// instance as a VMware migration would. Everything created is cleaned up on failure.
+func (migobj *Migrate) createInstanceFromDisks(ctx context.Context, vminfo vm.VMInfo, writeDisks func(vm.VMInfo) error) error {
+	vminfo.TargetMetadata = migobj.TargetMetadata
+	migobj.Volumetypes = padVolumeTypes(migobj.Volumetypes, len(vminfo.VMDisks))
+
+	networkids, portids, ipaddresses, err := migobj.ReservePortsForVM(ctx, &vminfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to reserve ports")
+	}
+	vcenterSettings, err := k8sutils.GetVjailbreakSettings(ctx, migobj.K8sClient)
+	if err != nil {
+		return errors.Wrap(err, "failed to get vjailbreak settings")
+	}
+
+	vminfo, err = migobj.CreateVolumes(ctx, vminfo)
+	if err != nil {
+		if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to create volumes: %s", err), portids, vcenterSettings); cleanuperror != nil {
+			return errors.Wrapf(err, "failed to cleanup after volume creation failure: %s", cleanuperror)
+		}
+		return errors.Wrap(err, "failed to create volumes")
+	}
+	for idx, vmdisk := range vminfo.VMDisks {
+		devicePath, err := migobj.AttachVolume(ctx, vmdisk)
+		if err != nil {
+			if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to attach volume: %s", err), portids, vcenterSettings); cleanuperror != nil {
+				return errors.Wrapf(err, "failed to cleanup after attach failure: %s", cleanuperror)
+			}
+			return errors.Wrap(err, "failed to attach volume")
+		}
+		vminfo.VMDisks[idx].Path = devicePath
+	}
+
+	if err := writeDisks(vminfo); err != nil {
+		if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to write disks: %s", err), portids, vcenterSettings); cleanuperror != nil {
+			return errors.Wrapf(err, "failed to cleanup after disk write failure: %s", cleanuperror)
+		}
+		return errors.Wrap(err, "failed to write disks")
+	}
+
+	espDiskIndex, err := migobj.ConvertVolumes(ctx, vminfo)
+	if err != nil {
+		if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to convert disks: %s", err), portids, vcenterSettings); cleanuperror != nil {
+			return errors.Wrapf(err, "failed to cleanup disks: %s", cleanuperror)
+		}
+		return errors.Wrap(err, "failed to convert disks")
+	}
+
+	if err := migobj.CreateTargetInstance(ctx, vminfo, networkids, portids, ipaddresses, espDiskIndex); err != nil {
+		if serverID, recoveryErr := migobj.verifyVMCreatedDespiteTimeout(ctx, vminfo); recoveryErr == nil {
+			utils.PrintLog(fmt.Sprintf("VM created despite CreateTargetInstance error (%v), skipping cleanup", err))
+			migobj.logMessage(fmt.Sprintf("VM created successfully: ID: %s", serverID))
+			return nil
+		}
+		if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to create target instance: %s", err), portids, vcenterSettings); cleanuperror != nil {
+			return errors.Wrapf(err, "failed to cleanup disks: %s", cleanuperror)
+		}
+		return errors.Wrap(err, "failed to create target instance")
+	}
+	return nil
+}
+
+// applianceSource is what the import needs from an appliance, whatever its format
+type applianceSource struct {
+	name     string
+	cpu      int
+	memoryMB int
+	uefi     bool
+	// osFamily is windowsGuest or linuxGuest, empty when the format does not say
+	osFamily string
+	// macs holds one entry per NIC, empty for an address Neutron should assign
+	macs  []string
+	disks []applianceDisk
+}
+
+// applianceDisk is a disk image to write into a volume
+type applianceDisk struct {
+	name string
+	// size is the virtual size in bytes
+	size int64
+	// image is the disk file, or the member of archive when archive is set
+	image   string
+	archive string
+	// format is the qemu image format when the source records it
+	format string
+}
+
+// readOVAAppliance reads an .ova archive or an .ovf with its disks beside it
+func readOVAAppliance(localPath string) (*applianceSource, error) {
+	appliance, err := ova.ReadAppliance(localPath)
+	if err != nil {
+		return nil, err
+	}
+	src := &applianceSource{
+		name:     appliance.Name,
+		cpu:      appliance.CPU,
+		memoryMB: appliance.MemoryMB,
+		uefi:     appliance.UEFI,
+		osFamily: appliance.OSFamily,
+	}
+	for _, nic := range appliance.NICs {
+		src.macs = append(src.macs, nic.MAC)
+	}
+	for _, disk := range appliance.Disks {
+		d := applianceDisk{name: disk.ID, size: disk.Capacity}
+		if ova.IsArchive(localPath) {
+			d.image, d.archive = disk.Href, localPath
+		} else {
+			d.image = filepath.Join(filepath.Dir(localPath), filepath.Base(disk.Href))
+		}
+		src.disks = append(src.disks, d)
+	}
+	return src, nil
+}
+
+// applianceDownloadClient downloads appliances. The timeouts bound connecting
+// and waiting for the response; the body of a multi-GB appliance takes as
+// long as it takes, the import context cancels it.
+var applianceDownloadClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   30 * time.Second,
+		ResponseHeaderTimeout: time.Minute,
+	},
+}
+
+// fetchApplianceSource returns the local path of the appliance and a function
+// releasing it. A URL is downloaded into a file of the import directory
+// removed on release, a file share is mounted read-only for the duration of
+// the import.
+func (migobj *Migrate) fetchApplianceSource(ctx context.Context, source string) (string, func(), error) {
+	noop := func() {}
+	u, err := url.Parse(source)
+	if err != nil || u.Scheme == "" {
+		localPath := filepath.Join(constants.ApplianceImportDir, filepath.Clean("/"+source))
+		if _, err := os.Stat(localPath); err != nil {
+			return "", noop, errors.Wrapf(err, "appliance %s not found in %s", source, constants.ApplianceImportHostDir)
+		}
+		return localPath, noop, nil
+	}
+
+	switch u.Scheme {
+	case "nfs", "smb":
+		return migobj.mountApplianceShare(ctx, u)
+	case "http", "https":
+	default:
+		return "", noop, errors.Errorf("unsupported source scheme %q", u.Scheme)
+	}
+
+	migobj.logMessage(fmt.Sprintf("Downloading appliance from %s", u.Redacted()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return "", noop, err
+	}
+	resp, err := applianceDownloadClient.Do(req)
+	if err != nil {
+		return "", noop, errors.Wrap(err, "failed to download appliance")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", noop, errors.Errorf("failed to download appliance: %s", resp.Status)
+	}
+
+	// Every download gets a file of its own, keeping the extension the
+	// appliance readers go by
+	out, err := os.CreateTemp(constants.ApplianceImportDir, "import-*"+path.Ext(u.Path))
+	if err != nil {
+		return "", noop, errors.Wrap(err, "failed to create download file")
+	}
+	localPath := out.Name()
+	release := func() {
+		if err := os.Remove(localPath); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to remove downloaded appliance %s: %v", localPath, err))
+		}
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		release()
+		return "", noop, errors.Wrap(err, "failed to download appliance")
+	}
+	if err := out.Close(); err != nil {
+		release()
+		return "", noop, err
+	}
+	migobj.logMessage(fmt.Sprintf("Downloaded appliance to %s", localPath))
+	return localPath, release, nil
+}
+
+// mountApplianceShare mounts the directory holding the source on an NFS export
+// or SMB share and returns the path of the source below the mount point
+func (migobj *Migrate) mountApplianceShare(ctx context.Context, u *url.URL) (string, func(), error) {
+	noop := func() {}
+	mountPoint, err := os.MkdirTemp("", "appliance-share-")
+	if err != nil {
+		return "", noop, errors.Wrap(err, "failed to create mount point")
+	}
+	dir := path.Dir(path.Clean(u.Path))
+
+	var device, options string
+	var credentialsFile string
+	switch u.Scheme {
+	case "nfs":
+		device, options = u.Host+":"+dir, "ro"
+	case "smb":
+		device, options = "//"+u.Host+dir, "ro,guest"
+		if username := strings.TrimSpace(os.Getenv(constants.ApplianceSourceUsernameEnv)); username != "" {
+			// A credentials file keeps the password out of the command line and the logs
+			credentialsFile = mountPoint + ".credentials"
+			content := fmt.Sprintf("username=%s\npassword=%s\n", username, os.Getenv(constants.ApplianceSourcePasswordEnv))
+			if err := os.WriteFile(credentialsFile, []byte(content), 0o600); err != nil {
+				os.Remove(mountPoint)
+				return "", noop, errors.Wrap(err, "failed to write share credentials")
+			}
+			options = "ro,credentials=" + credentialsFile
+		}
+	}
+
+	release := func() {
+		//nolint:gosec // mountPoint is a temporary directory created here
+		if err := utils.RunCommandWithLogFile(exec.Command("umount", mountPoint)); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to unmount %s: %v", mountPoint, err))
+		}
+		os.Remove(mountPoint)
+		if credentialsFile != "" {
+			os.Remove(credentialsFile)
+		}
+	}
+
+	migobj.logMessage(fmt.Sprintf("Mounting %s share %s", u.Scheme, device))
+	fsType := map[string]string{"nfs": "nfs", "smb": "cifs"}[u.Scheme]
+	//nolint:gosec // device is built from the validated source URL
+	cmd := exec.CommandContext(ctx, "mount", "-t", fsType, "-o", options, device, mountPoint)
+	if err := utils.RunCommandWithLogFile(cmd); err != nil {
+		os.Remove(mountPoint)
+		if credentialsFile != "" {
+			os.Remove(credentialsFile)
+		}
+		return "", noop, errors.Wrapf(err, "failed to mount %s", device)
+	}
+
+	localPath := filepath.Join(mountPoint, path.Base(u.Path))
+	if _, err := os.Stat(localPath); err != nil {
+		release()
+		return "", noop, errors.Wrapf(err, "%s not found on %s", path.Base(u.Path), device)
+	}
+	return localPath, release, nil
+}
+
+// applianceVMInfo describes the appliance, or any other source read without
+// vCenter, the way GetVMInfo describes a VMware VM. NICs beyond the source, or
+// without a pinned address, get an empty MAC so Neutron assigns one.
+func applianceVMInfo(appliance *applianceSource, osTypeOverride string, networkCount int) (vm.VMInfo, error) {
+	osType := osTypeOverride
+	if osType == "" {
+		osType = appliance.osFamily
+	}
+	if osType == "" {
+		return vm.VMInfo{}, errors.Errorf("%s does not state its guest OS, set the OS family on the import", appliance.name)
+	}
+	if len(appliance.macs) > networkCount {
+		utils.PrintLog(fmt.Sprintf("%s has %d NIC(s) but %d network(s) were given, the extra NICs are dropped", appliance.name, len(appliance.macs), networkCount))
+	}
+
+	vminfo := vm.VMInfo{
+		Name:      appliance.name,
+		CPU:       int32(appliance.cpu),
+		Memory:    int32(appliance.memoryMB),
+		UEFI:      appliance.uefi,
+		OSType:    osType,
+		Mac:       make([]string, networkCount),
+		IPperMac:  map[string][]vm.IpEntry{},
+		GatewayIP: map[string]string{},
+	}
+	copy(vminfo.Mac, appliance.macs)
+	for _, disk := range appliance.disks {
+		vminfo.VMDisks = append(vminfo.VMDisks, vm.VMDisk{
+			Name: disk.name,
+			Size: disk.size,
+		})
+	}
+	return vminfo, nil
+}
+
+// padVolumeTypes repeats the last volume type for disks beyond the list. With
+// no volume types every volume gets the Cinder default type.
+func padVolumeTypes(volumetypes []string, diskCount int) []string {
+	last := ""
+	if len(volumetypes) > 0 {
+		last = volumetypes[len(volumetypes)-1]
+	}
+	padded := append([]string{}, volumetypes...)
+	for len(padded) < diskCount {
+		padded = append(padded, last)
+	}
+	return padded
+}
+
+// writeApplianceDisks writes every disk image of the appliance into its
+// attached volume at vminfo.VMDisks[i].Path
+func (migobj *Migrate) writeApplianceDisks(ctx context.Context, appliance *applianceSource, vminfo vm.VMInfo) error {
+	workDir, err := os.MkdirTemp(constants.ApplianceImportDir, "extract-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create work directory")
+	}
+	defer os.RemoveAll(workDir)
+
+	for idx, disk := range appliance.disks {
+		migobj.logMessage(fmt.Sprintf("Copying disk %d/%d: %s", idx+1, len(appliance.disks), filepath.Base(disk.image)))
+		image := disk.image
+		if disk.archive != "" {
+			image = filepath.Join(workDir, filepath.Base(disk.image))
+			if err := ova.ExtractFile(disk.archive, disk.image, image); err != nil {
+				return err
+			}
+		}
+
+		// The format is passed when known so that the image is not probed
+		args := []string{"convert", "-n"}
+		if disk.format != "" {
+			args = append(args, "-f", disk.format)
+		}
+		args = append(args, "-O", "raw", image, vminfo.VMDisks[idx].Path)
+		//nolint:gosec // image and the device path come from the parsed appliance and Cinder, not user input
+		cmd := exec.CommandContext(ctx, "qemu-img", args...)
+		if err := utils.RunCommandWithLogFile(cmd); err != nil {
+			return errors.Wrapf(err, "failed to write %s to %s", filepath.Base(disk.image), vminfo.VMDisks[idx].Path)
+		}
+		if disk.archive != "" {
+			if err := os.Remove(image); err != nil {
+				utils.PrintLog(fmt.Sprintf("Failed to remove extracted disk %s: %v", image, err))
+			}
+		}
+		migobj.logMessage(fmt.Sprintf("Copying disk %d, Completed: 100%%", idx))
+	}
+	return nil
+}
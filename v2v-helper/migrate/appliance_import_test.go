@@ -0,0 +1,115 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApplianceVMInfo(t *testing.T) {
+	appliance := &applianceSource{
+		name:     "firewall",
+		cpu:      2,
+		memoryMB: 4096,
+		uefi:     true,
+		osFamily: "linuxGuest",
+		macs:     []string{"00:50:56:ab:cd:ef", "", ""},
+		disks:    []applianceDisk{{name: "vmdisk1", size: 8 << 30, image: "firewall-disk1.vmdk"}},
+	}
+
+	vminfo, err := applianceVMInfo(appliance, "", 2)
+	if err != nil {
+		t.Fatalf("applianceVMInfo() unexpected error: %v", err)
+	}
+	if vminfo.Name != "firewall" || vminfo.CPU != 2 || vminfo.Memory != 4096 || !vminfo.UEFI || vminfo.OSType != "linuxGuest" {
+		t.Errorf("applianceVMInfo() = %+v, want the descriptor hardware", vminfo)
+	}
+	if want := []string{"00:50:56:ab:cd:ef", ""}; !reflect.DeepEqual(vminfo.Mac, want) {
+		t.Errorf("Mac = %v, want %v", vminfo.Mac, want)
+	}
+	if len(vminfo.VMDisks) != 1 || vminfo.VMDisks[0].Name != "vmdisk1" || vminfo.VMDisks[0].Size != 8<<30 {
+		t.Errorf("VMDisks = %+v, want one 8GiB disk", vminfo.VMDisks)
+	}
+	if vminfo.IPperMac == nil || vminfo.GatewayIP == nil {
+		t.Error("IPperMac and GatewayIP must be initialised for port creation")
+	}
+
+	vminfo, err = applianceVMInfo(appliance, "windowsGuest", 4)
+	if err != nil {
+		t.Fatalf("applianceVMInfo() unexpected error: %v", err)
+	}
+	if vminfo.OSType != "windowsGuest" {
+		t.Errorf("OSType = %q, want the override", vminfo.OSType)
+	}
+	if len(vminfo.Mac) != 4 || vminfo.Mac[3] != "" {
+		t.Errorf("Mac = %v, want one entry per network", vminfo.Mac)
+	}
+
+	appliance.osFamily = ""
+	if _, err := applianceVMInfo(appliance, "", 1); err == nil {
+		t.Error("applianceVMInfo() without an OS family succeeded, want error")
+	}
+}
+
+func TestPadVolumeTypes(t *testing.T) {
+	tests := []struct {
+		in        []string
+		diskCount int
+		want      []string
+	}{
+		{in: []string{"ssd"}, diskCount: 3, want: []string{"ssd", "ssd", "ssd"}},
+		{in: []string{"ssd", "hdd"}, diskCount: 3, want: []string{"ssd", "hdd", "hdd"}},
+		{in: []string{"ssd", "hdd"}, diskCount: 1, want: []string{"ssd", "hdd"}},
+		{in: nil, diskCount: 2, want: []string{"", ""}},
+	}
+	for _, tt := range tests {
+		if got := padVolumeTypes(tt.in, tt.diskCount); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("padVolumeTypes(%v, %d) = %v, want %v", tt.in, tt.diskCount, got, tt.want)
+		}
+	}
+}
+
+func TestReadOVAAppliance(t *testing.T) {
+	dir := t.TempDir()
+	descriptor := `<?xml version="1.0" encoding="UTF-8"?>
+<Envelope xmlns="http://schemas.dmtf.org/ovf/envelope/1" xmlns:ovf="http://schemas.dmtf.org/ovf/envelope/1" xmlns:rasd="http://schemas.dmtf.org/wbem/wscim/1/cim-schema/2/CIM_ResourceAllocationSettingData">
+  <References>
+    <File ovf:href="firewall-disk1.vmdk" ovf:id="file1"/>
+  </References>
+  <DiskSection>
+    <Info>Virtual disk information</Info>
+    <Disk ovf:capacity="8" ovf:capacityAllocationUnits="byte * 2^30" ovf:diskId="vmdisk1" ovf:fileRef="file1"/>
+  </DiskSection>
+  <VirtualSystem ovf:id="firewall">
+    <Info>A virtual machine</Info>
+    <VirtualHardwareSection>
+      <Info>Virtual hardware requirements</Info>
+      <Item>
+        <rasd:ElementName>2 virtual CPU(s)</rasd:ElementName>
+        <rasd:InstanceID>1</rasd:InstanceID>
+        <rasd:ResourceType>3</rasd:ResourceType>
+        <rasd:VirtualQuantity>2</rasd:VirtualQuantity>
+      </Item>
+    </VirtualHardwareSection>
+  </VirtualSystem>
+</Envelope>`
+	ovfPath := filepath.Join(dir, "firewall.ovf")
+	if err := os.WriteFile(ovfPath, []byte(descriptor), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	appliance, err := readOVAAppliance(ovfPath)
+	if err != nil {
+		t.Fatalf("readOVAAppliance() unexpected error: %v", err)
+	}
+	want := applianceDisk{name: "vmdisk1", size: 8 << 30, image: filepath.Join(dir, "firewall-disk1.vmdk")}
+	if len(appliance.disks) != 1 || appliance.disks[0] != want {
+		t.Errorf("disks = %+v, want [%+v]", appliance.disks, want)
+	}
+	if appliance.name != "firewall" || appliance.cpu != 2 {
+		t.Errorf("readOVAAppliance() = %+v, want firewall with 2 CPU", appliance)
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	cindervolumes "github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// vmfsFuseCommands are the FUSE drivers that can mount a VMFS clone read-only
+// in this pod, in order of preference. vmfs6-fuse reads VMFS 6, vmfs-fuse
+// only VMFS 5 and older. The image ships neither yet, so ArrayCreds validation
+// rejects spec.arrayNativeCopy.
+var vmfsFuseCommands = []string{"vmfs6-fuse", "vmfs-fuse"}
+
+// useArrayNativeCopy reports whether the disks are extracted from an array
+// clone of their datastore rather than cloned with vmkfstools
+func (migobj *Migrate) useArrayNativeCopy() bool {
+	if !migobj.ArrayNativeCopy || migobj.StorageProvider == nil {
+		return false
+	}
+	_, ok := migobj.StorageProvider.(storage.ArrayCloner)
+	return ok
+}
+
+// ArrayNativeCopyDisks copies the disks of vminfo without any data going
+// through the ESXi host. The LUN of every datastore holding the disks is
+// snapshotted and cloned on the array, the clone is attached to this VM
+// through Cinder and mounted with a VMFS FUSE driver, and each VMDK is written
+// from it onto a new Cinder managed volume of the array. Like
+// cloneDisksToArray, the volumes are left unattached.
+func (migobj *Migrate) ArrayNativeCopyDisks(ctx context.Context, vminfo vm.VMInfo) ([]storage.Volume, error) {
+	migobj.logMessage("Starting array-native copy from array clones of the VM datastores")
+
+	fuseCommand, err := vmfsFuseCommand()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migobj.InitializeStorageProvider(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to initialize storage provider")
+	}
+	defer migobj.StorageProvider.Disconnect()
+
+	cloner, ok := migobj.StorageProvider.(storage.ArrayCloner)
+	if !ok {
+		return nil, fmt.Errorf("%s arrays do not support snapshot clones", migobj.VendorType)
+	}
+
+	volumes := make([]storage.Volume, len(vminfo.VMDisks))
+	datastores, disksByDatastore := groupDisksByDatastore(vminfo.VMDisks)
+	for _, datastore := range datastores {
+		err := migobj.copyDisksFromDatastoreClone(ctx, cloner, fuseCommand, &vminfo, datastore, disksByDatastore[datastore], volumes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to copy the disks of datastore %s", datastore)
+		}
+	}
+
+	migobj.logMessage("Array-native copy completed successfully")
+	return volumes, nil
+}
+
+// copyDisksFromDatastoreClone clones the LUN of datastore and writes the disks
+// idxs of vminfo from the clone onto their volumes. The snapshot and the clone
+// are removed afterwards.
+func (migobj *Migrate) copyDisksFromDatastoreClone(ctx context.Context, cloner storage.ArrayCloner, fuseCommand string,
+	vminfo *vm.VMInfo, datastore string, idxs []int, volumes []storage.Volume,
+) error {
+	firstDisk := vminfo.VMDisks[idxs[0]]
+	naa, err := migobj.datastoreBackingNAA(ctx, firstDisk)
+	if err != nil {
+		return err
+	}
+	lun, err := cloner.GetVolumeFromNAA(naa)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find the LUN %s of datastore %s on the array", naa, datastore)
+	}
+
+	snapshotName := sanitizeVolumeName(vminfo.Name + "-" + datastore + "-snap")
+	migobj.logMessage(fmt.Sprintf("Taking array snapshot %s of LUN %s", snapshotName, lun.Name))
+	snapshot, err := cloner.CreateSnapshot(lun, snapshotName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to snapshot LUN %s", lun.Name)
+	}
+	defer func() {
+		if err := cloner.DeleteSnapshot(snapshot); err != nil {
+			utils.PrintLog(fmt.Sprintf("Warning: Failed to delete array snapshot %s: %v", snapshot.Name, err))
+		}
+	}()
+
+	cloneName := sanitizeVolumeName(vminfo.Name + "-" + datastore + "-clone")
+	migobj.logMessage(fmt.Sprintf("Cloning array snapshot %s to volume %s", snapshot.Name, cloneName))
+	clone, err := cloner.CloneSnapshot(snapshot, cloneName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to clone snapshot %s", snapshot.Name)
+	}
+
+	// The clone reaches this VM the way the target volumes do, through Cinder
+	cloneVolumeID, err := migobj.manageVolumeToCinder(ctx, clone.Name, firstDisk)
+	if err != nil {
+		if delErr := migobj.StorageProvider.DeleteVolume(clone.Name); delErr != nil {
+			utils.PrintLog(fmt.Sprintf("Warning: Failed to delete datastore clone %s: %v", clone.Name, delErr))
+		}
+		return errors.Wrapf(err, "failed to Cinder manage datastore clone %s", clone.Name)
+	}
+	cloneDisk := vm.VMDisk{
+		Name:         clone.Name,
+		OpenstackVol: &cindervolumes.Volume{ID: cloneVolumeID, Name: clone.Name},
+	}
+	defer func() {
+		if err := migobj.Openstackclients.DeleteVolume(ctx, cloneVolumeID); err != nil {
+			utils.PrintLog(fmt.Sprintf("Warning: Failed to delete datastore clone volume %s: %v", cloneVolumeID, err))
+		}
+	}()
+
+	cloneDevice, err := migobj.AttachVolume(ctx, cloneDisk)
+	if err != nil {
+		return errors.Wrapf(err, "failed to attach datastore clone %s", clone.Name)
+	}
+	defer func() {
+		if err := migobj.DetachVolume(ctx, cloneDisk); err != nil {
+			utils.PrintLog(fmt.Sprintf("Warning: Failed to detach datastore clone %s: %v", clone.Name, err))
+		}
+	}()
+
+	mountPoint, err := os.MkdirTemp("", "vmfs-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create mount point")
+	}
+	defer os.Remove(mountPoint)
+	migobj.logMessage(fmt.Sprintf("Mounting datastore clone %s on %s with %s", cloneDevice, mountPoint, fuseCommand))
+	//nolint:gosec // the device comes from Cinder and the mount point is created here
+	if err := utils.RunCommandWithLogFile(exec.CommandContext(ctx, fuseCommand, cloneDevice, mountPoint)); err != nil {
+		return errors.Wrapf(err, "failed to mount datastore clone %s", cloneDevice)
+	}
+	defer func() {
+		//nolint:gosec // mountPoint is a temporary directory created here
+		if err := utils.RunCommandWithLogFile(exec.Command("umount", mountPoint)); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to unmount %s: %v", mountPoint, err))
+		}
+	}()
+
+	for _, idx := range idxs {
+		volume, err := migobj.extractDiskFromClone(ctx, idx, vminfo, mountPoint)
+		if err != nil {
+			return errors.Wrapf(err, "failed to copy disk %s", vminfo.VMDisks[idx].Name)
+		}
+		volumes[idx] = volume
+	}
+	return nil
+}
+
+// extractDiskFromClone creates the volume of disk idx of vminfo and writes the
+// VMDK of the disk, read from the datastore clone mounted on mountPoint, to it
+func (migobj *Migrate) extractDiskFromClone(ctx context.Context, idx int, vminfo *vm.VMInfo, mountPoint string) (storage.Volume, error) {
+	startTime := time.Now()
+	vmDisk := vminfo.VMDisks[idx]
+	source, err := vmdkPathOnMount(mountPoint, migobj.storageCopySource(vmDisk))
+	if err != nil {
+		return storage.Volume{}, err
+	}
+
+	targetVolume, _, err := migobj.createManagedTargetVolume(ctx, idx, vminfo)
+	if err != nil {
+		return storage.Volume{}, err
+	}
+	devicePath, err := migobj.AttachVolume(ctx, vminfo.VMDisks[idx])
+	if err != nil {
+		return storage.Volume{}, errors.Wrapf(err, "failed to attach volume for disk %s", vmDisk.Name)
+	}
+	defer func() {
+		if err := migobj.DetachVolume(ctx, vminfo.VMDisks[idx]); err != nil {
+			utils.PrintLog(fmt.Sprintf("Warning: Failed to detach volume for disk %s: %v", vmDisk.Name, err))
+		}
+	}()
+
+	migobj.logMessage(fmt.Sprintf("Writing %s from the datastore clone to %s", source, devicePath))
+	//nolint:gosec // the source is on the clone mounted here and the device comes from Cinder
+	cmd := exec.CommandContext(ctx, "qemu-img", "convert", "-n", "-f", "vmdk", "-O", "raw", source, devicePath)
+	if err := utils.RunCommandWithLogFile(cmd); err != nil {
+		return storage.Volume{}, errors.Wrapf(err, "failed to write %s to %s", source, devicePath)
+	}
+	migobj.recordTransfer(vmDisk.Name, vjailbreakv1alpha1.DiskTransferMethodStorageAccelerated, vmDisk.Size, vmDisk.Size)
+	migobj.logMessage(fmt.Sprintf("Array-native copy completed in %s for disk %s", time.Since(startTime).Round(time.Second), vmDisk.Name))
+	return targetVolume, nil
+}
+
+// datastoreBackingNAA returns the NAA of the LUN backing the datastore of
+// vmdisk, which must be a VMFS datastore on that one LUN
+func (migobj *Migrate) datastoreBackingNAA(ctx context.Context, vmdisk vm.VMDisk) (string, error) {
+	if vmdisk.DatastoreID == "" {
+		return "", fmt.Errorf("the datastore of disk %s is unknown", vmdisk.Name)
+	}
+	vcclient, err := migobj.vcenterClient()
+	if err != nil {
+		return "", err
+	}
+	ds := object.NewDatastore(vcclient.VCClient, types.ManagedObjectReference{Type: "Datastore", Value: vmdisk.DatastoreID})
+	var dsProps mo.Datastore
+	if err := ds.Properties(ctx, ds.Reference(), []string{"info"}, &dsProps); err != nil {
+		return "", errors.Wrapf(err, "failed to get properties of datastore %s", vmdisk.Datastore)
+	}
+	return singleExtentNAA(vmdisk.Datastore, dsProps.Info)
+}
+
+// singleExtentNAA returns the NAA of the only extent of a VMFS datastore. An
+// array clone of one LUN would hold a partial copy of a datastore spanning
+// several.
+func singleExtentNAA(datastore string, info types.BaseDatastoreInfo) (string, error) {
+	vmfsInfo, ok := info.(*types.VmfsDatastoreInfo)
+	if !ok || vmfsInfo.Vmfs == nil {
+		return "", fmt.Errorf("datastore %s is not a VMFS datastore", datastore)
+	}
+	if len(vmfsInfo.Vmfs.Extent) != 1 {
+		return "", fmt.Errorf("datastore %s spans %d extents, the array-native copy needs a datastore on a single LUN",
+			datastore, len(vmfsInfo.Vmfs.Extent))
+	}
+	return vmfsInfo.Vmfs.Extent[0].DiskName, nil
+}
+
+// groupDisksByDatastore returns the datastores of disks, in the order they
+// first appear, and the indexes of the disks on each of them
+func groupDisksByDatastore(disks []vm.VMDisk) ([]string, map[string][]int) {
+	var datastores []string
+	idxs := map[string][]int{}
+	for idx, disk := range disks {
+		if _, ok := idxs[disk.Datastore]; !ok {
+			datastores = append(datastores, disk.Datastore)
+		}
+		idxs[disk.Datastore] = append(idxs[disk.Datastore], idx)
+	}
+	return datastores, idxs
+}
+
+// vmdkPathOnMount maps the datastore path of a VMDK, "[datastore] dir/disk.vmdk",
+// to its path on the datastore clone mounted on mountPoint
+func vmdkPathOnMount(mountPoint, datastorePath string) (string, error) {
+	var p object.DatastorePath
+	if !p.FromString(datastorePath) || p.Path == "" {
+		return "", fmt.Errorf("invalid datastore path %q", datastorePath)
+	}
+	return filepath.Join(mountPoint, filepath.Clean("/"+p.Path)), nil
+}
+
+// vmfsFuseCommand returns the VMFS FUSE driver installed on the agent
+func vmfsFuseCommand() (string, error) {
+	for _, command := range vmfsFuseCommands {
+		if _, err := exec.LookPath(command); err == nil {
+			return command, nil
+		}
+	}
+	return "", fmt.Errorf("the array-native copy needs one of %v on the vjailbreak agent to read the datastore clone", vmfsFuseCommands)
+}
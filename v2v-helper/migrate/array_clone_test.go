@@ -0,0 +1,68 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"testing"
+
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestGroupDisksByDatastore(t *testing.T) {
+	disks := []vm.VMDisk{
+		{Name: "disk1", Datastore: "ds2"},
+		{Name: "disk2", Datastore: "ds1"},
+		{Name: "disk3", Datastore: "ds2"},
+	}
+	datastores, idxs := groupDisksByDatastore(disks)
+	assert.Equal(t, []string{"ds2", "ds1"}, datastores)
+	assert.Equal(t, map[string][]int{"ds2": {0, 2}, "ds1": {1}}, idxs)
+}
+
+func TestVMDKPathOnMount(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "disk in VM directory", path: "[ds1] vm1/vm1.vmdk", want: "/mnt/vmfs/vm1/vm1.vmdk"},
+		{name: "datastore name with spaces", path: "[Shared DS] vm 1/vm 1_1.vmdk", want: "/mnt/vmfs/vm 1/vm 1_1.vmdk"},
+		{name: "path escaping the mount", path: "[ds1] ../../etc/passwd", want: "/mnt/vmfs/etc/passwd"},
+		{name: "no datastore", path: "vm1/vm1.vmdk", wantErr: true},
+		{name: "datastore root", path: "[ds1]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := vmdkPathOnMount("/mnt/vmfs", tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSingleExtentNAA(t *testing.T) {
+	vmfs := func(extents ...string) *types.VmfsDatastoreInfo {
+		info := &types.VmfsDatastoreInfo{Vmfs: &types.HostVmfsVolume{}}
+		for _, extent := range extents {
+			info.Vmfs.Extent = append(info.Vmfs.Extent, types.HostScsiDiskPartition{DiskName: extent, Partition: 1})
+		}
+		return info
+	}
+
+	naa, err := singleExtentNAA("ds1", vmfs("naa.624a9370abcdef"))
+	assert.NoError(t, err)
+	assert.Equal(t, "naa.624a9370abcdef", naa)
+
+	_, err = singleExtentNAA("ds1", vmfs("naa.624a9370abcdef", "naa.624a9370fedcba"))
+	assert.ErrorContains(t, err, "spans 2 extents")
+
+	_, err = singleExtentNAA("nfs1", &types.NasDatastoreInfo{})
+	assert.ErrorContains(t, err, "not a VMFS datastore")
+}
@@ -0,0 +1,120 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+	// the schedule's time zone must load whatever zoneinfo the image ships
+	_ "time/tzdata"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/bandwidth"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/nbd"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bandwidthCopyPhases are the phases in which a migration moves disk data over
+// the network and so takes a share of the global bandwidth cap
+var bandwidthCopyPhases = map[vjailbreakv1alpha1.VMMigrationPhase]bool{
+	vjailbreakv1alpha1.VMMigrationPhaseCopying:              true,
+	vjailbreakv1alpha1.VMMigrationPhaseCopyingChangedBlocks: true,
+	vjailbreakv1alpha1.VMMigrationPhaseHotAddTransferring:   true,
+}
+
+// StartBandwidthThrottle sets up migobj.Throttle and keeps its rate in step with
+// the MigrationPlan cap, the time of day, the global cap and the number of
+// migrations sharing it until ctx is done. The throttle is set up even without
+// any cap, unlimited, so a global cap or schedule added to the vjailbreak
+// settings later also slows down the migrations already copying.
+func (migobj *Migrate) StartBandwidthThrottle(ctx context.Context) {
+	migobj.Throttle = nbd.NewThrottle()
+	migobj.refreshBandwidth(ctx, migobj.globalBandwidth(ctx))
+	go func() {
+		ticker := time.NewTicker(constants.BandwidthRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				migobj.refreshBandwidth(ctx, migobj.globalBandwidth(ctx))
+			}
+		}
+	}()
+}
+
+// refreshBandwidth applies the cap in effect now to migobj.Throttle
+func (migobj *Migrate) refreshBandwidth(ctx context.Context, global bandwidth.Schedule) {
+	copying := 1
+	if !global.IsUnlimited() {
+		copying = migobj.countCopyingMigrations(ctx)
+	}
+	migobj.Throttle.SetRate(effectiveBandwidth(time.Now().In(applianceLocation()), migobj.Bandwidth, global, copying))
+}
+
+// applianceLocation returns the appliance time zone that bandwidth schedules are
+// written in. It reaches the pod as TZ through the pf9-env ConfigMap, an unset
+// or unknown zone is logged and falls back to the pod's local time.
+func applianceLocation() *time.Location {
+	tz := os.Getenv("TZ")
+	if tz == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		utils.PrintLog(fmt.Sprintf("Evaluating bandwidth schedules in local time, unknown time zone %q: %v", tz, err))
+		return time.Local
+	}
+	return loc
+}
+
+// effectiveBandwidth returns the tighter of the migration's own cap and an even
+// share of the global cap between the migrations copying data, 0 is unlimited
+func effectiveBandwidth(now time.Time, own, global bandwidth.Schedule, copying int) int {
+	share := global.RateAt(now)
+	if share > 0 && copying > 1 {
+		share = max(share/copying, 1)
+	}
+	return bandwidth.Min(own.RateAt(now), share)
+}
+
+// globalBandwidth reads the global cap from the vjailbreak settings. A settings
+// read failure or a malformed schedule is logged and leaves that part unlimited.
+func (migobj *Migrate) globalBandwidth(ctx context.Context) bandwidth.Schedule {
+	if migobj.K8sClient == nil {
+		return bandwidth.Schedule{}
+	}
+	settings, err := k8sutils.GetVjailbreakSettings(ctx, migobj.K8sClient)
+	if err != nil {
+		utils.PrintLog(fmt.Sprintf("Failed to read the global bandwidth cap: %v", err))
+		return bandwidth.Schedule{}
+	}
+	global := bandwidth.Schedule{RateMbps: settings.GlobalBandwidthLimitMbps}
+	if global.Windows, err = bandwidth.ParseWindows(settings.GlobalBandwidthSchedule); err != nil {
+		utils.PrintLog(fmt.Sprintf("Ignoring %s: %v", constants.GlobalBandwidthScheduleKey, err))
+	}
+	return global
+}
+
+// countCopyingMigrations returns how many migrations are copying disk data,
+// counting this one even if it has not reported a copy phase yet
+func (migobj *Migrate) countCopyingMigrations(ctx context.Context) int {
+	migrations := &vjailbreakv1alpha1.MigrationList{}
+	if err := migobj.K8sClient.List(ctx, migrations, client.InNamespace(constants.NamespaceMigrationSystem)); err != nil {
+		utils.PrintLog(fmt.Sprintf("Failed to list migrations sharing the global bandwidth cap: %v", err))
+		return 1
+	}
+	copying := 0
+	for _, migration := range migrations.Items {
+		if bandwidthCopyPhases[migration.Status.Phase] {
+			copying++
+		}
+	}
+	return max(copying, 1)
+}
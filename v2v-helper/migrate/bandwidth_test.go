@@ -0,0 +1,130 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/bandwidth"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEffectiveBandwidth(t *testing.T) {
+	noon := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	midnight := time.Date(2026, 1, 1, 0, 30, 0, 0, time.Local)
+	// full speed at night, 200 Mbps during the day
+	dayCap := bandwidth.Schedule{Windows: []bandwidth.Window{{Start: 8 * 60, End: 18 * 60, RateMbps: 200}}}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		own     bandwidth.Schedule
+		global  bandwidth.Schedule
+		copying int
+		want    int
+	}{
+		{name: "no caps", now: noon, copying: 3, want: 0},
+		{name: "own schedule during the day", now: noon, own: dayCap, copying: 1, want: 200},
+		{name: "own schedule at night", now: midnight, own: dayCap, copying: 1, want: 0},
+		{name: "global cap shared", now: noon, global: bandwidth.Schedule{RateMbps: 1000}, copying: 4, want: 250},
+		{name: "tighter own cap wins", now: noon, own: dayCap, global: bandwidth.Schedule{RateMbps: 1000}, copying: 2, want: 200},
+		{name: "tighter global share wins", now: noon, own: dayCap, global: bandwidth.Schedule{RateMbps: 300}, copying: 3, want: 100},
+		{name: "share never drops to unlimited", now: noon, global: bandwidth.Schedule{RateMbps: 2}, copying: 5, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := effectiveBandwidth(tt.now, tt.own, tt.global, tt.copying); got != tt.want {
+				t.Errorf("effectiveBandwidth() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartBandwidthThrottle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := vjailbreakv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	migration := func(name string, phase vjailbreakv1alpha1.VMMigrationPhase) *vjailbreakv1alpha1.Migration {
+		return &vjailbreakv1alpha1.Migration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: constants.NamespaceMigrationSystem},
+			Status:     vjailbreakv1alpha1.MigrationStatus{Phase: phase},
+		}
+	}
+	settings := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.VjailbreakSettingsConfigMapName, Namespace: constants.NamespaceMigrationSystem},
+			Data:       data,
+		}
+	}
+
+	t.Run("unlimited", func(t *testing.T) {
+		migobj := &Migrate{K8sClient: ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(settings(map[string]string{})).Build()}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		migobj.StartBandwidthThrottle(ctx)
+		if migobj.Throttle == nil {
+			t.Fatal("Throttle not set up without a bandwidth cap")
+		}
+		if got := migobj.Throttle.RateMbps(); got != 0 {
+			t.Errorf("RateMbps() = %d, want 0", got)
+		}
+	})
+
+	t.Run("global cap added while copying", func(t *testing.T) {
+		k8sClient := ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(settings(map[string]string{})).Build()
+		migobj := &Migrate{K8sClient: k8sClient}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		migobj.StartBandwidthThrottle(ctx)
+
+		cm := settings(map[string]string{constants.GlobalBandwidthLimitMbpsKey: "100"})
+		if err := k8sClient.Update(ctx, cm); err != nil {
+			t.Fatal(err)
+		}
+		migobj.refreshBandwidth(ctx, migobj.globalBandwidth(ctx))
+		if got := migobj.Throttle.RateMbps(); got != 100 {
+			t.Errorf("RateMbps() = %d, want 100", got)
+		}
+	})
+
+	t.Run("global cap shared by copying migrations", func(t *testing.T) {
+		k8sClient := ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			settings(map[string]string{constants.GlobalBandwidthLimitMbpsKey: "300"}),
+			migration("migration-a", vjailbreakv1alpha1.VMMigrationPhaseCopying),
+			migration("migration-b", vjailbreakv1alpha1.VMMigrationPhaseCopyingChangedBlocks),
+			migration("migration-c", vjailbreakv1alpha1.VMMigrationPhaseSucceeded),
+		).Build()
+		migobj := &Migrate{K8sClient: k8sClient}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		migobj.StartBandwidthThrottle(ctx)
+		if migobj.Throttle == nil {
+			t.Fatal("Throttle not set up for a global cap")
+		}
+		if got := migobj.Throttle.RateMbps(); got != 150 {
+			t.Errorf("RateMbps() = %d, want 150", got)
+		}
+	})
+}
+
+func TestApplianceLocation(t *testing.T) {
+	t.Setenv("TZ", "Asia/Kolkata")
+	if got := applianceLocation().String(); got != "Asia/Kolkata" {
+		t.Errorf("applianceLocation() = %s, want Asia/Kolkata", got)
+	}
+	t.Setenv("TZ", "Not/AZone")
+	if got := applianceLocation(); got != time.Local {
+		t.Errorf("applianceLocation() = %s for an unknown zone, want local time", got)
+	}
+}
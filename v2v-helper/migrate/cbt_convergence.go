@@ -0,0 +1,220 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// finalSyncOverhead is the part of the cutover downtime that does not depend
+	// on the delta: powering off the VM, the last snapshot and the NBD restarts
+	finalSyncOverhead = time.Minute
+	// minSyncInterval is the shortest wait between periodic syncs
+	minSyncInterval = 5 * time.Minute
+	// convergenceWindow is how many of the last syncs the rates are taken over
+	convergenceWindow = 3
+)
+
+// cbtSync is one changed-block sync
+type cbtSync struct {
+	// deltaBytes is the size of the changed blocks copied
+	deltaBytes int64
+	// since is how long the source VM had to change them: the time between the
+	// snapshot of the previous sync and this one, 0 if unknown
+	since time.Duration
+	// copyTime is how long copying them took
+	copyTime time.Duration
+}
+
+// cbtConvergence predicts from the changed-block syncs of a warm migration how
+// long the final sync, with the source VM powered off, would take. The source
+// VM changes blocks at a dirty rate while they are copied at a copy rate, so the
+// delta left after a window is dirty rate × window, copied in that over the copy
+// rate.
+type cbtConvergence struct {
+	prevSnapshot time.Time
+	lastSnapshot time.Time
+	// recent are the last convergenceWindow syncs
+	recent    []cbtSync
+	syncs     int
+	peakDelta int64
+}
+
+// snapshotTaken records that the snapshot the next sync copies up to was taken at at
+func (c *cbtConvergence) snapshotTaken(at time.Time) {
+	c.prevSnapshot, c.lastSnapshot = c.lastSnapshot, at
+}
+
+// add records a sync that copied deltaBytes of blocks changed between the last
+// two snapshots in copyTime
+func (c *cbtConvergence) add(deltaBytes int64, copyTime time.Duration) {
+	s := cbtSync{deltaBytes: deltaBytes, copyTime: copyTime}
+	if !c.prevSnapshot.IsZero() {
+		s.since = c.lastSnapshot.Sub(c.prevSnapshot)
+	}
+	c.recent = append(c.recent, s)
+	if len(c.recent) > convergenceWindow {
+		c.recent = c.recent[len(c.recent)-convergenceWindow:]
+	}
+	c.syncs++
+	c.peakDelta = max(c.peakDelta, deltaBytes)
+}
+
+// rates returns the dirty and copy rates in bytes per second over the recent
+// syncs, and false while there is nothing to tell them from
+func (c *cbtConvergence) rates() (dirty, copied float64, ok bool) {
+	var dirtyBytes, copyBytes int64
+	var since, copyTime time.Duration
+	for _, s := range c.recent {
+		if s.since > 0 {
+			dirtyBytes += s.deltaBytes
+			since += s.since
+		}
+		if s.deltaBytes > 0 && s.copyTime > 0 {
+			copyBytes += s.deltaBytes
+			copyTime += s.copyTime
+		}
+	}
+	if since == 0 {
+		return 0, 0, false
+	}
+	dirty = float64(dirtyBytes) / since.Seconds()
+	if copyTime > 0 {
+		copied = float64(copyBytes) / copyTime.Seconds()
+	}
+	// A VM that changes blocks needs a copy rate to predict anything
+	return dirty, copied, dirty == 0 || copied > 0
+}
+
+// predictDowntime is how long the final sync takes when the source VM is powered
+// off window after the last snapshot
+func (c *cbtConvergence) predictDowntime(window time.Duration) (time.Duration, bool) {
+	dirty, copied, ok := c.rates()
+	if !ok {
+		return 0, false
+	}
+	if dirty == 0 {
+		return finalSyncOverhead, true
+	}
+	return finalSyncOverhead + time.Duration(dirty*window.Seconds()/copied*float64(time.Second)), true
+}
+
+// nextInterval shortens the configured interval between periodic syncs as the
+// delta shrinks from its peak, down to minSyncInterval, so that less is left for
+// the final sync. It goes back up when the delta grows again.
+func (c *cbtConvergence) nextInterval(configured time.Duration) time.Duration {
+	if len(c.recent) == 0 || c.peakDelta == 0 || configured <= minSyncInterval {
+		return configured
+	}
+	last := c.recent[len(c.recent)-1].deltaBytes
+	interval := time.Duration(float64(configured) * float64(last) / float64(c.peakDelta))
+	return min(max(interval, minSyncInterval), configured)
+}
+
+// lastSince is how long the source VM had to change the blocks of the last sync
+func (c *cbtConvergence) lastSince() time.Duration {
+	if len(c.recent) == 0 {
+		return 0
+	}
+	return c.recent[len(c.recent)-1].since
+}
+
+// readiness predicts the downtime of a cutover window after the last snapshot
+// against target, or returns nil while there is nothing to predict it from
+func (c *cbtConvergence) readiness(window, target time.Duration) *vjailbreakv1alpha1.CutoverReadiness {
+	downtime, ok := c.predictDowntime(window)
+	if !ok {
+		return nil
+	}
+	dirty, copied, _ := c.rates()
+	return &vjailbreakv1alpha1.CutoverReadiness{
+		Ready:               downtime <= target,
+		PredictedDowntime:   metav1.Duration{Duration: downtime.Round(time.Second)},
+		DowntimeTarget:      metav1.Duration{Duration: target},
+		DeltaBytes:          c.recent[len(c.recent)-1].deltaBytes,
+		DirtyBytesPerSecond: int64(dirty),
+		CopyBytesPerSecond:  int64(copied),
+		Syncs:               c.syncs,
+		UpdatedAt:           metav1.Now(),
+	}
+}
+
+// cutoverDowntimeTarget parses the CUTOVER_DOWNTIME_TARGET setting, falling back
+// to its default
+func cutoverDowntimeTarget(setting string) time.Duration {
+	target, err := time.ParseDuration(setting)
+	if err != nil || target <= 0 {
+		target, _ = time.ParseDuration(constants.CutoverDowntimeTarget)
+	}
+	return target
+}
+
+// getCutoverDowntimeTarget reads the CUTOVER_DOWNTIME_TARGET setting
+func (migobj *Migrate) getCutoverDowntimeTarget(ctx context.Context) time.Duration {
+	vjailbreakSettings, err := k8sutils.GetVjailbreakSettings(ctx, migobj.K8sClient)
+	if err != nil {
+		utils.PrintLog(fmt.Sprintf("Failed to get vjailbreak settings: %v, using the default cutover downtime target (%s)",
+			err, constants.CutoverDowntimeTarget))
+		return cutoverDowntimeTarget("")
+	}
+	return cutoverDowntimeTarget(vjailbreakSettings.CutoverDowntimeTarget)
+}
+
+// updateCutoverReadiness predicts the cutover downtime after the last sync, logs
+// it and records it on the Migration status for the admin to decide on cutover
+func (migobj *Migrate) updateCutoverReadiness(ctx context.Context, prefix string, window, target time.Duration,
+	nextSyncInterval *time.Duration,
+) {
+	readiness := migobj.convergence.readiness(window, target)
+	if readiness == nil {
+		return
+	}
+	if nextSyncInterval != nil {
+		readiness.NextSyncInterval = &metav1.Duration{Duration: *nextSyncInterval}
+	}
+	if readiness.Ready {
+		migobj.logMessage(fmt.Sprintf("%sReady for cutover: predicted downtime %s fits the %s target",
+			prefix, readiness.PredictedDowntime.Duration, target))
+	} else {
+		migobj.logMessage(fmt.Sprintf("%sNot ready for cutover: predicted downtime %s exceeds the %s target",
+			prefix, readiness.PredictedDowntime.Duration, target))
+	}
+	if migobj.K8sClient != nil {
+		if err := migobj.reportCutoverReadiness(ctx, readiness); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to report cutover readiness: %v", err))
+		}
+	}
+}
+
+// reportCutoverReadiness records the cutover readiness on the Migration status
+func (migobj *Migrate) reportCutoverReadiness(ctx context.Context, readiness *vjailbreakv1alpha1.CutoverReadiness) error {
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return errors.Wrap(err, "failed to get migration object name")
+	}
+	migration := &vjailbreakv1alpha1.Migration{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration); err != nil {
+		return errors.Wrapf(err, "failed to get migration %s to patch cutover readiness", migrationName)
+	}
+	patch := client.MergeFrom(migration.DeepCopy())
+	migration.Status.CutoverReadiness = readiness
+	if err := migobj.K8sClient.Status().Patch(ctx, migration, patch); err != nil {
+		return errors.Wrapf(err, "failed to patch cutover readiness on migration %s", migrationName)
+	}
+	return nil
+}
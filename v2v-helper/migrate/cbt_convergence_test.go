@@ -0,0 +1,97 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+func TestCBTConvergence(t *testing.T) {
+	start := time.Now()
+	c := &cbtConvergence{}
+	_, ok := c.predictDowntime(time.Hour)
+	assert.False(t, ok, "no prediction before a sync")
+	assert.Nil(t, c.readiness(time.Hour, 5*time.Minute))
+	assert.Equal(t, time.Hour, c.nextInterval(time.Hour))
+
+	// The full copy took an hour, in which 36 GB changed: 10 MB/s dirty. The
+	// delta was copied at 100 MB/s.
+	c.snapshotTaken(start)
+	c.snapshotTaken(start.Add(time.Hour))
+	c.add(36e9, 6*time.Minute)
+	downtime, ok := c.predictDowntime(time.Hour)
+	require.True(t, ok)
+	assert.Equal(t, finalSyncOverhead+6*time.Minute, downtime)
+	readiness := c.readiness(time.Hour, 5*time.Minute)
+	require.NotNil(t, readiness)
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, int64(10e6), readiness.DirtyBytesPerSecond)
+	assert.Equal(t, int64(100e6), readiness.CopyBytesPerSecond)
+	assert.Equal(t, 1, readiness.Syncs)
+
+	// A shorter window leaves less to copy
+	readiness = c.readiness(15*time.Minute, 5*time.Minute)
+	assert.True(t, readiness.Ready)
+	assert.Equal(t, finalSyncOverhead+90*time.Second, readiness.PredictedDowntime.Duration)
+
+	// The interval shrinks with the delta, but not below the minimum
+	c.snapshotTaken(start.Add(90 * time.Minute))
+	c.add(9e9, 90*time.Second)
+	assert.Equal(t, 15*time.Minute, c.nextInterval(time.Hour))
+	c.snapshotTaken(start.Add(95 * time.Minute))
+	c.add(1e9, 10*time.Second)
+	assert.Equal(t, minSyncInterval, c.nextInterval(time.Hour))
+	assert.Equal(t, 5*time.Minute, c.lastSince())
+
+	// Only the recent syncs count
+	for i := 0; i < convergenceWindow; i++ {
+		c.snapshotTaken(start.Add(time.Duration(100+5*i) * time.Minute))
+		c.add(0, 0)
+	}
+	downtime, ok = c.predictDowntime(time.Hour)
+	require.True(t, ok)
+	assert.Equal(t, finalSyncOverhead, downtime, "a VM that stopped changing blocks")
+	assert.Equal(t, minSyncInterval, c.nextInterval(time.Hour))
+}
+
+func TestCutoverDowntimeTarget(t *testing.T) {
+	assert.Equal(t, 10*time.Minute, cutoverDowntimeTarget("10m"))
+	assert.Equal(t, 5*time.Minute, cutoverDowntimeTarget(""))
+	assert.Equal(t, 5*time.Minute, cutoverDowntimeTarget("soon"))
+	assert.Equal(t, 5*time.Minute, cutoverDowntimeTarget("-1m"))
+}
+
+func TestReportCutoverReadiness(t *testing.T) {
+	builder, migrationName := checkpointTestClient(t)
+	k8sClient := builder.WithStatusSubresource(&vjailbreakv1alpha1.Migration{}).Build()
+	ctx := context.Background()
+	migobj := &Migrate{K8sClient: k8sClient}
+
+	start := time.Now()
+	migobj.convergence.snapshotTaken(start)
+	migobj.convergence.snapshotTaken(start.Add(10 * time.Minute))
+	migobj.convergence.add(6e9, time.Minute)
+	interval := 10 * time.Minute
+	migobj.updateCutoverReadiness(ctx, "", interval, 5*time.Minute, &interval)
+
+	migration := &vjailbreakv1alpha1.Migration{}
+	require.NoError(t, k8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration))
+	readiness := migration.Status.CutoverReadiness
+	require.NotNil(t, readiness)
+	assert.True(t, readiness.Ready)
+	assert.Equal(t, 2*time.Minute, readiness.PredictedDowntime.Duration)
+	assert.Equal(t, int64(6e9), readiness.DeltaBytes)
+	require.NotNil(t, readiness.NextSyncInterval)
+	assert.Equal(t, interval, readiness.NextSyncInterval.Duration)
+}
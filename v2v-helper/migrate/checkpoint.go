@@ -0,0 +1,360 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// CopyCheckpoint is how far the disk copy of a migration has got. It is kept
+// in a ConfigMap so that a v2v-helper pod that dies while copying leaves
+// enough behind for the next one to reattach the volumes and carry on with the
+// copy instead of starting it over. Only the vCenter NBD copy and the warm
+// StorageAcceleratedCopy, whose XCOPY clones count as a finished full copy,
+// keep one.
+//
+// The ConfigMap is owned by the MigrationPlan rather than the Migration, so it
+// also survives a retry that deletes the failed Migration. It is deleted once
+// the copy is done, and by cleanup together with the volumes it points at. A
+// pod terminated by an eviction, a drain or a pod delete keeps it, see
+// resumableAfterTermination.
+type CopyCheckpoint struct {
+	// SnapshotMOID is the migration snapshot the full copy reads from
+	SnapshotMOID string           `json:"snapshotMOID"`
+	Disks        []DiskCheckpoint `json:"disks"`
+}
+
+// DiskCheckpoint is the copy state of one disk
+type DiskCheckpoint struct {
+	Name      string `json:"name"`
+	DeviceKey int32  `json:"deviceKey"`
+	Size      int64  `json:"size"`
+	VolumeID  string `json:"volumeID"`
+	// Offset is how far the full copy has got, it is Size once the full copy is done
+	Offset int64 `json:"offset"`
+	// ChangeID is the CBT change ID the volume holds the disk at. The changed
+	// blocks since ChangeID bring it up to date with any later snapshot.
+	ChangeID string `json:"changeID,omitempty"`
+}
+
+// newCopyCheckpoint starts a checkpoint for the disks of vminfo, after
+// UpdateDisksInfo has filled in the migration snapshot
+func newCopyCheckpoint(vminfo vm.VMInfo) *CopyCheckpoint {
+	checkpoint := &CopyCheckpoint{}
+	for _, vmdisk := range vminfo.VMDisks {
+		checkpoint.SnapshotMOID = vmdisk.Snapname
+		disk := DiskCheckpoint{
+			Name:     vmdisk.Name,
+			Size:     vmdisk.Size,
+			ChangeID: vmdisk.ChangeID,
+		}
+		if vmdisk.Disk != nil {
+			disk.DeviceKey = vmdisk.Disk.Key
+		}
+		if vmdisk.OpenstackVol != nil {
+			disk.VolumeID = vmdisk.OpenstackVol.ID
+		}
+		checkpoint.Disks = append(checkpoint.Disks, disk)
+	}
+	return checkpoint
+}
+
+// matches reports why the checkpoint cannot be resumed for the disks of
+// vminfo, or nil if it can
+func (checkpoint *CopyCheckpoint) matches(vminfo vm.VMInfo) error {
+	if len(checkpoint.Disks) != len(vminfo.VMDisks) {
+		return errors.Errorf("checkpoint has %d disks, the VM has %d", len(checkpoint.Disks), len(vminfo.VMDisks))
+	}
+	for idx, vmdisk := range vminfo.VMDisks {
+		disk := checkpoint.Disks[idx]
+		deviceKey := int32(0)
+		if vmdisk.Disk != nil {
+			deviceKey = vmdisk.Disk.Key
+		}
+		if disk.Name != vmdisk.Name || disk.DeviceKey != deviceKey || disk.Size != vmdisk.Size {
+			return errors.Errorf("disk %d changed since the checkpoint: %s (DeviceKey=%d, %d bytes) is now %s (DeviceKey=%d, %d bytes)",
+				idx, disk.Name, disk.DeviceKey, disk.Size, vmdisk.Name, deviceKey, vmdisk.Size)
+		}
+		if disk.VolumeID == "" {
+			return errors.Errorf("checkpoint has no volume for disk %s", disk.Name)
+		}
+	}
+	return nil
+}
+
+// createOrResumeVolumes creates the volumes of a fresh copy, or reattaches the
+// volumes of an interrupted one and leaves its checkpoint in
+// migobj.copyCheckpoint for LiveReplicateDisks to carry on from.
+func (migobj *Migrate) createOrResumeVolumes(ctx context.Context, vminfo vm.VMInfo) (vm.VMInfo, error) {
+	if resumed, ok := migobj.resumeCopy(ctx, vminfo); ok {
+		return resumed, nil
+	}
+	return migobj.CreateVolumes(ctx, vminfo)
+}
+
+// resumeCopy picks up the volumes and the checkpoint of an interrupted copy,
+// and reports whether there was one to pick up. A checkpoint that no longer
+// fits the VM or its volumes is dropped together with the volumes it points
+// at, and the copy starts over.
+func (migobj *Migrate) resumeCopy(ctx context.Context, vminfo vm.VMInfo) (vm.VMInfo, bool) {
+	checkpoint, err := migobj.loadCopyCheckpoint(ctx)
+	if err != nil {
+		migobj.logMessage(fmt.Sprintf("WARNING: Failed to read the disk copy checkpoint, copying from the start: %v", err))
+	}
+	if checkpoint == nil {
+		return vminfo, false
+	}
+
+	resumed, err := migobj.resumeVolumes(ctx, vminfo, checkpoint)
+	if err != nil {
+		migobj.logMessage(fmt.Sprintf("Cannot resume the interrupted disk copy, copying from the start: %v", err))
+		migobj.discardCopyCheckpoint(ctx, checkpoint)
+		return vminfo, false
+	}
+	migobj.copyCheckpoint = checkpoint
+	for idx, disk := range checkpoint.Disks {
+		migobj.logMessage(fmt.Sprintf("Resuming disk %d (%s) on volume %s, %d of %d bytes copied",
+			idx, disk.Name, disk.VolumeID, disk.Offset, disk.Size))
+	}
+	return resumed, true
+}
+
+// resumeVolumes points the disks of vminfo at the volumes of the checkpoint. A
+// volume still attached to the vJailbreak node of the previous pod is detached
+// from it so that AttachVolume can attach it here.
+func (migobj *Migrate) resumeVolumes(ctx context.Context, vminfo vm.VMInfo, checkpoint *CopyCheckpoint) (vm.VMInfo, error) {
+	if err := checkpoint.matches(vminfo); err != nil {
+		return vminfo, err
+	}
+	openstackops := migobj.Openstackclients
+	vjailbreakUUID, err := migobj.vjailbreakInstanceUUID()
+	if err != nil {
+		return vminfo, err
+	}
+	for idx, disk := range checkpoint.Disks {
+		volume, err := openstackops.GetVolume(ctx, disk.VolumeID)
+		if err != nil {
+			return vminfo, errors.Wrapf(err, "failed to get volume %s of disk %s", disk.VolumeID, disk.Name)
+		}
+		if volume.Status != "available" && volume.Status != "in-use" {
+			return vminfo, errors.Errorf("volume %s of disk %s is %s", volume.ID, disk.Name, volume.Status)
+		}
+		for _, attachment := range volume.Attachments {
+			if attachment.ServerID == "" || attachment.ServerID == vjailbreakUUID {
+				continue
+			}
+			migobj.logMessage(fmt.Sprintf("Detaching volume %s from server %s of the previous attempt", volume.ID, attachment.ServerID))
+			if err := openstackops.DetachVolumeFromServer(ctx, attachment.ServerID, volume.ID); err != nil {
+				return vminfo, errors.Wrapf(err, "failed to detach volume %s from server %s", volume.ID, attachment.ServerID)
+			}
+			if err := openstackops.WaitForVolumeDetached(ctx, volume.ID, constants.CopyCheckpointDetachTimeout); err != nil {
+				return vminfo, errors.Wrapf(err, "failed to detach volume %s from server %s", volume.ID, attachment.ServerID)
+			}
+		}
+		vminfo.VMDisks[idx].OpenstackVol = volume
+	}
+	return vminfo, nil
+}
+
+// discardCopyCheckpoint deletes the volumes of a checkpoint that cannot be
+// resumed, then the checkpoint itself
+func (migobj *Migrate) discardCopyCheckpoint(ctx context.Context, checkpoint *CopyCheckpoint) {
+	for _, disk := range checkpoint.Disks {
+		if disk.VolumeID == "" {
+			continue
+		}
+		if err := migobj.Openstackclients.DetachVolumeFromVM(ctx, disk.VolumeID); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to detach volume %s of the interrupted copy: %v", disk.VolumeID, err))
+		}
+		if err := migobj.Openstackclients.DeleteVolume(ctx, disk.VolumeID); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to delete volume %s of the interrupted copy: %v", disk.VolumeID, err))
+		}
+	}
+	migobj.deleteCopyCheckpoint(ctx)
+}
+
+// loadCopyCheckpoint returns the checkpoint left by a previous attempt at this
+// migration, or nil if there is none
+func (migobj *Migrate) loadCopyCheckpoint(ctx context.Context) (*CopyCheckpoint, error) {
+	if migobj.K8sClient == nil {
+		return nil, nil
+	}
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return nil, err
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName + constants.CopyCheckpointSuffix,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to get disk copy checkpoint")
+	}
+	checkpoint := &CopyCheckpoint{}
+	if err := json.Unmarshal([]byte(configMap.Data[constants.CopyCheckpointKey]), checkpoint); err != nil {
+		return nil, errors.Wrap(err, "failed to parse disk copy checkpoint")
+	}
+	return checkpoint, nil
+}
+
+// updateCopyCheckpoint applies update to migobj.copyCheckpoint and saves it.
+// Disks copied in parallel report their offsets through it.
+func (migobj *Migrate) updateCopyCheckpoint(ctx context.Context, update func(checkpoint *CopyCheckpoint)) {
+	migobj.copyCheckpointMu.Lock()
+	if migobj.copyCheckpoint == nil {
+		migobj.copyCheckpointMu.Unlock()
+		return
+	}
+	update(migobj.copyCheckpoint)
+	migobj.copyCheckpointMu.Unlock()
+	migobj.saveCopyCheckpoint(ctx)
+}
+
+// saveCopyCheckpoint writes migobj.copyCheckpoint. A failed write is only
+// logged: it costs a longer copy after a restart, not the migration.
+func (migobj *Migrate) saveCopyCheckpoint(ctx context.Context) {
+	if migobj.K8sClient == nil {
+		return
+	}
+	migobj.copyCheckpointMu.Lock()
+	defer migobj.copyCheckpointMu.Unlock()
+	if migobj.copyCheckpoint == nil {
+		return
+	}
+	if err := migobj.writeCopyCheckpoint(ctx); err != nil {
+		utils.PrintLog(fmt.Sprintf("WARNING: Failed to save disk copy checkpoint: %v", err))
+	}
+}
+
+func (migobj *Migrate) writeCopyCheckpoint(ctx context.Context) error {
+	data, err := json.Marshal(migobj.copyCheckpoint)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal disk copy checkpoint")
+	}
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return err
+	}
+	configMap := &corev1.ConfigMap{}
+	err = migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName + constants.CopyCheckpointSuffix,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, configMap)
+	if err == nil {
+		configMap.Data = map[string]string{constants.CopyCheckpointKey: string(data)}
+		return migobj.K8sClient.Update(ctx, configMap)
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	migration := &vjailbreakv1alpha1.Migration{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration); err != nil {
+		return errors.Wrapf(err, "failed to get migration %s", migrationName)
+	}
+	configMap = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      migrationName + constants.CopyCheckpointSuffix,
+			Namespace: constants.NamespaceMigrationSystem,
+		},
+		Data: map[string]string{constants.CopyCheckpointKey: string(data)},
+	}
+	// Owned by the MigrationPlan that owns the Migration, see CopyCheckpoint
+	if owner := metav1.GetControllerOf(migration); owner != nil {
+		ownerRef := *owner
+		ownerRef.Controller = nil
+		ownerRef.BlockOwnerDeletion = nil
+		configMap.OwnerReferences = []metav1.OwnerReference{ownerRef}
+	}
+	return migobj.K8sClient.Create(ctx, configMap)
+}
+
+// deleteCopyCheckpoint drops the checkpoint once there is nothing left to
+// resume
+func (migobj *Migrate) deleteCopyCheckpoint(ctx context.Context) {
+	migobj.copyCheckpointMu.Lock()
+	migobj.copyCheckpoint = nil
+	migobj.copyCheckpointMu.Unlock()
+	if migobj.K8sClient == nil {
+		return
+	}
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      migrationName + constants.CopyCheckpointSuffix,
+			Namespace: constants.NamespaceMigrationSystem,
+		},
+	}
+	if err := migobj.K8sClient.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+		utils.PrintLog(fmt.Sprintf("WARNING: Failed to delete disk copy checkpoint: %v", err))
+	}
+}
+
+// resumableAfterTermination reports whether a terminated pod should leave its
+// volumes, the migration snapshot and the checkpoint for the next pod to resume
+// the copy from. That is the case while a copy keeps a checkpoint, unless the
+// Migration or its MigrationPlan is being deleted and no pod will follow.
+func (migobj *Migrate) resumableAfterTermination(ctx context.Context) bool {
+	migobj.copyCheckpointMu.Lock()
+	copying := migobj.copyCheckpoint != nil
+	migobj.copyCheckpointMu.Unlock()
+	if !copying || migobj.K8sClient == nil {
+		return false
+	}
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return false
+	}
+	migration := &vjailbreakv1alpha1.Migration{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		// Keeping too much is cheaper than a copy that has to start over
+		utils.PrintLog(fmt.Sprintf("Failed to get migration %s, keeping the disk copy checkpoint: %v", migrationName, err))
+		return true
+	}
+	if !migration.DeletionTimestamp.IsZero() {
+		return false
+	}
+	owner := metav1.GetControllerOf(migration)
+	if owner == nil || owner.Kind != "MigrationPlan" {
+		return true
+	}
+	plan := &vjailbreakv1alpha1.MigrationPlan{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      owner.Name,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, plan); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		utils.PrintLog(fmt.Sprintf("Failed to get migration plan %s, keeping the disk copy checkpoint: %v", owner.Name, err))
+		return true
+	}
+	return plan.DeletionTimestamp.IsZero()
+}
@@ -0,0 +1,225 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/openstack"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/govmomi/vim25/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func checkpointTestVMInfo() vm.VMInfo {
+	return vm.VMInfo{
+		Name:   "test-vm",
+		OSType: "linux",
+		VMDisks: []vm.VMDisk{
+			{Name: "disk1", Size: 40 << 30, Disk: &types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 2000}},
+				OpenstackVol: &volumes.Volume{ID: "vol-1"}, Snapname: "snapshot-7", ChangeID: "52 1a/10"},
+			{Name: "disk2", Size: 80 << 30, Disk: &types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 2001}},
+				OpenstackVol: &volumes.Volume{ID: "vol-2"}, Snapname: "snapshot-7", ChangeID: "52 1a/20"},
+		},
+	}
+}
+
+func TestNewCopyCheckpoint(t *testing.T) {
+	checkpoint := newCopyCheckpoint(checkpointTestVMInfo())
+	assert.Equal(t, &CopyCheckpoint{
+		SnapshotMOID: "snapshot-7",
+		Disks: []DiskCheckpoint{
+			{Name: "disk1", DeviceKey: 2000, Size: 40 << 30, VolumeID: "vol-1", ChangeID: "52 1a/10"},
+			{Name: "disk2", DeviceKey: 2001, Size: 80 << 30, VolumeID: "vol-2", ChangeID: "52 1a/20"},
+		},
+	}, checkpoint)
+}
+
+func TestCopyCheckpointMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(vminfo *vm.VMInfo)
+		wantErr string
+	}{
+		{name: "same disks", modify: func(*vm.VMInfo) {}},
+		{name: "disk added", modify: func(vminfo *vm.VMInfo) {
+			vminfo.VMDisks = append(vminfo.VMDisks, vm.VMDisk{Name: "disk3", Size: 1 << 30})
+		}, wantErr: "checkpoint has 2 disks, the VM has 3"},
+		{name: "disk grown", modify: func(vminfo *vm.VMInfo) {
+			vminfo.VMDisks[1].Size = 100 << 30
+		}, wantErr: "disk 1 changed since the checkpoint"},
+		{name: "disk replaced", modify: func(vminfo *vm.VMInfo) {
+			vminfo.VMDisks[0].Disk.Key = 2002
+		}, wantErr: "disk 0 changed since the checkpoint"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkpoint := newCopyCheckpoint(checkpointTestVMInfo())
+			vminfo := checkpointTestVMInfo()
+			tt.modify(&vminfo)
+			err := checkpoint.matches(vminfo)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func checkpointTestClient(t *testing.T) (*ctrlfake.ClientBuilder, string) {
+	const vmK8sName = "test-vm-node"
+	t.Setenv("VMWARE_MACHINE_OBJECT_NAME", vmK8sName)
+	migrationName := "migration-" + vmK8sName
+	controller := true
+
+	scheme := k8sruntime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, vjailbreakv1alpha1.AddToScheme(scheme))
+	migration := &vjailbreakv1alpha1.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      migrationName,
+			Namespace: constants.NamespaceMigrationSystem,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: vjailbreakv1alpha1.GroupVersion.String(),
+				Kind:       "MigrationPlan",
+				Name:       "plan",
+				UID:        "plan-uid",
+				Controller: &controller,
+			}},
+		},
+	}
+	return ctrlfake.NewClientBuilder().WithScheme(scheme).WithObjects(migration), migrationName
+}
+
+func TestCopyCheckpointSaveLoadDelete(t *testing.T) {
+	builder, migrationName := checkpointTestClient(t)
+	k8sClient := builder.Build()
+	ctx := context.Background()
+	migobj := &Migrate{K8sClient: k8sClient}
+
+	checkpoint, err := migobj.loadCopyCheckpoint(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint, "no checkpoint before the first copy")
+
+	migobj.copyCheckpoint = newCopyCheckpoint(checkpointTestVMInfo())
+	migobj.saveCopyCheckpoint(ctx)
+	migobj.copyCheckpoint.Disks[0].Offset = 16 << 30
+	migobj.saveCopyCheckpoint(ctx)
+
+	configMap := &corev1.ConfigMap{}
+	require.NoError(t, k8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName + constants.CopyCheckpointSuffix,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, configMap))
+	// owned by the MigrationPlan so that it outlives a retried Migration
+	require.Len(t, configMap.OwnerReferences, 1)
+	assert.Equal(t, "MigrationPlan", configMap.OwnerReferences[0].Kind)
+	assert.Nil(t, configMap.OwnerReferences[0].Controller)
+
+	checkpoint, err = migobj.loadCopyCheckpoint(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, migobj.copyCheckpoint, checkpoint)
+
+	migobj.deleteCopyCheckpoint(ctx)
+	assert.Nil(t, migobj.copyCheckpoint)
+	err = k8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName + constants.CopyCheckpointSuffix,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, configMap)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+// TestCreateOrResumeVolumes_StaleCheckpoint checks that a checkpoint that no
+// longer fits the VM is dropped together with its volumes and the copy starts
+// over on new volumes.
+func TestCreateOrResumeVolumes_StaleCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	builder, _ := checkpointTestClient(t)
+	k8sClient := builder.Build()
+	ctx := context.Background()
+
+	stale := &Migrate{K8sClient: k8sClient, copyCheckpoint: newCopyCheckpoint(checkpointTestVMInfo())}
+	stale.saveCopyCheckpoint(ctx)
+
+	vminfo := checkpointTestVMInfo()
+	vminfo.VMDisks[1].Size = 100 << 30
+	for idx := range vminfo.VMDisks {
+		vminfo.VMDisks[idx].OpenstackVol = nil
+	}
+
+	mockOpenStackOps := openstack.NewMockOpenstackOperations(ctrl)
+	for _, volumeID := range []string{"vol-1", "vol-2"} {
+		mockOpenStackOps.EXPECT().DetachVolumeFromVM(gomock.Any(), volumeID).Return(nil).Times(1)
+		mockOpenStackOps.EXPECT().DeleteVolume(gomock.Any(), volumeID).Return(nil).Times(1)
+	}
+	mockOpenStackOps.EXPECT().CreateVolume(gomock.Any(), "test-vm-disk1", int64(40<<30), "linux", false, "voltype", false).
+		Return(&volumes.Volume{ID: "vol-3"}, nil).Times(1)
+	mockOpenStackOps.EXPECT().CreateVolume(gomock.Any(), "test-vm-disk2", int64(100<<30), "linux", false, "voltype", false).
+		Return(&volumes.Volume{ID: "vol-4"}, nil).Times(1)
+
+	migobj := &Migrate{
+		K8sClient:        k8sClient,
+		Openstackclients: mockOpenStackOps,
+		Volumetypes:      []string{"voltype", "voltype"},
+	}
+	vminfo, err := migobj.createOrResumeVolumes(ctx, vminfo)
+	require.NoError(t, err)
+	assert.Equal(t, "vol-3", vminfo.VMDisks[0].OpenstackVol.ID)
+	assert.Equal(t, "vol-4", vminfo.VMDisks[1].OpenstackVol.ID)
+	assert.Nil(t, migobj.copyCheckpoint)
+
+	checkpoint, err := migobj.loadCopyCheckpoint(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint, "stale checkpoint is deleted")
+}
+
+// TestCleanup_TerminatedCopyKeepsCheckpoint checks that a pod terminated in the
+// middle of a copy leaves the volumes and the checkpoint for the next pod, and
+// that a deleted Migration gets no such pod to wait for.
+func TestCleanup_TerminatedCopyKeepsCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	builder, migrationName := checkpointTestClient(t)
+	plan := &vjailbreakv1alpha1.MigrationPlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: constants.NamespaceMigrationSystem},
+	}
+	k8sClient := builder.WithObjects(plan).Build()
+	ctx := context.Background()
+
+	// no calls expected: the volumes stay
+	mockOpenStackOps := openstack.NewMockOpenstackOperations(ctrl)
+	migobj := &Migrate{
+		K8sClient:        k8sClient,
+		Openstackclients: mockOpenStackOps,
+		copyCheckpoint:   newCopyCheckpoint(checkpointTestVMInfo()),
+	}
+	migobj.saveCopyCheckpoint(ctx)
+
+	require.True(t, migobj.resumableAfterTermination(ctx))
+	migobj.keepForResume.Store(true)
+	require.NoError(t, migobj.cleanup(ctx, checkpointTestVMInfo(), "Migration terminated", nil, nil))
+
+	checkpoint, err := migobj.loadCopyCheckpoint(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, newCopyCheckpoint(checkpointTestVMInfo()), checkpoint, "checkpoint kept for the next pod")
+
+	require.NoError(t, k8sClient.Delete(ctx, &vjailbreakv1alpha1.Migration{
+		ObjectMeta: metav1.ObjectMeta{Name: migrationName, Namespace: constants.NamespaceMigrationSystem},
+	}))
+	assert.False(t, migobj.resumableAfterTermination(ctx), "nothing resumes a deleted Migration")
+}
@@ -171,14 +171,7 @@ func (migobj *Migrate) validateLinuxOS(osRelease string) error {
 	osDetected := strings.ToLower(strings.TrimSpace(osRelease))
 	utils.PrintLog(fmt.Sprintf("OS detected by guestfish: %s", osDetected))
 
-	supportedOS := []string{
-		"redhat", "red hat", "rhel", "centos", "scientific linux",
-		"oracle linux", "fedora", "sles", "sled", "opensuse",
-		"alt linux", "debian", "ubuntu", "rocky linux",
-		"suse linux enterprise server", "suse linux enterprise desktop", "alma linux",
-	}
-
-	for _, s := range supportedOS {
+	for _, s := range constants.SupportedLinuxOS {
 		if strings.Contains(osDetected, s) {
 			utils.PrintLog("operating system compatibility check passed")
 			return nil
@@ -473,6 +466,12 @@ func (migobj *Migrate) ConvertVolumes(ctx context.Context, vminfo vm.VMInfo) (in
 		return -1, err
 	}
 
+	// Step 2.5: Stop before conversion on Windows storage it cannot take, such as
+	// a BitLocker system volume, rather than failing inside virt-v2v
+	if err := migobj.preflightWindowsStorage(ctx, vminfo); err != nil {
+		return -1, err
+	}
+
 	// Step 3: Generate XML configuration for conversion
 	if err := vmutils.GenerateXMLConfig(vminfo); err != nil {
 		return -1, errors.Wrap(err, "failed to generate XML")
@@ -0,0 +1,345 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// exportDiskFormat is the format of every exported disk, a qcow2 file or image
+	exportDiskFormat = "qcow2"
+	firmwareBIOS     = "bios"
+	firmwareUEFI     = "uefi"
+)
+
+// ExportManifest describes an exported VM well enough to rebuild it from the
+// exported disks: the firmware it boots with, its disks in their original order
+// and its NIC layout.
+type ExportManifest struct {
+	SourceVM   string    `json:"sourceVM"`
+	Migration  string    `json:"migration,omitempty"`
+	ExportedAt time.Time `json:"exportedAt"`
+	// Target is "pvc" or "glance", see ExportedDisk.Location
+	Target   string         `json:"target"`
+	OSType   string         `json:"osType"`
+	Firmware string         `json:"firmware"`
+	CPU      int32          `json:"cpu"`
+	MemoryMB int32          `json:"memoryMB"`
+	Disks    []ExportedDisk `json:"disks"`
+	NICs     []ExportedNIC  `json:"nics"`
+	// ImageProperties are the Glance image properties of the boot disk, from the
+	// VolumeImageProfiles of the MigrationPlan and the conversion
+	ImageProperties map[string]string `json:"imageProperties,omitempty"`
+}
+
+// ExportedDisk is one disk of an ExportManifest
+type ExportedDisk struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	Boot  bool   `json:"boot"`
+	// ESP is set on the disk holding the EFI system partition when it is not the boot disk
+	ESP       bool   `json:"esp,omitempty"`
+	SizeBytes int64  `json:"sizeBytes"`
+	Format    string `json:"format"`
+	// Location is the qcow2 file relative to the export PVC, or the Glance image ID
+	Location string `json:"location"`
+}
+
+// ExportedNIC is one NIC of an ExportManifest
+type ExportedNIC struct {
+	Index       int      `json:"index"`
+	MAC         string   `json:"mac"`
+	Network     string   `json:"network,omitempty"`
+	NetworkType string   `json:"networkType,omitempty"`
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+}
+
+// ExportDisks exports the converted volumes of a DataOnly migration as qcow2 files
+// on the export PVC or as Glance images, records a manifest next to them and in a
+// ConfigMap owned by the Migration, and deletes the volumes unless they are kept.
+// It returns vminfo without the deleted volumes.
+func (migobj *Migrate) ExportDisks(ctx context.Context, vminfo vm.VMInfo, espDiskIndex int) (vm.VMInfo, error) {
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return vminfo, errors.Wrap(err, "failed to get migration object name")
+	}
+	imageProperties := migobj.exportImageProperties(vminfo)
+	manifest := buildExportManifest(vminfo, espDiskIndex, migobj.ExportTarget, imageProperties)
+	manifest.Migration = migrationName
+
+	switch migobj.ExportTarget {
+	case constants.ExportTargetPVC:
+		err = migobj.exportDisksToPVC(ctx, vminfo, &manifest, filepath.Join(constants.ExportMountPath, migrationName))
+	case constants.ExportTargetGlance:
+		err = migobj.exportDisksToGlance(ctx, vminfo, &manifest, imageProperties)
+	default:
+		err = errors.Errorf("unknown export target %q", migobj.ExportTarget)
+	}
+	if err != nil {
+		return vminfo, err
+	}
+
+	manifestName, err := migobj.saveExportManifest(ctx, migrationName, manifest)
+	if err != nil {
+		return vminfo, err
+	}
+	locations := make([]string, 0, len(manifest.Disks))
+	for _, disk := range manifest.Disks {
+		locations = append(locations, disk.Location)
+	}
+	if err := migobj.reportExport(ctx, migrationName, locations, manifestName); err != nil {
+		migobj.logMessage(fmt.Sprintf("Warning: failed to report exported images: %v", err))
+	}
+
+	if migobj.ExportKeepVolumes {
+		return vminfo, nil
+	}
+	disks := make([]vm.VMDisk, len(vminfo.VMDisks))
+	copy(disks, vminfo.VMDisks)
+	for idx := range disks {
+		if disks[idx].OpenstackVol == nil {
+			continue
+		}
+		if err := migobj.Openstackclients.DeleteVolume(ctx, disks[idx].OpenstackVol.ID); err != nil {
+			return vminfo, errors.Wrapf(err, "failed to delete exported volume %s", disks[idx].OpenstackVol.ID)
+		}
+		disks[idx].OpenstackVol = nil
+	}
+	vminfo.VMDisks = disks
+	migobj.logMessage("Deleted the exported volumes")
+	return vminfo, nil
+}
+
+// exportDisksToPVC attaches each volume in turn and writes it to dir as a qcow2 file.
+// Files are written under a temporary name, so a file with the final name is complete.
+func (migobj *Migrate) exportDisksToPVC(ctx context.Context, vminfo vm.VMInfo, manifest *ExportManifest, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.Wrapf(err, "failed to create export directory %s", dir)
+	}
+	for idx, disk := range vminfo.VMDisks {
+		file := fmt.Sprintf("disk%d.%s", idx, exportDiskFormat)
+		migobj.logMessage(fmt.Sprintf("Exporting disk %d (%s) to %s", idx, disk.Name, file))
+		devicePath, err := migobj.AttachVolume(ctx, disk)
+		if err != nil {
+			return errors.Wrapf(err, "failed to attach volume of disk %s", disk.Name)
+		}
+		partial := filepath.Join(dir, file+".partial")
+		convertErr := runQemuImgExport(ctx, devicePath, partial, migobj.ExportCompress)
+		if err := migobj.DetachVolume(ctx, disk); err != nil {
+			return errors.Wrapf(err, "failed to detach volume of disk %s", disk.Name)
+		}
+		if convertErr != nil {
+			_ = os.Remove(partial)
+			return errors.Wrapf(convertErr, "failed to export disk %s", disk.Name)
+		}
+		if err := os.Rename(partial, filepath.Join(dir, file)); err != nil {
+			return errors.Wrapf(err, "failed to rename exported disk %s", file)
+		}
+		manifest.Disks[idx].Location = filepath.Join(filepath.Base(dir), file)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal export manifest")
+	}
+	if err := os.WriteFile(filepath.Join(dir, constants.ExportManifestFile), data, 0o644); err != nil { //nolint:gosec // the manifest is not secret
+		return errors.Wrap(err, "failed to write export manifest")
+	}
+	return nil
+}
+
+func runQemuImgExport(ctx context.Context, devicePath, output string, compress bool) error {
+	args := []string{"convert", "-f", "raw", "-O", exportDiskFormat}
+	if compress {
+		args = append(args, "-c")
+	}
+	args = append(args, devicePath, output)
+	out, err := exec.CommandContext(ctx, "qemu-img", args...).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "qemu-img convert failed: %s", string(out))
+	}
+	return nil
+}
+
+// exportDisksToGlance has Cinder upload every volume, which it does in parallel,
+// then waits for the images and sets their properties
+func (migobj *Migrate) exportDisksToGlance(ctx context.Context, vminfo vm.VMInfo, manifest *ExportManifest, bootProperties map[string]string) error {
+	openstackops := migobj.Openstackclients
+	visibility := migobj.ExportImageVisibility
+	if visibility == "" {
+		visibility = "private"
+	}
+	for idx, disk := range vminfo.VMDisks {
+		if disk.OpenstackVol == nil {
+			return errors.Errorf("disk %s has no volume to export", disk.Name)
+		}
+		imageName := fmt.Sprintf("%s-disk%d", vminfo.Name, idx)
+		migobj.logMessage(fmt.Sprintf("Uploading disk %d (%s) to Glance image %s", idx, disk.Name, imageName))
+		imageID, err := openstackops.UploadVolumeToImage(ctx, disk.OpenstackVol.ID, imageName, exportDiskFormat, visibility)
+		if err != nil {
+			return errors.Wrapf(err, "failed to upload disk %s to Glance", disk.Name)
+		}
+		manifest.Disks[idx].Location = imageID
+	}
+
+	for idx, disk := range manifest.Disks {
+		if err := openstackops.WaitForImage(ctx, disk.Location, constants.ExportImageTimeout); err != nil {
+			return errors.Wrapf(err, "failed to upload disk %s to Glance", disk.Name)
+		}
+		properties := map[string]string{
+			"vjailbreak_source_vm":  vminfo.Name,
+			"vjailbreak_disk_index": fmt.Sprint(idx),
+		}
+		if disk.Boot {
+			for key, value := range bootProperties {
+				properties[key] = value
+			}
+		}
+		if err := openstackops.SetImageProperties(ctx, disk.Location, properties); err != nil {
+			return errors.Wrapf(err, "failed to set properties of image %s", disk.Location)
+		}
+		migobj.logMessage(fmt.Sprintf("Disk %d (%s) exported to Glance image %s", idx, disk.Name, disk.Location))
+	}
+	return nil
+}
+
+// exportImageProperties returns the image properties a VM booting from the exported
+// boot disk needs. As on the boot volume, the VolumeImageProfiles of the plan win
+// over what vJailbreak derives.
+func (migobj *Migrate) exportImageProperties(vminfo vm.VMInfo) map[string]string {
+	derived := map[string]string{}
+	for key, value := range ldmImageMetadata(migobj.isLDMGuest) {
+		derived[key] = value
+	}
+	if vminfo.OSType != "" {
+		derived["os_type"] = vminfo.OSType
+	}
+	if vminfo.UEFI {
+		derived["hw_firmware_type"] = firmwareUEFI
+	}
+	return mergeBootVolumeImageMetadata(derived, migobj.ImageMetadata)
+}
+
+// buildExportManifest describes vminfo as it is exported, without disk locations
+func buildExportManifest(vminfo vm.VMInfo, espDiskIndex int, target string, imageProperties map[string]string) ExportManifest {
+	manifest := ExportManifest{
+		SourceVM:        vminfo.Name,
+		ExportedAt:      time.Now().UTC(),
+		Target:          target,
+		OSType:          vminfo.OSType,
+		Firmware:        firmwareBIOS,
+		CPU:             vminfo.CPU,
+		MemoryMB:        vminfo.Memory,
+		Disks:           make([]ExportedDisk, 0, len(vminfo.VMDisks)),
+		NICs:            []ExportedNIC{},
+		ImageProperties: imageProperties,
+	}
+	if vminfo.UEFI {
+		manifest.Firmware = firmwareUEFI
+	}
+	for idx, disk := range vminfo.VMDisks {
+		manifest.Disks = append(manifest.Disks, ExportedDisk{
+			Index:     idx,
+			Name:      disk.Name,
+			Boot:      disk.Boot,
+			ESP:       vminfo.UEFI && idx == espDiskIndex && !disk.Boot,
+			SizeBytes: disk.Size,
+			Format:    exportDiskFormat,
+		})
+	}
+	if len(vminfo.NetworkInterfaces) > 0 {
+		for _, nic := range vminfo.NetworkInterfaces {
+			manifest.NICs = append(manifest.NICs, ExportedNIC{
+				Index:       nic.Index,
+				MAC:         nic.MAC,
+				Network:     nic.Network,
+				NetworkType: nic.NetworkType,
+				IPAddresses: nic.IPAddress,
+			})
+		}
+		return manifest
+	}
+	// Sources without NIC details only know the MACs and the addresses behind them
+	for idx, mac := range vminfo.Mac {
+		nic := ExportedNIC{Index: idx, MAC: mac}
+		for _, entry := range vminfo.IPperMac[mac] {
+			nic.IPAddresses = append(nic.IPAddresses, entry.IP)
+		}
+		manifest.NICs = append(manifest.NICs, nic)
+	}
+	return manifest
+}
+
+// saveExportManifest stores the manifest in a ConfigMap owned by the Migration, so
+// it can be read without the PVC and goes away with the Migration
+func (migobj *Migrate) saveExportManifest(ctx context.Context, migrationName string, manifest ExportManifest) (string, error) {
+	if migobj.K8sClient == nil {
+		return "", nil
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal export manifest")
+	}
+	migration := &vjailbreakv1alpha1.Migration{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration); err != nil {
+		return "", errors.Wrapf(err, "failed to get migration %s", migrationName)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-export-manifest", migrationName),
+			Namespace: constants.NamespaceMigrationSystem,
+		},
+	}
+	// A retried migration exports again and replaces the manifest
+	if _, err := controllerutil.CreateOrUpdate(ctx, migobj.K8sClient, configMap, func() error {
+		configMap.Data = map[string]string{constants.ExportManifestKey: string(data)}
+		return controllerutil.SetOwnerReference(migration, configMap, migobj.K8sClient.Scheme())
+	}); err != nil {
+		return "", errors.Wrapf(err, "failed to save export manifest %s", configMap.Name)
+	}
+	migobj.logMessage(fmt.Sprintf("Saved export manifest to ConfigMap %s", configMap.Name))
+	return configMap.Name, nil
+}
+
+// reportExport records the exported images and the manifest on the Migration status
+func (migobj *Migrate) reportExport(ctx context.Context, migrationName string, locations []string, manifestName string) error {
+	if migobj.K8sClient == nil {
+		return nil
+	}
+	migration := &vjailbreakv1alpha1.Migration{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration); err != nil {
+		return errors.Wrapf(err, "failed to get migration %s to patch exported images", migrationName)
+	}
+	patch := client.MergeFrom(migration.DeepCopy())
+	migration.Status.ExportedImages = locations
+	migration.Status.ExportManifest = manifestName
+	if err := migobj.K8sClient.Status().Patch(ctx, migration, patch); err != nil {
+		return errors.Wrapf(err, "failed to patch exported images on migration %s", migrationName)
+	}
+	return nil
+}
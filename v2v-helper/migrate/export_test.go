@@ -0,0 +1,120 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+)
+
+func TestBuildExportManifest(t *testing.T) {
+	vminfo := vm.VMInfo{
+		Name:   "web01",
+		CPU:    4,
+		Memory: 8192,
+		UEFI:   true,
+		OSType: "linux",
+		VMDisks: []vm.VMDisk{
+			{Name: "Hard disk 1", Size: 1 << 30},
+			{Name: "Hard disk 2", Size: 20 << 30, Boot: true},
+			{Name: "Hard disk 3", Size: 100 << 30},
+		},
+		NetworkInterfaces: []vjailbreakv1alpha1.NIC{
+			{Network: "VM Network", NetworkType: "distributed", MAC: "00:50:56:00:00:01", Index: 0, IPAddress: []string{"10.0.0.5"}},
+		},
+	}
+
+	manifest := buildExportManifest(vminfo, 0, constants.ExportTargetPVC, map[string]string{"hw_firmware_type": "uefi"})
+	if manifest.SourceVM != "web01" || manifest.Firmware != firmwareUEFI || manifest.CPU != 4 || manifest.MemoryMB != 8192 {
+		t.Errorf("unexpected manifest header %+v", manifest)
+	}
+	if len(manifest.Disks) != 3 {
+		t.Fatalf("got %d disks, want 3", len(manifest.Disks))
+	}
+	if !manifest.Disks[0].ESP || manifest.Disks[0].Boot {
+		t.Errorf("disk 0 = %+v, want the ESP disk", manifest.Disks[0])
+	}
+	if !manifest.Disks[1].Boot || manifest.Disks[1].ESP {
+		t.Errorf("disk 1 = %+v, want the boot disk", manifest.Disks[1])
+	}
+	if manifest.Disks[2].Boot || manifest.Disks[2].ESP || manifest.Disks[2].SizeBytes != 100<<30 {
+		t.Errorf("disk 2 = %+v, want a data disk", manifest.Disks[2])
+	}
+	for _, disk := range manifest.Disks {
+		if disk.Format != exportDiskFormat {
+			t.Errorf("disk %d format = %q, want %q", disk.Index, disk.Format, exportDiskFormat)
+		}
+	}
+	want := []ExportedNIC{{Index: 0, MAC: "00:50:56:00:00:01", Network: "VM Network", NetworkType: "distributed", IPAddresses: []string{"10.0.0.5"}}}
+	if !reflect.DeepEqual(manifest.NICs, want) {
+		t.Errorf("NICs = %+v, want %+v", manifest.NICs, want)
+	}
+
+	if _, err := json.Marshal(manifest); err != nil {
+		t.Errorf("manifest does not marshal: %v", err)
+	}
+}
+
+func TestBuildExportManifestFromMACs(t *testing.T) {
+	vminfo := vm.VMInfo{
+		Name:     "appliance",
+		Mac:      []string{"52:54:00:00:00:01", "52:54:00:00:00:02"},
+		IPperMac: map[string][]vm.IpEntry{"52:54:00:00:00:02": {{IP: "192.168.1.10", Prefix: 24}}},
+		VMDisks:  []vm.VMDisk{{Name: "disk1", Boot: true}},
+	}
+
+	manifest := buildExportManifest(vminfo, -1, constants.ExportTargetGlance, nil)
+	if manifest.Firmware != firmwareBIOS {
+		t.Errorf("Firmware = %q, want %q", manifest.Firmware, firmwareBIOS)
+	}
+	if manifest.Disks[0].ESP {
+		t.Error("BIOS boot disk marked as ESP")
+	}
+	want := []ExportedNIC{
+		{Index: 0, MAC: "52:54:00:00:00:01"},
+		{Index: 1, MAC: "52:54:00:00:00:02", IPAddresses: []string{"192.168.1.10"}},
+	}
+	if !reflect.DeepEqual(manifest.NICs, want) {
+		t.Errorf("NICs = %+v, want %+v", manifest.NICs, want)
+	}
+}
+
+func TestExportImageProperties(t *testing.T) {
+	tests := []struct {
+		name     string
+		migobj   *Migrate
+		vminfo   vm.VMInfo
+		expected map[string]string
+	}{
+		{
+			name:     "derived from the VM",
+			migobj:   &Migrate{},
+			vminfo:   vm.VMInfo{OSType: "linux", UEFI: true},
+			expected: map[string]string{"os_type": "linux", "hw_firmware_type": "uefi"},
+		},
+		{
+			name:     "profile wins",
+			migobj:   &Migrate{ImageMetadata: map[string]string{"hw_firmware_type": "bios", "hw_vif_model": "e1000"}},
+			vminfo:   vm.VMInfo{OSType: "windows", UEFI: true},
+			expected: map[string]string{"os_type": "windows", "hw_firmware_type": "bios", "hw_vif_model": "e1000"},
+		},
+		{
+			name:     "LDM guest boots on SATA",
+			migobj:   &Migrate{isLDMGuest: true},
+			vminfo:   vm.VMInfo{OSType: "windows"},
+			expected: map[string]string{"os_type": "windows", imagePropDiskBus: diskBusSATA},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.migobj.exportImageProperties(tt.vminfo); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("exportImageProperties() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/virtv2v"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// inspectWindowsStorage reads the storage layout of a guest, tests replace it
+var inspectWindowsStorage = virtv2v.InspectWindowsStorage
+
+// unsupportedFilesystems are the file systems Windows guests use that libguestfs
+// cannot open, by their list-filesystems type
+var unsupportedFilesystems = map[string]string{
+	"refs": "ReFS",
+}
+
+// windowsStorageFindings classifies the storage layout of a Windows guest.
+// Without a root the system volume is one of the volumes that could not be read,
+// which makes every finding blocking.
+func windowsStorageFindings(storage virtv2v.WindowsStorage) []vjailbreakv1alpha1.GuestStorageFinding {
+	hasRoot := len(storage.Roots) > 0
+	var findings []vjailbreakv1alpha1.GuestStorageFinding
+	add := func(device string, kind vjailbreakv1alpha1.GuestStorageKind, severity vjailbreakv1alpha1.GuestStorageSeverity,
+		message, remediation string,
+	) {
+		if !hasRoot {
+			severity = vjailbreakv1alpha1.GuestStorageSeverityBlocking
+		}
+		findings = append(findings, vjailbreakv1alpha1.GuestStorageFinding{
+			Device: device, Kind: kind, Severity: severity, Message: message, Remediation: remediation,
+		})
+	}
+
+	devices := make([]string, 0, len(storage.Filesystems))
+	for device := range storage.Filesystems {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+	for _, device := range devices {
+		fsType := strings.ToLower(storage.Filesystems[device])
+		switch {
+		case fsType == "bitlocker" && hasRoot:
+			add(device, vjailbreakv1alpha1.GuestStorageKindBitLocker, vjailbreakv1alpha1.GuestStorageSeverityWarning,
+				"BitLocker encrypted data volume, copied encrypted",
+				"Its TPM protector will not unlock it on the new hardware: keep the recovery key at hand, "+
+					"or turn BitLocker off on the source VM before migrating")
+		case fsType == "bitlocker":
+			add(device, vjailbreakv1alpha1.GuestStorageKindBitLocker, vjailbreakv1alpha1.GuestStorageSeverityBlocking,
+				"BitLocker encrypted volume, conversion cannot read the system volume through it",
+				"Turn BitLocker off on the source VM (manage-bde -off C:) and wait for the decryption "+
+					"to finish before migrating again")
+		case unsupportedFilesystems[fsType] != "":
+			add(device, vjailbreakv1alpha1.GuestStorageKindUnsupportedFilesystem, vjailbreakv1alpha1.GuestStorageSeverityWarning,
+				fmt.Sprintf("%s volume, libguestfs cannot open it", unsupportedFilesystems[fsType]),
+				"The volume is copied as is and Windows mounts it after cutover. The system volume has to be NTFS.")
+		}
+	}
+
+	root := ""
+	if hasRoot {
+		root = storage.Roots[0]
+	}
+	for _, volume := range storage.LDMVolumes {
+		if volume == root {
+			add(volume, vjailbreakv1alpha1.GuestStorageKindDynamicDisk, vjailbreakv1alpha1.GuestStorageSeverityRemediated,
+				"The system volume is on a Windows Dynamic Disk, which virt-v2v cannot convert",
+				"Conversion is skipped and the VM is created on an emulated SATA bus with a virtio probe volume")
+			continue
+		}
+		add(volume, vjailbreakv1alpha1.GuestStorageKindDynamicDisk, vjailbreakv1alpha1.GuestStorageSeverityWarning,
+			"Volume on a Windows Dynamic Disk",
+			"Windows imports the dynamic disk group on first boot. Import it in Disk Management if it shows as Foreign.")
+	}
+
+	partitions := make([]string, 0, len(storage.PartitionTypes))
+	for partition := range storage.PartitionTypes {
+		partitions = append(partitions, partition)
+	}
+	sort.Strings(partitions)
+	for _, partition := range partitions {
+		switch storage.PartitionTypes[partition] {
+		case virtv2v.GPTTypeStorageSpaces:
+			add(partition, vjailbreakv1alpha1.GuestStorageKindStorageSpaces, vjailbreakv1alpha1.GuestStorageSeverityWarning,
+				"Member of a Storage Spaces pool, libguestfs cannot read the pool",
+				"Windows reassembles the pool on first boot when every member disk is migrated with the VM")
+		case virtv2v.GPTTypeLDMData, virtv2v.MBRIDLDM:
+			// libguestfs assembles the volumes of dynamic disks it can read, these
+			// are reported above
+			if len(storage.LDMVolumes) == 0 {
+				add(partition, vjailbreakv1alpha1.GuestStorageKindDynamicDisk, vjailbreakv1alpha1.GuestStorageSeverityWarning,
+					"Windows Dynamic Disk whose volumes libguestfs could not assemble",
+					"Make sure every disk of the dynamic disk group is migrated with the VM")
+			}
+		}
+	}
+	return findings
+}
+
+// preflightWindowsStorage inspects the copied disks of a Windows guest for the
+// storage layouts conversion cannot take as is: BitLocker, Dynamic Disks,
+// Storage Spaces and unsupported file systems. The findings are logged and
+// recorded on the Migration status, and a blocking one fails the migration with
+// what to do about it. A failure to inspect is never fatal, conversion then
+// finds out as it always did.
+func (migobj *Migrate) preflightWindowsStorage(ctx context.Context, vminfo vm.VMInfo) error {
+	if !strings.EqualFold(vminfo.OSType, constants.OSFamilyWindows) {
+		return nil
+	}
+	storage, err := inspectWindowsStorage(vminfo.VMDisks)
+	if err != nil {
+		utils.PrintLog(fmt.Sprintf("Warning: Windows storage preflight skipped: %v", err))
+		return nil
+	}
+	findings := windowsStorageFindings(storage)
+	if len(findings) == 0 {
+		migobj.logMessage("Windows storage preflight passed")
+		return nil
+	}
+
+	var blocking []string
+	for _, finding := range findings {
+		migobj.logMessage(fmt.Sprintf("Windows storage preflight: %s %s on %s: %s. %s",
+			finding.Severity, finding.Kind, finding.Device, finding.Message, finding.Remediation))
+		if finding.Severity == vjailbreakv1alpha1.GuestStorageSeverityBlocking {
+			blocking = append(blocking, fmt.Sprintf("%s on %s: %s", finding.Kind, finding.Device, finding.Remediation))
+		}
+	}
+	if migobj.K8sClient != nil {
+		if err := migobj.reportGuestStorage(ctx, findings); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to report guest storage findings: %v", err))
+		}
+	}
+	if len(blocking) > 0 {
+		return errors.Errorf("no Windows system volume could be read from the copied disks: %s",
+			strings.Join(blocking, "; "))
+	}
+	return nil
+}
+
+// reportGuestStorage records the guest storage findings on the Migration status
+func (migobj *Migrate) reportGuestStorage(ctx context.Context, findings []vjailbreakv1alpha1.GuestStorageFinding) error {
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return errors.Wrap(err, "failed to get migration object name")
+	}
+	migration := &vjailbreakv1alpha1.Migration{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration); err != nil {
+		return errors.Wrapf(err, "failed to get migration %s to patch guest storage findings", migrationName)
+	}
+	patch := client.MergeFrom(migration.DeepCopy())
+	migration.Status.GuestStorage = findings
+	if err := migobj.K8sClient.Status().Patch(ctx, migration, patch); err != nil {
+		return errors.Wrapf(err, "failed to patch guest storage findings on migration %s", migrationName)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/virtv2v"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+func TestWindowsStorageFindings(t *testing.T) {
+	kinds := func(findings []vjailbreakv1alpha1.GuestStorageFinding) map[string]string {
+		got := map[string]string{}
+		for _, finding := range findings {
+			got[finding.Device] = string(finding.Kind) + "/" + string(finding.Severity)
+		}
+		return got
+	}
+	tests := []struct {
+		name    string
+		storage virtv2v.WindowsStorage
+		want    map[string]string
+	}{
+		{
+			name: "plain NTFS",
+			storage: virtv2v.WindowsStorage{
+				Roots:       []string{"/dev/sda2"},
+				Filesystems: map[string]string{"/dev/sda1": "ntfs", "/dev/sda2": "ntfs"},
+			},
+			want: map[string]string{},
+		},
+		{
+			name: "BitLocker system volume",
+			storage: virtv2v.WindowsStorage{
+				Filesystems: map[string]string{"/dev/sda1": "ntfs", "/dev/sda2": "BitLocker"},
+			},
+			want: map[string]string{"/dev/sda2": "BitLocker/Blocking"},
+		},
+		{
+			name: "data volumes",
+			storage: virtv2v.WindowsStorage{
+				Roots: []string{"/dev/sda2"},
+				Filesystems: map[string]string{
+					"/dev/sda2": "ntfs", "/dev/sdb1": "BitLocker", "/dev/sdc1": "refs", "/dev/sde1": "unknown",
+				},
+				LDMVolumes: []string{"/dev/mapper/ldm_vol_data"},
+				PartitionTypes: map[string]string{
+					"/dev/sdd1": virtv2v.GPTTypeLDMData,
+					"/dev/sde1": virtv2v.GPTTypeStorageSpaces,
+				},
+			},
+			want: map[string]string{
+				"/dev/sdb1":                "BitLocker/Warning",
+				"/dev/sdc1":                "UnsupportedFilesystem/Warning",
+				"/dev/mapper/ldm_vol_data": "DynamicDisk/Warning",
+				"/dev/sde1":                "StorageSpaces/Warning",
+			},
+		},
+		{
+			name: "dynamic system volume",
+			storage: virtv2v.WindowsStorage{
+				Roots:      []string{"/dev/mapper/ldm_vol_c"},
+				LDMVolumes: []string{"/dev/mapper/ldm_vol_c"},
+			},
+			want: map[string]string{"/dev/mapper/ldm_vol_c": "DynamicDisk/Remediated"},
+		},
+		{
+			name: "dynamic disk libguestfs could not assemble",
+			storage: virtv2v.WindowsStorage{
+				PartitionTypes: map[string]string{"/dev/sda1": virtv2v.MBRIDLDM, "/dev/sdb1": virtv2v.MBRIDLDM},
+			},
+			want: map[string]string{"/dev/sda1": "DynamicDisk/Blocking", "/dev/sdb1": "DynamicDisk/Blocking"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, kinds(windowsStorageFindings(tt.storage)))
+		})
+	}
+}
+
+func TestPreflightWindowsStorage(t *testing.T) {
+	builder, migrationName := checkpointTestClient(t)
+	k8sClient := builder.WithStatusSubresource(&vjailbreakv1alpha1.Migration{}).Build()
+	ctx := context.Background()
+	migobj := &Migrate{K8sClient: k8sClient}
+	windows := vm.VMInfo{OSType: constants.OSFamilyWindows, VMDisks: []vm.VMDisk{{Path: "/dev/vdb"}}}
+
+	previous := inspectWindowsStorage
+	t.Cleanup(func() { inspectWindowsStorage = previous })
+	inspected := 0
+	inspect := func(storage virtv2v.WindowsStorage, err error) {
+		inspectWindowsStorage = func([]vm.VMDisk) (virtv2v.WindowsStorage, error) {
+			inspected++
+			return storage, err
+		}
+	}
+
+	// Linux guests are not inspected, and an inspection failure is not fatal
+	inspect(virtv2v.WindowsStorage{}, errors.New("appliance failed"))
+	require.NoError(t, migobj.preflightWindowsStorage(ctx, vm.VMInfo{OSType: constants.OSFamilyLinux}))
+	assert.Zero(t, inspected)
+	require.NoError(t, migobj.preflightWindowsStorage(ctx, windows))
+	assert.Equal(t, 1, inspected)
+
+	inspect(virtv2v.WindowsStorage{Filesystems: map[string]string{"/dev/sda2": "BitLocker"}}, nil)
+	err := migobj.preflightWindowsStorage(ctx, windows)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "manage-bde -off")
+
+	migration := &vjailbreakv1alpha1.Migration{}
+	require.NoError(t, k8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration))
+	require.Len(t, migration.Status.GuestStorage, 1)
+	assert.Equal(t, vjailbreakv1alpha1.GuestStorageKindBitLocker, migration.Status.GuestStorage[0].Kind)
+	assert.Equal(t, vjailbreakv1alpha1.GuestStorageSeverityBlocking, migration.Status.GuestStorage[0].Severity)
+}
@@ -3,18 +3,22 @@
 package migrate
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
 	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/pkg/common/libvirt"
 	esxissh "github.com/platform9/vjailbreak/v2v-helper/esxi-ssh"
+	"github.com/platform9/vjailbreak/v2v-helper/nbd"
 	k8sutils "github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
 	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
 	"github.com/platform9/vjailbreak/v2v-helper/vcenter"
@@ -339,25 +343,53 @@ func (migobj *Migrate) findFreePorts(sshClient *esxissh.Client, rangeMin, rangeM
 	return ports, nil
 }
 
-// serveViaNBD starts qemu-nbd on the Proxy VM via nohup and returns its PID.
-// Polls /proc/net/tcp until the port is bound before returning.
-func (migobj *Migrate) serveViaNBD(sshClient *esxissh.Client, blockDevice string, port int) (int, error) {
+// serveViaNBD starts qemu-nbd on the Proxy VM via nohup, listening on port of
+// all its interfaces, and returns its PID. image is a block device or image
+// file of the given qemu format, passed to the remote shell as is. Polls
+// /proc/net/tcp until the port is bound before returning.
+func (migobj *Migrate) serveViaNBD(sshClient *esxissh.Client, image, format string, readOnly bool, port int) (int, error) {
 	portHex := strings.ToUpper(fmt.Sprintf("%04x", port))
+	readOnlyFlag := ""
+	if readOnly {
+		readOnlyFlag = "--read-only "
+	}
+	return startQemuNBD(sshClient,
+		fmt.Sprintf("%s--format=%s --port=%d --bind=0.0.0.0 --persistent %s", readOnlyFlag, format, port, image),
+		fmt.Sprintf(`grep -q ":%s " /proc/net/tcp /proc/net/tcp6 2>/dev/null`, portHex),
+		fmt.Sprintf("port %d", port))
+}
+
+// serveViaNBDSocket starts a read-only qemu-nbd on a host reached over SSH,
+// such as a KVM or Proxmox VE host, that listens on the unix socket only and
+// returns its PID. Unlike serveViaNBD nothing is opened on the host's network,
+// the copy reaches the socket through esxissh.Client.ForwardUnixSocket.
+func serveViaNBDSocket(sshClient *esxissh.Client, image, format, socket string) (int, error) {
+	quotedSocket := libvirt.ShellQuote(socket)
+	return startQemuNBD(sshClient,
+		fmt.Sprintf("--read-only --format=%s --socket=%s --persistent %s", format, quotedSocket, image),
+		fmt.Sprintf("[ -S %s ]", quotedSocket),
+		socket)
+}
+
+// startQemuNBD runs qemu-nbd with args via nohup, waits up to five seconds
+// for ready to succeed and returns its PID. listen names where it listens in
+// errors.
+func startQemuNBD(sshClient *esxissh.Client, args, ready, listen string) (int, error) {
 	cmd := fmt.Sprintf(
-		`nohup qemu-nbd --format=raw --port=%d --bind=0.0.0.0 --persistent %s </dev/null >/dev/null 2>&1 & `+
+		`nohup qemu-nbd %s </dev/null >/dev/null 2>&1 & `+
 			`pid=$!; i=0; `+
-			`while [ $i -lt 20 ] && ! grep -q ":%s " /proc/net/tcp /proc/net/tcp6 2>/dev/null; `+
+			`while [ $i -lt 20 ] && ! %s; `+
 			`do i=$((i+1)); sleep 0.25; done; `+
 			`echo $pid`,
-		port, blockDevice, portHex,
+		args, ready,
 	)
 	out, err := sshClient.ExecuteCommand(cmd)
 	if err != nil {
-		return 0, errors.Wrapf(err, "qemu-nbd failed to start on port %d", port)
+		return 0, errors.Wrapf(err, "qemu-nbd failed to start on %s", listen)
 	}
 	pidStr := strings.TrimSpace(out)
 	if pidStr == "" {
-		return 0, fmt.Errorf("qemu-nbd started on port %d but PID was not captured", port)
+		return 0, fmt.Errorf("qemu-nbd started on %s but PID was not captured", listen)
 	}
 	pid, err := strconv.Atoi(pidStr)
 	if err != nil {
@@ -366,15 +398,19 @@ func (migobj *Migrate) serveViaNBD(sshClient *esxissh.Client, blockDevice string
 	return pid, nil
 }
 
-// runNBDCopy transfers data from an NBD source on the proxy VM to a local block device.
-// Retries up to hotAddNBDCopyRetries times.
-func (migobj *Migrate) runNBDCopy(ctx context.Context, proxyIP string, port int, destDevice string) error {
-	nbdURL := fmt.Sprintf("nbd://%s:%d", proxyIP, port)
+// runNBDCopy transfers data from an NBD source, such as the proxy VM, to a local block device.
+// Retries up to hotAddNBDCopyRetries times. The source is read through migobj.Throttle.
+// onProgress, unless nil, is called with the percentage of each attempt nbdcopy reports.
+func (migobj *Migrate) runNBDCopy(ctx context.Context, host string, port int, destDevice string, onProgress func(percent int)) error {
+	nbdURL := fmt.Sprintf("nbd://%s:%d", host, port)
+	sourceURL, stopProxy, err := migobj.Throttle.Proxy(nbdURL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to throttle nbdcopy from %s", nbdURL)
+	}
+	defer stopProxy()
 	for attempt := 1; attempt <= hotAddNBDCopyRetries; attempt++ {
 		migobj.logMessage(fmt.Sprintf("nbdcopy attempt %d/%d: %s → %s", attempt, hotAddNBDCopyRetries, nbdURL, destDevice))
-		//nolint:gosec // nbdURL and destDevice come from validated internal state, not user input
-		cmd := exec.CommandContext(ctx, "nbdcopy", nbdURL, destDevice)
-		out, err := cmd.CombinedOutput()
+		out, err := runNBDCopyWithProgress(ctx, sourceURL, destDevice, onProgress)
 		if err == nil {
 			return nil
 		}
@@ -386,6 +422,38 @@ func (migobj *Migrate) runNBDCopy(ctx context.Context, proxyIP string, port int,
 	return fmt.Errorf("nbdcopy failed after %d attempts: %s → %s", hotAddNBDCopyRetries, nbdURL, destDevice)
 }
 
+// runNBDCopyWithProgress runs one nbdcopy from sourceURL to destDevice and
+// returns its output. Its progress goes to onProgress when that is not nil.
+func runNBDCopyWithProgress(ctx context.Context, sourceURL, destDevice string, onProgress func(percent int)) ([]byte, error) {
+	if onProgress == nil {
+		//nolint:gosec // sourceURL and destDevice come from validated internal state, not user input
+		return exec.CommandContext(ctx, "nbdcopy", sourceURL, destDevice).CombinedOutput()
+	}
+	progressRead, progressWrite, err := os.Pipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create pipe")
+	}
+	defer progressRead.Close()
+	//nolint:gosec // sourceURL and destDevice come from validated internal state, not user input
+	cmd := exec.CommandContext(ctx, "nbdcopy", "--progress=3", sourceURL, destDevice)
+	cmd.ExtraFiles = []*os.File{progressWrite}
+	scanned := make(chan struct{})
+	go func() {
+		defer close(scanned)
+		scanner := bufio.NewScanner(progressRead)
+		for scanner.Scan() {
+			if percent, _, err := utils.ParseFraction(scanner.Text()); err == nil {
+				onProgress(percent)
+			}
+		}
+	}()
+	out, err := cmd.CombinedOutput()
+	// The scanner ends once no process holds the write end any more
+	progressWrite.Close()
+	<-scanned
+	return out, err
+}
+
 // adjustProxyDiskCount atomically adds delta to the ProxyVM's AttachedDiskCount status.
 // Failures are non-fatal and are logged without aborting the caller.
 func (migobj *Migrate) adjustProxyDiskCount(ctx context.Context, delta int) {
@@ -599,54 +667,105 @@ func (migobj *Migrate) HotAddCopyDisks(ctx context.Context, vminfo vm.VMInfo) er
 		transfers[i].NBDPort = ports[i]
 	}
 
-	// 9. Copy all disks concurrently — each disk gets its own NBD server and nbdcopy.
+	// 9. Copy the disks concurrently — each disk gets its own NBD server and nbdcopy.
+	//    All of them at once unless the MigrationPlan or the ESXi host budget allow fewer.
 	overallStart := time.Now()
-	errCh := make(chan error, len(transfers))
-	var wg sync.WaitGroup
-
-	for i := range transfers {
-		wg.Add(1)
-		t := &transfers[i]
-		idx := i
-		go func() {
-			defer wg.Done()
-			diskStart := time.Now()
-
-			migobj.logMessage(fmt.Sprintf("%s port %d for %s (disk %d/%d)",
-				constants.EventMessageHotAddServing, t.NBDPort, t.BlockDevice, idx+1, len(transfers)))
-			pid, err := migobj.serveViaNBD(sshClient, t.BlockDevice, t.NBDPort)
-			if err != nil {
-				errCh <- errors.Wrapf(err, "disk %d: failed to start NBD server on port %d", idx+1, t.NBDPort)
-				return
-			}
-			t.NBDPid = pid
+	workers := migobj.diskCopyWorkers(ctx, len(transfers), len(transfers))
+	err = copyDisksInParallel(ctx, len(transfers), workers, func(ctx context.Context, idx int) error {
+		t := &transfers[idx]
+		diskStart := time.Now()
+
+		migobj.logMessage(fmt.Sprintf("%s port %d for %s (disk %d/%d)",
+			constants.EventMessageHotAddServing, t.NBDPort, t.BlockDevice, idx+1, len(transfers)))
+		pid, err := migobj.serveViaNBD(sshClient, t.BlockDevice, "raw", false, t.NBDPort)
+		if err != nil {
+			return errors.Wrapf(err, "disk %d: failed to start NBD server on port %d", idx+1, t.NBDPort)
+		}
+		t.NBDPid = pid
 
-			migobj.logMessage(fmt.Sprintf("%s nbd://%s:%d → %s (disk %d/%d)",
-				constants.EventMessageHotAddCopying, migobj.ProxyVMIP, t.NBDPort, t.DestDevice, idx+1, len(transfers)))
-			if err := migobj.runNBDCopy(ctx, migobj.ProxyVMIP, t.NBDPort, t.DestDevice); err != nil {
-				errCh <- errors.Wrapf(err, "disk %d: nbdcopy failed", idx+1)
-				return
+		migobj.logMessage(fmt.Sprintf("%s nbd://%s:%d → %s (disk %d/%d)",
+			constants.EventMessageHotAddCopying, migobj.ProxyVMIP, t.NBDPort, t.DestDevice, idx+1, len(transfers)))
+		var onProgress func(percent int)
+		if idx < len(vminfo.VMDisks) {
+			disk := vminfo.VMDisks[idx]
+			onProgress = func(percent int) {
+				migobj.recordTransfer(disk.Name, vjailbreakv1alpha1.DiskTransferMethodHotAdd, disk.Size*int64(percent)/100, disk.Size)
 			}
+		}
+		if err := migobj.runNBDCopy(ctx, migobj.ProxyVMIP, t.NBDPort, t.DestDevice, onProgress); err != nil {
+			return errors.Wrapf(err, "disk %d: nbdcopy failed", idx+1)
+		}
 
-			migobj.logMessage(fmt.Sprintf("Disk %d/%d copied in %s: %s → %s",
-				idx+1, len(transfers), time.Since(diskStart).Round(time.Second),
-				t.BlockDevice, t.DestDevice))
-		}()
+		migobj.logMessage(fmt.Sprintf("Disk %d/%d copied in %s: %s → %s",
+			idx+1, len(transfers), time.Since(diskStart).Round(time.Second),
+			t.BlockDevice, t.DestDevice))
+		return nil
+	})
+	overallDuration := time.Since(overallStart)
+	if err != nil {
+		return err
 	}
+	migobj.logMessage(fmt.Sprintf("All %d disk(s) copied in %s", len(transfers), overallDuration.Round(time.Second)))
 
-	wg.Wait()
-	close(errCh)
-
-	overallDuration := time.Since(overallStart)
-	var copyErrors []string
-	for err := range errCh {
-		copyErrors = append(copyErrors, err.Error())
+	// 10. Compare the volumes with the frozen disks while qemu-nbd still serves them
+	verifiers := make([]diskVerifier, len(transfers))
+	for i := range transfers {
+		verifiers[i] = nbd.Source{URI: fmt.Sprintf("nbd://%s:%d", migobj.ProxyVMIP, transfers[i].NBDPort), Throttle: migobj.Throttle}
 	}
-	if len(copyErrors) > 0 {
-		return fmt.Errorf("one or more disks failed to copy:\n  %s", strings.Join(copyErrors, "\n  "))
+	if err := migobj.verifyDisks(ctx, vminfo, verifiers); err != nil {
+		return errors.Wrap(err, "disk verification failed")
 	}
-	migobj.logMessage(fmt.Sprintf("All %d disk(s) copied in %s", len(transfers), overallDuration.Round(time.Second)))
 
 	migobj.logMessage("Hot-Add disk copy completed successfully")
 	return nil
 }
+
+// copyDisksFromHost copies every disk of src into its attached volume at
+// vminfo.VMDisks[i].Path, serving one disk at a time from the KVM or Proxmox VE
+// host with qemu-nbd. disk.image is the path on the host. The host belongs to
+// the customer, so qemu-nbd only listens on a unix socket in a private
+// directory and the copy reads it through the SSH connection.
+func (migobj *Migrate) copyDisksFromHost(ctx context.Context, sshClient *esxissh.Client, host string,
+	src *applianceSource, vminfo vm.VMInfo,
+) error {
+	out, err := sshClient.ExecuteCommand("mktemp -d /tmp/vjailbreak-nbd.XXXXXX")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create a socket directory on %s", host)
+	}
+	socketDir := strings.TrimSpace(out)
+	defer func() {
+		if _, err := sshClient.ExecuteCommand("rm -rf " + libvirt.ShellQuote(socketDir)); err != nil {
+			utils.PrintLog(fmt.Sprintf("Warning: failed to remove %s on %s: %v", socketDir, host, err))
+		}
+	}()
+
+	for idx, disk := range src.disks {
+		migobj.logMessage(fmt.Sprintf("Copying disk %d/%d: %s", idx+1, len(src.disks), disk.image))
+		// A socket per disk, so a qemu-nbd that is slow to exit is never mistaken for the next one
+		socket := path.Join(socketDir, fmt.Sprintf("disk%d.sock", idx))
+		pid, err := serveViaNBDSocket(sshClient, libvirt.ShellQuote(disk.image), disk.format, socket)
+		if err != nil {
+			return errors.Wrapf(err, "failed to serve %s", disk.image)
+		}
+		copyErr := migobj.copyFromHostSocket(ctx, sshClient, socket, vminfo.VMDisks[idx].Path)
+		if _, err := sshClient.ExecuteCommand(fmt.Sprintf("kill %d 2>/dev/null; true", pid)); err != nil {
+			utils.PrintLog(fmt.Sprintf("Warning: failed to kill qemu-nbd PID %d on %s: %v", pid, host, err))
+		}
+		if copyErr != nil {
+			return errors.Wrapf(copyErr, "failed to copy %s", disk.image)
+		}
+		migobj.logMessage(fmt.Sprintf("Copying disk %d, Completed: 100%%", idx))
+	}
+	return nil
+}
+
+// copyFromHostSocket copies the NBD export on the unix socket of the SSH host
+// to destDevice
+func (migobj *Migrate) copyFromHostSocket(ctx context.Context, sshClient *esxissh.Client, socket, destDevice string) error {
+	forward, err := sshClient.ForwardUnixSocket(socket)
+	if err != nil {
+		return errors.Wrapf(err, "failed to forward %s", socket)
+	}
+	defer forward.Close()
+	return migobj.runNBDCopy(ctx, "127.0.0.1", forward.Port(), destDevice, nil)
+}
@@ -0,0 +1,117 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/pkg/common/libvirt"
+	esxissh "github.com/platform9/vjailbreak/v2v-helper/esxi-ssh"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+)
+
+// MigrateLibvirtDomain creates an OpenStack instance from a shut off domain on a
+// KVM host. The domain XML stands in for GetVMInfo. Each disk is served from the
+// host with qemu-nbd, which reads qcow2 and raw alike, and copied into its
+// volume with nbdcopy over the SSH connection. A guest that already boots from virtio disks needs none
+// of the driver changes virt-v2v makes, so the conversion is skipped for it.
+func (migobj *Migrate) MigrateLibvirtDomain(ctx context.Context, uri, domainName, targetName string) error {
+	conn, err := libvirt.ParseURI(uri)
+	if err != nil {
+		return err
+	}
+	privateKey := os.Getenv(constants.LibvirtSSHPrivateKeyEnv)
+	if privateKey == "" {
+		return errors.Errorf("%s is not set, the SSH key of %s is needed", constants.LibvirtSSHPrivateKeyEnv, conn.Host)
+	}
+
+	sshClient := esxissh.NewClient()
+	if err := sshClient.Connect(ctx, conn.Host, conn.User, []byte(privateKey)); err != nil {
+		return errors.Wrapf(err, "failed to connect to %s", conn.Host)
+	}
+	defer func() {
+		if err := sshClient.Disconnect(); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to disconnect from %s: %v", conn.Host, err))
+		}
+	}()
+
+	domain, sizes, err := readLibvirtDomain(sshClient, conn, domainName)
+	if err != nil {
+		return err
+	}
+	src := libvirtApplianceSource(domain, sizes)
+	migobj.logMessage(fmt.Sprintf("Read domain %s: %d CPU, %d MB memory, %d disk(s), %d NIC(s)",
+		src.name, src.cpu, src.memoryMB, len(src.disks), len(src.macs)))
+	if domain.AllDisksVirtio() {
+		migobj.logMessage(fmt.Sprintf("All disks of domain %s are on the virtio bus, skipping virt-v2v conversion", domainName))
+		migobj.Convert = false
+	}
+
+	vminfo, err := applianceVMInfo(src, migobj.Ostype, len(migobj.Networknames))
+	if err != nil {
+		return err
+	}
+	if targetName != "" {
+		vminfo.Name = targetName
+	}
+	return migobj.createInstanceFromDisks(ctx, vminfo, func(vminfo vm.VMInfo) error {
+		return migobj.copyDisksFromHost(ctx, sshClient, conn.Host, src, vminfo)
+	})
+}
+
+// readLibvirtDomain reads the definition and disk sizes of a shut off domain.
+// The disks of a running domain would change under the copy.
+func readLibvirtDomain(sshClient *esxissh.Client, conn *libvirt.Connection, domainName string) (*libvirt.Domain, []int64, error) {
+	out, err := sshClient.ExecuteCommand(conn.VirshCommand("domstate", domainName))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get state of domain %s: %s", domainName, strings.TrimSpace(out))
+	}
+	if state := strings.TrimSpace(out); state != libvirt.DomainStateShutOff {
+		return nil, nil, errors.Errorf("domain %s is %s, shut it down before migrating it", domainName, state)
+	}
+
+	out, err = sshClient.ExecuteCommand(conn.VirshCommand("dumpxml", "--inactive", domainName))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read domain %s: %s", domainName, strings.TrimSpace(out))
+	}
+	domain, err := libvirt.ParseDomain(strings.NewReader(out))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sizes := make([]int64, len(domain.Disks))
+	for idx, disk := range domain.Disks {
+		out, err := sshClient.ExecuteCommand(conn.VirshCommand("domblkinfo", domainName, disk.Target))
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to get size of disk %s: %s", disk.Target, strings.TrimSpace(out))
+		}
+		if sizes[idx], err = libvirt.ParseBlockCapacity(out); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to get size of disk %s", disk.Target)
+		}
+	}
+	return domain, sizes, nil
+}
+
+// libvirtApplianceSource describes the domain as the import path expects
+func libvirtApplianceSource(domain *libvirt.Domain, sizes []int64) *applianceSource {
+	src := &applianceSource{
+		name:     domain.Name,
+		cpu:      domain.CPU,
+		memoryMB: domain.MemoryMB,
+		uefi:     domain.UEFI,
+		osFamily: domain.OSFamily,
+	}
+	for _, nic := range domain.NICs {
+		src.macs = append(src.macs, nic.MAC)
+	}
+	for idx, disk := range domain.Disks {
+		src.disks = append(src.disks, applianceDisk{name: disk.Target, size: sizes[idx], image: disk.Source, format: disk.Format})
+	}
+	return src
+}
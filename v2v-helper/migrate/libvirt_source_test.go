@@ -0,0 +1,52 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/platform9/vjailbreak/pkg/common/libvirt"
+)
+
+func TestLibvirtApplianceSource(t *testing.T) {
+	domain := &libvirt.Domain{
+		Name:     "web01",
+		CPU:      2,
+		MemoryMB: 4096,
+		UEFI:     true,
+		OSFamily: "linuxGuest",
+		Disks: []libvirt.Disk{
+			{Target: "vda", Bus: "virtio", Format: "qcow2", Source: "/var/lib/libvirt/images/web01.qcow2"},
+			{Target: "vdb", Bus: "virtio", Format: "raw", Source: "/dev/vg0/web01-data"},
+		},
+		NICs: []libvirt.NIC{
+			{Network: "default", MAC: "52:54:00:12:34:56", Model: "virtio"},
+		},
+	}
+
+	src := libvirtApplianceSource(domain, []int64{20 << 30, 100 << 30})
+	want := &applianceSource{
+		name:     "web01",
+		cpu:      2,
+		memoryMB: 4096,
+		uefi:     true,
+		osFamily: "linuxGuest",
+		macs:     []string{"52:54:00:12:34:56"},
+		disks: []applianceDisk{
+			{name: "vda", size: 20 << 30, image: "/var/lib/libvirt/images/web01.qcow2", format: "qcow2"},
+			{name: "vdb", size: 100 << 30, image: "/dev/vg0/web01-data", format: "raw"},
+		},
+	}
+	if !reflect.DeepEqual(src, want) {
+		t.Errorf("libvirtApplianceSource() = %+v, want %+v", src, want)
+	}
+
+	vminfo, err := applianceVMInfo(src, "", 1)
+	if err != nil {
+		t.Fatalf("applianceVMInfo() unexpected error: %v", err)
+	}
+	if vminfo.Mac[0] != "52:54:00:12:34:56" || len(vminfo.VMDisks) != 2 || vminfo.VMDisks[1].Size != 100<<30 {
+		t.Errorf("applianceVMInfo() = %+v, want the domain MAC and both disks", vminfo)
+	}
+}
@@ -8,10 +8,13 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/platform9/vjailbreak/pkg/common/bandwidth"
 	"github.com/platform9/vjailbreak/pkg/common/constants"
 	"github.com/platform9/vjailbreak/v2v-helper/nbd"
 	"github.com/platform9/vjailbreak/v2v-helper/openstack"
@@ -84,10 +87,67 @@ type Migrate struct {
 	// plus user-entered custom metadata) applied to the target VM at create time.
 	TargetMetadata map[string]string
 
+	// PowerStoreAppliance is the appliance PowerStore volumes are created on,
+	// empty to let PowerStore place them
+	PowerStoreAppliance string
+	// HPECPG is the CPG HPE volumes are created in, empty to fall back to
+	// the only CPG of the array
+	HPECPG string
+	// Simulated array state file and injected faults, see the simulator
+	// storage provider
+	SimulatorStateFile    string
+	SimulatorCreateDelay  string
+	SimulatorFailMapping  bool
+	SimulatorNAACollision bool
+	// ArrayNativeCopy extracts the disks from an array clone of their VMFS
+	// datastore, see ArrayNativeCopyDisks
+	ArrayNativeCopy bool
+
+	// SourceHostType is "esxi" when URL is a standalone ESXi host rather than a
+	// vCenter. Disks are then copied cold over SSH, see ESXiCopyDisks.
+	SourceHostType string
+
+	// DestinationType is "kubevirt" when Openstackclients is the KubeVirt
+	// destination. The vJailbreak VM is then not the server volumes attach to.
+	DestinationType string
+
 	// DataOnly indicates no OpenStack VM should be created after disk conversion.
 	// When true, port reservation and VM creation are skipped and a DataCopied
 	// phase is reported instead of Succeeded.
 	DataOnly bool
+	// ExportTarget is "pvc" or "glance" to export the converted disks of a DataOnly
+	// migration as qcow2 files or Glance images, see ExportDisks
+	ExportTarget          string
+	ExportCompress        bool
+	ExportImageVisibility string
+	ExportKeepVolumes     bool
+
+	// Bandwidth is the MigrationPlan cap on the disk copy rate. Throttle enforces
+	// it together with the global cap, see StartBandwidthThrottle.
+	Bandwidth bandwidth.Schedule
+	Throttle  *nbd.Throttle
+	// VerifyMode compares the copied disks with the source before the VM is
+	// created, Sampled reads back VerifySamplePercent of each disk and Full all of it
+	VerifyMode          string
+	VerifySamplePercent int
+	// ParallelDiskCopies is how many disks are copied at once, see diskCopyWorkers
+	ParallelDiskCopies int
+	// VDDKTransport selects the VDDK transport modes and compression of the NBD copy
+	VDDKTransport nbd.VDDKTransport
+
+	// copyCheckpoint is the progress of LiveReplicateDisks, saved as it goes so a
+	// restarted pod can resume the copy, see CopyCheckpoint
+	copyCheckpoint *CopyCheckpoint
+	// copyCheckpointMu guards copyCheckpoint while disks are copied in parallel
+	copyCheckpointMu sync.Mutex
+	// keepForResume is set when the pod is terminated in the middle of a copy
+	// that the next pod can resume, cleanup then leaves everything in place
+	keepForResume atomic.Bool
+	// transfers is the throughput of the disk copies, see publishTransfers
+	transfers diskTransfers
+	// convergence measures the changed-block syncs of a warm migration to
+	// predict its cutover downtime
+	convergence cbtConvergence
 
 	// isLDMGuest is set once during ConvertVolumes when the Windows system volume
 	// is found on a Dynamic Disk (LDM). ConvertVolumes must know this before it
@@ -202,6 +262,8 @@ func (migobj *Migrate) gracefulTerminate(ctx context.Context, vminfo vm.VMInfo,
 	signal.Notify(gracefulShutdown, syscall.SIGTERM, syscall.SIGINT)
 	<-gracefulShutdown
 	migobj.logMessage("Gracefully terminating")
+	// Decided before cancel, the failing copy runs cleanup as well
+	migobj.keepForResume.Store(migobj.resumableAfterTermination(ctx))
 	cancel()
 	migobj.cleanup(ctx, vminfo, "Migration terminated", nil, nil)
 	os.Exit(0)
@@ -234,8 +296,15 @@ func (migobj *Migrate) MigrateVM(ctx context.Context) error {
 	if !migobj.DataOnly && len(vminfo.Mac) != len(migobj.Networknames) {
 		return errors.Errorf("number of mac addresses does not match number of network names mac(%d) network(%d)", len(vminfo.Mac), len(migobj.Networknames))
 	}
+	if err := migobj.checkVerificationSupported(); err != nil {
+		return err
+	}
+	if err := migobj.checkVDDKTransportSupported(); err != nil {
+		return err
+	}
 	// Graceful Termination clean-up volumes and snapshots
 	go migobj.gracefulTerminate(ctx, vminfo, cancel)
+	go migobj.publishTransfers(ctx)
 
 	// Reserve ports for VM
 	var networkids, portids, ipaddresses []string
@@ -270,8 +339,17 @@ func (migobj *Migrate) MigrateVM(ctx context.Context) error {
 			return errors.Wrap(err, "StorageAcceleratedCopy prerequisites validation failed")
 		}
 
-		// Perform the copy here.
-		if _, err := migobj.StorageAcceleratedCopyCopyDisks(ctx, vminfo); err != nil {
+		// Perform the copy here. A warm copy clones the disks of the running VM
+		// and catches up with the changed blocks over NBD until cutover.
+		if migobj.warmStorageCopy() {
+			vminfo, err = migobj.WarmStorageAcceleratedCopyDisks(ctx, vminfo)
+			if err != nil {
+				if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to perform warm StorageAcceleratedCopy copy: %s", err), portids, vcenterSettings); cleanuperror != nil {
+					return errors.Wrapf(err, "failed to cleanup after warm StorageAcceleratedCopy failure: %s", cleanuperror)
+				}
+				return errors.Wrap(err, "failed to perform warm StorageAcceleratedCopy copy")
+			}
+		} else if _, err := migobj.StorageAcceleratedCopyCopyDisks(ctx, vminfo); err != nil {
 			if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to perform StorageAcceleratedCopy copy: %s", err), portids, vcenterSettings); cleanuperror != nil {
 				return errors.Wrapf(err, "failed to cleanup after StorageAcceleratedCopy failure: %s", cleanuperror)
 			}
@@ -285,6 +363,32 @@ func (migobj *Migrate) MigrateVM(ctx context.Context) error {
 			return errors.Wrap(err, "failed to apply image metadata to XCOPY volumes")
 		}
 
+	} else if migobj.IsStandaloneESXi() {
+
+		vminfo, err = migobj.CreateVolumes(ctx, vminfo)
+		if err != nil {
+			if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to create volumes for ESXi migration: %s", err), portids, vcenterSettings); cleanuperror != nil {
+				return errors.Wrapf(err, "failed to cleanup after ESXi volume creation failure: %s", cleanuperror)
+			}
+			return errors.Wrap(err, "failed to create volumes for ESXi migration")
+		}
+		for idx, vmdisk := range vminfo.VMDisks {
+			path, err := migobj.AttachVolume(ctx, vmdisk)
+			if err != nil {
+				if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to attach volume for ESXi migration: %s", err), portids, vcenterSettings); cleanuperror != nil {
+					return errors.Wrapf(err, "failed to cleanup after ESXi attach failure: %s", cleanuperror)
+				}
+				return errors.Wrap(err, "failed to attach volume for ESXi migration")
+			}
+			vminfo.VMDisks[idx].Path = path
+		}
+		if err := migobj.ESXiCopyDisks(ctx, vminfo); err != nil {
+			if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to copy disks from ESXi host: %s", err), portids, vcenterSettings); cleanuperror != nil {
+				return errors.Wrapf(err, "failed to cleanup after ESXi disk copy failure: %s", cleanuperror)
+			}
+			return errors.Wrap(err, "failed to copy disks from ESXi host")
+		}
+
 	} else if migobj.StorageCopyMethod == constants.HotAddCopyMethod {
 
 		vminfo, err = migobj.CreateVolumes(ctx, vminfo)
@@ -313,8 +417,8 @@ func (migobj *Migrate) MigrateVM(ctx context.Context) error {
 
 	} else {
 
-		// Create and Add Volumes to Host
-		vminfo, err = migobj.CreateVolumes(ctx, vminfo)
+		// Create and Add Volumes to Host, or pick up those of an interrupted copy
+		vminfo, err = migobj.createOrResumeVolumes(ctx, vminfo)
 		if err != nil {
 			if cleanuperror := migobj.cleanup(ctx, vminfo, fmt.Sprintf("failed to create volumes: %s", err), portids, vcenterSettings); cleanuperror != nil {
 				return errors.Wrapf(err, "failed to cleanup after volume creation failure: %s", cleanuperror)
@@ -330,7 +434,7 @@ func (migobj *Migrate) MigrateVM(ctx context.Context) error {
 
 		// Create NBD servers
 		for range vminfo.VMDisks {
-			migobj.Nbdops = append(migobj.Nbdops, &nbd.NBDServer{})
+			migobj.Nbdops = append(migobj.Nbdops, &nbd.NBDServer{Throttle: migobj.Throttle, Transport: migobj.VDDKTransport})
 		}
 
 		// Live Replicate Disks
@@ -369,6 +473,18 @@ func (migobj *Migrate) MigrateVM(ctx context.Context) error {
 
 	if migobj.DataOnly {
 		migobj.logMessage("DataOnly mode: disk copy and conversion complete, skipping VM creation")
+		if migobj.ExportTarget != "" {
+			vminfo, err = migobj.ExportDisks(ctx, vminfo, espDiskIndex)
+			if err != nil {
+				return errors.Wrap(err, "failed to export disks")
+			}
+		}
+		// Exported disks whose volumes were deleted cannot be promoted later
+		if migobj.ExportTarget == "" || migobj.ExportKeepVolumes {
+			if err := migobj.saveStagedVM(ctx, vminfo, espDiskIndex); err != nil {
+				migobj.logMessage(fmt.Sprintf("Warning: failed to save staged VM, its volumes cannot be promoted: %v", err))
+			}
+		}
 		if err := migobj.reportStagedVolumeIDs(ctx, vminfo); err != nil {
 			migobj.logMessage(fmt.Sprintf("Warning: failed to report staged volume IDs: %v", err))
 		}
@@ -693,6 +809,10 @@ func (migobj *Migrate) detachProbeFromServer(ctx context.Context, serverID, prob
 }
 
 func (migobj *Migrate) cleanup(ctx context.Context, vminfo vm.VMInfo, message string, portids []string, vcenterSettings *k8sutils.VjailbreakSettings) error {
+	if migobj.keepForResume.Load() {
+		migobj.logMessage(fmt.Sprintf("%s. Keeping the volumes, the migration snapshot and the disk copy checkpoint for the next pod to resume the copy", message))
+		return nil
+	}
 	migobj.logMessage(fmt.Sprintf("%s. Trying to perform cleanup", message))
 	err := migobj.DetachAllVolumesWithCleanup(ctx, vminfo)
 	if err != nil {
@@ -702,6 +822,8 @@ func (migobj *Migrate) cleanup(ctx context.Context, vminfo vm.VMInfo, message st
 	if err != nil {
 		utils.PrintLog(fmt.Sprintf("Failed to delete all volumes from host: %s\n", err))
 	}
+	// Nothing is left to resume the copy into
+	migobj.deleteCopyCheckpoint(ctx)
 
 	// The probe is tracked outside vminfo.VMDisks, so DeleteAllVolumes cannot see
 	// it. It carries delete_on_termination, but that only helps once a server
@@ -712,10 +834,13 @@ func (migobj *Migrate) cleanup(ctx context.Context, vminfo vm.VMInfo, message st
 		migobj.deleteProbeVolume(ctx, probeID)
 	}
 
-	err = migobj.VMops.CleanUpSnapshots(true)
-	if err != nil {
-		utils.PrintLog(fmt.Sprintf("Failed to cleanup snapshot of source VM: %s\n", err))
-		return errors.Wrap(err, fmt.Sprintf("Failed to cleanup snapshot of source VM: %s\n", err))
+	// An appliance import has no source VM to take snapshots of
+	if migobj.VMops != nil {
+		err = migobj.VMops.CleanUpSnapshots(true)
+		if err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to cleanup snapshot of source VM: %s\n", err))
+			return errors.Wrap(err, fmt.Sprintf("Failed to cleanup snapshot of source VM: %s\n", err))
+		}
 	}
 
 	// Delete ports if cleanup is enabled
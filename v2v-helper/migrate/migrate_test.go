@@ -301,6 +301,7 @@ func TestLiveReplicateDisks(t *testing.T) {
 		mockOpenStackOps.EXPECT().AttachVolumeToVM(gomock.Any(), "id1").Return(nil).AnyTimes(),
 		mockOpenStackOps.EXPECT().FindDevice("id1").Return("/dev/sda", nil).AnyTimes(),
 		mockNBD.EXPECT().CopyDisk(context.TODO(), "/dev/sda", 0, false).Return(nil).AnyTimes(),
+		mockNBD.EXPECT().TransportMode().Return("nbdssl").AnyTimes(),
 		mockOpenStackOps.EXPECT().DetachVolumeFromVM(gomock.Any(), gomock.Any()).Return(nil).AnyTimes(),
 		mockOpenStackOps.EXPECT().WaitForVolume(gomock.Any(), gomock.Any()).Return(nil).AnyTimes(),
 
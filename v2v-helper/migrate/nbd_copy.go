@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
 	"github.com/platform9/vjailbreak/pkg/common/constants"
 	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
 	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
@@ -104,12 +105,17 @@ func (migobj *Migrate) SyncCBT(ctx context.Context, vminfo vm.VMInfo) error {
 	}
 
 	var changedAreas types.DiskChangeInfo
+	// The delta of all disks and how long it took to copy, for the cutover downtime prediction
+	var deltaBytes int64
+	var copyTime time.Duration
 
 	for idx := range vminfo.VMDisks {
 		changedAreas, err = vmops.CustomQueryChangedDiskAreas(vminfo.VMDisks[idx].ChangeID, migration_snapshot, vminfo.VMDisks[idx].Disk, 0)
 		if err != nil {
 			return errors.Wrap(err, "failed to get changed disk areas")
 		}
+		migobj.recordCBTDelta(vminfo.VMDisks[idx].Name, vjailbreakv1alpha1.DiskTransferMethodNBD, changedBytes(changedAreas))
+		deltaBytes += changedBytes(changedAreas)
 
 		if len(changedAreas.ChangedArea) == 0 {
 			migobj.logMessage(fmt.Sprintf("Periodic Sync: Disk %d: No changed blocks found. Skipping copy", idx))
@@ -141,7 +147,9 @@ func (migobj *Migrate) SyncCBT(ctx context.Context, vminfo vm.VMInfo) error {
 			changedBlockCopySuccess := true
 			startTime := time.Now()
 			migobj.logMessage(fmt.Sprintf("Periodic Sync: Starting incremental block copy for disk %d at %s", idx, startTime))
+			stopTracking := migobj.trackTransfer(ctx, vminfo.VMDisks[idx].Name, vjailbreakv1alpha1.DiskTransferMethodNBD, nbdProgress(nbdops[idx]))
 			err = nbdops[idx].CopyChangedBlocks(ctx, changedAreas, vminfo.VMDisks[idx].Path, vminfo.VMDisks[idx].OpenstackVol.Encrypted)
+			stopTracking()
 			if err != nil {
 				migobj.logMessage(fmt.Sprintf("Periodic Sync: Failed to copy changed blocks for disk %d: %v", idx, err))
 				select {
@@ -157,6 +165,7 @@ func (migobj *Migrate) SyncCBT(ctx context.Context, vminfo vm.VMInfo) error {
 			}
 
 			duration := time.Since(startTime)
+			copyTime += duration
 
 			migobj.logMessage(fmt.Sprintf("Periodic Sync: Incremental block copy for disk %d completed in %s", idx, duration))
 
@@ -164,12 +173,18 @@ func (migobj *Migrate) SyncCBT(ctx context.Context, vminfo vm.VMInfo) error {
 			if err != nil {
 				return errors.Wrap(err, "failed to update disk info")
 			}
+			if changedBlockCopySuccess {
+				migobj.updateCopyCheckpoint(ctx, func(checkpoint *CopyCheckpoint) {
+					checkpoint.Disks[idx].ChangeID = vminfo.VMDisks[idx].ChangeID
+				})
+			}
 			if !changedBlockCopySuccess {
 				migobj.logMessage(fmt.Sprintf("Periodic Sync: Failed to copy changed blocks: %s", err))
 				migobj.logMessage(fmt.Sprintf("Periodic Sync: Since full copy has completed, Retrying copy of changed blocks for disk: %d", idx))
 			}
 		}
 	}
+	migobj.convergence.add(deltaBytes, copyTime)
 	// Cleanup the snapshot taken for incremental copy
 	return nil
 }
@@ -239,7 +254,8 @@ func (migobj *Migrate) WaitforAdminCutover(ctx context.Context, vminfo vm.VMInfo
 		syncEnabled := migobj.getSyncEnabled()
 		var syncInterval time.Duration
 		if syncEnabled {
-			syncInterval = migobj.getSyncDuration()
+			// Syncs come sooner as the delta shrinks, leaving less for the final sync
+			syncInterval = migobj.convergence.nextInterval(migobj.getSyncDuration())
 		}
 
 		select {
@@ -316,6 +332,9 @@ func (migobj *Migrate) WaitforAdminCutover(ctx context.Context, vminfo vm.VMInfo
 			err = utils.DoRetryWithExponentialBackoff(ctx, func() error {
 				return vmops.TakeSnapshot(constants.MigrationSnapshotName)
 			}, maxRetries, capInterval)
+			if err == nil {
+				migobj.convergence.snapshotTaken(time.Now())
+			}
 			if err != nil {
 				syncCtx.LastError = err
 				syncCtx.WarningMessage = fmt.Sprintf("Snapshot creation '%s' failed after %d retries: %v. Will retry on next sync interval.", constants.MigrationSnapshotName, maxRetries, err)
@@ -345,11 +364,36 @@ func (migobj *Migrate) WaitforAdminCutover(ctx context.Context, vminfo vm.VMInfo
 			syncCtx.LastError = nil
 			migobj.logMessage("Periodic Sync: Sync cycle completed successfully")
 
+			// A cutover just before the next sync has the most blocks to copy
+			nextInterval := migobj.convergence.nextInterval(migobj.getSyncDuration())
+			migobj.updateCutoverReadiness(ctx, "Periodic Sync: ", nextInterval, migobj.getCutoverDowntimeTarget(ctx), &nextInterval)
+
 			elapsed = time.Since(start)
 		}
 	}
 }
 
+// verifyFinalCopy compares the volumes with the snapshot that was copied last.
+// The NBD servers of disks without changed blocks in the last pass still export
+// an older snapshot, so every server is restarted on the current one first.
+func (migobj *Migrate) verifyFinalCopy(ctx context.Context, vminfo vm.VMInfo) error {
+	if migobj.VerifyMode == "" {
+		return nil
+	}
+	for idx, disk := range vminfo.VMDisks {
+		if err := migobj.Nbdops[idx].StopNBDServer(); err != nil {
+			return errors.Wrap(err, "failed to stop NBD server")
+		}
+		if err := migobj.Nbdops[idx].StartNBDServer(migobj.VMops.GetVMObj(), migobj.URL, migobj.UserName, migobj.Password,
+			migobj.Thumbprint, disk.Snapname, disk.SnapBackingDisk, migobj.EventReporter); err != nil {
+			return errors.Wrap(err, "failed to start NBD server")
+		}
+	}
+	// sleep for 2 seconds to allow the NBD servers to start
+	time.Sleep(2 * time.Second)
+	return migobj.verifyDisks(ctx, vminfo, nbdVerifiers(migobj.Nbdops))
+}
+
 func (migobj *Migrate) LiveReplicateDisks(ctx context.Context, vminfo vm.VMInfo) (vm.VMInfo, error) {
 	vmops := migobj.VMops
 	nbdops := migobj.Nbdops
@@ -404,17 +448,35 @@ func (migobj *Migrate) LiveReplicateDisks(ctx context.Context, vminfo vm.VMInfo)
 		}
 	}
 
-	// clean up snapshots
-	utils.PrintLog("Cleaning up snapshots before copy")
-	err = vmops.CleanUpSnapshots(false)
-	if err != nil {
-		return vminfo, errors.Wrap(err, "failed to clean up snapshots: %s, please delete manually before starting again")
+	// An interrupted copy carries on from the snapshot it was reading if that
+	// is still there. Otherwise it reads a new one and the changed blocks since
+	// the checkpointed change IDs make up for what changed in between.
+	checkpoint := migobj.copyCheckpoint
+	reuseSnapshot := false
+	if checkpoint != nil {
+		snapshot, err := vmops.GetSnapshot(constants.MigrationSnapshotName)
+		reuseSnapshot = err == nil && snapshot != nil && snapshot.Value == checkpoint.SnapshotMOID
+		if reuseSnapshot {
+			migobj.logMessage(fmt.Sprintf("Resuming disk copy from snapshot %s", checkpoint.SnapshotMOID))
+		} else {
+			migobj.logMessage(fmt.Sprintf("Snapshot %s of the interrupted copy is gone, resuming from a new snapshot", checkpoint.SnapshotMOID))
+		}
 	}
 
-	utils.PrintLog("Starting NBD server")
-	err = vmops.TakeSnapshot(constants.MigrationSnapshotName)
-	if err != nil {
-		return vminfo, errors.Wrap(err, "failed to take snapshot of source VM")
+	if !reuseSnapshot {
+		// clean up snapshots
+		utils.PrintLog("Cleaning up snapshots before copy")
+		err = vmops.CleanUpSnapshots(false)
+		if err != nil {
+			return vminfo, errors.Wrap(err, "failed to clean up snapshots: %s, please delete manually before starting again")
+		}
+
+		utils.PrintLog("Starting NBD server")
+		err = vmops.TakeSnapshot(constants.MigrationSnapshotName)
+		if err != nil {
+			return vminfo, errors.Wrap(err, "failed to take snapshot of source VM")
+		}
+		migobj.convergence.snapshotTaken(time.Now())
 	}
 
 	// Cold migrations never use CBT, so the snapshot disks have no change IDs
@@ -425,6 +487,19 @@ func (migobj *Migrate) LiveReplicateDisks(ctx context.Context, vminfo vm.VMInfo)
 		return vminfo, errors.Wrap(err, "failed to update disk info")
 	}
 
+	if checkpoint == nil {
+		checkpoint = newCopyCheckpoint(vminfo)
+	} else {
+		// The volumes hold the disks as of the checkpointed change IDs, the
+		// incremental copies have to start from there
+		for idx := range vminfo.VMDisks {
+			vminfo.VMDisks[idx].ChangeID = checkpoint.Disks[idx].ChangeID
+		}
+		checkpoint.SnapshotMOID = newCopyCheckpoint(vminfo).SnapshotMOID
+	}
+	migobj.copyCheckpoint = checkpoint
+	migobj.saveCopyCheckpoint(ctx)
+
 	for idx, vmdisk := range vminfo.VMDisks {
 		migobj.logMessage(fmt.Sprintf("Copying disk %d, Completed: 0%%", idx))
 		err = nbdops[idx].StartNBDServer(vmops.GetVMObj(), envURL, envUserName, envPassword, thumbprint, vmdisk.Snapname, vmdisk.SnapBackingDisk, migobj.EventReporter)
@@ -459,29 +534,53 @@ func (migobj *Migrate) LiveReplicateDisks(ctx context.Context, vminfo vm.VMInfo)
 
 	// Check if migration has admin cutover if so don't copy any more changed blocks
 	adminInitiatedCutover := cutoverLabelPresent && (cutoverLabelValue == "no")
+	workers := migobj.diskCopyWorkers(ctx, len(vminfo.VMDisks), 1)
 	incrementalCopyCount := 0
 	for {
 		// If its the first copy, copy the entire disk
 		if incrementalCopyCount == 0 {
-			for idx := range vminfo.VMDisks {
+			err = copyDisksInParallel(ctx, len(vminfo.VMDisks), workers, func(ctx context.Context, idx int) error {
 				startTime := time.Now()
 				disk := vminfo.VMDisks[idx]
+				offset := checkpoint.Disks[idx].Offset
+				if offset >= disk.Size {
+					migobj.logMessage(fmt.Sprintf("Disk %d (%s) is fully copied already, skipping full copy", idx, disk.Name))
+					return nil
+				}
 
 				migobj.logMessage(fmt.Sprintf("Starting full disk copy [%d/%d]: %s (DeviceKey=%d)",
 					idx+1, len(vminfo.VMDisks), disk.Name, disk.Disk.Key))
 				migobj.logMessage(fmt.Sprintf("  Source: %s", extractFileName(disk.SnapBackingDisk)))
 				migobj.logMessage(fmt.Sprintf("  Target: %s (Volume ID: %s)", disk.Path, disk.OpenstackVol.ID))
 
-				err = nbdops[idx].CopyDisk(ctx, disk.Path, idx, disk.OpenstackVol.Encrypted)
+				stopTracking := migobj.trackTransfer(ctx, disk.Name, vjailbreakv1alpha1.DiskTransferMethodNBD, nbdProgress(nbdops[idx]))
+				err := nbdops[idx].CopyDiskFrom(ctx, disk.Path, idx, offset, disk.Size, disk.OpenstackVol.Encrypted, func(offset int64) {
+					migobj.updateCopyCheckpoint(ctx, func(checkpoint *CopyCheckpoint) {
+						checkpoint.Disks[idx].Offset = offset
+					})
+				})
+				stopTracking()
 				if err != nil {
-					return vminfo, errors.Wrap(err, fmt.Sprintf("failed to copy disk %s (DeviceKey=%d)", disk.Name, disk.Disk.Key))
+					return errors.Wrap(err, fmt.Sprintf("failed to copy disk %s (DeviceKey=%d)", disk.Name, disk.Disk.Key))
 				}
+				migobj.recordTransportMode(disk.Name, nbdops[idx].TransportMode())
 				duration := time.Since(startTime)
 				if migobj.MigrationType == "cold" {
 					migobj.logMessage(fmt.Sprintf("✓ Disk %d (%s) copied successfully in %s", idx, disk.Name, duration))
 				} else {
 					migobj.logMessage(fmt.Sprintf("✓ Disk %d (%s) copied successfully in %s, copying changed blocks now", idx, disk.Name, duration))
 				}
+				return nil
+			})
+			if err != nil {
+				return vminfo, err
+			}
+
+			// A cold migration copies a single snapshot, check it before any cutover
+			if migobj.MigrationType == "cold" {
+				if err := migobj.verifyFinalCopy(ctx, vminfo); err != nil {
+					return vminfo, errors.Wrap(err, "disk verification failed")
+				}
 			}
 
 			if adminInitiatedCutover {
@@ -524,73 +623,108 @@ func (migobj *Migrate) LiveReplicateDisks(ctx context.Context, vminfo vm.VMInfo)
 				return vminfo, errors.Wrap(err, "failed to get snapshot")
 			}
 
-			var changedAreas types.DiskChangeInfo
-			done := true
-
+			// The changed areas are looked up one disk after the other, only the
+			// copies run in parallel
+			var changedDisks []int
+			changedAreas := make([]types.DiskChangeInfo, len(vminfo.VMDisks))
 			for idx := range vminfo.VMDisks {
 				err := vmops.UpdateDiskInfo(&vminfo, vminfo.VMDisks[idx], false)
 				if err != nil {
 					return vminfo, errors.Wrap(err, "failed to update disk info")
 				}
 
-				changedAreas, err = vmops.CustomQueryChangedDiskAreas(vminfo.VMDisks[idx].ChangeID, migration_snapshot, vminfo.VMDisks[idx].Disk, 0)
+				changedAreas[idx], err = vmops.CustomQueryChangedDiskAreas(vminfo.VMDisks[idx].ChangeID, migration_snapshot, vminfo.VMDisks[idx].Disk, 0)
 				if err != nil {
 					return vminfo, errors.Wrap(err, "failed to get changed disk areas")
 				}
+				migobj.recordCBTDelta(vminfo.VMDisks[idx].Name, vjailbreakv1alpha1.DiskTransferMethodNBD, changedBytes(changedAreas[idx]))
 
-				if len(changedAreas.ChangedArea) == 0 {
+				if len(changedAreas[idx].ChangedArea) == 0 {
 					if migobj.MigrationType != "cold" {
 						migobj.logMessage(fmt.Sprintf("Disk %d: No changed blocks found. Skipping copy", idx))
 					}
 				} else {
 					migobj.logMessage(fmt.Sprintf("Disk %d: Blocks have Changed.", idx))
+					changedDisks = append(changedDisks, idx)
+				}
+			}
+			done := len(changedDisks) == 0
 
-					utils.PrintLog("Restarting NBD server")
-					err = nbdops[idx].StopNBDServer()
-					if err != nil {
-						return vminfo, errors.Wrap(err, "failed to stop NBD server")
-					}
+			copyStart := time.Now()
+			err = copyDisksInParallel(ctx, len(changedDisks), workers, func(ctx context.Context, i int) error {
+				idx := changedDisks[i]
+				utils.PrintLog("Restarting NBD server")
+				err := nbdops[idx].StopNBDServer()
+				if err != nil {
+					return errors.Wrap(err, "failed to stop NBD server")
+				}
 
-					err = nbdops[idx].StartNBDServer(vmops.GetVMObj(), envURL, envUserName, envPassword, thumbprint, vminfo.VMDisks[idx].Snapname, vminfo.VMDisks[idx].SnapBackingDisk, migobj.EventReporter)
-					if err != nil {
-						return vminfo, errors.Wrap(err, "failed to start NBD server")
-					}
-					// sleep for 2 seconds to allow the NBD server to start
-					time.Sleep(2 * time.Second)
-
-					// 11. Copy Changed Blocks over
-					done = false
-					changedBlockCopySuccess := true
-					migobj.logMessage("Copying changed blocks")
-
-					startTime := time.Now()
-					migobj.logMessage(fmt.Sprintf("Starting incremental block copy for disk %d at %s", idx, startTime))
-
-					// Use exponential backoff for retry logic (3 retries, 30 second cap)
-					copyErr := utils.DoRetryWithExponentialBackoff(ctx, func() error {
-						return nbdops[idx].CopyChangedBlocks(ctx, changedAreas, vminfo.VMDisks[idx].Path, vminfo.VMDisks[idx].OpenstackVol.Encrypted)
-					}, 3, 30*time.Second)
-
-					if copyErr != nil {
-						changedBlockCopySuccess = false
-						// Fail the migration if copy fails after 3 retries
-						return vminfo, errors.Wrap(copyErr, fmt.Sprintf("failed to copy changed blocks for disk %d after 3 attempts", idx))
-					}
+				err = nbdops[idx].StartNBDServer(vmops.GetVMObj(), envURL, envUserName, envPassword, thumbprint, vminfo.VMDisks[idx].Snapname, vminfo.VMDisks[idx].SnapBackingDisk, migobj.EventReporter)
+				if err != nil {
+					return errors.Wrap(err, "failed to start NBD server")
+				}
+				// sleep for 2 seconds to allow the NBD server to start
+				time.Sleep(2 * time.Second)
+
+				// 11. Copy Changed Blocks over
+				migobj.logMessage("Copying changed blocks")
+
+				startTime := time.Now()
+				migobj.logMessage(fmt.Sprintf("Starting incremental block copy for disk %d at %s", idx, startTime))
+
+				// Use exponential backoff for retry logic (3 retries, 30 second cap)
+				stopTracking := migobj.trackTransfer(ctx, vminfo.VMDisks[idx].Name, vjailbreakv1alpha1.DiskTransferMethodNBD, nbdProgress(nbdops[idx]))
+				copyErr := utils.DoRetryWithExponentialBackoff(ctx, func() error {
+					return nbdops[idx].CopyChangedBlocks(ctx, changedAreas[idx], vminfo.VMDisks[idx].Path, vminfo.VMDisks[idx].OpenstackVol.Encrypted)
+				}, 3, 30*time.Second)
+				stopTracking()
+
+				if copyErr != nil {
+					// Fail the migration if copy fails after 3 retries
+					return errors.Wrap(copyErr, fmt.Sprintf("failed to copy changed blocks for disk %d after 3 attempts", idx))
+				}
 
-					duration := time.Since(startTime)
+				duration := time.Since(startTime)
 
-					migobj.logMessage(fmt.Sprintf("Incremental block copy for disk %d completed in %s", idx, duration))
+				migobj.logMessage(fmt.Sprintf("Incremental block copy for disk %d completed in %s", idx, duration))
+				migobj.logMessage(fmt.Sprintf("Finished copying and syncing changed blocks for disk %d in %s [Progress: %d/20]", idx, duration, incrementalCopyCount))
+				return nil
+			})
+			if err != nil {
+				return vminfo, err
+			}
+			var deltaBytes int64
+			for _, idx := range changedDisks {
+				deltaBytes += changedBytes(changedAreas[idx])
+			}
+			migobj.convergence.add(deltaBytes, time.Since(copyStart))
 
-					err = vmops.UpdateDiskInfo(&vminfo, vminfo.VMDisks[idx], changedBlockCopySuccess)
-					if err != nil {
-						return vminfo, errors.Wrap(err, "failed to update disk info")
-					}
-					migobj.logMessage(fmt.Sprintf("Finished copying and syncing changed blocks for disk %d in %s [Progress: %d/20]", idx, duration, incrementalCopyCount))
+			for _, idx := range changedDisks {
+				err = vmops.UpdateDiskInfo(&vminfo, vminfo.VMDisks[idx], true)
+				if err != nil {
+					return vminfo, errors.Wrap(err, "failed to update disk info")
 				}
+				checkpoint.Disks[idx].ChangeID = vminfo.VMDisks[idx].ChangeID
+			}
+			if len(changedDisks) > 0 {
+				migobj.saveCopyCheckpoint(ctx)
 			}
 			if final {
+				if err := migobj.verifyFinalCopy(ctx, vminfo); err != nil {
+					return vminfo, errors.Wrap(err, "disk verification failed")
+				}
 				break
 			}
+			// Readiness is only reported, the admin decides when to cut over. An admin
+			// cutover has the VM powered off already.
+			if !done && !adminInitiatedCutover {
+				migobj.updateCutoverReadiness(ctx, "", migobj.convergence.lastSince(),
+					cutoverDowntimeTarget(vcenterSettings.CutoverDowntimeTarget), nil)
+			}
+			if !done && incrementalCopyCount > vcenterSettings.ChangedBlocksCopyIterationThreshold {
+				migobj.logMessage(fmt.Sprintf("Warning: changed blocks did not converge within %d syncs, cutting over anyway",
+					vcenterSettings.ChangedBlocksCopyIterationThreshold))
+			}
 			if done || incrementalCopyCount > vcenterSettings.ChangedBlocksCopyIterationThreshold {
 				if migobj.MigrationType == "mock" {
 					utils.PrintLog("Mock migration detected, skipping VM power off")
@@ -649,11 +783,16 @@ func (migobj *Migrate) LiveReplicateDisks(ctx context.Context, vminfo vm.VMInfo)
 		if err != nil {
 			return vminfo, errors.Wrap(err, "failed to take snapshot of source VM")
 		}
+		migobj.convergence.snapshotTaken(time.Now())
 
 		incrementalCopyCount += 1
 
 	}
 
+	// Conversion changes the volumes in place, there is no resuming the copy
+	// into them from here on
+	migobj.deleteCopyCheckpoint(ctx)
+
 	err = migobj.DetachAllVolumes(ctx, vminfo)
 	if err != nil {
 		return vminfo, errors.Wrap(err, "Failed to detach all volumes from VM")
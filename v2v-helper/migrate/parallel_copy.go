@@ -0,0 +1,142 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// diskCopyWorkers returns how many of disks are copied at once: ParallelDiskCopies
+// or defaultWorkers when the MigrationPlan does not set it, but never more than
+// this migration's share of the NFC connections of its ESXi host. Each disk copy
+// holds one of them while it runs.
+func (migobj *Migrate) diskCopyWorkers(ctx context.Context, disks, defaultWorkers int) int {
+	requested := migobj.ParallelDiskCopies
+	if requested <= 0 {
+		requested = defaultWorkers
+	}
+	budget := constants.NFCConnectionsPerESXiHost
+	if migobj.K8sClient != nil {
+		if settings, err := k8sutils.GetVjailbreakSettings(ctx, migobj.K8sClient); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to read %s, using %d: %v", constants.NFCConnectionsPerESXiHostKey, budget, err))
+		} else if settings.NFCConnectionsPerESXiHost > 0 {
+			budget = settings.NFCConnectionsPerESXiHost
+		}
+	}
+	sharing := migobj.countCopyingMigrationsOnHost(ctx)
+	workers := parallelDiskCopies(disks, requested, budget, sharing)
+	if workers > 1 || requested > 1 {
+		migobj.logMessage(fmt.Sprintf("Copying %d disks %d at a time (requested %d, %d NFC connections of the ESXi host shared by %d migrations)",
+			disks, workers, requested, budget, sharing))
+	}
+	return workers
+}
+
+// parallelDiskCopies is the number of disks copied at once out of disks when
+// requested are asked for and budget connections to the ESXi host are shared
+// evenly by sharing migrations. At least one disk is always copied.
+func parallelDiskCopies(disks, requested, budget, sharing int) int {
+	workers := min(requested, disks)
+	if budget > 0 {
+		workers = min(workers, budget/max(sharing, 1))
+	}
+	return max(workers, 1)
+}
+
+// countCopyingMigrationsOnHost returns how many migrations copy disk data from the
+// ESXi host of this one, counting this one even if it has not reported a copy
+// phase yet. Migrations created before their ESXi host was labelled are not seen.
+func (migobj *Migrate) countCopyingMigrationsOnHost(ctx context.Context) int {
+	if migobj.K8sClient == nil {
+		return 1
+	}
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return 1
+	}
+	migration := &vjailbreakv1alpha1.Migration{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration); err != nil {
+		utils.PrintLog(fmt.Sprintf("Failed to get migration %s for its ESXi host: %v", migrationName, err))
+		return 1
+	}
+	host := migration.Labels[constants.ESXiHostLabel]
+	if host == "" {
+		return 1
+	}
+	migrations := &vjailbreakv1alpha1.MigrationList{}
+	if err := migobj.K8sClient.List(ctx, migrations, client.InNamespace(constants.NamespaceMigrationSystem),
+		client.MatchingLabels{constants.ESXiHostLabel: host}); err != nil {
+		utils.PrintLog(fmt.Sprintf("Failed to list migrations copying from ESXi host %s: %v", host, err))
+		return 1
+	}
+	copying := 1
+	for _, other := range migrations.Items {
+		if other.Name != migrationName && bandwidthCopyPhases[other.Status.Phase] {
+			copying++
+		}
+	}
+	return copying
+}
+
+// copyDisksInParallel runs copyDisk for disks 0 to disks-1, workers of them at a
+// time. After the first failure no further disk is started and the context of the
+// running ones is cancelled. Each disk has its own NBD server and reports its own
+// progress, so copyDisk only touches the state of disk idx.
+func copyDisksInParallel(ctx context.Context, disks, workers int, copyDisk func(ctx context.Context, idx int) error) error {
+	if workers <= 1 {
+		for idx := 0; idx < disks; idx++ {
+			if err := copyDisk(ctx, idx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		failOnce sync.Once
+		firstErr error
+	)
+	slots := make(chan struct{}, workers)
+	for idx := 0; idx < disks; idx++ {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := copyDisk(ctx, idx); err != nil {
+				// The disks cancelled because of it fail too, only the first failure is the cause
+				failOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(idx)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	// The parent context was done before every disk was started
+	return ctx.Err()
+}
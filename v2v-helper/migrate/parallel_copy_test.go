@@ -0,0 +1,133 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+func TestParallelDiskCopies(t *testing.T) {
+	tests := []struct {
+		name                             string
+		disks, requested, budget, shared int
+		want                             int
+	}{
+		{name: "serial", disks: 4, requested: 1, budget: 8, shared: 1, want: 1},
+		{name: "all disks", disks: 4, requested: 8, budget: 8, shared: 1, want: 4},
+		{name: "requested", disks: 4, requested: 2, budget: 8, shared: 1, want: 2},
+		{name: "share of the host", disks: 4, requested: 4, budget: 8, shared: 3, want: 2},
+		{name: "host fully shared", disks: 4, requested: 4, budget: 8, shared: 10, want: 1},
+		{name: "no budget", disks: 4, requested: 4, budget: 0, shared: 3, want: 4},
+		{name: "no disks", disks: 0, requested: 4, budget: 8, shared: 1, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parallelDiskCopies(tt.disks, tt.requested, tt.budget, tt.shared))
+		})
+	}
+}
+
+func TestCopyDisksInParallel(t *testing.T) {
+	t.Run("bounded by workers", func(t *testing.T) {
+		var running, peak, copied atomic.Int32
+		err := copyDisksInParallel(context.Background(), 6, 2, func(ctx context.Context, idx int) error {
+			now := running.Add(1)
+			for {
+				old := peak.Load()
+				if now <= old || peak.CompareAndSwap(old, now) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			copied.Add(1)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(6), copied.Load())
+		assert.Equal(t, int32(2), peak.Load())
+	})
+
+	t.Run("serial stops at the first failure", func(t *testing.T) {
+		var copied []int
+		err := copyDisksInParallel(context.Background(), 3, 1, func(ctx context.Context, idx int) error {
+			copied = append(copied, idx)
+			if idx == 1 {
+				return errors.New("disk 1 failed")
+			}
+			return nil
+		})
+		assert.EqualError(t, err, "disk 1 failed")
+		assert.Equal(t, []int{0, 1}, copied)
+	})
+
+	t.Run("first failure cancels the others", func(t *testing.T) {
+		var started atomic.Int32
+		err := copyDisksInParallel(context.Background(), 5, 2, func(ctx context.Context, idx int) error {
+			started.Add(1)
+			if idx == 0 {
+				return errors.New("disk 0 failed")
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.EqualError(t, err, "disk 0 failed")
+		assert.Less(t, started.Load(), int32(5))
+	})
+
+	t.Run("parent cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := copyDisksInParallel(ctx, 3, 2, func(ctx context.Context, idx int) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestCountCopyingMigrationsOnHost(t *testing.T) {
+	builder, migrationName := checkpointTestClient(t)
+	onHost := func(name, host string, phase vjailbreakv1alpha1.VMMigrationPhase) *vjailbreakv1alpha1.Migration {
+		return &vjailbreakv1alpha1.Migration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: constants.NamespaceMigrationSystem,
+				Labels:    map[string]string{constants.ESXiHostLabel: host},
+			},
+			Status: vjailbreakv1alpha1.MigrationStatus{Phase: phase},
+		}
+	}
+	k8sClient := builder.WithObjects(
+		onHost("copying", "esxi-1", vjailbreakv1alpha1.VMMigrationPhaseCopying),
+		onHost("hot-add", "esxi-1", vjailbreakv1alpha1.VMMigrationPhaseHotAddTransferring),
+		onHost("converting", "esxi-1", vjailbreakv1alpha1.VMMigrationPhaseConvertingDisk),
+		onHost("other-host", "esxi-2", vjailbreakv1alpha1.VMMigrationPhaseCopying),
+	).Build()
+	ctx := context.Background()
+	migobj := &Migrate{K8sClient: k8sClient}
+
+	// Not labelled with its ESXi host
+	assert.Equal(t, 1, migobj.countCopyingMigrationsOnHost(ctx))
+
+	migration := &vjailbreakv1alpha1.Migration{}
+	require.NoError(t, k8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration))
+	migration.Labels = map[string]string{constants.ESXiHostLabel: "esxi-1"}
+	require.NoError(t, k8sClient.Update(ctx, migration))
+	assert.Equal(t, 3, migobj.countCopyingMigrationsOnHost(ctx))
+
+	assert.Equal(t, 1, (&Migrate{}).countCopyingMigrationsOnHost(ctx))
+}
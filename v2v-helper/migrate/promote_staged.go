@@ -0,0 +1,226 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// StagedVM is what a data-only migration records about the VM whose volumes it
+// staged, so that an instance can be created from them later without reading
+// the source VM again
+type StagedVM struct {
+	Name     string    `json:"name"`
+	StagedAt time.Time `json:"stagedAt"`
+	OSType   string    `json:"osType"`
+	UEFI     bool      `json:"uefi"`
+	CPU      int32     `json:"cpu"`
+	MemoryMB int32     `json:"memoryMB"`
+	// ESPDiskIndex is the disk holding the EFI system partition when it is not
+	// the boot disk, and -1 otherwise
+	ESPDiskIndex int          `json:"espDiskIndex"`
+	Disks        []StagedDisk `json:"disks"`
+	// MACs are the MACs of the source NICs in order and IPperMac their addresses
+	MACs      []string                `json:"macs"`
+	IPperMac  map[string][]vm.IpEntry `json:"ipPerMac,omitempty"`
+	GatewayIP map[string]string       `json:"gatewayIP,omitempty"`
+}
+
+// StagedDisk is one disk of a StagedVM
+type StagedDisk struct {
+	Name      string `json:"name"`
+	SizeBytes int64  `json:"sizeBytes"`
+	Boot      bool   `json:"boot"`
+	VolumeID  string `json:"volumeID"`
+}
+
+// stagedVMConfigMapName is the ConfigMap a data-only migration records its StagedVM in
+func stagedVMConfigMapName(migrationName string) string {
+	return fmt.Sprintf("%s-staged-vm", migrationName)
+}
+
+func buildStagedVM(vminfo vm.VMInfo, espDiskIndex int) (StagedVM, error) {
+	staged := StagedVM{
+		Name:         vminfo.Name,
+		StagedAt:     time.Now().UTC(),
+		OSType:       vminfo.OSType,
+		UEFI:         vminfo.UEFI,
+		CPU:          vminfo.CPU,
+		MemoryMB:     vminfo.Memory,
+		ESPDiskIndex: espDiskIndex,
+		MACs:         vminfo.Mac,
+		IPperMac:     vminfo.IPperMac,
+		GatewayIP:    vminfo.GatewayIP,
+	}
+	for _, disk := range vminfo.VMDisks {
+		if disk.OpenstackVol == nil || disk.OpenstackVol.ID == "" {
+			return StagedVM{}, errors.Errorf("disk %s has no staged volume", disk.Name)
+		}
+		staged.Disks = append(staged.Disks, StagedDisk{
+			Name:      disk.Name,
+			SizeBytes: disk.Size,
+			Boot:      disk.Boot,
+			VolumeID:  disk.OpenstackVol.ID,
+		})
+	}
+	return staged, nil
+}
+
+// saveStagedVM records the VM of a data-only migration in a ConfigMap owned by the
+// Migration, for PromoteStagedVolumes
+func (migobj *Migrate) saveStagedVM(ctx context.Context, vminfo vm.VMInfo, espDiskIndex int) error {
+	if migobj.K8sClient == nil {
+		return nil
+	}
+	staged, err := buildStagedVM(vminfo, espDiskIndex)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(staged, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal staged VM")
+	}
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return errors.Wrap(err, "failed to get migration object name")
+	}
+	migration := &vjailbreakv1alpha1.Migration{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration); err != nil {
+		return errors.Wrapf(err, "failed to get migration %s", migrationName)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      stagedVMConfigMapName(migrationName),
+			Namespace: constants.NamespaceMigrationSystem,
+		},
+	}
+	// A retried migration stages new volumes and replaces those of the last attempt
+	if _, err := controllerutil.CreateOrUpdate(ctx, migobj.K8sClient, configMap, func() error {
+		configMap.Data = map[string]string{constants.StagedVMKey: string(data)}
+		return controllerutil.SetOwnerReference(migration, configMap, migobj.K8sClient.Scheme())
+	}); err != nil {
+		return errors.Wrapf(err, "failed to save staged VM %s", configMap.Name)
+	}
+	migobj.logMessage(fmt.Sprintf("Saved staged VM to ConfigMap %s", configMap.Name))
+	return nil
+}
+
+// loadStagedVM reads the StagedVM a data-only migration recorded
+func (migobj *Migrate) loadStagedVM(ctx context.Context) (StagedVM, error) {
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return StagedVM{}, errors.Wrap(err, "failed to get migration object name")
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      stagedVMConfigMapName(migrationName),
+		Namespace: constants.NamespaceMigrationSystem,
+	}, configMap); err != nil {
+		return StagedVM{}, errors.Wrapf(err, "failed to get staged VM of migration %s", migrationName)
+	}
+	staged := StagedVM{}
+	if err := json.Unmarshal([]byte(configMap.Data[constants.StagedVMKey]), &staged); err != nil {
+		return StagedVM{}, errors.Wrapf(err, "failed to parse staged VM %s", configMap.Name)
+	}
+	if len(staged.Disks) == 0 {
+		return StagedVM{}, errors.Errorf("staged VM %s has no disks", configMap.Name)
+	}
+	return staged, nil
+}
+
+// stagedVMInfo rebuilds the VMInfo CreateTargetInstance needs from a StagedVM. Every
+// staged volume must still exist and be available to attach.
+func (migobj *Migrate) stagedVMInfo(ctx context.Context, staged StagedVM) (vm.VMInfo, error) {
+	vminfo := vm.VMInfo{
+		Name:      staged.Name,
+		OSType:    staged.OSType,
+		UEFI:      staged.UEFI,
+		CPU:       staged.CPU,
+		Memory:    staged.MemoryMB,
+		Mac:       staged.MACs,
+		IPperMac:  staged.IPperMac,
+		GatewayIP: staged.GatewayIP,
+	}
+	// The port overrides write to it
+	if vminfo.IPperMac == nil {
+		vminfo.IPperMac = map[string][]vm.IpEntry{}
+	}
+	for _, disk := range staged.Disks {
+		volume, err := migobj.Openstackclients.GetVolume(ctx, disk.VolumeID)
+		if err != nil {
+			return vm.VMInfo{}, errors.Wrapf(err, "failed to get staged volume %s of disk %s", disk.VolumeID, disk.Name)
+		}
+		if volume.Status != "available" {
+			return vm.VMInfo{}, errors.Errorf("staged volume %s of disk %s is %s, not available", disk.VolumeID, disk.Name, volume.Status)
+		}
+		vminfo.VMDisks = append(vminfo.VMDisks, vm.VMDisk{
+			Name:         disk.Name,
+			Size:         disk.SizeBytes,
+			Boot:         disk.Boot,
+			OpenstackVol: volume,
+		})
+	}
+	return vminfo, nil
+}
+
+// PromoteStagedVolumes creates the instance of a data-only migration from the
+// volumes it staged. Ports are reserved and the flavor is picked as in MigrateVM;
+// the source VM is never read. The guest was configured for its source NICs when
+// it was converted. A failed promotion keeps the staged volumes so that it can be
+// run again.
+func (migobj *Migrate) PromoteStagedVolumes(ctx context.Context) error {
+	migobj.logMessage(constants.EventMessagePromotingStagedVolumes)
+	staged, err := migobj.loadStagedVM(ctx)
+	if err != nil {
+		return err
+	}
+	vminfo, err := migobj.stagedVMInfo(ctx, staged)
+	if err != nil {
+		return err
+	}
+	if len(vminfo.Mac) != len(migobj.Networknames) {
+		return errors.Errorf("number of mac addresses does not match number of network names mac(%d) network(%d)", len(vminfo.Mac), len(migobj.Networknames))
+	}
+	migobj.logMessage(fmt.Sprintf("Creating VM %s from %d volumes staged at %s", vminfo.Name, len(vminfo.VMDisks), staged.StagedAt.Format(time.RFC3339)))
+
+	networkids, portids, ipaddresses, err := migobj.ReservePortsForVM(ctx, &vminfo)
+	if err != nil {
+		return errors.Wrap(err, "failed to reserve ports for VM")
+	}
+	err = migobj.CreateTargetInstance(ctx, vminfo, networkids, portids, ipaddresses, staged.ESPDiskIndex)
+	if err == nil {
+		return nil
+	}
+	if serverID, recoveryErr := migobj.verifyVMCreatedDespiteTimeout(ctx, vminfo); recoveryErr == nil {
+		utils.PrintLog(fmt.Sprintf("VM created despite CreateTargetInstance error (%v)", err))
+		migobj.logMessage(fmt.Sprintf("VM created successfully: ID: %s", serverID))
+		return nil
+	}
+	// Unlike cleanup, the staged volumes are kept for the next attempt. Ports
+	// passed in by the plan were not created here and are left alone.
+	vjailbreakSettings, settingsErr := k8sutils.GetVjailbreakSettings(ctx, migobj.K8sClient)
+	if settingsErr == nil && vjailbreakSettings.CleanupPortsAfterMigrationFailure && len(migobj.Networkports) == 0 {
+		if portErr := migobj.DeleteAllPorts(ctx, portids); portErr != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to delete ports: %s\n", portErr))
+		}
+	}
+	return errors.Wrap(err, "failed to create target instance from the staged volumes")
+}
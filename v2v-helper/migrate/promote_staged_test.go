@@ -0,0 +1,112 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/openstack"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+func stagedTestVMInfo() vm.VMInfo {
+	return vm.VMInfo{
+		Name:   "test-vm",
+		OSType: "windows",
+		UEFI:   true,
+		CPU:    4,
+		Memory: 8192,
+		Mac:    []string{"00:50:56:aa:bb:cc"},
+		IPperMac: map[string][]vm.IpEntry{
+			"00:50:56:aa:bb:cc": {{IP: "10.0.0.5", Prefix: 24}},
+		},
+		VMDisks: []vm.VMDisk{
+			{Name: "disk1", Size: 40 << 30, Boot: true, OpenstackVol: &volumes.Volume{ID: "vol-1"}},
+			{Name: "disk2", Size: 100 << 30, OpenstackVol: &volumes.Volume{ID: "vol-2"}},
+		},
+	}
+}
+
+func TestBuildStagedVM(t *testing.T) {
+	staged, err := buildStagedVM(stagedTestVMInfo(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "test-vm", staged.Name)
+	assert.Equal(t, 1, staged.ESPDiskIndex)
+	assert.Equal(t, []StagedDisk{
+		{Name: "disk1", SizeBytes: 40 << 30, Boot: true, VolumeID: "vol-1"},
+		{Name: "disk2", SizeBytes: 100 << 30, VolumeID: "vol-2"},
+	}, staged.Disks)
+
+	vminfo := stagedTestVMInfo()
+	vminfo.VMDisks[1].OpenstackVol = nil
+	_, err = buildStagedVM(vminfo, -1)
+	assert.ErrorContains(t, err, "disk disk2 has no staged volume")
+}
+
+func TestStagedVMSaveLoad(t *testing.T) {
+	builder, migrationName := checkpointTestClient(t)
+	k8sClient := builder.Build()
+	ctx := context.Background()
+	migobj := &Migrate{K8sClient: k8sClient}
+
+	require.NoError(t, migobj.saveStagedVM(ctx, stagedTestVMInfo(), -1))
+	configMap := &corev1.ConfigMap{}
+	require.NoError(t, k8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      stagedVMConfigMapName(migrationName),
+		Namespace: constants.NamespaceMigrationSystem,
+	}, configMap))
+	require.Len(t, configMap.OwnerReferences, 1)
+	assert.Equal(t, "Migration", configMap.OwnerReferences[0].Kind)
+
+	// A retried migration overwrites the volumes it staged before
+	vminfo := stagedTestVMInfo()
+	vminfo.VMDisks[0].OpenstackVol.ID = "vol-3"
+	require.NoError(t, migobj.saveStagedVM(ctx, vminfo, -1))
+
+	staged, err := migobj.loadStagedVM(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "vol-3", staged.Disks[0].VolumeID)
+	assert.Equal(t, -1, staged.ESPDiskIndex)
+	assert.Equal(t, []vm.IpEntry{{IP: "10.0.0.5", Prefix: 24}}, staged.IPperMac["00:50:56:aa:bb:cc"])
+}
+
+func TestStagedVMInfo(t *testing.T) {
+	staged, err := buildStagedVM(stagedTestVMInfo(), -1)
+	require.NoError(t, err)
+	staged.IPperMac = nil
+
+	t.Run("volumes available", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockOpenStackOps := openstack.NewMockOpenstackOperations(ctrl)
+		mockOpenStackOps.EXPECT().GetVolume(gomock.Any(), "vol-1").Return(&volumes.Volume{ID: "vol-1", Status: "available"}, nil)
+		mockOpenStackOps.EXPECT().GetVolume(gomock.Any(), "vol-2").Return(&volumes.Volume{ID: "vol-2", Status: "available"}, nil)
+		migobj := &Migrate{Openstackclients: mockOpenStackOps}
+
+		vminfo, err := migobj.stagedVMInfo(context.Background(), staged)
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), vminfo.CPU)
+		assert.True(t, vminfo.UEFI)
+		assert.NotNil(t, vminfo.IPperMac)
+		require.Len(t, vminfo.VMDisks, 2)
+		assert.True(t, vminfo.VMDisks[0].Boot)
+		assert.Equal(t, "vol-2", vminfo.VMDisks[1].OpenstackVol.ID)
+	})
+
+	t.Run("volume attached elsewhere", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockOpenStackOps := openstack.NewMockOpenstackOperations(ctrl)
+		mockOpenStackOps.EXPECT().GetVolume(gomock.Any(), "vol-1").Return(&volumes.Volume{ID: "vol-1", Status: "in-use"}, nil)
+		migobj := &Migrate{Openstackclients: mockOpenStackOps}
+
+		_, err := migobj.stagedVMInfo(context.Background(), staged)
+		assert.ErrorContains(t, err, "staged volume vol-1 of disk disk1 is in-use, not available")
+	})
+}
@@ -0,0 +1,125 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/pkg/common/proxmox"
+	esxissh "github.com/platform9/vjailbreak/v2v-helper/esxi-ssh"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+)
+
+// MigrateProxmoxVM creates an OpenStack instance from a stopped VM of a Proxmox
+// VE cluster. The VM configuration from the API stands in for GetVMInfo. Each
+// disk is served from the node of the VM with qemu-nbd, which opens files, LVM
+// and ZFS volumes and rbd: images alike, and copied into its volume with nbdcopy
+// over the SSH connection, so no port is opened on the node.
+func (migobj *Migrate) MigrateProxmoxVM(ctx context.Context, apiURL string, insecure bool, vmidStr, targetName string) error {
+	vmid, err := strconv.Atoi(vmidStr)
+	if err != nil {
+		return errors.Errorf("invalid Proxmox VMID %q", vmidStr)
+	}
+	pveClient, err := proxmox.NewClient(apiURL, os.Getenv(constants.ProxmoxTokenIDEnv), os.Getenv(constants.ProxmoxTokenSecretEnv), insecure)
+	if err != nil {
+		return err
+	}
+	privateKey := os.Getenv(constants.ProxmoxSSHPrivateKeyEnv)
+	if privateKey == "" {
+		return errors.Errorf("%s is not set, the SSH key of the Proxmox VE nodes is needed", constants.ProxmoxSSHPrivateKeyEnv)
+	}
+
+	vmInfo, src, host, err := readProxmoxVM(ctx, pveClient, vmid)
+	if err != nil {
+		return err
+	}
+	migobj.logMessage(fmt.Sprintf("Read VM %d (%s) on node %s: %d CPU, %d MB memory, %d disk(s), %d NIC(s)",
+		vmid, src.name, vmInfo.Node, src.cpu, src.memoryMB, len(src.disks), len(src.macs)))
+	if vmInfo.AllDisksVirtio() {
+		migobj.logMessage(fmt.Sprintf("All disks of VM %d are on the virtio bus, skipping virt-v2v conversion", vmid))
+		migobj.Convert = false
+	}
+
+	// qemu-nbd needs root to open the volumes of the node
+	sshClient := esxissh.NewClient()
+	if err := sshClient.Connect(ctx, host, "root", []byte(privateKey)); err != nil {
+		return errors.Wrapf(err, "failed to connect to node %s at %s", vmInfo.Node, host)
+	}
+	defer func() {
+		if err := sshClient.Disconnect(); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to disconnect from %s: %v", host, err))
+		}
+	}()
+
+	vminfo, err := applianceVMInfo(src, migobj.Ostype, len(migobj.Networknames))
+	if err != nil {
+		return err
+	}
+	if targetName != "" {
+		vminfo.Name = targetName
+	}
+	return migobj.createInstanceFromDisks(ctx, vminfo, func(vminfo vm.VMInfo) error {
+		return migobj.copyDisksFromHost(ctx, sshClient, host, src, vminfo)
+	})
+}
+
+// readProxmoxVM reads the configuration and volumes of a stopped VM, along with
+// the address of its node. The disks of a running VM would change under the copy.
+func readProxmoxVM(ctx context.Context, pveClient *proxmox.Client, vmid int) (*proxmox.VM, *applianceSource, string, error) {
+	// The VM may have moved to another node since it was discovered
+	res, err := pveClient.FindVM(ctx, vmid)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	status, err := pveClient.VMStatus(ctx, res.Node, vmid)
+	if err != nil {
+		return nil, nil, "", errors.Wrapf(err, "failed to get status of VM %d", vmid)
+	}
+	if status != proxmox.VMStatusStopped {
+		return nil, nil, "", errors.Errorf("VM %d is %s, shut it down before migrating it", vmid, status)
+	}
+	vmInfo, err := pveClient.VMConfig(ctx, res.Node, vmid)
+	if err != nil {
+		return nil, nil, "", errors.Wrapf(err, "failed to read configuration of VM %d", vmid)
+	}
+
+	volumes := make([]*proxmox.Volume, len(vmInfo.Disks))
+	for idx, disk := range vmInfo.Disks {
+		if volumes[idx], err = pveClient.VolumeInfo(ctx, res.Node, disk.Volume); err != nil {
+			return nil, nil, "", errors.Wrapf(err, "failed to locate disk %s", disk.Key)
+		}
+	}
+	host, err := pveClient.NodeAddress(ctx, res.Node)
+	if err != nil {
+		return nil, nil, "", errors.Wrapf(err, "failed to get address of node %s", res.Node)
+	}
+	return vmInfo, proxmoxApplianceSource(vmInfo, volumes), host, nil
+}
+
+// proxmoxApplianceSource describes the VM as the import path expects
+func proxmoxApplianceSource(vmInfo *proxmox.VM, volumes []*proxmox.Volume) *applianceSource {
+	src := &applianceSource{
+		name:     vmInfo.Name,
+		cpu:      vmInfo.CPU,
+		memoryMB: vmInfo.MemoryMB,
+		uefi:     vmInfo.UEFI,
+		osFamily: vmInfo.OSFamily,
+	}
+	for _, nic := range vmInfo.NICs {
+		src.macs = append(src.macs, nic.MAC)
+	}
+	for idx, disk := range vmInfo.Disks {
+		size := volumes[idx].Size
+		if size == 0 {
+			size = disk.SizeBytes
+		}
+		src.disks = append(src.disks, applianceDisk{name: disk.Key, size: size, image: volumes[idx].Path, format: volumes[idx].Format})
+	}
+	return src
+}
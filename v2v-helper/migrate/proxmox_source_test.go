@@ -0,0 +1,88 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/platform9/vjailbreak/pkg/common/proxmox"
+)
+
+// newPVEStandIn serves the Proxmox VE API calls of readProxmoxVM for VM 100,
+// which has moved to node pve2, and the running VM 101
+func newPVEStandIn(t *testing.T) *proxmox.Client {
+	t.Helper()
+	responses := map[string]string{
+		"/api2/json/cluster/resources": `[
+			{"type":"qemu","vmid":100,"name":"web01","node":"pve2","status":"stopped"},
+			{"type":"qemu","vmid":101,"name":"db01","node":"pve1","status":"running"}
+		]`,
+		"/api2/json/nodes/pve2/qemu/100/status/current": `{"status":"stopped"}`,
+		"/api2/json/nodes/pve1/qemu/101/status/current": `{"status":"running"}`,
+		"/api2/json/nodes/pve2/qemu/100/config": `{"name":"web01","cores":2,"memory":4096,"ostype":"l26","bios":"ovmf",` +
+			`"boot":"order=virtio1;net0","virtio0":"local:100/vm-100-disk-0.qcow2,size=10G","virtio1":"ceph:vm-100-disk-1,size=20G",` +
+			`"net0":"virtio=BC:24:11:00:00:01,bridge=vmbr0"}`,
+		"/api2/json/nodes/pve2/storage/local/content/local:100/vm-100-disk-0.qcow2": `{"path":"/var/lib/vz/images/100/vm-100-disk-0.qcow2","format":"qcow2","size":10737418240}`,
+		"/api2/json/nodes/pve2/storage/ceph/content/ceph:vm-100-disk-1":             `{"path":"rbd:rbd/vm-100-disk-1:conf=/etc/pve/ceph.conf","format":"raw","size":21474836480}`,
+		"/api2/json/cluster/status": `[{"type":"node","name":"pve1","ip":"10.0.0.11"},{"type":"node","name":"pve2","ip":"10.0.0.12"}]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":` + body + `}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := proxmox.NewClient(server.URL, "root@pam!vjailbreak", "5e3c1d2a", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestReadProxmoxVM(t *testing.T) {
+	c := newPVEStandIn(t)
+
+	vmInfo, src, host, err := readProxmoxVM(context.Background(), c, 100)
+	if err != nil {
+		t.Fatalf("readProxmoxVM() unexpected error: %v", err)
+	}
+	if host != "10.0.0.12" {
+		t.Errorf("host = %q, want the address of pve2", host)
+	}
+	if !vmInfo.AllDisksVirtio() {
+		t.Error("AllDisksVirtio() = false for a VM with only virtio disks")
+	}
+	want := &applianceSource{
+		name:     "web01",
+		cpu:      2,
+		memoryMB: 4096,
+		uefi:     true,
+		osFamily: "linuxGuest",
+		macs:     []string{"bc:24:11:00:00:01"},
+		disks: []applianceDisk{
+			{name: "virtio1", size: 20 << 30, image: "rbd:rbd/vm-100-disk-1:conf=/etc/pve/ceph.conf", format: "raw"},
+			{name: "virtio0", size: 10 << 30, image: "/var/lib/vz/images/100/vm-100-disk-0.qcow2", format: "qcow2"},
+		},
+	}
+	if !reflect.DeepEqual(src, want) {
+		t.Errorf("readProxmoxVM() source = %+v, want %+v", src, want)
+	}
+}
+
+func TestReadProxmoxVMRejectsRunningVM(t *testing.T) {
+	c := newPVEStandIn(t)
+
+	_, _, _, err := readProxmoxVM(context.Background(), c, 101)
+	if err == nil || !strings.Contains(err.Error(), "is running") {
+		t.Errorf("readProxmoxVM() error = %v, want a running VM error", err)
+	}
+}
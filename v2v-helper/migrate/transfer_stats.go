@@ -0,0 +1,221 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/nbd"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// transferSampleInterval is how often the progress of a running disk copy is read
+	transferSampleInterval = 5 * time.Second
+	// transferReportInterval is how often changed transfers are written to the
+	// Migration status, the controller reads them every 30 seconds
+	transferReportInterval = 15 * time.Second
+	// transferRateWindow is roughly how far back the copy rate looks. Shorter
+	// follows changes faster, longer evens out the steps nbdcopy reports in.
+	transferRateWindow = 30 * time.Second
+)
+
+// diskTransfers is the throughput of the disk copies of a migration. The
+// controller exports it as Prometheus metrics, see publishTransfers.
+type diskTransfers struct {
+	mu      sync.Mutex
+	disks   []vjailbreakv1alpha1.DiskTransfer
+	meters  map[string]*transferMeter
+	changed bool
+}
+
+// transferMeter estimates the copy rate of a disk from its progress, as an
+// exponentially weighted moving average
+type transferMeter struct {
+	copied int64
+	at     time.Time
+	rate   float64
+	rated  bool
+}
+
+// observe adds that copied bytes were done at at and returns the rate in bytes
+// per second. The first observation has no rate.
+func (m *transferMeter) observe(copied int64, at time.Time) float64 {
+	if !m.at.IsZero() {
+		elapsed := at.Sub(m.at).Seconds()
+		if elapsed <= 0 {
+			return m.rate
+		}
+		sample := float64(copied-m.copied) / elapsed
+		if m.rated {
+			m.rate += (1 - math.Exp(-elapsed/transferRateWindow.Seconds())) * (sample - m.rate)
+		} else {
+			m.rate, m.rated = sample, true
+		}
+	}
+	m.copied, m.at = copied, at
+	return m.rate
+}
+
+// disk returns the transfer of the disk named name, adding it if it has none
+// yet. d.mu must be held.
+func (d *diskTransfers) disk(name string, method vjailbreakv1alpha1.DiskTransferMethod) *vjailbreakv1alpha1.DiskTransfer {
+	idx := slices.IndexFunc(d.disks, func(t vjailbreakv1alpha1.DiskTransfer) bool { return t.Disk == name })
+	if idx < 0 {
+		d.disks = append(d.disks, vjailbreakv1alpha1.DiskTransfer{Disk: name, Method: method, UpdatedAt: metav1.Now()})
+		idx = len(d.disks) - 1
+	}
+	d.disks[idx].Method = method
+	return &d.disks[idx]
+}
+
+// recordTransfer records that copied of the total bytes of the current copy pass
+// of disk name are done. A smaller copied or another total starts a new pass.
+func (migobj *Migrate) recordTransfer(name string, method vjailbreakv1alpha1.DiskTransferMethod, copied, total int64) {
+	d := &migobj.transfers
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.meters == nil {
+		d.meters = map[string]*transferMeter{}
+	}
+	transfer := d.disk(name, method)
+	meter := d.meters[name]
+	if meter == nil || copied < transfer.BytesCopied || total != transfer.BytesTotal {
+		meter = &transferMeter{}
+		d.meters[name] = meter
+	}
+	now := time.Now()
+	rate := int64(meter.observe(copied, now))
+	if copied != transfer.BytesCopied || total != transfer.BytesTotal {
+		transfer.UpdatedAt = metav1.NewTime(now)
+	}
+	if copied != transfer.BytesCopied || total != transfer.BytesTotal || rate != transfer.BytesPerSecond {
+		transfer.BytesCopied, transfer.BytesTotal, transfer.BytesPerSecond = copied, total, rate
+		d.changed = true
+	}
+}
+
+// recordCBTDelta records that a CBT sync found changedBytes of changed blocks on disk name
+func (migobj *Migrate) recordCBTDelta(name string, method vjailbreakv1alpha1.DiskTransferMethod, changedBytes int64) {
+	d := &migobj.transfers
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	transfer := d.disk(name, method)
+	transfer.CBTDeltaBytes = changedBytes
+	transfer.CBTSyncs++
+	d.changed = true
+}
+
+// changedBytes is the size of the changed areas CBT reported for a disk
+func changedBytes(changedAreas types.DiskChangeInfo) int64 {
+	var size int64
+	for _, area := range changedAreas.ChangedArea {
+		size += area.Length
+	}
+	return size
+}
+
+// trackTransfer records the progress of the copy of disk name every
+// transferSampleInterval until the returned function is called, which records
+// it a last time. progress returns the bytes copied and the total of the copy.
+func (migobj *Migrate) trackTransfer(ctx context.Context, name string, method vjailbreakv1alpha1.DiskTransferMethod,
+	progress func() (copied, total int64),
+) func() {
+	sample := func() {
+		if copied, total := progress(); total > 0 {
+			migobj.recordTransfer(name, method, copied, total)
+		}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(transferSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sample()
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		sample()
+	}
+}
+
+// nbdProgress reads the progress of the copy nbdserver runs for trackTransfer
+func nbdProgress(nbdserver nbd.NBDOperations) func() (int64, int64) {
+	return func() (int64, int64) {
+		copied, total, _ := nbdserver.GetProgress()
+		return copied, total
+	}
+}
+
+// publishTransfers writes the disk transfers to the Migration status every
+// transferReportInterval while they change, until ctx is done
+func (migobj *Migrate) publishTransfers(ctx context.Context) {
+	if migobj.K8sClient == nil {
+		return
+	}
+	ticker := time.NewTicker(transferReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d := &migobj.transfers
+			d.mu.Lock()
+			if !d.changed {
+				d.mu.Unlock()
+				continue
+			}
+			transfers := slices.Clone(d.disks)
+			d.changed = false
+			d.mu.Unlock()
+			if err := migobj.reportTransfers(ctx, transfers); err != nil {
+				utils.PrintLog(fmt.Sprintf("Failed to report disk transfers: %v", err))
+			}
+		}
+	}
+}
+
+// reportTransfers records the disk transfers on the Migration status
+func (migobj *Migrate) reportTransfers(ctx context.Context, transfers []vjailbreakv1alpha1.DiskTransfer) error {
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return errors.Wrap(err, "failed to get migration object name")
+	}
+	migration := &vjailbreakv1alpha1.Migration{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration); err != nil {
+		return errors.Wrapf(err, "failed to get migration %s to patch disk transfers", migrationName)
+	}
+	patch := client.MergeFrom(migration.DeepCopy())
+	migration.Status.DiskTransfers = transfers
+	if err := migobj.K8sClient.Status().Patch(ctx, migration, patch); err != nil {
+		return errors.Wrapf(err, "failed to patch disk transfers on migration %s", migrationName)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmware/govmomi/vim25/types"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+func TestTransferMeter(t *testing.T) {
+	start := time.Now()
+	meter := &transferMeter{}
+	assert.Zero(t, meter.observe(0, start), "first observation has no rate")
+
+	// A steady rate is approached
+	var rate float64
+	for i := 1; i <= 60; i++ {
+		rate = meter.observe(int64(i)*50e6, start.Add(time.Duration(i)*5*time.Second))
+	}
+	assert.InDelta(t, 10e6, rate, 1)
+
+	// A stall slows it down without going below 0
+	rate = meter.observe(60*50e6, start.Add(305*time.Second))
+	assert.Less(t, rate, 10e6)
+	assert.Greater(t, rate, 0.0)
+
+	// No time passed
+	assert.Equal(t, rate, meter.observe(61*50e6, start.Add(305*time.Second)))
+}
+
+func TestRecordTransfer(t *testing.T) {
+	migobj := &Migrate{}
+	nbd := vjailbreakv1alpha1.DiskTransferMethodNBD
+
+	migobj.recordTransfer("disk-1", nbd, 0, 100e6)
+	migobj.recordTransfer("disk-2", nbd, 10e6, 100e6)
+	migobj.recordTransfer("disk-1", nbd, 40e6, 100e6)
+	require.Len(t, migobj.transfers.disks, 2)
+	disk1 := migobj.transfers.disks[0]
+	assert.Equal(t, "disk-1", disk1.Disk)
+	assert.Equal(t, int64(40e6), disk1.BytesCopied)
+	assert.Equal(t, int64(60e6), disk1.BytesRemaining())
+	assert.Positive(t, disk1.BytesPerSecond)
+	assert.True(t, migobj.transfers.changed)
+
+	// A CBT sync starts a new pass with its own rate
+	migobj.recordCBTDelta("disk-1", nbd, changedBytes(types.DiskChangeInfo{
+		ChangedArea: []types.DiskChangeExtent{{Start: 0, Length: 4e6}, {Start: 10e6, Length: 1e6}},
+	}))
+	migobj.recordTransfer("disk-1", nbd, 0, 5e6)
+	disk1 = migobj.transfers.disks[0]
+	assert.Equal(t, int64(5e6), disk1.CBTDeltaBytes)
+	assert.Equal(t, 1, disk1.CBTSyncs)
+	assert.Equal(t, int64(5e6), disk1.BytesTotal)
+	assert.Zero(t, disk1.BytesPerSecond)
+	_, ok := disk1.ETA()
+	assert.False(t, ok, "ETA without a rate")
+}
+
+func TestTrackTransfer(t *testing.T) {
+	migobj := &Migrate{}
+	copied := int64(0)
+	stop := migobj.trackTransfer(context.Background(), "disk-1", vjailbreakv1alpha1.DiskTransferMethodHotAdd,
+		func() (int64, int64) { return copied, 100 })
+	copied = 100
+	stop()
+	require.Len(t, migobj.transfers.disks, 1)
+	assert.Equal(t, int64(100), migobj.transfers.disks[0].BytesCopied, "the last progress is recorded on stop")
+	assert.Equal(t, vjailbreakv1alpha1.DiskTransferMethodHotAdd, migobj.transfers.disks[0].Method)
+}
+
+func TestReportTransfers(t *testing.T) {
+	builder, migrationName := checkpointTestClient(t)
+	k8sClient := builder.WithStatusSubresource(&vjailbreakv1alpha1.Migration{}).Build()
+	ctx := context.Background()
+	migobj := &Migrate{K8sClient: k8sClient}
+
+	migobj.recordTransfer("disk-1", vjailbreakv1alpha1.DiskTransferMethodStorageAccelerated, 30, 100)
+	require.NoError(t, migobj.reportTransfers(ctx, migobj.transfers.disks))
+
+	migration := &vjailbreakv1alpha1.Migration{}
+	require.NoError(t, k8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration))
+	require.Len(t, migration.Status.DiskTransfers, 1)
+	assert.Equal(t, "disk-1", migration.Status.DiskTransfers[0].Disk)
+	assert.Equal(t, int64(30), migration.Status.DiskTransfers[0].BytesCopied)
+}
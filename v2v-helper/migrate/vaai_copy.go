@@ -11,6 +11,7 @@ import (
 
 	cindervolumes "github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
 	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
 	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
 	esxissh "github.com/platform9/vjailbreak/v2v-helper/esxi-ssh"
 	"github.com/platform9/vjailbreak/v2v-helper/openstack"
@@ -63,65 +64,135 @@ func (migobj *Migrate) StorageAcceleratedCopyCopyDisks(ctx context.Context, vmin
 		return []storage.Volume{}, fmt.Errorf("storage provider not initialized for StorageAcceleratedCopy copy")
 	}
 
+	// The array-native copy reads the disks from an array clone of their
+	// datastore, the ESXi host takes no part in it
+	nativeCopy := migobj.useArrayNativeCopy()
+	var esxiClient *esxissh.Client
+	if !nativeCopy {
+		var err error
+		esxiClient, err = migobj.connectESXiForStorageCopy(ctx)
+		if err != nil {
+			return []storage.Volume{}, err
+		}
+		defer esxiClient.Disconnect()
+	}
+
+	// Verify VM is powered off before attempting StorageAcceleratedCopy copy
+	// The VM should already be powered off by the migration flow before calling this function
+	if vminfo.State != "poweredOff" {
+		migobj.logMessage(fmt.Sprintf("VM %s is not powered off (state: %s). VM must be powered off before storage copy can proceed", vminfo.Name, vminfo.State))
+		migobj.logMessage("Powering off VM")
+		if err := migobj.VMops.VMPowerOff(); err != nil {
+			return []storage.Volume{}, errors.Wrap(err, "failed to power off VM")
+		}
+		migobj.logMessage("VM powered off successfully")
+	}
+
+	migobj.logMessage(fmt.Sprintf("VM %s is powered off, proceeding with StorageAcceleratedCopy copy", vminfo.Name))
+
+	var volumes []storage.Volume
+	var err error
+	if nativeCopy {
+		volumes, err = migobj.ArrayNativeCopyDisks(ctx, vminfo)
+	} else {
+		// Wait for ESXi to release file locks on VMDK files after VM power off
+		// This is necessary to avoid "Failed to lock the file" errors during vmkfstools clone
+		migobj.logMessage("Waiting 5 seconds for ESXi to release disk file locks...")
+		time.Sleep(5 * time.Second)
+
+		volumes, err = migobj.cloneDisksToArray(ctx, esxiClient, vminfo)
+	}
+	if err != nil {
+		return []storage.Volume{}, err
+	}
+
+	// The volumes are attached to this VM one after the other
+	for idx, vmdisk := range vminfo.VMDisks {
+		// Attach the Cinder volume to get the device path
+		devicePath, err := migobj.AttachVolume(ctx, vmdisk)
+		if err != nil {
+			return []storage.Volume{}, errors.Wrapf(err, "failed to attach volume for disk %s", vmdisk.Name)
+		}
+		vminfo.VMDisks[idx].Path = devicePath
+
+		migobj.logMessage(fmt.Sprintf("Successfully copied disk %s via StorageAcceleratedCopy", vmdisk.Name))
+	}
+
+	migobj.logMessage("StorageAcceleratedCopy disk copy completed successfully")
+	return volumes, nil
+}
+
+// connectESXiForStorageCopy opens the SSH session to the ESXi host of the VM
+// that runs the vmkfstools clones
+func (migobj *Migrate) connectESXiForStorageCopy(ctx context.Context) (*esxissh.Client, error) {
 	// Get ESXi host information
 	host, err := migobj.getESXiHost(ctx)
 	if err != nil {
-		return []storage.Volume{}, errors.Wrap(err, "failed to get ESXi host")
+		return nil, errors.Wrap(err, "failed to get ESXi host")
 	}
 
 	hostIP, err := migobj.getHostIPAddress(ctx, host)
 	if err != nil {
-		return []storage.Volume{}, errors.Wrap(err, "failed to get ESXi host IP")
+		return nil, errors.Wrap(err, "failed to get ESXi host IP")
 	}
 
 	migobj.logMessage(fmt.Sprintf("ESXi host: %s (IP: %s)", host.Name(), hostIP))
 
 	// Connect to ESXi via SSH
 	esxiClient := esxissh.NewClient()
-	defer esxiClient.Disconnect()
 
 	// TODO: For now hardcode "root", give option to pass user via configmap
 	migobj.logMessage("Connecting to ESXi host via SSH")
 	if err := esxiClient.Connect(ctx, hostIP, "root", migobj.ESXiSSHPrivateKey); err != nil {
-		return []storage.Volume{}, errors.Wrap(err, "failed to connect to ESXi via SSH")
+		esxiClient.Disconnect()
+		return nil, errors.Wrap(err, "failed to connect to ESXi via SSH")
 	}
 
 	// Test the connection
 	migobj.logMessage("Testing ESXi connection")
 	if err := esxiClient.TestConnection(); err != nil {
-		return []storage.Volume{}, errors.Wrap(err, "failed to test ESXi connection")
+		esxiClient.Disconnect()
+		return nil, errors.Wrap(err, "failed to test ESXi connection")
 	}
 
 	migobj.logMessage("Connected to ESXi host via SSH")
+	return esxiClient, nil
+}
 
-	// Verify VM is powered off before attempting StorageAcceleratedCopy copy
-	// The VM should already be powered off by the migration flow before calling this function
-	if vminfo.State != "poweredOff" {
-		migobj.logMessage(fmt.Sprintf("VM %s is not powered off (state: %s). VM must be powered off before storage copy can proceed", vminfo.Name, vminfo.State))
-		migobj.logMessage("Powering off VM")
-		if err := migobj.VMops.VMPowerOff(); err != nil {
-			return []storage.Volume{}, errors.Wrap(err, "failed to power off VM")
-		}
-		migobj.logMessage("VM powered off successfully")
+// cloneDisksToArray creates a Cinder managed volume on the array for every
+// disk of vminfo and clones the disk onto it with vmkfstools. The volumes are
+// left unattached.
+func (migobj *Migrate) cloneDisksToArray(ctx context.Context, esxiClient *esxissh.Client, vminfo vm.VMInfo) ([]storage.Volume, error) {
+	// The array connection and the initiator group of the ESXi host are shared by
+	// all disks, which may be cloned in parallel
+	if err := migobj.InitializeStorageProvider(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to initialize storage provider")
 	}
+	defer migobj.StorageProvider.Disconnect()
 
-	migobj.logMessage(fmt.Sprintf("VM %s is powered off, proceeding with StorageAcceleratedCopy copy", vminfo.Name))
-
-	// Wait for ESXi to release file locks on VMDK files after VM power off
-	// This is necessary to avoid "Failed to lock the file" errors during vmkfstools clone
-	migobj.logMessage("Waiting 5 seconds for ESXi to release disk file locks...")
-	time.Sleep(5 * time.Second)
+	hostAdapters, err := esxiClient.GetAllHostAdapters()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ESXi host adapters")
+	}
+	migobj.logMessage(fmt.Sprintf("ESXi host adapters: %v", hostAdapters))
 
-	volumes := []storage.Volume{}
+	// Map host adapters to initiator group on the storage array
+	initiatorGroup := "vjailbreak-xcopy"
+	migobj.logMessage(fmt.Sprintf("Creating/updating initiator group: %s", initiatorGroup))
+	mappingContext, err := migobj.StorageProvider.CreateOrUpdateInitiatorGroup(initiatorGroup, hostAdapters)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create initiator group %s", initiatorGroup)
+	}
 
-	// Process each disk
-	for idx, vmdisk := range vminfo.VMDisks {
+	volumes := make([]storage.Volume, len(vminfo.VMDisks))
+	workers := migobj.diskCopyWorkers(ctx, len(vminfo.VMDisks), 1)
+	err = copyDisksInParallel(ctx, len(vminfo.VMDisks), workers, func(ctx context.Context, idx int) error {
+		vmdisk := vminfo.VMDisks[idx]
 		migobj.logMessage(fmt.Sprintf("Processing disk %d/%d: %s", idx+1, len(vminfo.VMDisks), vmdisk.Name))
-
 		// use vmkfstools RDM clone
-		clonedVolume, err := migobj.copyDiskViaStorageAcceleratedCopy(ctx, esxiClient, idx, &vminfo, hostIP)
+		clonedVolume, err := migobj.copyDiskViaStorageAcceleratedCopy(ctx, esxiClient, idx, &vminfo, initiatorGroup, mappingContext)
 		if err != nil {
-			return []storage.Volume{}, errors.Wrapf(err, "failed to copy disk %s via StorageAcceleratedCopy", vmdisk.Name)
+			return errors.Wrapf(err, "failed to copy disk %s via StorageAcceleratedCopy", vmdisk.Name)
 		}
 
 		// Update the disk with the OpenStack volume info from the cloned volume
@@ -131,25 +202,18 @@ func (migobj *Migrate) StorageAcceleratedCopyCopyDisks(ctx context.Context, vmin
 			Size: int(clonedVolume.Size / (1024 * 1024 * 1024)), // Convert bytes to GB
 		}
 		migobj.logMessage(fmt.Sprintf("Updated disk %s with Cinder volume ID: %s", vmdisk.Name, clonedVolume.OpenstackVol.ID))
-
-		// Attach the Cinder volume to get the device path
-		devicePath, err := migobj.AttachVolume(ctx, vminfo.VMDisks[idx])
-		if err != nil {
-			return []storage.Volume{}, errors.Wrapf(err, "failed to attach volume for disk %s", vmdisk.Name)
-		}
-		vminfo.VMDisks[idx].Path = devicePath
-		volumes = append(volumes, clonedVolume)
-
-		migobj.logMessage(fmt.Sprintf("Successfully copied disk %s via StorageAcceleratedCopy", vmdisk.Name))
+		volumes[idx] = clonedVolume
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	migobj.logMessage("StorageAcceleratedCopy disk copy completed successfully")
 	return volumes, nil
 }
 
 // copyDiskViaStorageAcceleratedCopy copies a single disk using StorageAcceleratedCopy XCOPY
 func (migobj *Migrate) copyDiskViaStorageAcceleratedCopy(ctx context.Context, esxiClient *esxissh.Client,
-	idx int, vminfo *vm.VMInfo, hostIP string,
+	idx int, vminfo *vm.VMInfo, initiatorGroup string, mappingContext storage.MappingContext,
 ) (storage.Volume, error) {
 	startTime := time.Now()
 
@@ -158,65 +222,13 @@ func (migobj *Migrate) copyDiskViaStorageAcceleratedCopy(ctx context.Context, es
 	defer func() {
 		migobj.logMessage(fmt.Sprintf("StorageAcceleratedCopy XCOPY completed in %s (total: %s) for disk %s",
 			time.Since(startTime).Round(time.Second), time.Since(startTime).Round(time.Second), vmDisk.Name))
-		migobj.StorageProvider.Disconnect()
 	}()
-	// Step 1: Initialize storage provider
-	migobj.InitializeStorageProvider(ctx)
 
-	// Step 2: Get all ESXi host HBA adapters (IQN for iSCSI, fc.WWNN:WWPN for FC)
-	hostAdapters, err := esxiClient.GetAllHostAdapters()
+	targetVolume, cinderVolumeName, err := migobj.createManagedTargetVolume(ctx, idx, vminfo)
 	if err != nil {
-		return storage.Volume{}, errors.Wrap(err, "failed to get ESXi host adapters")
+		return storage.Volume{}, err
 	}
-	migobj.logMessage(fmt.Sprintf("ESXi host adapters: %v", hostAdapters))
-
-	// Step 3: Map host adapters to initiator group on the storage array
-	initiatorGroup := "vjailbreak-xcopy"
-	migobj.logMessage(fmt.Sprintf("Creating/updating initiator group: %s", initiatorGroup))
-	mappingContext, err := migobj.StorageProvider.CreateOrUpdateInitiatorGroup(initiatorGroup, hostAdapters)
-	if err != nil {
-		return storage.Volume{}, errors.Wrapf(err, "failed to create initiator group %s", initiatorGroup)
-	}
-
-	// Step 4: Create target volume with sanitized name
-	// Use vmDisk.Size (VMware disk size in bytes) - Pure API expects size in bytes
-	diskSizeBytes := vmDisk.Size
-	// Ensure size is a multiple of 512 (sector alignment)
-	if diskSizeBytes%512 != 0 {
-		diskSizeBytes = ((diskSizeBytes / 512) + 1) * 512
-	}
-	sanitizedName := sanitizeVolumeName(vminfo.Name + "-" + vmDisk.Name)
-	migobj.logMessage(fmt.Sprintf("Creating target volume %s (sanitized from: %s) with size %d bytes (%d GB)",
-		sanitizedName, vmDisk.Name, diskSizeBytes, diskSizeBytes/(1024*1024*1024)))
-	targetVolume, err := migobj.StorageProvider.CreateVolume(sanitizedName, diskSizeBytes)
-	if err != nil {
-		return storage.Volume{}, errors.Wrapf(err, "failed to create target volume %s", sanitizedName)
-	}
-
-	// Step 5: Cinder manage the volume FIRST
-	// This renames the volume on Pure to volume-<cinder-id>-cinder
-	migobj.logMessage(fmt.Sprintf("Cinder managing the volume %s", targetVolume.Name))
-	cinderVolumeId, err := migobj.manageVolumeToCinder(ctx, targetVolume.Name, vmDisk)
-	if err != nil {
-		return storage.Volume{}, errors.Wrapf(err, "failed to Cinder manage volume %s", targetVolume.Name)
-	}
-	vminfo.VMDisks[idx].OpenstackVol = &cindervolumes.Volume{
-		ID:   cinderVolumeId,
-		Name: targetVolume.Name,
-		Size: int(targetVolume.Size / (1024 * 1024 * 1024)), // Convert bytes to GB
-	}
-
-	// After Cinder manage, the volume name changes based on the backend driver:
-	// - Pure: volume-<cinder-id>-cinder
-	// - NetApp: /vol/<volume_path>/volume-<cinder-id> (includes the full LUN path)
-	// We use wildcard search with volume-<cinder-id> prefix which matches both patterns
-	// Use ResolveCinderVolumeToLUN to get the actual renamed volume from the storage array
-	resolvedVol, err := migobj.StorageProvider.ResolveCinderVolumeToLUN(cinderVolumeId)
-	if err != nil {
-		return storage.Volume{}, errors.Wrapf(err, "failed to resolve Cinder volume %s on storage array", cinderVolumeId)
-	}
-	cinderVolumeName := resolvedVol.Name
-	migobj.logMessage(fmt.Sprintf("Volume renamed by Cinder to: %s", cinderVolumeName))
+	cinderVolumeId := targetVolume.OpenstackVol.ID
 	// Step 6: Map target volume to ESXi host using the NEW Cinder volume name
 	migobj.logMessage(fmt.Sprintf("Mapping target volume %s to ESXi host", cinderVolumeName))
 	targetVol := storage.Volume{
@@ -265,10 +277,11 @@ func (migobj *Migrate) copyDiskViaStorageAcceleratedCopy(ctx context.Context, es
 
 	// Step 7: Perform StorageAcceleratedCopy XCOPY clone directly to raw device (RDM format)
 	// This clones directly to the raw device without needing a datastore
-	migobj.logMessage(fmt.Sprintf("Starting StorageAcceleratedCopy XCOPY clone: %s -> %s (RDM)", vmDisk.Path, targetDevicePath))
+	sourcePath := migobj.storageCopySource(vmDisk)
+	migobj.logMessage(fmt.Sprintf("Starting StorageAcceleratedCopy XCOPY clone: %s -> %s (RDM)", sourcePath, targetDevicePath))
 
 	cloneStart := time.Now()
-	task, err := esxiClient.StartVmkfstoolsRDMClone(vmDisk.Path, targetDevicePath)
+	task, err := esxiClient.StartVmkfstoolsRDMClone(sourcePath, targetDevicePath)
 	if err != nil {
 		return storage.Volume{}, errors.Wrapf(err, "failed to start StorageAcceleratedCopy RDM clone for disk %s", vmDisk.Name)
 	}
@@ -276,6 +289,10 @@ func (migobj *Migrate) copyDiskViaStorageAcceleratedCopy(ctx context.Context, es
 	// Step 8: Monitor clone progress
 	tracker := esxissh.NewCloneTracker(esxiClient, task, idx, migobj)
 	tracker.SetPollInterval(2 * time.Second)
+	tracker.OnProgress(func(percentDone float64) {
+		migobj.recordTransfer(vmDisk.Name, vjailbreakv1alpha1.DiskTransferMethodStorageAccelerated,
+			int64(percentDone/100*float64(vmDisk.Size)), vmDisk.Size)
+	})
 
 	err = tracker.WaitForCompletion(ctx)
 	if err != nil {
@@ -288,12 +305,67 @@ func (migobj *Migrate) copyDiskViaStorageAcceleratedCopy(ctx context.Context, es
 	migobj.logMessage(fmt.Sprintf("Copy completed in %s (total: %s) for disk %s",
 		cloneDuration.Round(time.Second), totalDuration.Round(time.Second), vmDisk.Name))
 
-	// Update the target volume with Cinder info
+	return targetVolume, nil
+}
+
+// createManagedTargetVolume creates the array volume disk idx of vminfo is
+// copied to and manages it into Cinder. It returns the volume, carrying its
+// Cinder ID, and the name Cinder renamed it to on the array.
+func (migobj *Migrate) createManagedTargetVolume(ctx context.Context, idx int, vminfo *vm.VMInfo) (storage.Volume, string, error) {
+	vmDisk := vminfo.VMDisks[idx]
+
+	// Step 4: Create target volume with sanitized name
+	// Use vmDisk.Size (VMware disk size in bytes) - Pure API expects size in bytes
+	diskSizeBytes := vmDisk.Size
+	// Ensure size is a multiple of 512 (sector alignment)
+	if diskSizeBytes%512 != 0 {
+		diskSizeBytes = ((diskSizeBytes / 512) + 1) * 512
+	}
+	sanitizedName := sanitizeVolumeName(vminfo.Name + "-" + vmDisk.Name)
+	migobj.logMessage(fmt.Sprintf("Creating target volume %s (sanitized from: %s) with size %d bytes (%d GB)",
+		sanitizedName, vmDisk.Name, diskSizeBytes, diskSizeBytes/(1024*1024*1024)))
+	targetVolume, err := migobj.StorageProvider.CreateVolume(sanitizedName, diskSizeBytes)
+	if err != nil {
+		return storage.Volume{}, "", errors.Wrapf(err, "failed to create target volume %s", sanitizedName)
+	}
+
+	// Step 5: Cinder manage the volume FIRST
+	// This renames the volume on Pure to volume-<cinder-id>-cinder
+	migobj.logMessage(fmt.Sprintf("Cinder managing the volume %s", targetVolume.Name))
+	cinderVolumeId, err := migobj.manageVolumeToCinder(ctx, targetVolume.Name, vmDisk)
+	if err != nil {
+		return storage.Volume{}, "", errors.Wrapf(err, "failed to Cinder manage volume %s", targetVolume.Name)
+	}
+	vminfo.VMDisks[idx].OpenstackVol = &cindervolumes.Volume{
+		ID:   cinderVolumeId,
+		Name: targetVolume.Name,
+		Size: int(targetVolume.Size / (1024 * 1024 * 1024)), // Convert bytes to GB
+	}
+
+	// Arrays with no Cinder driver behind them rename the volume themselves
+	if recorder, ok := migobj.StorageProvider.(storage.CinderManageRecorder); ok {
+		if err := recorder.ManageVolume(targetVolume.Name, cinderVolumeId); err != nil {
+			return storage.Volume{}, "", errors.Wrapf(err, "failed to record the Cinder manage of volume %s", targetVolume.Name)
+		}
+	}
+
+	// After Cinder manage, the volume name changes based on the backend driver:
+	// - Pure: volume-<cinder-id>-cinder
+	// - NetApp: /vol/<volume_path>/volume-<cinder-id> (includes the full LUN path)
+	// - PowerStore: volume-<cinder-id>
+	// - HPE: osv-<base64 of the cinder-id>
+	// We use wildcard search with volume-<cinder-id> prefix which matches both patterns
+	// Use ResolveCinderVolumeToLUN to get the actual renamed volume from the storage array
+	resolvedVol, err := migobj.StorageProvider.ResolveCinderVolumeToLUN(cinderVolumeId)
+	if err != nil {
+		return storage.Volume{}, "", errors.Wrapf(err, "failed to resolve Cinder volume %s on storage array", cinderVolumeId)
+	}
+	cinderVolumeName := resolvedVol.Name
+	migobj.logMessage(fmt.Sprintf("Volume renamed by Cinder to: %s", cinderVolumeName))
 	targetVolume.OpenstackVol = storage.OpenstackVolume{
 		ID: cinderVolumeId,
 	}
-
-	return targetVolume, nil
+	return targetVolume, cinderVolumeName, nil
 }
 
 // ValidateStorageAcceleratedCopyPrerequisites validates that all prerequisites for StorageAcceleratedCopy copy are met
@@ -334,6 +406,16 @@ func (migobj *Migrate) getESXiHost(ctx context.Context) (*object.HostSystem, err
 		return nil, fmt.Errorf("VM has no host")
 	}
 
+	vcclient, err := migobj.vcenterClient()
+	if err != nil {
+		return nil, err
+	}
+	host := object.NewHostSystem(vcclient.VCClient, *vmProps.Runtime.Host)
+	return host, nil
+}
+
+// vcenterClient returns the vCenter client behind VMops
+func (migobj *Migrate) vcenterClient() (*vcenter.VCenterClient, error) {
 	// Access VCenterClient through the VMOps interface
 	// VMops is of type vm.VMOperations interface, we need the concrete type
 	// to access GetVCenterClient() which is not part of the interface
@@ -345,9 +427,7 @@ func (migobj *Migrate) getESXiHost(ctx context.Context) (*object.HostSystem, err
 	if !ok {
 		return nil, fmt.Errorf("VMops does not implement GetVCenterClient()")
 	}
-
-	host := object.NewHostSystem(vcGetter.GetVCenterClient().VCClient, *vmProps.Runtime.Host)
-	return host, nil
+	return vcGetter.GetVCenterClient(), nil
 }
 
 // getHostIPAddress returns the management IP address of an ESXi host
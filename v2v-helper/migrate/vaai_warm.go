@@ -0,0 +1,97 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/nbd"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+)
+
+// warmStorageCopy reports whether a StorageAcceleratedCopy migration keeps the
+// VM running while its disks are cloned. Hot and mock migrations do, the
+// writes made during the clone are caught up with over NBD.
+func (migobj *Migrate) warmStorageCopy() bool {
+	return migobj.StorageCopyMethod == constants.StorageCopyMethod &&
+		(migobj.MigrationType == "hot" || migobj.MigrationType == "mock")
+}
+
+// storageCopySource is the VMDK that vmkfstools clones, or that is extracted
+// from the array clone of its datastore, for vmdisk. A warm copy reads the base
+// disk frozen by the migration snapshot, the running VM only writes to the
+// delta disk on top of it.
+func (migobj *Migrate) storageCopySource(vmdisk vm.VMDisk) string {
+	if migobj.warmStorageCopy() && vmdisk.SnapBackingDisk != "" {
+		return vmdisk.SnapBackingDisk
+	}
+	return vmdisk.Path
+}
+
+// WarmStorageAcceleratedCopyDisks copies the disks of a running VM with
+// StorageAcceleratedCopy. The disks are XCOPY cloned from the migration
+// snapshot onto array volumes, which then stand for the full copy of
+// LiveReplicateDisks: it only copies the blocks changed since the snapshot
+// over NBD, and powers the VM off at cutover for the last of them.
+func (migobj *Migrate) WarmStorageAcceleratedCopyDisks(ctx context.Context, vminfo vm.VMInfo) (vm.VMInfo, error) {
+	migobj.logMessage("Starting warm StorageAcceleratedCopy, the VM keeps running during the XCOPY clone")
+	vmops := migobj.VMops
+
+	if err := migobj.EnableCBTWrapper(); err != nil {
+		return vminfo, errors.Wrap(err, "CBT Failure")
+	}
+
+	// The clones of an interrupted migration only need their changed blocks
+	resumed, ok := migobj.resumeCopy(ctx, vminfo)
+	if ok {
+		vminfo = resumed
+	} else {
+		utils.PrintLog("Cleaning up snapshots before copy")
+		if err := vmops.CleanUpSnapshots(false); err != nil {
+			return vminfo, errors.Wrap(err, "failed to clean up snapshots, please delete manually before starting again")
+		}
+		if err := vmops.TakeSnapshot(constants.MigrationSnapshotName); err != nil {
+			return vminfo, errors.Wrap(err, "failed to take snapshot of source VM")
+		}
+		migobj.convergence.snapshotTaken(time.Now())
+		if err := vmops.UpdateDisksInfo(&vminfo, true); err != nil {
+			return vminfo, errors.Wrap(err, "failed to update disk info")
+		}
+
+		if migobj.useArrayNativeCopy() {
+			if _, err := migobj.ArrayNativeCopyDisks(ctx, vminfo); err != nil {
+				return vminfo, err
+			}
+		} else {
+			esxiClient, err := migobj.connectESXiForStorageCopy(ctx)
+			if err != nil {
+				return vminfo, err
+			}
+			_, err = migobj.cloneDisksToArray(ctx, esxiClient, vminfo)
+			esxiClient.Disconnect()
+			if err != nil {
+				return vminfo, err
+			}
+		}
+
+		// The volumes hold the disks as of the snapshot change IDs
+		checkpoint := newCopyCheckpoint(vminfo)
+		for idx := range checkpoint.Disks {
+			checkpoint.Disks[idx].Offset = checkpoint.Disks[idx].Size
+		}
+		migobj.copyCheckpoint = checkpoint
+		migobj.saveCopyCheckpoint(ctx)
+		migobj.logMessage(fmt.Sprintf("Array copy of %d disks completed, copying the blocks changed since snapshot %s",
+			len(vminfo.VMDisks), checkpoint.SnapshotMOID))
+	}
+
+	for range vminfo.VMDisks {
+		migobj.Nbdops = append(migobj.Nbdops, &nbd.NBDServer{Throttle: migobj.Throttle, Transport: migobj.VDDKTransport})
+	}
+	return migobj.LiveReplicateDisks(ctx, vminfo)
+}
@@ -0,0 +1,59 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"testing"
+
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageCopySource(t *testing.T) {
+	vmdisk := vm.VMDisk{
+		Name:            "disk1",
+		Path:            "[ds1] vm1/vm1-000001.vmdk",
+		SnapBackingDisk: "[ds1] vm1/vm1.vmdk",
+	}
+	tests := []struct {
+		name     string
+		migobj   *Migrate
+		wantWarm bool
+		want     string
+	}{
+		{
+			name:   "cold storage accelerated copy",
+			migobj: &Migrate{StorageCopyMethod: constants.StorageCopyMethod, MigrationType: "cold"},
+			want:   vmdisk.Path,
+		},
+		{
+			name:     "hot storage accelerated copy",
+			migobj:   &Migrate{StorageCopyMethod: constants.StorageCopyMethod, MigrationType: "hot"},
+			wantWarm: true,
+			want:     vmdisk.SnapBackingDisk,
+		},
+		{
+			name:     "mock storage accelerated copy",
+			migobj:   &Migrate{StorageCopyMethod: constants.StorageCopyMethod, MigrationType: "mock"},
+			wantWarm: true,
+			want:     vmdisk.SnapBackingDisk,
+		},
+		{
+			name:   "hot NBD copy",
+			migobj: &Migrate{MigrationType: "hot"},
+			want:   vmdisk.Path,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantWarm, tt.migobj.warmStorageCopy())
+			assert.Equal(t, tt.want, tt.migobj.storageCopySource(vmdisk))
+		})
+	}
+
+	t.Run("no snapshot backing disk", func(t *testing.T) {
+		migobj := &Migrate{StorageCopyMethod: constants.StorageCopyMethod, MigrationType: "hot"}
+		assert.Equal(t, vmdisk.Path, migobj.storageCopySource(vm.VMDisk{Path: vmdisk.Path}))
+	})
+}
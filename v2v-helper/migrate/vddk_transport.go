@@ -0,0 +1,100 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+)
+
+// sysfsRoot is where the agent's sysfs is read from, tests point it elsewhere
+var sysfsRoot = "/sys"
+
+// vddkTransportAvailable returns why mode cannot be used from this agent, or ""
+// when it can. It only rules out what the agent can tell on its own: SAN needs a
+// Fibre Channel or iSCSI initiator and HotAdd the agent to run as a VMware VM.
+// Whether they reach the datastore of the disk only VDDK finds out.
+func vddkTransportAvailable(mode string) string {
+	switch vjailbreakv1alpha1.VDDKTransportMode(mode) {
+	case vjailbreakv1alpha1.VDDKTransportModeNBD, vjailbreakv1alpha1.VDDKTransportModeNBDSSL:
+		return ""
+	case vjailbreakv1alpha1.VDDKTransportModeSAN:
+		for _, class := range []string{"fc_host", "iscsi_session"} {
+			if entries, err := os.ReadDir(filepath.Join(sysfsRoot, "class", class)); err == nil && len(entries) > 0 {
+				return ""
+			}
+		}
+		return "the agent has no Fibre Channel or iSCSI initiator"
+	case vjailbreakv1alpha1.VDDKTransportModeHotAdd:
+		vendor, err := os.ReadFile(filepath.Join(sysfsRoot, "class", "dmi", "id", "sys_vendor"))
+		if err == nil && strings.Contains(string(vendor), "VMware") {
+			return ""
+		}
+		return "the agent is not a VMware virtual machine"
+	case vjailbreakv1alpha1.VDDKTransportModeFile:
+		return "the agent has no local copy of the disk"
+	default:
+		return "unknown transport mode"
+	}
+}
+
+// checkVDDKTransportSupported fails a migration early when none of the VDDK
+// transport modes it asks for can work from this agent. Modes that cannot are
+// logged, VDDK falls back past them to the next.
+func (migobj *Migrate) checkVDDKTransportSupported() error {
+	// A warm StorageAcceleratedCopy reads the changed blocks through VDDK
+	if (migobj.StorageCopyMethod == constants.StorageCopyMethod && !migobj.warmStorageCopy()) ||
+		migobj.StorageCopyMethod == constants.HotAddCopyMethod ||
+		migobj.IsStandaloneESXi() {
+		return nil
+	}
+	modes := migobj.VDDKTransport.ModeList()
+	var usable []string
+	var reasons []string
+	for _, mode := range modes {
+		if reason := vddkTransportAvailable(mode); reason != "" {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", mode, reason))
+			continue
+		}
+		usable = append(usable, mode)
+	}
+	if len(usable) == 0 {
+		return errors.Errorf("none of the VDDK transport modes %s can be used from this agent (%s)",
+			strings.Join(modes, ":"), strings.Join(reasons, "; "))
+	}
+	if len(reasons) > 0 && len(migobj.VDDKTransport.Modes) > 0 {
+		utils.PrintLog(fmt.Sprintf("Skipping unusable VDDK transport modes (%s), falling back to %s",
+			strings.Join(reasons, "; "), strings.Join(usable, ":")))
+	}
+	utils.PrintLog(fmt.Sprintf("VDDK transport modes %s validated", strings.Join(modes, ":")))
+	return nil
+}
+
+// recordTransportMode records the VDDK transport mode the copy of disk name
+// used, and warns when it is not the first that was asked for
+func (migobj *Migrate) recordTransportMode(name, mode string) {
+	if mode == "" {
+		return
+	}
+	d := &migobj.transfers
+	d.mu.Lock()
+	transfer := d.disk(name, vjailbreakv1alpha1.DiskTransferMethodNBD)
+	if transfer.Transport != vjailbreakv1alpha1.VDDKTransportMode(mode) {
+		transfer.Transport = vjailbreakv1alpha1.VDDKTransportMode(mode)
+		d.changed = true
+	}
+	d.mu.Unlock()
+
+	if preferred := migobj.VDDKTransport.ModeList()[0]; mode != preferred && len(migobj.VDDKTransport.Modes) > 0 {
+		migobj.logMessage(fmt.Sprintf("Warning: disk %s was copied with VDDK transport %s, not the preferred %s", name, mode, preferred))
+	} else {
+		utils.PrintLog(fmt.Sprintf("Disk %s was copied with VDDK transport %s", name, mode))
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/nbd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSysfs points sysfsRoot at a directory holding files, relative to it
+func fakeSysfs(t *testing.T, files map[string]string) {
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	previous := sysfsRoot
+	sysfsRoot = root
+	t.Cleanup(func() { sysfsRoot = previous })
+}
+
+func TestCheckVDDKTransportSupported(t *testing.T) {
+	vmwareGuest := map[string]string{"class/dmi/id/sys_vendor": "VMware, Inc.\n"}
+	iscsi := map[string]string{"class/iscsi_session/session1/targetname": "iqn.2001-05.com.example:lun1"}
+	tests := []struct {
+		name       string
+		sysfs      map[string]string
+		migobj     *Migrate
+		wantErrMsg string
+	}{
+		{name: "defaults", migobj: &Migrate{}},
+		{
+			name:   "SAN falls back to NBD",
+			migobj: &Migrate{VDDKTransport: nbd.VDDKTransport{Modes: []string{"san", "nbdssl"}}},
+		},
+		{
+			name:       "SAN only without an initiator",
+			migobj:     &Migrate{VDDKTransport: nbd.VDDKTransport{Modes: []string{"san"}}},
+			wantErrMsg: "no Fibre Channel or iSCSI initiator",
+		},
+		{
+			name:   "SAN only over iSCSI",
+			sysfs:  iscsi,
+			migobj: &Migrate{VDDKTransport: nbd.VDDKTransport{Modes: []string{"san"}}},
+		},
+		{
+			name:       "HotAdd only on bare metal",
+			sysfs:      map[string]string{"class/dmi/id/sys_vendor": "Dell Inc.\n"},
+			migobj:     &Migrate{VDDKTransport: nbd.VDDKTransport{Modes: []string{"hotadd"}}},
+			wantErrMsg: "not a VMware virtual machine",
+		},
+		{
+			name:   "HotAdd only in a VMware VM",
+			sysfs:  vmwareGuest,
+			migobj: &Migrate{VDDKTransport: nbd.VDDKTransport{Modes: []string{"hotadd"}}},
+		},
+		{
+			name: "another copy method",
+			migobj: &Migrate{
+				StorageCopyMethod: constants.StorageCopyMethod,
+				VDDKTransport:     nbd.VDDKTransport{Modes: []string{"san"}},
+			},
+		},
+		{
+			name:  "warm storage accelerated copy",
+			sysfs: map[string]string{"class/dmi/id/sys_vendor": "Dell Inc.\n"},
+			migobj: &Migrate{
+				StorageCopyMethod: constants.StorageCopyMethod,
+				MigrationType:     "hot",
+				VDDKTransport:     nbd.VDDKTransport{Modes: []string{"hotadd"}},
+			},
+			wantErrMsg: "not a VMware virtual machine",
+		},
+		{
+			name: "standalone ESXi",
+			migobj: &Migrate{
+				SourceHostType: string(vjailbreakv1alpha1.VMwareHostTypeESXi),
+				VDDKTransport:  nbd.VDDKTransport{Modes: []string{"hotadd"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeSysfs(t, tt.sysfs)
+			err := tt.migobj.checkVDDKTransportSupported()
+			if tt.wantErrMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErrMsg)
+		})
+	}
+}
+
+func TestRecordTransportMode(t *testing.T) {
+	migobj := &Migrate{VDDKTransport: nbd.VDDKTransport{Modes: []string{"san", "nbdssl"}}}
+	migobj.recordTransportMode("disk-1", "")
+	assert.Empty(t, migobj.transfers.disks, "nothing to record before the disk is opened")
+
+	migobj.recordTransportMode("disk-1", "nbdssl")
+	require.Len(t, migobj.transfers.disks, 1)
+	assert.Equal(t, vjailbreakv1alpha1.VDDKTransportModeNBDSSL, migobj.transfers.disks[0].Transport)
+	assert.Equal(t, vjailbreakv1alpha1.DiskTransferMethodNBD, migobj.transfers.disks[0].Method)
+	assert.True(t, migobj.transfers.changed)
+}
@@ -0,0 +1,159 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/nbd"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// diskVerifier compares a copied disk with the source it was copied from and
+// copies ranges of it again. The NBD servers of a live replication and
+// nbd.Source for any other NBD export are one.
+type diskVerifier interface {
+	VerifyDisk(ctx context.Context, dest string, ranges []nbd.VerifyRange) ([]nbd.VerifyRange, error)
+	RepairDisk(ctx context.Context, dest string, ranges []nbd.VerifyRange, destEncrypted bool) error
+}
+
+// checkVerificationSupported fails a migration that asks for integrity
+// verification with a copy method that has no NBD source to read back from
+func (migobj *Migrate) checkVerificationSupported() error {
+	if migobj.VerifyMode == "" {
+		return nil
+	}
+	if migobj.StorageCopyMethod == constants.StorageCopyMethod && !migobj.warmStorageCopy() {
+		return errors.New("integrity verification is not available with a cold StorageAcceleratedCopy")
+	}
+	if migobj.IsStandaloneESXi() {
+		return errors.New("integrity verification is not available for a standalone ESXi source")
+	}
+	return nil
+}
+
+// verifyDisks compares every disk of vminfo, attached at its Path, with its
+// verifier. Ranges that differ are copied again up to
+// constants.VerifyRepairAttempts times. The results are recorded on the
+// Migration status, and an error is returned while any disk still differs so
+// that no VM is created from it.
+func (migobj *Migrate) verifyDisks(ctx context.Context, vminfo vm.VMInfo, verifiers []diskVerifier) error {
+	if migobj.VerifyMode == "" {
+		return nil
+	}
+	if len(verifiers) != len(vminfo.VMDisks) {
+		return errors.Errorf("have %d sources to verify %d disks against", len(verifiers), len(vminfo.VMDisks))
+	}
+	samplePercent := 100
+	if migobj.VerifyMode == string(vjailbreakv1alpha1.VerificationModeSampled) {
+		samplePercent = migobj.VerifySamplePercent
+	}
+
+	results := make([]vjailbreakv1alpha1.DiskVerification, 0, len(vminfo.VMDisks))
+	var unverified []string
+	for idx, disk := range vminfo.VMDisks {
+		result, err := migobj.verifyDisk(ctx, idx, disk, verifiers[idx], samplePercent)
+		if err != nil {
+			return errors.Wrapf(err, "failed to verify disk %s", disk.Name)
+		}
+		results = append(results, result)
+		if !result.Verified {
+			unverified = append(unverified, disk.Name)
+		}
+	}
+
+	if err := migobj.reportVerification(ctx, results); err != nil {
+		migobj.logMessage(fmt.Sprintf("Warning: failed to report disk verification: %v", err))
+	}
+	if len(unverified) > 0 {
+		return errors.Errorf("disks %s still differ from the source after %d repairs",
+			strings.Join(unverified, ", "), constants.VerifyRepairAttempts)
+	}
+	migobj.logMessage("All disks match the source")
+	return nil
+}
+
+func (migobj *Migrate) verifyDisk(ctx context.Context, idx int, disk vm.VMDisk, verifier diskVerifier, samplePercent int) (vjailbreakv1alpha1.DiskVerification, error) {
+	result := vjailbreakv1alpha1.DiskVerification{
+		Disk: disk.Name,
+		Mode: vjailbreakv1alpha1.VerificationMode(migobj.VerifyMode),
+	}
+	ranges := nbd.PlanVerifyRanges(disk.Size, samplePercent)
+	for _, r := range ranges {
+		result.BytesVerified += r.Length
+	}
+	migobj.logMessage(fmt.Sprintf("Verifying disk %d (%s): comparing %d bytes in %d ranges with the source",
+		idx, disk.Name, result.BytesVerified, len(ranges)))
+
+	mismatches, err := verifier.VerifyDisk(ctx, disk.Path, ranges)
+	if err != nil {
+		return result, err
+	}
+	result.Mismatches = len(mismatches)
+	for _, r := range mismatches[:min(len(mismatches), constants.VerifyMismatchReportLimit)] {
+		result.MismatchedRanges = append(result.MismatchedRanges, r.String())
+	}
+
+	destEncrypted := disk.OpenstackVol != nil && disk.OpenstackVol.Encrypted
+	for attempt := 1; len(mismatches) > 0 && attempt <= constants.VerifyRepairAttempts; attempt++ {
+		migobj.logMessage(fmt.Sprintf("Warning: %d ranges of disk %d (%s) differ from the source, copying them again [%d/%d]",
+			len(mismatches), idx, disk.Name, attempt, constants.VerifyRepairAttempts))
+		if err := verifier.RepairDisk(ctx, disk.Path, mismatches, destEncrypted); err != nil {
+			return result, err
+		}
+		if mismatches, err = verifier.VerifyDisk(ctx, disk.Path, mismatches); err != nil {
+			return result, err
+		}
+	}
+	result.Repaired = result.Mismatches - len(mismatches)
+	result.Verified = len(mismatches) == 0
+
+	if result.Verified {
+		migobj.logMessage(fmt.Sprintf("Disk %d (%s) matches the source", idx, disk.Name))
+	} else {
+		utils.PrintLog(fmt.Sprintf("Disk %d (%s) still differs from the source in %d ranges", idx, disk.Name, len(mismatches)))
+	}
+	return result, nil
+}
+
+// reportVerification records the verification results on the Migration status
+func (migobj *Migrate) reportVerification(ctx context.Context, results []vjailbreakv1alpha1.DiskVerification) error {
+	if migobj.K8sClient == nil {
+		return nil
+	}
+	migrationName, err := utils.GetMigrationObjectName()
+	if err != nil {
+		return errors.Wrap(err, "failed to get migration object name")
+	}
+	migration := &vjailbreakv1alpha1.Migration{}
+	if err := migobj.K8sClient.Get(ctx, k8stypes.NamespacedName{
+		Name:      migrationName,
+		Namespace: constants.NamespaceMigrationSystem,
+	}, migration); err != nil {
+		return errors.Wrapf(err, "failed to get migration %s to patch disk verification", migrationName)
+	}
+	patch := client.MergeFrom(migration.DeepCopy())
+	migration.Status.DiskVerification = results
+	if err := migobj.K8sClient.Status().Patch(ctx, migration, patch); err != nil {
+		return errors.Wrapf(err, "failed to patch disk verification on migration %s", migrationName)
+	}
+	return nil
+}
+
+// nbdVerifiers verifies the disks of a live replication against the snapshot
+// their NBD servers export
+func nbdVerifiers(nbdops []nbd.NBDOperations) []diskVerifier {
+	verifiers := make([]diskVerifier, len(nbdops))
+	for idx, nbdserver := range nbdops {
+		verifiers[idx] = nbdserver
+	}
+	return verifiers
+}
@@ -0,0 +1,142 @@
+// Copyright © 2025 The vjailbreak authors
+
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
+	"github.com/platform9/vjailbreak/pkg/common/constants"
+	"github.com/platform9/vjailbreak/v2v-helper/nbd"
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+func TestCheckVerificationSupported(t *testing.T) {
+	tests := []struct {
+		name       string
+		migobj     *Migrate
+		wantErrMsg string
+	}{
+		{name: "off", migobj: &Migrate{StorageCopyMethod: constants.StorageCopyMethod}},
+		{name: "live replication", migobj: &Migrate{VerifyMode: "Full"}},
+		{name: "hot-add", migobj: &Migrate{VerifyMode: "Sampled", StorageCopyMethod: constants.HotAddCopyMethod}},
+		{
+			name:       "storage accelerated copy",
+			migobj:     &Migrate{VerifyMode: "Full", StorageCopyMethod: constants.StorageCopyMethod},
+			wantErrMsg: "not available with a cold StorageAcceleratedCopy",
+		},
+		{
+			name:   "warm storage accelerated copy",
+			migobj: &Migrate{VerifyMode: "Full", StorageCopyMethod: constants.StorageCopyMethod, MigrationType: "hot"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.migobj.checkVerificationSupported()
+			if tt.wantErrMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErrMsg)
+		})
+	}
+}
+
+func TestVerifyDisks(t *testing.T) {
+	const diskSize = 10 * nbd.VerifyBlockSize
+	bad := []nbd.VerifyRange{{Offset: 3 * nbd.VerifyBlockSize, Length: nbd.VerifyBlockSize}}
+	vminfo := vm.VMInfo{VMDisks: []vm.VMDisk{
+		{Name: "disk1", Size: diskSize, Path: "/dev/vdb", OpenstackVol: &volumes.Volume{ID: "vol-1"}},
+		{Name: "disk2", Size: diskSize, Path: "/dev/vdc", OpenstackVol: &volumes.Volume{ID: "vol-2", Encrypted: true}},
+	}}
+
+	tests := []struct {
+		name    string
+		expect  func(disk1, disk2 *nbd.MockNBDOperations)
+		want    []vjailbreakv1alpha1.DiskVerification
+		wantErr string
+	}{
+		{
+			name: "all disks match",
+			expect: func(disk1, disk2 *nbd.MockNBDOperations) {
+				disk1.EXPECT().VerifyDisk(gomock.Any(), "/dev/vdb", nbd.PlanVerifyRanges(diskSize, 100)).Return(nil, nil)
+				disk2.EXPECT().VerifyDisk(gomock.Any(), "/dev/vdc", nbd.PlanVerifyRanges(diskSize, 100)).Return(nil, nil)
+			},
+			want: []vjailbreakv1alpha1.DiskVerification{
+				{Disk: "disk1", Mode: "Full", BytesVerified: diskSize, Verified: true},
+				{Disk: "disk2", Mode: "Full", BytesVerified: diskSize, Verified: true},
+			},
+		},
+		{
+			name: "mismatch copied again",
+			expect: func(disk1, disk2 *nbd.MockNBDOperations) {
+				disk1.EXPECT().VerifyDisk(gomock.Any(), "/dev/vdb", gomock.Any()).Return(nil, nil)
+				gomock.InOrder(
+					disk2.EXPECT().VerifyDisk(gomock.Any(), "/dev/vdc", nbd.PlanVerifyRanges(diskSize, 100)).Return(bad, nil),
+					disk2.EXPECT().RepairDisk(gomock.Any(), "/dev/vdc", bad, true).Return(nil),
+					disk2.EXPECT().VerifyDisk(gomock.Any(), "/dev/vdc", bad).Return(nil, nil),
+				)
+			},
+			want: []vjailbreakv1alpha1.DiskVerification{
+				{Disk: "disk1", Mode: "Full", BytesVerified: diskSize, Verified: true},
+				{Disk: "disk2", Mode: "Full", BytesVerified: diskSize, Mismatches: 1,
+					MismatchedRanges: []string{"12582912+4194304"}, Repaired: 1, Verified: true},
+			},
+		},
+		{
+			name: "mismatch persists",
+			expect: func(disk1, disk2 *nbd.MockNBDOperations) {
+				disk1.EXPECT().VerifyDisk(gomock.Any(), "/dev/vdb", gomock.Any()).Return(bad, nil)
+				disk1.EXPECT().VerifyDisk(gomock.Any(), "/dev/vdb", bad).Return(bad, nil).Times(constants.VerifyRepairAttempts)
+				disk1.EXPECT().RepairDisk(gomock.Any(), "/dev/vdb", bad, false).Return(nil).Times(constants.VerifyRepairAttempts)
+				disk2.EXPECT().VerifyDisk(gomock.Any(), "/dev/vdc", gomock.Any()).Return(nil, nil)
+			},
+			want: []vjailbreakv1alpha1.DiskVerification{
+				{Disk: "disk1", Mode: "Full", BytesVerified: diskSize, Mismatches: 1,
+					MismatchedRanges: []string{"12582912+4194304"}},
+				{Disk: "disk2", Mode: "Full", BytesVerified: diskSize, Verified: true},
+			},
+			wantErr: "disks disk1 still differ from the source",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			builder, migrationName := checkpointTestClient(t)
+			k8sClient := builder.WithStatusSubresource(&vjailbreakv1alpha1.Migration{}).Build()
+
+			disk1 := nbd.NewMockNBDOperations(ctrl)
+			disk2 := nbd.NewMockNBDOperations(ctrl)
+			tt.expect(disk1, disk2)
+
+			migobj := &Migrate{K8sClient: k8sClient, VerifyMode: "Full"}
+			err := migobj.verifyDisks(context.Background(), vminfo, nbdVerifiers([]nbd.NBDOperations{disk1, disk2}))
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+
+			migration := &vjailbreakv1alpha1.Migration{}
+			require.NoError(t, k8sClient.Get(context.Background(), k8stypes.NamespacedName{
+				Name:      migrationName,
+				Namespace: constants.NamespaceMigrationSystem,
+			}, migration))
+			assert.Equal(t, tt.want, migration.Status.DiskVerification)
+		})
+	}
+}
+
+func TestVerifyDisks_Off(t *testing.T) {
+	migobj := &Migrate{}
+	assert.NoError(t, migobj.verifyDisks(context.Background(), vm.VMInfo{VMDisks: []vm.VMDisk{{Name: "disk1"}}}, nil))
+}
@@ -14,8 +14,11 @@ import (
 	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
 	"github.com/platform9/vjailbreak/pkg/common/constants"
 	"github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage"
+	hpesdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/hpalletra"
 	netappsdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/netapp"
+	powerstoresdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/powerstore"
 	_ "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/providers"
+	simulatorsdk "github.com/platform9/vjailbreak/pkg/vpwned/sdk/storage/simulator"
 	"github.com/platform9/vjailbreak/v2v-helper/openstack"
 	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
 	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
@@ -155,6 +158,19 @@ func (migobj *Migrate) DetachAllVolumes(ctx context.Context, vminfo vm.VMInfo) e
 	return nil
 }
 
+// vjailbreakInstanceUUID returns the server ID volumes are attached to while they
+// are copied. A KubeVirt destination copies to staged files instead and has none.
+func (migobj *Migrate) vjailbreakInstanceUUID() (string, error) {
+	if migobj.DestinationType == constants.DestinationTypeKubevirt {
+		return "", nil
+	}
+	vjailbreakUUID, err := openstack.GetCurrentInstanceUUID()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get vJailbreak instance UUID")
+	}
+	return vjailbreakUUID, nil
+}
+
 // DetachAllVolumesWithCleanup is like DetachAllVolumes but handles the case where
 // a volume is attached to a foreign server (e.g. an orphaned target VM created by
 // a timed-out CreateTargetInstance call). Boot volumes cannot be hot-detached from
@@ -163,9 +179,9 @@ func (migobj *Migrate) DetachAllVolumes(ctx context.Context, vminfo vm.VMInfo) e
 func (migobj *Migrate) DetachAllVolumesWithCleanup(ctx context.Context, vminfo vm.VMInfo) error {
 	openstackops := migobj.Openstackclients
 
-	vjailbreakUUID, err := openstack.GetCurrentInstanceUUID()
+	vjailbreakUUID, err := migobj.vjailbreakInstanceUUID()
 	if err != nil {
-		return errors.Wrap(err, "failed to get vJailbreak instance UUID")
+		return err
 	}
 
 	deletedServers := map[string]bool{}
@@ -218,9 +234,9 @@ func (migobj *Migrate) DetachAllVolumesWithCleanup(ctx context.Context, vminfo v
 // which also insists on ACTIVE - wrong for the LDM promotion, where the guest is
 // deliberately stopped first and an ACTIVE check would reject every promotion.
 func (migobj *Migrate) resolveTargetServerID(ctx context.Context, vminfo vm.VMInfo) (string, error) {
-	vjailbreakUUID, err := openstack.GetCurrentInstanceUUID()
+	vjailbreakUUID, err := migobj.vjailbreakInstanceUUID()
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get vJailbreak instance UUID")
+		return "", err
 	}
 
 	var bootDisk *vm.VMDisk
@@ -563,6 +579,26 @@ func (migobj *Migrate) buildProviderOptions() map[string]string {
 			opts[netappsdk.OptionFlexVol] = migobj.NetAppFlexVol
 		}
 	}
+	if migobj.VendorType == powerstoresdk.VendorName && migobj.PowerStoreAppliance != "" {
+		opts[powerstoresdk.OptionAppliance] = migobj.PowerStoreAppliance
+	}
+	if migobj.VendorType == hpesdk.VendorName && migobj.HPECPG != "" {
+		opts[hpesdk.OptionCPG] = migobj.HPECPG
+	}
+	if migobj.VendorType == simulatorsdk.VendorName {
+		if migobj.SimulatorStateFile != "" {
+			opts[simulatorsdk.OptionStateFile] = migobj.SimulatorStateFile
+		}
+		if migobj.SimulatorCreateDelay != "" {
+			opts[simulatorsdk.OptionCreateDelay] = migobj.SimulatorCreateDelay
+		}
+		if migobj.SimulatorFailMapping {
+			opts[simulatorsdk.OptionFailMapping] = "true"
+		}
+		if migobj.SimulatorNAACollision {
+			opts[simulatorsdk.OptionNAACollision] = "true"
+		}
+	}
 	if len(opts) == 0 {
 		return nil
 	}
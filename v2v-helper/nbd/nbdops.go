@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"os/exec"
@@ -32,18 +33,31 @@ type NBDOperations interface {
 	StartNBDServer(vm *object.VirtualMachine, server, username, password, thumbprint, snapref, file string, progchan chan string) error
 	StopNBDServer() error
 	CopyDisk(ctx context.Context, dest string, diskindex int, destEncrypted bool) error
+	CopyDiskFrom(ctx context.Context, dest string, diskindex int, offset, size int64, destEncrypted bool, checkpoint func(offset int64)) error
 	CopyChangedBlocks(ctx context.Context, changedAreas types.DiskChangeInfo, path string, destEncrypted bool) error
 	GetProgress() (int64, int64, time.Duration)
+	VerifyDisk(ctx context.Context, dest string, ranges []VerifyRange) ([]VerifyRange, error)
+	RepairDisk(ctx context.Context, dest string, ranges []VerifyRange, destEncrypted bool) error
+	TransportMode() string
 }
 
 type NBDServer struct {
 	cmd          *exec.Cmd
 	tmp_dir      string
 	progresschan chan string
-	TotalSize    int64
-	StartTime    time.Time
-	CopiedSize   int64
-	Duration     time.Duration
+	// progressMu guards the progress of the current copy, GetProgress is
+	// called while it runs
+	progressMu sync.Mutex
+	TotalSize  int64
+	StartTime  time.Time
+	CopiedSize int64
+	Duration   time.Duration
+	// Throttle caps the copy rate, it is shared by every disk of the migration
+	Throttle *Throttle
+	// Transport selects the VDDK transport modes and compression
+	Transport VDDKTransport
+	// transport is the transport mode VDDK used, read from the nbdkit output
+	transport *transportWatcher
 }
 
 type BlockStatusData struct {
@@ -56,6 +70,10 @@ type BlockStatusData struct {
 
 const MaxChunkSize = 64 * 1024 * 1024
 
+// CopySegmentSize is how much of a disk CopyDiskFrom copies between two
+// checkpoints, and so at most how much a resumed copy has to copy again.
+const CopySegmentSize = 16 << 30 // 16 GiB
+
 // MaxBlockStatusLength limits the maximum block status request size to 2GB
 const MaxBlockStatusLength = (2 << 30)
 
@@ -244,9 +262,10 @@ vixDiskLib.nfcAio.Session.BufCount=4`
 		fmt.Sprintf("user=%s", username),
 		fmt.Sprintf("password=%s", password),
 		fmt.Sprintf("thumbprint=%s", thumbprint),
-		"compression=fastlz",
+	)
+	cmd.Args = append(cmd.Args, nbdserver.Transport.pluginArgs()...)
+	cmd.Args = append(cmd.Args,
 		"config=/home/fedora/vddk.conf",
-		"transports=file:nbdssl:nbd",
 		fmt.Sprintf("vm=moref=%s", vm.Reference().Value),
 		fmt.Sprintf("snapshot=%s", snapref),
 		file,
@@ -263,6 +282,13 @@ vixDiskLib.nfcAio.Session.BufCount=4`
 	}
 
 	utils.AddDebugOutputToFileWithCommandCategory(cmd, cmdstring, utils.LogCategoryNBD, password)
+	// The vddk plugin logs the transport mode VDDK picked when it opens the disk
+	watcher := &transportWatcher{}
+	if cmd.Stderr != nil {
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, watcher)
+	} else {
+		cmd.Stderr = watcher
+	}
 
 	utils.PrintLog(fmt.Sprintf("Executing %s\n", cmdstring))
 	err = cmd.Start()
@@ -274,9 +300,19 @@ vixDiskLib.nfcAio.Session.BufCount=4`
 	nbdserver.cmd = cmd
 	nbdserver.tmp_dir = tmp_dir
 	nbdserver.progresschan = progchan
+	nbdserver.transport = watcher
 	return nil
 }
 
+// TransportMode returns the VDDK transport mode the running nbdkit uses, empty
+// until a copy has opened the disk
+func (nbdserver *NBDServer) TransportMode() string {
+	if nbdserver.transport == nil {
+		return ""
+	}
+	return nbdserver.transport.Mode()
+}
+
 func (nbdserver *NBDServer) StopNBDServer() error {
 	err := nbdserver.cmd.Process.Kill()
 	if err != nil {
@@ -309,20 +345,98 @@ func buildNbdcopyArgs(sockUrl, dest string, destEncrypted bool) []string {
 }
 
 func (nbdserver *NBDServer) CopyDisk(ctx context.Context, dest string, diskindex int, destEncrypted bool) error {
-	// Copy the disk from source to destination
-	progressRead, progressWrite, err := os.Pipe()
+	if destEncrypted {
+		utils.PrintLog(fmt.Sprintf(
+			"Disk %d destination volume is encrypted; disabling nbdcopy --target-is-zero and doing a full dense copy", diskindex))
+	}
+
+	// nbdcopy has no rate limit of its own, so it reads through an nbdkit
+	// rate filter when the migration is throttled
+	sockUrl, stopProxy, err := nbdserver.Throttle.Proxy(generateSockUrl(nbdserver.tmp_dir))
 	if err != nil {
-		return errors.Wrapf(err, "failed to create pipe")
+		return errors.Wrapf(err, "failed to throttle disk %d copy", diskindex)
 	}
-	defer progressRead.Close()
-	defer progressWrite.Close()
+	defer stopProxy()
 
+	// The size of dest is not known here, only the percentage is reported
+	nbdserver.startProgress(0, 0)
+	args := buildNbdcopyArgs(sockUrl, dest, destEncrypted)
+	return nbdserver.runNbdcopy(ctx, args, diskindex, func(progressInt int) int { return progressInt })
+}
+
+// buildSegmentNbdcopyArgs constructs the nbdcopy arguments copying the
+// length bytes at offset of the NBD source at sockUrl to the same offset of
+// dest. Both ends are nbdkit offset filters that nbdcopy runs itself, so each
+// segment is an ordinary whole-disk copy to nbdcopy. --target-is-zero follows
+// the same rule as buildNbdcopyArgs.
+func buildSegmentNbdcopyArgs(sockUrl, dest string, offset, length int64, destEncrypted bool) []string {
+	args := []string{"--progress=3"}
+	if !destEncrypted {
+		args = append(args, "--target-is-zero")
+	}
+	window := []string{fmt.Sprintf("offset=%d", offset), fmt.Sprintf("range=%d", length)}
+	args = append(args, "--", "[", "nbdkit", "--exit-with-parent", "--readonly", "--filter=offset", "nbd", fmt.Sprintf("uri=%s", sockUrl))
+	args = append(args, window...)
+	args = append(args, "]", "[", "nbdkit", "--exit-with-parent", "--filter=offset", "file", fmt.Sprintf("file=%s", dest))
+	args = append(args, window...)
+	return append(args, "]")
+}
+
+// CopyDiskFrom copies the disk of size bytes to dest like CopyDisk, but starts
+// at offset and goes in CopySegmentSize segments. After each segment
+// checkpoint is called with the offset up to which dest is complete, so a
+// copy interrupted by a pod restart can continue from there.
+//
+// --target-is-zero still holds for the segment a previous attempt was in the
+// middle of: the resumed copy either reads the same snapshot again, or reads
+// a newer one and then copies the CBT changes since the checkpointed change
+// ID, which rewrites every block of that segment that has changed.
+func (nbdserver *NBDServer) CopyDiskFrom(ctx context.Context, dest string, diskindex int, offset, size int64, destEncrypted bool, checkpoint func(offset int64)) error {
+	if offset >= size {
+		return nil
+	}
+	if offset > 0 {
+		utils.PrintLog(fmt.Sprintf("Resuming copy of disk %d at offset %d of %d", diskindex, offset, size))
+	}
 	if destEncrypted {
 		utils.PrintLog(fmt.Sprintf(
 			"Disk %d destination volume is encrypted; disabling nbdcopy --target-is-zero and doing a full dense copy", diskindex))
 	}
 
-	args := buildNbdcopyArgs(generateSockUrl(nbdserver.tmp_dir), dest, destEncrypted)
+	sockUrl, stopProxy, err := nbdserver.Throttle.Proxy(generateSockUrl(nbdserver.tmp_dir))
+	if err != nil {
+		return errors.Wrapf(err, "failed to throttle disk %d copy", diskindex)
+	}
+	defer stopProxy()
+
+	nbdserver.startProgress(offset, size)
+	for offset < size {
+		length := min(CopySegmentSize, size-offset)
+		segmentStart := offset
+		args := buildSegmentNbdcopyArgs(sockUrl, dest, segmentStart, length, destEncrypted)
+		err := nbdserver.runNbdcopy(ctx, args, diskindex, func(progressInt int) int {
+			return int((segmentStart + length*int64(progressInt)/100) * 100 / size)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to copy disk %d at offset %d", diskindex, segmentStart)
+		}
+		offset += length
+		checkpoint(offset)
+	}
+	return nil
+}
+
+// runNbdcopy runs nbdcopy with args and reports its progress for disk
+// diskindex. toDiskPercent maps the progress of this nbdcopy run to the
+// progress of the whole disk.
+func (nbdserver *NBDServer) runNbdcopy(ctx context.Context, args []string, diskindex int, toDiskPercent func(progressInt int) int) error {
+	progressRead, progressWrite, err := os.Pipe()
+	if err != nil {
+		return errors.Wrapf(err, "failed to create pipe")
+	}
+	defer progressRead.Close()
+	defer progressWrite.Close()
+
 	cmd := exec.CommandContext(ctx, "nbdcopy", args...)
 	cmd.ExtraFiles = []*os.File{progressWrite}
 
@@ -341,6 +455,8 @@ func (nbdserver *NBDServer) CopyDisk(ctx context.Context, dest string, diskindex
 				utils.PrintLog(fmt.Sprintf("Error converting progress percent to int: %v", err))
 				continue
 			}
+			progressInt = toDiskPercent(progressInt)
+			nbdserver.setCopiedPercent(progressInt)
 			msg := fmt.Sprintf("Copying disk %d, Completed: %d%%", diskindex, progressInt)
 
 			if (progressInt == 0 && lastLoggedProgress != 0) || progressInt == 100 || (progressInt > lastLoggedProgress && progressInt%logInterval == 0) {
@@ -562,7 +678,7 @@ func zeroRange(fd *os.File, offset int64, length int64, destEncrypted bool) erro
 	return writeZeros(fd, offset, length)
 }
 
-func copyRange(fd *os.File, handle *libnbd.Libnbd, block *BlockStatusData, destEncrypted bool) error {
+func copyRange(ctx context.Context, fd *os.File, handle *libnbd.Libnbd, throttle *Throttle, block *BlockStatusData, destEncrypted bool) error {
 	isZeroOrHole := (block.Flags & (libnbd.STATE_ZERO | libnbd.STATE_HOLE)) != 0
 
 	if isZeroOrHole {
@@ -582,6 +698,9 @@ func copyRange(fd *os.File, handle *libnbd.Libnbd, block *BlockStatusData, destE
 		length := len(buffer)
 
 		offset := block.Offset + count
+		if err := throttle.Wait(ctx, length); err != nil {
+			return fmt.Errorf("throttled read at offset %d: %v", offset, err)
+		}
 		err := handle.Pread(buffer, uint64(offset), nil)
 		if err != nil {
 			return fmt.Errorf("error reading from source at offset %d: %v", offset, err)
@@ -603,8 +722,9 @@ func copyRange(fd *os.File, handle *libnbd.Libnbd, block *BlockStatusData, destE
 // into sub-ranges and dispatches them across the handle pool so that multiple
 // Preads run in flight concurrently — which is the only way to exceed
 // single-stream VDDK throughput. Smaller blocks take a single handle from the
-// pool and run copyRange as before.
-func copyBlockParallel(ctx context.Context, fd *os.File, pool *handlePool, block *BlockStatusData, destEncrypted bool) error {
+// pool and run copyRange as before. Only data reads count against the
+// throttle, zeroing never touches the source.
+func copyBlockParallel(ctx context.Context, fd *os.File, pool *handlePool, throttle *Throttle, block *BlockStatusData, destEncrypted bool) error {
 	isZeroOrHole := (block.Flags & (libnbd.STATE_ZERO | libnbd.STATE_HOLE)) != 0
 
 	if isZeroOrHole {
@@ -619,7 +739,7 @@ func copyBlockParallel(ctx context.Context, fd *os.File, pool *handlePool, block
 			return fmt.Errorf("acquire handle for small block at offset %d: %v", block.Offset, err)
 		}
 		defer pool.Release(handle)
-		return copyRange(fd, handle, block, destEncrypted)
+		return copyRange(ctx, fd, handle, throttle, block, destEncrypted)
 	}
 
 	// Plan sub-ranges. Each sub-range is treated as a fresh data block so
@@ -651,7 +771,7 @@ func copyBlockParallel(ctx context.Context, fd *os.File, pool *handlePool, block
 				return
 			}
 			defer pool.Release(handle)
-			if err := copyRange(fd, handle, subRange, destEncrypted); err != nil {
+			if err := copyRange(ctx, fd, handle, throttle, subRange, destEncrypted); err != nil {
 				errCh <- fmt.Errorf("sub-range %d at offset %d failed: %v", subRangeIdx, subRange.Offset, err)
 				return
 			}
@@ -665,10 +785,41 @@ func copyBlockParallel(ctx context.Context, fd *os.File, pool *handlePool, block
 	return nil
 }
 
+// GetProgress returns the bytes copied and the total bytes of the current
+// copy, and how long it has run. The total is 0 while it is not known.
 func (nbdserver *NBDServer) GetProgress() (int64, int64, time.Duration) {
+	nbdserver.progressMu.Lock()
+	defer nbdserver.progressMu.Unlock()
 	return nbdserver.CopiedSize, nbdserver.TotalSize, nbdserver.Duration
 }
 
+// startProgress starts the progress of a copy of total bytes, copied of which
+// are already done
+func (nbdserver *NBDServer) startProgress(copied, total int64) {
+	nbdserver.progressMu.Lock()
+	defer nbdserver.progressMu.Unlock()
+	nbdserver.StartTime = time.Now()
+	nbdserver.TotalSize = total
+	nbdserver.CopiedSize = copied
+	nbdserver.Duration = 0
+}
+
+func (nbdserver *NBDServer) setCopied(copied int64) {
+	nbdserver.progressMu.Lock()
+	defer nbdserver.progressMu.Unlock()
+	nbdserver.CopiedSize = copied
+	nbdserver.Duration = time.Since(nbdserver.StartTime)
+}
+
+// setCopiedPercent sets the progress from nbdcopy, which reports whole percents
+// of the total
+func (nbdserver *NBDServer) setCopiedPercent(percent int) {
+	nbdserver.progressMu.Lock()
+	defer nbdserver.progressMu.Unlock()
+	nbdserver.CopiedSize = max(nbdserver.CopiedSize, nbdserver.TotalSize*int64(percent)/100)
+	nbdserver.Duration = time.Since(nbdserver.StartTime)
+}
+
 func (nbdserver *NBDServer) CopyChangedBlocks(ctx context.Context, changedAreas types.DiskChangeInfo, path string, destEncrypted bool) error {
 	// Coalesce CBT-reported extents to amortize per-extent overhead. Holes
 	// inside a coalesced range will be discovered by getBlockStatus and
@@ -720,13 +871,10 @@ func (nbdserver *NBDServer) CopyChangedBlocks(ctx context.Context, changedAreas
 		copiedsize := int64(0)
 		lastLoggedPct := -1
 		const logInterval = 5
-		startTime := time.Now()
-		nbdserver.StartTime = startTime
-		nbdserver.TotalSize = totalsize
+		nbdserver.startProgress(0, totalsize)
 		for progress := range incrementalcopyprogress {
 			copiedsize += progress
-			nbdserver.CopiedSize = copiedsize
-			nbdserver.Duration = time.Since(startTime)
+			nbdserver.setCopied(copiedsize)
 
 			currentPct := 0
 			if totalsize > 0 {
@@ -794,7 +942,7 @@ func (nbdserver *NBDServer) CopyChangedBlocks(ctx context.Context, changedAreas
 				retries := uint64(0)
 				waitTime := 1 * time.Minute
 				for blockIdx := 0; blockIdx < len(blocks); {
-					if err := copyBlockParallel(copyCtx, fd, pool, blocks[blockIdx], destEncrypted); err != nil {
+					if err := copyBlockParallel(copyCtx, fd, pool, nbdserver.Throttle, blocks[blockIdx], destEncrypted); err != nil {
 						// If we were cancelled (peer worker errored), don't
 						// log a confusing failure or burn retries — just exit.
 						if copyCtx.Err() != nil {
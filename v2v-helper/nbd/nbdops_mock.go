@@ -65,6 +65,20 @@ func (mr *MockNBDOperationsMockRecorder) CopyDisk(ctx, dest, diskindex, destEncr
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyDisk", reflect.TypeOf((*MockNBDOperations)(nil).CopyDisk), ctx, dest, diskindex, destEncrypted)
 }
 
+// CopyDiskFrom mocks base method.
+func (m *MockNBDOperations) CopyDiskFrom(ctx context.Context, dest string, diskindex int, offset, size int64, destEncrypted bool, checkpoint func(int64)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyDiskFrom", ctx, dest, diskindex, offset, size, destEncrypted, checkpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CopyDiskFrom indicates an expected call of CopyDiskFrom.
+func (mr *MockNBDOperationsMockRecorder) CopyDiskFrom(ctx, dest, diskindex, offset, size, destEncrypted, checkpoint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyDiskFrom", reflect.TypeOf((*MockNBDOperations)(nil).CopyDiskFrom), ctx, dest, diskindex, offset, size, destEncrypted, checkpoint)
+}
+
 // GetProgress mocks base method.
 func (m *MockNBDOperations) GetProgress() (int64, int64, time.Duration) {
 	m.ctrl.T.Helper()
@@ -81,6 +95,20 @@ func (mr *MockNBDOperationsMockRecorder) GetProgress() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProgress", reflect.TypeOf((*MockNBDOperations)(nil).GetProgress))
 }
 
+// RepairDisk mocks base method.
+func (m *MockNBDOperations) RepairDisk(ctx context.Context, dest string, ranges []VerifyRange, destEncrypted bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepairDisk", ctx, dest, ranges, destEncrypted)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RepairDisk indicates an expected call of RepairDisk.
+func (mr *MockNBDOperationsMockRecorder) RepairDisk(ctx, dest, ranges, destEncrypted interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepairDisk", reflect.TypeOf((*MockNBDOperations)(nil).RepairDisk), ctx, dest, ranges, destEncrypted)
+}
+
 // StartNBDServer mocks base method.
 func (m *MockNBDOperations) StartNBDServer(vm *object.VirtualMachine, server, username, password, thumbprint, snapref, file string, progchan chan string) error {
 	m.ctrl.T.Helper()
@@ -108,3 +136,32 @@ func (mr *MockNBDOperationsMockRecorder) StopNBDServer() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopNBDServer", reflect.TypeOf((*MockNBDOperations)(nil).StopNBDServer))
 }
+
+// TransportMode mocks base method.
+func (m *MockNBDOperations) TransportMode() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransportMode")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// TransportMode indicates an expected call of TransportMode.
+func (mr *MockNBDOperationsMockRecorder) TransportMode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransportMode", reflect.TypeOf((*MockNBDOperations)(nil).TransportMode))
+}
+
+// VerifyDisk mocks base method.
+func (m *MockNBDOperations) VerifyDisk(ctx context.Context, dest string, ranges []VerifyRange) ([]VerifyRange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyDisk", ctx, dest, ranges)
+	ret0, _ := ret[0].([]VerifyRange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyDisk indicates an expected call of VerifyDisk.
+func (mr *MockNBDOperationsMockRecorder) VerifyDisk(ctx, dest, ranges interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyDisk", reflect.TypeOf((*MockNBDOperations)(nil).VerifyDisk), ctx, dest, ranges)
+}
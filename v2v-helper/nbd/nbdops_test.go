@@ -4,6 +4,7 @@ package nbd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -214,3 +215,32 @@ func TestZeroRange_ReadsBackAsZero(t *testing.T) {
 		})
 	}
 }
+
+// TestBuildSegmentNbdcopyArgs checks that a CopyDiskFrom segment reads and
+// writes the same window of the disk and keeps the --target-is-zero rule of
+// buildNbdcopyArgs.
+func TestBuildSegmentNbdcopyArgs(t *testing.T) {
+	const sockUrl = "nbd+unix:///?socket=/tmp/nbdkit-test/nbdkit.sock"
+	const dest = "/dev/sda"
+
+	args := buildSegmentNbdcopyArgs(sockUrl, dest, CopySegmentSize, 1<<30, false)
+	assert.Equal(t, []string{
+		"--progress=3", "--target-is-zero", "--",
+		"[", "nbdkit", "--exit-with-parent", "--readonly", "--filter=offset", "nbd", "uri=" + sockUrl, "offset=17179869184", "range=1073741824", "]",
+		"[", "nbdkit", "--exit-with-parent", "--filter=offset", "file", "file=" + dest, "offset=17179869184", "range=1073741824", "]",
+	}, args)
+
+	encryptedArgs := buildSegmentNbdcopyArgs(sockUrl, dest, 0, CopySegmentSize, true)
+	assert.NotContains(t, encryptedArgs, "--target-is-zero",
+		"encrypted destinations must not use --target-is-zero")
+}
+
+// TestCopyDiskFrom_Done checks that a disk whose checkpoint is already at its
+// end is not copied again.
+func TestCopyDiskFrom_Done(t *testing.T) {
+	nbdserver := &NBDServer{}
+	called := false
+	err := nbdserver.CopyDiskFrom(context.Background(), "/dev/sda", 0, 1<<30, 1<<30, false, func(int64) { called = true })
+	require.NoError(t, err)
+	assert.False(t, called)
+}
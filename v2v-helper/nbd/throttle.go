@@ -0,0 +1,193 @@
+// Copyright © 2025 The vjailbreak authors
+
+package nbd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/platform9/vjailbreak/pkg/common/bandwidth"
+	"github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
+	"golang.org/x/time/rate"
+)
+
+// ThrottleBurst is the most bytes a Throttle lets through at once. It is
+// larger than any single Pread so one read never has to be split.
+const ThrottleBurst = MaxChunkSize
+
+// throttleProxyStartTimeout bounds the wait for a rate filter proxy to listen
+const throttleProxyStartTimeout = 30 * time.Second
+
+// Throttle caps the disk copy rate of a migration. One Throttle is shared by
+// every disk of the migration: the Go copy loops wait on its token bucket, and
+// nbdcopy reads through an nbdkit rate filter proxy (see Proxy) whose rate file
+// is kept in step with it. A nil *Throttle does not limit anything.
+type Throttle struct {
+	limiter *rate.Limiter
+
+	mu       sync.Mutex
+	rateMbps int
+	// rateFiles are the rate files of the running proxies, each gets an equal
+	// share of rateMbps
+	rateFiles map[string]struct{}
+}
+
+// NewThrottle returns an unlimited Throttle, use SetRate to cap it.
+func NewThrottle() *Throttle {
+	return &Throttle{
+		limiter:   rate.NewLimiter(rate.Inf, ThrottleBurst),
+		rateFiles: map[string]struct{}{},
+	}
+}
+
+// SetRate changes the cap to rateMbps, 0 is unlimited. Copies in flight pick
+// up the new rate on their next read.
+func (throttle *Throttle) SetRate(rateMbps int) {
+	if throttle == nil {
+		return
+	}
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+	if rateMbps < 0 {
+		rateMbps = 0
+	}
+	if rateMbps == throttle.rateMbps {
+		return
+	}
+	if rateMbps == 0 {
+		utils.PrintLog("Disk copy bandwidth is now unlimited")
+		throttle.limiter.SetLimit(rate.Inf)
+	} else {
+		utils.PrintLog(fmt.Sprintf("Disk copy bandwidth is now capped at %d Mbps", rateMbps))
+		throttle.limiter.SetLimit(rate.Limit(bandwidth.BytesPerSecond(rateMbps)))
+	}
+	throttle.rateMbps = rateMbps
+	throttle.writeRateFilesLocked()
+}
+
+// RateMbps returns the current cap, 0 is unlimited.
+func (throttle *Throttle) RateMbps() int {
+	if throttle == nil {
+		return 0
+	}
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+	return throttle.rateMbps
+}
+
+// Wait blocks until n more bytes may be copied or ctx is done.
+func (throttle *Throttle) Wait(ctx context.Context, n int) error {
+	if throttle == nil {
+		return nil
+	}
+	for n > 0 {
+		chunk := min(n, ThrottleBurst)
+		if err := throttle.limiter.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// writeRateFilesLocked splits the rate evenly across the running proxies. The
+// nbdkit rate filter re-reads its rate file about once a second and treats a
+// rate of 0 as unlimited. Caller must hold throttle.mu.
+func (throttle *Throttle) writeRateFilesLocked() {
+	if len(throttle.rateFiles) == 0 {
+		return
+	}
+	bitsPerSecond := bandwidth.BytesPerSecond(throttle.rateMbps) * 8 / int64(len(throttle.rateFiles))
+	if throttle.rateMbps > 0 && bitsPerSecond == 0 {
+		bitsPerSecond = 1
+	}
+	for rateFile := range throttle.rateFiles {
+		if err := os.WriteFile(rateFile, []byte(strconv.FormatInt(bitsPerSecond, 10)+"\n"), 0644); err != nil {
+			utils.PrintLog(fmt.Sprintf("Failed to update nbdkit rate file %s: %v", rateFile, err))
+		}
+	}
+}
+
+// Proxy starts an nbdkit rate filter in front of the NBD source at uri and
+// returns the URI nbdcopy should read from instead. stop must be called once
+// the copy is done. A nil Throttle returns uri unchanged.
+func (throttle *Throttle) Proxy(uri string) (string, func(), error) {
+	if throttle == nil {
+		return uri, func() {}, nil
+	}
+	tmpDir, err := os.MkdirTemp("", "nbdkit-rate-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	socket := fmt.Sprintf("%s/nbdkit.sock", tmpDir)
+	pidFile := fmt.Sprintf("%s/nbdkit.pid", tmpDir)
+	rateFile := fmt.Sprintf("%s/rate", tmpDir)
+
+	throttle.mu.Lock()
+	throttle.rateFiles[rateFile] = struct{}{}
+	throttle.writeRateFilesLocked()
+	throttle.mu.Unlock()
+
+	unregister := func() {
+		throttle.mu.Lock()
+		delete(throttle.rateFiles, rateFile)
+		throttle.writeRateFilesLocked()
+		throttle.mu.Unlock()
+		os.RemoveAll(tmpDir)
+	}
+
+	cmd := exec.Command(
+		"nbdkit",
+		"--exit-with-parent",
+		"--readonly",
+		"--foreground",
+		fmt.Sprintf("--unix=%s", socket),
+		fmt.Sprintf("--pidfile=%s", pidFile),
+		"--filter=rate",
+		"nbd",
+		fmt.Sprintf("uri=%s", uri),
+		fmt.Sprintf("rate-file=%s", rateFile),
+	)
+	cmdString := cmd.String()
+	utils.AddDebugOutputToFileWithCommandCategory(cmd, cmdString, utils.LogCategoryNBD)
+	utils.PrintLog(fmt.Sprintf("Executing %s\n", cmdString))
+	if err := cmd.Start(); err != nil {
+		utils.CloseLogFile(cmd)
+		unregister()
+		return "", nil, fmt.Errorf("failed to start nbdkit rate filter: %v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		utils.CloseLogFile(cmd)
+		close(exited)
+	}()
+	stop := func() {
+		_ = cmd.Process.Kill()
+		<-exited
+		unregister()
+	}
+
+	// nbdkit writes the pidfile once it is listening on the socket
+	deadline := time.Now().Add(throttleProxyStartTimeout)
+	for {
+		if _, err := os.Stat(pidFile); err == nil {
+			return generateSockUrl(tmpDir), stop, nil
+		}
+		select {
+		case <-exited:
+			unregister()
+			return "", nil, fmt.Errorf("nbdkit rate filter for %s exited before it was ready", uri)
+		case <-time.After(100 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			stop()
+			return "", nil, fmt.Errorf("timed out waiting for nbdkit rate filter for %s", uri)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright © 2025 The vjailbreak authors
+
+package nbd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestThrottle_NilIsUnlimited checks that code paths holding a nil Throttle,
+// which is every migration without a bandwidth cap, copy without waiting.
+func TestThrottle_NilIsUnlimited(t *testing.T) {
+	var throttle *Throttle
+	throttle.SetRate(100)
+	assert.Equal(t, 0, throttle.RateMbps())
+	assert.NoError(t, throttle.Wait(context.Background(), 10*ThrottleBurst))
+
+	uri, stop, err := throttle.Proxy("nbd://proxy:10809")
+	require.NoError(t, err)
+	stop()
+	assert.Equal(t, "nbd://proxy:10809", uri)
+}
+
+// TestThrottle_Wait checks that a capped throttle actually delays reads past
+// the burst and gives up when the copy is cancelled.
+func TestThrottle_Wait(t *testing.T) {
+	throttle := NewThrottle()
+	// unlimited: more than a burst goes through at once
+	start := time.Now()
+	require.NoError(t, throttle.Wait(context.Background(), 3*ThrottleBurst))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// 8 Mbps is 1 MB/s, draining the initial burst leaves nothing for the next read
+	throttle.SetRate(8)
+	assert.Equal(t, 8, throttle.RateMbps())
+	require.NoError(t, throttle.Wait(context.Background(), ThrottleBurst))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Error(t, throttle.Wait(ctx, 1<<20))
+
+	throttle.SetRate(0)
+	assert.NoError(t, throttle.Wait(context.Background(), ThrottleBurst))
+}
+
+// TestThrottle_RateFiles checks that the rate is split evenly across the
+// nbdkit rate filter proxies of the migration and written in bits per second.
+func TestThrottle_RateFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+
+	throttle := NewThrottle()
+	throttle.rateFiles[first] = struct{}{}
+	throttle.rateFiles[second] = struct{}{}
+
+	readRate := func(path string) string {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return strings.TrimSpace(string(content))
+	}
+
+	throttle.SetRate(200)
+	assert.Equal(t, "100000000", readRate(first))
+	assert.Equal(t, "100000000", readRate(second))
+
+	throttle.mu.Lock()
+	delete(throttle.rateFiles, second)
+	throttle.writeRateFilesLocked()
+	throttle.mu.Unlock()
+	assert.Equal(t, "200000000", readRate(first))
+
+	throttle.SetRate(0)
+	assert.Equal(t, "0", readRate(first))
+}
@@ -0,0 +1,82 @@
+// Copyright © 2025 The vjailbreak authors
+
+package nbd
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultVDDKTransports are the VDDK transport modes tried in order when none
+// are configured
+var DefaultVDDKTransports = []string{"file", "nbdssl", "nbd"}
+
+// DefaultVDDKCompression is the NBD compression used when none is configured
+const DefaultVDDKCompression = "fastlz"
+
+// VDDKTransport selects how the nbdkit vddk plugin reads the disk from ESXi
+type VDDKTransport struct {
+	// Modes are the transport modes VDDK tries in order, the first that works is
+	// used. Empty means DefaultVDDKTransports.
+	Modes []string
+	// Compression is the compression of the NBD modes, empty means
+	// DefaultVDDKCompression
+	Compression string
+}
+
+// ModeList returns the transport modes VDDK tries, in order
+func (t VDDKTransport) ModeList() []string {
+	if len(t.Modes) == 0 {
+		return DefaultVDDKTransports
+	}
+	return t.Modes
+}
+
+// pluginArgs returns the nbdkit vddk plugin parameters of the transport
+func (t VDDKTransport) pluginArgs() []string {
+	compression := t.Compression
+	if compression == "" {
+		compression = DefaultVDDKCompression
+	}
+	return []string{
+		"compression=" + compression,
+		"transports=" + strings.Join(t.ModeList(), ":"),
+	}
+}
+
+// transportModeRe matches the debug message the vddk plugin logs when it opens
+// the disk with the transport VDDK picked
+var transportModeRe = regexp.MustCompile(`transport mode: (\w+)`)
+
+// transportWatcher reads the output of nbdkit for the transport mode VDDK used
+type transportWatcher struct {
+	mu   sync.Mutex
+	line []byte
+	mode string
+}
+
+func (w *transportWatcher) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.line = append(w.line, p...)
+	for {
+		idx := bytes.IndexByte(w.line, '\n')
+		if idx < 0 {
+			break
+		}
+		if m := transportModeRe.FindSubmatch(w.line[:idx]); m != nil {
+			w.mode = string(m[1])
+		}
+		w.line = w.line[idx+1:]
+	}
+	return len(p), nil
+}
+
+// Mode returns the transport mode VDDK used, empty until the disk is opened
+func (w *transportWatcher) Mode() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.mode
+}
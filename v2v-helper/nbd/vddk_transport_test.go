@@ -0,0 +1,33 @@
+// Copyright © 2025 The vjailbreak authors
+
+package nbd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVDDKTransportPluginArgs(t *testing.T) {
+	assert.Equal(t, []string{"compression=fastlz", "transports=file:nbdssl:nbd"}, VDDKTransport{}.pluginArgs(),
+		"the defaults keep the previous fixed configuration")
+	assert.Equal(t, []string{"compression=none", "transports=san:hotadd:nbdssl"},
+		VDDKTransport{Modes: []string{"san", "hotadd", "nbdssl"}, Compression: "none"}.pluginArgs())
+}
+
+func TestTransportWatcher(t *testing.T) {
+	w := &transportWatcher{}
+	assert.Empty(t, w.Mode())
+
+	// Messages can arrive split across writes
+	fmt.Fprint(w, "nbdkit: vddk[1]: debug: VixDiskLib_Open (...)\nnbdkit: vddk[1]: debug: transport mo")
+	assert.Empty(t, w.Mode(), "an incomplete line is not matched")
+	fmt.Fprint(w, "de: nbdssl\nnbdkit: vddk[1]: debug: ")
+	assert.Equal(t, "nbdssl", w.Mode())
+
+	fmt.Fprint(w, "transport mode: san\n")
+	assert.Equal(t, "san", w.Mode(), "the last open wins")
+
+	assert.Empty(t, (&NBDServer{}).TransportMode(), "no mode before the server is started")
+}
@@ -0,0 +1,179 @@
+// Copyright © 2025 The vjailbreak authors
+
+package nbd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+	"libguestfs.org/libnbd"
+)
+
+// VerifyBlockSize is the size of the ranges compared by an integrity check, and
+// so the granularity at which mismatches are reported and recopied
+const VerifyBlockSize = 4 << 20 // 4 MiB
+
+// VerifyRange is a byte range of a disk compared by an integrity check
+type VerifyRange struct {
+	Offset int64
+	Length int64
+}
+
+func (r VerifyRange) String() string {
+	return fmt.Sprintf("%d+%d", r.Offset, r.Length)
+}
+
+// PlanVerifyRanges returns the ranges of a disk of size bytes an integrity check
+// compares. samplePercent of the VerifyBlockSize blocks are picked evenly across
+// the disk, always including the first and the last one, 100 checks every block.
+func PlanVerifyRanges(size int64, samplePercent int) []VerifyRange {
+	if size <= 0 {
+		return nil
+	}
+	blocks := (size + VerifyBlockSize - 1) / VerifyBlockSize
+	sampled := blocks
+	if samplePercent < 100 {
+		sampled = max(1, blocks*int64(samplePercent)/100)
+	}
+
+	ranges := make([]VerifyRange, 0, sampled)
+	for i := int64(0); i < sampled; i++ {
+		block := int64(0)
+		if sampled > 1 {
+			block = i * (blocks - 1) / (sampled - 1)
+		}
+		offset := block * VerifyBlockSize
+		ranges = append(ranges, VerifyRange{Offset: offset, Length: min(VerifyBlockSize, size-offset)})
+	}
+	return ranges
+}
+
+// Source is an NBD export a copied disk is verified against and repaired from
+type Source struct {
+	URI string
+	// Throttle caps the reads from the source like those of the copy
+	Throttle *Throttle
+}
+
+// VerifyDisk hashes every range of the source and of dest and returns the
+// ranges whose hashes differ. The cached pages of dest are dropped first so
+// that what is compared is what reached the device.
+func (source Source) VerifyDisk(ctx context.Context, dest string, ranges []VerifyRange) ([]VerifyRange, error) {
+	handle, err := connectSource(source.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	fd, err := os.Open(dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", dest, err)
+	}
+	defer fd.Close()
+	if err := fd.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync %s: %v", dest, err)
+	}
+	if err := unix.Fadvise(int(fd.Fd()), 0, 0, unix.FADV_DONTNEED); err != nil {
+		return nil, fmt.Errorf("failed to drop cached pages of %s: %v", dest, err)
+	}
+
+	var mismatches []VerifyRange
+	for _, r := range ranges {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		sourceHash, err := hashSourceRange(ctx, handle, source.Throttle, r)
+		if err != nil {
+			return nil, err
+		}
+		destHash, err := hashFileRange(fd, r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at offset %d: %v", dest, r.Offset, err)
+		}
+		if !bytes.Equal(sourceHash, destHash) {
+			mismatches = append(mismatches, r)
+		}
+	}
+	return mismatches, nil
+}
+
+// RepairDisk copies the ranges from the source to dest again
+func (source Source) RepairDisk(ctx context.Context, dest string, ranges []VerifyRange, destEncrypted bool) error {
+	handle, err := connectSource(source.URI)
+	if err != nil {
+		return err
+	}
+	defer handle.Close()
+
+	fd, err := os.OpenFile(dest, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", dest, err)
+	}
+	defer fd.Close()
+	for _, r := range ranges {
+		// Copied as data even if the source reports a hole, reading it back
+		// is what the check compares with
+		block := &BlockStatusData{Offset: r.Offset, Length: r.Length}
+		if err := copyRange(ctx, fd, handle, source.Throttle, block, destEncrypted); err != nil {
+			return err
+		}
+	}
+	return fd.Sync()
+}
+
+// VerifyDisk compares ranges of dest with the snapshot the server exports
+func (nbdserver *NBDServer) VerifyDisk(ctx context.Context, dest string, ranges []VerifyRange) ([]VerifyRange, error) {
+	return nbdserver.source().VerifyDisk(ctx, dest, ranges)
+}
+
+// RepairDisk recopies ranges of dest from the snapshot the server exports
+func (nbdserver *NBDServer) RepairDisk(ctx context.Context, dest string, ranges []VerifyRange, destEncrypted bool) error {
+	return nbdserver.source().RepairDisk(ctx, dest, ranges, destEncrypted)
+}
+
+func (nbdserver *NBDServer) source() Source {
+	return Source{URI: generateSockUrl(nbdserver.tmp_dir), Throttle: nbdserver.Throttle}
+}
+
+func connectSource(uri string) (*libnbd.Libnbd, error) {
+	handle, err := libnbd.Create()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create libnbd handle: %v", err)
+	}
+	if err := handle.ConnectUri(uri); err != nil {
+		handle.Close()
+		return nil, fmt.Errorf("failed to connect to %s: %v", uri, err)
+	}
+	return handle, nil
+}
+
+func hashSourceRange(ctx context.Context, handle *libnbd.Libnbd, throttle *Throttle, r VerifyRange) ([]byte, error) {
+	hash := sha256.New()
+	buffer := make([]byte, MaxPreadLength)
+	for count := int64(0); count < r.Length; {
+		length := min(int64(len(buffer)), r.Length-count)
+		offset := r.Offset + count
+		if err := throttle.Wait(ctx, int(length)); err != nil {
+			return nil, fmt.Errorf("throttled read at offset %d: %v", offset, err)
+		}
+		if err := handle.Pread(buffer[:length], uint64(offset), nil); err != nil {
+			return nil, fmt.Errorf("error reading from source at offset %d: %v", offset, err)
+		}
+		hash.Write(buffer[:length])
+		count += length
+	}
+	return hash.Sum(nil), nil
+}
+
+func hashFileRange(fd *os.File, r VerifyRange) ([]byte, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(fd, r.Offset, r.Length)); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
@@ -0,0 +1,65 @@
+// Copyright © 2025 The vjailbreak authors
+
+package nbd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanVerifyRanges(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          int64
+		samplePercent int
+		want          []VerifyRange
+	}{
+		{name: "empty disk", size: 0, samplePercent: 100, want: nil},
+		{name: "full check of a partial last block", size: 2*VerifyBlockSize + 512, samplePercent: 100, want: []VerifyRange{
+			{Offset: 0, Length: VerifyBlockSize},
+			{Offset: VerifyBlockSize, Length: VerifyBlockSize},
+			{Offset: 2 * VerifyBlockSize, Length: 512},
+		}},
+		{name: "sample keeps the first and last block", size: 100 * VerifyBlockSize, samplePercent: 3, want: []VerifyRange{
+			{Offset: 0, Length: VerifyBlockSize},
+			{Offset: 49 * VerifyBlockSize, Length: VerifyBlockSize},
+			{Offset: 99 * VerifyBlockSize, Length: VerifyBlockSize},
+		}},
+		{name: "small disk still gets one sample", size: 10 * VerifyBlockSize, samplePercent: 1, want: []VerifyRange{
+			{Offset: 0, Length: VerifyBlockSize},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PlanVerifyRanges(tt.size, tt.samplePercent))
+		})
+	}
+}
+
+func TestHashFileRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disk")
+	data := bytes.Repeat([]byte("vjailbreak"), 1000)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	fd, err := os.Open(path)
+	require.NoError(t, err)
+	defer fd.Close()
+
+	first, err := hashFileRange(fd, VerifyRange{Offset: 0, Length: 100})
+	require.NoError(t, err)
+	same, err := hashFileRange(fd, VerifyRange{Offset: 1000, Length: 100})
+	require.NoError(t, err)
+	shifted, err := hashFileRange(fd, VerifyRange{Offset: 1, Length: 100})
+	require.NoError(t, err)
+	assert.Equal(t, first, same, "identical content hashes the same wherever it is")
+	assert.NotEqual(t, first, shifted)
+
+	// A range past the end of a short destination never matches a full source range
+	short, err := hashFileRange(fd, VerifyRange{Offset: int64(len(data)) - 50, Length: 100})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, short)
+}
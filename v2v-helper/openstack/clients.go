@@ -34,6 +34,7 @@ import (
 	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/servergroups"
 	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/servers"
 	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/volumeattach"
+	"github.com/gophercloud/gophercloud/v2/openstack/image/v2/images"
 	"github.com/gophercloud/gophercloud/v2/openstack/networking/v2/extensions/portsbinding"
 	"github.com/gophercloud/gophercloud/v2/openstack/networking/v2/extensions/portsecurity"
 	"github.com/gophercloud/gophercloud/v2/openstack/networking/v2/networks"
@@ -45,8 +46,10 @@ type OpenStackClients struct {
 	BlockStorageClient *gophercloud.ServiceClient
 	ComputeClient      *gophercloud.ServiceClient
 	NetworkingClient   *gophercloud.ServiceClient
-	K8sClient          client.Client
-	AuthURL, Tenant    string
+	// ImageClient is nil when the cloud has no image service endpoint
+	ImageClient     *gophercloud.ServiceClient
+	K8sClient       client.Client
+	AuthURL, Tenant string
 }
 
 type OpenStackMetadata struct {
@@ -1475,3 +1478,98 @@ func (osclient *OpenStackClients) GetCinderVolumeServices(ctx context.Context) (
 
 	return result, nil
 }
+
+// UploadVolumeToImage asks Cinder to upload a volume to a new Glance image. Cinder
+// copies the volume image metadata, such as hw_firmware_type, onto the image.
+func (osclient *OpenStackClients) UploadVolumeToImage(ctx context.Context, volumeID, imageName, diskFormat, visibility string) (string, error) {
+	pkgutils.PrintLog(fmt.Sprintf("OPENSTACK API: Uploading volume %s to image %s as %s, authurl %s, tenant %s",
+		volumeID, imageName, diskFormat, osclient.AuthURL, osclient.Tenant))
+	opts := volumes.UploadImageOpts{
+		ImageName:       imageName,
+		ContainerFormat: "bare",
+		DiskFormat:      diskFormat,
+		Visibility:      visibility,
+	}
+	// Visibility in the upload request needs volume API microversion 3.1
+	client := *osclient.BlockStorageClient
+	client.Microversion = "3.1"
+	var err error
+	for i := 0; i < constants.DeleteOperationRetryCount; i++ {
+		var image volumes.VolumeImage
+		image, err = volumes.UploadImage(ctx, &client, volumeID, opts).Extract()
+		if err == nil {
+			return image.ImageID, nil
+		}
+		pkgutils.PrintLog(fmt.Sprintf("Transient error uploading volume %s to image (attempt %d/%d): %s", volumeID, i+1, constants.DeleteOperationRetryCount, err))
+		time.Sleep(constants.DeleteOperationRetryIntervalSeconds * time.Second)
+	}
+	return "", fmt.Errorf("failed to upload volume %s to image after %d attempts: %s", volumeID, constants.DeleteOperationRetryCount, err)
+}
+
+// WaitForImage polls until the image is active, or fails once Glance gives up on it
+// or the timeout expires. Cinder keeps the volume in uploading state until then.
+func (osclient *OpenStackClients) WaitForImage(ctx context.Context, imageID string, timeout time.Duration) error {
+	if osclient.ImageClient == nil {
+		return fmt.Errorf("image service is not available")
+	}
+	pkgutils.PrintLog(fmt.Sprintf("OPENSTACK API: Waiting for image %s to become active, authurl %s, tenant %s", imageID, osclient.AuthURL, osclient.Tenant))
+
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(constants.ExportImagePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("image %s was not active after %s", imageID, timeout)
+		case <-ticker.C:
+			image, err := images.Get(ctx, osclient.ImageClient, imageID).Extract()
+			if err != nil {
+				pkgutils.PrintLog(fmt.Sprintf("Transient error polling image %s: %s", imageID, err))
+				continue
+			}
+			switch image.Status {
+			case images.ImageStatusActive:
+				return nil
+			case images.ImageStatusKilled, images.ImageStatusDeleted, images.ImageStatusPendingDelete:
+				return fmt.Errorf("image %s is in %s state", imageID, image.Status)
+			}
+		}
+	}
+}
+
+// SetImageProperties adds properties to an image, replacing those it already has
+func (osclient *OpenStackClients) SetImageProperties(ctx context.Context, imageID string, properties map[string]string) error {
+	if len(properties) == 0 {
+		return nil
+	}
+	if osclient.ImageClient == nil {
+		return fmt.Errorf("image service is not available")
+	}
+	pkgutils.PrintLog(fmt.Sprintf("OPENSTACK API: Setting %d properties on image %s, authurl %s, tenant %s", len(properties), imageID, osclient.AuthURL, osclient.Tenant))
+	image, err := images.Get(ctx, osclient.ImageClient, imageID).Extract()
+	if err != nil {
+		return fmt.Errorf("failed to get image %s: %s", imageID, err)
+	}
+	// Cinder copies the volume image metadata onto the image, so some of the
+	// properties exist already and are replaced rather than added
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	opts := make(images.UpdateOpts, 0, len(keys))
+	for _, key := range keys {
+		op := images.AddOp
+		if _, ok := image.Properties[key]; ok {
+			op = images.ReplaceOp
+		}
+		opts = append(opts, images.UpdateImageProperty{Op: op, Name: key, Value: properties[key]})
+	}
+	if _, err := images.Update(ctx, osclient.ImageClient, imageID, opts).Extract(); err != nil {
+		return fmt.Errorf("failed to set properties on image %s: %s", imageID, err)
+	}
+	return nil
+}
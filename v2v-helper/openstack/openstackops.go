@@ -12,6 +12,7 @@ import (
 	vjailbreakv1alpha1 "github.com/platform9/vjailbreak/k8s/migration/api/v1alpha1"
 	"github.com/platform9/vjailbreak/pkg/common/constants"
 	"github.com/platform9/vjailbreak/v2v-helper/pkg/k8sutils"
+	pkgutils "github.com/platform9/vjailbreak/v2v-helper/pkg/utils"
 	"github.com/platform9/vjailbreak/v2v-helper/vm"
 
 	gophercloud "github.com/gophercloud/gophercloud/v2"
@@ -69,6 +70,11 @@ type OpenstackOperations interface {
 	// attached to a migrated VM.
 	WaitForVolumeDetached(ctx context.Context, volumeID string, timeout time.Duration) error
 	GetServerStatus(ctx context.Context, serverID string) (string, error)
+	// UploadVolumeToImage uploads a volume to a new Glance image through Cinder and
+	// returns the image ID. The image is not usable until WaitForImage returns.
+	UploadVolumeToImage(ctx context.Context, volumeID, imageName, diskFormat, visibility string) (string, error)
+	WaitForImage(ctx context.Context, imageID string, timeout time.Duration) error
+	SetImageProperties(ctx context.Context, imageID string, properties map[string]string) error
 }
 
 func authOptionsFromEnv() (gophercloud.AuthOptions, error) {
@@ -179,10 +185,18 @@ func validateOpenStack(ctx context.Context, insecure bool) (*OpenStackClients, e
 		return nil, fmt.Errorf("failed to create networking client: %s", err)
 	}
 
+	// Only exports to Glance use the image service, so a missing endpoint is not fatal
+	imageClient, err := openstack.NewImageV2(providerClient, endpoint)
+	if err != nil {
+		pkgutils.PrintLog(fmt.Sprintf("Image service not available, exports to Glance will fail: %s", err))
+		imageClient = nil
+	}
+
 	return &OpenStackClients{
 		BlockStorageClient: blockStorageClient,
 		ComputeClient:      computeClient,
 		NetworkingClient:   networkingClient,
+		ImageClient:        imageClient,
 		K8sClient:          nil,
 		AuthURL:            opts.IdentityEndpoint,
 		Tenant:             opts.TenantName,
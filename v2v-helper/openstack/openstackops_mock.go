@@ -145,6 +145,20 @@ func (mr *MockOpenstackOperationsMockRecorder) DeleteServer(ctx, serverID interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteServer", reflect.TypeOf((*MockOpenstackOperations)(nil).DeleteServer), ctx, serverID)
 }
 
+// SetImageProperties mocks base method.
+func (m *MockOpenstackOperations) SetImageProperties(ctx context.Context, imageID string, properties map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetImageProperties", ctx, imageID, properties)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetImageProperties indicates an expected call of SetImageProperties.
+func (mr *MockOpenstackOperationsMockRecorder) SetImageProperties(ctx, imageID, properties interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetImageProperties", reflect.TypeOf((*MockOpenstackOperations)(nil).SetImageProperties), ctx, imageID, properties)
+}
+
 // StopServer mocks base method.
 func (m *MockOpenstackOperations) StopServer(ctx context.Context, serverID string) error {
 	m.ctrl.T.Helper()
@@ -173,6 +187,35 @@ func (mr *MockOpenstackOperationsMockRecorder) DetachVolumeFromServer(ctx, serve
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachVolumeFromServer", reflect.TypeOf((*MockOpenstackOperations)(nil).DetachVolumeFromServer), ctx, serverID, volumeID)
 }
 
+// UploadVolumeToImage mocks base method.
+func (m *MockOpenstackOperations) UploadVolumeToImage(ctx context.Context, volumeID string, imageName string, diskFormat string, visibility string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadVolumeToImage", ctx, volumeID, imageName, diskFormat, visibility)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadVolumeToImage indicates an expected call of UploadVolumeToImage.
+func (mr *MockOpenstackOperationsMockRecorder) UploadVolumeToImage(ctx, volumeID, imageName, diskFormat, visibility interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadVolumeToImage", reflect.TypeOf((*MockOpenstackOperations)(nil).UploadVolumeToImage), ctx, volumeID, imageName, diskFormat, visibility)
+}
+
+// WaitForImage mocks base method.
+func (m *MockOpenstackOperations) WaitForImage(ctx context.Context, imageID string, timeout time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForImage", ctx, imageID, timeout)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForImage indicates an expected call of WaitForImage.
+func (mr *MockOpenstackOperationsMockRecorder) WaitForImage(ctx, imageID, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForImage", reflect.TypeOf((*MockOpenstackOperations)(nil).WaitForImage), ctx, imageID, timeout)
+}
+
 // WaitForVolumeDetached mocks base method.
 func (m *MockOpenstackOperations) WaitForVolumeDetached(ctx context.Context, volumeID string, timeout time.Duration) error {
 	m.ctrl.T.Helper()
@@ -28,4 +28,11 @@ type VjailbreakSettings struct {
 	V2VHelperPodEphemeralStorageRequest string
 	V2VHelperPodEphemeralStorageLimit   string
 	HTTPTimeoutSeconds                  int
+	GlobalBandwidthLimitMbps            int
+	GlobalBandwidthSchedule             string
+	NFCConnectionsPerESXiHost           int
+	MaxMigrationsPerESXiHost            int
+	MaxMigrationsPerDatastore           int
+	MaxMigrationsPerAgent               int
+	CutoverDowntimeTarget               string
 }
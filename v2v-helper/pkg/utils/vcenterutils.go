@@ -3,8 +3,11 @@ package utils
 import (
 	"context"
 	"encoding/json"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/platform9/vjailbreak/pkg/common/bandwidth"
 	"github.com/platform9/vjailbreak/pkg/common/constants"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -59,6 +62,21 @@ type MigrationParams struct {
 	// provider should fall back to auto-detection.
 	NetAppSVM     string
 	NetAppFlexVol string
+	// PowerStore appliance new volumes are created on. Empty to let
+	// PowerStore place them.
+	PowerStoreAppliance string
+	// HPE CPG new volumes are created in. Empty to fall back to the only
+	// CPG of the array.
+	HPECPG string
+	// Simulated array state file and injected faults, for development
+	// and CI clusters
+	SimulatorStateFile    string
+	SimulatorCreateDelay  string
+	SimulatorFailMapping  bool
+	SimulatorNAACollision bool
+	// ArrayNativeCopy extracts the disks from an array clone of their VMFS
+	// datastore instead of cloning them with vmkfstools
+	ArrayNativeCopy bool
 
 	ImageMetadata map[string]string
 
@@ -73,6 +91,51 @@ type MigrationParams struct {
 
 	// DataOnly indicates no OpenStack VM should be created after disk conversion.
 	DataOnly bool
+
+	// SourceHostType is "esxi" when the source is a standalone ESXi host
+	SourceHostType string
+
+	// SourceType is "appliance", "libvirt" or "proxmox" when the source is not a VMware VM
+	SourceType string
+	// ApplianceFormat and ApplianceSource locate the appliance to import
+	ApplianceFormat string
+	ApplianceSource string
+	// LibvirtURI and LibvirtDomain locate the domain of a libvirt source
+	LibvirtURI    string
+	LibvirtDomain string
+	// ProxmoxAPIURL, ProxmoxInsecure and ProxmoxVMID locate the VM of a Proxmox VE source
+	ProxmoxAPIURL   string
+	ProxmoxInsecure bool
+	ProxmoxVMID     string
+
+	// DestinationType is "kubevirt" to create the VM on KubeVirt rather than OpenStack
+	DestinationType string
+	// KubevirtNamespace and KubevirtUploadProxyURL locate the KubeVirt destination
+	KubevirtNamespace      string
+	KubevirtUploadProxyURL string
+
+	// ExportTarget is "pvc" or "glance" to export the converted disks of a data-only
+	// migration, empty otherwise
+	ExportTarget          string
+	ExportCompress        bool
+	ExportImageVisibility string
+	ExportKeepVolumes     bool
+
+	// Bandwidth is the MigrationPlan cap on the disk copy rate, unlimited when unset
+	Bandwidth bandwidth.Schedule
+
+	// VerifyMode is the integrity verification mode, Sampled or Full, empty when off
+	VerifyMode          string
+	VerifySamplePercent int
+
+	// ParallelDiskCopies is how many disks the MigrationPlan lets v2v-helper copy
+	// at once, 0 when unset
+	ParallelDiskCopies int
+
+	// VDDKTransports are the VDDK transport modes to try in order, empty for the
+	// default, and VDDKCompression the NBD compression
+	VDDKTransports  []string
+	VDDKCompression string
 }
 
 // GetMigrationParams is function that returns the migration parameters
@@ -109,6 +172,35 @@ func GetMigrationParams(ctx context.Context, client client.Client) (*MigrationPa
 		}
 	}
 
+	var bandwidthSchedule bandwidth.Schedule
+	if raw := configMap.Data[constants.BandwidthLimitMbpsKey]; raw != "" {
+		if bandwidthSchedule.RateMbps, err = strconv.Atoi(raw); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s from configmap", constants.BandwidthLimitMbpsKey)
+		}
+	}
+	if bandwidthSchedule.Windows, err = bandwidth.ParseWindows(configMap.Data[constants.BandwidthScheduleKey]); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s from configmap", constants.BandwidthScheduleKey)
+	}
+
+	verifySamplePercent := 100
+	if raw := configMap.Data[constants.VerifySamplePercentKey]; raw != "" {
+		if verifySamplePercent, err = strconv.Atoi(raw); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s from configmap", constants.VerifySamplePercentKey)
+		}
+	}
+
+	parallelDiskCopies := 0
+	if raw := configMap.Data[constants.ParallelDiskCopiesKey]; raw != "" {
+		if parallelDiskCopies, err = strconv.Atoi(raw); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s from configmap", constants.ParallelDiskCopiesKey)
+		}
+	}
+
+	var vddkTransports []string
+	if raw := configMap.Data[constants.VDDKTransportsKey]; raw != "" {
+		vddkTransports = strings.Split(raw, ":")
+	}
+
 	return &MigrationParams{
 		SourceVMName:                   string(configMap.Data["SOURCE_VM_NAME"]),
 		SourceVMID:                     string(configMap.Data["SOURCE_VM_ID"]),
@@ -145,6 +237,13 @@ func GetMigrationParams(ctx context.Context, client client.Client) (*MigrationPa
 		ProxyVMK8sName:                 string(configMap.Data["PROXY_VM_K8S_NAME"]),
 		NetAppSVM:                      string(configMap.Data["NETAPP_SVM"]),
 		NetAppFlexVol:                  string(configMap.Data["NETAPP_FLEXVOL"]),
+		PowerStoreAppliance:            string(configMap.Data["POWERSTORE_APPLIANCE"]),
+		HPECPG:                         string(configMap.Data["HPE_CPG"]),
+		SimulatorStateFile:             string(configMap.Data["SIMULATOR_STATE_FILE"]),
+		SimulatorCreateDelay:           string(configMap.Data["SIMULATOR_CREATE_DELAY"]),
+		SimulatorFailMapping:           string(configMap.Data["SIMULATOR_FAIL_MAPPING"]) == constants.TrueString,
+		SimulatorNAACollision:          string(configMap.Data["SIMULATOR_NAA_COLLISION"]) == constants.TrueString,
+		ArrayNativeCopy:                string(configMap.Data["ARRAY_NATIVE_COPY"]) == constants.TrueString,
 		AcknowledgeNetworkConflictRisk: string(configMap.Data["ACKNOWLEDGE_NETWORK_CONFLICT_RISK"]) == constants.TrueString,
 		NetworkOverrides:               string(configMap.Data["NETWORK_OVERRIDES"]),
 		ImageMetadata:                  imageMetadata,
@@ -152,5 +251,27 @@ func GetMigrationParams(ctx context.Context, client client.Client) (*MigrationPa
 		SourceTagsMetadata:             sourceTagsMetadata,
 		CustomMetadata:                 customMetadata,
 		DataOnly:                       string(configMap.Data["DATA_ONLY"]) == "true",
+		SourceHostType:                 string(configMap.Data["SOURCE_HOST_TYPE"]),
+		SourceType:                     string(configMap.Data[constants.SourceTypeKey]),
+		ApplianceFormat:                string(configMap.Data[constants.ApplianceFormatKey]),
+		ApplianceSource:                string(configMap.Data[constants.ApplianceSourceKey]),
+		LibvirtURI:                     string(configMap.Data[constants.LibvirtURIKey]),
+		LibvirtDomain:                  string(configMap.Data[constants.LibvirtDomainKey]),
+		ProxmoxAPIURL:                  string(configMap.Data[constants.ProxmoxAPIURLKey]),
+		ProxmoxInsecure:                string(configMap.Data[constants.ProxmoxInsecureKey]) == constants.TrueString,
+		ProxmoxVMID:                    string(configMap.Data[constants.ProxmoxVMIDKey]),
+		DestinationType:                string(configMap.Data[constants.DestinationTypeKey]),
+		KubevirtNamespace:              string(configMap.Data[constants.KubevirtNamespaceKey]),
+		KubevirtUploadProxyURL:         string(configMap.Data[constants.KubevirtUploadProxyURLKey]),
+		ExportTarget:                   string(configMap.Data[constants.ExportTargetKey]),
+		ExportCompress:                 string(configMap.Data[constants.ExportCompressKey]) == constants.TrueString,
+		ExportImageVisibility:          string(configMap.Data[constants.ExportImageVisibilityKey]),
+		ExportKeepVolumes:              string(configMap.Data[constants.ExportKeepVolumesKey]) == constants.TrueString,
+		Bandwidth:                      bandwidthSchedule,
+		VerifyMode:                     string(configMap.Data[constants.VerifyModeKey]),
+		VerifySamplePercent:            verifySamplePercent,
+		ParallelDiskCopies:             parallelDiskCopies,
+		VDDKTransports:                 vddkTransports,
+		VDDKCompression:                string(configMap.Data[constants.VDDKCompressionKey]),
 	}, nil
 }
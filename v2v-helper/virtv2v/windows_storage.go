@@ -0,0 +1,165 @@
+// Copyright © 2025 The vjailbreak authors
+
+package virtv2v
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/platform9/vjailbreak/v2v-helper/vm"
+)
+
+// Section markers of the Windows storage inspection
+const (
+	rootsMarker       = "---VJB-ROOTS---"
+	filesystemsMarker = "---VJB-FS---"
+	ldmVolumesMarker  = "---VJB-LDM---"
+	partitionsMarker  = "---VJB-PARTS---"
+	partTypeMarker    = "---VJB-PARTTYPE---"
+)
+
+// GPT partition types and the MBR partition id of Windows storage layouts
+// conversion has to look out for
+const (
+	GPTTypeLDMData       = "AF9B60A0-1431-4F62-BC68-3311714A69AD"
+	GPTTypeStorageSpaces = "E75CAF8F-F680-4CEE-AFA3-B001E56EFC2D"
+	MBRIDLDM             = "0x42"
+)
+
+// WindowsStorage is what the storage preflight found on the disks of a guest
+type WindowsStorage struct {
+	// Roots are the operating system roots inspect-os found, none when the
+	// system volume cannot be read
+	Roots []string
+	// Filesystems maps every filesystem libguestfs sees to its type, as
+	// list-filesystems reports it. BitLocker volumes have type "BitLocker".
+	Filesystems map[string]string
+	// LDMVolumes are the volumes assembled from Windows Dynamic Disks
+	LDMVolumes []string
+	// PartitionTypes maps the partitions holding no filesystem libguestfs knows
+	// to their GPT partition type, or their MBR id as 0x42
+	PartitionTypes map[string]string
+}
+
+// InspectWindowsStorage reads the storage layout of the guest on disks. It runs
+// guestfish without a mount plan, as RunCommandInGuestAllVolumes would need one
+// and a BitLocker or Storage Spaces system volume leaves no root to plan from.
+// One appliance boot, and a second when some partitions hold no filesystem.
+func InspectWindowsStorage(disks []vm.VMDisk) (WindowsStorage, error) {
+	if len(disks) == 0 {
+		return WindowsStorage{}, fmt.Errorf("no disks supplied; cannot inspect the guest storage")
+	}
+	out, err := runScript(disks, windowsStorageScript())
+	if err != nil {
+		return WindowsStorage{}, fmt.Errorf("failed to inspect the guest storage: %w", err)
+	}
+	storage, partitions := parseWindowsStorage(out)
+
+	var unknown []string
+	for _, partition := range partitions {
+		if fsType, ok := storage.Filesystems[partition]; !ok || fsType == "unknown" {
+			unknown = append(unknown, partition)
+		}
+	}
+	if script := partitionTypesScript(unknown); script != "" {
+		out, err := runScript(disks, script)
+		if err != nil {
+			return WindowsStorage{}, fmt.Errorf("failed to read the partition types of %v: %w", unknown, err)
+		}
+		storage.PartitionTypes = parsePartitionTypes(out)
+	}
+	return storage, nil
+}
+
+// windowsStorageScript builds the first inspection script. inspect-os and
+// list-ldm-volumes may fail on such guests, which is tolerated.
+func windowsStorageScript() string {
+	var b strings.Builder
+	b.WriteString("run\n")
+	fmt.Fprintf(&b, "echo %s\n", rootsMarker)
+	b.WriteString("- inspect-os\n")
+	fmt.Fprintf(&b, "echo %s\n", filesystemsMarker)
+	b.WriteString("list-filesystems\n")
+	fmt.Fprintf(&b, "echo %s\n", ldmVolumesMarker)
+	b.WriteString("- list-ldm-volumes\n")
+	fmt.Fprintf(&b, "echo %s\n", partitionsMarker)
+	b.WriteString("list-partitions\n")
+	return b.String()
+}
+
+// parseWindowsStorage reads the answers to windowsStorageScript and returns the
+// partitions of the guest alongside
+func parseWindowsStorage(out string) (WindowsStorage, []string) {
+	storage := WindowsStorage{Filesystems: map[string]string{}}
+	var partitions []string
+	section := ""
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch line {
+		case rootsMarker, filesystemsMarker, ldmVolumesMarker, partitionsMarker:
+			section = line
+			continue
+		case "":
+			continue
+		}
+		switch section {
+		case rootsMarker:
+			storage.Roots = append(storage.Roots, line)
+		case filesystemsMarker:
+			if device, fsType, ok := strings.Cut(line, ": "); ok {
+				storage.Filesystems[device] = fsType
+			}
+		case ldmVolumesMarker:
+			storage.LDMVolumes = append(storage.LDMVolumes, line)
+		case partitionsMarker:
+			partitions = append(partitions, line)
+		}
+	}
+	return storage, partitions
+}
+
+// partitionRe splits a partition name into its device and partition number
+var partitionRe = regexp.MustCompile(`^(/dev/[a-z]+)(\d+)$`)
+
+// partitionTypesScript asks for the GPT type or the MBR id of every partition.
+// One of the two fails, depending on the partition table, which is tolerated.
+func partitionTypesScript(partitions []string) string {
+	var b strings.Builder
+	for _, partition := range partitions {
+		m := partitionRe.FindStringSubmatch(partition)
+		if m == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "echo %s %s\n", partTypeMarker, quoteArg(partition))
+		b.WriteString("- " + guestfishLine("part-get-gpt-type", m[1], m[2]) + "\n")
+		b.WriteString("- " + guestfishLine("part-get-mbr-id", m[1], m[2]) + "\n")
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "run\n" + b.String()
+}
+
+// parsePartitionTypes reads the answers to partitionTypesScript. guestfish
+// prints the MBR id in decimal, it is returned in hex like MBRIDLDM.
+func parsePartitionTypes(out string) map[string]string {
+	types := map[string]string{}
+	current := ""
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, partTypeMarker):
+			current = strings.TrimSpace(strings.TrimPrefix(line, partTypeMarker))
+		case line == "" || current == "":
+		default:
+			if id, err := strconv.Atoi(line); err == nil {
+				types[current] = fmt.Sprintf("0x%02x", id)
+			} else {
+				types[current] = strings.ToUpper(line)
+			}
+		}
+	}
+	return types
+}
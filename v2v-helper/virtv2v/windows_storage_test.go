@@ -0,0 +1,54 @@
+// Copyright © 2025 The vjailbreak authors
+
+package virtv2v
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWindowsStorage(t *testing.T) {
+	// A BitLocker system volume: inspect-os finds no root and fails, which only
+	// leaves its error on stderr
+	out := rootsMarker + "\n" +
+		filesystemsMarker + "\n/dev/sda1: ntfs\n/dev/sda2: BitLocker\n/dev/sdb1: unknown\n/dev/mapper/ldm_vol_data: ntfs\n" +
+		ldmVolumesMarker + "\n/dev/mapper/ldm_vol_data\n" +
+		partitionsMarker + "\n/dev/sda1\n/dev/sda2\n/dev/sdb1\n/dev/sdc1\n"
+	storage, partitions := parseWindowsStorage(out)
+	assert.Empty(t, storage.Roots)
+	assert.Equal(t, map[string]string{
+		"/dev/sda1":                "ntfs",
+		"/dev/sda2":                "BitLocker",
+		"/dev/sdb1":                "unknown",
+		"/dev/mapper/ldm_vol_data": "ntfs",
+	}, storage.Filesystems)
+	assert.Equal(t, []string{"/dev/mapper/ldm_vol_data"}, storage.LDMVolumes)
+	assert.Equal(t, []string{"/dev/sda1", "/dev/sda2", "/dev/sdb1", "/dev/sdc1"}, partitions)
+
+	storage, _ = parseWindowsStorage(rootsMarker + "\n/dev/sda2\n" + filesystemsMarker + "\n")
+	assert.Equal(t, []string{"/dev/sda2"}, storage.Roots)
+}
+
+func TestPartitionTypesScript(t *testing.T) {
+	assert.Empty(t, partitionTypesScript(nil))
+	assert.Empty(t, partitionTypesScript([]string{"/dev/mapper/ldm_part_x"}), "only disk partitions have a type")
+	assert.Equal(t, "run\n"+
+		"echo "+partTypeMarker+" \"/dev/sdb1\"\n"+
+		"- part-get-gpt-type \"/dev/sdb\" \"1\"\n"+
+		"- part-get-mbr-id \"/dev/sdb\" \"1\"\n",
+		partitionTypesScript([]string{"/dev/sdb1"}))
+}
+
+func TestParsePartitionTypes(t *testing.T) {
+	out := partTypeMarker + " /dev/sdb1\n" +
+		"e75caf8f-f680-4cee-afa3-b001e56efc2d\n" +
+		partTypeMarker + " /dev/sdc1\n" +
+		"66\n" +
+		// Both commands failed
+		partTypeMarker + " /dev/sdd1\n"
+	assert.Equal(t, map[string]string{
+		"/dev/sdb1": GPTTypeStorageSpaces,
+		"/dev/sdc1": MBRIDLDM,
+	}, parsePartitionTypes(out))
+}
@@ -27,10 +27,8 @@ import (
 
 //go:generate mockgen -source=vmops.go -destination=vmops_mock.go -package=vm
 
-// MinCBTHardwareVersion is the minimum VMware virtual hardware version that
-// supports Changed Block Tracking (CBT). VMs on older hardware versions cannot
-// use CBT and must be migrated using cold migration. See VMware KB 1020128.
-const MinCBTHardwareVersion = 7
+// MinCBTHardwareVersion is constants.MinCBTHardwareVersion
+const MinCBTHardwareVersion = constants.MinCBTHardwareVersion
 
 type VMOperations interface {
 	GetVMInfo(ostype string, rdmDisks []string) (VMInfo, error)